@@ -0,0 +1,83 @@
+package coregex
+
+import (
+	"fmt"
+
+	"github.com/coregx/coregex/internal/wire"
+	"github.com/coregx/coregex/meta"
+)
+
+// Version is the coregex release. Regexes saved with MarshalBinary can only
+// be loaded by the same release.
+const Version = meta.Version
+
+// ErrVersionMismatch is returned by UnmarshalBinary for data saved by another
+// coregex version. Compile the pattern again in that case.
+var ErrVersionMismatch = meta.ErrVersionMismatch
+
+// ErrInvalidEngineData is returned by UnmarshalBinary for data that is not a
+// saved Regex or is corrupt.
+var ErrInvalidEngineData = meta.ErrInvalidEngineData
+
+// AppendBinary implements encoding.BinaryAppender. It appends the compiled
+// regex to b: the NFAs, the OnePass DFA tables, the selected strategy and the
+// extracted literals, together with the pattern text and the Longest setting.
+//
+// Unlike MarshalText, which saves only the pattern, the result can be loaded
+// by UnmarshalBinary without parsing, literal extraction or strategy
+// selection, which makes it suitable for caching compiled patterns between
+// runs.
+func (r *Regex) AppendBinary(b []byte) ([]byte, error) {
+	engine, err := r.engine.AppendBinary(nil)
+	if err != nil {
+		return nil, err
+	}
+	b = wire.AppendBytes(b, engine)
+	b = wire.AppendString(b, r.pattern)
+	return wire.AppendBool(b, r.longest), nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. See AppendBinary.
+//
+// Example:
+//
+//	data, err := re.MarshalBinary()
+//	...
+//	var loaded coregex.Regex
+//	if err := loaded.UnmarshalBinary(data); errors.Is(err, coregex.ErrVersionMismatch) {
+//	    // saved by another coregex release: compile the pattern again
+//	}
+func (r *Regex) MarshalBinary() ([]byte, error) {
+	return r.AppendBinary(nil)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. It replaces r with
+// the regex saved by MarshalBinary.
+//
+// It returns an error matching ErrVersionMismatch if data was saved by
+// another coregex version, and one matching ErrInvalidEngineData if data is
+// not a saved regex.
+func (r *Regex) UnmarshalBinary(data []byte) error {
+	return r.UnmarshalBinaryWithObserver(data, nil)
+}
+
+// UnmarshalBinaryWithObserver is like UnmarshalBinary but sets the observer
+// of the regex, which MarshalBinary does not save, to observer.
+func (r *Regex) UnmarshalBinaryWithObserver(data []byte, observer Observer) error {
+	rd := wire.NewReader(data)
+	saved := rd.Bytes()
+	pattern := rd.String()
+	longest := rd.Bool()
+	if err := rd.Done(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidEngineData, err)
+	}
+	engine, err := meta.LoadEngineWithObserver(saved, observer)
+	if err != nil {
+		return err
+	}
+	if longest {
+		engine.SetLongest(true)
+	}
+	*r = Regex{engine: engine, pattern: pattern, longest: longest}
+	return nil
+}
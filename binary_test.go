@@ -0,0 +1,71 @@
+package coregex
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRegexBinaryRoundTrip(t *testing.T) {
+	tests := []struct {
+		pattern string
+		longest bool
+	}{
+		{`(?P<user>\w+)@(?P<host>[\w.]+)`, false},
+		{`a|ab`, true},
+		{`.*\.(txt|log)`, false},
+		{`(?i)error: (\d+)`, false},
+		{`^$`, false},
+	}
+	inputs := []string{"", "ab abc", "mail bob@example.com now", "a.txt\nb.log", "ERROR: 42 error: 7"}
+	for _, tt := range tests {
+		re := MustCompile(tt.pattern)
+		if tt.longest {
+			re.Longest()
+		}
+		data, err := re.MarshalBinary()
+		if err != nil {
+			t.Fatalf("%q: MarshalBinary: %v", tt.pattern, err)
+		}
+		var loaded Regex
+		if err := loaded.UnmarshalBinary(data); err != nil {
+			t.Fatalf("%q: UnmarshalBinary: %v", tt.pattern, err)
+		}
+
+		if loaded.String() != tt.pattern {
+			t.Errorf("loaded String() = %q, want %q", loaded.String(), tt.pattern)
+		}
+		if got, want := loaded.SubexpNames(), re.SubexpNames(); !reflect.DeepEqual(got, want) {
+			t.Errorf("%q: SubexpNames = %q, want %q", tt.pattern, got, want)
+		}
+		for _, in := range inputs {
+			got := loaded.FindAllStringSubmatchIndex(in, -1)
+			want := re.FindAllStringSubmatchIndex(in, -1)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("%q on %q: loaded %v, want %v", tt.pattern, in, got, want)
+			}
+		}
+	}
+}
+
+func TestRegexUnmarshalBinaryErrors(t *testing.T) {
+	data, err := MustCompile(`\d+`).AppendBinary([]byte("x"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = data[1:]
+
+	var re Regex
+	if err := re.UnmarshalBinary(data[:len(data)-1]); !errors.Is(err, ErrInvalidEngineData) {
+		t.Errorf("truncated data: error = %v, want ErrInvalidEngineData", err)
+	}
+	if err := re.UnmarshalBinary([]byte(`\d+`)); !errors.Is(err, ErrInvalidEngineData) {
+		t.Errorf("pattern text: error = %v, want ErrInvalidEngineData", err)
+	}
+	other := bytes.Replace(data, []byte(Version), []byte(strings.Repeat("0", len(Version))), 1)
+	if err := re.UnmarshalBinary(other); !errors.Is(err, ErrVersionMismatch) {
+		t.Errorf("other version: error = %v, want ErrVersionMismatch", err)
+	}
+}
@@ -0,0 +1,488 @@
+// Package build constructs regular expressions from typed parts instead of
+// pattern strings.
+//
+// Patterns assembled from data by string concatenation break as soon as a
+// piece is not quoted right, and lose their structure. With build, literals
+// are always literal and every part is an Expr:
+//
+//	host := build.Alt(build.Lit("example.com"), build.Lit("api.example.com"))
+//	url := build.Concat(
+//	    build.Anchor(build.StartText),
+//	    build.Lit("https://"),
+//	    build.Group("host", host),
+//	    build.Repeat(build.Class(`/\w.-`), 0, -1),
+//	)
+//	re, err := coregex.CompileExpr(url)
+//
+// An Expr is turned into a *syntax.Regexp by Regexp, or compiled straight to
+// a meta.Engine by Compile; coregex.CompileExpr returns a *coregex.Regex.
+//
+// A Library holds named sub-patterns: Define a name once and Ref it from
+// any number of patterns, which is how pattern libraries (IP addresses,
+// host names, dates) are built up. References are resolved when the
+// pattern is built, so a definition may refer to names defined after it.
+//
+// Capture groups are numbered in the order they appear in the built
+// pattern, counting each use of a referenced definition. Group names must be
+// unique within one pattern.
+package build
+
+import (
+	"errors"
+	"fmt"
+	"regexp/syntax"
+	"unicode"
+
+	"github.com/coregx/coregex/meta"
+)
+
+var (
+	// ErrInvalidExpr is returned for an Expr that cannot be built: a nil
+	// Expr, a bad repetition count, nested repetitions or an expression
+	// too large for regexp/syntax, a bad or duplicate group name, or a
+	// Class spec that is not a single class.
+	ErrInvalidExpr = errors.New("build: invalid expression")
+
+	// ErrUndefined is returned for a Ref to a name that is not defined in
+	// its Library.
+	ErrUndefined = errors.New("build: undefined name")
+
+	// ErrRecursive is returned when a definition refers to itself, directly
+	// or through other definitions.
+	ErrRecursive = errors.New("build: recursive definition")
+)
+
+// maxRepeat is the largest repetition count, as in regexp/syntax. It also
+// bounds the product of the counts of nested repetitions.
+const maxRepeat = 1000
+
+// maxSize is the largest size (see exprSize) of a built expression, the
+// limit of regexp/syntax: 128 MB of 40-byte program instructions.
+const maxSize = 128 << 20 / 40
+
+// Expr is a part of a regular expression. Exprs are immutable and may be
+// shared between patterns and goroutines.
+type Expr interface {
+	// build returns a new syntax tree for the expression.
+	build(b *builder) (*syntax.Regexp, error)
+}
+
+// builder holds the state of building one pattern.
+type builder struct {
+	flags  syntax.Flags
+	active map[libRef]bool // definitions being built, to detect recursion
+}
+
+// buildExpr builds e, which may be nil.
+func (b *builder) buildExpr(e Expr) (*syntax.Regexp, error) {
+	if e == nil {
+		return nil, fmt.Errorf("%w: nil Expr", ErrInvalidExpr)
+	}
+	return e.build(b)
+}
+
+// buildAll builds each of exprs.
+func (b *builder) buildAll(exprs []Expr) ([]*syntax.Regexp, error) {
+	subs := make([]*syntax.Regexp, len(exprs))
+	for i, e := range exprs {
+		re, err := b.buildExpr(e)
+		if err != nil {
+			return nil, err
+		}
+		subs[i] = re
+	}
+	return subs, nil
+}
+
+// Regexp builds the syntax tree of e with the syntax.Perl flags.
+func Regexp(e Expr) (*syntax.Regexp, error) {
+	return RegexpWithFlags(e, syntax.Perl)
+}
+
+// RegexpWithFlags builds the syntax tree of e with the given parser flags,
+// which apply to the whole expression as if they were set at the start of
+// a pattern: syntax.FoldCase makes every literal and class
+// case-insensitive, syntax.NonGreedy swaps greedy and lazy repetitions, and
+// the flags are used to parse Class specs and Pattern fragments.
+func RegexpWithFlags(e Expr, flags syntax.Flags) (*syntax.Regexp, error) {
+	b := &builder{flags: flags, active: make(map[libRef]bool)}
+	re, err := b.buildExpr(e)
+	if err != nil {
+		return nil, err
+	}
+	if size := exprSize(re); size > maxSize {
+		return nil, fmt.Errorf("%w: expression size %d exceeds %d", ErrInvalidExpr, size, maxSize)
+	}
+	if err := numberCaptures(re, new(int), make(map[string]bool)); err != nil {
+		return nil, err
+	}
+	return re, nil
+}
+
+// String returns the pattern text of e.
+func String(e Expr) (string, error) {
+	re, err := Regexp(e)
+	if err != nil {
+		return "", err
+	}
+	return re.String(), nil
+}
+
+// Compile builds e with the parser flags of config.SyntaxFlags (see
+// RegexpWithFlags) and compiles it with config. Verbose mode and extended
+// syntax do not apply to an Expr.
+func Compile(e Expr, config meta.Config) (*meta.Engine, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	flags := config.SyntaxFlags
+	if flags == 0 {
+		flags = syntax.Perl
+	}
+	re, err := RegexpWithFlags(e, flags)
+	if err != nil {
+		return nil, err
+	}
+	return meta.CompileRegexp(re, config)
+}
+
+// numberCaptures numbers the capture groups of re in order, starting after
+// *n, and checks that their names are unique.
+func numberCaptures(re *syntax.Regexp, n *int, names map[string]bool) error {
+	if re.Op == syntax.OpCapture {
+		*n++
+		re.Cap = *n
+		if re.Name != "" {
+			if names[re.Name] {
+				return fmt.Errorf("%w: duplicate group name %q", ErrInvalidExpr, re.Name)
+			}
+			names[re.Name] = true
+		}
+	}
+	for _, sub := range re.Sub {
+		if err := numberCaptures(sub, n, names); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Lit matches the text s literally.
+func Lit(s string) Expr {
+	return literal(s)
+}
+
+type literal string
+
+func (l literal) build(b *builder) (*syntax.Regexp, error) {
+	if l == "" {
+		return &syntax.Regexp{Op: syntax.OpEmptyMatch, Flags: b.flags}, nil
+	}
+	return &syntax.Regexp{Op: syntax.OpLiteral, Flags: b.flags, Rune: []rune(string(l))}, nil
+}
+
+// Class matches one character of a class given in bracket syntax without
+// the brackets, such as `a-z0-9_`, `\d\s` or `\p{Greek}`. A leading ^
+// negates the class.
+func Class(spec string) Expr {
+	return class(spec)
+}
+
+type class string
+
+func (c class) build(b *builder) (*syntax.Regexp, error) {
+	re, err := syntax.Parse("["+string(c)+"]", b.flags)
+	if err != nil {
+		return nil, err
+	}
+	switch re.Op {
+	case syntax.OpCharClass, syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		return re, nil
+	case syntax.OpLiteral:
+		if len(re.Rune) == 1 {
+			return re, nil
+		}
+	}
+	// The spec closed the bracket and went on, as in `a]b`.
+	return nil, fmt.Errorf("%w: %q is not a single class", ErrInvalidExpr, string(c))
+}
+
+// Pattern is a fragment in regular expression syntax, for the parts that
+// are easier to write as a pattern. Its capture groups count as groups of
+// the whole pattern.
+func Pattern(s string) Expr {
+	return pattern(s)
+}
+
+type pattern string
+
+func (p pattern) build(b *builder) (*syntax.Regexp, error) {
+	return syntax.Parse(string(p), b.flags)
+}
+
+// Concat matches each of exprs in sequence. With no exprs it matches the
+// empty string.
+func Concat(exprs ...Expr) Expr {
+	return concat(append([]Expr(nil), exprs...))
+}
+
+type concat []Expr
+
+func (c concat) build(b *builder) (*syntax.Regexp, error) {
+	subs, err := b.buildAll(c)
+	if err != nil {
+		return nil, err
+	}
+	switch len(subs) {
+	case 0:
+		return &syntax.Regexp{Op: syntax.OpEmptyMatch, Flags: b.flags}, nil
+	case 1:
+		return subs[0], nil
+	}
+	return &syntax.Regexp{Op: syntax.OpConcat, Flags: b.flags, Sub: subs}, nil
+}
+
+// Alt matches any one of exprs, preferring the first that leads to a match
+// as an alternation does. With no exprs it matches nothing.
+func Alt(exprs ...Expr) Expr {
+	return alt(append([]Expr(nil), exprs...))
+}
+
+type alt []Expr
+
+func (a alt) build(b *builder) (*syntax.Regexp, error) {
+	subs, err := b.buildAll(a)
+	if err != nil {
+		return nil, err
+	}
+	switch len(subs) {
+	case 0:
+		// An empty class, which is how the parser writes "no match".
+		return &syntax.Regexp{Op: syntax.OpCharClass, Flags: b.flags, Rune: []rune{}}, nil
+	case 1:
+		return subs[0], nil
+	}
+	return &syntax.Regexp{Op: syntax.OpAlternate, Flags: b.flags, Sub: subs}, nil
+}
+
+// Repeat matches e at least minCount and at most maxCount times, as
+// e{min,max}; a negative maxCount means no upper bound. Counts are limited
+// to 1000.
+func Repeat(e Expr, minCount, maxCount int) Expr {
+	return repeat{sub: e, min: minCount, max: maxCount}
+}
+
+// Star matches e zero or more times, as e*.
+func Star(e Expr) Expr {
+	return Repeat(e, 0, -1)
+}
+
+// Plus matches e one or more times, as e+.
+func Plus(e Expr) Expr {
+	return Repeat(e, 1, -1)
+}
+
+// Opt matches e zero or one time, as e?.
+func Opt(e Expr) Expr {
+	return Repeat(e, 0, 1)
+}
+
+type repeat struct {
+	sub      Expr
+	min, max int
+	lazy     bool
+}
+
+func (r repeat) build(b *builder) (*syntax.Regexp, error) {
+	maxCount := r.max
+	if maxCount < 0 {
+		maxCount = -1
+	}
+	if r.min < 0 || r.min > maxRepeat || maxCount > maxRepeat || (maxCount >= 0 && maxCount < r.min) {
+		return nil, fmt.Errorf("%w: repetition {%d,%d}", ErrInvalidExpr, r.min, r.max)
+	}
+	sub, err := b.buildExpr(r.sub)
+	if err != nil {
+		return nil, err
+	}
+	flags := b.flags
+	if r.lazy {
+		flags ^= syntax.NonGreedy
+	}
+	re := &syntax.Regexp{Flags: flags, Sub: []*syntax.Regexp{sub}}
+	switch {
+	case r.min == 0 && maxCount == -1:
+		re.Op = syntax.OpStar
+	case r.min == 1 && maxCount == -1:
+		re.Op = syntax.OpPlus
+	case r.min == 0 && maxCount == 1:
+		re.Op = syntax.OpQuest
+	default:
+		re.Op, re.Min, re.Max = syntax.OpRepeat, r.min, maxCount
+		if (r.min >= 2 || maxCount >= 2) && !repeatIsValid(re, maxRepeat) {
+			return nil, fmt.Errorf("%w: nested repetition {%d,%d} exceeds %d", ErrInvalidExpr, r.min, r.max, maxRepeat)
+		}
+	}
+	return re, nil
+}
+
+// repeatIsValid reports whether the counts of the nested repetitions of re
+// multiply to at most n, the check of regexp/syntax: (a{100}){100} would
+// compile to 10,000 copies of a.
+func repeatIsValid(re *syntax.Regexp, n int) bool {
+	if re.Op == syntax.OpRepeat {
+		m := re.Max
+		if m == 0 {
+			return true
+		}
+		if m < 0 {
+			m = re.Min
+		}
+		if m > n {
+			return false
+		}
+		if m > 0 {
+			n /= m
+		}
+	}
+	for _, sub := range re.Sub {
+		if !repeatIsValid(sub, n) {
+			return false
+		}
+	}
+	return true
+}
+
+// exprSize returns about how many program instructions re compiles to, as
+// regexp/syntax counts them against maxSize. The same Expr used many times
+// builds a small tree but a large program.
+func exprSize(re *syntax.Regexp) int64 {
+	var size int64
+	switch re.Op {
+	case syntax.OpLiteral:
+		size = int64(len(re.Rune))
+	case syntax.OpCapture, syntax.OpStar:
+		size = 2 + exprSize(re.Sub[0])
+	case syntax.OpPlus, syntax.OpQuest:
+		size = 1 + exprSize(re.Sub[0])
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			size += exprSize(sub)
+		}
+	case syntax.OpAlternate:
+		for _, sub := range re.Sub {
+			size += exprSize(sub)
+		}
+		if len(re.Sub) > 1 {
+			size += int64(len(re.Sub) - 1)
+		}
+	case syntax.OpRepeat:
+		sub := exprSize(re.Sub[0])
+		if re.Max == -1 {
+			if re.Min == 0 {
+				size = 2 + sub
+			} else {
+				size = 1 + int64(re.Min)*sub
+			}
+		} else {
+			size = int64(re.Max)*sub + int64(re.Max-re.Min)
+		}
+	default:
+		size = 1
+	}
+	return max(size, 1)
+}
+
+// Lazy makes the repetition e (built by Repeat, Star, Plus or Opt) prefer
+// as few matches as possible, as e*? does. Other Exprs are returned as is.
+func Lazy(e Expr) Expr {
+	if r, ok := e.(repeat); ok {
+		r.lazy = !r.lazy
+		return r
+	}
+	return e
+}
+
+// Group captures the text matched by e. An empty name makes an unnamed
+// group; otherwise the group is named as (?P<name>e), and the name must
+// consist of letters, digits and underscores.
+func Group(name string, e Expr) Expr {
+	return group{name: name, sub: e}
+}
+
+type group struct {
+	name string
+	sub  Expr
+}
+
+func (g group) build(b *builder) (*syntax.Regexp, error) {
+	for _, r := range g.name {
+		if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return nil, fmt.Errorf("%w: group name %q", ErrInvalidExpr, g.name)
+		}
+	}
+	sub, err := b.buildExpr(g.sub)
+	if err != nil {
+		return nil, err
+	}
+	return &syntax.Regexp{Op: syntax.OpCapture, Flags: b.flags, Name: g.name, Sub: []*syntax.Regexp{sub}}, nil
+}
+
+// Fold makes the literals and classes of e match regardless of case, as
+// (?i:e).
+func Fold(e Expr) Expr {
+	return fold{sub: e}
+}
+
+type fold struct {
+	sub Expr
+}
+
+func (f fold) build(b *builder) (*syntax.Regexp, error) {
+	saved := b.flags
+	b.flags |= syntax.FoldCase
+	defer func() { b.flags = saved }()
+	return b.buildExpr(f.sub)
+}
+
+// Assertion is a zero-width assertion for Anchor.
+type Assertion uint8
+
+const (
+	// StartText matches at the start of the text, as \A.
+	StartText Assertion = iota
+	// EndText matches at the end of the text, as \z.
+	EndText
+	// StartLine matches at the start of the text or after a '\n', as (?m:^).
+	StartLine
+	// EndLine matches at the end of the text or before a '\n', as (?m:$).
+	EndLine
+	// WordBoundary matches between a word and a non-word char, as \b.
+	WordBoundary
+	// NoWordBoundary matches where WordBoundary does not, as \B.
+	NoWordBoundary
+)
+
+// assertionOps maps each Assertion to its syntax.Op.
+var assertionOps = [...]syntax.Op{
+	StartText:      syntax.OpBeginText,
+	EndText:        syntax.OpEndText,
+	StartLine:      syntax.OpBeginLine,
+	EndLine:        syntax.OpEndLine,
+	WordBoundary:   syntax.OpWordBoundary,
+	NoWordBoundary: syntax.OpNoWordBoundary,
+}
+
+// Anchor matches the empty string where the assertion a holds.
+func Anchor(a Assertion) Expr {
+	return anchor(a)
+}
+
+type anchor Assertion
+
+func (a anchor) build(b *builder) (*syntax.Regexp, error) {
+	if int(a) >= len(assertionOps) {
+		return nil, fmt.Errorf("%w: assertion %d", ErrInvalidExpr, a)
+	}
+	return &syntax.Regexp{Op: assertionOps[a], Flags: b.flags}, nil
+}
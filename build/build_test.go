@@ -0,0 +1,246 @@
+package build
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+	"regexp/syntax"
+	"slices"
+	"testing"
+
+	"github.com/coregx/coregex/meta"
+)
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		expr Expr
+		want string
+	}{
+		{Lit("a.b*"), `a\.b\*`},
+		{Lit(""), `(?:)`},
+		{Class(`a-z0-9_`), `[0-9_a-z]`},
+		{Class(`^\d`), `[^0-9]`},
+		{Class(`x`), `x`},
+		{Concat(Lit("a"), Class(`bc`), Lit("d")), `a[bc]d`},
+		{Concat(), `(?:)`},
+		{Concat(Lit("x")), `x`},
+		{Alt(Lit("foo"), Lit("bar")), `foo|bar`},
+		{Alt(), `[^\x00-\x{10FFFF}]`},
+		{Repeat(Lit("ab"), 2, 3), `(?:ab){2,3}`},
+		{Repeat(Class(`a`), 2, -1), `a{2,}`},
+		{Repeat(Class(`a`), 2, 2), `a{2}`},
+		{Star(Lit("a")), `a*`},
+		{Plus(Lit("a")), `a+`},
+		{Opt(Lit("a")), `a?`},
+		{Lazy(Star(Lit("a"))), `a*?`},
+		{Lazy(Lit("a")), `a`},
+		{Group("", Lit("a")), `(a)`},
+		{Group("word", Plus(Class(`\w`))), `(?P<word>[0-9A-Z_a-z]+)`},
+		{Fold(Lit("abc")), `(?i:abc)`},
+		{Concat(Anchor(StartText), Anchor(EndText)), `\A\z`},
+		{Concat(Anchor(StartLine), Anchor(EndLine)), `(?m:^$)`},
+		{Concat(Anchor(WordBoundary), Anchor(NoWordBoundary)), `\b\B`},
+		{Pattern(`\d+(x)`), `[0-9]+(x)`},
+	}
+	for _, tt := range tests {
+		got, err := String(tt.expr)
+		if err != nil {
+			t.Errorf("String(%v): %v", tt.want, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestBuildErrors(t *testing.T) {
+	var serr *syntax.Error
+	tests := []struct {
+		expr Expr
+		want error
+	}{
+		{nil, ErrInvalidExpr},
+		{Concat(Lit("a"), nil), ErrInvalidExpr},
+		{Repeat(Lit("a"), -1, 2), ErrInvalidExpr},
+		{Repeat(Lit("a"), 3, 2), ErrInvalidExpr},
+		{Repeat(Lit("a"), 0, 1001), ErrInvalidExpr},
+		{Group("a-b", Lit("a")), ErrInvalidExpr},
+		{Concat(Group("x", Lit("a")), Group("x", Lit("b"))), ErrInvalidExpr},
+		{Class(`a]b[c`), ErrInvalidExpr},
+		{Class(`a]|b`), ErrInvalidExpr},
+		{Anchor(Assertion(99)), ErrInvalidExpr},
+		{Repeat(Repeat(Lit("a"), 0, 100), 0, 100), ErrInvalidExpr},
+		{Repeat(Concat(Lit("b"), Plus(Repeat(Lit("a"), 50, 50))), 21, -1), ErrInvalidExpr},
+		{Repeat(Pattern(`(?:a{2}){100}`), 5, 10), ErrInvalidExpr},
+		{Concat(slices.Repeat([]Expr{Repeat(Class(`a-z`), 1000, 1000)}, 4000)...), ErrInvalidExpr},
+	}
+	for _, tt := range tests {
+		if _, err := Regexp(tt.expr); !errors.Is(err, tt.want) {
+			t.Errorf("Regexp(%#v) error = %v, want %v", tt.expr, err, tt.want)
+		}
+	}
+
+	// Nested repetitions up to the limit build.
+	for _, e := range []Expr{Repeat(Repeat(Lit("a"), 2, 2), 500, 500), Star(Repeat(Lit("a"), 1000, 1000))} {
+		if _, err := Regexp(e); err != nil {
+			t.Errorf("Regexp(%#v): %v", e, err)
+		}
+	}
+
+	for _, e := range []Expr{Class(`z-a`), Class(``), Pattern(`a(`)} {
+		if _, err := Regexp(e); !errors.As(err, &serr) {
+			t.Errorf("Regexp(%#v) error = %v, want a *syntax.Error", e, err)
+		}
+	}
+}
+
+func TestCaptureNumbering(t *testing.T) {
+	lib := NewLibrary()
+	lib.Define("pair", Concat(Group("", Class(`a-z`)), Group("", Class(`0-9`))))
+	e := Concat(Group("first", lib.Ref("pair")), Pattern(`-(x)-`), lib.Ref("pair"))
+
+	re, err := Regexp(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := re.String(), `(?P<first>([a-z])([0-9]))-(x)-([a-z])([0-9])`; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if got := re.MaxCap(); got != 6 {
+		t.Errorf("MaxCap() = %d, want 6", got)
+	}
+	if got, want := re.CapNames(), []string{"", "first", "", "", "", "", ""}; !reflect.DeepEqual(got, want) {
+		t.Errorf("CapNames() = %q, want %q", got, want)
+	}
+}
+
+func TestLibrary(t *testing.T) {
+	lib := NewLibrary()
+	lib.Define("ipv4", Concat(Repeat(Concat(lib.Ref("octet"), Lit(".")), 3, 3), lib.Ref("octet")))
+	e := Concat(Anchor(StartText), lib.Ref("ipv4"), Anchor(EndText))
+
+	// Refs are resolved when the pattern is built.
+	if _, err := Regexp(e); !errors.Is(err, ErrUndefined) {
+		t.Errorf("Regexp() error = %v, want ErrUndefined", err)
+	}
+	lib.Define("octet", Repeat(Class(`0-9`), 1, 3))
+	got, err := String(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `\A(?:[0-9]{1,3}\.){3}[0-9]{1,3}\z`; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	// Redefining a name changes the patterns built afterwards.
+	lib.Define("octet", Alt(Lit("0"), Concat(Class(`1-9`), Opt(Class(`0-9`)))))
+	got, _ = String(e)
+	if want := `\A(?:(?:0|[1-9][0-9]?)\.){3}(?:0|[1-9][0-9]?)\z`; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if e, ok := lib.Lookup("octet"); !ok || e == nil {
+		t.Error(`Lookup("octet") failed`)
+	}
+	if _, ok := lib.Lookup("nope"); ok {
+		t.Error(`Lookup("nope") succeeded`)
+	}
+
+	// A name may be used twice in a row, but not inside itself.
+	lib.Define("twice", Concat(lib.Ref("octet"), lib.Ref("octet")))
+	if _, err := Regexp(lib.Ref("twice")); err != nil {
+		t.Errorf("Regexp(twice): %v", err)
+	}
+	lib.Define("a", Concat(Lit("a"), Opt(lib.Ref("b"))))
+	lib.Define("b", Concat(Lit("b"), lib.Ref("a")))
+	if _, err := Regexp(lib.Ref("a")); !errors.Is(err, ErrRecursive) {
+		t.Errorf("Regexp(a) error = %v, want ErrRecursive", err)
+	}
+
+	// Refs are bound to their library.
+	other := NewLibrary()
+	other.Define("octet", Lit("x"))
+	if got, _ := String(other.Ref("octet")); got != "x" {
+		t.Errorf("other library: String() = %q, want %q", got, "x")
+	}
+}
+
+func TestRegexpWithFlags(t *testing.T) {
+	e := Concat(Lit("ab"), Star(Class(`c`)), Lazy(Opt(Lit("d"))))
+	re, err := RegexpWithFlags(e, syntax.Perl|syntax.FoldCase|syntax.NonGreedy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := re.String(), `(?i:abC*?d?)`; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+// TestCompileMatchesPattern checks that compiling a built expression finds
+// the same matches as compiling its pattern text, and as regexp.
+func TestCompileMatchesPattern(t *testing.T) {
+	hosts := Alt(Lit("example.com"), Lit("example.org"), Lit("test.example.com"))
+	lib := NewLibrary()
+	lib.Define("word", Plus(Class(`\w`)))
+	exprs := []Expr{
+		hosts,
+		Concat(Anchor(WordBoundary), hosts, Anchor(WordBoundary)),
+		Concat(Group("user", lib.Ref("word")), Lit("@"), Group("host", hosts)),
+		Fold(Concat(Lit("GET "), Plus(Class(`^ `)))),
+		Concat(Anchor(StartLine), Lit("x"), Repeat(Class(`0-9`), 2, 4), Anchor(EndLine)),
+		Lazy(Star(Lit("a"))),
+		Concat(Lit("a"), Alt(), Lit("b")),
+		Concat(Lit("a"), Concat(), Lit("b")),
+		Alt(Lit("é"), Lit("e")),
+	}
+	inputs := []string{
+		"",
+		"see example.com and test.example.com, not example.company",
+		"mail bob@example.org or ann@test.example.com",
+		"get /index.html HTTP/1.1",
+		"x12\nx12345\nx999",
+		"aaab",
+		"e ab",
+	}
+	for _, e := range exprs {
+		text, err := String(e)
+		if err != nil {
+			t.Fatal(err)
+		}
+		built, err := Compile(e, meta.DefaultConfig())
+		if err != nil {
+			t.Fatalf("Compile(%q): %v", text, err)
+		}
+		parsed, err := meta.Compile(text)
+		if err != nil {
+			t.Fatalf("meta.Compile(%q): %v", text, err)
+		}
+		std := regexp.MustCompile(text)
+		for _, in := range inputs {
+			want := std.FindAllStringSubmatchIndex(in, -1)
+			for _, engine := range []*meta.Engine{built, parsed} {
+				var got [][]int
+				for _, m := range engine.FindAllSubmatch([]byte(in), -1) {
+					var loc []int
+					for g := range m.NumCaptures() {
+						if span := m.GroupIndex(g); span != nil {
+							loc = append(loc, span...)
+						} else {
+							loc = append(loc, -1, -1)
+						}
+					}
+					got = append(got, loc)
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("%q on %q: got %v, want %v", text, in, got, want)
+				}
+			}
+		}
+	}
+
+	config := meta.DefaultConfig()
+	config.MaxRecursionDepth = 0
+	if _, err := Compile(hosts, config); err == nil {
+		t.Error("Compile with an invalid config succeeded")
+	}
+}
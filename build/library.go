@@ -0,0 +1,76 @@
+package build
+
+import (
+	"fmt"
+	"regexp/syntax"
+	"sync"
+)
+
+// Library is a set of named sub-patterns. Define names in it and use them
+// with Ref. A Library is safe for concurrent use.
+//
+// Example:
+//
+//	lib := build.NewLibrary()
+//	lib.Define("octet", build.Alt(
+//	    build.Concat(build.Lit("25"), build.Class(`0-5`)),
+//	    build.Concat(build.Lit("2"), build.Class(`0-4`), build.Class(`0-9`)),
+//	    build.Concat(build.Opt(build.Class(`01`)), build.Class(`0-9`), build.Opt(build.Class(`0-9`))),
+//	))
+//	lib.Define("ipv4", build.Concat(
+//	    build.Repeat(build.Concat(lib.Ref("octet"), build.Lit(".")), 3, 3),
+//	    lib.Ref("octet"),
+//	))
+//	re, err := coregex.CompileExpr(build.Group("ip", lib.Ref("ipv4")))
+type Library struct {
+	mu   sync.RWMutex
+	defs map[string]Expr
+}
+
+// NewLibrary returns an empty Library.
+func NewLibrary() *Library {
+	return &Library{defs: make(map[string]Expr)}
+}
+
+// Define sets the sub-pattern of name, replacing an earlier definition.
+// Patterns built afterwards use the new definition.
+func (l *Library) Define(name string, e Expr) {
+	l.mu.Lock()
+	l.defs[name] = e
+	l.mu.Unlock()
+}
+
+// Lookup returns the definition of name.
+func (l *Library) Lookup(name string) (Expr, bool) {
+	l.mu.RLock()
+	e, ok := l.defs[name]
+	l.mu.RUnlock()
+	return e, ok
+}
+
+// Ref matches the sub-pattern defined as name. The definition is looked up
+// when the pattern is built, not when Ref is called; building fails with
+// ErrUndefined if name is not defined then, and with ErrRecursive if the
+// definition refers back to name.
+func (l *Library) Ref(name string) Expr {
+	return libRef{lib: l, name: name}
+}
+
+// libRef is a reference to a definition of a Library.
+type libRef struct {
+	lib  *Library
+	name string
+}
+
+func (r libRef) build(b *builder) (*syntax.Regexp, error) {
+	e, ok := r.lib.Lookup(r.name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUndefined, r.name)
+	}
+	if b.active[r] {
+		return nil, fmt.Errorf("%w: %q", ErrRecursive, r.name)
+	}
+	b.active[r] = true
+	defer delete(b.active, r)
+	return b.buildExpr(e)
+}
@@ -0,0 +1,149 @@
+package coregex
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/coregx/coregex/meta"
+)
+
+// latin1 decodes b as Latin-1: byte i of b becomes rune U+00xx. It returns
+// the decoded string and the byte offset in it of each input offset.
+func latin1(b []byte) (string, []int) {
+	var sb strings.Builder
+	offsets := make([]int, len(b)+1)
+	for i, c := range b {
+		offsets[i] = sb.Len()
+		sb.WriteRune(rune(c))
+	}
+	offsets[len(b)] = sb.Len()
+	return sb.String(), offsets
+}
+
+// latin1Index maps stdlib offsets in the decoded string back to input bytes.
+func latin1Index(loc, offsets []int) []int {
+	if loc == nil {
+		return nil
+	}
+	back := make(map[int]int, len(offsets))
+	for i, o := range offsets {
+		back[o] = i
+	}
+	out := make([]int, len(loc))
+	for i, o := range loc {
+		if o < 0 {
+			out[i] = -1
+		} else {
+			out[i] = back[o]
+		}
+	}
+	return out
+}
+
+// TestCompileBytesMatchesLatin1 checks byte mode against stdlib regexp on the
+// Latin-1 decoding of the input, which has the same semantics by definition.
+func TestCompileBytesMatchesLatin1(t *testing.T) {
+	tests := []struct {
+		pattern string
+		input   string
+	}{
+		{`\xFF`, "ab\xffcd"},
+		{`\xFF+`, "a\xff\xff\xffb"},
+		{`\x89PNG\r\n\x1A\n(.{4})`, "junk\x89PNG\r\n\x1a\n\x00\x00\x00\x0dIHDR"},
+		{`.`, "\xc3\xa9"},
+		{`a.c`, "a\x80c"},
+		{`(?s)a.c`, "a\nc"},
+		{`[^a]+`, "aa\x80\xfe\nz"},
+		{`\W+`, "ab\xe9\xff cd"},
+		{`\D\d`, "\xff9"},
+		{`[\x80-\xBF]{2}`, "x\x80\xbfy"},
+		{`é`, "caf\xe9 caf\xc3\xa9"},
+		{`(?i)é`, "\xc9\xe9"},
+		{`(?i)ab\xE9`, "xxAB\xc9"},
+		{`(?i)k+`, "kKk"},
+		{`\pL+`, "1ab\xe9\xc0 \xd7"},
+		{`\bfoo\b`, "\xfffoo\xff"},
+		{`(a+)(\xFE|\xFF)`, "aa\xfe aaa\xff"},
+		{`^\x00\x01`, "\x00\x01\x02"},
+		{`\x01\x02$`, "\x00\x01\x02"},
+		{`(?m)^\xFF$`, "a\n\xff\nb"},
+		{`x*`, "\xff\xff"},
+		{`\x00[\x00-\xFF]{3}\x00`, strings.Repeat("\xaa", 200) + "\x00\x01\x02\x03\x00"},
+		{`(foo|ba\xE4r)\xFF`, "xx ba\xe4r\xff foo\xff"},
+		{`\p{Greek}`, "abc\xce\xb1"},
+	}
+
+	for _, tt := range tests {
+		re, err := CompileBytes(tt.pattern)
+		if err != nil {
+			t.Fatalf("CompileBytes(%q): %v", tt.pattern, err)
+		}
+		std := regexp.MustCompile(tt.pattern)
+		input := []byte(tt.input)
+		decoded, offsets := latin1(input)
+
+		want := latin1Index(std.FindStringSubmatchIndex(decoded), offsets)
+		if got := re.FindSubmatchIndex(input); !reflect.DeepEqual(got, want) {
+			t.Errorf("%q on %q: FindSubmatchIndex = %v, want %v", tt.pattern, tt.input, got, want)
+		}
+		if got := re.Match(input); got != (want != nil) {
+			t.Errorf("%q on %q: Match = %v, want %v", tt.pattern, tt.input, got, want != nil)
+		}
+
+		var wantAll [][]int
+		for _, loc := range std.FindAllStringIndex(decoded, -1) {
+			wantAll = append(wantAll, latin1Index(loc, offsets))
+		}
+		if got := re.FindAllIndex(input, -1); !reflect.DeepEqual(got, wantAll) {
+			t.Errorf("%q on %q: FindAllIndex = %v, want %v", tt.pattern, tt.input, got, wantAll)
+		}
+	}
+}
+
+func TestCompileBytesErrors(t *testing.T) {
+	for _, pattern := range []string{`中`, `a\x{100}`, `(?i)\x{212A}\x{100}`} {
+		_, err := CompileBytes(pattern)
+		if !errors.Is(err, meta.ErrNotByte) {
+			t.Errorf("CompileBytes(%q) err = %v, want ErrNotByte", pattern, err)
+		}
+	}
+
+	// The same patterns are valid UTF-8 mode patterns.
+	if _, err := Compile(`中`); err != nil {
+		t.Errorf("Compile: %v", err)
+	}
+}
+
+func TestCompileBytesDiffersFromUTF8(t *testing.T) {
+	input := []byte("caf\xc3\xa9")
+
+	// One UTF-8 character is two bytes.
+	if got := MustCompile(`f.$`).Find(input); string(got) != "f\xc3\xa9" {
+		t.Errorf("UTF-8 mode: f.$ found %q", got)
+	}
+	if got := MustCompileBytes(`f.$`).Find(input); got != nil {
+		t.Errorf("byte mode: f.$ found %q, want no match", got)
+	}
+	if got := MustCompileBytes(`f..$`).Find(input); !utf8.Valid(got) || string(got) != "f\xc3\xa9" {
+		t.Errorf("byte mode: f..$ found %q", got)
+	}
+}
+
+func TestCompileSetBytes(t *testing.T) {
+	config := meta.DefaultConfig()
+	config.Bytes = true
+	set, err := CompileSetWithConfig([]string{`\xFF\xFE`, `\x00+`, `zz`}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := set.Matches([]byte("a\xff\xfe\x00")), []int{0, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Matches = %v, want %v", got, want)
+	}
+	if got := set.Matches([]byte("\xc3\xbf\xc3\xbe")); got != nil {
+		t.Errorf("Matches(UTF-8 ÿþ) = %v, want nil", got)
+	}
+}
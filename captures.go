@@ -0,0 +1,189 @@
+package coregex
+
+import (
+	"iter"
+	"slices"
+)
+
+// Captures holds the positions of one match and its capture groups.
+//
+// A Captures is filled in place by the search that owns it: the value yielded
+// by AllSubmatch is reused for every match, so its contents are only valid
+// until the iteration continues. Copy what must be kept (for example with
+// Slots and append).
+type Captures struct {
+	haystack []byte
+	slots    []int // [start0, end0, start1, end1, ...], -1 for unset groups
+	names    []string
+}
+
+// newCaptures returns a Captures sized for the groups of r.
+func (r *Regex) newCaptures(haystack []byte) *Captures {
+	return &Captures{
+		haystack: haystack,
+		slots:    make([]int, r.engine.NumCaptures()*2),
+		names:    r.engine.SubexpNames(),
+	}
+}
+
+// Len returns the number of groups, including group 0 (the whole match).
+func (c *Captures) Len() int {
+	return len(c.slots) / 2
+}
+
+// Start returns the start offset of the whole match.
+func (c *Captures) Start() int {
+	return c.slots[0]
+}
+
+// End returns the end offset of the whole match.
+func (c *Captures) End() int {
+	return c.slots[1]
+}
+
+// Index returns the offsets of group i, or -1, -1 if the group did not take
+// part in the match or does not exist.
+func (c *Captures) Index(i int) (start, end int) {
+	if i < 0 || i >= c.Len() {
+		return -1, -1
+	}
+	return c.slots[2*i], c.slots[2*i+1]
+}
+
+// Group returns the text of group i as a subslice of the haystack, or nil if
+// the group did not take part in the match or does not exist.
+func (c *Captures) Group(i int) []byte {
+	start, end := c.Index(i)
+	if start < 0 {
+		return nil
+	}
+	return c.haystack[start:end:end]
+}
+
+// Name returns the text of the first group with the given name, or nil if
+// that group did not take part in the match or there is no such group.
+func (c *Captures) Name(name string) []byte {
+	if name == "" {
+		return nil
+	}
+	for i, n := range c.names {
+		if n == name {
+			return c.Group(i)
+		}
+	}
+	return nil
+}
+
+// Slots returns the match as [start0, end0, start1, end1, ...] in the layout
+// of FindSubmatchIndex. The slice is the Captures' own buffer and is
+// overwritten by the next match.
+func (c *Captures) Slots() []int {
+	return c.slots
+}
+
+// AllSubmatch returns an iterator over all successive non-overlapping matches
+// in b with their capture groups.
+//
+// Zero allocation per match: a single Captures is allocated for the whole
+// iteration and overwritten in place for every match, so the yielded pointer
+// is only valid until the loop body returns.
+//
+// Matches and empty-match handling are the same as FindAllSubmatchIndex.
+//
+// Example:
+//
+//	re := coregex.MustCompile(`(?P<key>\w+)=(?P<value>\w+)`)
+//	for c := range re.AllSubmatch([]byte("a=1 b=2")) {
+//	    fmt.Printf("%s -> %s\n", c.Name("key"), c.Name("value"))
+//	}
+//	// Output:
+//	// a -> 1
+//	// b -> 2
+func (r *Regex) AllSubmatch(b []byte) iter.Seq[*Captures] {
+	return func(yield func(*Captures) bool) {
+		caps := r.newCaptures(b)
+		buf := func() []int { return caps.slots }
+		for range r.allSubmatchSlots(b, -1, buf) {
+			if !yield(caps) {
+				return
+			}
+		}
+	}
+}
+
+// AppendAllSubmatchIndex appends the slots of all successive matches in b to
+// dst and returns the extended slice. Each match adds 2*(NumSubexp()+1) ints
+// in the layout of FindSubmatchIndex, so match k is
+// dst[k*stride : (k+1)*stride] with stride = 2*(NumSubexp()+1).
+//
+// Zero-allocation when dst has sufficient capacity. If n > 0, it appends at
+// most n matches. If n <= 0, it appends all matches.
+//
+// Example:
+//
+//	re := coregex.MustCompile(`(\w)=(\d)`)
+//	slots := re.AppendAllSubmatchIndex(nil, []byte("a=1 b=2"), -1)
+//	// slots = [0 3 0 1 2 3 4 7 4 5 6 7]
+func (r *Regex) AppendAllSubmatchIndex(dst []int, b []byte, n int) []int {
+	if n == 0 {
+		return dst
+	}
+	stride := r.engine.NumCaptures() * 2
+	// Each match is written straight into the spare capacity of dst.
+	next := func() []int {
+		dst = slices.Grow(dst, stride)
+		return dst[len(dst) : len(dst)+stride]
+	}
+	for range r.allSubmatchSlots(b, n, next) {
+		dst = dst[:len(dst)+stride]
+	}
+	return dst
+}
+
+// AppendAllStringSubmatchIndex is the string version of
+// AppendAllSubmatchIndex.
+func (r *Regex) AppendAllStringSubmatchIndex(dst []int, s string, n int) []int {
+	return r.AppendAllSubmatchIndex(dst, stringToBytes(s), n)
+}
+
+// allSubmatchSlots runs the FindAll loop over b. Every search writes into the
+// buffer returned by slots, which is yielded on a match. With n > 0 it stops
+// after n matches.
+func (r *Regex) allSubmatchSlots(b []byte, n int, slots func() []int) iter.Seq[[]int] {
+	return func(yield func([]int) bool) {
+		searcher := r.engine.NewSearcher(len(b))
+		defer searcher.Close()
+		count := 0
+		pos := 0
+		lastMatchEnd := -1
+		for pos <= len(b) && (n <= 0 || count < n) {
+			buf := slots()
+			if !searcher.FindSubmatchSlotsAt(b, pos, buf) {
+				return
+			}
+			start, end := buf[0], buf[1]
+			// Skip empty matches at the position where a non-empty match just ended.
+			// This matches Go stdlib behavior.
+			//nolint:gocritic // badCond: intentional - checking empty match at lastMatchEnd
+			if start == end && start == lastMatchEnd {
+				pos++
+				continue
+			}
+			count++
+			if !yield(buf) {
+				return
+			}
+			// Advance as FindAllSubmatchIndex does: past an empty match, so
+			// that it is not found again, and to the end of a non-empty one.
+			lastMatchEnd = end
+			switch {
+			case start == end:
+				pos = end + 1
+			case end > pos:
+				pos = end
+			default:
+				pos++
+			}
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package coregex
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+var capturesTests = []struct {
+	pattern string
+	input   string
+}{
+	{`(\w+)@(\w+)\.(\w+)`, "a@b.c x@y.z nope"},
+	{`(?P<key>\w+)=(?P<value>\d+)?`, "a=1 b= c=33"},
+	{`(a)|(b)`, "abba"},
+	{`(a*)`, "baaab"},
+	{`x*`, "axxb"},
+	{`^(\d+)`, "123 456"},
+	{`(\d+)$`, "123 456"},
+	{`\b(\w)(\w*)\b`, "hello big world"},
+	{`(foo|foobar)(baz)?`, "foobarbaz foobaz"},
+	{`([a-z]+)(\d+)`, "abc123 de4 5"},
+	{`(?i)(hello)`, "HeLLo hello"},
+	{`(.)(.)`, "日本語"},
+	{`no match`, "haystack"},
+	{`\b`, "aaa"},
+	{`(\b)`, "ab cd"},
+	{`\s*`, "  lots   of  space "},
+	{`(x*)|(y)`, "ayb"},
+	{`(a*)$`, "baa"},
+}
+
+// TestAllSubmatch checks AllSubmatch against FindAllSubmatchIndex.
+func TestAllSubmatch(t *testing.T) {
+	for _, tt := range capturesTests {
+		re := MustCompile(tt.pattern)
+		input := []byte(tt.input)
+		want := re.FindAllSubmatchIndex(input, -1)
+
+		var got [][]int
+		for c := range re.AllSubmatch(input) {
+			got = append(got, slices.Clone(c.Slots()))
+
+			if c.Len() != re.NumSubexp()+1 {
+				t.Errorf("%q: Len = %d, want %d", tt.pattern, c.Len(), re.NumSubexp()+1)
+			}
+			if start, end := c.Index(0); start != c.Start() || end != c.End() {
+				t.Errorf("%q: Index(0) = %d, %d, want %d, %d", tt.pattern, start, end, c.Start(), c.End())
+			}
+			for i := 0; i < c.Len(); i++ {
+				start, _ := c.Index(i)
+				if g := c.Group(i); (g == nil) != (start < 0) {
+					t.Errorf("%q: Group(%d) = %q with start %d", tt.pattern, i, g, start)
+				}
+			}
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%q on %q: AllSubmatch = %v, want %v", tt.pattern, tt.input, got, want)
+		}
+	}
+}
+
+func TestAllSubmatchBreak(t *testing.T) {
+	re := MustCompile(`(\d)`)
+	count := 0
+	for range re.AllSubmatch([]byte("1 2 3 4")) {
+		count++
+		if count == 2 {
+			break
+		}
+	}
+	if count != 2 {
+		t.Errorf("count = %d, want 2", count)
+	}
+}
+
+func TestCapturesName(t *testing.T) {
+	re := MustCompile(`(?P<key>\w+)=(?P<value>\d+)?`)
+	var pairs []string
+	for c := range re.AllSubmatch([]byte("a=1 b= c=33")) {
+		pairs = append(pairs, string(c.Name("key"))+":"+string(c.Name("value")))
+		if c.Name("missing") != nil || c.Name("") != nil {
+			t.Error("Name of an unknown group is not nil")
+		}
+	}
+	if want := []string{"a:1", "b:", "c:33"}; !reflect.DeepEqual(pairs, want) {
+		t.Errorf("pairs = %v, want %v", pairs, want)
+	}
+}
+
+func TestAppendAllSubmatchIndex(t *testing.T) {
+	for _, tt := range capturesTests {
+		re := MustCompile(tt.pattern)
+		stride := 2 * (re.NumSubexp() + 1)
+
+		for _, n := range []int{-1, 0, 1, 2} {
+			var want []int
+			for _, m := range re.FindAllStringSubmatchIndex(tt.input, n) {
+				want = append(want, m...)
+			}
+			prefix := []int{42}
+			got := re.AppendAllStringSubmatchIndex(prefix, tt.input, n)
+			if got[0] != 42 {
+				t.Errorf("%q: prefix overwritten", tt.pattern)
+			}
+			if got = got[1:]; len(got) == 0 {
+				got = nil
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("%q on %q, n=%d: AppendAllStringSubmatchIndex = %v, want %v",
+					tt.pattern, tt.input, n, got, want)
+			}
+			if len(got)%stride != 0 {
+				t.Errorf("%q: len %d is not a multiple of %d", tt.pattern, len(got), stride)
+			}
+		}
+	}
+}
+
+func TestAllSubmatchAllocs(t *testing.T) {
+	re := MustCompile(`(\w+)=(\d+)`)
+	input := []byte("a=1 bb=22 ccc=333 dddd=4444")
+
+	allocs := testing.AllocsPerRun(100, func() {
+		for range re.AllSubmatch(input) {
+		}
+	})
+	// The Captures and its slot buffer are allocated once per iteration.
+	if allocs > 3 {
+		t.Errorf("AllSubmatch allocs = %v, want at most 3", allocs)
+	}
+
+	buf := make([]int, 0, 64)
+	allocs = testing.AllocsPerRun(100, func() {
+		buf = re.AppendAllSubmatchIndex(buf[:0], input, -1)
+	})
+	if allocs != 0 {
+		t.Errorf("AppendAllSubmatchIndex allocs = %v, want 0", allocs)
+	}
+}
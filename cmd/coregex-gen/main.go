@@ -0,0 +1,74 @@
+// Command coregex-gen generates standalone Go matchers from regular
+// expressions, for use with go generate.
+//
+// Usage:
+//
+//	coregex-gen [-pkg name] [-o file] [-max-states n] Name=pattern...
+//
+// For each Name=pattern argument the generated file has a
+// MatchName([]byte) bool and a FindName([]byte) (start, end int) function,
+// implemented as a DFA state machine that needs no coregex at run time.
+// Both run in time linear in the input length.
+// The package name defaults to $GOPACKAGE, which go generate sets, and the
+// output to standard output. See package codegen for the details.
+//
+// Example:
+//
+//	//go:generate coregex-gen -o tokens_gen.go Ident=[A-Za-z_]\w* Number=[0-9]+(\.[0-9]+)?
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/coregx/coregex/codegen"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "coregex-gen:", err)
+		os.Exit(2)
+	}
+}
+
+// run parses args, generates the matchers and writes them to the -o file
+// or, without one, to stdout.
+func run(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("coregex-gen", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	pkg := flags.String("pkg", os.Getenv("GOPACKAGE"), "package name of the generated file")
+	out := flags.String("o", "", "output file (default stdout)")
+	maxStates := flags.Int("max-states", 0, "maximum DFA states per pattern (default 10000)")
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("%w\nusage: coregex-gen [-pkg name] [-o file] [-max-states n] Name=pattern...", err)
+	}
+	if *pkg == "" {
+		return errors.New("no package name: use -pkg or run from go generate")
+	}
+	if flags.NArg() == 0 {
+		return errors.New("no patterns: want Name=pattern arguments")
+	}
+
+	matchers := make([]codegen.Matcher, 0, flags.NArg())
+	for _, arg := range flags.Args() {
+		name, pattern, ok := strings.Cut(arg, "=")
+		if !ok {
+			return fmt.Errorf("argument %q is not Name=pattern", arg)
+		}
+		matchers = append(matchers, codegen.Matcher{Name: name, Pattern: pattern})
+	}
+
+	src, err := codegen.Generate(codegen.Config{Package: *pkg, MaxStates: *maxStates}, matchers...)
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		_, err = stdout.Write(src)
+		return err
+	}
+	return os.WriteFile(*out, src, 0o644)
+}
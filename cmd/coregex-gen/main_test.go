@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	var stdout bytes.Buffer
+	if err := run([]string{"-pkg", "lexer", `Ident=[A-Za-z_]\w*`, "Eq=a=b"}, &stdout); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"package lexer", "func MatchIdent(", "func FindEq(", "`a=b`"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("output has no %q", want)
+		}
+	}
+
+	out := filepath.Join(t.TempDir(), "gen.go")
+	if err := run([]string{"-pkg", "lexer", "-o", out, `Ident=[A-Za-z_]\w*`, "Eq=a=b"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, stdout.Bytes()) {
+		t.Error("-o wrote different code than stdout")
+	}
+
+	t.Setenv("GOPACKAGE", "fromenv")
+	stdout.Reset()
+	if err := run([]string{"A=a"}, &stdout); err != nil || !strings.Contains(stdout.String(), "package fromenv") {
+		t.Errorf("GOPACKAGE not used (err %v)", err)
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	t.Setenv("GOPACKAGE", "")
+	tests := [][]string{
+		{"A=a"},                           // no package
+		{"-pkg", "p"},                     // no patterns
+		{"-pkg", "p", "Aa"},               // not Name=pattern
+		{"-pkg", "p", "a=a"},              // lower-case name
+		{"-pkg", "p", "A=("},              // bad pattern
+		{"-pkg", "p", "-max-states", "x"}, // bad flag
+		{"-pkg", "p", "-unknown", "A=a"},  // unknown flag
+	}
+	for _, args := range tests {
+		if err := run(args, &bytes.Buffer{}); err == nil {
+			t.Errorf("run(%q): no error", args)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/coregx/coregex"
+)
+
+// benchReport is the report of the bench subcommand.
+type benchReport struct {
+	Pattern string        `json:"pattern"`
+	File    string        `json:"file"`
+	Bytes   int           `json:"bytes"`
+	Results []benchResult `json:"results"`
+
+	// Speedup is the coregex throughput divided by the regexp one.
+	Speedup float64 `json:"speedup"`
+}
+
+// benchResult is the throughput of one engine finding all matches.
+type benchResult struct {
+	Engine     string  `json:"engine"`
+	Matches    int     `json:"matches"`
+	Iterations int     `json:"iterations"`
+	NsPerOp    int64   `json:"ns_per_op"`
+	MBPerSec   float64 `json:"mb_per_sec"`
+}
+
+func benchFlags(fs *flag.FlagSet) func([]string) (report, error) {
+	duration := fs.Duration("time", time.Second, "how long to run each engine")
+	return func(args []string) (report, error) {
+		re, err := coregex.Compile(args[0])
+		if err != nil {
+			return nil, err
+		}
+		std, err := regexp.Compile(args[0])
+		if err != nil {
+			return nil, fmt.Errorf("regexp: %w", err)
+		}
+		data, err := os.ReadFile(args[1])
+		if err != nil {
+			return nil, err
+		}
+		r := &benchReport{Pattern: args[0], File: args[1], Bytes: len(data)}
+		r.Results = []benchResult{
+			bench("coregex", data, *duration, func(b []byte) int { return len(re.FindAllIndex(b, -1)) }),
+			bench("regexp", data, *duration, func(b []byte) int { return len(std.FindAllIndex(b, -1)) }),
+		}
+		if r.Results[1].MBPerSec > 0 {
+			r.Speedup = r.Results[0].MBPerSec / r.Results[1].MBPerSec
+		}
+		return r, nil
+	}
+}
+
+// bench runs findAll on data repeatedly for at least d, and at least once.
+func bench(engine string, data []byte, d time.Duration, findAll func([]byte) int) benchResult {
+	res := benchResult{Engine: engine}
+	start := time.Now()
+	var elapsed time.Duration
+	for res.Iterations == 0 || elapsed < d {
+		res.Matches = findAll(data)
+		res.Iterations++
+		elapsed = time.Since(start)
+	}
+	res.NsPerOp = elapsed.Nanoseconds() / int64(res.Iterations)
+	if elapsed > 0 {
+		res.MBPerSec = float64(len(data)) * float64(res.Iterations) / 1e6 / elapsed.Seconds()
+	}
+	return res
+}
+
+func (r *benchReport) writeText(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "pattern: %s\nfile:    %s (%d bytes)\n\n", strconv.Quote(r.Pattern), r.File, r.Bytes); err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "engine\tmatches\titerations\ttime/op\tMB/s\t")
+	for _, res := range r.Results {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%v\t%.1f\t\n",
+			res.Engine, res.Matches, res.Iterations, time.Duration(res.NsPerOp), res.MBPerSec)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "\nspeedup: %.1fx\n", r.Speedup)
+	if err == nil && r.Results[0].Matches != r.Results[1].Matches {
+		_, err = fmt.Fprintln(w, "warning: the engines found different numbers of matches")
+	}
+	return err
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/coregx/coregex/dfa/lazy"
+	"github.com/coregx/coregex/meta"
+)
+
+// dfaReport is the report of the dfa subcommand.
+type dfaReport struct {
+	Pattern        string `json:"pattern"`
+	File           string `json:"file"`
+	Bytes          int    `json:"bytes"`
+	ByteClasses    int    `json:"byte_classes"`
+	CacheCapacity  int    `json:"cache_capacity"`
+	MaxCacheClears int    `json:"max_cache_clears"`
+	Searches       int    `json:"searches"`
+	Matches        int    `json:"matches"`
+	States         int    `json:"states"`
+	PeakStates     int    `json:"peak_states"`
+	MemoryBytes    int    `json:"memory_bytes"`
+	CacheClears    int    `json:"cache_clears"`
+
+	// ClearLimitReached reports whether the cache was cleared
+	// MaxCacheClears times, after which a full cache makes the lazy DFA
+	// fall back to the PikeVM.
+	ClearLimitReached bool `json:"clear_limit_reached"`
+
+	// Growth samples the cache each time its state count doubles or it is
+	// cleared, and once at the end.
+	Growth []dfaSample `json:"growth"`
+}
+
+// dfaSample is the cache state after the search that ended at Offset.
+type dfaSample struct {
+	Offset int `json:"offset"`
+	States int `json:"states"`
+	Clears int `json:"clears"`
+}
+
+func dfaFlags(fs *flag.FlagSet) func([]string) (report, error) {
+	config := lazy.DefaultConfig()
+	fs.IntVar(&config.CacheCapacityBytes, "cache", config.CacheCapacityBytes, "lazy DFA cache capacity in bytes")
+	fs.IntVar(&config.MaxCacheClears, "max-clears", config.MaxCacheClears, "cache clears before falling back to the PikeVM")
+	return func(args []string) (report, error) {
+		engine, err := meta.Compile(args[0])
+		if err != nil {
+			return nil, err
+		}
+		data, err := os.ReadFile(args[1])
+		if err != nil {
+			return nil, err
+		}
+		d, err := lazy.CompileWithConfig(engine.NFA(), config)
+		if err != nil {
+			return nil, err
+		}
+		r := &dfaReport{
+			Pattern:        args[0],
+			File:           args[1],
+			Bytes:          len(data),
+			ByteClasses:    d.AlphabetLen(),
+			CacheCapacity:  config.CacheCapacityBytes,
+			MaxCacheClears: config.MaxCacheClears,
+		}
+		r.scan(d, data)
+		return r, nil
+	}
+}
+
+// scan finds all matches of d in data, like FindAll does, sampling the
+// cache after each search.
+func (r *dfaReport) scan(d *lazy.DFA, data []byte) {
+	cache := d.NewCache()
+	var s, last dfaSample
+	for at := 0; at <= len(data); {
+		end := d.SearchAt(cache, data, at)
+		r.Searches++
+		s = dfaSample{Offset: end, States: cache.Size(), Clears: cache.ClearCount()}
+		if end < 0 {
+			s.Offset = len(data)
+		}
+		r.PeakStates = max(r.PeakStates, s.States)
+		if len(r.Growth) == 0 || s.Clears != last.Clears || s.States >= 2*last.States {
+			r.Growth = append(r.Growth, s)
+			last = s
+		}
+		if end < 0 {
+			break
+		}
+		r.Matches++
+		if end > at {
+			at = end
+		} else {
+			at++
+		}
+	}
+	if s != last {
+		r.Growth = append(r.Growth, s)
+	}
+	r.States = cache.Size()
+	r.MemoryBytes = cache.MemoryUsage()
+	r.CacheClears = cache.ClearCount()
+	r.ClearLimitReached = r.CacheClears >= r.MaxCacheClears
+}
+
+func (r *dfaReport) writeText(w io.Writer) error {
+	limit := ""
+	if r.ClearLimitReached {
+		limit = " (limit reached: a full cache falls back to the PikeVM)"
+	}
+	_, err := fmt.Fprintf(w, `pattern:      %s
+file:         %s (%d bytes)
+byte classes: %d
+cache:        %d bytes, %d clears allowed
+searches:     %d (%d matches)
+states:       %d (peak %d, %d bytes)
+cache clears: %d%s
+
+`, strconv.Quote(r.Pattern), r.File, r.Bytes, r.ByteClasses, r.CacheCapacity, r.MaxCacheClears,
+		r.Searches, r.Matches, r.States, r.PeakStates, r.MemoryBytes, r.CacheClears, limit)
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "offset\tstates\tclears\t")
+	for _, s := range r.Growth {
+		fmt.Fprintf(tw, "%d\t%d\t%d\t\n", s.Offset, s.States, s.Clears)
+	}
+	return tw.Flush()
+}
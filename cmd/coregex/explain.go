@@ -0,0 +1,119 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/coregx/coregex/literal"
+	"github.com/coregx/coregex/meta"
+)
+
+// explainReport is the report of the explain subcommand.
+type explainReport struct {
+	Pattern        string         `json:"pattern"`
+	Strategy       string         `json:"strategy"`
+	Reason         string         `json:"reason"`
+	NFAStates      int            `json:"nfa_states"`
+	RuneNFAStates  int            `json:"rune_nfa_states"`
+	ASCIINFAStates int            `json:"ascii_nfa_states"`
+	Prefixes       []literalInfo  `json:"prefixes"`
+	Suffixes       []literalInfo  `json:"suffixes"`
+	Inner          []literalInfo  `json:"inner"`
+	Prefilter      *prefilterInfo `json:"prefilter,omitempty"`
+	OnePass        bool           `json:"onepass"`
+	ReverseDFA     bool           `json:"reverse_dfa"`
+	Engines        []string       `json:"engines"`
+}
+
+// prefilterInfo is the meta.PrefilterPlan of a pattern.
+type prefilterInfo struct {
+	Kind       string `json:"kind"`
+	IsFast     bool   `json:"fast"`
+	IsComplete bool   `json:"complete"`
+	HeapBytes  int    `json:"heap_bytes"`
+}
+
+// literalInfo is a literal of a literal.Seq.
+type literalInfo struct {
+	Text     string `json:"text"`
+	Complete bool   `json:"complete"`
+}
+
+func explainFlags(*flag.FlagSet) func([]string) (report, error) {
+	return func(args []string) (report, error) {
+		engine, err := meta.Compile(args[0])
+		if err != nil {
+			return nil, err
+		}
+		plan := engine.Explain()
+		r := &explainReport{
+			Pattern:        args[0],
+			Strategy:       plan.Strategy.String(),
+			Reason:         plan.Reason,
+			NFAStates:      plan.NFAStates,
+			RuneNFAStates:  plan.RuneNFAStates,
+			ASCIINFAStates: plan.ASCIINFAStates,
+			Prefixes:       literals(plan.Prefixes),
+			Suffixes:       literals(plan.Suffixes),
+			Inner:          literals(plan.Inner),
+			OnePass:        plan.OnePass,
+			ReverseDFA:     plan.ReverseDFA,
+			Engines:        plan.Engines,
+		}
+		if pf := plan.Prefilter; pf.Kind != "" {
+			r.Prefilter = &prefilterInfo{Kind: pf.Kind, IsFast: pf.IsFast, IsComplete: pf.IsComplete, HeapBytes: pf.HeapBytes}
+		}
+		return r, nil
+	}
+}
+
+// literals returns the literals of seq, which may be nil.
+func literals(seq *literal.Seq) []literalInfo {
+	lits := make([]literalInfo, seq.Len())
+	for i := range lits {
+		lit := seq.Get(i)
+		lits[i] = literalInfo{Text: string(lit.Bytes), Complete: lit.Complete}
+	}
+	return lits
+}
+
+func (r *explainReport) writeText(w io.Writer) error {
+	prefilter := "none"
+	if pf := r.Prefilter; pf != nil {
+		prefilter = fmt.Sprintf("%s (fast=%v complete=%v, %d bytes)", pf.Kind, pf.IsFast, pf.IsComplete, pf.HeapBytes)
+	}
+	_, err := fmt.Fprintf(w, `pattern:     %s
+strategy:    %s
+reason:      %s
+NFA states:  %d (rune %d, ASCII %d)
+prefixes:    %s
+suffixes:    %s
+inner:       %s
+prefilter:   %s
+onepass:     %v
+reverse DFA: %v
+engines:     %s
+`, strconv.Quote(r.Pattern), r.Strategy, r.Reason, r.NFAStates, r.RuneNFAStates, r.ASCIINFAStates,
+		literalsText(r.Prefixes), literalsText(r.Suffixes), literalsText(r.Inner), prefilter,
+		r.OnePass, r.ReverseDFA, strings.Join(r.Engines, ", "))
+	return err
+}
+
+// literalsText formats lits as quoted strings, marking the complete ones
+// with a trailing '!'.
+func literalsText(lits []literalInfo) string {
+	if len(lits) == 0 {
+		return "none"
+	}
+	texts := make([]string, len(lits))
+	for i, lit := range lits {
+		texts[i] = strconv.Quote(lit.Text)
+		if lit.Complete {
+			texts[i] += "!"
+		}
+	}
+	return strings.Join(texts, " ")
+}
@@ -0,0 +1,100 @@
+// Command coregex shows how coregex compiles and runs a pattern, to find out
+// why a pattern is slow.
+//
+// Usage:
+//
+//	coregex explain [-json] PATTERN
+//	coregex nfa [-json] [-dot] PATTERN
+//	coregex dfa [-json] [-cache bytes] [-max-clears n] PATTERN FILE
+//	coregex bench [-json] [-time d] PATTERN FILE
+//
+// The subcommands are:
+//
+//	explain  the strategy and why it was chosen, the prefix and suffix
+//	         literals, the prefilter and the engines that were built
+//	nfa      the states of the compiled NFA; with -dot, its graph in
+//	         Graphviz DOT format
+//	dfa      how the lazy DFA cache grows and is cleared while scanning FILE
+//	bench    the throughput of finding all matches in FILE, against regexp
+//
+// With -json the report is printed as JSON instead of text. explain and
+// nfa show what COREGEX_DEBUG=2 prints at compile time, without the
+// environment variable.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+const usage = `usage:
+	coregex explain [-json] PATTERN
+	coregex nfa [-json] [-dot] PATTERN
+	coregex dfa [-json] [-cache bytes] [-max-clears n] PATTERN FILE
+	coregex bench [-json] [-time d] PATTERN FILE`
+
+// report is the result of a subcommand, printed as text or JSON.
+type report interface {
+	writeText(w io.Writer) error
+}
+
+// command runs a subcommand on its arguments after the flags.
+type command struct {
+	// nargs is the number of arguments: PATTERN or PATTERN FILE.
+	nargs int
+
+	// flags registers the subcommand's own flags and returns the function
+	// that runs it.
+	flags func(fs *flag.FlagSet) func(args []string) (report, error)
+}
+
+var commands = map[string]command{
+	"explain": {1, explainFlags},
+	"nfa":     {1, nfaFlags},
+	"dfa":     {2, dfaFlags},
+	"bench":   {2, benchFlags},
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "coregex:", err)
+		os.Exit(2)
+	}
+}
+
+// run runs the subcommand named by args[0] and writes its report to stdout.
+func run(args []string, stdout io.Writer) error {
+	if len(args) == 0 {
+		return errors.New("no subcommand\n" + usage)
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown subcommand %q\n%s", args[0], usage)
+	}
+	flags := flag.NewFlagSet("coregex "+args[0], flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	jsonOut := flags.Bool("json", false, "print the report as JSON")
+	runCmd := cmd.flags(flags)
+	if err := flags.Parse(args[1:]); err != nil {
+		return fmt.Errorf("%w\n%s", err, usage)
+	}
+	if flags.NArg() != cmd.nargs {
+		return fmt.Errorf("%s wants %d arguments, got %d\n%s", args[0], cmd.nargs, flags.NArg(), usage)
+	}
+
+	r, err := runCmd(flags.Args())
+	if err != nil {
+		return err
+	}
+	if *jsonOut {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		return enc.Encode(r)
+	}
+	return r.writeText(stdout)
+}
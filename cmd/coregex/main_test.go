@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeInput writes a haystack for the dfa and bench subcommands.
+func writeInput(t *testing.T) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "input.txt")
+	data := strings.Repeat("the quick brown fox jumps over the lazy dog\nmail bob@example.com\n", 200)
+	if err := os.WriteFile(file, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestRunText(t *testing.T) {
+	file := writeInput(t)
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{[]string{"explain", `\w+@\w+\.com`}, []string{"strategy:    UseReverseSuffix", `suffixes:    ".com"`, "reverse DFA: true", "engines:     PikeVM"}},
+		{[]string{"explain", `foo|bar`}, []string{`prefixes:    "foo"! "bar"!`, "prefilter:   Teddy (SSSE3 slim) (fast=true complete=true"}},
+		{[]string{"nfa", `(a)[a-c0-9]`}, []string{"NFA{states:", "Capture(1 start)", "Sparse 2 transitions", "\t['0'-'9'] -> "}},
+		{[]string{"nfa", "-dot", `(a)[a-c0-9]`}, []string{"digraph nfa {", `label="capture 1 start"`, `label="[0-9a-c]"`}},
+		{[]string{"dfa", `\w+x`, file}, []string{"searches:     401 (400 matches)", "cache clears: 0\n", "offset  states  clears"}},
+		{[]string{"dfa", "-cache", "1000", "-max-clears", "2", `\w{2,12}o\w{2}`, file}, []string{"cache clears: 2 (limit reached"}},
+		{[]string{"bench", "-time", "1ms", `o\w+`, file}, []string{"coregex     1200", "regexp     1200", "speedup:"}},
+	}
+	for _, tt := range tests {
+		var stdout bytes.Buffer
+		if err := run(tt.args, &stdout); err != nil {
+			t.Errorf("run(%q): %v", tt.args, err)
+			continue
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(stdout.String(), want) {
+				t.Errorf("run(%q) output has no %q:\n%s", tt.args, want, stdout.String())
+			}
+		}
+	}
+}
+
+func TestRunJSON(t *testing.T) {
+	file := writeInput(t)
+	run := func(args ...string) map[string]any {
+		t.Helper()
+		var stdout bytes.Buffer
+		if err := run(args, &stdout); err != nil {
+			t.Fatalf("run(%q): %v", args, err)
+		}
+		var v map[string]any
+		if err := json.Unmarshal(stdout.Bytes(), &v); err != nil {
+			t.Fatalf("run(%q): %v\n%s", args, err, stdout.String())
+		}
+		return v
+	}
+
+	explain := run("explain", "-json", "hello")
+	prefilter, _ := explain["prefilter"].(map[string]any)
+	if explain["strategy"] != "UseDFA" || prefilter["kind"] != "memmem" || prefilter["complete"] != true {
+		t.Errorf("explain -json = %v", explain)
+	}
+	nfa := run("nfa", "-json", "[ab]c")
+	if states, _ := nfa["states"].([]any); len(states) == 0 {
+		t.Errorf("nfa -json = %v", nfa)
+	}
+	graph := run("nfa", "-dot", "-json", "[ab]c")
+	if edges, _ := graph["edges"].([]any); graph["name"] != "nfa" || len(edges) == 0 {
+		t.Errorf("nfa -dot -json = %v", graph)
+	}
+	dfa := run("dfa", "-json", "fox", file)
+	if dfa["matches"] != 200.0 || dfa["cache_clears"] != 0.0 {
+		t.Errorf("dfa -json = %v", dfa)
+	}
+	if growth, _ := dfa["growth"].([]any); len(growth) == 0 {
+		t.Errorf("dfa -json has no growth samples: %v", dfa)
+	}
+	bench := run("bench", "-json", "-time", "1ms", "fox", file)
+	if results, _ := bench["results"].([]any); len(results) != 2 {
+		t.Errorf("bench -json = %v", bench)
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	file := writeInput(t)
+	tests := [][]string{
+		{},                                // no subcommand
+		{"unknown", "a"},                  // unknown subcommand
+		{"explain"},                       // no pattern
+		{"explain", "a", "b"},             // too many arguments
+		{"explain", "("},                  // bad pattern
+		{"nfa", "-unknown", "a"},          // unknown flag
+		{"dfa", "a"},                      // no file
+		{"dfa", "a", file + ".missing"},   // missing file
+		{"dfa", "-cache", "x", "a", file}, // bad flag value
+		{"bench", "(", file},              // bad pattern
+	}
+	for _, args := range tests {
+		if err := run(args, &bytes.Buffer{}); err == nil {
+			t.Errorf("run(%q): no error", args)
+		}
+	}
+}
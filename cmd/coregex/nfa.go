@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/coregx/coregex/meta"
+	"github.com/coregx/coregex/nfa"
+)
+
+// nfaReport is the report of the nfa subcommand.
+type nfaReport struct {
+	Pattern string     `json:"pattern"`
+	Summary string     `json:"summary"`
+	States  []nfaState `json:"states"`
+}
+
+// nfaState is an NFA state. Text is its State.String; the transitions of
+// Sparse states, which Text only counts, are listed in Transitions.
+type nfaState struct {
+	ID          nfa.StateID      `json:"id"`
+	Kind        string           `json:"kind"`
+	Text        string           `json:"text"`
+	Transitions []transitionInfo `json:"transitions,omitempty"`
+}
+
+// transitionInfo is a transition of a Sparse state.
+type transitionInfo struct {
+	Lo   byte        `json:"lo"`
+	Hi   byte        `json:"hi"`
+	Next nfa.StateID `json:"next"`
+	Text string      `json:"text"`
+}
+
+// nfaGraph is the report of the nfa subcommand with -dot: the NFA graph as
+// DOT, or with -json as the JSON of nfa.NFA.WriteJSON.
+type nfaGraph struct {
+	n *nfa.NFA
+}
+
+func nfaFlags(fs *flag.FlagSet) func([]string) (report, error) {
+	dot := fs.Bool("dot", false, "print the NFA as a Graphviz DOT graph")
+	return func(args []string) (report, error) {
+		engine, err := meta.Compile(args[0])
+		if err != nil {
+			return nil, err
+		}
+		n := engine.NFA()
+		if *dot {
+			return nfaGraph{n}, nil
+		}
+		r := &nfaReport{Pattern: args[0], Summary: n.String(), States: make([]nfaState, 0, n.States())}
+		for it := n.Iter(); it.HasNext(); {
+			s := it.Next()
+			state := nfaState{ID: s.ID(), Kind: s.Kind().String(), Text: s.String()}
+			for _, t := range s.Transitions() {
+				state.Transitions = append(state.Transitions, transitionInfo{Lo: t.Lo, Hi: t.Hi, Next: t.Next, Text: t.String()})
+			}
+			r.States = append(r.States, state)
+		}
+		return r, nil
+	}
+}
+
+func (r *nfaReport) writeText(w io.Writer) error {
+	if _, err := fmt.Fprintln(w, r.Summary); err != nil {
+		return err
+	}
+	for _, s := range r.States {
+		if _, err := fmt.Fprintln(w, s.Text); err != nil {
+			return err
+		}
+		for _, t := range s.Transitions {
+			if _, err := fmt.Fprintf(w, "\t%s\n", t.Text); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (g nfaGraph) writeText(w io.Writer) error {
+	return g.n.WriteDOT(w)
+}
+
+func (g nfaGraph) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	err := g.n.WriteJSON(&b)
+	return b.Bytes(), err
+}
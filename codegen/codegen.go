@@ -0,0 +1,364 @@
+// Package codegen generates standalone Go matchers from regular expressions.
+//
+// Like re2go and Ragel, it moves the regex engine from run time to build
+// time: each pattern is compiled to an NFA, determinized and minimized into
+// a dense DFA (dfa/dense), and written out as a switch-based state machine.
+// The generated file imports nothing, so a tokenizer or validator whose
+// patterns are fixed pays neither compilation nor DFA cache setup and runs
+// code the branch predictor can learn.
+//
+// For a Matcher named Ident, the generated file has:
+//
+//	// MatchIdent reports whether b contains a match of ...
+//	func MatchIdent(b []byte) bool
+//
+//	// FindIdent returns the start and end of the leftmost-first match ...
+//	func FindIdent(b []byte) (start, end int)
+//
+// with the semantics of the meta engine's IsMatch and FindIndices.
+//
+// Match and Find run in time linear in len(b). Find scans forward to the
+// end of the match, then back to its start with a reverse DFA in which ^,
+// $, \b and \B are mirrored, so assertions cost no extra pass.
+//
+// Generate is the library entry point; cmd/coregex-gen wraps it for use
+// with go generate:
+//
+//	//go:generate coregex-gen -o matchers_gen.go Ident=[A-Za-z_]\w* Number=\d+
+//
+// Patterns use the Perl syntax of regexp. Look-around, Unicode word
+// boundaries and patterns whose DFA exceeds Config.MaxStates are rejected.
+package codegen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/token"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/coregx/coregex/dfa/dense"
+	"github.com/coregx/coregex/nfa"
+)
+
+// Header is the first line of every generated file. It marks the file as
+// generated for go vet, linters and code review tools.
+const Header = "// Code generated by coregex-gen. DO NOT EDIT."
+
+var (
+	// ErrInvalidName is returned by Generate for a matcher name that is not
+	// an exported Go identifier, or that is used twice.
+	ErrInvalidName = errors.New("codegen: invalid matcher name")
+
+	// ErrInvalidPackage is returned by Generate for a package name that is
+	// not a Go identifier.
+	ErrInvalidPackage = errors.New("codegen: invalid package name")
+)
+
+// Matcher is a pattern to generate functions for.
+type Matcher struct {
+	// Name is appended to Match and Find to name the generated functions.
+	// It must start with an upper-case letter.
+	Name string
+
+	// Pattern is the regular expression, in the syntax of regexp.
+	Pattern string
+}
+
+// Config configures Generate.
+type Config struct {
+	// Package is the package clause of the generated file.
+	Package string
+
+	// MaxStates is the maximum number of states of each DFA, counted before
+	// minimization. Zero means the dense package default (10,000).
+	MaxStates int
+}
+
+// Generate returns the gofmt-formatted source of a Go file with MatchXxx
+// and FindXxx functions for each matcher, in order.
+//
+// Errors from compiling a pattern or building its DFA wrap the nfa or
+// dense errors, for example dense.ErrTooLarge.
+func Generate(config Config, matchers ...Matcher) ([]byte, error) {
+	if !token.IsIdentifier(config.Package) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidPackage, config.Package)
+	}
+	g := &generator{}
+	g.printf("%s\n\npackage %s\n", Header, config.Package)
+
+	seen := make(map[string]bool, len(matchers))
+	for _, m := range matchers {
+		if !isMatcherName(m.Name) || seen[m.Name] {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidName, m.Name)
+		}
+		seen[m.Name] = true
+		if err := g.matcher(m, config); err != nil {
+			return nil, fmt.Errorf("codegen: %s: %w", m.Name, err)
+		}
+	}
+
+	src, err := format.Source(g.buf.Bytes())
+	if err != nil {
+		// A bug in the generator, not in the input.
+		return nil, fmt.Errorf("codegen: formatting generated code: %w", err)
+	}
+	return src, nil
+}
+
+// isMatcherName reports whether name can follow Match and Find in an
+// exported identifier.
+func isMatcherName(name string) bool {
+	if name == "" || !token.IsIdentifier(name) {
+		return false
+	}
+	r := []rune(name)[0]
+	return unicode.IsUpper(r)
+}
+
+// program is what is generated for one matcher.
+type program struct {
+	name    string
+	pattern string
+
+	fwd *dense.DFA
+	rev *dense.DFA // nil if anchored
+
+	// anchored: every match starts at 0, no reverse search is needed.
+	anchored bool
+}
+
+// matcher compiles m and writes its functions.
+func (g *generator) matcher(m Matcher, config Config) error {
+	n, err := nfa.NewDefaultCompiler().Compile(m.Pattern)
+	if err != nil {
+		return err
+	}
+	dc := dense.DefaultConfig()
+	if config.MaxStates > 0 {
+		dc.MaxStates = config.MaxStates
+	}
+	p := &program{name: m.Name, pattern: m.Pattern, anchored: n.IsAlwaysAnchored()}
+	if p.fwd, err = dense.Build(n, dc); err != nil {
+		return err
+	}
+	if !p.anchored {
+		dc.BreakAtMatch = false
+		if p.rev, err = dense.Build(nfa.ReverseAnchoredLook(n), dc); err != nil {
+			return err
+		}
+	}
+
+	g.matchFunc(p)
+	g.findFunc(p)
+	g.scanFunc("fwd"+p.name, p.fwd, false)
+	if p.rev != nil {
+		g.scanFunc("rev"+p.name, p.rev, true)
+	}
+	return nil
+}
+
+// generator accumulates the generated source.
+type generator struct {
+	buf bytes.Buffer
+}
+
+func (g *generator) printf(format string, args ...any) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// quotePattern returns pattern as a raw string for doc comments, or quoted
+// if it contains a backquote or a line break.
+func quotePattern(pattern string) string {
+	if strings.ContainsAny(pattern, "`\r\n") {
+		return strconv.Quote(pattern)
+	}
+	return "`" + pattern + "`"
+}
+
+// matchFunc writes MatchXxx, an earliest-match forward scan.
+func (g *generator) matchFunc(p *program) {
+	g.printf("\n// Match%s reports whether b contains a match of the regular expression\n// %s.\n",
+		p.name, quotePattern(p.pattern))
+	g.printf("func Match%s(b []byte) bool {\n", p.name)
+	g.printf("return fwd%s(b, 0, %d, true) >= 0\n}\n", p.name, stateIndex(p.fwd, p.fwd.StartState(false, -1)))
+}
+
+// findFunc writes FindXxx: the forward scan finds the match end, then the
+// reverse DFA finds the start.
+func (g *generator) findFunc(p *program) {
+	g.printf("\n// Find%s returns the start and end of the leftmost-first match of the\n// regular expression %s in b, or -1, -1 if there is none.\n",
+		p.name, quotePattern(p.pattern))
+	g.printf("func Find%s(b []byte) (start, end int) {\n", p.name)
+	g.printf("end = fwd%s(b, 0, %d, false)\n", p.name, stateIndex(p.fwd, p.fwd.StartState(false, -1)))
+	g.printf("if end < 0 {\nreturn -1, -1\n}\n")
+	if p.anchored {
+		g.printf("return 0, end\n}\n")
+		return
+	}
+	// The leftmost-first match starts at the smallest position from which
+	// the reverse DFA reaches end. Its look-behind is the byte after end.
+	g.printf("s := %d\n", stateIndex(p.rev, p.rev.StartState(true, -1)))
+	g.startSwitch(p.rev, "end < len(b)", "b[end]")
+	g.printf("return rev%s(b, end, s), end\n}\n", p.name)
+}
+
+// startSwitch writes code that, if cond holds, sets s to the anchored
+// start state of d for the context byte expr. Nothing is written if the
+// context does not matter, as for patterns without ^, $, \b or \B.
+func (g *generator) startSwitch(d *dense.DFA, cond, expr string) {
+	text := stateIndex(d, d.StartState(true, -1))
+	lf := stateIndex(d, d.StartState(true, '\n'))
+	word := stateIndex(d, d.StartState(true, 'a'))
+	other := stateIndex(d, d.StartState(true, ' '))
+	if lf == text && word == text && other == text {
+		return
+	}
+	g.printf("if %s {\n", cond)
+	g.printf("switch c := %s; {\n", expr)
+	g.printf("case c == '\\n':\ns = %d\n", lf)
+	g.printf("case %s:\ns = %d\n", wordCond, word)
+	g.printf("default:\ns = %d\n}\n}\n", other)
+}
+
+// wordCond is true for the ASCII word bytes, which \b and \B look at.
+const wordCond = "'0' <= c && c <= '9' || 'A' <= c && c <= 'Z' || c == '_' || 'a' <= c && c <= 'z'"
+
+// stateIndex returns the generated number of state sid of d. The numbering
+// keeps the dense layout: 0 is the dead state and the match states follow
+// it, so the scan loop tests both with one comparison.
+func stateIndex(d *dense.DFA, sid dense.StateID) int {
+	return int(sid) / d.AlphabetLen()
+}
+
+// scanFunc writes the scan loop of d. Forward scans run from at to the end
+// of b and return the end of the last match (or, if earliest, the first),
+// or -1. Reverse scans run from end back to 0 and return the smallest match
+// start, or -1.
+func (g *generator) scanFunc(name string, d *dense.DFA, reverse bool) {
+	states := d.States()
+	maxMatch := 0
+	for i := 1; i < states; i++ {
+		if d.IsMatchState(dense.StateID(i * d.AlphabetLen())) {
+			maxMatch = i
+		}
+	}
+
+	if reverse {
+		g.printf("\n// %s runs the reverse DFA from state s back over b[:end] and returns\n// the start of the longest match ending at end, or -1.\n", name)
+		g.printf("func %s(b []byte, end, s int) int {\n", name)
+		g.printf("last := -1\n")
+		g.printf("for i := end - 1; i >= 0; i-- {\n")
+	} else {
+		g.printf("\n// %s runs the forward DFA from state s over b[at:] and returns the end\n// of the last match, or with earliest of the first, or -1.\n", name)
+		g.printf("func %s(b []byte, at, s int, earliest bool) int {\n", name)
+		g.printf("last := -1\n")
+		g.printf("for i := at; i < len(b); i++ {\n")
+	}
+	g.printf("switch s {\n")
+	for i := 1; i < states; i++ {
+		g.printf("case %d:\n", i)
+		g.transitions(d, dense.StateID(i*d.AlphabetLen()))
+	}
+	g.printf("}\n")
+
+	if maxMatch == 0 {
+		g.printf("if s == 0 {\nreturn last\n}\n")
+	} else {
+		g.printf("if s <= %d {\n", maxMatch)
+		g.printf("if s == 0 {\nreturn last\n}\n")
+		if reverse {
+			g.printf("last = i + 1\n")
+		} else {
+			g.printf("last = i\nif earliest {\nreturn last\n}\n")
+		}
+		g.printf("}\n")
+	}
+	g.printf("}\n")
+
+	var eoi []string
+	for i := 1; i < states; i++ {
+		if d.IsMatchAtEOI(dense.StateID(i * d.AlphabetLen())) {
+			eoi = append(eoi, strconv.Itoa(i))
+		}
+	}
+	if len(eoi) > 0 {
+		end := "len(b)"
+		if reverse {
+			end = "0"
+		}
+		g.printf("switch s {\ncase %s:\nreturn %s\n}\n", strings.Join(eoi, ", "), end)
+	}
+	g.printf("return last\n}\n")
+}
+
+// transitions writes the transitions of state sid: a switch over byte
+// ranges, in the order of their first byte, with the dead state as default.
+func (g *generator) transitions(d *dense.DFA, sid dense.StateID) {
+	type run struct {
+		lo, hi byte
+		next   int
+	}
+	var runs []run
+	for b := 0; b < 256; b++ {
+		next := stateIndex(d, d.Next(sid, byte(b)))
+		if len(runs) > 0 && runs[len(runs)-1].next == next && int(runs[len(runs)-1].hi) == b-1 {
+			runs[len(runs)-1].hi = byte(b)
+			continue
+		}
+		runs = append(runs, run{lo: byte(b), hi: byte(b), next: next})
+	}
+	if len(runs) == 1 {
+		g.printf("s = %d\n", runs[0].next)
+		return
+	}
+
+	// Group the runs by target state, keeping first-appearance order.
+	var order []int
+	conds := make(map[int][]string)
+	toDead := false
+	for _, r := range runs {
+		if r.next == 0 {
+			toDead = true
+			continue
+		}
+		if _, ok := conds[r.next]; !ok {
+			order = append(order, r.next)
+		}
+		conds[r.next] = append(conds[r.next], rangeCond(r.lo, r.hi))
+	}
+	g.printf("switch c := b[i]; {\n")
+	for _, next := range order {
+		g.printf("case %s:\ns = %d\n", strings.Join(conds[next], ", "), next)
+	}
+	if toDead {
+		g.printf("default:\ns = 0\n")
+	}
+	g.printf("}\n")
+}
+
+// rangeCond returns a condition on c for the byte range [lo, hi].
+func rangeCond(lo, hi byte) string {
+	switch {
+	case lo == hi:
+		return "c == " + byteLit(lo)
+	case lo == 0:
+		return "c <= " + byteLit(hi)
+	case hi == 0xFF:
+		return "c >= " + byteLit(lo)
+	default:
+		return byteLit(lo) + " <= c && c <= " + byteLit(hi)
+	}
+}
+
+// byteLit returns a Go literal for b: a rune literal for printable ASCII,
+// hex otherwise.
+func byteLit(b byte) string {
+	if b >= 0x20 && b < 0x7F {
+		return strconv.QuoteRune(rune(b))
+	}
+	return fmt.Sprintf("0x%02X", b)
+}
@@ -0,0 +1,103 @@
+package codegen
+
+import (
+	"bytes"
+	"errors"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/coregx/coregex/dfa/dense"
+)
+
+func TestGenerate(t *testing.T) {
+	src, err := Generate(Config{Package: "lexer"},
+		Matcher{Name: "Ident", Pattern: `[A-Za-z_]\w*`},
+		Matcher{Name: "Word", Pattern: `\bfoo\b`},
+		Matcher{Name: "Start", Pattern: `^ab`},
+		Matcher{Name: "Quote", Pattern: "a`b"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(src, []byte(Header+"\n")) {
+		t.Errorf("source does not start with %q", Header)
+	}
+	file, err := parser.ParseFile(token.NewFileSet(), "gen.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("generated code does not parse: %v\n%s", err, src)
+	}
+	if file.Name.Name != "lexer" {
+		t.Errorf("package %s, want lexer", file.Name.Name)
+	}
+	if len(file.Imports) != 0 {
+		t.Errorf("generated code imports %d packages, want none", len(file.Imports))
+	}
+	for _, fn := range []string{"MatchIdent", "FindIdent", "fwdIdent", "revIdent", "MatchWord", "FindWord", "fwdWord", "revWord", "MatchStart", "FindStart", "MatchQuote"} {
+		if file.Scope.Lookup(fn) == nil {
+			t.Errorf("generated code has no %s", fn)
+		}
+	}
+	// Anchored patterns need no start search.
+	if file.Scope.Lookup("revStart") != nil {
+		t.Error("generated code has revStart")
+	}
+	if !strings.Contains(string(src), `"a`+"`"+`b"`) {
+		t.Error("a pattern with a backquote is not quoted in the doc comment")
+	}
+
+	again, err := Generate(Config{Package: "lexer"},
+		Matcher{Name: "Ident", Pattern: `[A-Za-z_]\w*`},
+		Matcher{Name: "Word", Pattern: `\bfoo\b`},
+		Matcher{Name: "Start", Pattern: `^ab`},
+		Matcher{Name: "Quote", Pattern: "a`b"},
+	)
+	if err != nil || !bytes.Equal(again, src) {
+		t.Errorf("Generate is not deterministic (err %v)", err)
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   Config
+		matchers []Matcher
+		want     error
+	}{
+		{"package", Config{Package: "my-pkg"}, []Matcher{{"A", `a`}}, ErrInvalidPackage},
+		{"empty name", Config{Package: "p"}, []Matcher{{"", `a`}}, ErrInvalidName},
+		{"lower-case name", Config{Package: "p"}, []Matcher{{"ident", `a`}}, ErrInvalidName},
+		{"not an identifier", Config{Package: "p"}, []Matcher{{"A-B", `a`}}, ErrInvalidName},
+		{"duplicate name", Config{Package: "p"}, []Matcher{{"A", `a`}, {"A", `b`}}, ErrInvalidName},
+		{"too large", Config{Package: "p", MaxStates: 50}, []Matcher{{"A", `[01]*1[01]{10}`}}, dense.ErrTooLarge},
+		{"look-ahead", Config{Package: "p"}, []Matcher{{"A", `a(?=b)`}}, dense.ErrUnsupported},
+	}
+	for _, tt := range tests {
+		if _, err := Generate(tt.config, tt.matchers...); !errors.Is(err, tt.want) {
+			t.Errorf("%s: error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+	if _, err := Generate(Config{Package: "p"}, Matcher{"A", `a(`}); err == nil {
+		t.Error("invalid pattern: no error")
+	}
+}
+
+func TestRangeCond(t *testing.T) {
+	tests := []struct {
+		lo, hi byte
+		want   string
+	}{
+		{'a', 'a', `c == 'a'`},
+		{0, '/', `c <= '/'`},
+		{0xC0, 0xFF, `c >= 0xC0`},
+		{'0', '9', `'0' <= c && c <= '9'`},
+		{'\n', '\n', `c == 0x0A`},
+		{'\'', '\'', `c == '\''`},
+	}
+	for _, tt := range tests {
+		if got := rangeCond(tt.lo, tt.hi); got != tt.want {
+			t.Errorf("rangeCond(%#x, %#x) = %s, want %s", tt.lo, tt.hi, got, tt.want)
+		}
+	}
+}
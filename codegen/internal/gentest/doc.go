@@ -0,0 +1,5 @@
+// Package gentest holds matchers generated by cmd/coregex-gen, which its
+// tests compare with the meta engine.
+package gentest
+
+//go:generate go run ../../../cmd/coregex-gen -o matchers_gen.go Ident=[A-Za-z_]\w* Number=[0-9]+(\.[0-9]+)? Alt=a|ab|abc NonGreedy=a+?b*? Stars=x* Email=\w+@\w+\.(com|org) Word=\bfoo\b Line=(?m)^\w+$ Anchored=^ab+c? Unicode=héllo|wörld|\p{Greek}+ Dot=a.c Repeat=(?:ab|cd){2,3} Suffix=\w+$ Inner=\Bo+\B Hash=(?m)^#\w* Digits=\b\d+\b|x\b
@@ -0,0 +1,120 @@
+package gentest
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coregx/coregex/codegen"
+	"github.com/coregx/coregex/meta"
+)
+
+// generated lists the matchers of the go:generate directive in doc.go with
+// the generated functions.
+var generated = []struct {
+	codegen.Matcher
+	match func([]byte) bool
+	find  func([]byte) (int, int)
+}{
+	{codegen.Matcher{Name: "Ident", Pattern: `[A-Za-z_]\w*`}, MatchIdent, FindIdent},
+	{codegen.Matcher{Name: "Number", Pattern: `[0-9]+(\.[0-9]+)?`}, MatchNumber, FindNumber},
+	{codegen.Matcher{Name: "Alt", Pattern: `a|ab|abc`}, MatchAlt, FindAlt},
+	{codegen.Matcher{Name: "NonGreedy", Pattern: `a+?b*?`}, MatchNonGreedy, FindNonGreedy},
+	{codegen.Matcher{Name: "Stars", Pattern: `x*`}, MatchStars, FindStars},
+	{codegen.Matcher{Name: "Email", Pattern: `\w+@\w+\.(com|org)`}, MatchEmail, FindEmail},
+	{codegen.Matcher{Name: "Word", Pattern: `\bfoo\b`}, MatchWord, FindWord},
+	{codegen.Matcher{Name: "Line", Pattern: `(?m)^\w+$`}, MatchLine, FindLine},
+	{codegen.Matcher{Name: "Anchored", Pattern: `^ab+c?`}, MatchAnchored, FindAnchored},
+	{codegen.Matcher{Name: "Unicode", Pattern: `héllo|wörld|\p{Greek}+`}, MatchUnicode, FindUnicode},
+	{codegen.Matcher{Name: "Dot", Pattern: `a.c`}, MatchDot, FindDot},
+	{codegen.Matcher{Name: "Repeat", Pattern: `(?:ab|cd){2,3}`}, MatchRepeat, FindRepeat},
+	{codegen.Matcher{Name: "Suffix", Pattern: `\w+$`}, MatchSuffix, FindSuffix},
+	{codegen.Matcher{Name: "Inner", Pattern: `\Bo+\B`}, MatchInner, FindInner},
+	{codegen.Matcher{Name: "Hash", Pattern: `(?m)^#\w*`}, MatchHash, FindHash},
+	{codegen.Matcher{Name: "Digits", Pattern: `\b\d+\b|x\b`}, MatchDigits, FindDigits},
+}
+
+var inputs = []string{
+	"",
+	"a",
+	"abc abd ab",
+	"x xx xxx",
+	"_id2 = 3.14 + 42.",
+	"mail bob@example.com or eve@test.org.",
+	"foo food xfoo foo_ foo",
+	"line one\nline\ntwo words\n",
+	"abbbc ab",
+	"hello héllo wörld αβγ δ",
+	"a😀c abc a\nc a\xffc",
+	"ababab cdabcd abcdabcdab",
+	"\xff\xfe invalid \xc3",
+	"#tag a#b\n #no\n#\nfoo boo x1 2x x 12 x",
+}
+
+func TestGeneratedMatchesMeta(t *testing.T) {
+	for _, g := range generated {
+		engine, err := meta.Compile(g.Pattern)
+		if err != nil {
+			t.Fatalf("meta.Compile(%q): %v", g.Pattern, err)
+		}
+		for _, in := range inputs {
+			h := []byte(in)
+			if got, want := g.match(h), engine.IsMatch(h); got != want {
+				t.Errorf("Match%s(%q) = %v, want %v", g.Name, in, got, want)
+			}
+			// Every suffix, so that matches are found at many positions.
+			for at := range len(h) + 1 {
+				start, end := g.find(h[at:])
+				wantStart, wantEnd, found := engine.FindIndices(h[at:])
+				if !found {
+					wantStart, wantEnd = -1, -1
+				}
+				if start != wantStart || end != wantEnd {
+					t.Errorf("Find%s(%q) = (%d, %d), want (%d, %d)", g.Name, in[at:], start, end, wantStart, wantEnd)
+				}
+			}
+		}
+	}
+}
+
+func TestGeneratedLinear(t *testing.T) {
+	// The match of \w+$ is the last byte, and every position before it
+	// starts a run of word bytes that fails only at the '!'.
+	elapsed := func(n int) time.Duration {
+		input := []byte(strings.Repeat("a", n) + "!b")
+		best := time.Duration(1 << 62)
+		for range 3 {
+			start := time.Now()
+			if s, e := FindSuffix(input); s != n+1 || e != n+2 {
+				t.Fatalf("FindSuffix = (%d, %d), want (%d, %d)", s, e, n+1, n+2)
+			}
+			best = min(best, time.Since(start))
+		}
+		return best
+	}
+	small, large := elapsed(4<<10), elapsed(16<<10)
+	// Linear time grows 4x; quadratic 16x.
+	if large > 8*small+5*time.Millisecond {
+		t.Errorf("%v for 4 KB, %v for 16 KB: not linear", small, large)
+	}
+}
+
+func TestGeneratedUpToDate(t *testing.T) {
+	matchers := make([]codegen.Matcher, len(generated))
+	for i, g := range generated {
+		matchers[i] = g.Matcher
+	}
+	want, err := codegen.Generate(codegen.Config{Package: "gentest"}, matchers...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile("matchers_gen.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Error("matchers_gen.go is out of date: run go generate")
+	}
+}
@@ -0,0 +1,124 @@
+package coregex
+
+import (
+	"context"
+
+	"github.com/coregx/coregex/meta"
+)
+
+// ErrSearchAborted is matched by errors.Is for every error returned by a
+// *Context method whose search was stopped by cancellation or by the budget.
+var ErrSearchAborted = meta.ErrSearchAborted
+
+// ErrBudgetExhausted is the cause of a search stopped because the budget set
+// with WithSearchBudget ran out.
+var ErrBudgetExhausted = meta.ErrBudgetExhausted
+
+// SearchAbortedError is the error type returned by aborted *Context searches.
+// Its Cause is ctx.Err() or ErrBudgetExhausted.
+type SearchAbortedError = meta.SearchAbortedError
+
+// WithSearchBudget returns a copy of ctx that limits each *Context search run
+// with it to work engine steps (one per byte scanned by the DFA, more when the
+// NFA has to run). The search stops as soon as it exceeds them, however small
+// the budget. Zero or negative means unlimited.
+//
+// Example:
+//
+//	ctx := coregex.WithSearchBudget(r.Context(), 16*int64(len(body)))
+//	ok, err := re.MatchContext(ctx, body)
+func WithSearchBudget(ctx context.Context, work int64) context.Context {
+	return meta.WithSearchBudget(ctx, work)
+}
+
+// MatchContext is like Match but gives up when ctx is done or its search
+// budget runs out, returning an error that matches ErrSearchAborted.
+//
+// Cancellation is checked every few KB of input. When ctx can never be done
+// and carries no budget (context.Background()), this is exactly Match.
+// Otherwise the search runs on the lazy DFA, which finds where the match
+// ends, and the NFA only around the match, without the literal fast paths of
+// Match, so it can be much slower where those apply.
+//
+// Example:
+//
+//	ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+//	defer cancel()
+//	ok, err := re.MatchContext(ctx, input)
+//	if errors.Is(err, coregex.ErrSearchAborted) {
+//	    // timed out
+//	}
+func (r *Regex) MatchContext(ctx context.Context, b []byte) (bool, error) {
+	return r.engine.IsMatchContext(ctx, b)
+}
+
+// MatchStringContext is like MatchContext but for a string.
+func (r *Regex) MatchStringContext(ctx context.Context, s string) (bool, error) {
+	return r.engine.IsMatchContext(ctx, stringToBytes(s))
+}
+
+// FindContext is like Find but interruptible, see MatchContext.
+func (r *Regex) FindContext(ctx context.Context, b []byte) ([]byte, error) {
+	start, end, found, err := r.engine.FindIndicesContext(ctx, b, 0)
+	if err != nil || !found {
+		return nil, err
+	}
+	return b[start:end], nil
+}
+
+// FindStringContext is like FindString but interruptible, see MatchContext.
+func (r *Regex) FindStringContext(ctx context.Context, s string) (string, error) {
+	start, end, found, err := r.engine.FindIndicesContext(ctx, stringToBytes(s), 0)
+	if err != nil || !found {
+		return "", err
+	}
+	return s[start:end], nil
+}
+
+// FindIndexContext is like FindIndex but interruptible, see MatchContext.
+func (r *Regex) FindIndexContext(ctx context.Context, b []byte) ([]int, error) {
+	start, end, found, err := r.engine.FindIndicesContext(ctx, b, 0)
+	if err != nil || !found {
+		return nil, err
+	}
+	return []int{start, end}, nil
+}
+
+// FindStringIndexContext is like FindStringIndex but interruptible, see
+// MatchContext.
+func (r *Regex) FindStringIndexContext(ctx context.Context, s string) ([]int, error) {
+	return r.FindIndexContext(ctx, stringToBytes(s))
+}
+
+// FindSubmatchContext is like FindSubmatch but interruptible, see
+// MatchContext.
+func (r *Regex) FindSubmatchContext(ctx context.Context, b []byte) ([][]byte, error) {
+	match, err := r.engine.FindSubmatchContext(ctx, b, 0)
+	if err != nil || match == nil {
+		return nil, err
+	}
+	return match.AllGroups(), nil
+}
+
+// FindSubmatchIndexContext is like FindSubmatchIndex but interruptible, see
+// MatchContext.
+func (r *Regex) FindSubmatchIndexContext(ctx context.Context, b []byte) ([]int, error) {
+	match, err := r.engine.FindSubmatchContext(ctx, b, 0)
+	if err != nil || match == nil {
+		return nil, err
+	}
+
+	numGroups := match.NumCaptures()
+	result := make([]int, numGroups*2)
+	for i := 0; i < numGroups; i++ {
+		idx := match.GroupIndex(i)
+		if len(idx) >= 2 {
+			result[i*2] = idx[0]
+			result[i*2+1] = idx[1]
+		} else {
+			result[i*2] = -1
+			result[i*2+1] = -1
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,56 @@
+package coregex
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRegexContextMethods(t *testing.T) {
+	re := MustCompile(`(\w+)@(\w+)\.com`)
+	input := "mail bob@example.com now"
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	if ok, err := re.MatchStringContext(ctx, input); err != nil || !ok {
+		t.Errorf("MatchStringContext = %v, %v", ok, err)
+	}
+	if got, err := re.FindStringContext(ctx, input); err != nil || got != re.FindString(input) {
+		t.Errorf("FindStringContext = %q, %v", got, err)
+	}
+	if got, err := re.FindStringIndexContext(ctx, input); err != nil || !reflect.DeepEqual(got, re.FindStringIndex(input)) {
+		t.Errorf("FindStringIndexContext = %v, %v", got, err)
+	}
+	if got, err := re.FindSubmatchIndexContext(ctx, []byte(input)); err != nil || !reflect.DeepEqual(got, re.FindSubmatchIndex([]byte(input))) {
+		t.Errorf("FindSubmatchIndexContext = %v, %v", got, err)
+	}
+	if got, err := re.FindSubmatchContext(ctx, []byte("nothing")); err != nil || got != nil {
+		t.Errorf("FindSubmatchContext(no match) = %q, %v", got, err)
+	}
+
+	// Background context: the regular search, never aborted.
+	if got, err := re.FindContext(context.Background(), []byte(input)); err != nil || string(got) != "bob@example.com" {
+		t.Errorf("FindContext(Background) = %q, %v", got, err)
+	}
+}
+
+func TestRegexContextAborted(t *testing.T) {
+	re := MustCompile(`(x+x+)+y`)
+	input := []byte(strings.Repeat("x", 1<<20))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := re.MatchContext(ctx, input); !errors.Is(err, ErrSearchAborted) {
+		t.Errorf("cancelled: err = %v, want ErrSearchAborted", err)
+	}
+
+	ctx = WithSearchBudget(context.Background(), 1<<16)
+	loc, err := re.FindIndexContext(ctx, input)
+	if loc != nil || !errors.Is(err, ErrSearchAborted) || !errors.Is(err, ErrBudgetExhausted) {
+		t.Errorf("budget: FindIndexContext = %v, %v", loc, err)
+	}
+}
@@ -0,0 +1,98 @@
+package dense
+
+import (
+	"errors"
+
+	"github.com/coregx/coregex/internal/conv"
+	"github.com/coregx/coregex/internal/wire"
+)
+
+// errInvalidTables is returned by UnmarshalBinary for tables that do not fit
+// together.
+var errInvalidTables = errors.New("dense: inconsistent DFA tables")
+
+// AppendBinary implements encoding.BinaryAppender. It appends the byte
+// classes, the transition table, the start states and the end-of-input
+// flags to b.
+//
+// The format is internal to this version of coregex; it is meant to be
+// embedded in the versioned engine format of the meta package.
+func (d *DFA) AppendBinary(b []byte) ([]byte, error) {
+	b, _ = d.classes.AppendBinary(b)
+	b = wire.AppendUint(b, uint64(d.maxMatch))
+	for anchored := range d.starts {
+		for _, sid := range d.starts[anchored] {
+			b = wire.AppendUint(b, uint64(sid))
+		}
+	}
+	b = wire.AppendUint(b, uint64(len(d.eoi)))
+	for _, eoi := range d.eoi {
+		b = wire.AppendBool(b, eoi)
+	}
+	for _, sid := range d.table {
+		b = wire.AppendUint(b, uint64(sid))
+	}
+	return b, nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (d *DFA) MarshalBinary() ([]byte, error) {
+	return d.AppendBinary(nil)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. It replaces d with
+// the DFA encoded by AppendBinary.
+func (d *DFA) UnmarshalBinary(data []byte) error {
+	r := wire.NewReader(data)
+	decoded := &DFA{}
+	var classes [256]byte
+	for i := range classes {
+		classes[i] = r.Byte()
+	}
+	if err := decoded.classes.UnmarshalBinary(classes[:]); err != nil {
+		r.Fail(err)
+	}
+	decoded.stride = decoded.classes.AlphabetLen()
+	decoded.maxMatch = StateID(r.Uint32())
+	for anchored := range decoded.starts {
+		for kind := range decoded.starts[anchored] {
+			decoded.starts[anchored][kind] = StateID(r.Uint32())
+		}
+	}
+	n := r.Len()
+	decoded.eoi = make([]bool, n)
+	for i := range decoded.eoi {
+		decoded.eoi[i] = r.Bool()
+	}
+	if r.Err() == nil && n > 0 && uint64(n)*uint64(decoded.stride) <= uint64(len(data)) {
+		decoded.table = make([]StateID, n*decoded.stride)
+		for i := range decoded.table {
+			decoded.table[i] = StateID(r.Uint32())
+		}
+	}
+	if err := r.Done(); err != nil {
+		return err
+	}
+
+	limit := conv.IntToUint32(len(decoded.table))
+	valid := func(sid StateID) bool {
+		return uint32(sid) < limit && int(sid)%decoded.stride == 0
+	}
+	if n == 0 || len(decoded.table) != n*decoded.stride || !valid(decoded.maxMatch) {
+		return errInvalidTables
+	}
+	for anchored := range decoded.starts {
+		for _, sid := range decoded.starts[anchored] {
+			if !valid(sid) {
+				return errInvalidTables
+			}
+		}
+	}
+	for _, sid := range decoded.table {
+		if !valid(sid) {
+			return errInvalidTables
+		}
+	}
+	*d = *decoded
+	return nil
+}
@@ -0,0 +1,44 @@
+package dense
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestDFABinaryRoundTrip(t *testing.T) {
+	for _, pattern := range []string{`[a-z]+ing`, `(?m)^\w+$`, `\bfoo\b`, `a|ab`} {
+		d := buildDFA(t, pattern, DefaultConfig())
+		data, err := d.MarshalBinary()
+		if err != nil {
+			t.Fatalf("%q: MarshalBinary: %v", pattern, err)
+		}
+		var loaded DFA
+		if err := loaded.UnmarshalBinary(data); err != nil {
+			t.Fatalf("%q: UnmarshalBinary: %v", pattern, err)
+		}
+		if !reflect.DeepEqual(&loaded, d) {
+			t.Errorf("%q: loaded DFA differs from the original", pattern)
+		}
+	}
+}
+
+func TestDFAUnmarshalBinaryErrors(t *testing.T) {
+	data, _ := buildDFA(t, `\w+@\w+\.com`, DefaultConfig()).MarshalBinary()
+	var d DFA
+	for n := range len(data) {
+		if d.UnmarshalBinary(data[:n]) == nil {
+			t.Fatalf("UnmarshalBinary(data[:%d]) succeeded", n)
+		}
+	}
+	for i := range data {
+		corrupt := bytes.Clone(data)
+		corrupt[i] ^= 0xff
+		if d.UnmarshalBinary(corrupt) == nil {
+			h := []byte("mail bob@example.com now")
+			d.SearchAt(h, 0)
+			d.IsMatchAt(h, 3)
+			d.SearchReverse(h, 0, 20)
+		}
+	}
+}
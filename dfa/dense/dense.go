@@ -0,0 +1,159 @@
+// Package dense implements a fully determinized DFA with read-only tables.
+//
+// Unlike the lazy DFA, which builds states on demand in a per-search cache,
+// a dense DFA is determinized ahead of time: every state reachable from the
+// start states is built by Build, optionally minimized with Hopcroft's
+// algorithm, and stored in one flat transition table indexed by the byte
+// equivalence classes of the NFA. Searching only reads the tables, so a DFA
+// can be shared by any number of goroutines without a cache.
+//
+// The price is compile time and memory, both of which grow with the number
+// of states. Build gives up with ErrTooLarge once Config.MaxStates or
+// Config.SizeLimit is exceeded; callers then fall back to the lazy DFA.
+//
+// Supported assertions are ^, $, \A, \z, \b and \B with ASCII word chars.
+// Patterns with look-ahead, look-behind or Unicode word boundaries, and
+// multi-pattern NFAs, are rejected with ErrUnsupported.
+//
+// Table layout: state IDs are premultiplied by the stride (the alphabet
+// length), so the next state is table[sid+class]. The dead state is 0 and
+// the match states follow it, so a single comparison against the last match
+// state detects both in the search loop.
+//
+// Example:
+//
+//	n, _ := nfa.NewCompiler(nfa.DefaultCompilerConfig()).Compile(`[a-z]+ing`)
+//	d, err := dense.Build(n, dense.DefaultConfig())
+//	if err != nil {
+//	    // too large or unsupported: use the lazy DFA
+//	}
+//	end := d.SearchAt([]byte("the king sings"), 0) // 8
+package dense
+
+import (
+	"errors"
+
+	"github.com/coregx/coregex/nfa"
+)
+
+var (
+	// ErrTooLarge is returned by Build when the DFA would have more states
+	// than Config.MaxStates or a transition table larger than
+	// Config.SizeLimit.
+	ErrTooLarge = errors.New("dense: DFA exceeds the size limit")
+
+	// ErrUnsupported is returned by Build for NFAs a dense DFA cannot
+	// represent: look-ahead, look-behind, Unicode word boundaries or more
+	// than one pattern.
+	ErrUnsupported = errors.New("dense: NFA is not supported")
+)
+
+// StateID is a premultiplied DFA state identifier: the index of the state's
+// first transition in the transition table.
+type StateID uint32
+
+// DeadState is the state that matches nothing more. Its transitions all lead
+// back to itself.
+const DeadState StateID = 0
+
+// Config configures Build.
+type Config struct {
+	// MaxStates is the maximum number of DFA states, counted before
+	// minimization. Build returns ErrTooLarge when it is exceeded.
+	// Default: 10,000
+	MaxStates int
+
+	// SizeLimit is the maximum size of the transition table in bytes,
+	// checked before minimization. Zero means no limit beyond MaxStates.
+	// Default: 2MB
+	SizeLimit int
+
+	// Minimize runs Hopcroft's algorithm on the determinized DFA, merging
+	// states that no input can tell apart.
+	// Default: true
+	Minimize bool
+
+	// BreakAtMatch stops following lower priority NFA threads once a match
+	// is found, which gives leftmost-first (Perl) match ends. Reverse DFAs
+	// that look for the leftmost match start set it to false.
+	// Default: true
+	BreakAtMatch bool
+}
+
+// DefaultConfig returns a configuration for a forward leftmost-first DFA.
+func DefaultConfig() Config {
+	return Config{
+		MaxStates:    10_000,
+		SizeLimit:    2 * 1024 * 1024,
+		Minimize:     true,
+		BreakAtMatch: true,
+	}
+}
+
+// Start kinds, chosen by the byte before the search position (or after it,
+// for reverse searches).
+const (
+	startText    = iota // at the edge of the haystack
+	startLineLF         // after '\n'
+	startWord           // after an ASCII word byte
+	startNonWord        // after any other byte
+	startKinds
+)
+
+// DFA is a fully determinized DFA. It is immutable after Build or
+// UnmarshalBinary and safe for concurrent use.
+type DFA struct {
+	// table holds stride transitions per state; entries are premultiplied
+	// state IDs.
+	table []StateID
+
+	// classes maps bytes to alphabet classes.
+	classes nfa.ByteClasses
+
+	// stride is the alphabet length.
+	stride int
+
+	// maxMatch is the premultiplied ID of the last match state. IDs in
+	// (DeadState, maxMatch] are match states; DeadState <= maxMatch always.
+	maxMatch StateID
+
+	// eoi reports, per state index, whether the pattern matches at the end
+	// of the haystack when the search is in that state.
+	eoi []bool
+
+	// starts holds the start states: [anchored][kind].
+	starts [2][startKinds]StateID
+}
+
+// States returns the number of DFA states, including the dead state.
+func (d *DFA) States() int {
+	return len(d.table) / d.stride
+}
+
+// AlphabetLen returns the number of byte equivalence classes.
+func (d *DFA) AlphabetLen() int {
+	return d.stride
+}
+
+// MemoryUsage returns the approximate heap memory used by the DFA tables in
+// bytes.
+func (d *DFA) MemoryUsage() int {
+	return len(d.table)*4 + len(d.eoi) + 256
+}
+
+// isWordByte reports whether b is an ASCII word char [0-9A-Za-z_].
+func isWordByte(b byte) bool {
+	return b == '_' || ('0' <= b && b <= '9') || ('a' <= b && b <= 'z') || ('A' <= b && b <= 'Z')
+}
+
+// kindOf returns the start kind for a search next to byte b.
+func kindOf(b byte) int {
+	switch {
+	case b == '\n':
+		return startLineLF
+	case isWordByte(b):
+		return startWord
+	default:
+		return startNonWord
+	}
+}
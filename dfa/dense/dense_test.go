@@ -0,0 +1,293 @@
+package dense
+
+import (
+	"errors"
+	"regexp"
+	"regexp/syntax"
+	"testing"
+
+	"github.com/coregx/coregex/nfa"
+)
+
+// compileNFA compiles pattern with the default compiler.
+func compileNFA(t *testing.T, pattern string) *nfa.NFA {
+	t.Helper()
+	n, err := nfa.NewDefaultCompiler().Compile(pattern)
+	if err != nil {
+		t.Fatalf("Compile(%q): %v", pattern, err)
+	}
+	return n
+}
+
+// buildDFA builds a dense DFA for pattern with config.
+func buildDFA(t *testing.T, pattern string, config Config) *DFA {
+	t.Helper()
+	d, err := Build(compileNFA(t, pattern), config)
+	if err != nil {
+		t.Fatalf("Build(%q): %v", pattern, err)
+	}
+	return d
+}
+
+var densePatterns = []string{
+	`a`,
+	`abc`,
+	`a|ab`,
+	`ab|a`,
+	`a*`,
+	`a+?`,
+	`a*?b`,
+	`(a|b)*abb`,
+	`[a-z]+ing`,
+	`\d+\.\d+`,
+	`\w+@\w+\.com`,
+	`x*`,
+	`^abc`,
+	`abc$`,
+	`^$`,
+	`(?m)^\w+`,
+	`(?m)\w+$`,
+	`(?m)^$`,
+	`\bfoo\b`,
+	`\Bo\B`,
+	`\b`,
+	`\B`,
+	`foo\b|bar`,
+	`.*`,
+	`.+?x`,
+	`(?s).+`,
+	`[^a]+`,
+	`héllo|wörld`,
+	`\p{Greek}+`,
+	`(?i)straße`,
+	`(foo|foobar)baz`,
+	`a{2,4}?`,
+	`\A\w+\z`,
+}
+
+var denseInputs = []string{
+	"",
+	"a",
+	"abc",
+	"xabcx",
+	"abababb aabb",
+	"singing ring king",
+	"3.14 and 2.71",
+	"mail bob@example.com now",
+	"line one\nline two\n\nlast",
+	"foo bar foobar barfoo foo",
+	"hello héllo wörld",
+	"αβγ abc δ",
+	"STRASSE straße",
+	"foobarbaz foobaz",
+	"aaaaa",
+	"no\nx match",
+}
+
+func TestSearchAtMatchesStdlib(t *testing.T) {
+	for _, pattern := range densePatterns {
+		re := regexp.MustCompile(pattern)
+		d := buildDFA(t, pattern, DefaultConfig())
+		for _, in := range denseInputs {
+			h := []byte(in)
+			for at := 0; at <= len(h); at++ {
+				if at > 0 && anchoredContext(pattern) {
+					continue
+				}
+				want := -1
+				if loc := re.FindIndex(h[at:]); loc != nil {
+					want = at + loc[1]
+				}
+				if got := d.SearchAt(h, at); got != want {
+					t.Errorf("%q.SearchAt(%q, %d) = %d, want %d", pattern, in, at, got, want)
+				}
+				if got := d.IsMatchAt(h, at); got != (want >= 0) {
+					t.Errorf("%q.IsMatchAt(%q, %d) = %v, want %v", pattern, in, at, got, want >= 0)
+				}
+			}
+		}
+	}
+}
+
+// anchoredContext reports whether matches of pattern depend on the bytes
+// before the search position, which regexp cannot see in h[at:].
+func anchoredContext(pattern string) bool {
+	re, _ := syntax.Parse(pattern, syntax.Perl)
+	var walk func(*syntax.Regexp) bool
+	walk = func(re *syntax.Regexp) bool {
+		switch re.Op {
+		case syntax.OpBeginLine, syntax.OpBeginText, syntax.OpWordBoundary, syntax.OpNoWordBoundary:
+			return true
+		}
+		for _, sub := range re.Sub {
+			if walk(sub) {
+				return true
+			}
+		}
+		return false
+	}
+	return walk(re)
+}
+
+func TestSearchAtAnchored(t *testing.T) {
+	tests := []struct {
+		pattern string
+		input   string
+		at      int
+		want    int
+	}{
+		{`abc`, "xabc", 0, -1},
+		{`abc`, "xabc", 1, 4},
+		{`a+`, "baaab", 1, 4},
+		{`a|ab`, "ab", 0, 1},
+		{`\bfoo`, "xfoo foo", 1, -1},
+		{`\bfoo`, "xfoo foo", 5, 8},
+		{`(?m)^b`, "a\nb", 2, 3},
+		{`x*`, "abc", 1, 1},
+	}
+	for _, tt := range tests {
+		d := buildDFA(t, tt.pattern, DefaultConfig())
+		if got := d.SearchAtAnchored([]byte(tt.input), tt.at); got != tt.want {
+			t.Errorf("%q.SearchAtAnchored(%q, %d) = %d, want %d", tt.pattern, tt.input, tt.at, got, tt.want)
+		}
+	}
+}
+
+func TestSearchReverse(t *testing.T) {
+	config := DefaultConfig()
+	config.BreakAtMatch = false
+	patterns := []string{`abc`, `a+`, `[a-z]+ing`, `\d+\.\d+`, `(a|b)*abb`, `héllo|wörld`, `.*`, `(foo|foobar)baz`, `x*`, `x*y`, `[ab]*c`, `a*b*`}
+	for _, pattern := range patterns {
+		re := regexp.MustCompile(pattern)
+		rev, err := Build(nfa.ReverseAnchored(compileNFA(t, pattern)), config)
+		if err != nil {
+			t.Fatalf("Build(reverse %q): %v", pattern, err)
+		}
+		for _, in := range denseInputs {
+			h := []byte(in)
+			for _, loc := range re.FindAllIndex(h, -1) {
+				if got := rev.SearchReverse(h, 0, loc[1]); got != loc[0] {
+					t.Errorf("%q.SearchReverse(%q, 0, %d) = %d, want %d", pattern, in, loc[1], got, loc[0])
+				}
+				if got := rev.SearchReverse(h, loc[0], loc[1]); got != loc[0] {
+					t.Errorf("%q.SearchReverse(%q, %d, %d) = %d, want %d", pattern, in, loc[0], loc[1], got, loc[0])
+				}
+			}
+		}
+	}
+	rev, _ := Build(nfa.ReverseAnchored(compileNFA(t, `abc`)), config)
+	if got := rev.SearchReverse([]byte("xabd"), 0, 4); got != -1 {
+		t.Errorf("SearchReverse without a match = %d, want -1", got)
+	}
+	if got := rev.SearchReverse([]byte("abc"), 2, 1); got != -1 {
+		t.Errorf("SearchReverse with start > end = %d, want -1", got)
+	}
+}
+
+func TestMinimize(t *testing.T) {
+	tests := []struct {
+		pattern string
+		states  int // including the dead state
+	}{
+		{`a*`, 4},
+		{`ab|cb|db`, 7},
+		{`(?:ab|cb)d`, 9},
+		{`(a|b)*abb`, 11},
+	}
+	for _, tt := range tests {
+		full := DefaultConfig()
+		full.Minimize = false
+		before := buildDFA(t, tt.pattern, full)
+		after := buildDFA(t, tt.pattern, DefaultConfig())
+		if after.States() >= before.States() {
+			t.Errorf("%q: minimized DFA has %d states, unminimized %d", tt.pattern, after.States(), before.States())
+		}
+		if got := after.States(); got != tt.states {
+			t.Errorf("%q: minimized DFA has %d states, want %d", tt.pattern, got, tt.states)
+		}
+		for _, in := range denseInputs {
+			h := []byte(in)
+			for at := range len(h) + 1 {
+				if a, b := after.SearchAt(h, at), before.SearchAt(h, at); a != b {
+					t.Errorf("%q on %q at %d: minimized %d, unminimized %d", tt.pattern, in, at, a, b)
+				}
+			}
+		}
+	}
+}
+
+func TestBuildLimits(t *testing.T) {
+	n := compileNFA(t, `[01]*1[01]{12}`)
+	config := DefaultConfig()
+	config.MaxStates = 100
+	if _, err := Build(n, config); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Build with MaxStates 100: error = %v, want ErrTooLarge", err)
+	}
+	config = DefaultConfig()
+	config.SizeLimit = 1024
+	if _, err := Build(n, config); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Build with SizeLimit 1KB: error = %v, want ErrTooLarge", err)
+	}
+
+	set, err := nfa.NewDefaultCompiler().CompileSet([]*syntax.Regexp{
+		mustParse(t, `a`), mustParse(t, `b`),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Build(set, DefaultConfig()); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Build(set): error = %v, want ErrUnsupported", err)
+	}
+	unicodeWords, err := nfa.NewCompiler(nfa.CompilerConfig{UTF8: true, UnicodeWordBoundary: true}).Compile(`\bab`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Build(unicodeWords, DefaultConfig()); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Build(Unicode \\b): error = %v, want ErrUnsupported", err)
+	}
+}
+
+func mustParse(t *testing.T, pattern string) *syntax.Regexp {
+	t.Helper()
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return re
+}
+
+func TestMemoryUsage(t *testing.T) {
+	d := buildDFA(t, `[a-z]+ing`, DefaultConfig())
+	if d.MemoryUsage() < d.States()*d.AlphabetLen()*4 {
+		t.Errorf("MemoryUsage() = %d, less than the transition table", d.MemoryUsage())
+	}
+}
+
+func TestStateAccessors(t *testing.T) {
+	for _, pattern := range densePatterns {
+		d := buildDFA(t, pattern, DefaultConfig())
+		for _, in := range denseInputs {
+			h := []byte(in)
+			// An IsMatchAt built from the exported accessors.
+			sid := d.StartState(false, -1)
+			got := false
+			for _, b := range h {
+				sid = d.Next(sid, b)
+				if d.IsDeadState(sid) || d.IsMatchState(sid) {
+					got = d.IsMatchState(sid)
+					break
+				}
+			}
+			if !d.IsDeadState(sid) && !d.IsMatchState(sid) {
+				got = d.IsMatchAtEOI(sid)
+			}
+			if want := d.IsMatchAt(h, 0); got != want {
+				t.Errorf("%q on %q: accessors give %v, IsMatchAt %v", pattern, in, got, want)
+			}
+		}
+	}
+	d := buildDFA(t, `\bfoo`, DefaultConfig())
+	if d.StartState(true, 'a') == d.StartState(true, ' ') {
+		t.Error(`\bfoo: same start state after a word and a non-word byte`)
+	}
+}
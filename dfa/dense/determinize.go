@@ -0,0 +1,360 @@
+package dense
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/coregx/coregex/internal/conv"
+	"github.com/coregx/coregex/internal/sparse"
+	"github.com/coregx/coregex/nfa"
+)
+
+// lookSet is a set of nfa.Look assertions, one bit per assertion.
+type lookSet uint8
+
+const (
+	lookStartText    lookSet = 1 << nfa.LookStartText
+	lookEndText      lookSet = 1 << nfa.LookEndText
+	lookStartLine    lookSet = 1 << nfa.LookStartLine
+	lookEndLine      lookSet = 1 << nfa.LookEndLine
+	lookWordBoundary lookSet = 1 << nfa.LookWordBoundary
+	lookNoWordBound  lookSet = 1 << nfa.LookNoWordBoundary
+
+	lookWord = lookWordBoundary | lookNoWordBound
+)
+
+// has reports whether look is in s.
+func (s lookSet) has(look nfa.Look) bool {
+	return look <= nfa.LookNoWordBoundary && s&(1<<look) != 0
+}
+
+// dstate is a DFA state under construction.
+//
+// set is the ordered epsilon closure of the NFA threads, following only the
+// assertions in look, which are the ones known to hold at this position
+// before seeing the next byte: \A, and ^ after a '\n'. Assertions that
+// depend on the next byte ($, \z, \b, \B) stay pending in set and are
+// resolved when the transition or end-of-input flag is computed.
+//
+// match means a match ended just before the byte that led to this state:
+// the DFA reports matches one byte late, like the lazy DFA.
+type dstate struct {
+	set      []nfa.StateID
+	look     lookSet
+	fromWord bool
+	match    bool
+}
+
+// determinizer runs the subset construction.
+type determinizer struct {
+	nfa    *nfa.NFA
+	config Config
+
+	classes nfa.ByteClasses
+	reps    []byte // a representative byte per class
+	stride  int
+
+	// looks holds the assertions present in the NFA; state flags for
+	// assertions it lacks are dropped so they do not split states.
+	looks lookSet
+
+	states []dstate
+	index  map[string]int
+	trans  []int // stride transitions per state, as state indexes
+	eoi    []bool
+
+	// scratch space
+	set1, set2 *sparse.SparseSet
+	stack      []nfa.StateID
+	key        []byte
+}
+
+// Build determinizes n into a dense DFA.
+//
+// It returns ErrUnsupported for NFAs with look-around, Unicode word
+// boundaries or more than one pattern, and ErrTooLarge when the DFA
+// exceeds config.MaxStates or config.SizeLimit.
+func Build(n *nfa.NFA, config Config) (*DFA, error) {
+	looks, classes, err := checkNFA(n)
+	if err != nil {
+		return nil, err
+	}
+	if config.MaxStates <= 0 {
+		config.MaxStates = DefaultConfig().MaxStates
+	}
+
+	d := &determinizer{
+		nfa:     n,
+		config:  config,
+		classes: classes,
+		looks:   looks,
+		index:   make(map[string]int),
+		set1:    sparse.NewSparseSet(conv.IntToUint32(n.States())),
+		set2:    sparse.NewSparseSet(conv.IntToUint32(n.States())),
+	}
+	d.stride = d.classes.AlphabetLen()
+	d.reps = make([]byte, d.stride)
+	for b := 255; b >= 0; b-- {
+		d.reps[d.classes.Get(byte(b))] = byte(b)
+	}
+
+	// The dead state is always index 0.
+	if _, err := d.add(dstate{}); err != nil {
+		return nil, err
+	}
+
+	var starts [2][startKinds]int
+	for anchored, start := range []nfa.StateID{n.StartUnanchored(), n.StartAnchored()} {
+		for kind := range startKinds {
+			sid, err := d.start(start, kind)
+			if err != nil {
+				return nil, err
+			}
+			starts[anchored][kind] = sid
+		}
+	}
+
+	// States are appended as they are found; building them in order
+	// visits each exactly once.
+	for i := 0; i < len(d.states); i++ {
+		for class := range d.stride {
+			next, err := d.add(d.next(d.states[i], d.reps[class]))
+			if err != nil {
+				return nil, err
+			}
+			d.trans[i*d.stride+class] = next
+		}
+	}
+
+	match := make([]bool, len(d.states))
+	for i := range d.states {
+		match[i] = d.states[i].match
+	}
+	a := &automaton{trans: d.trans, stride: d.stride, match: match, eoi: d.eoi, starts: starts}
+	if config.Minimize {
+		a = a.minimize()
+	}
+	return a.dfa(d.classes), nil
+}
+
+// checkNFA returns the assertions in n and the byte classes of its
+// transitions, or ErrUnsupported if a dense DFA cannot represent n.
+//
+// The classes are computed from the states rather than taken from
+// n.ByteClasses, which does not separate '\n' for line anchors and is not
+// kept up to date by nfa.Reverse.
+func checkNFA(n *nfa.NFA) (lookSet, nfa.ByteClasses, error) {
+	if n.PatternCount() > 1 {
+		return 0, nfa.ByteClasses{}, fmt.Errorf("%w: %d patterns", ErrUnsupported, n.PatternCount())
+	}
+	if n.HasLookaround() || n.HasUnicodeWordBoundary() {
+		return 0, nfa.ByteClasses{}, fmt.Errorf("%w: look-around or Unicode word boundary", ErrUnsupported)
+	}
+	var looks lookSet
+	set := nfa.NewByteClassSet()
+	for it := n.Iter(); it.HasNext(); {
+		s := it.Next()
+		switch s.Kind() {
+		case nfa.StateByteRange:
+			lo, hi, _ := s.ByteRange()
+			set.SetRange(lo, hi)
+		case nfa.StateSparse:
+			for _, tr := range s.Transitions() {
+				set.SetRange(tr.Lo, tr.Hi)
+			}
+		case nfa.StateLook:
+			look, _ := s.Look()
+			looks |= 1 << look
+		case nfa.StateRuneAny, nfa.StateRuneAnyNotNL:
+			return 0, nfa.ByteClasses{}, fmt.Errorf("%w: rune state %d", ErrUnsupported, s.ID())
+		}
+	}
+	if looks&lookWord != 0 {
+		set.SetWordBoundary()
+	}
+	if looks&(lookStartLine|lookEndLine) != 0 {
+		set.SetByte('\n')
+	}
+	return looks, set.ByteClasses(), nil
+}
+
+// start adds the start state for an NFA start state and a start kind.
+func (d *determinizer) start(start nfa.StateID, kind int) (int, error) {
+	var look lookSet
+	switch kind {
+	case startText:
+		look = lookStartText | lookStartLine
+	case startLineLF:
+		look = lookStartLine
+	}
+	d.set1.Clear()
+	d.closeInto(d.set1, start, look)
+	return d.add(dstate{
+		set:      toStates(d.set1),
+		look:     look,
+		fromWord: kind == startWord,
+	})
+}
+
+// next computes the state after s on byte b.
+func (d *determinizer) next(s dstate, b byte) dstate {
+	ahead := s.look
+	if b == '\n' {
+		ahead |= lookEndLine
+	}
+	if isWordByte(b) != s.fromWord {
+		ahead |= lookWordBoundary
+	} else {
+		ahead |= lookNoWordBound
+	}
+	set := d.resolve(s, ahead)
+
+	var look lookSet
+	if b == '\n' {
+		look = lookStartLine
+	}
+	var next dstate
+	d.set2.Clear()
+	for _, id := range set {
+		state := d.nfa.State(id)
+		switch state.Kind() {
+		case nfa.StateMatch:
+			next.match = true
+		case nfa.StateByteRange:
+			lo, hi, to := state.ByteRange()
+			if lo <= b && b <= hi {
+				d.closeInto(d.set2, to, look)
+			}
+		case nfa.StateSparse:
+			for _, tr := range state.Transitions() {
+				if tr.Lo <= b && b <= tr.Hi {
+					d.closeInto(d.set2, tr.Next, look)
+				}
+			}
+		}
+		// Threads after a match have lower priority: a leftmost-first
+		// search never prefers them.
+		if next.match && d.config.BreakAtMatch {
+			break
+		}
+	}
+	next.set = toStates(d.set2)
+	next.look = look
+	next.fromWord = isWordByte(b)
+	return next
+}
+
+// matchesAtEOI reports whether s matches at the end of the haystack.
+func (d *determinizer) matchesAtEOI(s dstate) bool {
+	// The "next byte" at the end of input is a non-word byte.
+	ahead := s.look | lookEndText | lookEndLine | lookNoWordBound
+	if s.fromWord {
+		ahead ^= lookNoWordBound | lookWordBoundary
+	}
+	for _, id := range d.resolve(s, ahead) {
+		if d.nfa.IsMatch(id) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolve returns the closure of s.set with the assertions in ahead also
+// holding. The order of s.set is kept, with threads unblocked by ahead
+// following the assertion that blocked them.
+func (d *determinizer) resolve(s dstate, ahead lookSet) []nfa.StateID {
+	if (ahead&^s.look)&d.looks == 0 {
+		return s.set
+	}
+	d.set1.Clear()
+	for _, id := range s.set {
+		d.closeInto(d.set1, id, ahead)
+	}
+	return toStates(d.set1)
+}
+
+// closeInto adds seed and its epsilon closure to set, following look
+// states whose assertion is in look. Split states are explored left first,
+// so set lists threads in priority order.
+func (d *determinizer) closeInto(set *sparse.SparseSet, seed nfa.StateID, look lookSet) {
+	stack := append(d.stack[:0], seed)
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if id == nfa.InvalidState || !set.Insert(uint32(id)) {
+			continue
+		}
+		state := d.nfa.State(id)
+		switch state.Kind() {
+		case nfa.StateEpsilon:
+			stack = append(stack, state.Epsilon())
+		case nfa.StateSplit:
+			left, right := state.Split()
+			stack = append(stack, right, left)
+		case nfa.StateCapture:
+			_, _, next := state.Capture()
+			stack = append(stack, next)
+		case nfa.StateLook:
+			l, next := state.Look()
+			if look.has(l) {
+				stack = append(stack, next)
+			}
+		}
+	}
+	d.stack = stack
+}
+
+// add returns the index of s, adding it if it is new.
+func (d *determinizer) add(s dstate) (int, error) {
+	if len(s.set) == 0 {
+		// No thread is left to look at the context.
+		s.look, s.fromWord = 0, false
+	}
+	s.look &= d.looks
+	if d.looks&lookWord == 0 {
+		s.fromWord = false
+	}
+
+	key := d.key[:0]
+	var flags byte
+	if s.match {
+		flags |= 1
+	}
+	if s.fromWord {
+		flags |= 2
+	}
+	key = append(key, flags, byte(s.look))
+	for _, id := range s.set {
+		key = binary.AppendUvarint(key, uint64(id))
+	}
+	d.key = key
+	if i, ok := d.index[string(key)]; ok {
+		return i, nil
+	}
+
+	if len(d.states) >= d.config.MaxStates {
+		return 0, fmt.Errorf("%w: more than %d states", ErrTooLarge, d.config.MaxStates)
+	}
+	if limit := d.config.SizeLimit; limit > 0 && (len(d.states)+1)*d.stride*4 > limit {
+		return 0, fmt.Errorf("%w: transition table over %d bytes", ErrTooLarge, limit)
+	}
+	i := len(d.states)
+	d.states = append(d.states, s)
+	d.index[string(key)] = i
+	d.trans = append(d.trans, make([]int, d.stride)...)
+	d.eoi = append(d.eoi, d.matchesAtEOI(s))
+	return i, nil
+}
+
+// toStates returns a copy of the members of set in insertion order.
+func toStates(set *sparse.SparseSet) []nfa.StateID {
+	values := set.Values()
+	if len(values) == 0 {
+		return nil
+	}
+	states := make([]nfa.StateID, len(values))
+	for i, v := range values {
+		states[i] = nfa.StateID(v)
+	}
+	return states
+}
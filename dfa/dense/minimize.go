@@ -0,0 +1,219 @@
+package dense
+
+import (
+	"github.com/coregx/coregex/internal/conv"
+	"github.com/coregx/coregex/nfa"
+)
+
+// automaton is a DFA with plain state indexes, before the final table
+// layout. State 0 is the dead state.
+type automaton struct {
+	trans  []int // stride transitions per state
+	stride int
+	match  []bool
+	eoi    []bool
+	starts [2][startKinds]int
+}
+
+// minimize merges equivalent states with Hopcroft's algorithm.
+//
+// Two states are equivalent when every input leads them through the same
+// sequence of match flags and end-of-input flags. The partition starts from
+// the blocks of states with equal flags and is refined by the preimages of
+// splitter blocks until no block can be split. A block that is split while
+// queued has both halves queued; otherwise only the smaller half is, which
+// bounds the work by O(n·k·log n) for n states and k byte classes.
+func (a *automaton) minimize() *automaton {
+	n, k := len(a.match), a.stride
+
+	// Reverse transitions grouped by class and target, in CSR form:
+	// the sources of (class, target) are sources[offsets[i]:offsets[i+1]]
+	// with i = class*n + target.
+	offsets := make([]int, k*n+1)
+	for s := range n {
+		for c := range k {
+			offsets[c*n+a.trans[s*k+c]+1]++
+		}
+	}
+	for i := 1; i < len(offsets); i++ {
+		offsets[i] += offsets[i-1]
+	}
+	sources := make([]int, len(a.trans))
+	fill := append([]int(nil), offsets[:k*n]...)
+	for s := range n {
+		for c := range k {
+			i := c*n + a.trans[s*k+c]
+			sources[fill[i]] = s
+			fill[i]++
+		}
+	}
+
+	// Initial partition by (match, eoi).
+	var blocks [][]int
+	blockOf := make([]int, n)
+	first := map[[2]bool]int{}
+	for s := range n {
+		flags := [2]bool{a.match[s], a.eoi[s]}
+		b, ok := first[flags]
+		if !ok {
+			b = len(blocks)
+			first[flags] = b
+			blocks = append(blocks, nil)
+		}
+		blocks[b] = append(blocks[b], s)
+		blockOf[s] = b
+	}
+
+	queued := make([]bool, len(blocks), n)
+	var work []int
+	for b := range blocks {
+		queued[b] = true
+		work = append(work, b)
+	}
+
+	inX := make([]bool, n)
+	count := make([]int, n)
+	var xs, touched []int
+	for len(work) > 0 {
+		splitter := work[len(work)-1]
+		work = work[:len(work)-1]
+		queued[splitter] = false
+		members := append([]int(nil), blocks[splitter]...)
+
+		for c := range k {
+			// X is the set of states with a transition on c into the
+			// splitter.
+			xs = xs[:0]
+			for _, t := range members {
+				for _, s := range sources[offsets[c*n+t]:offsets[c*n+t+1]] {
+					if !inX[s] {
+						inX[s] = true
+						xs = append(xs, s)
+					}
+				}
+			}
+			touched = touched[:0]
+			for _, s := range xs {
+				b := blockOf[s]
+				if count[b] == 0 {
+					touched = append(touched, b)
+				}
+				count[b]++
+			}
+
+			for _, b := range touched {
+				if count[b] < len(blocks[b]) {
+					var in, out []int
+					for _, s := range blocks[b] {
+						if inX[s] {
+							in = append(in, s)
+						} else {
+							out = append(out, s)
+						}
+					}
+					nb := len(blocks)
+					blocks[b] = out
+					blocks = append(blocks, in)
+					queued = append(queued, false)
+					for _, s := range in {
+						blockOf[s] = nb
+					}
+					switch {
+					case queued[b]:
+						queued[nb] = true
+						work = append(work, nb)
+					case len(in) < len(out):
+						queued[nb] = true
+						work = append(work, nb)
+					default:
+						queued[b] = true
+						work = append(work, b)
+					}
+				}
+				count[b] = 0
+			}
+			for _, s := range xs {
+				inX[s] = false
+			}
+		}
+	}
+
+	// Number the blocks so that the dead state's block is 0.
+	dead := blockOf[0]
+	renumber := func(s int) int {
+		switch b := blockOf[s]; b {
+		case dead:
+			return 0
+		case 0:
+			return dead
+		default:
+			return b
+		}
+	}
+
+	m := len(blocks)
+	out := &automaton{
+		trans:  make([]int, m*k),
+		stride: k,
+		match:  make([]bool, m),
+		eoi:    make([]bool, m),
+	}
+	for _, states := range blocks {
+		rep := states[0]
+		nb := renumber(rep)
+		for c := range k {
+			out.trans[nb*k+c] = renumber(a.trans[rep*k+c])
+		}
+		out.match[nb] = a.match[rep]
+		out.eoi[nb] = a.eoi[rep]
+	}
+	for anchored := range a.starts {
+		for kind, s := range a.starts[anchored] {
+			out.starts[anchored][kind] = renumber(s)
+		}
+	}
+	return out
+}
+
+// dfa lays out the final tables: the dead state first, then the match
+// states, then the rest, with premultiplied state IDs.
+func (a *automaton) dfa(classes nfa.ByteClasses) *DFA {
+	n, k := len(a.match), a.stride
+	order := make([]int, 0, n)
+	order = append(order, 0)
+	for s := 1; s < n; s++ {
+		if a.match[s] {
+			order = append(order, s)
+		}
+	}
+	matches := len(order) - 1
+	for s := 1; s < n; s++ {
+		if !a.match[s] {
+			order = append(order, s)
+		}
+	}
+	newID := make([]StateID, n)
+	for i, s := range order {
+		newID[s] = StateID(conv.IntToUint32(i * k))
+	}
+
+	d := &DFA{
+		table:    make([]StateID, n*k),
+		classes:  classes,
+		stride:   k,
+		maxMatch: StateID(conv.IntToUint32(matches * k)),
+		eoi:      make([]bool, n),
+	}
+	for i, s := range order {
+		for c := range k {
+			d.table[i*k+c] = newID[a.trans[s*k+c]]
+		}
+		d.eoi[i] = a.eoi[s]
+	}
+	for anchored := range a.starts {
+		for kind, s := range a.starts[anchored] {
+			d.starts[anchored][kind] = newID[s]
+		}
+	}
+	return d
+}
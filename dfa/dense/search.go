@@ -0,0 +1,142 @@
+package dense
+
+// startState returns the start state for a search at position at, whose
+// context is the byte before at.
+func (d *DFA) startState(haystack []byte, at int, anchored bool) StateID {
+	before := -1
+	if at > 0 {
+		before = int(haystack[at-1])
+	}
+	return d.StartState(anchored, before)
+}
+
+// SearchAt returns the end of the first match starting at or after at, or
+// -1 if there is none. With Config.BreakAtMatch the end is that of the
+// leftmost-first match; without it the search runs until the DFA dies and
+// returns the last match end it saw.
+//
+// The bytes before at are used as context for ^ and \b.
+func (d *DFA) SearchAt(haystack []byte, at int) int {
+	return d.search(haystack, at, false)
+}
+
+// SearchAtAnchored is SearchAt for matches that start exactly at at.
+func (d *DFA) SearchAtAnchored(haystack []byte, at int) int {
+	return d.search(haystack, at, true)
+}
+
+// search is the forward search loop. Entering a match state at byte i
+// means a match ends at i; the dead state ends the search.
+func (d *DFA) search(haystack []byte, at int, anchored bool) int {
+	if at < 0 || at > len(haystack) {
+		return -1
+	}
+	sid := d.startState(haystack, at, anchored)
+	last := -1
+	for i := at; i < len(haystack); i++ {
+		sid = d.table[int(sid)+int(d.classes.Get(haystack[i]))]
+		if sid <= d.maxMatch {
+			if sid == DeadState {
+				return last
+			}
+			last = i
+		}
+	}
+	if d.eoi[int(sid)/d.stride] {
+		return len(haystack)
+	}
+	return last
+}
+
+// IsMatchAt reports whether a match starts at or after at. It stops at the
+// first match state it enters.
+func (d *DFA) IsMatchAt(haystack []byte, at int) bool {
+	if at < 0 || at > len(haystack) {
+		return false
+	}
+	sid := d.startState(haystack, at, false)
+	for i := at; i < len(haystack); i++ {
+		sid = d.table[int(sid)+int(d.classes.Get(haystack[i]))]
+		if sid <= d.maxMatch {
+			return sid != DeadState
+		}
+	}
+	return d.eoi[int(sid)/d.stride]
+}
+
+// SearchReverse runs a DFA built from a reversed NFA backward over
+// haystack[start:end], anchored at end, and returns the smallest position
+// p >= start such that haystack[p:end] matches, or -1 if there is none.
+// Build the DFA with BreakAtMatch false so the scan goes on past the first
+// match it sees.
+//
+// The byte at end, if any, is the context of the search, and the byte
+// before start is consulted for the match flag at start.
+func (d *DFA) SearchReverse(haystack []byte, start, end int) int {
+	if start < 0 || start > end || end > len(haystack) {
+		return -1
+	}
+	kind := startText
+	if end < len(haystack) {
+		kind = kindOf(haystack[end])
+	}
+	sid := d.starts[1][kind]
+	last := -1
+	for i := end - 1; i >= start; i-- {
+		sid = d.table[int(sid)+int(d.classes.Get(haystack[i]))]
+		if sid <= d.maxMatch {
+			if sid == DeadState {
+				return last
+			}
+			last = i + 1
+		}
+	}
+	if start > 0 {
+		sid = d.table[int(sid)+int(d.classes.Get(haystack[start-1]))]
+		if sid != DeadState && sid <= d.maxMatch {
+			last = start
+		}
+	} else if d.eoi[int(sid)/d.stride] {
+		last = 0
+	}
+	return last
+}
+
+// StartState returns the start state for a search whose context is before:
+// the byte before the search position, or -1 at the start of the haystack.
+// For a reverse DFA the context is the byte after the search end.
+//
+// StartState, Next and the state predicates let callers run the DFA on
+// their own, for example to generate code from it.
+func (d *DFA) StartState(anchored bool, before int) StateID {
+	kind := startText
+	if before >= 0 {
+		kind = kindOf(byte(before))
+	}
+	if anchored {
+		return d.starts[1][kind]
+	}
+	return d.starts[0][kind]
+}
+
+// Next returns the state after sid on byte b.
+func (d *DFA) Next(sid StateID, b byte) StateID {
+	return d.table[int(sid)+int(d.classes.Get(b))]
+}
+
+// IsDeadState reports whether sid is the dead state.
+func (d *DFA) IsDeadState(sid StateID) bool {
+	return sid == DeadState
+}
+
+// IsMatchState reports whether entering sid means a match ended just
+// before the byte that led to it.
+func (d *DFA) IsMatchState(sid StateID) bool {
+	return sid != DeadState && sid <= d.maxMatch
+}
+
+// IsMatchAtEOI reports whether a search in state sid at the end of the
+// haystack has a match ending there.
+func (d *DFA) IsMatchAtEOI(sid StateID) bool {
+	return d.eoi[int(sid)/d.stride]
+}
@@ -76,16 +76,18 @@ func (b *Builder) Build() (*DFA, error) {
 
 	// Create DFA — fully immutable after this point
 	dfa := &DFA{
-		nfa:              b.nfa,
-		config:           b.config,
-		prefilter:        pf,
-		pikevm:           nfa.NewPikeVM(b.nfa),
-		byteClasses:      b.nfa.ByteClasses(),
-		unanchoredStart:  b.nfa.StartUnanchored(),
-		hasWordBoundary:  hasWordBoundary,
-		hasEndLine:       hasEndLine,
-		isAlwaysAnchored: isAlwaysAnchored,
-		startByteMap:     startByteMap,
+		nfa:                 b.nfa,
+		config:              b.config,
+		prefilter:           pf,
+		pikevm:              nfa.NewPikeVM(b.nfa),
+		byteClasses:         b.nfa.ByteClasses(),
+		unanchoredStart:     b.nfa.StartUnanchored(),
+		hasWordBoundary:     hasWordBoundary,
+		unicodeWordBoundary: b.nfa.HasUnicodeWordBoundary(),
+		hasEndLine:          hasEndLine,
+		hasLookaround:       b.nfa.HasLookaround(),
+		isAlwaysAnchored:    isAlwaysAnchored,
+		startByteMap:        startByteMap,
 	}
 
 	return dfa, nil
@@ -278,8 +280,12 @@ func (b *Builder) epsilonClosureInto(result *StateSet, seed nfa.StateID, lookHav
 			}
 
 		case nfa.StateLook:
+			// Look-ahead and look-behind cannot be decided by a DFA state;
+			// assume they hold, so the DFA matches a superset of the pattern.
+			// Only the searches that do not use the look-around search of
+			// lookaround.go get here with them.
 			look, next := state.Look()
-			if lookHave.Contains(look) && next != nfa.InvalidState {
+			if (lookHave.Contains(look) || look.IsLookaround()) && next != nfa.InvalidState {
 				stack = append(stack, next)
 			}
 
@@ -314,120 +320,105 @@ func (b *Builder) epsilonClosureInto(result *StateSet, seed nfa.StateID, lookHav
 // IMPORTANT: This function only expands states reachable by CROSSING a word boundary assertion.
 // It does NOT follow epsilon/split transitions from states that haven't crossed a word boundary.
 // This prevents false matches in patterns without word boundaries (like `a*`).
+//
+// The states past a crossed assertion are placed right after it, so the
+// result keeps the priority order of the threads for leftmost-first moves.
 func (b *Builder) resolveWordBoundaries(states []nfa.StateID, wordBoundarySatisfied bool) []nfa.StateID {
-	// First, find states reachable by crossing a word boundary assertion
-	// We track states that have crossed a boundary separately (use pooled StateSet)
-	crossedBoundary := acquireStateSet()
-	stack := make([]nfa.StateID, 0, len(states))
-
-	// Start by looking for word boundary assertions in input states
+	// If no word boundary can be crossed, return original states unchanged
+	crosses := false
 	for _, sid := range states {
-		state := b.nfa.State(sid)
-		if state == nil {
-			continue
-		}
-		if state.Kind() == nfa.StateLook {
-			look, next := state.Look()
-			if next == nfa.InvalidState {
-				continue
-			}
-			// Check if word boundary assertion is satisfied
-			switch look {
-			case nfa.LookWordBoundary:
-				if wordBoundarySatisfied && !crossedBoundary.Contains(next) {
-					crossedBoundary.Add(next)
-					stack = append(stack, next)
-				}
-			case nfa.LookNoWordBoundary:
-				if !wordBoundarySatisfied && !crossedBoundary.Contains(next) {
-					crossedBoundary.Add(next)
-					stack = append(stack, next)
-				}
-			}
+		if b.crossesWordBoundary(sid, wordBoundarySatisfied) {
+			crosses = true
+			break
 		}
 	}
-
-	// If no word boundary was crossed, return original states unchanged
-	if len(stack) == 0 {
-		releaseStateSet(crossedBoundary)
+	if !crosses {
 		return states
 	}
+	return b.resolveWordBoundariesOrdered(states, wordBoundarySatisfied, false)
+}
 
-	// Now expand states reachable from crossed boundaries via epsilon/split
-	for len(stack) > 0 {
-		current := stack[len(stack)-1]
-		stack = stack[:len(stack)-1]
+// resolveWordBoundariesUntilMatch is resolveWordBoundaries for break-at-match.
+// The result ends at the first match state: the threads after it have lower
+// priority than the match.
+func (b *Builder) resolveWordBoundariesUntilMatch(states []nfa.StateID, wordBoundarySatisfied bool) []nfa.StateID {
+	return b.resolveWordBoundariesOrdered(states, wordBoundarySatisfied, true)
+}
 
-		state := b.nfa.State(current)
-		if state == nil {
-			continue
-		}
+// crossesWordBoundary reports whether sid is a \b or \B assertion that holds.
+func (b *Builder) crossesWordBoundary(sid nfa.StateID, wordBoundarySatisfied bool) bool {
+	state := b.nfa.State(sid)
+	if state == nil || state.Kind() != nfa.StateLook {
+		return false
+	}
+	look, next := state.Look()
+	if next == nfa.InvalidState {
+		return false
+	}
+	switch look {
+	case nfa.LookWordBoundary, nfa.LookWordBoundaryUnicode:
+		return wordBoundarySatisfied
+	case nfa.LookNoWordBoundary, nfa.LookNoWordBoundaryUnicode:
+		return !wordBoundarySatisfied
+	}
+	return false
+}
 
-		switch state.Kind() {
-		case nfa.StateLook:
-			// Continue through any additional word boundary assertions
-			look, next := state.Look()
-			if next == nfa.InvalidState {
+// resolveWordBoundariesOrdered follows each satisfied \b or \B assertion in
+// place, so the result keeps the priority order of the threads. With
+// untilMatch it ends at the first match state.
+func (b *Builder) resolveWordBoundariesOrdered(states []nfa.StateID, wordBoundarySatisfied, untilMatch bool) []nfa.StateID {
+	seen := acquireStateSet()
+	defer releaseStateSet(seen)
+
+	result := make([]nfa.StateID, 0, len(states))
+	stack := make([]nfa.StateID, 0, 8)
+	for _, sid := range states {
+		stack = append(stack[:0], sid)
+		for len(stack) > 0 {
+			current := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if current == nfa.InvalidState || seen.Contains(current) {
 				continue
 			}
-			switch look {
-			case nfa.LookWordBoundary:
-				if wordBoundarySatisfied && !crossedBoundary.Contains(next) {
-					crossedBoundary.Add(next)
+			seen.Add(current)
+			result = append(result, current)
+
+			state := b.nfa.State(current)
+			if state == nil {
+				continue
+			}
+			// The states of the input set are already closed except for
+			// their word boundaries; past a crossed one, close here.
+			crossed := current != sid
+			switch state.Kind() {
+			case nfa.StateMatch:
+				if untilMatch {
+					return result
+				}
+			case nfa.StateLook:
+				if b.crossesWordBoundary(current, wordBoundarySatisfied) {
+					_, next := state.Look()
 					stack = append(stack, next)
 				}
-			case nfa.LookNoWordBoundary:
-				if !wordBoundarySatisfied && !crossedBoundary.Contains(next) {
-					crossedBoundary.Add(next)
+			case nfa.StateEpsilon:
+				if crossed {
+					stack = append(stack, state.Epsilon())
+				}
+			case nfa.StateSplit:
+				if crossed {
+					left, right := state.Split()
+					stack = append(stack, right, left)
+				}
+			case nfa.StateCapture:
+				if crossed {
+					_, _, next := state.Capture()
 					stack = append(stack, next)
 				}
 			}
-
-		case nfa.StateEpsilon:
-			// Follow epsilon transitions to reach consuming states after word boundaries
-			next := state.Epsilon()
-			if next != nfa.InvalidState && !crossedBoundary.Contains(next) {
-				crossedBoundary.Add(next)
-				stack = append(stack, next)
-			}
-
-		case nfa.StateSplit:
-			// Follow split transitions
-			left, right := state.Split()
-			if left != nfa.InvalidState && !crossedBoundary.Contains(left) {
-				crossedBoundary.Add(left)
-				stack = append(stack, left)
-			}
-			if right != nfa.InvalidState && !crossedBoundary.Contains(right) {
-				crossedBoundary.Add(right)
-				stack = append(stack, right)
-			}
-
-		case nfa.StateCapture:
-			// Follow through capture states when resolving word boundaries
-			// Fix for Issue #15: capture states are epsilon transitions
-			_, _, next := state.Capture()
-			if next != nfa.InvalidState && !crossedBoundary.Contains(next) {
-				crossedBoundary.Add(next)
-				stack = append(stack, next)
-			}
 		}
 	}
-
-	// Combine original states with states reached by crossing word boundaries
-	result := acquireStateSet()
-	for _, sid := range states {
-		result.Add(sid)
-	}
-	for _, sid := range crossedBoundary.ToSlice() {
-		result.Add(sid)
-	}
-	releaseStateSet(crossedBoundary)
-
-	// Get result slice before releasing
-	resultSlice := result.ToSlice()
-	releaseStateSet(result)
-	return resultSlice
+	return result
 }
 
 // containsMatchState returns true if any state in the set is a match state
@@ -451,21 +442,28 @@ func (b *Builder) containsMatchState(states []nfa.StateID) bool {
 //
 // This is called after the main search loop when we've exhausted input
 // but might still have pending word boundary assertions that could match.
+//
+// The two kinds of assertion can follow each other, as in `$\b` or
+// `\b$\B`, so they are applied together until the set stops growing.
 func (b *Builder) CheckEOIMatch(states []nfa.StateID, isFromWord bool) bool {
 	// At EOI, "next" byte is non-word, so:
 	// - \b is satisfied if isFromWord is true (transition from word to non-word)
 	// - \B is satisfied if isFromWord is false (staying in non-word)
 	wordBoundarySatisfied := isFromWord
 
-	// Resolve word boundary assertions
-	resolved := b.resolveWordBoundaries(states, wordBoundarySatisfied)
-
-	// Also check end-of-text assertions (\z, $)
-	// At EOI, both are satisfied
+	// End-of-text assertions (\z, $) are all satisfied at EOI.
 	lookHave := LookSetForEOI()
 
-	// Expand with end-of-text assertions
-	final := b.epsilonClosure(resolved, lookHave)
+	final := states
+	for {
+		// Both steps keep their input states, so the set only grows.
+		resolved := b.resolveWordBoundaries(final, wordBoundarySatisfied)
+		closed := b.epsilonClosure(resolved, lookHave)
+		if len(closed) == len(final) {
+			break
+		}
+		final = closed
+	}
 
 	// Check if any resulting state is a match
 	return b.containsMatchState(final)
@@ -721,7 +719,9 @@ func (b *Builder) checkHasWordBoundary() bool {
 		}
 		if state.Kind() == nfa.StateLook {
 			look, _ := state.Look()
-			if look == nfa.LookWordBoundary || look == nfa.LookNoWordBoundary {
+			switch look {
+			case nfa.LookWordBoundary, nfa.LookNoWordBoundary,
+				nfa.LookWordBoundaryUnicode, nfa.LookNoWordBoundaryUnicode:
 				return true
 			}
 		}
@@ -63,6 +63,16 @@ type DFACache struct {
 	// clearCount tracks cache clear count for NFA fallback threshold.
 	clearCount int
 
+	// clears and giveUps count the clears that made room for new states and
+	// the times a search gave up on the full cache for the NFA, over the
+	// life of the cache. Unlike clearCount, they are never reset.
+	clears  uint64
+	giveUps uint64
+
+	// look holds the states and look-around results of the look-around
+	// search (see lookaround.go); nil until the first one.
+	look *lookCache
+
 	// Statistics
 	hits   uint64
 	misses uint64
@@ -257,11 +267,13 @@ func (c *DFACache) Clear() {
 	// Clear map (GC will reclaim memory)
 	c.states = make(map[StateKey]*State)
 	c.stateList = c.stateList[:0]
+	c.flatTrans = c.flatTrans[:0] // rows of old states must not leak into new IDs
 	c.startTable = newStartTableFromByteMap(&c.startTable.byteMap)
 	c.nextID = StateID(c.stride)
 	c.clearCount = 0
 	c.hits = 0
 	c.misses = 0
+	c.look = nil
 }
 
 // ClearKeepMemory clears all states from the cache but keeps the allocated
@@ -287,9 +299,11 @@ func (c *DFACache) ClearKeepMemory() {
 		delete(c.states, k)
 	}
 	c.stateList = c.stateList[:0]
+	c.flatTrans = c.flatTrans[:0] // rows of old states must not leak into new IDs
 	c.startTable = newStartTableFromByteMap(&c.startTable.byteMap)
 	c.nextID = StateID(c.stride)
 	c.clearCount++
+	c.clears++
 }
 
 // ClearCount returns how many times the cache has been cleared.
@@ -298,6 +312,16 @@ func (c *DFACache) ClearCount() int {
 	return c.clearCount
 }
 
+// ClearStats returns how many times the cache was cleared to make room for
+// new states and how many times a search gave up on it because it was
+// still full after Config.MaxCacheClears clears, over the life of the
+// cache. A search that gives up finishes on the NFA. Clear and Reset do not
+// reset the counts, so the difference between two calls covers the
+// searches in between.
+func (c *DFACache) ClearStats() (clears, giveUps uint64) {
+	return c.clears, c.giveUps
+}
+
 // ResetClearCount resets the clear counter to zero.
 // Called at the start of each new search to give the DFA a fresh budget.
 func (c *DFACache) ResetClearCount() {
@@ -343,9 +367,11 @@ func (c *DFACache) Reset() {
 		delete(c.states, k)
 	}
 	c.stateList = c.stateList[:0]
+	c.flatTrans = c.flatTrans[:0] // rows of old states must not leak into new IDs
 	c.startTable = newStartTableFromByteMap(&c.startTable.byteMap)
 	c.nextID = StateID(c.stride)
 	c.clearCount = 0
 	c.hits = 0
 	c.misses = 0
+	c.look = nil
 }
@@ -443,3 +443,77 @@ func TestCacheStateIDAssignment(t *testing.T) {
 		}
 	}
 }
+
+func TestCacheClearStats(t *testing.T) {
+	config := DefaultConfig().WithMaxStates(3).WithMaxCacheClears(1)
+	nfaObj, err := nfa.NewDefaultCompiler().Compile("a[ab]{4}c")
+	if err != nil {
+		t.Fatalf("NFA compile error: %v", err)
+	}
+	d, err := CompileWithConfig(nfaObj, config)
+	if err != nil {
+		t.Fatalf("DFA compile error: %v", err)
+	}
+	cache := d.NewCache()
+
+	if d.IsMatch(cache, []byte("abc123")) {
+		t.Fatal("IsMatch(abc123) = true")
+	}
+	clears, giveUps := cache.ClearStats()
+	if clears != 1 || giveUps != 0 {
+		t.Errorf("ClearStats() = %d, %d, want 1, 0", clears, giveUps)
+	}
+
+	// The budget of clears is used up, so a search that needs new states
+	// gives up and finishes on the NFA. Clear resets the budget but not the
+	// counts.
+	if !d.IsMatch(cache, []byte("xx abbab abbbbc")) {
+		t.Fatal("IsMatch(xx abbab abbbbc) = false after giving up")
+	}
+	clears, giveUps = cache.ClearStats()
+	if clears != 1 || giveUps == 0 {
+		t.Errorf("ClearStats() = %d, %d after the budget ran out, want 1, 1+", clears, giveUps)
+	}
+	cache.Clear()
+	if c, g := cache.ClearStats(); c != clears || g != giveUps {
+		t.Errorf("ClearStats() = %d, %d after Clear, want %d, %d", c, g, clears, giveUps)
+	}
+}
+
+// TestCacheClearDropsTransitions checks that a state created after a clear
+// does not inherit the transitions of the state that had its ID before.
+func TestCacheClearDropsTransitions(t *testing.T) {
+	d, err := CompilePattern(`[a-z]+\d`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clears := []struct {
+		name  string
+		clear func(*DFACache)
+	}{
+		{"Clear", (*DFACache).Clear},
+		{"ClearKeepMemory", (*DFACache).ClearKeepMemory},
+		{"Reset", (*DFACache).Reset},
+	}
+	for _, tt := range clears {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := d.NewCache()
+			if got := d.Find(cache, []byte("abc1 xyz2")); got != 4 {
+				t.Fatalf("Find = %d, want 4", got)
+			}
+			tt.clear(cache)
+
+			nfaStates := []nfa.StateID{1}
+			state := NewStateWithStride(InvalidState, nfaStates, false, false, d.AlphabetLen())
+			id, err := cache.Insert(ComputeStateKey(nfaStates), state)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for class := 0; class < d.AlphabetLen(); class++ {
+				if next := cache.FlatNext(id, class); next != InvalidState {
+					t.Errorf("FlatNext(%d, %d) = %d after %s, want InvalidState", id, class, next, tt.name)
+				}
+			}
+		})
+	}
+}
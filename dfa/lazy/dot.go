@@ -0,0 +1,107 @@
+package lazy
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/coregx/coregex/internal/graph"
+)
+
+// WriteDOT writes the states that cache has materialized so far as a
+// Graphviz DOT graph, for debugging. States that searches have not reached
+// yet, and transitions that were never computed, are not shown; nor are
+// transitions to the dead state.
+//
+// Edges are labeled with the bytes of their byte classes (see ByteClasses),
+// nodes with the NFA states they stand for. Because the lazy DFA reports
+// matches one byte late, a match state is one entered after a match ended
+// at the previous byte. Start states get an entry arrow per start kind.
+func (d *DFA) WriteDOT(cache *DFACache, w io.Writer) error {
+	return d.graph(cache).WriteDOT(w)
+}
+
+// WriteJSON writes the graph of WriteDOT as JSON.
+func (d *DFA) WriteJSON(cache *DFACache, w io.Writer) error {
+	return d.graph(cache).WriteJSON(w)
+}
+
+// graph returns the graph of the states materialized in cache.
+func (d *DFA) graph(cache *DFACache) *graph.Graph {
+	g := &graph.Graph{Name: "lazy_dfa"}
+	stride := cache.stride
+	if stride == 0 {
+		return g
+	}
+	index := func(sid StateID) uint32 {
+		return uint32(sid.Offset() / stride)
+	}
+
+	starts := make(map[uint32][]string)
+	for anchored := range 2 {
+		for kind := range startKindCount {
+			sid := cache.startTable.states[anchored][kind]
+			if !cache.startTable.initialized[anchored][kind] || sid.IsDeadTag() || sid.IsInvalidTag() {
+				continue
+			}
+			name := kind.String()
+			if anchored == 1 {
+				name += " anchored"
+			}
+			id := index(sid)
+			starts[id] = append(starts[id], name)
+		}
+	}
+
+	for _, s := range cache.stateList {
+		if s == nil {
+			continue
+		}
+		id := index(s.id)
+		node := graph.Node{ID: id, Match: s.isMatch, Starts: starts[id]}
+		nfaStates := make([]string, len(s.nfaStates))
+		for i, ns := range s.nfaStates {
+			node.NFAStates = append(node.NFAStates, uint32(ns))
+			nfaStates[i] = fmt.Sprint(ns)
+		}
+		node.Label = fmt.Sprintf("%d\n{%s}", id, strings.Join(nfaStates, " "))
+		g.Nodes = append(g.Nodes, node)
+		g.Edges = append(g.Edges, d.edges(cache, id)...)
+	}
+	return g
+}
+
+// edges returns the computed transitions of the state with index id, one
+// edge per target state.
+func (d *DFA) edges(cache *DFACache, id uint32) []graph.Edge {
+	stride := cache.stride
+	start := int(id) * stride
+	if start+stride > len(cache.flatTrans) {
+		return nil
+	}
+	row := cache.flatTrans[start : start+stride]
+
+	var edges []graph.Edge
+	byTarget := make(map[StateID][]byte)
+	for b := range 256 {
+		class := b
+		if d.byteClasses != nil {
+			class = int(d.byteClasses.Get(byte(b)))
+		}
+		next := row[class]
+		if next.IsInvalidTag() || next.IsDeadTag() {
+			continue
+		}
+		next &= TagMask
+		if _, ok := byTarget[next]; !ok {
+			edges = append(edges, graph.Edge{From: id, To: uint32(next.Offset() / stride)})
+		}
+		byTarget[next] = append(byTarget[next], byte(b))
+	}
+	for i := range edges {
+		e := &edges[i]
+		e.Ranges = graph.Ranges(byTarget[StateID(int(e.To)*stride)])
+		e.Label = graph.RangesLabel(e.Ranges)
+	}
+	return edges
+}
@@ -0,0 +1,81 @@
+package lazy
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestWriteDOTMaterializedStates(t *testing.T) {
+	dfa, err := CompilePattern(`[a-c]+x`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache := dfa.NewCache()
+
+	var before bytes.Buffer
+	if err := dfa.WriteDOT(cache, &before); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(before.String(), "->") {
+		t.Errorf("fresh cache has edges:\n%s", before.String())
+	}
+
+	// The match is seen one byte late, on the '!' after it.
+	if got := dfa.Find(cache, []byte("zzabx!")); got != 5 {
+		t.Fatalf("Find = %d, want 5", got)
+	}
+	var after bytes.Buffer
+	if err := dfa.WriteDOT(cache, &after); err != nil {
+		t.Fatal(err)
+	}
+	out := after.String()
+	for _, want := range []string{"digraph lazy_dfa {", `label="[a-c]"`, `label="x"`, "shape=doublecircle", `label="Text"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("WriteDOT output lacks %q:\n%s", want, out)
+		}
+	}
+	if got, want := strings.Count(out, "[label=\"")-strings.Count(out, "->"), cache.Size(); got != want {
+		t.Errorf("WriteDOT shows %d states, want the %d cached ones:\n%s", got, want, out)
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	dfa, err := CompilePattern(`ab|cd`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache := dfa.NewCache()
+	dfa.Find(cache, []byte("xxcd"))
+
+	var b bytes.Buffer
+	if err := dfa.WriteJSON(cache, &b); err != nil {
+		t.Fatal(err)
+	}
+	var g struct {
+		Nodes []struct {
+			ID        uint32   `json:"id"`
+			NFAStates []uint32 `json:"nfa_states"`
+		} `json:"nodes"`
+		Edges []struct {
+			From uint32 `json:"from"`
+			To   uint32 `json:"to"`
+		} `json:"edges"`
+	}
+	if err := json.Unmarshal(b.Bytes(), &g); err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Nodes) != cache.Size() {
+		t.Errorf("%d nodes, want the %d cached states", len(g.Nodes), cache.Size())
+	}
+	ids := make(map[uint32]bool)
+	for _, n := range g.Nodes {
+		ids[n.ID] = true
+	}
+	for _, e := range g.Edges {
+		if !ids[e.From] || !ids[e.To] {
+			t.Errorf("edge %d -> %d references a state that is not a node", e.From, e.To)
+		}
+	}
+}
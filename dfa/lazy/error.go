@@ -43,6 +43,14 @@ var ErrInvalidConfig = &DFAError{
 	Message: "invalid DFA configuration",
 }
 
+// ErrQuit indicates that the DFA met a byte it cannot decide on its own: a
+// non-ASCII byte in a pattern with Unicode word boundaries. Searches fall
+// back to the NFA; StreamScanner.Feed returns it.
+var ErrQuit = &DFAError{
+	Kind:    Quit,
+	Message: "DFA quit on a non-ASCII byte next to a Unicode word boundary",
+}
+
 // ErrorKind classifies DFA errors into categories
 type ErrorKind uint8
 
@@ -65,6 +73,9 @@ const (
 	// NFAFallback indicates DFA gave up and fell back to NFA
 	// (not an error per se, but tracked for metrics)
 	NFAFallback
+
+	// Quit indicates the DFA met a byte it does not handle
+	Quit
 )
 
 // String returns a human-readable error kind name
@@ -80,6 +91,8 @@ func (k ErrorKind) String() string {
 		return "InvalidConfig"
 	case NFAFallback:
 		return "NFAFallback"
+	case Quit:
+		return "Quit"
 	default:
 		return fmt.Sprintf("UnknownErrorKind(%d)", k)
 	}
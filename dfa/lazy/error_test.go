@@ -17,6 +17,7 @@ func TestErrorKindString(t *testing.T) {
 		{name: "StateLimitExceeded", kind: StateLimitExceeded, want: "StateLimitExceeded"},
 		{name: "InvalidConfig", kind: InvalidConfig, want: "InvalidConfig"},
 		{name: "NFAFallback", kind: NFAFallback, want: "NFAFallback"},
+		{name: "Quit", kind: Quit, want: "Quit"},
 		{name: "unknown error kind 99", kind: ErrorKind(99), want: "UnknownErrorKind(99)"},
 		{name: "unknown error kind 255", kind: ErrorKind(255), want: "UnknownErrorKind(255)"},
 	}
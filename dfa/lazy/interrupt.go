@@ -0,0 +1,28 @@
+package lazy
+
+import (
+	"github.com/coregx/coregex/nfa"
+)
+
+// SearchAtInterruptible is like SearchAt (the end of the leftmost-first match
+// starting at or after at, or -1) but charges in for every byte scanned and
+// gives up once it fires.
+//
+// The haystack is scanned in slices of at most nfa.InterruptCheckInterval
+// bytes (see nfa.Interrupt.Allowance) with the DFA state carried across
+// slices, so the end is the same as SearchAt's; the regular search loops are
+// left untouched. from is at or before the
+// start of the match: the last slice boundary ahead of it at which no match
+// attempt in progress had started earlier, so an NFA looking for the start
+// and captures can begin there instead of at at.
+//
+// In earliest mode the scan stops at the first match end found, as IsMatch
+// does. Returns ok=false if the search was aborted (in.Stopped() is true) or
+// the DFA gave up (cache too small); in the latter case the caller falls back
+// to the NFA.
+func (d *DFA) SearchAtInterruptible(cache *DFACache, haystack []byte, at int, earliest bool, in *nfa.Interrupt) (from, end int, ok bool) {
+	if at > len(haystack) {
+		return at, -1, true
+	}
+	return d.searchSpan(cache, haystack, at, len(haystack), nfa.SpanOptions{Earliest: earliest}, in)
+}
@@ -0,0 +1,109 @@
+package lazy
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/coregx/coregex/nfa"
+)
+
+func TestSearchAtInterruptible(t *testing.T) {
+	long := strings.Repeat("x", 3*nfa.InterruptCheckInterval)
+	tests := []struct {
+		pattern  string
+		haystack string
+		at       int
+	}{
+		{`foo`, "xx foo foo", 0},
+		{`foo`, "xx foo foo", 4},
+		{`a+`, "baaab", 0},
+		{`a|ab`, "xab", 0},
+		{`abc$`, "abc abc", 0},
+		{`\bcat\b`, "concat cat", 0},
+		{`^abc`, "abc", 1},
+		{`needle`, long + "needle" + long, 0},
+		{`x+y`, long + "y", 0},
+		{`nothing`, long, 0},
+	}
+
+	for _, tt := range tests {
+		d, err := CompilePattern(tt.pattern)
+		if err != nil {
+			t.Fatalf("compile %q: %v", tt.pattern, err)
+		}
+		want := d.SearchAt(d.NewCache(), []byte(tt.haystack), tt.at)
+		in := &nfa.Interrupt{}
+		_, got, ok := d.SearchAtInterruptible(d.NewCache(), []byte(tt.haystack), tt.at, false, in)
+		if !ok || got != want {
+			t.Errorf("%q at %d: SearchAtInterruptible = %d, %v; want %d, true", tt.pattern, tt.at, got, ok, want)
+		}
+
+		_, earliest, ok := d.SearchAtInterruptible(d.NewCache(), []byte(tt.haystack), tt.at, true, in)
+		if !ok || (earliest >= 0) != (want >= 0) {
+			t.Errorf("%q at %d: earliest = %d, %v; want match %v", tt.pattern, tt.at, earliest, ok, want >= 0)
+		}
+	}
+}
+
+func TestSearchAtInterruptibleFrom(t *testing.T) {
+	long := strings.Repeat("x ", 5*nfa.InterruptCheckInterval)
+	tests := []struct {
+		pattern  string
+		haystack string
+		start    int // start of the match
+		minFrom  int // from must lie in [minFrom, start]
+	}{
+		{`needle`, long + "needle" + long, len(long), len(long) - nfa.InterruptCheckInterval},
+		{`\w+dle`, long + "needle", len(long), len(long) - nfa.InterruptCheckInterval},
+		{`(?:x )+y`, long + "y", 0, 0},
+		{`a|x`, long, 0, 0},
+	}
+
+	for _, tt := range tests {
+		d, err := CompilePattern(tt.pattern)
+		if err != nil {
+			t.Fatalf("compile %q: %v", tt.pattern, err)
+		}
+		from, end, ok := d.SearchAtInterruptible(d.NewCache(), []byte(tt.haystack), 0, false, &nfa.Interrupt{})
+		if !ok || end < 0 {
+			t.Fatalf("%q: SearchAtInterruptible = %d, %v; want a match", tt.pattern, end, ok)
+		}
+		if from < tt.minFrom || from > tt.start {
+			t.Errorf("%q: from = %d, want in [%d, %d]", tt.pattern, from, tt.minFrom, tt.start)
+		}
+	}
+}
+
+func TestSearchAtInterruptibleAborts(t *testing.T) {
+	d, err := CompilePattern(`needle`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	haystack := []byte(strings.Repeat("x", 10*nfa.InterruptCheckInterval) + "needle")
+
+	in := &nfa.Interrupt{Budget: 2 * nfa.InterruptCheckInterval}
+	if _, end, ok := d.SearchAtInterruptible(d.NewCache(), haystack, 0, false, in); ok || end != -1 {
+		t.Errorf("budget: got %d, %v; want -1, false", end, ok)
+	}
+	if !in.Stopped() || !in.BudgetExhausted() {
+		t.Errorf("budget: Stopped() = %v, BudgetExhausted() = %v", in.Stopped(), in.BudgetExhausted())
+	}
+	if in.Spent() > 4*nfa.InterruptCheckInterval {
+		t.Errorf("budget: Spent() = %d, search did not stop promptly", in.Spent())
+	}
+
+	in = &nfa.Interrupt{Budget: 10}
+	if _, _, ok := d.SearchAtInterruptible(d.NewCache(), haystack, 0, false, in); ok || in.Spent() != 11 {
+		t.Errorf("small budget: ok = %v, Spent() = %d; want false, 11", ok, in.Spent())
+	}
+
+	done := make(chan struct{})
+	close(done)
+	in = &nfa.Interrupt{Done: done}
+	if _, _, ok := d.SearchAtInterruptible(d.NewCache(), haystack, 0, false, in); ok || !in.Stopped() {
+		t.Errorf("done: ok = %v, Stopped() = %v; want false, true", ok, in.Stopped())
+	}
+	if in.BudgetExhausted() {
+		t.Error("done: BudgetExhausted() = true")
+	}
+}
@@ -13,6 +13,12 @@
 //   - Real-world regex where most states are never visited
 //   - Memory-constrained environments
 //
+// For an NFA with look-ahead or look-behind assertions (nfa.HasLookaround),
+// the forward searches decide the assertions exactly, from tables made by
+// one pass of each body over the haystack (see nfa.LookaroundMemo). The
+// reverse, stream and overlapping searches assume that every such
+// assertion holds and so match a superset of the pattern.
+//
 // Example usage:
 //
 //	// Compile pattern to DFA
@@ -36,6 +42,7 @@ package lazy
 
 import (
 	"errors"
+	"unicode/utf8"
 
 	"github.com/coregx/coregex/nfa"
 	"github.com/coregx/coregex/prefilter"
@@ -78,11 +85,21 @@ type DFA struct {
 	// When false, we can skip expensive word boundary checks in the search loop.
 	hasWordBoundary bool
 
+	// unicodeWordBoundary is true if the pattern's \b or \B use Unicode word
+	// chars. The DFA resolves them like ASCII ones, which is exact as long as
+	// the bytes on both sides are ASCII; it quits to the NFA on other bytes.
+	unicodeWordBoundary bool
+
 	// hasEndLine is true if the NFA contains EndLine ($) look assertions.
 	// When true, determinize performs look-ahead re-computation on '\n' bytes.
 	// When false (most patterns), this check is skipped entirely.
 	hasEndLine bool
 
+	// hasLookaround is true if the NFA has look-ahead or look-behind
+	// assertions. The forward searches then run the look-around search of
+	// lookaround.go.
+	hasLookaround bool
+
 	// isAlwaysAnchored is true if the pattern is inherently anchored (has ^ prefix).
 	// When true, we only need to try matching from position 0.
 	isAlwaysAnchored bool
@@ -150,6 +167,9 @@ func (d *DFA) Find(cache *DFACache, haystack []byte) int {
 // Unlike Find, it takes the FULL haystack and a starting position, so assertions
 // like ^ correctly check against the original input start, not a sliced position.
 func (d *DFA) FindAt(cache *DFACache, haystack []byte, at int) int {
+	if d.hasLookaround {
+		return d.searchLookaround(cache, haystack, at, false, false)
+	}
 	if at > len(haystack) {
 		return -1
 	}
@@ -188,6 +208,9 @@ func (d *DFA) FindAt(cache *DFACache, haystack []byte, at int) int {
 // (e.g., via reverse search) and needs forward DFA scan for greedy matching.
 // Unlike FindAt, this always uses direct DFA search, avoiding prefilter overhead.
 func (d *DFA) SearchAt(cache *DFACache, haystack []byte, at int) int {
+	if d.hasLookaround {
+		return d.searchLookaround(cache, haystack, at, false, false)
+	}
 	if at > len(haystack) {
 		return -1
 	}
@@ -217,6 +240,9 @@ func (d *DFA) SearchAt(cache *DFACache, haystack []byte, at int) int {
 // requires the match to begin exactly at position 'at' (no implicit (?s:.)*? prefix).
 // This is used by ReverseSuffix after finding match start via reverse DFA.
 func (d *DFA) SearchAtAnchored(cache *DFACache, haystack []byte, at int) int {
+	if d.hasLookaround {
+		return d.searchLookaround(cache, haystack, at, true, false)
+	}
 	if at > len(haystack) {
 		return -1
 	}
@@ -253,7 +279,7 @@ func (d *DFA) SearchAtAnchored(cache *DFACache, haystack []byte, at int) int {
 
 		if d.hasWordBoundary {
 			st := cache.getState(sid)
-			if st != nil && st.checkWordBoundaryFast(b) {
+			if st != nil && d.checkWordBoundaryFast(st, b) {
 				return pos
 			}
 		}
@@ -331,6 +357,9 @@ func (d *DFA) SearchAtAnchored(cache *DFACache, haystack []byte, at int) int {
 // then reverse DFA finds the exact start. Leftmost-longest would over-extend
 // past the first match for patterns like "[^"]*" on input with multiple matches.
 func (d *DFA) SearchFirstAt(cache *DFACache, haystack []byte, at int) int {
+	if d.hasLookaround {
+		return d.searchLookaround(cache, haystack, at, false, false)
+	}
 	if at > len(haystack) {
 		return -1
 	}
@@ -460,7 +489,7 @@ func (d *DFA) searchFirstAt(cache *DFACache, haystack []byte, startPos int) int
 
 		if d.hasWordBoundary {
 			st := cache.getState(sid)
-			if st != nil && st.checkWordBoundaryFast(haystack[pos]) {
+			if st != nil && d.checkWordBoundaryFast(st, haystack[pos]) {
 				return pos
 			}
 		}
@@ -528,6 +557,9 @@ func (d *DFA) searchFirstAt(cache *DFACache, haystack []byte, startPos int) int
 //	    fmt.Println("Pattern matches!")
 //	}
 func (d *DFA) IsMatch(cache *DFACache, haystack []byte) bool {
+	if d.hasLookaround {
+		return d.searchLookaround(cache, haystack, 0, false, true) >= 0
+	}
 	if len(haystack) == 0 {
 		return d.matchesEmpty(cache)
 	}
@@ -544,6 +576,9 @@ func (d *DFA) IsMatch(cache *DFACache, haystack []byte) bool {
 // This is O(k) where k is the distance to the first match, vs FindAt's O(n)
 // which always scans for the longest match.
 func (d *DFA) IsMatchAt(cache *DFACache, haystack []byte, at int) bool {
+	if d.hasLookaround {
+		return d.searchLookaround(cache, haystack, at, false, true) >= 0
+	}
 	if at >= len(haystack) {
 		if at == len(haystack) {
 			return d.matchesEmpty(cache)
@@ -741,7 +776,7 @@ func (d *DFA) searchEarliestMatch(cache *DFACache, haystack []byte, startPos int
 		// Check if word boundary would result in a match BEFORE consuming the byte.
 		// O(1) word boundary match check using pre-computed flags (was 30% CPU).
 		// matchAtWordBoundary/matchAtNonWordBoundary computed during determinize.
-		if d.hasWordBoundary && currentState.checkWordBoundaryFast(b) {
+		if d.hasWordBoundary && d.checkWordBoundaryFast(currentState, b) {
 			return true
 		}
 
@@ -852,7 +887,7 @@ func (d *DFA) searchEarliestMatchAnchored(cache *DFACache, haystack []byte, star
 
 		if d.hasWordBoundary {
 			st := cache.getState(sid)
-			if st != nil && st.checkWordBoundaryFast(b) {
+			if st != nil && d.checkWordBoundaryFast(st, b) {
 				return true
 			}
 		}
@@ -1334,6 +1369,12 @@ func (d *DFA) searchAt(cache *DFACache, haystack []byte, startPos int) int { //n
 //
 //	or if determinization limit exceeded.
 func (d *DFA) determinize(cache *DFACache, current *State, b byte) (*State, error) {
+	// Unicode word boundaries cannot be resolved one byte at a time.
+	// The transition is not cached, so every search reaching it quits.
+	if d.unicodeWordBoundary && b >= utf8.RuneSelf {
+		return nil, ErrQuit
+	}
+
 	// Need builder for move operations.
 	// Use NewBuilderWithWordBoundary to pass pre-computed flag and avoid O(states) scan.
 	builder := NewBuilderWithWordBoundary(d.nfa, d.config, d.hasWordBoundary)
@@ -1397,7 +1438,16 @@ func (d *DFA) determinize(cache *DFACache, current *State, b byte) (*State, erro
 	// Compute state key INCLUDING word context AND match delay flag.
 	// With match delay, the same NFA state set can produce both match and
 	// non-match DFA states (depending on whether the source had NFA match).
-	key := ComputeStateKeyWithWordAndMatch(nextNFAStates, nextIsFromWord, isMatch)
+	key := d.stateKey(ComputeStateKeyWithWordAndMatch(nextNFAStates, nextIsFromWord, isMatch), nextNFAStates)
+
+	// Multi-pattern NFAs: the delayed match must also remember WHICH patterns
+	// matched in the source state, so two sources reaching the same NFA set
+	// with different matching patterns become different DFA states.
+	var matchPatterns []nfa.PatternID
+	if isMatch && d.nfa.PatternCount() > 1 {
+		matchPatterns = collectMatchPatterns(d.nfa, currentNFAStates, nil)
+		key = key.withPatterns(matchPatterns)
+	}
 
 	// Check if state already exists in cache
 	if existing, ok := cache.Get(key); ok {
@@ -1409,17 +1459,11 @@ func (d *DFA) determinize(cache *DFACache, current *State, b byte) (*State, erro
 
 	// Create new DFA state with word context and compressed alphabet stride
 	newState := NewStateWithStride(InvalidState, nextNFAStates, isMatch, nextIsFromWord, d.AlphabetLen())
+	newState.matchPatterns = matchPatterns
 
 	// Pre-compute word boundary match flags to avoid per-byte checkWordBoundaryMatch.
 	// This eliminates the expensive Builder + resolveWordBoundaries call in the hot loop.
-	if d.hasWordBoundary && !isMatch {
-		// Check: would resolving \b (word boundary satisfied) produce a match?
-		wbStates := builder.resolveWordBoundaries(nextNFAStates, true)
-		newState.matchAtWordBoundary = builder.containsMatchState(wbStates)
-		// Check: would resolving \B (word boundary NOT satisfied) produce a match?
-		nwbStates := builder.resolveWordBoundaries(nextNFAStates, false)
-		newState.matchAtNonWordBoundary = builder.containsMatchState(nwbStates)
-	}
+	d.setWordBoundaryMatches(builder, newState)
 
 	// Insert into cache
 	_, err := cache.Insert(key, newState)
@@ -1445,6 +1489,31 @@ func (d *DFA) determinize(cache *DFACache, current *State, b byte) (*State, erro
 	return newState, nil
 }
 
+// stateKey returns the cache key of a state with the given NFA states. With
+// BreakAtMatch their priority order is part of the state (see withOrder).
+func (d *DFA) stateKey(key StateKey, nfaStates []nfa.StateID) StateKey {
+	if d.config.BreakAtMatch {
+		return key.withOrder(nfaStates)
+	}
+	return key
+}
+
+// setWordBoundaryMatches pre-computes whether resolving \b or \B in the new
+// state produces a match (see State.checkWordBoundaryFast). With BreakAtMatch
+// a match state already reports a match at this position and is skipped;
+// without it, the state's own threads may end another match one byte later.
+func (d *DFA) setWordBoundaryMatches(builder *Builder, state *State) {
+	if !d.hasWordBoundary || (state.isMatch && d.config.BreakAtMatch) {
+		return
+	}
+	// Check: would resolving \b (word boundary satisfied) produce a match?
+	wbStates := builder.resolveWordBoundaries(state.nfaStates, true)
+	state.matchAtWordBoundary = builder.containsMatchState(wbStates)
+	// Check: would resolving \B (word boundary NOT satisfied) produce a match?
+	nwbStates := builder.resolveWordBoundaries(state.nfaStates, false)
+	state.matchAtNonWordBoundary = builder.containsMatchState(nwbStates)
+}
+
 // containsNFAMatch checks if any of the given NFA state IDs is a match state.
 // Used for EOI match detection with 1-byte match delay: at end of input,
 // we check the current DFA state's NFA states directly rather than following
@@ -1472,6 +1541,7 @@ func containsNFAMatch(n *nfa.NFA, states []nfa.StateID) bool {
 func (d *DFA) tryClearCache(cache *DFACache) error {
 	// Check if we've exceeded the maximum number of cache clears
 	if cache.ClearCount() >= d.config.MaxCacheClears {
+		cache.giveUps++
 		return ErrCacheFull
 	}
 
@@ -1487,8 +1557,9 @@ func (d *DFA) tryClearCache(cache *DFACache) error {
 	startStateSet := builder.epsilonClosure([]nfa.StateID{d.nfa.StartUnanchored()}, startLook)
 	// With 1-byte match delay, start states are never match states.
 	startState := NewStateWithStride(StartState, startStateSet, false, false, d.AlphabetLen())
+	d.setWordBoundaryMatches(builder, startState)
 
-	key := ComputeStateKeyWithWord(startStateSet, false)
+	key := d.stateKey(ComputeStateKeyWithWord(startStateSet, false), startStateSet)
 	_, _ = cache.Insert(key, startState) // Cannot fail: cache was just cleared
 	cache.registerState(startState)
 
@@ -1531,7 +1602,7 @@ func (d *DFA) checkEOIMatch(state *State) bool {
 // state wasn't already a match, but resolving word boundaries produces one).
 // Returns false for patterns without word boundaries (e.g., `a*`).
 func (d *DFA) checkWordBoundaryMatch(state *State, nextByte byte) bool {
-	if state == nil {
+	if state == nil || d.unicodeWordBoundary && nextByte >= utf8.RuneSelf {
 		return false
 	}
 
@@ -1557,6 +1628,16 @@ func (d *DFA) checkWordBoundaryMatch(state *State, nextByte byte) bool {
 	return builder.containsMatchState(resolved)
 }
 
+// checkWordBoundaryFast is State.checkWordBoundaryFast for the next byte b.
+// With Unicode word boundaries a non-ASCII byte resolves nothing here: the
+// transition on it quits to the NFA, which sees the whole character.
+func (d *DFA) checkWordBoundaryFast(state *State, b byte) bool {
+	if d.unicodeWordBoundary && b >= utf8.RuneSelf {
+		return false
+	}
+	return state.checkWordBoundaryFast(b)
+}
+
 // getStartState returns the appropriate start state for the given position.
 //
 // The start state depends on:
@@ -1572,6 +1653,9 @@ func (d *DFA) getStartState(cache *DFACache, haystack []byte, pos int, anchored
 	if pos == 0 {
 		kind = StartText
 	} else {
+		if d.unicodeWordBoundary && haystack[pos-1] >= utf8.RuneSelf {
+			return nil // the look-behind byte is part of a non-ASCII character
+		}
 		kind = cache.startTable.GetKind(haystack[pos-1])
 	}
 
@@ -1585,6 +1669,8 @@ func (d *DFA) getStartState(cache *DFACache, haystack []byte, pos int, anchored
 	builder := NewBuilderWithWordBoundary(d.nfa, d.config, d.hasWordBoundary)
 	config := StartConfig{Kind: kind, Anchored: anchored}
 	state, key := ComputeStartStateWithStride(builder, d.nfa, config, d.AlphabetLen())
+	key = d.stateKey(key, state.nfaStates)
+	d.setWordBoundaryMatches(builder, state)
 
 	// Try to insert into cache using GetOrInsert
 	// This handles the case where another goroutine may have inserted it
@@ -1592,6 +1678,7 @@ func (d *DFA) getStartState(cache *DFACache, haystack []byte, pos int, anchored
 	if err != nil {
 		// Cache full - return the computed state anyway
 		// (it won't be cached, but search can continue)
+		cache.giveUps++
 		return state
 	}
 
@@ -2127,6 +2214,9 @@ func (d *DFA) getStartStateForReverse(cache *DFACache, haystack []byte, end int)
 	if end >= len(haystack) {
 		kind = StartText // End of input = "start of text" for reverse DFA
 	} else {
+		if d.unicodeWordBoundary && haystack[end] >= utf8.RuneSelf {
+			return nil
+		}
 		kind = cache.startTable.GetKind(haystack[end])
 	}
 
@@ -2140,6 +2230,8 @@ func (d *DFA) getStartStateForReverse(cache *DFACache, haystack []byte, end int)
 	builder := NewBuilderWithWordBoundary(d.nfa, d.config, d.hasWordBoundary)
 	cfg := StartConfig{Kind: kind, Anchored: false}
 	state, key := ComputeStartStateWithStride(builder, d.nfa, cfg, d.AlphabetLen())
+	key = d.stateKey(key, state.nfaStates)
+	d.setWordBoundaryMatches(builder, state)
 
 	insertedState, existed, err := cache.GetOrInsert(key, state)
 	if err != nil {
@@ -49,9 +49,9 @@ func (s LookSet) Contains(look nfa.Look) bool {
 		return s&LookStartLine != 0
 	case nfa.LookEndLine:
 		return s&LookEndLine != 0
-	case nfa.LookWordBoundary:
+	case nfa.LookWordBoundary, nfa.LookWordBoundaryUnicode:
 		return s&LookWordBoundary != 0
-	case nfa.LookNoWordBoundary:
+	case nfa.LookNoWordBoundary, nfa.LookNoWordBoundaryUnicode:
 		return s&LookNoWordBoundary != 0
 	default:
 		return false
@@ -69,9 +69,9 @@ func (s LookSet) Insert(look nfa.Look) LookSet {
 		return s | LookStartLine
 	case nfa.LookEndLine:
 		return s | LookEndLine
-	case nfa.LookWordBoundary:
+	case nfa.LookWordBoundary, nfa.LookWordBoundaryUnicode:
 		return s | LookWordBoundary
-	case nfa.LookNoWordBoundary:
+	case nfa.LookNoWordBoundary, nfa.LookNoWordBoundaryUnicode:
 		return s | LookNoWordBoundary
 	default:
 		return s
@@ -110,3 +110,92 @@ func LookSetFromStartKind(kind StartKind) LookSet {
 func LookSetForEOI() LookSet {
 	return LookEndText | LookEndLine
 }
+
+// lookPhase orders the assertions of one position by when the DFA decides
+// them: ^ and \A when the state is entered, $ before the next byte, and \b
+// and \B with it. \z only holds at the end of input, where $, \z, \b and \B
+// are decided together (see Builder.CheckEOIMatch).
+func lookPhase(look nfa.Look) int {
+	switch look {
+	case nfa.LookStartText, nfa.LookStartLine:
+		return 0
+	case nfa.LookEndLine:
+		return 1
+	case nfa.LookEndText:
+		return -1
+	default:
+		return 2
+	}
+}
+
+// HasInexactLookChain reports whether n has a zero-width assertion that can
+// be followed, without a byte in between, by one the DFA decides in an
+// earlier phase (see lookPhase), as in \B^, \z(?m:^), (?m:$)\B or
+// \b(?m:$). The DFA misses the matches that need such a chain, so a search
+// that must be exact uses the PikeVM or BoundedBacktracker instead.
+func HasInexactLookChain(n *nfa.NFA) bool {
+	var seen []bool
+	var stack []nfa.StateID
+	for i := 0; i < n.States(); i++ {
+		first := n.State(nfa.StateID(i))
+		if first.Kind() != nfa.StateLook || first.Lookaround() != nil {
+			continue
+		}
+		look, next := first.Look()
+		if lookPhase(look) == 0 {
+			continue // decided first, any assertion may follow
+		}
+		if seen == nil {
+			seen = make([]bool, n.States())
+		} else {
+			clear(seen)
+		}
+		stack = append(stack[:0], next)
+		for len(stack) > 0 {
+			id := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			s := n.State(id)
+			if s == nil || seen[id] {
+				continue
+			}
+			seen[id] = true
+			switch s.Kind() {
+			case nfa.StateLook:
+				if s.Lookaround() != nil {
+					continue
+				}
+				then, after := s.Look()
+				if inexactLookPair(look, then) {
+					return true
+				}
+				stack = append(stack, after)
+			case nfa.StateSplit:
+				left, right := s.Split()
+				stack = append(stack, left, right)
+			case nfa.StateEpsilon:
+				stack = append(stack, s.Epsilon())
+			case nfa.StateCapture:
+				_, _, after := s.Capture()
+				stack = append(stack, after)
+			}
+		}
+	}
+	return false
+}
+
+// inexactLookPair reports whether the DFA misses first followed by then.
+func inexactLookPair(first, then nfa.Look) bool {
+	p, q := lookPhase(first), lookPhase(then)
+	switch {
+	case q == 0:
+		// ^ and \A are only decided when a state is entered.
+		return p != 0
+	case p < 0 || q < 0:
+		// \z holds at the end of input, where the rest is decided with it.
+		return false
+	default:
+		// In the middle of the input $ is decided before \b and \B, and a
+		// match through both is lost in either order.
+		return p != q
+	}
+}
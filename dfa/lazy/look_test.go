@@ -18,6 +18,8 @@ func TestLookSetInsertAndContains(t *testing.T) {
 		{name: "EndLine", look: nfa.LookEndLine, lookSet: LookEndLine},
 		{name: "WordBoundary", look: nfa.LookWordBoundary, lookSet: LookWordBoundary},
 		{name: "NoWordBoundary", look: nfa.LookNoWordBoundary, lookSet: LookNoWordBoundary},
+		{name: "WordBoundaryUnicode", look: nfa.LookWordBoundaryUnicode, lookSet: LookWordBoundary},
+		{name: "NoWordBoundaryUnicode", look: nfa.LookNoWordBoundaryUnicode, lookSet: LookNoWordBoundary},
 	}
 
 	for _, tt := range tests {
@@ -292,3 +294,33 @@ func TestLookSetAllAssertions(t *testing.T) {
 		}
 	}
 }
+
+func TestHasInexactLookChain(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    bool
+	}{
+		{`\B\A`, true},
+		{`\B^`, true},
+		{`\z(?m:^)`, true},
+		{`(?m:$)\B`, true},
+		{`\b(?m:$)`, true},
+		{`(\B)(?:x|(?m:$))`, true},
+		{`^\b`, false},
+		{`(?m)^$`, false},
+		{`a$\b`, false},
+		{`$\B`, false},
+		{`\bfoo\b`, false},
+		{`\B\Bx`, false},
+		{`\Ba(?m:^)`, false},
+	}
+	for _, tt := range tests {
+		n, err := nfa.NewCompiler(nfa.CompilerConfig{UTF8: true}).Compile(tt.pattern)
+		if err != nil {
+			t.Fatalf("Compile(%q): %v", tt.pattern, err)
+		}
+		if got := HasInexactLookChain(n); got != tt.want {
+			t.Errorf("HasInexactLookChain(%q) = %v, want %v", tt.pattern, got, tt.want)
+		}
+	}
+}
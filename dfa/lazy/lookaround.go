@@ -0,0 +1,377 @@
+package lazy
+
+import (
+	"github.com/coregx/coregex/nfa"
+)
+
+// Look-around search.
+//
+// A DFA state of the regular search loops has its look assertions resolved
+// when it is built, from the bytes around the transition. That cannot work
+// for look-ahead and look-behind, whose bodies look arbitrarily far, so
+// NFAs with them (nfa.HasLookaround) are searched by the loop in this file
+// instead. Its states come in two layers:
+//   - a lookSeeds is the ordered set of NFA states entered by a byte, with
+//     its epsilon closure not taken yet. It lists the Look states the
+//     closure can reach, at most 64, as the conditions of the state.
+//   - a lookResolved is the closure of a lookSeeds under one assignment of
+//     its conditions (a bit mask), in priority order up to the first Match
+//     state. Its byte transitions lead to lookSeeds.
+//
+// At each position the search evaluates the conditions of the current
+// lookSeeds with nfa.State.LookHolds, whose look-around results come from
+// the per-search tables of the nfa.LookaroundMemo in the cache, and moves
+// to the lookResolved for the mask. Both layers are cached, so the search
+// does constant work per byte plus the conditions the states ask for, and
+// the tables cost one pass of each body.
+//
+// The look-around search is used by Find, FindAt, SearchAt, SearchFirstAt,
+// SearchAtAnchored, IsMatch and IsMatchAt. The reverse, stream and
+// overlapping searches still assume that look-around holds.
+
+// lookSeeds is an unresolved state of the look-around search.
+type lookSeeds struct {
+	epoch int // lookCache.epoch when made
+	seeds []nfa.StateID
+	looks []*nfa.State // conditions: bit i of a mask is looks[i]
+
+	// resolved maps a condition mask to its state; only is the state for
+	// mask 0 if there are no conditions.
+	resolved map[uint64]*lookResolved
+	only     *lookResolved
+}
+
+// lookResolved is a resolved state of the look-around search.
+type lookResolved struct {
+	states []nfa.StateID // byte states in priority order
+	match  bool          // a Match state comes after states
+	next   []*lookSeeds  // by byte class; nil if not computed yet
+}
+
+// lookCache is the part of a DFACache used by the look-around search.
+type lookCache struct {
+	memo     nfa.LookaroundMemo
+	seeds    map[string]*lookSeeds
+	resolved map[string]*lookResolved
+	starts   [2]*lookSeeds // unanchored and anchored start states
+	size     int           // approximate bytes used by the states
+
+	// epoch counts the clears. A search holding a state from before the
+	// last clear looks it up again, so that the old states are dropped.
+	epoch int
+
+	// seen, gen and stack are closure scratch space.
+	seen  []uint32
+	gen   uint32
+	stack []nfa.StateID
+	key   []byte
+}
+
+// maxLookConditions is the most conditions a state of the look-around
+// search can have; a search that needs more gives up for the NFA.
+const maxLookConditions = 64
+
+// ResetLookaround forgets the look-around results of the cache, which it
+// keeps for the searches of one haystack (see nfa.LookaroundMemo.Begin).
+// Call it if the haystack bytes may change before the next search.
+func (c *DFACache) ResetLookaround() {
+	if c.look != nil {
+		c.look.memo.Reset()
+	}
+}
+
+// lookCacheOf returns the look-around part of cache, creating it.
+func (d *DFA) lookCacheOf(cache *DFACache) *lookCache {
+	if cache.look == nil {
+		cache.look = &lookCache{seen: make([]uint32, d.nfa.States())}
+		cache.look.clear()
+	}
+	return cache.look
+}
+
+// clear drops all states.
+func (lc *lookCache) clear() {
+	lc.seeds = make(map[string]*lookSeeds)
+	lc.resolved = make(map[string]*lookResolved)
+	lc.starts = [2]*lookSeeds{}
+	lc.size = 0
+	lc.epoch++
+}
+
+// searchLookaround returns the end of the leftmost-first match starting at
+// or after at (exactly at if anchored), or -1. In earliest mode it returns
+// the first match end it sees. It gives up for the NFA if the cache is
+// still full after Config.MaxCacheClears clears or a state has too many
+// conditions.
+func (d *DFA) searchLookaround(cache *DFACache, haystack []byte, at int, anchored, earliest bool) int {
+	if at > len(haystack) {
+		return -1
+	}
+	lc := d.lookCacheOf(cache)
+	lc.memo.Begin(haystack, at)
+	cache.ResetClearCount()
+
+	u := d.lookStart(lc, anchored)
+	end := -1
+	for pos := at; ; pos++ {
+		if u.epoch != lc.epoch {
+			u = d.lookSeedsOf(lc, u.seeds)
+		}
+		r := u.only
+		if r == nil {
+			var ok bool
+			if r, ok = d.resolveLook(cache, lc, u, haystack, pos); !ok {
+				return d.lookaroundFallback(cache, haystack, at, anchored)
+			}
+		}
+		if r.match {
+			end = pos
+			if earliest {
+				return end
+			}
+		}
+		if len(r.states) == 0 || pos == len(haystack) {
+			return end
+		}
+		class := d.byteToClass(haystack[pos])
+		if u = r.next[class]; u == nil {
+			var ok bool
+			if u, ok = d.lookStep(cache, lc, r, haystack[pos]); !ok {
+				return d.lookaroundFallback(cache, haystack, at, anchored)
+			}
+		}
+	}
+}
+
+// lookStart returns the start state of the look-around search.
+func (d *DFA) lookStart(lc *lookCache, anchored bool) *lookSeeds {
+	i, start := 0, d.nfa.StartUnanchored()
+	if anchored {
+		i, start = 1, d.nfa.StartAnchored()
+	}
+	if lc.starts[i] == nil {
+		lc.starts[i] = d.lookSeedsOf(lc, []nfa.StateID{start})
+	}
+	return lc.starts[i]
+}
+
+// lookaroundFallback finishes a look-around search on the NFA. The
+// leftmost-first match also decides the anchored search: a match starting
+// at at is the leftmost one.
+func (d *DFA) lookaroundFallback(cache *DFACache, haystack []byte, at int, anchored bool) int {
+	cache.giveUps++
+	start, end, matched := d.pikevm.SearchAt(haystack, at)
+	if !matched || anchored && start != at {
+		return -1
+	}
+	return end
+}
+
+// resolveLook returns the state u moves to at pos. It returns false if u
+// has too many conditions or the cache is still full after
+// Config.MaxCacheClears clears.
+func (d *DFA) resolveLook(cache *DFACache, lc *lookCache, u *lookSeeds, haystack []byte, pos int) (*lookResolved, bool) {
+	if len(u.looks) > maxLookConditions {
+		return nil, false
+	}
+	var mask uint64
+	for i, st := range u.looks {
+		if st.LookHolds(&lc.memo, haystack, pos) {
+			mask |= 1 << i
+		}
+	}
+	if r := u.resolved[mask]; r != nil {
+		return r, true
+	}
+	if !d.makeLookRoom(cache, lc) {
+		return nil, false
+	}
+	r := d.closeLook(lc, u, mask)
+	u.resolved[mask] = r
+	lc.size += 16
+	return r, true
+}
+
+// makeLookRoom clears the look-around states if they use up the cache
+// capacity. It returns false if that took more than Config.MaxCacheClears
+// clears during the current search.
+func (d *DFA) makeLookRoom(cache *DFACache, lc *lookCache) bool {
+	if lc.size < cache.capacityBytes {
+		return true
+	}
+	if cache.clearCount >= d.config.MaxCacheClears {
+		return false
+	}
+	lc.clear()
+	cache.clearCount++
+	cache.clears++
+	return true
+}
+
+// closeLook takes the epsilon closure of the seeds of u under the
+// conditions of mask, in priority order, and returns the cached state for
+// the byte states it reaches before the first Match state.
+func (d *DFA) closeLook(lc *lookCache, u *lookSeeds, mask uint64) *lookResolved {
+	lc.nextGen()
+	var states []nfa.StateID
+	match := false
+seeds:
+	for _, seed := range u.seeds {
+		lc.stack = append(lc.stack[:0], seed)
+		for len(lc.stack) > 0 {
+			id := lc.stack[len(lc.stack)-1]
+			lc.stack = lc.stack[:len(lc.stack)-1]
+			if !lc.visit(id) {
+				continue
+			}
+			st := d.nfa.State(id)
+			if st == nil {
+				continue
+			}
+			switch st.Kind() {
+			case nfa.StateMatch:
+				match = true
+				break seeds
+			case nfa.StateByteRange, nfa.StateSparse:
+				states = append(states, id)
+			case nfa.StateLook:
+				if mask&(1<<lookIndex(u.looks, st)) != 0 {
+					_, next := st.Look()
+					lc.stack = append(lc.stack, next)
+				}
+			default:
+				lc.pushEpsilons(st)
+			}
+		}
+	}
+
+	key := lc.keyOf(states)
+	if match {
+		key = append(key, 1)
+	}
+	if r, ok := lc.resolved[string(key)]; ok {
+		return r
+	}
+	r := &lookResolved{states: states, match: match, next: make([]*lookSeeds, d.AlphabetLen())}
+	lc.resolved[string(key)] = r
+	lc.size += len(states)*4 + len(r.next)*8 + 64
+	return r
+}
+
+// lookStep returns the state r moves to on b and caches the transition. It
+// returns false if the cache is still full after Config.MaxCacheClears
+// clears.
+func (d *DFA) lookStep(cache *DFACache, lc *lookCache, r *lookResolved, b byte) (*lookSeeds, bool) {
+	if !d.makeLookRoom(cache, lc) {
+		return nil, false
+	}
+	lc.nextGen()
+	var seeds []nfa.StateID
+	for _, id := range r.states {
+		st := d.nfa.State(id)
+		switch st.Kind() {
+		case nfa.StateByteRange:
+			if lo, hi, next := st.ByteRange(); lo <= b && b <= hi && lc.visit(next) {
+				seeds = append(seeds, next)
+			}
+		case nfa.StateSparse:
+			for _, tr := range st.Transitions() {
+				if tr.Lo <= b && b <= tr.Hi && lc.visit(tr.Next) {
+					seeds = append(seeds, tr.Next)
+				}
+			}
+		}
+	}
+	u := d.lookSeedsOf(lc, seeds)
+	r.next[d.byteToClass(b)] = u
+	return u, true
+}
+
+// lookSeedsOf returns the cached state for seeds, with its conditions: the
+// Look states reachable from the seeds if every assertion held.
+func (d *DFA) lookSeedsOf(lc *lookCache, seeds []nfa.StateID) *lookSeeds {
+	if u, ok := lc.seeds[string(lc.keyOf(seeds))]; ok {
+		return u
+	}
+	u := &lookSeeds{epoch: lc.epoch, seeds: seeds, resolved: make(map[uint64]*lookResolved)}
+	lc.nextGen()
+	for _, seed := range seeds {
+		lc.stack = append(lc.stack[:0], seed)
+		for len(lc.stack) > 0 {
+			id := lc.stack[len(lc.stack)-1]
+			lc.stack = lc.stack[:len(lc.stack)-1]
+			if !lc.visit(id) {
+				continue
+			}
+			st := d.nfa.State(id)
+			if st == nil {
+				continue
+			}
+			if st.Kind() == nfa.StateLook {
+				u.looks = append(u.looks, st)
+				_, next := st.Look()
+				lc.stack = append(lc.stack, next)
+				continue
+			}
+			lc.pushEpsilons(st)
+		}
+	}
+	if len(u.looks) == 0 {
+		u.only = d.closeLook(lc, u, 0)
+	}
+	lc.seeds[string(lc.keyOf(seeds))] = u
+	lc.size += len(seeds)*4 + len(u.looks)*8 + 64
+	return u
+}
+
+// pushEpsilons pushes the epsilon successors of a Split, Epsilon or
+// Capture state, the preferred one last.
+func (lc *lookCache) pushEpsilons(st *nfa.State) {
+	switch st.Kind() {
+	case nfa.StateSplit:
+		left, right := st.Split()
+		lc.stack = append(lc.stack, right, left)
+	case nfa.StateEpsilon:
+		lc.stack = append(lc.stack, st.Epsilon())
+	case nfa.StateCapture:
+		_, _, next := st.Capture()
+		lc.stack = append(lc.stack, next)
+	}
+}
+
+// nextGen empties the seen set.
+func (lc *lookCache) nextGen() {
+	lc.gen++
+	if lc.gen == 0 {
+		clear(lc.seen)
+		lc.gen = 1
+	}
+}
+
+// visit adds id to the seen set and reports whether it was new.
+func (lc *lookCache) visit(id nfa.StateID) bool {
+	if int(id) >= len(lc.seen) || lc.seen[id] == lc.gen {
+		return false
+	}
+	lc.seen[id] = lc.gen
+	return true
+}
+
+// keyOf returns the map key of an ordered state set, in lc.key.
+func (lc *lookCache) keyOf(ids []nfa.StateID) []byte {
+	lc.key = lc.key[:0]
+	for _, id := range ids {
+		lc.key = append(lc.key, byte(id), byte(id>>8), byte(id>>16), byte(id>>24))
+	}
+	return lc.key
+}
+
+// lookIndex returns the condition bit of Look state st.
+func lookIndex(looks []*nfa.State, st *nfa.State) int {
+	for i, l := range looks {
+		if l == st {
+			return i
+		}
+	}
+	return 0
+}
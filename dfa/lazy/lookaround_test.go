@@ -0,0 +1,75 @@
+package lazy
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/coregx/coregex/nfa"
+)
+
+// TestLookaroundSearch checks the look-around search against the PikeVM at
+// every start position of random haystacks, with the default cache and with
+// one so small that it is cleared all the time.
+func TestLookaroundSearch(t *testing.T) {
+	patterns := []string{
+		`foo(?=bar)`,
+		`foo(?!bar)`,
+		`(?<=\$)\d+`,
+		`(?<!\$)\b\d+`,
+		`(?<=ab|c)x`,
+		`(?<=^|,)\w+`,
+		`\w+(?=,)`,
+		`(?=a)`,
+		`(?<=a)`,
+		`a(?=\b)`,
+		`(?<=a(?!b))c`,
+		`(?m)^(?=\w*\d)\w+$`,
+		`(?:a(?=b)|ab(?<=b)c|\w)+`,
+	}
+	rng := rand.New(rand.NewSource(1))
+	small := DefaultConfig()
+	small.CacheCapacityBytes = 1
+	small.MaxCacheClears = 1000
+	for _, pattern := range patterns {
+		n, err := nfa.NewDefaultCompiler().Compile(pattern)
+		if err != nil {
+			t.Fatalf("Compile(%q): %v", pattern, err)
+		}
+		pikevm := nfa.NewPikeVM(n)
+		for _, config := range []Config{DefaultConfig(), small} {
+			d, err := CompileWithConfig(n, config)
+			if err != nil {
+				t.Fatalf("CompileWithConfig(%q): %v", pattern, err)
+			}
+			cache := d.NewCache()
+			for range 50 {
+				haystack := make([]byte, rng.Intn(12))
+				for i := range haystack {
+					haystack[i] = "abcfor$1,\n "[rng.Intn(11)]
+				}
+				for at := 0; at <= len(haystack); at++ {
+					start, end, found := pikevm.SearchAt(haystack, at)
+					if !found {
+						start, end = -1, -1
+					}
+					if got := d.SearchAt(cache, haystack, at); got != end {
+						t.Errorf("%q SearchAt(%q, %d) = %d, want %d", pattern, haystack, at, got, end)
+					}
+					if got := d.IsMatchAt(cache, haystack, at); got != found {
+						t.Errorf("%q IsMatchAt(%q, %d) = %v, want %v", pattern, haystack, at, got, found)
+					}
+					want := -1
+					if start == at {
+						want = end
+					}
+					if got := d.SearchAtAnchored(cache, haystack, at); got != want {
+						t.Errorf("%q SearchAtAnchored(%q, %d) = %d, want %d", pattern, haystack, at, got, want)
+					}
+				}
+			}
+			if _, giveUps := cache.ClearStats(); giveUps != 0 {
+				t.Errorf("%q: %d searches gave up for the NFA", pattern, giveUps)
+			}
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package lazy
+
+import (
+	"github.com/coregx/coregex/nfa"
+)
+
+// WhichOverlappingMatches reports every pattern of a multi-pattern NFA that
+// matches anywhere in the haystack, in a single forward pass.
+//
+// matched must have length >= the NFA's PatternCount(). matched[pid] is set
+// to true for each matching pattern (existing true entries are left alone).
+// Returns the number of patterns newly marked and ok=true on success.
+//
+// ok=false means the DFA could not answer (the pattern has word boundary
+// assertions, or the cache was cleared more than MaxCacheClears times) and
+// the caller must fall back to nfa.PikeVM.WhichOverlappingMatches. In that
+// case matched may already contain partial results, which remain valid.
+//
+// The DFA must be compiled with BreakAtMatch=false: leftmost-first
+// determinization drops lower-priority threads after a match, which would
+// hide matches of later patterns.
+//
+// Pattern IDs travel with match states: determinize records the patterns
+// that matched in the source state (see State.MatchPatterns), so reading a
+// match-tagged transition tells exactly which patterns ended one byte
+// earlier. The search stops early once every pattern has matched.
+func (d *DFA) WhichOverlappingMatches(cache *DFACache, haystack []byte, matched []bool) (int, bool) {
+	if d.hasWordBoundary {
+		// \b and \B are resolved by per-byte look-ahead in the search loops
+		// (checkWordBoundaryFast), which does not carry pattern IDs.
+		return 0, false
+	}
+
+	total := d.nfa.PatternCount()
+	remaining := 0
+	for pid := 0; pid < total; pid++ {
+		if !matched[pid] {
+			remaining++
+		}
+	}
+	if remaining == 0 {
+		return 0, true
+	}
+
+	state := d.getStartState(cache, haystack, 0, false)
+	if state == nil {
+		return 0, false
+	}
+
+	found := 0
+	mark := func(patterns []nfa.PatternID) {
+		for _, pid := range patterns {
+			if !matched[pid] {
+				matched[pid] = true
+				found++
+				remaining--
+			}
+		}
+	}
+
+	sid := state.id
+	for pos := 0; pos < len(haystack); pos++ {
+		b := haystack[pos]
+		nextID := InvalidState
+		if offset := sid.Offset() + int(d.byteToClass(b)); offset < len(cache.flatTrans) {
+			nextID = cache.flatTrans[offset]
+		}
+
+		switch nextID {
+		case InvalidState:
+			current := cache.getState(sid)
+			if current == nil {
+				return found, false
+			}
+			next, err := d.determinize(cache, current, b)
+			if err != nil {
+				if !isCacheCleared(err) {
+					return found, false
+				}
+				// All state pointers are stale. Rebuild the current state
+				// from its NFA set and redo this byte. Its delayed match (if
+				// any) was already reported when we entered it.
+				current = d.reinsertState(cache, current.NFAStates(), current.IsFromWord(), sid.IsStartTag())
+				if current == nil {
+					return found, false
+				}
+				sid = current.id
+				pos--
+				continue
+			}
+			if next == nil {
+				// Dead state: no pattern can match any more.
+				return found, true
+			}
+			sid = next.id
+
+		case DeadState:
+			return found, true
+
+		default:
+			sid = nextID
+		}
+
+		if sid.IsMatchTag() {
+			if st := cache.getState(sid); st != nil {
+				mark(matchPatterns(st, total))
+				if remaining == 0 {
+					return found, true
+				}
+			}
+		}
+	}
+
+	// EOI: matches of the last state are still pending (1-byte delay), and
+	// end-of-text assertions ($, \z) are satisfied now.
+	if eoi := cache.getState(sid); eoi != nil {
+		builder := NewBuilderWithWordBoundary(d.nfa, d.config, d.hasWordBoundary)
+		final := builder.epsilonClosure(eoi.NFAStates(), LookSetForEOI())
+		mark(collectMatchPatterns(d.nfa, final, nil))
+	}
+
+	return found, true
+}
+
+// onlyPattern is the pattern of every match of a single-pattern DFA.
+var onlyPattern = []nfa.PatternID{0}
+
+// matchPatterns returns the patterns whose match the match state st reports
+// in a DFA of total patterns. Single-pattern DFAs record none in their
+// states (see State.MatchPatterns): all their matches are of pattern 0.
+func matchPatterns(st *State, total int) []nfa.PatternID {
+	if total == 1 {
+		return onlyPattern
+	}
+	return st.matchPatterns
+}
+
+// reinsertState re-creates a non-match DFA state for the given NFA set after
+// a cache clear, keeping the start tag if the state was a start state.
+// Returns nil if the state cannot be inserted.
+func (d *DFA) reinsertState(cache *DFACache, nfaStates []nfa.StateID, isFromWord, isStart bool) *State {
+	key := d.stateKey(ComputeStateKeyWithWordAndMatch(nfaStates, isFromWord, false), nfaStates)
+	if existing, ok := cache.Get(key); ok {
+		return existing
+	}
+	state := NewStateWithStride(InvalidState, nfaStates, false, isFromWord, d.AlphabetLen())
+	d.setWordBoundaryMatches(NewBuilderWithWordBoundary(d.nfa, d.config, d.hasWordBoundary), state)
+	if _, err := cache.Insert(key, state); err != nil {
+		return nil
+	}
+	cache.registerState(state)
+	if isStart {
+		state.id = state.id.WithStartTag()
+	}
+	return state
+}
+
+// collectMatchPatterns appends the pattern IDs of all match states in the
+// given NFA state set to dst, in ascending order without duplicates.
+func collectMatchPatterns(n *nfa.NFA, states []nfa.StateID, dst []nfa.PatternID) []nfa.PatternID {
+	start := len(dst)
+	for _, sid := range states {
+		if !n.IsMatch(sid) {
+			continue
+		}
+		pid := n.MatchPattern(sid)
+		// Insertion into the sorted tail; match states per DFA state are few.
+		i := len(dst)
+		for i > start && dst[i-1] > pid {
+			i--
+		}
+		if i > start && dst[i-1] == pid {
+			continue
+		}
+		dst = append(dst, 0)
+		copy(dst[i+1:], dst[i:])
+		dst[i] = pid
+	}
+	return dst
+}
@@ -0,0 +1,114 @@
+package lazy
+
+import (
+	"reflect"
+	"regexp"
+	"regexp/syntax"
+	"testing"
+
+	"github.com/coregx/coregex/nfa"
+)
+
+func compileSetDFA(t *testing.T, patterns []string, config Config) (*DFA, *nfa.NFA) {
+	t.Helper()
+	res := make([]*syntax.Regexp, len(patterns))
+	for i, p := range patterns {
+		re, err := syntax.Parse(p, syntax.Perl)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", p, err)
+		}
+		res[i] = re
+	}
+	n, err := nfa.NewDefaultCompiler().CompileSet(res)
+	if err != nil {
+		t.Fatalf("CompileSet: %v", err)
+	}
+	config.BreakAtMatch = false
+	d, err := CompileWithConfig(n, config)
+	if err != nil {
+		t.Fatalf("CompileWithConfig: %v", err)
+	}
+	return d, n
+}
+
+// TestWhichOverlappingMatches compares the DFA against stdlib run per pattern.
+func TestWhichOverlappingMatches(t *testing.T) {
+	tests := []struct {
+		patterns []string
+		input    string
+	}{
+		{[]string{"foo", "bar", "baz"}, "xx bar yy"},
+		{[]string{"foo", "o+", "fo"}, "foo"},
+		{[]string{"a", "b"}, "xyz"},
+		{[]string{"^a", "a$", "b"}, "ba"},
+		{[]string{"^a", "a$", "b"}, "ab"},
+		{[]string{"", "z"}, "abc"},
+		{[]string{`\d+`, `[a-z]+`, `\s`}, "abc 123"},
+		{[]string{`a+b`, `ab+`, `b`}, "aaab"},
+		{[]string{`(?i)hello`, `world$`, `^x`}, "HeLLo world"},
+		{[]string{`(?m)^b`, `c$`}, "a\nb\nc"},
+		{[]string{`héllo`, `\p{Greek}+`}, "say héllo"},
+		// A match followed by a byte that kills the DFA.
+		{[]string{`(a|b)*c`}, "cc "},
+		{[]string{`(a|b)*c`}, "c "},
+		{[]string{`(a|b)*c`}, "acx"},
+		{[]string{`a+`}, "a\xa9x"},
+		{[]string{`(a|b)*c`, `zzz`}, "acx"},
+	}
+
+	for _, tt := range tests {
+		d, _ := compileSetDFA(t, tt.patterns, DefaultConfig())
+		cache := d.NewCache()
+
+		matched := make([]bool, len(tt.patterns))
+		n, ok := d.WhichOverlappingMatches(cache, []byte(tt.input), matched)
+		if !ok {
+			t.Errorf("%q on %q: ok = false", tt.patterns, tt.input)
+			continue
+		}
+
+		want := make([]bool, len(tt.patterns))
+		wantN := 0
+		for i, p := range tt.patterns {
+			want[i] = regexp.MustCompile(p).MatchString(tt.input)
+			if want[i] {
+				wantN++
+			}
+		}
+		if !reflect.DeepEqual(matched, want) || n != wantN {
+			t.Errorf("%q on %q: matched = %v (n=%d), want %v (n=%d)",
+				tt.patterns, tt.input, matched, n, want, wantN)
+		}
+	}
+}
+
+func TestWhichOverlappingMatchesWordBoundaryFallback(t *testing.T) {
+	d, _ := compileSetDFA(t, []string{`\bfoo\b`, "bar"}, DefaultConfig())
+	matched := make([]bool, 2)
+	if _, ok := d.WhichOverlappingMatches(d.NewCache(), []byte("foo bar"), matched); ok {
+		t.Error("ok = true for word boundary set, want fallback")
+	}
+}
+
+func TestWhichOverlappingMatchesCacheClear(t *testing.T) {
+	config := DefaultConfig()
+	config.CacheCapacityBytes = 0
+	config.MaxStates = 10
+	config.MaxCacheClears = 1000
+	d, _ := compileSetDFA(t, []string{`[a-z]{3}x`, `[0-9]{4}`, `zz`}, config)
+
+	input := []byte("abcdefghij 0123 klmnopqrstuvwabcx end")
+	matched := make([]bool, 3)
+	cache := d.NewCache()
+	_, ok := d.WhichOverlappingMatches(cache, input, matched)
+	if !ok {
+		t.Fatal("ok = false")
+	}
+	if cache.clearCount == 0 {
+		t.Fatal("cache was never cleared; test does not exercise recovery")
+	}
+	want := []bool{true, true, false}
+	if !reflect.DeepEqual(matched, want) {
+		t.Errorf("matched = %v, want %v", matched, want)
+	}
+}
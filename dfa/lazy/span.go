@@ -0,0 +1,90 @@
+package lazy
+
+import (
+	"github.com/coregx/coregex/nfa"
+)
+
+// SearchSpan returns the end of the first match within haystack[start:end],
+// or -1 if there is none, scanning no further than one byte past end.
+//
+// As with nfa.PikeVM.SearchSpan, the bytes around the span stay visible to
+// look-around assertions: the start state is chosen by the byte before
+// start, as StartTable.GetKindForPosition does, and a match ending at end is
+// resolved by the byte after it, not as the end of the text.
+//
+// The match is the leftmost-first one (BreakAtMatch), or in opts.Earliest
+// mode the first to end. With opts.Full, the result is end if a match
+// covers the whole span: this needs a DFA without BreakAtMatch, which sees
+// every match end. With BreakAtMatch off and opts.Anchored, the result is
+// the end of the longest match.
+//
+// in may be nil. If it is not, the scan charges it for every byte and gives
+// up once it fires. Returns ok=false if the search was aborted
+// (in.Stopped() is true) or the DFA gave up (cache too small); in the latter
+// case the caller falls back to the NFA.
+func (d *DFA) SearchSpan(cache *DFACache, haystack []byte, start, end int, opts nfa.SpanOptions, in *nfa.Interrupt) (matchEnd int, ok bool) {
+	_, matchEnd, ok = d.searchSpan(cache, haystack, start, end, opts, in)
+	return matchEnd, ok
+}
+
+// searchSpan is SearchSpan that also returns from, a lower bound of the
+// match start: the last slice boundary before the first match end at which
+// every match attempt in progress had started there (StreamScanner.Fresh).
+func (d *DFA) searchSpan(cache *DFACache, haystack []byte, start, end int, opts nfa.SpanOptions, in *nfa.Interrupt) (from, matchEnd int, ok bool) {
+	scanner := d.NewStreamScanner(cache)
+	prev := -1
+	if start > 0 {
+		prev = int(haystack[start-1])
+	}
+	if opts.Anchored || opts.Full {
+		scanner.RestartAnchored(int64(start), prev)
+	} else {
+		scanner.Restart(int64(start), prev)
+	}
+
+	matchEnd = -1
+	stop := false
+	onMatch := func(e int64) bool {
+		if opts.Full {
+			if int(e) == end {
+				matchEnd = end
+			}
+			return true
+		}
+		matchEnd = int(e)
+		stop = opts.Earliest
+		return !stop
+	}
+
+	from = start
+	for pos := start; pos < end && !stop && !scanner.Dead(); {
+		step := end - pos
+		if in != nil {
+			step = min(step, in.Allowance())
+		}
+		n, err := scanner.Feed(haystack[pos:pos+step], onMatch)
+		if err != nil {
+			return start, -1, false
+		}
+		pos += n
+		if in != nil && in.Spend(n) {
+			return start, -1, false
+		}
+		if matchEnd < 0 && pos < end && scanner.Fresh() {
+			from = pos
+		}
+	}
+	if stop || scanner.Dead() {
+		return from, matchEnd, true
+	}
+
+	if end < len(haystack) {
+		// The byte after the span only resolves a match ending at end.
+		if _, err := scanner.Feed(haystack[end:end+1], onMatch); err != nil {
+			return start, -1, false
+		}
+	} else {
+		scanner.Finish(onMatch)
+	}
+	return from, matchEnd, true
+}
@@ -163,6 +163,11 @@ type State struct {
 	// Pre-allocated to avoid heap allocations during search.
 	nfaStates []nfa.StateID
 
+	// matchPatterns lists the patterns whose match this state reports.
+	// Only populated for match states of multi-pattern NFAs; with the 1-byte
+	// match delay these are the patterns that matched in the SOURCE state.
+	matchPatterns []nfa.PatternID
+
 	// accelBytes contains 1-3 exit bytes for accelerable states.
 	// An accelerable state is one where most bytes loop back to self,
 	// and only 1-3 bytes cause a transition to a different state.
@@ -235,10 +240,10 @@ func (s *State) IsFromWord() bool {
 // via word boundary resolution. Uses pre-computed flags — O(1), no allocation.
 // Replaces the expensive checkWordBoundaryMatch (30% CPU) which created Builder
 // and resolved word boundaries per byte.
+//
+// The flags are only set on match states of DFAs without BreakAtMatch (see
+// DFA.setWordBoundaryMatches).
 func (s *State) checkWordBoundaryFast(b byte) bool {
-	if s.isMatch {
-		return false // Already a match — let normal processing handle it
-	}
 	isBoundary := s.isFromWord != isWordByte(b)
 	if isBoundary {
 		return s.matchAtWordBoundary
@@ -246,6 +251,12 @@ func (s *State) checkWordBoundaryFast(b byte) bool {
 	return s.matchAtNonWordBoundary
 }
 
+// MatchPatterns returns the patterns reported by this match state.
+// Returns nil for non-match states and for single-pattern DFAs.
+func (s *State) MatchPatterns() []nfa.PatternID {
+	return s.matchPatterns
+}
+
 // NFAStates returns the NFA states represented by this DFA state
 func (s *State) NFAStates() []nfa.StateID {
 	return s.nfaStates
@@ -372,6 +383,36 @@ func ComputeStateKeyWithWordAndMatch(nfaStates []nfa.StateID, isFromWord bool, i
 	return StateKey(h.Sum64())
 }
 
+// withOrder mixes the order of the NFA states into the key. Used by DFAs
+// with BreakAtMatch, which drop the threads after a match: the same NFA
+// states in another priority order are a different state there.
+func (k StateKey) withOrder(nfaStates []nfa.StateID) StateKey {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte{
+		byte(k), byte(k >> 8), byte(k >> 16), byte(k >> 24),
+		byte(k >> 32), byte(k >> 40), byte(k >> 48), byte(k >> 56),
+	})
+	for _, sid := range nfaStates {
+		_, _ = h.Write([]byte{byte(sid), byte(sid >> 8), byte(sid >> 16), byte(sid >> 24)})
+	}
+	return StateKey(h.Sum64())
+}
+
+// withPatterns mixes a sorted list of matched pattern IDs into the key.
+// Used for multi-pattern match states, which must not be shared between
+// sources that matched different patterns.
+func (k StateKey) withPatterns(patterns []nfa.PatternID) StateKey {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte{
+		byte(k), byte(k >> 8), byte(k >> 16), byte(k >> 24),
+		byte(k >> 32), byte(k >> 40), byte(k >> 48), byte(k >> 56),
+	})
+	for _, pid := range patterns {
+		_, _ = h.Write([]byte{byte(pid), byte(pid >> 8), byte(pid >> 16), byte(pid >> 24)})
+	}
+	return StateKey(h.Sum64())
+}
+
 // sortStateIDs performs insertion sort on NFA state IDs.
 //
 // Insertion sort is used because:
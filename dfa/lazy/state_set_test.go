@@ -300,6 +300,20 @@ func TestComputeStateKey(t *testing.T) {
 	}
 }
 
+func TestStateKeyWithOrder(t *testing.T) {
+	// Break-at-match DFAs key states by the priority order of their threads
+	a := []nfa.StateID{1, 2, 3}
+	b := []nfa.StateID{3, 1, 2}
+	keyA := ComputeStateKey(a).withOrder(a)
+	keyB := ComputeStateKey(b).withOrder(b)
+	if keyA == keyB {
+		t.Errorf("Different order should produce different ordered keys: both %d", keyA)
+	}
+	if again := ComputeStateKey(a).withOrder(a); again != keyA {
+		t.Errorf("Same order should produce same ordered key: %d vs %d", keyA, again)
+	}
+}
+
 func TestComputeStateKeyWithWord(t *testing.T) {
 	nfaStates := []nfa.StateID{1, 2, 3}
 
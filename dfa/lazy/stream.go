@@ -0,0 +1,348 @@
+package lazy
+
+import (
+	"errors"
+
+	"github.com/coregx/coregex/nfa"
+)
+
+// StreamScanner runs the DFA over input that arrives in pieces.
+//
+// The current DFA state is carried from one Feed call to the next, so the
+// scan over chunks c1, c2, ... behaves exactly like a scan over their
+// concatenation: look-behind context (line starts, word boundaries) flows
+// across chunk boundaries with the state, and end-of-text assertions are only
+// resolved by Finish. Reported offsets are absolute stream offsets.
+//
+// The scan is unanchored and reports match ENDS: for every position at which
+// some match ends, the callback passed to Feed/Finish is invoked once. With
+// BreakAtMatch enabled (the default), lower-priority threads are dropped after
+// the first match, so the scanner goes dead once the leftmost-first match can
+// no longer be extended; with BreakAtMatch disabled every match end in the
+// stream is reported.
+//
+// Memory use is bounded by the cache capacity and is independent of stream
+// length; Feed fails with ErrCacheFull rather than exceed it. A StreamScanner is not safe for concurrent use; it owns the cache
+// it was created with for its whole lifetime.
+//
+// With Unicode word boundaries the scan stops with ErrQuit at the first
+// non-ASCII byte, since there is no NFA to fall back to.
+type StreamScanner struct {
+	dfa   *DFA
+	cache *DFACache
+
+	sid     StateID
+	started bool
+	dead    bool
+
+	// prevSID and prevByte are the state before the last byte and that
+	// byte, for Fresh. prevSID is InvalidState until a byte is consumed.
+	prevSID  StateID
+	prevByte byte
+
+	// offset is the absolute stream offset of the next byte to be fed.
+	offset int64
+
+	// lastEnd is the last match end reported, used to report each end once.
+	lastEnd int64
+}
+
+// NewStreamScanner returns a scanner positioned at the start of a stream.
+// The cache must not be used by anything else while the scanner is in use.
+func (d *DFA) NewStreamScanner(cache *DFACache) *StreamScanner {
+	s := &StreamScanner{
+		dfa:   d,
+		cache: cache,
+	}
+	s.Reset()
+	return s
+}
+
+// Reset rewinds the scanner to the start of a new stream.
+func (s *StreamScanner) Reset() {
+	s.sid = InvalidState
+	s.prevSID = InvalidState
+	s.started = false
+	s.dead = false
+	s.offset = 0
+	s.lastEnd = -1
+	s.cache.ResetClearCount()
+}
+
+// Restart abandons any match attempt in progress and repositions the scanner
+// at the absolute stream offset, as if matches could only start from there.
+// prev is the byte preceding that offset (look-behind context), or -1 if
+// there is none. The caller feeds the stream again from offset, and match
+// ends reported before the restart are reported again.
+func (s *StreamScanner) Restart(offset int64, prev int) {
+	s.restart(offset, prev, false)
+}
+
+// RestartAnchored is Restart for a single match attempt starting exactly at
+// offset: the scanner goes dead once that attempt can no longer match.
+func (s *StreamScanner) RestartAnchored(offset int64, prev int) {
+	s.restart(offset, prev, true)
+}
+
+func (s *StreamScanner) restart(offset int64, prev int, anchored bool) {
+	s.dead = false
+	s.offset = offset
+	s.lastEnd = -1
+	s.prevSID = InvalidState
+	var state *State
+	if prev < 0 {
+		state = s.dfa.getStartState(s.cache, nil, 0, anchored)
+	} else {
+		state = s.dfa.getStartState(s.cache, []byte{byte(prev)}, 1, anchored)
+	}
+	s.sid = InvalidState // quit: Feed fails
+	if state != nil {
+		s.sid = state.id
+	}
+	s.started = true
+}
+
+// Offset returns the absolute offset of the next byte to be fed.
+func (s *StreamScanner) Offset() int64 {
+	return s.offset
+}
+
+// Dead reports whether no further match is possible, whatever input follows.
+func (s *StreamScanner) Dead() bool {
+	return s.dead
+}
+
+// Fresh reports whether every match attempt in progress started at the
+// current offset: no attempt that started before it survived the last byte.
+// The stream before the offset can then be forgotten, except as look-behind
+// context, without losing the start of a match.
+//
+// The DFA state alone cannot tell: an attempt that started earlier may be in
+// the same NFA state as one that starts now (a*b after "aaa"). Fresh steps
+// the NFA threads of the previous state, without the unanchored prefix,
+// over the last byte.
+func (s *StreamScanner) Fresh() bool {
+	if s.dead || s.prevSID == InvalidState {
+		return true
+	}
+	prev := s.cache.getState(s.prevSID)
+	if prev == nil {
+		return false
+	}
+
+	d := s.dfa
+	builder := NewBuilderWithWordBoundary(d.nfa, d.config, d.hasWordBoundary)
+	states := prev.NFAStates()
+	if d.hasEndLine && s.prevByte == '\n' {
+		states = builder.epsilonClosure(states, LookEndLine)
+	}
+	attempts := make([]nfa.StateID, 0, len(states))
+	for _, id := range states {
+		if !d.isUnanchoredPrefix(id) {
+			attempts = append(attempts, id)
+		}
+	}
+	return len(builder.moveWithWordContext(attempts, s.prevByte, prev.IsFromWord())) == 0
+}
+
+// Feed scans chunk, invoking fn with the absolute end offset of every match
+// that ends before or inside it. If fn returns false the scan stops right
+// after the report; Feed then returns the number of bytes of chunk consumed,
+// and the rest of chunk must be fed again to continue.
+//
+// Returns ErrCacheFull if the DFA cache cannot hold even a single
+// transition (the cache capacity is too small for this pattern). The
+// scanner must then be Reset before it is fed again.
+func (s *StreamScanner) Feed(chunk []byte, fn func(end int64) bool) (int, error) {
+	if s.dead {
+		return len(chunk), nil
+	}
+	if !s.started {
+		s.Restart(0, -1)
+	}
+
+	if s.sid == InvalidState {
+		return 0, ErrQuit
+	}
+
+	d := s.dfa
+	cache := s.cache
+	cache.ResetClearCount()
+
+	// Consecutive failed determinizations of the same byte. A cache clear
+	// always makes room, so more than a few in a row means no progress.
+	attempts := 0
+	for i := 0; i < len(chunk); i++ {
+		b := chunk[i]
+		pos := s.offset + int64(i)
+
+		var current *State
+		breakAtMatch := false
+		if d.hasWordBoundary {
+			// \b/\B resolved by one byte of look-ahead: a match ending here.
+			current = cache.getState(s.sid)
+			if current != nil && d.checkWordBoundaryFast(current, b) {
+				if !s.report(pos, fn) {
+					s.offset += int64(i)
+					return i, nil
+				}
+				breakAtMatch = d.config.BreakAtMatch
+			}
+		}
+
+		nextID := InvalidState
+		if offset := s.sid.Offset() + int(d.byteToClass(b)); offset < len(cache.flatTrans) && !breakAtMatch {
+			nextID = cache.flatTrans[offset]
+		}
+
+		switch nextID {
+		case InvalidState:
+			if current == nil {
+				current = cache.getState(s.sid)
+			}
+			if current == nil {
+				s.offset += int64(i)
+				return i, ErrCacheFull
+			}
+			var next *State
+			var err error
+			if breakAtMatch {
+				next, err = d.breakAtWordBoundaryMatch(cache, current, b)
+			} else {
+				next, err = d.determinize(cache, current, b)
+			}
+			if err != nil {
+				if !isCacheCleared(err) && !errors.Is(err, ErrCacheFull) {
+					s.offset += int64(i)
+					return i, err
+				}
+				attempts++
+				if attempts > 2 {
+					s.offset += int64(i)
+					return i, ErrCacheFull
+				}
+				if isCacheCleared(err) {
+					// All state pointers are stale: rebuild the current state.
+					current = d.reinsertState(cache, current.NFAStates(), current.IsFromWord(), s.sid.IsStartTag())
+					if current == nil {
+						s.offset += int64(i)
+						return i, ErrCacheFull
+					}
+					s.sid = current.id
+				} else {
+					// Out of clears for this chunk. Unlike a bounded search
+					// there is no NFA to fall back to, so allow more.
+					cache.ResetClearCount()
+				}
+				i-- // redo this byte
+				continue
+			}
+			if next == nil {
+				s.dead = true
+				s.offset += int64(i) + 1
+				return i + 1, nil
+			}
+			s.prevSID, s.prevByte = s.sid, b
+			s.sid = next.id
+
+		case DeadState:
+			s.dead = true
+			s.offset += int64(i) + 1
+			return i + 1, nil
+
+		default:
+			s.prevSID, s.prevByte = s.sid, b
+			s.sid = nextID
+		}
+		attempts = 0
+
+		// 1-byte match delay: entering a match state means a match ended
+		// right before this byte.
+		if s.sid.IsMatchTag() && !s.report(pos, fn) {
+			s.offset += int64(i) + 1
+			return i + 1, nil
+		}
+	}
+
+	s.offset += int64(len(chunk))
+	return len(chunk), nil
+}
+
+// Finish resolves end-of-text assertions and reports a match ending at the
+// end of the stream, if any. Feed must not be called after Finish without
+// a Reset.
+func (s *StreamScanner) Finish(fn func(end int64) bool) {
+	if s.dead {
+		return
+	}
+	if !s.started {
+		s.Restart(0, -1)
+	}
+	s.dead = true
+	if state := s.cache.getState(s.sid); state != nil && s.dfa.checkEOIMatch(state) {
+		s.report(s.offset, fn)
+	}
+}
+
+// breakAtWordBoundaryMatch is determinize for a byte b on which a \b or \B of
+// current completes a match. determinize only breaks at matches that need no
+// look-ahead, so here, as it does for those, the threads of lower priority
+// than the match are dropped and the scan goes dead once the match can no
+// longer be extended. The transition is not cached: searches stop at the
+// match and never take it.
+func (d *DFA) breakAtWordBoundaryMatch(cache *DFACache, current *State, b byte) (*State, error) {
+	// The NFA states are resolved here, in priority order; the move must
+	// not resolve (and reorder) them again.
+	builder := NewBuilderWithWordBoundary(d.nfa, d.config, false)
+	states := current.NFAStates()
+	if d.hasEndLine && b == '\n' {
+		states = builder.epsilonClosure(states, LookEndLine)
+	}
+	states = builder.resolveWordBoundariesUntilMatch(states, current.IsFromWord() != isWordByte(b))
+	nextNFAStates := builder.moveWithWordContext(states, b, current.IsFromWord())
+	if len(nextNFAStates) == 0 {
+		return nil, nil //nolint:nilnil // dead state is valid, not an error
+	}
+
+	isFromWord := isWordByte(b)
+	key := d.stateKey(ComputeStateKeyWithWordAndMatch(nextNFAStates, isFromWord, false), nextNFAStates)
+	if existing, ok := cache.Get(key); ok {
+		return existing, nil
+	}
+	state := NewStateWithStride(InvalidState, nextNFAStates, false, isFromWord, d.AlphabetLen())
+	d.setWordBoundaryMatches(NewBuilderWithWordBoundary(d.nfa, d.config, true), state)
+	if _, err := cache.Insert(key, state); err != nil {
+		if clearErr := d.tryClearCache(cache); clearErr != nil {
+			return nil, clearErr
+		}
+		return nil, errCacheCleared
+	}
+	cache.registerState(state)
+	return state, nil
+}
+
+// report invokes fn for a match end unless that end was already reported.
+func (s *StreamScanner) report(end int64, fn func(int64) bool) bool {
+	if end == s.lastEnd {
+		return true
+	}
+	s.lastEnd = end
+	return fn(end)
+}
+
+// isUnanchoredPrefix reports whether id is a state of the (?s:.)*? prefix
+// that makes the NFA unanchored (see nfa.Compiler).
+func (d *DFA) isUnanchoredPrefix(id nfa.StateID) bool {
+	start := d.nfa.StartUnanchored()
+	if start == d.nfa.StartAnchored() {
+		return false
+	}
+	if id == start {
+		return true
+	}
+	if st := d.nfa.State(start); st != nil && st.Kind() == nfa.StateSplit {
+		_, loop := st.Split()
+		return id == loop
+	}
+	return false
+}
@@ -0,0 +1,297 @@
+package lazy
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/coregx/coregex/nfa"
+)
+
+// scanChunks feeds the chunks to a fresh scanner and collects all match ends.
+func scanChunks(t *testing.T, d *DFA, chunks ...string) ([]int64, *StreamScanner) {
+	t.Helper()
+	s := d.NewStreamScanner(d.NewCache())
+	var ends []int64
+	record := func(end int64) bool {
+		ends = append(ends, end)
+		return true
+	}
+	for _, c := range chunks {
+		if _, err := s.Feed([]byte(c), record); err != nil {
+			t.Fatalf("Feed(%q): %v", c, err)
+		}
+	}
+	s.Finish(record)
+	return ends, s
+}
+
+func TestStreamScannerAcrossChunks(t *testing.T) {
+	config := DefaultConfig()
+	config.BreakAtMatch = false
+
+	tests := []struct {
+		pattern string
+		chunks  []string
+		want    []int64
+	}{
+		{"foo", []string{"xf", "o", "ox foo"}, []int64{4, 9}},
+		{`foo\b`, []string{"fo", "o", "d foo"}, []int64{8}},
+		{`foo$`, []string{"foo", "x", "foo"}, []int64{7}},
+		{`(?m)^bar`, []string{"bar\n", "ba", "r xbar"}, []int64{3, 7}},
+		{`(?m)^bar`, []string{"foo bar", "\nbarx"}, []int64{11}}, // '\n' and 'o' differ
+		{`(?m)x$`, []string{"axa", "\nbx"}, []int64{6}},
+		{`(?m)x$`, []string{"ax", "\nbx\n"}, []int64{2, 5}},
+		{`\d+`, []string{"a1", "23b"}, []int64{2, 3, 4}},
+		{"nothing", []string{"some", "thing"}, nil},
+	}
+
+	for _, tt := range tests {
+		d, err := CompilePatternWithConfig(tt.pattern, config)
+		if err != nil {
+			t.Fatalf("compile %q: %v", tt.pattern, err)
+		}
+		got, _ := scanChunks(t, d, tt.chunks...)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q over %q: ends = %v, want %v", tt.pattern, tt.chunks, got, tt.want)
+		}
+	}
+}
+
+func TestStreamScannerDeadAndRestart(t *testing.T) {
+	d, err := CompilePattern(`^ab`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, s := scanChunks(t, d, "x", "ab")
+	if !s.Dead() {
+		t.Error("anchored scanner not dead after mismatch")
+	}
+
+	config := DefaultConfig()
+	config.BreakAtMatch = false
+	d, err = CompilePatternWithConfig(`\bab`, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s = d.NewStreamScanner(d.NewCache())
+	var ends []int64
+	record := func(end int64) bool {
+		ends = append(ends, end)
+		return true
+	}
+	if _, err := s.Feed([]byte("xxa"), record); err != nil {
+		t.Fatal(err)
+	}
+	// Restart inside "xxab" right before 'a': look-behind 'x' rules out \b.
+	s.Restart(2, 'x')
+	if _, err := s.Feed([]byte("ab ab"), record); err != nil {
+		t.Fatal(err)
+	}
+	s.Finish(record)
+	if s.Offset() != 7 {
+		t.Errorf("Offset() = %d, want 7", s.Offset())
+	}
+	if want := []int64{7}; !reflect.DeepEqual(ends, want) {
+		t.Errorf("ends = %v, want %v", ends, want)
+	}
+}
+
+// TestStreamScannerBreakAtWordBoundary checks that BreakAtMatch also ends
+// the scan after a match completed by a \b or \B, once a higher-priority
+// thread can no longer extend it.
+func TestStreamScannerBreakAtWordBoundary(t *testing.T) {
+	tests := []struct {
+		pattern string
+		input   string
+		ends    []int64
+		offset  int64
+	}{
+		{`\bfoo\b`, "foo foo foo", []int64{3}, 4},
+		{`\w+\b`, "ab cd", []int64{2}, 3},
+		{`x\B`, "axyz", []int64{2}, 3},
+		{`a\b|ab\b`, "ab a", []int64{2}, 3},
+		{`a\b|ab\b`, "ax ab", []int64{5}, 5}, // no match before the end
+		{`\b`, "  ab", []int64{2}, 3},
+		{`\B`, "ab", []int64{1}, 2},
+		{`\B`, "k=v a= b=c", []int64{6}, 7},
+		{`\B`, "==", []int64{0}, 1}, // in the start state
+	}
+
+	for _, tt := range tests {
+		d, err := CompilePattern(tt.pattern)
+		if err != nil {
+			t.Fatalf("compile %q: %v", tt.pattern, err)
+		}
+		ends, s := scanChunks(t, d, tt.input)
+		if !reflect.DeepEqual(ends, tt.ends) || s.Offset() != tt.offset {
+			t.Errorf("%q on %q: ends = %v, Offset() = %d; want %v, %d", tt.pattern, tt.input, ends, s.Offset(), tt.ends, tt.offset)
+		}
+	}
+}
+
+func TestStreamScannerStop(t *testing.T) {
+	config := DefaultConfig()
+	config.BreakAtMatch = false
+	d, err := CompilePatternWithConfig("a", config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := d.NewStreamScanner(d.NewCache())
+	chunk := []byte("aaaa")
+	var ends []int64
+	stop := func(end int64) bool {
+		ends = append(ends, end)
+		return false
+	}
+	for len(chunk) > 0 {
+		n, err := s.Feed(chunk, stop)
+		if err != nil {
+			t.Fatal(err)
+		}
+		chunk = chunk[n:]
+	}
+	s.Finish(stop)
+	if want := []int64{1, 2, 3, 4}; !reflect.DeepEqual(ends, want) {
+		t.Errorf("ends = %v, want %v", ends, want)
+	}
+}
+
+// TestStreamScannerRestartReportsAgain checks that a match end seen before a
+// Restart is reported again when the rescan reaches it.
+func TestStreamScannerRestartReportsAgain(t *testing.T) {
+	d, err := CompilePattern(`cat`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := d.NewStreamScanner(d.NewCache())
+	var ends []int64
+	record := func(end int64) bool {
+		ends = append(ends, end)
+		return true
+	}
+	if _, err := s.Feed([]byte("a cat"), record); err != nil {
+		t.Fatal(err)
+	}
+	s.Finish(record)
+	s.Restart(1, 'a')
+	if _, err := s.Feed([]byte(" cat"), record); err != nil {
+		t.Fatal(err)
+	}
+	s.Finish(record)
+	if want := []int64{5, 5}; !reflect.DeepEqual(ends, want) {
+		t.Errorf("ends = %v, want %v", ends, want)
+	}
+}
+
+// TestStreamScannerQuit checks that a scanner for a pattern with Unicode
+// word boundaries stops with ErrQuit on the first non-ASCII byte.
+func TestStreamScannerQuit(t *testing.T) {
+	n, err := nfa.NewCompiler(nfa.CompilerConfig{UTF8: true, UnicodeWordBoundary: true}).Compile(`\bab`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Without BreakAtMatch the scan goes on after the match, up to the é.
+	config := DefaultConfig()
+	config.BreakAtMatch = false
+	d, err := NewBuilder(n, config).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := d.NewStreamScanner(d.NewCache())
+	var ends []int64
+	record := func(end int64) bool {
+		ends = append(ends, end)
+		return true
+	}
+	if _, err := s.Feed([]byte("x ab"), record); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Feed([]byte(" é"), record); !errors.Is(err, ErrQuit) {
+		t.Errorf("Feed error = %v, want ErrQuit", err)
+	}
+	if want := []int64{4}; !reflect.DeepEqual(ends, want) {
+		t.Errorf("ends = %v, want %v", ends, want)
+	}
+	if got := d.Find(d.NewCache(), []byte("éab ab")); got != 7 {
+		t.Errorf("Find = %d, want 7", got)
+	}
+}
+
+func TestStreamScannerCacheFull(t *testing.T) {
+	n, err := nfa.NewCompiler(nfa.CompilerConfig{UTF8: true}).Compile(`\w+x`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Too small for the states of a single byte.
+	config := DefaultConfig()
+	config.BreakAtMatch = false
+	config.CacheCapacityBytes = 1
+	d, err := NewBuilder(n, config).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := d.NewStreamScanner(d.NewCache())
+	if _, err := s.Feed([]byte("abx"), func(int64) bool { return true }); !errors.Is(err, ErrCacheFull) {
+		t.Errorf("Feed error = %v, want ErrCacheFull", err)
+	}
+}
+
+func TestStreamScannerFresh(t *testing.T) {
+	tests := []struct {
+		pattern string
+		input   string
+		want    bool
+	}{
+		{`foo`, "", true},
+		{`foo`, "xf", false},
+		{`foo`, "xfx", true},
+		{`a*b`, "xaaa", false}, // same DFA state as after "x", but a started earlier
+		{`a*b`, "aax", true},
+		{`\w+@\w+`, "ab cd", false},
+		{`\w+@\w+`, "ab ", true},
+		{`x$`, "ax", false}, // waiting for the end of text
+		{`(?m)^ab`, "a\n", true},
+		{`^ab`, "a", false},
+	}
+
+	for _, tt := range tests {
+		d, err := CompilePattern(tt.pattern)
+		if err != nil {
+			t.Fatalf("compile %q: %v", tt.pattern, err)
+		}
+		s := d.NewStreamScanner(d.NewCache())
+		if _, err := s.Feed([]byte(tt.input), func(int64) bool { return true }); err != nil {
+			t.Fatal(err)
+		}
+		if got := s.Fresh(); got != tt.want {
+			t.Errorf("%q after %q: Fresh() = %v, want %v", tt.pattern, tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestStreamScannerRestartAnchored(t *testing.T) {
+	config := DefaultConfig()
+	config.BreakAtMatch = false
+	d, err := CompilePatternWithConfig(`a|ab+`, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := d.NewStreamScanner(d.NewCache())
+	var ends []int64
+	record := func(end int64) bool {
+		ends = append(ends, end)
+		return true
+	}
+	// Only the attempt at offset 3 counts: the later "ab" is not reported.
+	s.RestartAnchored(3, 'x')
+	if _, err := s.Feed([]byte("abbc ab"), record); err != nil {
+		t.Fatal(err)
+	}
+	if !s.Dead() {
+		t.Error("anchored scanner not dead after the attempt failed")
+	}
+	if want := []int64{4, 5, 6}; !reflect.DeepEqual(ends, want) {
+		t.Errorf("ends = %v, want %v", ends, want)
+	}
+}
@@ -0,0 +1,100 @@
+package onepass
+
+import (
+	"errors"
+
+	"github.com/coregx/coregex/internal/conv"
+	"github.com/coregx/coregex/internal/wire"
+	"github.com/coregx/coregex/nfa"
+)
+
+// errInvalidTables is returned by UnmarshalBinary for tables that do not fit
+// together.
+var errInvalidTables = errors.New("onepass: inconsistent DFA tables")
+
+// AppendBinary implements encoding.BinaryAppender. It appends the
+// transition table, byte classes, start state and match states to b.
+//
+// The format is internal to this version of coregex; it is meant to be
+// embedded in the versioned engine format of the meta package.
+func (d *DFA) AppendBinary(b []byte) ([]byte, error) {
+	b = wire.AppendInt(b, d.numCaptures)
+	b = wire.AppendInt(b, d.stateCount)
+	b = wire.AppendInt(b, d.stride)
+	b = wire.AppendUint(b, uint64(d.startState))
+	b, _ = d.classes.AppendBinary(b)
+	b = wire.AppendUint(b, uint64(len(d.table)))
+	for _, t := range d.table {
+		b = wire.AppendUint(b, uint64(t))
+	}
+	b = wire.AppendUint(b, uint64(len(d.matchStates)))
+	for i, match := range d.matchStates {
+		b = wire.AppendBool(b, match)
+		b = wire.AppendUint(b, uint64(d.matchSlots[i]))
+	}
+	return b, nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (d *DFA) MarshalBinary() ([]byte, error) {
+	return d.AppendBinary(nil)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. It replaces d with
+// the DFA encoded by AppendBinary.
+func (d *DFA) UnmarshalBinary(data []byte) error {
+	r := wire.NewReader(data)
+	decoded := &DFA{
+		numCaptures: r.Int(),
+		stateCount:  r.Int(),
+		stride:      r.Int(),
+		startState:  StateID(r.Uint32()),
+		classes:     new(nfa.ByteClasses),
+	}
+	var classes [256]byte
+	for i := range classes {
+		classes[i] = r.Byte()
+	}
+	if err := decoded.classes.UnmarshalBinary(classes[:]); err != nil {
+		r.Fail(err)
+	}
+	decoded.table = make([]Transition, r.Len())
+	for i := range decoded.table {
+		decoded.table[i] = Transition(r.Uint())
+	}
+	n := r.Len()
+	decoded.matchStates = make([]bool, n)
+	decoded.matchSlots = make([]uint32, n)
+	for i := range n {
+		decoded.matchStates[i] = r.Bool()
+		decoded.matchSlots[i] = r.Uint32()
+	}
+	if err := r.Done(); err != nil {
+		return err
+	}
+
+	decoded.alphabetLen = decoded.classes.AlphabetLen()
+	if decoded.numCaptures < 1 || decoded.numCaptures > 16 ||
+		decoded.stride != nextPowerOf2(decoded.alphabetLen) ||
+		decoded.stateCount < 0 || decoded.stateCount > int(MaxStateID) ||
+		len(decoded.table) != decoded.stateCount*decoded.stride ||
+		len(decoded.matchStates) != decoded.stateCount ||
+		int(decoded.startState) >= decoded.stateCount {
+		return errInvalidTables
+	}
+	for _, t := range decoded.table {
+		if int(t.NextState()) >= decoded.stateCount {
+			return errInvalidTables
+		}
+	}
+	decoded.stride2 = log2(decoded.stride)
+	decoded.minMatchID = StateID(conv.IntToUint32(len(decoded.matchStates)))
+	for i := len(decoded.matchStates) - 1; i >= 0; i-- {
+		if decoded.matchStates[i] {
+			decoded.minMatchID = StateID(conv.IntToUint32(i))
+			break
+		}
+	}
+	*d = *decoded
+	return nil
+}
@@ -0,0 +1,42 @@
+package onepass
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestDFABinaryRoundTrip(t *testing.T) {
+	for _, pattern := range []string{`(\d+)-(\d+)`, `([a-z]+)@([a-z]+)\.com`, `(a|b)c*`} {
+		dfa := compileOnePass(t, pattern)
+		data, err := dfa.MarshalBinary()
+		if err != nil {
+			t.Fatalf("%q: MarshalBinary: %v", pattern, err)
+		}
+		var loaded DFA
+		if err := loaded.UnmarshalBinary(data); err != nil {
+			t.Fatalf("%q: UnmarshalBinary: %v", pattern, err)
+		}
+		if !reflect.DeepEqual(&loaded, dfa) {
+			t.Errorf("%q: loaded DFA differs from the original", pattern)
+		}
+	}
+}
+
+func TestDFAUnmarshalBinaryErrors(t *testing.T) {
+	data, _ := compileOnePass(t, `(\d+)-(\d+)`).MarshalBinary()
+	var d DFA
+	for n := range len(data) {
+		if d.UnmarshalBinary(data[:n]) == nil {
+			t.Fatalf("UnmarshalBinary(data[:%d]) succeeded", n)
+		}
+	}
+	for i := range data {
+		corrupt := bytes.Clone(data)
+		corrupt[i] ^= 0xff
+		if d.UnmarshalBinary(corrupt) == nil {
+			cache := NewCache(d.NumCaptures())
+			d.Search([]byte("12-34 5-6"), cache)
+		}
+	}
+}
@@ -16,8 +16,13 @@ type Builder struct {
 	seen      *sparse.SparseSet // visited NFA states during epsilon closure
 	stack     []stackEntry      // DFS stack
 	matched   bool              // true if we've reached a match state in current closure
+	matchEnd  bool              // the match needs the end of input (\z)
 	matchMask uint32            // slot mask accumulated to reach match state
 
+	// startLooks is set if the start state's closure passes start
+	// assertions (^, \A), which only hold there.
+	startLooks bool
+
 	// DFA state being built
 	numStates  int                     // number of DFA states created
 	table      []Transition            // transition table
@@ -32,8 +37,9 @@ type Builder struct {
 
 // stackEntry represents an entry in the DFS stack during epsilon closure.
 type stackEntry struct {
-	nfaID nfa.StateID
-	slots uint32 // slot mask accumulated along epsilon path
+	nfaID    nfa.StateID
+	slots    uint32 // slot mask accumulated along epsilon path
+	afterEnd bool   // an end of text assertion (\z) is on the epsilon path
 }
 
 // Build attempts to build a one-pass DFA from the given NFA.
@@ -113,7 +119,7 @@ func (b *Builder) buildState(nfaRoot nfa.StateID) (StateID, error) {
 	}
 
 	// Compute epsilon closure with one-pass checking
-	closure, isMatch, err := b.epsilonClosureOnePass(nfaRoot)
+	closure, isMatch, err := b.epsilonClosureOnePass(nfaRoot, nfaRoot == b.nfa.StartAnchored())
 	if err != nil {
 		return 0, err
 	}
@@ -150,22 +156,31 @@ func (b *Builder) buildState(nfaRoot nfa.StateID) (StateID, error) {
 }
 
 // closureEntry represents a state in the epsilon closure with accumulated slots.
+// matchWins is set for states reached after a match that needs no end of
+// input, which has priority over their transitions under leftmost-first
+// semantics.
 type closureEntry struct {
-	nfaID nfa.StateID
-	slots uint32
+	nfaID     nfa.StateID
+	slots     uint32
+	matchWins bool
 }
 
 // epsilonClosureOnePass computes epsilon closure while checking one-pass property.
 // Returns (closure entries with slots, isMatch, error).
 // If isMatch is true, b.matchMask contains the slot mask to apply at match.
-func (b *Builder) epsilonClosureOnePass(root nfa.StateID) ([]closureEntry, bool, error) {
+// atStart is set for the closure of the start state, the only one in which
+// start assertions hold.
+//
+//nolint:gocognit,gocyclo,cyclop // one case per NFA state kind
+func (b *Builder) epsilonClosureOnePass(root nfa.StateID, atStart bool) ([]closureEntry, bool, error) {
 	b.seen.Clear()
 	b.matched = false
+	b.matchEnd = false
 	b.matchMask = 0
 	b.stack = b.stack[:0]
 
 	// Start DFS from root
-	if err := b.stackPush(root, 0); err != nil {
+	if err := b.stackPush(root, 0, false); err != nil {
 		return nil, false, err
 	}
 
@@ -178,9 +193,11 @@ func (b *Builder) epsilonClosureOnePass(root nfa.StateID) ([]closureEntry, bool,
 
 		nfaID := entry.nfaID
 		slots := entry.slots
+		afterEnd := entry.afterEnd
 
-		// Save this entry with accumulated slots
-		closure = append(closure, closureEntry{nfaID, slots})
+		// Save this entry with accumulated slots. Entries are popped in
+		// priority order, so a match seen before this one wins over it.
+		closure = append(closure, closureEntry{nfaID, slots, b.matched && !b.matchEnd})
 
 		state := b.nfa.State(nfaID)
 		if state == nil {
@@ -194,24 +211,26 @@ func (b *Builder) epsilonClosureOnePass(root nfa.StateID) ([]closureEntry, bool,
 				return nil, false, ErrNotOnePass
 			}
 			b.matched = true
+			b.matchEnd = afterEnd
 			// Save the slots accumulated to reach match state
 			// These are the capture END positions
 			b.matchMask = slots
 
 		case nfa.StateSplit:
-			// Follow both epsilon paths
+			// Follow both epsilon paths; left is pushed last so that it is
+			// popped first, as it has priority.
 			left, right := state.Split()
-			if err := b.stackPush(left, slots); err != nil {
+			if err := b.stackPush(right, slots, afterEnd); err != nil {
 				return nil, false, err
 			}
-			if err := b.stackPush(right, slots); err != nil {
+			if err := b.stackPush(left, slots, afterEnd); err != nil {
 				return nil, false, err
 			}
 
 		case nfa.StateEpsilon:
 			// Follow epsilon transition
 			next := state.Epsilon()
-			if err := b.stackPush(next, slots); err != nil {
+			if err := b.stackPush(next, slots, afterEnd); err != nil {
 				return nil, false, err
 			}
 
@@ -225,24 +244,43 @@ func (b *Builder) epsilonClosureOnePass(root nfa.StateID) ([]closureEntry, bool,
 			if slotIdx < 32 {
 				slots |= (1 << slotIdx)
 			}
-			if err := b.stackPush(next, slots); err != nil {
+			if err := b.stackPush(next, slots, afterEnd); err != nil {
 				return nil, false, err
 			}
 
 		case nfa.StateLook:
 			// Handle anchors (^, $, \A, \z) as epsilon transitions.
 			// For onepass DFA (which is always anchored at start):
-			// - Start anchors (^, \A): Always satisfied - follow epsilon
-			// - End anchors ($, \z): Follow epsilon; match checked at input end
-			_, next := state.Look()
+			// - Start anchors (^, \A): Only satisfied in the start state -
+			//   follow epsilon there
+			// - End of text ($, \z): Follow epsilon; match checked at input end,
+			//   so no byte may be consumed after them
+			// Multi-line $ also holds before '\n', and word boundaries depend on
+			// the bytes on both sides, which the transitions do not see.
+			look, next := state.Look()
+			switch look {
+			case nfa.LookStartText, nfa.LookStartLine:
+				if !atStart {
+					return nil, false, ErrNotOnePass
+				}
+				b.startLooks = true
+			case nfa.LookEndText:
+				afterEnd = true
+			default:
+				return nil, false, ErrNotOnePass
+			}
 			if next != nfa.InvalidState {
-				if err := b.stackPush(next, slots); err != nil {
+				if err := b.stackPush(next, slots, afterEnd); err != nil {
 					return nil, false, err
 				}
 			}
 
-			// ByteRange and Sparse are not epsilon transitions
-			// They will be handled in buildTransitions
+		case nfa.StateByteRange, nfa.StateSparse:
+			// Byte transitions are handled in buildTransitions; after an
+			// end assertion the input must have ended.
+			if afterEnd {
+				return nil, false, ErrNotOnePass
+			}
 		}
 	}
 
@@ -251,7 +289,7 @@ func (b *Builder) epsilonClosureOnePass(root nfa.StateID) ([]closureEntry, bool,
 
 // stackPush adds an NFA state to the DFS stack.
 // Returns error if state already visited (indicates non-one-pass).
-func (b *Builder) stackPush(nfaID nfa.StateID, slots uint32) error {
+func (b *Builder) stackPush(nfaID nfa.StateID, slots uint32, afterEnd bool) error {
 	// Check if already visited via epsilon path
 	if b.seen.Contains(uint32(nfaID)) {
 		// Multiple epsilon paths to same state = NOT one-pass
@@ -259,7 +297,7 @@ func (b *Builder) stackPush(nfaID nfa.StateID, slots uint32) error {
 	}
 
 	b.seen.Insert(uint32(nfaID))
-	b.stack = append(b.stack, stackEntry{nfaID, slots})
+	b.stack = append(b.stack, stackEntry{nfaID, slots, afterEnd})
 	return nil
 }
 
@@ -267,6 +305,7 @@ func (b *Builder) stackPush(nfaID nfa.StateID, slots uint32) error {
 type transInfo struct {
 	targetNFA nfa.StateID
 	slots     uint32 // Slots accumulated from SOURCE epsilon closure
+	matchWins bool   // the closure's match has priority over the transition
 }
 
 // buildTransitions builds byte transitions for a DFA state.
@@ -303,11 +342,13 @@ func (b *Builder) buildTransitions(tableIdx int, closure []closureEntry) error {
 					byteTransitions[class] = transInfo{
 						targetNFA: next,
 						slots:     existing.slots | entry.slots,
+						matchWins: existing.matchWins && entry.matchWins,
 					}
 				} else {
 					byteTransitions[class] = transInfo{
 						targetNFA: next,
 						slots:     entry.slots, // SOURCE slots!
+						matchWins: entry.matchWins,
 					}
 				}
 			}
@@ -325,11 +366,13 @@ func (b *Builder) buildTransitions(tableIdx int, closure []closureEntry) error {
 						byteTransitions[class] = transInfo{
 							targetNFA: trans.Next,
 							slots:     existing.slots | entry.slots,
+							matchWins: existing.matchWins && entry.matchWins,
 						}
 					} else {
 						byteTransitions[class] = transInfo{
 							targetNFA: trans.Next,
 							slots:     entry.slots, // SOURCE slots!
+							matchWins: entry.matchWins,
 						}
 					}
 				}
@@ -339,6 +382,11 @@ func (b *Builder) buildTransitions(tableIdx int, closure []closureEntry) error {
 
 	// Build DFA transitions from byte transitions
 	for class, info := range byteTransitions {
+		// The start state's closure assumed its start assertions hold.
+		if b.startLooks && info.targetNFA == b.nfa.StartAnchored() {
+			return ErrNotOnePass
+		}
+
 		// Recursively build target DFA state
 		nextDFA, err := b.buildState(info.targetNFA)
 		if err != nil {
@@ -346,7 +394,7 @@ func (b *Builder) buildTransitions(tableIdx int, closure []closureEntry) error {
 		}
 
 		// Create transition with SOURCE slots (applied at current position BEFORE consuming byte)
-		trans := NewTransition(nextDFA, false, info.slots)
+		trans := NewTransition(nextDFA, info.matchWins, info.slots)
 
 		// Store in table
 		idx := tableIdx + int(class)
@@ -378,6 +426,11 @@ func IsOnePass(n *nfa.NFA) bool {
 		return false
 	}
 
+	// Look-ahead and look-behind depend on input the DFA does not track
+	if n.HasLookaround() {
+		return false
+	}
+
 	// Heuristic: small NFAs are more likely to be one-pass
 	// But we can't definitively say without full analysis
 	return true
@@ -319,6 +319,11 @@ func TestSearchCaptureVsStdlib(t *testing.T) {
 		{`([a-z]+)\s+([a-z]+)`, "hello world"},
 		{`(\d+)-(\d+)-(\d+)`, "2025-01-15"},
 		{`a(b|c)d`, "abd"},
+		// A match stops the search when it has priority over the next byte.
+		{`(a+?)`, "aaa"},
+		{`(a*?)b`, "aab"},
+		{`(()| )\w?`, " _= "},
+		{`(a)$`, "ab"},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,83 @@
+package onepass
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/coregx/coregex/internal/graph"
+)
+
+// WriteDOT writes the one-pass DFA as a Graphviz DOT graph, for debugging.
+//
+// Edges are labeled with the bytes of their byte classes and the capture
+// slots the transition saves before consuming the byte; slot 2i is the
+// start and 2i+1 the end of group i. Match states show the slots saved when
+// the match ends in them. Transitions to the dead state are not shown.
+func (d *DFA) WriteDOT(w io.Writer) error {
+	return d.graph().WriteDOT(w)
+}
+
+// WriteJSON writes the graph of WriteDOT as JSON, with the slot masks as
+// numbers.
+func (d *DFA) WriteJSON(w io.Writer) error {
+	return d.graph().WriteJSON(w)
+}
+
+// graph returns the state graph of the DFA.
+func (d *DFA) graph() *graph.Graph {
+	g := &graph.Graph{Name: "onepass_dfa", Nodes: make([]graph.Node, 0, d.stateCount)}
+	for sid := range StateID(d.stateCount) {
+		node := graph.Node{ID: uint32(sid), Label: fmt.Sprint(sid), Match: d.isMatchState(sid)}
+		if node.Match {
+			node.Slots = d.getMatchSlots(sid)
+			node.Label += "\nslots " + graph.SlotsLabel(node.Slots)
+		}
+		if sid == d.startState {
+			node.Starts = []string{"anchored"}
+		}
+		g.Nodes = append(g.Nodes, node)
+		g.Edges = append(g.Edges, d.edges(sid)...)
+	}
+	return g
+}
+
+// edges returns the transitions of state sid, one edge per distinct
+// transition, in order of first byte.
+func (d *DFA) edges(sid StateID) []graph.Edge {
+	var (
+		edges []graph.Edge
+		trans []Transition
+		bytes [][]byte
+	)
+	index := make(map[Transition]int)
+	for b := range 256 {
+		t := d.getTransition(sid, d.classes.Get(byte(b)))
+		if t.IsDead() {
+			continue
+		}
+		i, ok := index[t]
+		if !ok {
+			i = len(edges)
+			index[t] = i
+			edges = append(edges, graph.Edge{From: uint32(sid), To: uint32(t.NextState()), Slots: t.SlotMask()})
+			trans = append(trans, t)
+			bytes = append(bytes, nil)
+		}
+		bytes[i] = append(bytes[i], byte(b))
+	}
+	for i := range edges {
+		e := &edges[i]
+		e.Ranges = graph.Ranges(bytes[i])
+		e.Label = graph.RangesLabel(e.Ranges)
+		if e.Slots != 0 {
+			e.Label += "\nslots " + graph.SlotsLabel(e.Slots)
+		}
+		if look := trans[i].LookAround(); look != 0 {
+			e.Label += fmt.Sprintf("\nlook %#x", look)
+		}
+		if trans[i].IsMatchWins() {
+			e.Label += "\nmatch wins"
+		}
+	}
+	return edges
+}
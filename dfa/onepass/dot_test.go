@@ -0,0 +1,72 @@
+package onepass
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestWriteDOT(t *testing.T) {
+	dfa := compileOnePass(t, `(\d+)-(a|b)`)
+	if dfa == nil {
+		return
+	}
+	var b bytes.Buffer
+	if err := dfa.WriteDOT(&b); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	for _, want := range []string{
+		"digraph onepass_dfa {",
+		`start_0_0 -> 0 [label="anchored"];`,
+		`label="[0-9]\nslots {2}"`,
+		`label="\\-\nslots {3}"`,
+		`label="[ab]\nslots {4}"`,
+		`slots {5}", shape=doublecircle`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("WriteDOT output lacks %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	dfa := compileOnePass(t, `(a)b`)
+	if dfa == nil {
+		return
+	}
+	var b bytes.Buffer
+	if err := dfa.WriteJSON(&b); err != nil {
+		t.Fatal(err)
+	}
+	var g struct {
+		Nodes []struct {
+			ID    uint32 `json:"id"`
+			Match bool   `json:"match"`
+			Slots uint32 `json:"slots"`
+		} `json:"nodes"`
+		Edges []struct {
+			Slots uint32 `json:"slots"`
+		} `json:"edges"`
+	}
+	if err := json.Unmarshal(b.Bytes(), &g); err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Nodes) != dfa.stateCount {
+		t.Errorf("%d nodes, want %d", len(g.Nodes), dfa.stateCount)
+	}
+	var mask uint32
+	for _, e := range g.Edges {
+		mask |= e.Slots
+	}
+	for _, n := range g.Nodes {
+		if n.Match {
+			mask |= n.Slots
+		}
+	}
+	// Group 1 starts before 'a' and ends before 'b'.
+	if want := uint32(1<<2 | 1<<3); mask&want != want {
+		t.Errorf("slot masks %#b lack slots 2 and 3:\n%s", mask, b.String())
+	}
+}
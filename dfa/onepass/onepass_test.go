@@ -58,6 +58,8 @@ var notOnePassPatterns = []struct {
 	{`a*a`, "ambiguous repetition"},
 	{`(.*) (.*)`, "ambiguous greedy groups"},
 	{`(.*)x`, "greedy ambiguity"},
+	{`^(a)\b(b)`, "word boundary"},
+	{`^(a)\B(b)`, "not word boundary"},
 }
 
 func TestBuildOnePass(t *testing.T) {
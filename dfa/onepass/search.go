@@ -33,6 +33,18 @@ func (d *DFA) Search(input []byte, cache *Cache) []int {
 		class := d.classes.Get(b)
 		trans := d.getTransition(state, class)
 
+		// Leftmost-first: a match in the current state ends the search if it
+		// has priority over the transition.
+		if trans.IsMatchWins() && d.isMatchState(state) {
+			// Apply match slots (capture END positions from match state's epsilon closure)
+			applyMatchSlots(cache.slots, d.getMatchSlots(state), pos)
+			// Set end of entire match (group 0)
+			if len(cache.slots) >= 2 {
+				cache.slots[1] = pos
+			}
+			return cache.slots
+		}
+
 		// Check for dead state (no match)
 		if trans.IsDead() {
 			return nil
@@ -47,20 +59,7 @@ func (d *DFA) Search(input []byte, cache *Cache) []int {
 		pos++
 
 		// Transition to next state
-		nextState := trans.NextState()
-
-		// Check for match (leftmost-first: return on first match if match-wins)
-		if trans.IsMatchWins() && d.isMatchState(nextState) {
-			// Apply match slots (capture END positions from match state's epsilon closure)
-			applyMatchSlots(cache.slots, d.getMatchSlots(nextState), pos)
-			// Set end of entire match (group 0)
-			if len(cache.slots) >= 2 {
-				cache.slots[1] = pos
-			}
-			return cache.slots
-		}
-
-		state = nextState
+		state = trans.NextState()
 	}
 
 	// Check final state for match
@@ -119,3 +119,33 @@ func ExampleRegex_NumSubexp() {
 	fmt.Println("Number of groups:", re.NumSubexp())
 	// Output: Number of groups: 3
 }
+
+// ExampleSet_Matches demonstrates reporting every pattern that matches.
+func ExampleSet_Matches() {
+	set := coregex.MustCompileSet([]string{`(?i)select\s`, `<script`, `\.\./`})
+	fmt.Println(set.MatchesString("GET /../etc?q=SELECT * FROM users"))
+	// Output: [0 2]
+}
+
+// ExampleSet_FindAll demonstrates matches tagged with pattern indices.
+func ExampleSet_FindAll() {
+	set := coregex.MustCompileSet([]string{`\d+`, `[a-z]+`})
+	for _, m := range set.FindAllString("ab 12", -1) {
+		fmt.Println(m.Pattern, m.Start, m.End)
+	}
+	// Output:
+	// 1 0 2
+	// 0 3 5
+}
+
+// ExampleRegex_Explain shows how to check which strategy a pattern uses.
+func ExampleRegex_Explain() {
+	plan := coregex.MustCompile(`\w+@\w+\.com`).Explain()
+	fmt.Println(plan.Strategy)
+	fmt.Println(plan.Suffixes.Get(0))
+	fmt.Println(plan.ReverseDFA)
+	// Output:
+	// UseReverseSuffix
+	// literal{.com, complete=false}
+	// true
+}
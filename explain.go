@@ -0,0 +1,27 @@
+package coregex
+
+import (
+	"github.com/coregx/coregex/meta"
+)
+
+// Plan describes how a Regex was compiled: the search strategy and why it
+// was chosen, the NFA sizes, the prefix, suffix and inner literals, the
+// prefilter, and which auxiliary engines were built. See Regex.Explain.
+type Plan = meta.Plan
+
+// PrefilterPlan describes the prefilter of a Plan: its kind, whether it is
+// fast and complete, and its heap size.
+type PrefilterPlan = meta.PrefilterPlan
+
+// Explain returns how the regex was compiled. It reports what the
+// COREGEX_DEBUG environment variable prints at compile time, for code that
+// vets patterns: a Strategy of meta.UseNFA, for example, means every search
+// runs the PikeVM.
+//
+// Example:
+//
+//	plan := coregex.MustCompile(`\w+@\w+\.com`).Explain()
+//	fmt.Println(plan.Strategy, plan.Reason) // UseReverseSuffix suffix literal prefilter + ...
+func (r *Regex) Explain() Plan {
+	return r.engine.Explain()
+}
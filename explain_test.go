@@ -0,0 +1,35 @@
+package coregex
+
+import (
+	"testing"
+
+	"github.com/coregx/coregex/meta"
+)
+
+func TestRegexExplain(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		options  CompileOptions
+		strategy meta.Strategy
+		kind     string
+	}{
+		{`hello`, CompileOptions{}, meta.UseDFA, "memmem"},
+		{`foo|bar|baz`, CompileOptions{}, meta.UseTeddy, "Teddy (SSSE3 slim)"},
+		{`\w+(?<=x)`, CompileOptions{}, meta.UseNFA, ""},
+		{`HELLO`, CompileOptions{CaseInsensitive: true}, meta.UseTeddy, "Teddy (SSSE3 slim)"},
+	}
+	for _, tt := range tests {
+		re, err := CompileWithOptions(tt.pattern, tt.options)
+		if err != nil {
+			t.Fatalf("Compile(%q): %v", tt.pattern, err)
+		}
+		plan := re.Explain()
+		if plan.Strategy != tt.strategy || plan.Prefilter.Kind != tt.kind {
+			t.Errorf("%q: plan %s with prefilter %q, want %s with %q",
+				tt.pattern, plan.Strategy, plan.Prefilter.Kind, tt.strategy, tt.kind)
+		}
+		if plan.Reason == "" || plan.NFAStates == 0 {
+			t.Errorf("%q: plan %+v has no reason or NFA states", tt.pattern, plan)
+		}
+	}
+}
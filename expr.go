@@ -0,0 +1,70 @@
+package coregex
+
+import (
+	"regexp/syntax"
+
+	buildpkg "github.com/coregx/coregex/build" // build is a test helper of this package
+	"github.com/coregx/coregex/meta"
+)
+
+// CompileExpr compiles a pattern built with the build package.
+//
+// The Regex's String method returns the pattern text of e, which Compile
+// accepts as well.
+//
+// Example:
+//
+//	hosts := []string{"example.com", "example.org"}
+//	alts := make([]build.Expr, len(hosts))
+//	for i, h := range hosts {
+//	    alts[i] = build.Lit(h)
+//	}
+//	re, err := coregex.CompileExpr(build.Concat(
+//	    build.Anchor(build.WordBoundary),
+//	    build.Alt(alts...),
+//	    build.Anchor(build.WordBoundary),
+//	))
+func CompileExpr(e buildpkg.Expr) (*Regex, error) {
+	return CompileExprWithConfig(e, meta.DefaultConfig())
+}
+
+// MustCompileExpr is like CompileExpr but panics if e cannot be built.
+func MustCompileExpr(e buildpkg.Expr) *Regex {
+	re, err := CompileExpr(e)
+	if err != nil {
+		panic("regexp: CompileExpr: " + err.Error())
+	}
+	return re
+}
+
+// CompileExprWithConfig compiles a pattern built with the build package with
+// a custom configuration. The flags of config.SyntaxFlags apply to the whole
+// expression (see build.RegexpWithFlags); Verbose and ExtendedSyntax are
+// ignored.
+func CompileExprWithConfig(e buildpkg.Expr, config meta.Config) (*Regex, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	flags := config.SyntaxFlags
+	if flags == 0 {
+		flags = syntax.Perl
+	}
+	re, err := buildpkg.RegexpWithFlags(e, flags)
+	if err != nil {
+		return nil, err
+	}
+
+	// The pattern text is parsed again by LiteralPrefix, so it must not be
+	// read in verbose mode or with extended syntax.
+	config.Verbose = false
+	config.ExtendedSyntax = false
+	pattern := re.String()
+	engine, err := meta.CompileRegexp(re, config)
+	if err != nil {
+		return nil, err
+	}
+	return &Regex{
+		engine:  engine,
+		pattern: pattern,
+	}, nil
+}
@@ -0,0 +1,92 @@
+package coregex
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	buildpkg "github.com/coregx/coregex/build"
+)
+
+func TestCompileExpr(t *testing.T) {
+	b := struct {
+		Lit    func(string) buildpkg.Expr
+		Class  func(string) buildpkg.Expr
+		Concat func(...buildpkg.Expr) buildpkg.Expr
+		Alt    func(...buildpkg.Expr) buildpkg.Expr
+		Plus   func(buildpkg.Expr) buildpkg.Expr
+		Group  func(string, buildpkg.Expr) buildpkg.Expr
+	}{buildpkg.Lit, buildpkg.Class, buildpkg.Concat, buildpkg.Alt, buildpkg.Plus, buildpkg.Group}
+
+	hosts := []string{"example.com", "a+b.example.org", "[::1]"}
+	alts := make([]buildpkg.Expr, len(hosts))
+	for i, h := range hosts {
+		alts[i] = b.Lit(h)
+	}
+	lib := buildpkg.NewLibrary()
+	lib.Define("host", b.Alt(alts...))
+	e := b.Concat(
+		b.Group("user", b.Plus(b.Class(`\w.`))),
+		b.Lit("@"),
+		b.Group("host", lib.Ref("host")),
+	)
+
+	re, err := CompileExpr(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := "to: bob@a+b.example.org, root@[::1], x@ab.example.org"
+	want := [][]string{
+		{"bob@a+b.example.org", "bob", "a+b.example.org"},
+		{"root@[::1]", "root", "[::1]"},
+	}
+	if got := re.FindAllStringSubmatch(input, -1); !reflect.DeepEqual(got, want) {
+		t.Errorf("FindAllStringSubmatch = %q, want %q", got, want)
+	}
+	if got, want := re.SubexpNames(), []string{"", "user", "host"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("SubexpNames = %q, want %q", got, want)
+	}
+
+	// String returns pattern text that compiles to the same regex.
+	same := MustCompile(re.String())
+	if got := same.FindAllStringSubmatch(input, -1); !reflect.DeepEqual(got, want) {
+		t.Errorf("%q: FindAllStringSubmatch = %q, want %q", re.String(), got, want)
+	}
+	if prefix, complete := MustCompileExpr(b.Concat(b.Lit("a b"), b.Class(`0-9`))).LiteralPrefix(); prefix != "a b" || complete {
+		t.Errorf("LiteralPrefix() = %q, %v; want %q, false", prefix, complete, "a b")
+	}
+}
+
+func TestCompileExprWithConfig(t *testing.T) {
+	e := buildpkg.Concat(buildpkg.Lit("get "), buildpkg.Plus(buildpkg.Class(`a-z/`)))
+
+	config := CompileOptions{CaseInsensitive: true, Verbose: true}.Config()
+	re, err := CompileExprWithConfig(e, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := re.FindString("GET /Index"); got != "GET /Index" {
+		t.Errorf("FindString = %q, want %q", got, "GET /Index")
+	}
+	// Verbose mode does not apply to the literal text of an Expr.
+	if !re.MatchString("get /") {
+		t.Error("Verbose stripped spaces from a literal")
+	}
+	if re := MustCompileExpr(e); re.MatchString("GET /") {
+		t.Error("case-sensitive expression matched")
+	}
+
+	if _, err := CompileExpr(buildpkg.Repeat(e, 2, 1)); !errors.Is(err, buildpkg.ErrInvalidExpr) {
+		t.Errorf("CompileExpr error = %v, want ErrInvalidExpr", err)
+	}
+	config.MaxRecursionDepth = 0
+	if _, err := CompileExprWithConfig(e, config); err == nil {
+		t.Error("CompileExprWithConfig with an invalid config succeeded")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("MustCompileExpr did not panic")
+		}
+	}()
+	MustCompileExpr(nil)
+}
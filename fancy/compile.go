@@ -0,0 +1,512 @@
+package fancy
+
+import (
+	"regexp/syntax"
+	"unicode"
+
+	"github.com/coregx/coregex/meta"
+	"github.com/coregx/coregex/nfa"
+)
+
+// instOp is the operation of a backtracking VM instruction.
+type instOp uint8
+
+const (
+	opMatch       instOp = iota // the match is complete
+	opRunes                     // match runes, folding case if fold
+	opClass                     // match one rune in the ranges of runes
+	opDelegate                  // match delegates[arg] anchored at the position
+	opSplit                     // continue at x, backtrack to y
+	opJmp                       // continue at x
+	opSave                      // set slot arg to the position
+	opBackref                   // match the text of group arg again, folding case if fold
+	opProgress                  // leave the loop at x if slot arg holds the position
+	opAtomicStart               // set slot arg to the backtrack stack depth
+	opAtomicEnd                 // drop the alternatives pushed since opAtomicStart
+)
+
+// inst is a backtracking VM instruction.
+type inst struct {
+	op    instOp
+	fold  bool
+	arg   int
+	x, y  int
+	runes []rune
+}
+
+// delegate is a sub-expression that does not need backtracking, matched by
+// a meta engine.
+type delegate struct {
+	engine   *meta.Engine
+	captures bool // the sub-expression has capture groups
+}
+
+// program is a compiled fancy pattern.
+//
+// Slots 0 to 2*groups-1 hold the capture positions; the slots after them
+// are the registers of empty-loop checks and atomic groups.
+type program struct {
+	insts     []inst
+	delegates []delegate
+	numSlots  int
+	bytes     bool   // byte mode: one byte per character
+	anchored  bool   // every match starts with \A
+	prefix    []byte // literal every match starts with, if any
+}
+
+// compiler compiles a parsed fancy pattern into a program.
+//
+// A sub-expression is hard if it is a backreference or an atomic group,
+// is a capture group that a backreference refers to, or contains one of
+// those. Everything else is easy and can be handed to a meta engine
+// (delegated), as long as backtracking into it is never needed: the easy
+// sub-expression must either always match the same number of characters,
+// or be in tail position, where what follows can no longer fail (the end
+// of the pattern, or the end of an atomic group). Single characters and
+// literals are matched inline rather than delegated.
+type compiler struct {
+	prog      *program
+	config    meta.Config
+	refs      map[int]bool // groups that a backreference refers to
+	hard      map[*syntax.Regexp]bool
+	delegated map[*syntax.Regexp]int // delegate index of a sub-expression
+	runs      map[runKey]*syntax.Regexp
+}
+
+// runKey identifies the concatenation of concat.Sub[start:end].
+type runKey struct {
+	concat     *syntax.Regexp
+	start, end int
+}
+
+// compileProgram compiles re, which has groups capture groups including
+// group 0.
+func compileProgram(re *syntax.Regexp, groups int, config meta.Config) (*program, error) {
+	c := &compiler{
+		prog:      &program{numSlots: 2 * groups, bytes: config.Bytes},
+		config:    config,
+		refs:      make(map[int]bool),
+		hard:      make(map[*syntax.Regexp]bool),
+		delegated: make(map[*syntax.Regexp]int),
+		runs:      make(map[runKey]*syntax.Regexp),
+	}
+	collectRefs(re, c.refs)
+
+	c.emit(inst{op: opSave, arg: 0})
+	if err := c.compile(re, true); err != nil {
+		return nil, err
+	}
+	c.emit(inst{op: opSave, arg: 1})
+	c.emit(inst{op: opMatch})
+	c.analyzeStart(re)
+	return c.prog, nil
+}
+
+// emit appends an instruction and returns its index.
+func (c *compiler) emit(in inst) int {
+	c.prog.insts = append(c.prog.insts, in)
+	return len(c.prog.insts) - 1
+}
+
+// newSlot allocates a register slot.
+func (c *compiler) newSlot() int {
+	c.prog.numSlots++
+	return c.prog.numSlots - 1
+}
+
+// compile compiles re. tail reports whether what follows re cannot fail.
+func (c *compiler) compile(re *syntax.Regexp, tail bool) error {
+	if !c.isHard(re) {
+		if c.inlinable(re) {
+			c.emitInline(re)
+			return nil
+		}
+		if minLen, maxLen := lengthRange(re); tail || minLen == maxLen {
+			return c.emitDelegate(re)
+		}
+	}
+
+	if group, ok := backrefOf(re); ok {
+		c.emit(inst{op: opBackref, arg: group, fold: re.Flags&syntax.FoldCase != 0})
+		return nil
+	}
+	if body, ok := atomicOf(re); ok {
+		reg := c.newSlot()
+		c.emit(inst{op: opAtomicStart, arg: reg})
+		// Nothing after the body can backtrack into it.
+		if err := c.compile(body, true); err != nil {
+			return err
+		}
+		c.emit(inst{op: opAtomicEnd, arg: reg})
+		return nil
+	}
+
+	switch re.Op {
+	case syntax.OpConcat:
+		return c.compileConcat(re, tail)
+	case syntax.OpAlternate:
+		return c.compileAlternate(re.Sub, tail)
+	case syntax.OpCapture:
+		c.emit(inst{op: opSave, arg: 2 * re.Cap})
+		if err := c.compile(re.Sub[0], tail); err != nil {
+			return err
+		}
+		c.emit(inst{op: opSave, arg: 2*re.Cap + 1})
+		return nil
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		minRep, maxRep := repeatBounds(re)
+		return c.compileRepeat(re.Sub[0], minRep, maxRep, re.Flags&syntax.NonGreedy == 0)
+	}
+	// Leaves always have a fixed length and were delegated above.
+	return c.emitDelegate(re)
+}
+
+// compileConcat compiles a concatenation, delegating runs of easy
+// sub-expressions together.
+func (c *compiler) compileConcat(re *syntax.Regexp, tail bool) error {
+	subs := re.Sub
+	for i := 0; i < len(subs); {
+		j := i
+		for j < len(subs) && !c.isHard(subs[j]) {
+			j++
+		}
+		if !tail || j < len(subs) {
+			// Only the fixed-length part of the run can be delegated.
+			k := i
+			for k < j && fixedLength(subs[k]) {
+				k++
+			}
+			j = k
+		}
+
+		switch {
+		case j-i > 1:
+			if err := c.compile(c.run(re, i, j), tail && j == len(subs)); err != nil {
+				return err
+			}
+		case j-i == 1:
+			if err := c.compile(subs[i], tail && j == len(subs)); err != nil {
+				return err
+			}
+		default:
+			if err := c.compile(subs[i], tail && i == len(subs)-1); err != nil {
+				return err
+			}
+			j = i + 1
+		}
+		i = j
+	}
+	return nil
+}
+
+// run returns the concatenation of concat.Sub[start:end].
+func (c *compiler) run(concat *syntax.Regexp, start, end int) *syntax.Regexp {
+	key := runKey{concat: concat, start: start, end: end}
+	if re, ok := c.runs[key]; ok {
+		return re
+	}
+	re := &syntax.Regexp{
+		Op:    syntax.OpConcat,
+		Flags: concat.Flags,
+		Sub:   append([]*syntax.Regexp(nil), concat.Sub[start:end]...),
+	}
+	c.runs[key] = re
+	return re
+}
+
+// compileAlternate compiles an alternation as a chain of splits.
+func (c *compiler) compileAlternate(subs []*syntax.Regexp, tail bool) error {
+	jumps := make([]int, 0, len(subs)-1)
+	for i, sub := range subs {
+		if i == len(subs)-1 {
+			if err := c.compile(sub, tail); err != nil {
+				return err
+			}
+			break
+		}
+		split := c.emit(inst{op: opSplit})
+		c.prog.insts[split].x = split + 1
+		if err := c.compile(sub, tail); err != nil {
+			return err
+		}
+		jumps = append(jumps, c.emit(inst{op: opJmp}))
+		c.prog.insts[split].y = len(c.prog.insts)
+	}
+	for _, j := range jumps {
+		c.prog.insts[j].x = len(c.prog.insts)
+	}
+	return nil
+}
+
+// compileRepeat compiles sub{minRep,maxRep}, maxRep -1 meaning unbounded.
+// Counted repetitions are unrolled; delegates are shared between the
+// copies.
+func (c *compiler) compileRepeat(sub *syntax.Regexp, minRep, maxRep int, greedy bool) error {
+	for i := 0; i < minRep; i++ {
+		if err := c.compile(sub, false); err != nil {
+			return err
+		}
+	}
+
+	if maxRep < 0 {
+		loop := c.emit(inst{op: opSplit})
+		// An iteration that matches nothing would loop forever; as in
+		// PCRE, it is accepted and ends the loop.
+		progress := -1
+		if minLen, _ := lengthRange(sub); minLen == 0 {
+			progress = c.newSlot()
+			c.emit(inst{op: opSave, arg: progress})
+		}
+		if err := c.compile(sub, false); err != nil {
+			return err
+		}
+		check := -1
+		if progress >= 0 {
+			check = c.emit(inst{op: opProgress, arg: progress})
+		}
+		c.emit(inst{op: opJmp, x: loop})
+		exit := len(c.prog.insts)
+		if check >= 0 {
+			c.prog.insts[check].x = exit
+		}
+		c.branch(loop, loop+1, exit, greedy)
+		return nil
+	}
+
+	splits := make([]int, 0, maxRep-minRep)
+	for i := minRep; i < maxRep; i++ {
+		splits = append(splits, c.emit(inst{op: opSplit}))
+		if err := c.compile(sub, false); err != nil {
+			return err
+		}
+	}
+	for _, split := range splits {
+		c.branch(split, split+1, len(c.prog.insts), greedy)
+	}
+	return nil
+}
+
+// branch sets the targets of a split between another iteration (body) and
+// leaving the repetition (exit).
+func (c *compiler) branch(split, body, exit int, greedy bool) {
+	in := &c.prog.insts[split]
+	if greedy {
+		in.x, in.y = body, exit
+	} else {
+		in.x, in.y = exit, body
+	}
+}
+
+// inlinable reports whether re is a character, a literal or a concatenation
+// of those, which the VM matches without a delegate.
+func (c *compiler) inlinable(re *syntax.Regexp) bool {
+	if c.prog.bytes {
+		return false
+	}
+	switch re.Op {
+	case syntax.OpLiteral, syntax.OpCharClass, syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		return true
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if !c.inlinable(sub) {
+				return false
+			}
+		}
+		return len(re.Sub) > 0
+	}
+	return false
+}
+
+// emitInline emits the instructions of an inlinable expression.
+func (c *compiler) emitInline(re *syntax.Regexp) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		c.emit(inst{op: opRunes, runes: re.Rune, fold: re.Flags&syntax.FoldCase != 0})
+	case syntax.OpCharClass:
+		c.emit(inst{op: opClass, runes: re.Rune})
+	case syntax.OpAnyChar:
+		c.emit(inst{op: opClass, runes: []rune{0, unicode.MaxRune}})
+	case syntax.OpAnyCharNotNL:
+		c.emit(inst{op: opClass, runes: []rune{0, '\n' - 1, '\n' + 1, unicode.MaxRune}})
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			c.emitInline(sub)
+		}
+	}
+}
+
+// emitDelegate emits a delegate for re, compiling its engine on first use.
+func (c *compiler) emitDelegate(re *syntax.Regexp) error {
+	idx, ok := c.delegated[re]
+	if !ok {
+		engine, err := meta.CompileRegexp(re, c.config)
+		if err != nil {
+			return err
+		}
+		idx = len(c.prog.delegates)
+		c.prog.delegates = append(c.prog.delegates, delegate{engine: engine, captures: hasCapture(re)})
+		c.delegated[re] = idx
+	}
+	c.emit(inst{op: opDelegate, arg: idx})
+	return nil
+}
+
+// isHard reports whether re needs the backtracking VM.
+func (c *compiler) isHard(re *syntax.Regexp) bool {
+	if hard, ok := c.hard[re]; ok {
+		return hard
+	}
+	_, backref := backrefOf(re)
+	_, atomic := atomicOf(re)
+	hard := backref || atomic || re.Op == syntax.OpCapture && c.refs[re.Cap]
+	if !hard && !nfa.IsLookaround(re) {
+		for _, sub := range re.Sub {
+			if c.isHard(sub) {
+				hard = true
+				break
+			}
+		}
+	}
+	c.hard[re] = hard
+	return hard
+}
+
+// analyzeStart records what every match of re starts with.
+func (c *compiler) analyzeStart(re *syntax.Regexp) {
+	for {
+		if body, ok := atomicOf(re); ok {
+			re = body
+			continue
+		}
+		switch {
+		case re.Op == syntax.OpConcat && len(re.Sub) > 0, re.Op == syntax.OpCapture:
+			re = re.Sub[0]
+			continue
+		case re.Op == syntax.OpBeginText:
+			c.prog.anchored = true
+		case re.Op == syntax.OpLiteral && re.Flags&syntax.FoldCase == 0 && !c.prog.bytes:
+			c.prog.prefix = []byte(string(re.Rune))
+		}
+		return
+	}
+}
+
+// collectRefs records the groups referred to by the backreferences in re.
+func collectRefs(re *syntax.Regexp, refs map[int]bool) {
+	if group, ok := backrefOf(re); ok {
+		refs[group] = true
+	}
+	for _, sub := range re.Sub {
+		collectRefs(sub, refs)
+	}
+}
+
+// hasFancy reports whether re contains a backreference or an atomic group.
+func hasFancy(re *syntax.Regexp) bool {
+	if _, ok := backrefOf(re); ok {
+		return true
+	}
+	if _, ok := atomicOf(re); ok {
+		return true
+	}
+	for _, sub := range re.Sub {
+		if hasFancy(sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasCapture reports whether re contains a capture group.
+func hasCapture(re *syntax.Regexp) bool {
+	if re.Op == syntax.OpCapture {
+		return true
+	}
+	for _, sub := range re.Sub {
+		if hasCapture(sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// repeatBounds returns the repetition counts of a repetition node, maxRep
+// -1 meaning unbounded.
+func repeatBounds(re *syntax.Regexp) (minRep, maxRep int) {
+	switch re.Op {
+	case syntax.OpStar:
+		return 0, -1
+	case syntax.OpPlus:
+		return 1, -1
+	case syntax.OpQuest:
+		return 0, 1
+	}
+	return re.Min, re.Max
+}
+
+// fixedLength reports whether every match of re has the same number of
+// characters. Such a match always ends at the same position, so no
+// alternative of re can make what follows match.
+func fixedLength(re *syntax.Regexp) bool {
+	minLen, maxLen := lengthRange(re)
+	return minLen == maxLen
+}
+
+// lengthRange returns the fewest and most characters re can match, maxLen
+// -1 meaning unbounded.
+func lengthRange(re *syntax.Regexp) (minLen, maxLen int) {
+	if _, ok := backrefOf(re); ok {
+		return 0, -1
+	}
+	if body, ok := atomicOf(re); ok {
+		return lengthRange(body)
+	}
+	switch re.Op {
+	case syntax.OpLiteral:
+		return len(re.Rune), len(re.Rune)
+	case syntax.OpCharClass, syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		return 1, 1
+	case syntax.OpCapture:
+		return lengthRange(re.Sub[0])
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			lo, hi := lengthRange(sub)
+			minLen += lo
+			if maxLen >= 0 {
+				maxLen = addLen(maxLen, hi)
+			}
+		}
+		return minLen, maxLen
+	case syntax.OpAlternate:
+		for i, sub := range re.Sub {
+			lo, hi := lengthRange(sub)
+			if i == 0 || lo < minLen {
+				minLen = lo
+			}
+			if i == 0 || maxLen >= 0 && (hi < 0 || hi > maxLen) {
+				maxLen = hi
+			}
+		}
+		return minLen, maxLen
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		lo, hi := lengthRange(re.Sub[0])
+		minRep, maxRep := repeatBounds(re)
+		switch {
+		case hi == 0:
+			return 0, 0
+		case hi < 0 || maxRep < 0:
+			return lo * minRep, -1
+		}
+		return lo * minRep, hi * maxRep
+	}
+	// Empty-width assertions, empty matches and look-around.
+	return 0, 0
+}
+
+// addLen adds two maximum lengths, -1 meaning unbounded.
+func addLen(a, b int) int {
+	if a < 0 || b < 0 {
+		return -1
+	}
+	return a + b
+}
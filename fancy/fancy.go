@@ -0,0 +1,221 @@
+// Package fancy implements regular expressions with backreferences, atomic
+// groups and possessive quantifiers, which the coregex engines cannot run in
+// linear time.
+//
+// On top of the coregex syntax (Go's regexp/syntax plus look-around), fancy
+// accepts:
+//
+//	\1 ... \N      backreference to group N (decimal; \0 is still octal NUL)
+//	\k<name>       backreference to a named group
+//	(?P=name)      backreference to a named group
+//	(?>re)         atomic group: once re matches, its alternatives are dropped
+//	x*+ x++ x?+    possessive repetitions, like (?>x*), (?>x+) and (?>x?)
+//	x{n,m}+        possessive counted repetition
+//
+// A backreference matches the text its group last matched, folding case
+// under (?i); it fails if the group has not matched. Look-around bodies
+// cannot contain these constructs.
+//
+// Patterns that use none of them compile to a regular coregex engine and
+// keep its linear-time guarantee. Otherwise the pattern runs on a
+// backtracking VM, but only the parts that need it: sub-expressions that do
+// not contain a backreference, an atomic group or a referenced group are
+// handed to meta engines whenever backtracking into them cannot change the
+// result. Backtracking can take exponential time, so every search is limited
+// to Config.StepLimit steps and returns an error matching ErrStepLimit (and
+// coregex.ErrSearchAborted) when it runs out, instead of running away.
+//
+// The methods of Regex mirror those of coregex.Regex, with an error result
+// for the step limit.
+//
+// Example:
+//
+//	re := fancy.MustCompile(`\b(\w+)\s+\1\b`)
+//	dup, err := re.FindString("it is is here")
+//	// dup == "is is", err == nil
+package fancy
+
+import (
+	"errors"
+	"sync"
+	"unsafe"
+
+	"github.com/coregx/coregex/meta"
+)
+
+// DefaultStepLimit is the default Config.StepLimit.
+const DefaultStepLimit = 1_000_000
+
+// ErrStepLimit is the cause of a search stopped after Config.StepLimit
+// backtracking steps. The error returned is a *meta.SearchAbortedError, so
+// errors.Is(err, coregex.ErrSearchAborted) holds as well.
+var ErrStepLimit = errors.New("regexp: backtracking step limit exceeded")
+
+// Config configures the compilation of a fancy Regex.
+type Config struct {
+	// Meta is the configuration of the parser and of the engines that run
+	// the parts of the pattern without fancy constructs (or all of it).
+	Meta meta.Config
+
+	// StepLimit is the most backtracking VM steps one search may take,
+	// counting every instruction run at every start position. A step is
+	// roughly one character matched or one backtrack. Zero or negative
+	// means unlimited.
+	StepLimit int
+}
+
+// DefaultConfig returns the default configuration.
+func DefaultConfig() Config {
+	return Config{
+		Meta:      meta.DefaultConfig(),
+		StepLimit: DefaultStepLimit,
+	}
+}
+
+// Regex is a compiled fancy regular expression. It is safe for concurrent
+// use.
+type Regex struct {
+	pattern   string
+	names     []string
+	stepLimit int
+	bytes     bool
+
+	// engine runs patterns without fancy constructs; prog runs the others.
+	engine *meta.Engine
+	prog   *program
+	vms    sync.Pool
+}
+
+// Compile parses a fancy regular expression.
+func Compile(pattern string) (*Regex, error) {
+	return CompileWithConfig(pattern, DefaultConfig())
+}
+
+// MustCompile is like Compile but panics if the expression cannot be parsed.
+func MustCompile(pattern string) *Regex {
+	re, err := Compile(pattern)
+	if err != nil {
+		panic("regexp: Compile(`" + pattern + "`): " + err.Error())
+	}
+	return re
+}
+
+// CompileWithConfig parses a fancy regular expression with a custom
+// configuration.
+//
+// Example:
+//
+//	config := fancy.DefaultConfig()
+//	config.StepLimit = 10_000
+//	re, err := fancy.CompileWithConfig(`^(a+)+\1$`, config)
+func CompileWithConfig(pattern string, config Config) (*Regex, error) {
+	if err := config.Meta.Validate(); err != nil {
+		return nil, err
+	}
+	re, names, err := parse(pattern, config.Meta)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Regex{pattern: pattern, names: names, stepLimit: config.StepLimit, bytes: config.Meta.Bytes}
+	if !hasFancy(re) {
+		if r.engine, err = meta.CompileRegexp(re, config.Meta); err != nil {
+			return nil, err
+		}
+		return r, nil
+	}
+	if r.prog, err = compileProgram(re, len(names), config.Meta); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// String returns the source text used to compile the regular expression.
+func (r *Regex) String() string {
+	return r.pattern
+}
+
+// NumSubexp returns the number of parenthesized subexpressions.
+func (r *Regex) NumSubexp() int {
+	return len(r.names) - 1
+}
+
+// SubexpNames returns the names of the parenthesized subexpressions. The
+// name of the first subexpression is names[1]; names[0] is always "".
+func (r *Regex) SubexpNames() []string {
+	return r.names
+}
+
+// SubexpIndex returns the index of the first subexpression with the given
+// name, or -1 if there is none.
+func (r *Regex) SubexpIndex(name string) int {
+	if name != "" {
+		for i, n := range r.names {
+			if n == name {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// Backtracks reports whether the pattern runs on the backtracking VM, that
+// is, whether it uses backreferences or atomic groups. Searches of other
+// patterns never fail.
+func (r *Regex) Backtracks() bool {
+	return r.prog != nil
+}
+
+// isMatch reports whether b contains a match.
+func (r *Regex) isMatch(b []byte) (bool, error) {
+	if r.engine != nil {
+		return r.engine.IsMatch(b), nil
+	}
+	m := r.getVM()
+	defer r.vms.Put(m)
+	return m.search(b, 0, r.stepLimit)
+}
+
+// find writes the first match starting at or after at to slots, which has
+// room for 2*(NumSubexp()+1) positions, or for 2 when the groups are not
+// needed. Groups that did not participate are -1.
+func (r *Regex) find(b []byte, at int, slots []int) (bool, error) {
+	if r.engine != nil {
+		s := r.engine.NewSearcher(len(b))
+		defer s.Close()
+		return searcherFind(&s, b, at, slots), nil
+	}
+	m := r.getVM()
+	defer r.vms.Put(m)
+	found, err := m.search(b, at, r.stepLimit)
+	if found {
+		copy(slots, m.slots)
+	}
+	return found, err
+}
+
+// searcherFind is find on a searcher of the engine.
+func searcherFind(s *meta.Searcher, b []byte, at int, slots []int) bool {
+	if len(slots) == 2 {
+		start, end, found := s.FindIndicesAt(b, at)
+		slots[0], slots[1] = start, end
+		return found
+	}
+	return s.FindSubmatchSlotsAt(b, at, slots)
+}
+
+// getVM returns a pooled VM.
+func (r *Regex) getVM() *vm {
+	if m, ok := r.vms.Get().(*vm); ok {
+		return m
+	}
+	return newVM(r.prog)
+}
+
+// stringToBytes returns a read-only view of the bytes of s.
+func stringToBytes(s string) []byte {
+	if s == "" {
+		return nil
+	}
+	return unsafe.Slice(unsafe.StringData(s), len(s)) //nolint:gosec // read-only view
+}
@@ -0,0 +1,287 @@
+package fancy
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"testing"
+
+	"github.com/coregx/coregex/meta"
+)
+
+func TestFancy(t *testing.T) {
+	tests := []struct {
+		pattern string
+		input   string
+		want    []int // FindStringSubmatchIndex
+	}{
+		// Backreferences.
+		{`\b(\w+)\s+\1\b`, "it is is here", []int{3, 8, 3, 5}},
+		{`(\d+)-\1`, "12-13 7-7", []int{6, 9, 6, 7}},
+		{`(a|b)\1`, "abba", []int{1, 3, 1, 2}},
+		{`(?i)(ab)\1`, "abAB", []int{0, 4, 0, 2}},
+		{`(?i)(k)\1`, "kK", []int{0, 4, 0, 1}},
+		{`(ab)\1`, "abAB", nil},
+		{`<(\w+)>.*</\1>`, "<b>x</i></b>", []int{0, 12, 1, 2}},
+		{`(?P<q>['"]).*?\k<q>`, `say "it's" ok`, []int{4, 10, 4, 5}},
+		{`(?P<q>['"]).*?(?P=q)`, `'a"b'`, []int{0, 5, 0, 1}},
+		{`^(a|ab)(c|bcd)\2$`, "abcdbcd", []int{0, 7, 0, 1, 1, 4}},
+		{`(?:(a)|b)\1`, "ba aa", []int{3, 5, 3, 4}},
+		{`(a)?b\1`, "b", nil},
+		{`(a*)*\1`, "aaa", []int{0, 3, 3, 3}},
+		{`(x)(?:y\1)+`, "xyxyxyz", []int{0, 5, 0, 1}},
+		{`^(\w+)(?:,\1)*$`, "ab,ab,ab", []int{0, 8, 0, 2}},
+		{`^(\w+)(?:,\1)*$`, "ab,ab,ac", nil},
+		{`(é)\1`, "aéé", []int{1, 5, 1, 3}},
+		{`(\w)\1{2}`, "abbbc", []int{1, 4, 1, 2}},
+		{`(?m)^(\w+)$\n^\1$`, "a\nb\nb\n", []int{2, 5, 2, 3}},
+
+		// Atomic groups and possessive quantifiers.
+		{`(?>a+)b`, "aaab", []int{0, 4}},
+		{`(?>a+)a`, "aaaa", nil},
+		{`(?>ab|a)c`, "ac abc", []int{0, 2}},
+		{`(?>a|ab)c`, "abc", nil},
+		{`a++a`, "aaaa", nil},
+		{`a*+b`, "aaab", []int{0, 4}},
+		{`a?+a`, "a", nil},
+		{`a{1,3}+a`, "aaaa", []int{0, 4}},
+		{`a{1,3}+a`, "aaa", nil},
+		{`"[^"]*+"`, `x "abc" y`, []int{2, 7}},
+		{`(?:(?>(a))x|a)`, "a", []int{0, 1, -1, -1}},
+		{`(?>(\w+))\s\1`, "ab ab", []int{0, 5, 0, 2}},
+		{`x(?>)y`, "xy", []int{0, 2}},
+		{`a{2}+b|a{2}+c`, "aac", []int{0, 3}},
+
+		// Fancy constructs next to look-around and anchors.
+		{`(?<=\$)(\d)\1`, "11 $22", []int{4, 6, 4, 5}},
+		{`\A(a)\1`, "baa", nil},
+		{`(?i)(?>HELLO)\s(\w+)\s\1`, "hello big big", []int{0, 13, 6, 9}},
+	}
+
+	for _, tt := range tests {
+		re, err := Compile(tt.pattern)
+		if err != nil {
+			t.Errorf("Compile(%q): %v", tt.pattern, err)
+			continue
+		}
+		got, err := re.FindStringSubmatchIndex(tt.input)
+		if err != nil {
+			t.Errorf("%q FindStringSubmatchIndex(%q): %v", tt.pattern, tt.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q FindStringSubmatchIndex(%q) = %v, want %v", tt.pattern, tt.input, got, tt.want)
+		}
+		if matched, _ := re.MatchString(tt.input); matched != (tt.want != nil) {
+			t.Errorf("%q MatchString(%q) = %v", tt.pattern, tt.input, matched)
+		}
+	}
+}
+
+// TestFancyMatchesRegexp runs patterns without fancy constructs on the
+// backtracking VM, by appending an empty atomic group, and checks that the
+// results are those of the regexp package. Loops whose body can match empty
+// are left out: like PCRE, the VM keeps the groups of a final empty
+// iteration, where regexp does not.
+func TestFancyMatchesRegexp(t *testing.T) {
+	patterns := []string{
+		`a+`, `a*`, `a*?`, `(a+)(b+)?`, `(a|ab)(c|bcd)(d*)`, `x*`, `(?:a|b)*c`,
+		`\b\w+\b`, `^\w+$`, `(?m)^\w+$`, `(a+)+`, `(a|b)*?c`, `[^a]+`,
+		`(?i)straße|é+`, `\d{2,4}`, `(\d{2,4}?)(\d*)`, `(?s).{2}`, `.`, ``,
+		`(?U)a+b*`, `(a?)(a?)(a?)aaa`, `((a)|b)+`, `(?:(a)|(b))+c`,
+	}
+	inputs := []string{
+		"", "a", "aaa", "abcd", "abbbcd", "xxaxx", "ab ab\nc d", "aabbaabbc",
+		"STRASSE straße éé", "123456789", "a\nb", "日本語", "bbbbc", "aaaaa",
+	}
+	for _, p := range patterns {
+		want := regexp.MustCompile(p)
+		re := MustCompile(p + `(?>)`)
+		if !re.Backtracks() {
+			t.Fatalf("%q: not on the backtracking VM", p)
+		}
+		for _, in := range inputs {
+			got, err := re.FindAllStringSubmatchIndex(in, -1)
+			if err != nil {
+				t.Errorf("%q FindAllStringSubmatchIndex(%q): %v", p, in, err)
+				continue
+			}
+			if w := want.FindAllStringSubmatchIndex(in, -1); !reflect.DeepEqual(got, w) {
+				t.Errorf("%q FindAllStringSubmatchIndex(%q) = %v, want %v", p, in, got, w)
+			}
+		}
+	}
+}
+
+func TestFancyDelegatesPlainPatterns(t *testing.T) {
+	tests := []struct {
+		pattern string
+		std     string // the same pattern for the regexp package
+	}{
+		{`a+b`, `a+b`},
+		{`(\w+)@(\w+)`, `(\w+)@(\w+)`},
+		{`a\+\+`, `a\+\+`},
+		{`a{2}\+`, `a{2}\+`},
+		{`[(?>]+`, `[(?>]+`},
+		{`\Q\1(?>\E`, `\Q\1(?>\E`},
+		{`\\1`, `\\1`},
+		{`foo(?=bar)`, `foo`},
+	}
+	for _, tt := range tests {
+		re := MustCompile(tt.pattern)
+		if re.Backtracks() {
+			t.Errorf("%q: Backtracks() = true", tt.pattern)
+		}
+		std := regexp.MustCompile(tt.std)
+		for _, in := range []string{"aab", "me@host", "foobar", "a++", `\1(?>`, "(?>", "aa+"} {
+			got, err := re.FindStringSubmatchIndex(in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := std.FindStringSubmatchIndex(in); !reflect.DeepEqual(got, want) {
+				t.Errorf("%q FindStringSubmatchIndex(%q) = %v, want %v", tt.pattern, in, got, want)
+			}
+		}
+	}
+}
+
+func TestFancyStepLimit(t *testing.T) {
+	config := DefaultConfig()
+	config.StepLimit = 10_000
+	re, err := CompileWithConfig(`^(a+)+\1b$`, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := strings.Repeat("a", 40)
+
+	_, err = re.MatchString(input)
+	if !errors.Is(err, ErrStepLimit) || !errors.Is(err, meta.ErrSearchAborted) {
+		t.Fatalf("MatchString = %v, want ErrStepLimit", err)
+	}
+	var aborted *meta.SearchAbortedError
+	if !errors.As(err, &aborted) || aborted.Work <= int64(config.StepLimit) {
+		t.Errorf("error = %#v, want a SearchAbortedError with Work > %d", err, config.StepLimit)
+	}
+	if all, err := re.FindAllString(input, -1); all != nil || !errors.Is(err, ErrStepLimit) {
+		t.Errorf("FindAllString = %q, %v; want nil, ErrStepLimit", all, err)
+	}
+	if out, err := re.ReplaceAllString(input, "x"); out != "" || !errors.Is(err, ErrStepLimit) {
+		t.Errorf("ReplaceAllString = %q, %v; want \"\", ErrStepLimit", out, err)
+	}
+
+	// Short inputs fit in the limit.
+	if ok, err := re.MatchString("aaaab"); !ok || err != nil {
+		t.Errorf("MatchString(aaaab) = %v, %v; want true", ok, err)
+	}
+
+	// Without a limit the search runs to completion.
+	config.StepLimit = 0
+	re, _ = CompileWithConfig(`^(a+)+\1b$`, config)
+	if ok, err := re.MatchString(strings.Repeat("a", 16)); ok || err != nil {
+		t.Errorf("unlimited MatchString = %v, %v; want false, nil", ok, err)
+	}
+}
+
+func TestFancyFindAll(t *testing.T) {
+	re := MustCompile(`(\w)\1`)
+	all, err := re.FindAllString("aabbcd eeff", -1)
+	if err != nil || !reflect.DeepEqual(all, []string{"aa", "bb", "ee", "ff"}) {
+		t.Errorf("FindAllString = %q, %v", all, err)
+	}
+	all, _ = re.FindAllString("aabbcd eeff", 3)
+	if !reflect.DeepEqual(all, []string{"aa", "bb", "ee"}) {
+		t.Errorf("FindAllString(n=3) = %q", all)
+	}
+	subs, _ := re.FindAllStringSubmatch("xx yy", -1)
+	if !reflect.DeepEqual(subs, [][]string{{"xx", "x"}, {"yy", "y"}}) {
+		t.Errorf("FindAllStringSubmatch = %q", subs)
+	}
+	idx, _ := re.FindAllIndex([]byte("zz"), -1)
+	if !reflect.DeepEqual(idx, [][]int{{0, 2}}) {
+		t.Errorf("FindAllIndex = %v", idx)
+	}
+
+	// Empty matches follow the rules of regexp.
+	empty := MustCompile(`(a*)\1`)
+	got, _ := empty.FindAllStringIndex("baaab", -1)
+	if want := regexp.MustCompile(`(?:aa)*`).FindAllStringIndex("baaab", -1); !reflect.DeepEqual(got, want) {
+		t.Errorf("FindAllStringIndex = %v, want %v", got, want)
+	}
+
+	b, _ := re.Find([]byte("a bb"))
+	s, _ := re.FindString("a bb")
+	sub, _ := re.FindSubmatch([]byte("a bb"))
+	if string(b) != "bb" || s != "bb" || len(sub) != 2 || string(sub[1]) != "b" {
+		t.Errorf("Find = %q, FindString = %q, FindSubmatch = %q", b, s, sub)
+	}
+	if loc, err := re.FindStringIndex("abc"); loc != nil || err != nil {
+		t.Errorf("FindStringIndex(abc) = %v, %v; want nil", loc, err)
+	}
+}
+
+func TestFancySubexp(t *testing.T) {
+	re := MustCompile(`(?P<first>\w)(?>x)(\w)(?P<last>\w)\k<first>`)
+	if got, want := re.SubexpNames(), []string{"", "first", "", "last"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("SubexpNames() = %q, want %q", got, want)
+	}
+	if re.NumSubexp() != 3 || re.SubexpIndex("last") != 3 || re.SubexpIndex("nope") != -1 {
+		t.Errorf("NumSubexp() = %d, SubexpIndex(last) = %d", re.NumSubexp(), re.SubexpIndex("last"))
+	}
+	if re.String() != `(?P<first>\w)(?>x)(\w)(?P<last>\w)\k<first>` {
+		t.Errorf("String() = %q", re.String())
+	}
+}
+
+func TestFancyConfig(t *testing.T) {
+	config := DefaultConfig()
+	config.Meta.Verbose = true
+	re, err := CompileWithConfig(`(\w+) \s+ \1  # a repeated word`, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := re.FindString("so so"); got != "so so" {
+		t.Errorf("verbose FindString = %q", got)
+	}
+
+	config = DefaultConfig()
+	config.Meta.Bytes = true
+	re, err = CompileWithConfig(`(?i)([\xC0-\xFF])\1`, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loc, _ := re.FindIndex([]byte{'a', 0xE9, 0xC9}); !reflect.DeepEqual(loc, []int{1, 3}) {
+		t.Errorf("byte mode FindIndex = %v, want [1 3]", loc)
+	}
+
+	config = DefaultConfig()
+	config.Meta.SyntaxFlags = syntax.Perl | syntax.Literal
+	re, err = CompileWithConfig(`(a)\1`, config)
+	if err != nil || re.Backtracks() {
+		t.Fatalf("literal: %v, Backtracks() = %v", err, re != nil && re.Backtracks())
+	}
+	if ok, _ := re.MatchString(`x(a)\1`); !ok {
+		t.Error(`literal (a)\1 does not match itself`)
+	}
+}
+
+func TestFancyConcurrent(t *testing.T) {
+	re := MustCompile(`(\w+)-\1`)
+	done := make(chan bool)
+	for g := 0; g < 8; g++ {
+		go func() {
+			ok := true
+			for i := 0; i < 200; i++ {
+				got, err := re.FindString("xx ab-ab cd-ce")
+				ok = ok && got == "ab-ab" && err == nil
+			}
+			done <- ok
+		}()
+	}
+	for g := 0; g < 8; g++ {
+		if !<-done {
+			t.Error("concurrent FindString returned a wrong result")
+		}
+	}
+}
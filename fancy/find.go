@@ -0,0 +1,221 @@
+package fancy
+
+// Match reports whether b contains a match of r.
+func (r *Regex) Match(b []byte) (bool, error) {
+	return r.isMatch(b)
+}
+
+// MatchString reports whether s contains a match of r.
+func (r *Regex) MatchString(s string) (bool, error) {
+	return r.isMatch(stringToBytes(s))
+}
+
+// Find returns the leftmost match of r in b, or nil if there is none.
+func (r *Regex) Find(b []byte) ([]byte, error) {
+	var slots [2]int
+	found, err := r.find(b, 0, slots[:])
+	if !found {
+		return nil, err
+	}
+	return b[slots[0]:slots[1]:slots[1]], nil
+}
+
+// FindString returns the leftmost match of r in s, or "" if there is none.
+func (r *Regex) FindString(s string) (string, error) {
+	var slots [2]int
+	found, err := r.find(stringToBytes(s), 0, slots[:])
+	if !found {
+		return "", err
+	}
+	return s[slots[0]:slots[1]], nil
+}
+
+// FindIndex returns the location of the leftmost match of r in b as
+// b[loc[0]:loc[1]], or nil if there is none.
+func (r *Regex) FindIndex(b []byte) ([]int, error) {
+	slots := make([]int, 2)
+	found, err := r.find(b, 0, slots)
+	if !found {
+		return nil, err
+	}
+	return slots, nil
+}
+
+// FindStringIndex is like FindIndex for a string.
+func (r *Regex) FindStringIndex(s string) ([]int, error) {
+	return r.FindIndex(stringToBytes(s))
+}
+
+// FindSubmatchIndex returns the index pairs of the leftmost match of r in b
+// and of its subexpressions, -1 for those that did not participate, or nil
+// if there is no match.
+func (r *Regex) FindSubmatchIndex(b []byte) ([]int, error) {
+	slots := make([]int, 2*len(r.names))
+	found, err := r.find(b, 0, slots)
+	if !found {
+		return nil, err
+	}
+	return slots, nil
+}
+
+// FindStringSubmatchIndex is like FindSubmatchIndex for a string.
+func (r *Regex) FindStringSubmatchIndex(s string) ([]int, error) {
+	return r.FindSubmatchIndex(stringToBytes(s))
+}
+
+// FindSubmatch returns the text of the leftmost match of r in b and of its
+// subexpressions, nil for those that did not participate, or nil if there
+// is no match.
+func (r *Regex) FindSubmatch(b []byte) ([][]byte, error) {
+	loc, err := r.FindSubmatchIndex(b)
+	if loc == nil {
+		return nil, err
+	}
+	return submatches(b, loc), nil
+}
+
+// FindStringSubmatch is like FindSubmatch for a string.
+func (r *Regex) FindStringSubmatch(s string) ([]string, error) {
+	loc, err := r.FindStringSubmatchIndex(s)
+	if loc == nil {
+		return nil, err
+	}
+	return stringSubmatches(s, loc), nil
+}
+
+// FindAll returns up to n successive non-overlapping matches of r in b
+// (all of them if n < 0), or nil if there is none. As in regexp, an empty
+// match right after a previous match is ignored.
+func (r *Regex) FindAll(b []byte, n int) ([][]byte, error) {
+	var result [][]byte
+	err := r.all(b, n, 2, func(loc []int) {
+		result = append(result, b[loc[0]:loc[1]:loc[1]])
+	})
+	return result, err
+}
+
+// FindAllString is like FindAll for a string.
+func (r *Regex) FindAllString(s string, n int) ([]string, error) {
+	var result []string
+	err := r.all(stringToBytes(s), n, 2, func(loc []int) {
+		result = append(result, s[loc[0]:loc[1]])
+	})
+	return result, err
+}
+
+// FindAllIndex is the 'All' version of FindIndex.
+func (r *Regex) FindAllIndex(b []byte, n int) ([][]int, error) {
+	var result [][]int
+	err := r.all(b, n, 2, func(loc []int) {
+		result = append(result, append([]int(nil), loc...))
+	})
+	return result, err
+}
+
+// FindAllStringIndex is like FindAllIndex for a string.
+func (r *Regex) FindAllStringIndex(s string, n int) ([][]int, error) {
+	return r.FindAllIndex(stringToBytes(s), n)
+}
+
+// FindAllSubmatchIndex is the 'All' version of FindSubmatchIndex.
+func (r *Regex) FindAllSubmatchIndex(b []byte, n int) ([][]int, error) {
+	var result [][]int
+	err := r.all(b, n, 2*len(r.names), func(loc []int) {
+		result = append(result, append([]int(nil), loc...))
+	})
+	return result, err
+}
+
+// FindAllStringSubmatchIndex is like FindAllSubmatchIndex for a string.
+func (r *Regex) FindAllStringSubmatchIndex(s string, n int) ([][]int, error) {
+	return r.FindAllSubmatchIndex(stringToBytes(s), n)
+}
+
+// FindAllSubmatch is the 'All' version of FindSubmatch.
+func (r *Regex) FindAllSubmatch(b []byte, n int) ([][][]byte, error) {
+	var result [][][]byte
+	err := r.all(b, n, 2*len(r.names), func(loc []int) {
+		result = append(result, submatches(b, loc))
+	})
+	return result, err
+}
+
+// FindAllStringSubmatch is like FindAllSubmatch for a string.
+func (r *Regex) FindAllStringSubmatch(s string, n int) ([][]string, error) {
+	var result [][]string
+	err := r.all(stringToBytes(s), n, 2*len(r.names), func(loc []int) {
+		result = append(result, stringSubmatches(s, loc))
+	})
+	return result, err
+}
+
+// all calls deliver with the positions of up to n successive matches (all
+// of them if n < 0), following the rules of regexp: after an empty match
+// the search resumes one character later, and an empty match right after
+// the previous match is skipped. slots is the number of positions wanted
+// per match; the slice passed to deliver is reused.
+func (r *Regex) all(b []byte, n, slots int, deliver func(loc []int)) error {
+	if n < 0 {
+		n = len(b) + 1
+	}
+	loc := make([]int, slots)
+	find := r.find
+	if r.engine != nil {
+		// One searcher for the loop keeps look-around results across matches.
+		s := r.engine.NewSearcher(len(b))
+		defer s.Close()
+		find = func(b []byte, at int, slots []int) (bool, error) {
+			return searcherFind(&s, b, at, slots), nil
+		}
+	}
+	for pos, i, prevEnd := 0, 0, -1; i < n && pos <= len(b); {
+		found, err := find(b, pos, loc)
+		if err != nil {
+			return err
+		}
+		if !found {
+			break
+		}
+
+		accept := true
+		if loc[1] == pos {
+			// An empty match: skip it if it abuts the previous match, and
+			// move on by one character either way.
+			if loc[0] == prevEnd {
+				accept = false
+			}
+			pos = nextChar(b, pos, r.bytes)
+		} else {
+			pos = loc[1]
+		}
+		prevEnd = loc[1]
+
+		if accept {
+			deliver(loc)
+			i++
+		}
+	}
+	return nil
+}
+
+// submatches returns the text of each group of a match.
+func submatches(b []byte, loc []int) [][]byte {
+	result := make([][]byte, len(loc)/2)
+	for i := range result {
+		if loc[2*i] >= 0 {
+			result[i] = b[loc[2*i]:loc[2*i+1]:loc[2*i+1]]
+		}
+	}
+	return result
+}
+
+// stringSubmatches returns the text of each group of a match.
+func stringSubmatches(s string, loc []int) []string {
+	result := make([]string, len(loc)/2)
+	for i := range result {
+		if loc[2*i] >= 0 {
+			result[i] = s[loc[2*i]:loc[2*i+1]]
+		}
+	}
+	return result
+}
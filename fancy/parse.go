@@ -0,0 +1,394 @@
+package fancy
+
+import (
+	"errors"
+	"fmt"
+	"regexp/syntax"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/coregx/coregex/internal/scan"
+	"github.com/coregx/coregex/meta"
+	"github.com/coregx/coregex/nfa"
+)
+
+// Fancy syntax.
+//
+// regexp/syntax rejects backreferences, atomic groups and possessive
+// quantifiers. parse rewrites each of them into a named capture (empty for
+// backreferences and possessive markers, wrapping the body for atomic
+// groups), parses the result with meta.Parse so that flags, verbose mode and
+// look-around work as usual, and replaces the captures by fancy nodes:
+//   - a backreference becomes an OpEmptyMatch named backrefName, with the
+//     group number in Min and the FoldCase flag of its context
+//   - an atomic group becomes an OpEmptyMatch named atomicName, with the
+//     body in Sub[0]
+//   - a possessive marker makes the repetition before it an atomic group
+//
+// The remaining captures are renumbered so that group numbers are those of
+// the pattern as written.
+
+// ErrInvalidBackref is the syntax.ErrorCode of a backreference to a group
+// that does not exist.
+const ErrInvalidBackref syntax.ErrorCode = "invalid backreference"
+
+// Names of the fancy nodes made by parse. They cannot be capture names.
+const (
+	backrefName = "\x00backref"
+	atomicName  = "\x00atomic"
+)
+
+// fancyCapture is the name prefix of the captures parse puts in place of the
+// fancy constructs before parsing.
+const fancyCapture = "__coregex_fancy_"
+
+// constructKind is the kind of a fancy construct.
+type constructKind uint8
+
+const (
+	kindBackref constructKind = iota
+	kindAtomic
+	kindPossessive
+)
+
+// construct is a backreference, atomic group opener or possessive marker of
+// a pattern.
+type construct struct {
+	kind  constructKind
+	text  string // as written: `\1`, `\k<name>`, `(?P=name)`, `(?>` or `+`
+	group int    // backreference by number, 0 if by name
+	name  string // backreference by name
+}
+
+// placeholder returns the capture that stands for construct i.
+func (c construct) placeholder(i int) string {
+	open := "(?P<" + fancyCapture + strconv.Itoa(i) + ">"
+	if c.kind == kindAtomic {
+		return open
+	}
+	return open + ")"
+}
+
+// parse parses pattern with the syntax of config, extended with
+// backreferences, atomic groups and possessive quantifiers. It returns the
+// tree and the names of its capture groups (names[0] is "").
+//
+// Errors are *meta.CompileError, quoting the pattern as written.
+func parse(pattern string, config meta.Config) (*syntax.Regexp, []string, error) {
+	flags := config.SyntaxFlags
+	if flags == 0 {
+		flags = syntax.Perl
+	}
+	src, constructs := pattern, []construct(nil)
+	if flags&(syntax.PerlX|syntax.Literal) == syntax.PerlX {
+		src, constructs = rewrite(pattern)
+	}
+
+	re, err := meta.Parse(src, config)
+	if err != nil {
+		return nil, nil, restoreError(pattern, constructs, err)
+	}
+	if len(constructs) == 0 {
+		return re, captureNames(re), nil
+	}
+
+	p := &parser{pattern: pattern, constructs: constructs}
+	root := &syntax.Regexp{Op: syntax.OpConcat, Sub: []*syntax.Regexp{re}}
+	p.rewrite(root)
+	names := p.renumber()
+	if err := p.resolve(names); err != nil {
+		return nil, nil, err
+	}
+	return root.Sub[0], names, nil
+}
+
+// rewrite replaces the fancy constructs of pattern by placeholder captures.
+// Escapes, \Q...\E and character classes are skipped with the same rules as
+// the parser.
+func rewrite(pattern string) (string, []construct) {
+	var b strings.Builder
+	var constructs []construct
+	add := func(c construct) {
+		b.WriteString(c.placeholder(len(constructs)))
+		constructs = append(constructs, c)
+	}
+
+	quantified := false // the previous token was a repetition operator
+	sc := scan.New(pattern)
+	for {
+		tok, ok := sc.Next()
+		if !ok {
+			break
+		}
+		i, c := tok.Start, pattern[tok.Start]
+		afterQuantifier := quantified
+		quantified = false
+		switch {
+		case tok.Kind == scan.Escape && !tok.InClass:
+			if c, size := backref(pattern[i:]); size > 0 {
+				add(c)
+				sc.Seek(i + size)
+				continue
+			}
+			b.WriteString(pattern[i:tok.End])
+			continue
+
+		case tok.Kind != scan.Byte:
+			b.WriteString(pattern[i:tok.End])
+			continue
+
+		case strings.HasPrefix(pattern[i:], "(?>"):
+			add(construct{kind: kindAtomic, text: "(?>"})
+			sc.Seek(i + len("(?>"))
+			continue
+
+		case strings.HasPrefix(pattern[i:], "(?P="):
+			if end := strings.IndexByte(pattern[i:], ')'); end >= 0 {
+				text := pattern[i : i+end+1]
+				add(construct{kind: kindBackref, text: text, name: text[len("(?P=") : len(text)-1]})
+				sc.Seek(i + end + 1)
+				continue
+			}
+
+		case c == '(':
+			// The '?' of a group opener is not a repetition.
+			if strings.HasPrefix(pattern[i:], "(?") {
+				b.WriteString("(?")
+				sc.Seek(i + 2)
+				continue
+			}
+
+		case c == '+' && afterQuantifier:
+			add(construct{kind: kindPossessive, text: "+"})
+			continue
+
+		case c == '*' || c == '+':
+			quantified = true
+
+		case c == '?':
+			// After a repetition, '?' makes it lazy.
+			quantified = !afterQuantifier
+
+		case c == '{':
+			if n := repeatLen(pattern[i:]); n > 0 {
+				b.WriteString(pattern[i : i+n])
+				sc.Seek(i + n)
+				quantified = true
+				continue
+			}
+		}
+		b.WriteByte(c)
+	}
+	return b.String(), constructs
+}
+
+// backref parses the backreference at the start of s: \N for a decimal group
+// number N >= 1, or \k<name>. size is 0 if s does not start with one.
+func backref(s string) (c construct, size int) {
+	switch {
+	case len(s) >= 2 && s[1] >= '1' && s[1] <= '9':
+		n := 2
+		for n < len(s) && s[n] >= '0' && s[n] <= '9' {
+			n++
+		}
+		group, err := strconv.Atoi(s[1:n])
+		if err != nil {
+			return construct{}, 0
+		}
+		return construct{kind: kindBackref, text: s[:n], group: group}, n
+	case strings.HasPrefix(s, `\k<`):
+		end := strings.IndexByte(s, '>')
+		if end < 0 {
+			return construct{}, 0
+		}
+		return construct{kind: kindBackref, text: s[:end+1], name: s[len(`\k<`):end]}, end + 1
+	}
+	return construct{}, 0
+}
+
+// repeatLen returns the length of the {n}, {n,} or {n,m} repetition at the
+// start of s, or 0 if s does not start with one.
+func repeatLen(s string) int {
+	i := 1
+	digits := func() int {
+		start := i
+		for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+			i++
+		}
+		return i - start
+	}
+	if digits() == 0 {
+		return 0
+	}
+	if i < len(s) && s[i] == ',' {
+		i++
+		digits()
+	}
+	if i < len(s) && s[i] == '}' {
+		return i + 1
+	}
+	return 0
+}
+
+// restoreError rewrites a parse error of the rewritten pattern so that it
+// quotes pattern as written.
+func restoreError(pattern string, constructs []construct, err error) error {
+	var cerr *meta.CompileError
+	if len(constructs) == 0 || !errors.As(err, &cerr) {
+		return err
+	}
+	var serr *syntax.Error
+	if !errors.As(err, &serr) {
+		if errors.Is(err, nfa.ErrUnsupportedLookaround) && strings.Contains(err.Error(), fancyCapture) {
+			err = fmt.Errorf("%w: backreferences, atomic groups and possessive quantifiers "+
+				"are not supported in look-around", nfa.ErrUnsupportedLookaround)
+			return &meta.CompileError{Pattern: pattern, Offset: -1, Err: err}
+		}
+		return &meta.CompileError{Pattern: pattern, Offset: -1, Err: cerr.Err}
+	}
+
+	restore := make([]string, 0, 2*len(constructs))
+	for i, c := range constructs {
+		restore = append(restore, c.placeholder(i), c.text)
+	}
+	expr := strings.NewReplacer(restore...).Replace(serr.Expr)
+	return &meta.CompileError{
+		Pattern: pattern,
+		Offset:  strings.Index(pattern, expr),
+		Err:     &syntax.Error{Code: serr.Code, Expr: expr},
+	}
+}
+
+// parser turns the placeholder captures of a parsed pattern into fancy
+// nodes.
+type parser struct {
+	pattern    string
+	constructs []construct
+	captures   []*syntax.Regexp // the remaining capture groups
+	backrefs   []*syntax.Regexp // backreference nodes, with their construct index in Max
+}
+
+// rewrite replaces the placeholder captures below re.
+func (p *parser) rewrite(re *syntax.Regexp) {
+	if nfa.IsLookaround(re) {
+		return // look-around bodies cannot contain captures
+	}
+	for _, sub := range re.Sub {
+		p.rewrite(sub)
+		if sub.Op != syntax.OpCapture {
+			continue
+		}
+		idx, ok := p.placeholder(sub)
+		if !ok {
+			p.captures = append(p.captures, sub)
+			continue
+		}
+		switch p.constructs[idx].kind {
+		case kindBackref:
+			*sub = syntax.Regexp{Op: syntax.OpEmptyMatch, Flags: sub.Flags, Name: backrefName, Max: idx}
+			p.backrefs = append(p.backrefs, sub)
+		case kindAtomic:
+			// Sub may alias the node's own Sub0 array; copy it first.
+			body := sub.Sub[0]
+			*sub = syntax.Regexp{Op: syntax.OpEmptyMatch, Flags: sub.Flags, Name: atomicName, Sub: []*syntax.Regexp{body}}
+		}
+	}
+
+	// A possessive marker makes the node before it atomic. When the parser
+	// has factored that node out of an alternation it is a simple fixed
+	// repetition, which cannot backtrack anyway.
+	if re.Op != syntax.OpConcat && re.Op != syntax.OpAlternate {
+		return
+	}
+	subs := re.Sub[:0]
+	for _, sub := range re.Sub {
+		if idx, ok := p.placeholder(sub); !ok || p.constructs[idx].kind != kindPossessive {
+			subs = append(subs, sub)
+			continue
+		}
+		if n := len(subs); n > 0 && re.Op == syntax.OpConcat {
+			subs[n-1] = &syntax.Regexp{
+				Op:    syntax.OpEmptyMatch,
+				Flags: subs[n-1].Flags,
+				Name:  atomicName,
+				Sub:   []*syntax.Regexp{subs[n-1]},
+			}
+			continue
+		}
+		subs = append(subs, &syntax.Regexp{Op: syntax.OpEmptyMatch, Flags: sub.Flags})
+	}
+	re.Sub = subs
+}
+
+// placeholder returns the construct index of a placeholder capture.
+func (p *parser) placeholder(re *syntax.Regexp) (int, bool) {
+	if re.Op != syntax.OpCapture || !strings.HasPrefix(re.Name, fancyCapture) {
+		return 0, false
+	}
+	idx, err := strconv.Atoi(strings.TrimPrefix(re.Name, fancyCapture))
+	if err != nil || idx >= len(p.constructs) {
+		return 0, false // a user group that happens to use the prefix
+	}
+	return idx, true
+}
+
+// renumber numbers the remaining captures in the order of their opening
+// parentheses and returns their names.
+func (p *parser) renumber() []string {
+	sort.Slice(p.captures, func(i, j int) bool { return p.captures[i].Cap < p.captures[j].Cap })
+	names := make([]string, len(p.captures)+1)
+	for i, c := range p.captures {
+		c.Cap = i + 1
+		names[i+1] = c.Name
+	}
+	return names
+}
+
+// resolve sets the group number of every backreference node.
+func (p *parser) resolve(names []string) error {
+	for _, re := range p.backrefs {
+		c := p.constructs[re.Max]
+		group := c.group
+		if c.name != "" {
+			group = 0
+			for i, name := range names {
+				if i > 0 && name == c.name {
+					group = i
+				}
+			}
+		}
+		if group <= 0 || group >= len(names) {
+			return &meta.CompileError{
+				Pattern: p.pattern,
+				Offset:  strings.Index(p.pattern, c.text),
+				Err:     &syntax.Error{Code: ErrInvalidBackref, Expr: c.text},
+			}
+		}
+		re.Min, re.Max = group, 0
+	}
+	return nil
+}
+
+// captureNames returns the capture names of a tree without fancy nodes.
+func captureNames(re *syntax.Regexp) []string {
+	names := make([]string, re.MaxCap()+1)
+	copy(names, re.CapNames())
+	return names
+}
+
+// backrefOf returns the group of a backreference node.
+func backrefOf(re *syntax.Regexp) (group int, ok bool) {
+	if re.Op != syntax.OpEmptyMatch || re.Name != backrefName {
+		return 0, false
+	}
+	return re.Min, true
+}
+
+// atomicOf returns the body of an atomic group node.
+func atomicOf(re *syntax.Regexp) (body *syntax.Regexp, ok bool) {
+	if re.Op != syntax.OpEmptyMatch || re.Name != atomicName || len(re.Sub) != 1 {
+		return nil, false
+	}
+	return re.Sub[0], true
+}
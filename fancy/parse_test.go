@@ -0,0 +1,130 @@
+package fancy
+
+import (
+	"errors"
+	"reflect"
+	"regexp/syntax"
+	"strings"
+	"testing"
+
+	"github.com/coregx/coregex/meta"
+	"github.com/coregx/coregex/nfa"
+)
+
+func TestRewrite(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+		texts   []string // the constructs found, as written
+	}{
+		{`abc`, `abc`, nil},
+		{`(a)\1`, `(a)(?P<__coregex_fancy_0>)`, []string{`\1`}},
+		{`(a)\12`, `(a)(?P<__coregex_fancy_0>)`, []string{`\12`}},
+		{`\0\1`, `\0(?P<__coregex_fancy_0>)`, []string{`\1`}},
+		{`\k<x>(?P=y)`, `(?P<__coregex_fancy_0>)(?P<__coregex_fancy_1>)`, []string{`\k<x>`, `(?P=y)`}},
+		{`(?>ab)`, `(?P<__coregex_fancy_0>ab)`, []string{`(?>`}},
+		{`a*+b++c?+`, `a*(?P<__coregex_fancy_0>)b+(?P<__coregex_fancy_1>)c?(?P<__coregex_fancy_2>)`, []string{`+`, `+`, `+`}},
+		{`a{2,3}+`, `a{2,3}(?P<__coregex_fancy_0>)`, []string{`+`}},
+		{`a+?+`, `a+?+`, nil},
+		{`a\++`, `a\++`, nil},
+		{`(?:a)+`, `(?:a)+`, nil},
+		{`(?i)+`, `(?i)+`, nil},
+		{`a{x}+`, `a{x}+`, nil},
+		{`\\1`, `\\1`, nil},
+		{`[\1(?>]+`, `[\1(?>]+`, nil},
+		{`[]\1]`, `[]\1]`, nil},
+		{`[[:alpha:]\1]`, `[[:alpha:]\1]`, nil},
+		{`\Q\1(?>\E\1`, `\Q\1(?>\E(?P<__coregex_fancy_0>)`, []string{`\1`}},
+		{`\Q\1`, `\Q\1`, nil},
+		{`é\1`, `é(?P<__coregex_fancy_0>)`, []string{`\1`}},
+	}
+	for _, tt := range tests {
+		got, constructs := rewrite(tt.pattern)
+		if got != tt.want {
+			t.Errorf("rewrite(%q) = %q, want %q", tt.pattern, got, tt.want)
+		}
+		var texts []string
+		for _, c := range constructs {
+			texts = append(texts, c.text)
+		}
+		if !reflect.DeepEqual(texts, tt.texts) {
+			t.Errorf("rewrite(%q) constructs = %q, want %q", tt.pattern, texts, tt.texts)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		pattern string
+		code    syntax.ErrorCode
+		expr    string
+	}{
+		{`(a)\2`, ErrInvalidBackref, `\2`},
+		{`\1`, ErrInvalidBackref, `\1`},
+		{`(?P<x>a)\k<y>`, ErrInvalidBackref, `\k<y>`},
+		{`(?P<x>a)(?P=y)`, ErrInvalidBackref, `(?P=y)`},
+		{`(?>a`, syntax.ErrMissingParen, `(?>a`},
+		{`a**+`, syntax.ErrInvalidRepeatOp, `**`},
+		{`(a)\1[`, syntax.ErrMissingBracket, `[`},
+	}
+	for _, tt := range tests {
+		_, err := Compile(tt.pattern)
+		var cerr *meta.CompileError
+		var serr *syntax.Error
+		if !errors.As(err, &cerr) || !errors.As(err, &serr) {
+			t.Errorf("Compile(%q) error = %v, want a syntax error", tt.pattern, err)
+			continue
+		}
+		if cerr.Pattern != tt.pattern || serr.Code != tt.code {
+			t.Errorf("Compile(%q) error = %v (pattern %q, code %q), want code %q",
+				tt.pattern, err, cerr.Pattern, serr.Code, tt.code)
+		}
+		if tt.expr != "" && serr.Expr != tt.expr {
+			t.Errorf("Compile(%q) error expr = %q, want %q", tt.pattern, serr.Expr, tt.expr)
+		}
+		if strings.Contains(err.Error(), fancyCapture) {
+			t.Errorf("Compile(%q) error %q leaks a placeholder", tt.pattern, err)
+		}
+	}
+}
+
+func TestParseLookaroundErrors(t *testing.T) {
+	for _, p := range []string{`(a)(?=\1)`, `(?<!(?>a))b`, `(?=a++)`} {
+		_, err := Compile(p)
+		if !errors.Is(err, nfa.ErrUnsupportedLookaround) {
+			t.Errorf("Compile(%q) error = %v, want ErrUnsupportedLookaround", p, err)
+			continue
+		}
+		if strings.Contains(err.Error(), fancyCapture) {
+			t.Errorf("Compile(%q) error %q leaks a placeholder", p, err)
+		}
+	}
+}
+
+func TestParseGroups(t *testing.T) {
+	re, names, err := parse(`(?P<a>x)(?>(y)|z)\1\k<a>(w)++`, meta.DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"", "a", "", ""}; !reflect.DeepEqual(names, want) {
+		t.Errorf("names = %q, want %q", names, want)
+	}
+	var backrefs []int
+	var atomics int
+	var walk func(*syntax.Regexp)
+	walk = func(re *syntax.Regexp) {
+		if g, ok := backrefOf(re); ok {
+			backrefs = append(backrefs, g)
+		}
+		if _, ok := atomicOf(re); ok {
+			atomics++
+		}
+		for _, sub := range re.Sub {
+			walk(sub)
+		}
+	}
+	walk(re)
+	if !reflect.DeepEqual(backrefs, []int{1, 1}) || atomics != 2 {
+		t.Errorf("backrefs = %v, atomic groups = %d; want [1 1], 2", backrefs, atomics)
+	}
+}
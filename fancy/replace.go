@@ -0,0 +1,124 @@
+package fancy
+
+import "github.com/coregx/coregex/internal/expand"
+
+// ReplaceAll returns a copy of src with every match of r replaced by repl,
+// in which $ signs are interpreted as in Expand.
+func (r *Regex) ReplaceAll(src, repl []byte) ([]byte, error) {
+	template := string(repl)
+	return r.replaceAll(src, 2*len(r.names), func(dst []byte, loc []int) []byte {
+		return r.expand(dst, template, src, loc)
+	})
+}
+
+// ReplaceAllString is like ReplaceAll for strings.
+func (r *Regex) ReplaceAllString(src, repl string) (string, error) {
+	b := stringToBytes(src)
+	out, err := r.replaceAll(b, 2*len(r.names), func(dst []byte, loc []int) []byte {
+		return r.expand(dst, repl, b, loc)
+	})
+	return string(out), err
+}
+
+// ReplaceAllLiteral returns a copy of src with every match of r replaced by
+// repl, which is used as is.
+func (r *Regex) ReplaceAllLiteral(src, repl []byte) ([]byte, error) {
+	return r.replaceAll(src, 2, func(dst []byte, _ []int) []byte {
+		return append(dst, repl...)
+	})
+}
+
+// ReplaceAllLiteralString is like ReplaceAllLiteral for strings.
+func (r *Regex) ReplaceAllLiteralString(src, repl string) (string, error) {
+	out, err := r.replaceAll(stringToBytes(src), 2, func(dst []byte, _ []int) []byte {
+		return append(dst, repl...)
+	})
+	return string(out), err
+}
+
+// ReplaceAllFunc returns a copy of src with every match of r replaced by the
+// return value of repl applied to it.
+func (r *Regex) ReplaceAllFunc(src []byte, repl func([]byte) []byte) ([]byte, error) {
+	return r.replaceAll(src, 2, func(dst []byte, loc []int) []byte {
+		return append(dst, repl(src[loc[0]:loc[1]])...)
+	})
+}
+
+// ReplaceAllStringFunc is like ReplaceAllFunc for strings.
+func (r *Regex) ReplaceAllStringFunc(src string, repl func(string) string) (string, error) {
+	out, err := r.replaceAll(stringToBytes(src), 2, func(dst []byte, loc []int) []byte {
+		return append(dst, repl(src[loc[0]:loc[1]])...)
+	})
+	return string(out), err
+}
+
+// replaceAll returns a copy of src with every match replaced by what expand
+// appends for it. On error the result is nil.
+func (r *Regex) replaceAll(src []byte, slots int, expand func(dst []byte, loc []int) []byte) ([]byte, error) {
+	var out []byte
+	last := 0
+	err := r.all(src, -1, slots, func(loc []int) {
+		out = append(out, src[last:loc[0]]...)
+		out = expand(out, loc)
+		last = loc[1]
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append(out, src[last:]...), nil
+}
+
+// Split slices s into substrings separated by the matches of r, like
+// regexp.Regexp.Split: n > 0 returns at most n substrings (the last being
+// the unsplit remainder), n == 0 returns nil and n < 0 returns them all.
+func (r *Regex) Split(s string, n int) ([]string, error) {
+	if n == 0 {
+		return nil, nil
+	}
+	if r.pattern != "" && s == "" {
+		return []string{""}, nil
+	}
+
+	matches, err := r.FindAllStringIndex(s, n)
+	if err != nil {
+		return nil, err
+	}
+	parts := make([]string, 0, len(matches))
+	beg, end := 0, 0
+	for _, match := range matches {
+		if n > 0 && len(parts) == n-1 {
+			break
+		}
+		end = match[0]
+		if match[1] != 0 {
+			parts = append(parts, s[beg:end])
+		}
+		beg = match[1]
+	}
+	if end != len(s) {
+		parts = append(parts, s[beg:])
+	}
+	return parts, nil
+}
+
+// Expand appends template to dst with the variables of the template
+// replaced by the groups of match, as returned by FindSubmatchIndex on src.
+//
+// As in regexp, a variable is $name or ${name}, where a numeric name is a
+// group index and other names are group names; $name takes the longest
+// name possible. A reference to a missing or unmatched group expands to
+// nothing, and $$ is a literal $.
+func (r *Regex) Expand(dst []byte, template []byte, src []byte, match []int) []byte {
+	return r.expand(dst, string(template), src, match)
+}
+
+// ExpandString is like Expand for a string template and source.
+func (r *Regex) ExpandString(dst []byte, template string, src string, match []int) []byte {
+	return r.expand(dst, template, stringToBytes(src), match)
+}
+
+// expand implements Expand with the reference parser of the root package
+// templates.
+func (r *Regex) expand(dst []byte, template string, src []byte, match []int) []byte {
+	return expand.Append(dst, template, src, match, r.SubexpIndex)
+}
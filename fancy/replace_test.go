@@ -0,0 +1,100 @@
+package fancy
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestReplaceAll(t *testing.T) {
+	tests := []struct {
+		pattern, repl string
+		input, want   string
+	}{
+		{`\b(\w+) \1\b`, `$1`, "it is is here here", "it is here"},
+		{`(?P<w>\w)\k<w>`, `${w}`, "aabbc", "abc"},
+		{`(?P<w>\w)\k<w>`, `<$w$$>`, "xx", "<x$>"},
+		{`(\w)\1`, `$2[$1x]${1}x`, "oo", "[]ox"},
+		{`(?>a+)`, `-`, "baab", "b-b"},
+		{`(a)\1*`, ``, "caaat", "ct"},
+		{`x*+`, `-`, "abc", "-a-b-c-"},
+		{`\w+`, `<$0>`, "ab cd", "<ab> <cd>"},
+	}
+	for _, tt := range tests {
+		re := MustCompile(tt.pattern)
+		got, err := re.ReplaceAllString(tt.input, tt.repl)
+		if err != nil || got != tt.want {
+			t.Errorf("%q ReplaceAllString(%q, %q) = %q, %v; want %q", tt.pattern, tt.input, tt.repl, got, err, tt.want)
+		}
+		b, err := re.ReplaceAll([]byte(tt.input), []byte(tt.repl))
+		if err != nil || string(b) != tt.want {
+			t.Errorf("%q ReplaceAll(%q, %q) = %q, %v; want %q", tt.pattern, tt.input, tt.repl, b, err, tt.want)
+		}
+	}
+
+	re := MustCompile(`(\w)\1`)
+	if got, _ := re.ReplaceAllLiteralString("aab", "$1"); got != "$1b" {
+		t.Errorf("ReplaceAllLiteralString = %q", got)
+	}
+	if got, _ := re.ReplaceAllLiteral([]byte("aab"), []byte("$1")); string(got) != "$1b" {
+		t.Errorf("ReplaceAllLiteral = %q", got)
+	}
+	if got, _ := re.ReplaceAllStringFunc("aab cc", strings.ToUpper); got != "AAb CC" {
+		t.Errorf("ReplaceAllStringFunc = %q", got)
+	}
+	if got, _ := re.ReplaceAllFunc([]byte("xxy"), func(b []byte) []byte { return b[:1] }); string(got) != "xy" {
+		t.Errorf("ReplaceAllFunc = %q", got)
+	}
+}
+
+// TestExpandMatchesRegexp checks Expand against regexp.Regexp.Expand.
+func TestExpandMatchesRegexp(t *testing.T) {
+	const pattern = `(?P<first>\w+) (?P<second>\w+)(x)?`
+	const src = "hello world"
+	templates := []string{
+		``, `$first`, `${second}-$1`, `$1x`, `${1}x`, `$3`, `$$`, `$`, `${`, `${first`,
+		`$first$second`, `$01`, `$10`, `$nope`, `a$-b`, `$ 1`, `${}`, `$firstë`, `${1}ë`, `$1_`,
+	}
+	re := MustCompile(pattern)
+	std := regexp.MustCompile(pattern)
+	match, _ := re.FindStringSubmatchIndex(src)
+	for _, tmpl := range templates {
+		want := std.ExpandString(nil, tmpl, src, match)
+		if got := re.ExpandString(nil, tmpl, src, match); string(got) != string(want) {
+			t.Errorf("ExpandString(%q) = %q, want %q", tmpl, got, want)
+		}
+		if got := re.Expand([]byte("> "), []byte(tmpl), []byte(src), match); string(got) != "> "+string(want) {
+			t.Errorf("Expand(%q) = %q, want %q", tmpl, got, "> "+string(want))
+		}
+	}
+}
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		pattern, input string
+		n              int
+		want           []string
+	}{
+		{`(\W)\1`, "a--b,,c-d", -1, []string{"a", "b", "c-d"}},
+		{`(\W)\1`, "a--b,,c-d", 2, []string{"a", "b,,c-d"}},
+		{`(\W)\1`, "a--b", 0, nil},
+		{`(\W)\1`, "", -1, []string{""}},
+		{`(?>,+)`, ",a,,b,", -1, []string{"", "a", "b", ""}},
+		{`x*+`, "abc", -1, []string{"a", "b", "c"}},
+	}
+	for _, tt := range tests {
+		got, err := MustCompile(tt.pattern).Split(tt.input, tt.n)
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q Split(%q, %d) = %q, %v; want %q", tt.pattern, tt.input, tt.n, got, err, tt.want)
+		}
+	}
+
+	// Patterns without fancy constructs split like regexp.
+	for _, in := range []string{"", "a", "a,b", ",a,,b,"} {
+		got, _ := MustCompile(`,*`).Split(in, -1)
+		if want := regexp.MustCompile(`,*`).Split(in, -1); !reflect.DeepEqual(got, want) {
+			t.Errorf("Split(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package fancy
+
+import (
+	"bytes"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/coregx/coregex/meta"
+)
+
+// frame is an entry of the backtrack stack: an alternative to resume at pc
+// and pos, or, when pc is -1, a slot to restore to the value pos.
+type frame struct {
+	pc   int
+	pos  int
+	slot int
+}
+
+// vm runs a program by backtracking. It is used by one search at a time.
+type vm struct {
+	prog  *program
+	stack []frame
+	slots []int
+	steps int
+	limit int
+}
+
+// newVM returns a VM for prog.
+func newVM(prog *program) *vm {
+	return &vm{prog: prog, slots: make([]int, prog.numSlots)}
+}
+
+// search finds the leftmost match starting at or after at, leaving its
+// capture positions in m.slots. It fails with a *meta.SearchAbortedError
+// caused by ErrStepLimit after more than limit steps (limit <= 0 means no
+// limit).
+func (m *vm) search(haystack []byte, at, limit int) (bool, error) {
+	m.steps, m.limit = 0, limit
+	prog := m.prog
+	for start := at; start <= len(haystack); start = nextChar(haystack, start, prog.bytes) {
+		if len(prog.prefix) > 0 {
+			i := bytes.Index(haystack[start:], prog.prefix)
+			if i < 0 {
+				return false, nil
+			}
+			start += i
+		}
+		if found, err := m.run(haystack, start); found || err != nil {
+			return found, err
+		}
+		if prog.anchored {
+			break
+		}
+	}
+	return false, nil
+}
+
+// nextChar returns the position of the character after the one at pos, or
+// pos+1 at the end of the haystack.
+func nextChar(haystack []byte, pos int, byteMode bool) int {
+	if pos >= len(haystack) || byteMode {
+		return pos + 1
+	}
+	_, size := utf8.DecodeRune(haystack[pos:])
+	return pos + size
+}
+
+// run matches the program anchored at start.
+func (m *vm) run(haystack []byte, start int) (bool, error) {
+	for i := range m.slots {
+		m.slots[i] = -1
+	}
+	m.stack = m.stack[:0]
+
+	insts := m.prog.insts
+	pc, pos := 0, start
+	for {
+		m.steps++
+		if m.limit > 0 && m.steps > m.limit {
+			return false, &meta.SearchAbortedError{Cause: ErrStepLimit, Work: int64(m.steps)}
+		}
+
+		in := &insts[pc]
+		ok := true
+		switch in.op {
+		case opMatch:
+			return true, nil
+		case opRunes:
+			pos, ok = matchRunes(haystack, pos, in.runes, in.fold)
+		case opClass:
+			pos, ok = matchClass(haystack, pos, in.runes)
+		case opDelegate:
+			pos, ok = m.delegate(haystack, pos, in.arg)
+		case opSplit:
+			m.stack = append(m.stack, frame{pc: in.y, pos: pos})
+			pc = in.x
+			continue
+		case opJmp:
+			pc = in.x
+			continue
+		case opSave:
+			m.set(in.arg, pos)
+		case opBackref:
+			pos, ok = m.backref(haystack, pos, in.arg, in.fold)
+		case opProgress:
+			if m.slots[in.arg] == pos {
+				pc = in.x
+				continue
+			}
+		case opAtomicStart:
+			m.set(in.arg, len(m.stack))
+		case opAtomicEnd:
+			m.cut(m.slots[in.arg])
+		}
+		if ok {
+			pc++
+			continue
+		}
+		if pc, pos, ok = m.backtrack(); !ok {
+			return false, nil
+		}
+	}
+}
+
+// set sets a slot, recording its old value for backtracking.
+func (m *vm) set(slot, value int) {
+	m.stack = append(m.stack, frame{pc: -1, pos: m.slots[slot], slot: slot})
+	m.slots[slot] = value
+}
+
+// backtrack restores slots up to the most recent alternative and returns
+// it. ok is false when no alternative is left.
+func (m *vm) backtrack() (pc, pos int, ok bool) {
+	for n := len(m.stack); n > 0; n = len(m.stack) {
+		f := m.stack[n-1]
+		m.stack = m.stack[:n-1]
+		if f.pc < 0 {
+			m.slots[f.slot] = f.pos
+			continue
+		}
+		return f.pc, f.pos, true
+	}
+	return 0, 0, false
+}
+
+// cut drops the alternatives above depth mark of the stack, keeping the
+// slot restores so that backtracking past the atomic group still undoes
+// its captures.
+func (m *vm) cut(mark int) {
+	kept := m.stack[:mark]
+	for _, f := range m.stack[mark:] {
+		if f.pc < 0 {
+			kept = append(kept, f)
+		}
+	}
+	m.stack = kept
+}
+
+// delegate matches a delegate anchored at pos and returns the end of the
+// match.
+func (m *vm) delegate(haystack []byte, pos, idx int) (int, bool) {
+	d := &m.prog.delegates[idx]
+	in := meta.NewInput(haystack).WithSpan(pos, len(haystack)).WithAnchor(meta.AnchorStart)
+	if !d.captures {
+		_, end, found := d.engine.SearchIndices(in)
+		return end, found
+	}
+	match := d.engine.SearchSubmatch(in)
+	if match == nil {
+		return pos, false
+	}
+	for group := 1; group < match.NumCaptures(); group++ {
+		if span := match.GroupIndex(group); span != nil {
+			m.set(2*group, span[0])
+			m.set(2*group+1, span[1])
+		}
+	}
+	return match.End(), true
+}
+
+// backref matches the text of a group again at pos. A group that has not
+// matched fails.
+func (m *vm) backref(haystack []byte, pos, group int, fold bool) (int, bool) {
+	start, end := m.slots[2*group], m.slots[2*group+1]
+	if start < 0 || end < start {
+		return pos, false
+	}
+	text := haystack[start:end]
+	if !fold {
+		if bytes.HasPrefix(haystack[pos:], text) {
+			return pos + len(text), true
+		}
+		return pos, false
+	}
+
+	for len(text) > 0 {
+		if pos >= len(haystack) {
+			return pos, false
+		}
+		want, n := m.decode(text)
+		got, size := m.decode(haystack[pos:])
+		if !equalFold(want, got) {
+			return pos, false
+		}
+		text = text[n:]
+		pos += size
+	}
+	return pos, true
+}
+
+// decode returns the character at the start of b.
+func (m *vm) decode(b []byte) (rune, int) {
+	if m.prog.bytes {
+		return rune(b[0]), 1
+	}
+	return utf8.DecodeRune(b)
+}
+
+// matchRunes matches a literal at pos.
+func matchRunes(haystack []byte, pos int, runes []rune, fold bool) (int, bool) {
+	for _, want := range runes {
+		if pos >= len(haystack) {
+			return pos, false
+		}
+		got, size := utf8.DecodeRune(haystack[pos:])
+		if got != want && (!fold || !equalFold(want, got)) {
+			return pos, false
+		}
+		pos += size
+	}
+	return pos, true
+}
+
+// matchClass matches one character in ranges (sorted lo, hi pairs) at pos.
+func matchClass(haystack []byte, pos int, ranges []rune) (int, bool) {
+	if pos >= len(haystack) {
+		return pos, false
+	}
+	r, size := utf8.DecodeRune(haystack[pos:])
+	lo, hi := 0, len(ranges)/2
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		switch {
+		case r < ranges[2*mid]:
+			hi = mid
+		case r > ranges[2*mid+1]:
+			lo = mid + 1
+		default:
+			return pos + size, true
+		}
+	}
+	return pos, false
+}
+
+// equalFold reports whether a and b are equal under simple case folding.
+func equalFold(a, b rune) bool {
+	if a == b {
+		return true
+	}
+	for f := unicode.SimpleFold(a); f != a; f = unicode.SimpleFold(f) {
+		if f == b {
+			return true
+		}
+	}
+	return false
+}
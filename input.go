@@ -0,0 +1,90 @@
+package coregex
+
+import (
+	"github.com/coregx/coregex/meta"
+)
+
+// Input describes one search: a haystack, the span of it to search, and the
+// anchoring and earliest-match modes. Create one with NewInput and refine it
+// with the With methods; see Regex.Search.
+//
+// Unlike searching a subslice, searching a span keeps the rest of the
+// haystack as look-around context: ^ only matches at the start of the
+// haystack, $ only at its end, and \b at a span edge sees the neighbouring
+// byte.
+type Input = meta.Input
+
+// Anchor selects where a match may start and end within the span of an Input.
+type Anchor = meta.Anchor
+
+// Anchoring modes for Input.WithAnchor.
+const (
+	// Unanchored allows a match anywhere in the span.
+	Unanchored = meta.Unanchored
+
+	// AnchorStart requires the match to start at the span start.
+	AnchorStart = meta.AnchorStart
+
+	// AnchorBoth requires the match to cover the whole span (a full match).
+	AnchorBoth = meta.AnchorBoth
+)
+
+// NewInput returns an unanchored Input that searches all of haystack.
+func NewInput(haystack []byte) Input {
+	return meta.NewInput(haystack)
+}
+
+// NewInputString is like NewInput for a string haystack.
+func NewInputString(s string) Input {
+	return meta.NewInput(stringToBytes(s))
+}
+
+// MatchInput reports whether the regex matches within the span of in.
+//
+// Example:
+//
+//	re := coregex.MustCompile(`\bcat\b`)
+//	in := coregex.NewInputString("concatenate").WithSpan(3, 6)
+//	re.MatchInput(in) // false: the span is "cat", but \b sees "n" and "e"
+func (r *Regex) MatchInput(in Input) bool {
+	return r.engine.IsMatchInput(in)
+}
+
+// Search returns the index pair [start, end] of the first match within the
+// span of in, as offsets into the whole haystack, or nil if there is none.
+//
+// Example:
+//
+//	re := coregex.MustCompile(`\d+`)
+//	in := coregex.NewInputString("id=12345").WithSpan(3, 6).WithAnchor(coregex.AnchorStart)
+//	re.Search(in) // [3 6]
+func (r *Regex) Search(in Input) []int {
+	start, end, found := r.engine.SearchIndices(in)
+	if !found {
+		return nil
+	}
+	return []int{start, end}
+}
+
+// SearchSubmatch is like Search but also returns the index pairs of the
+// capture groups, in the layout of FindSubmatchIndex.
+func (r *Regex) SearchSubmatch(in Input) []int {
+	match := r.engine.SearchSubmatch(in)
+	if match == nil {
+		return nil
+	}
+
+	numGroups := match.NumCaptures()
+	result := make([]int, numGroups*2)
+	for i := 0; i < numGroups; i++ {
+		idx := match.GroupIndex(i)
+		if len(idx) >= 2 {
+			result[i*2] = idx[0]
+			result[i*2+1] = idx[1]
+		} else {
+			result[i*2] = -1
+			result[i*2+1] = -1
+		}
+	}
+	return result
+}
@@ -0,0 +1,115 @@
+package coregex
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestSearchInput(t *testing.T) {
+	tests := []struct {
+		name     string
+		pattern  string
+		haystack string
+		start    int
+		end      int
+		anchor   Anchor
+		earliest bool
+		want     []int // nil for no match
+	}{
+		{"whole haystack", `\d+`, "ab123cd", 0, 7, Unanchored, false, []int{2, 5}},
+		{"span cuts match", `\d+`, "ab123cd", 0, 4, Unanchored, false, []int{2, 4}},
+		{"span skips match", `\d+`, "12ab34", 2, 6, Unanchored, false, []int{4, 6}},
+		{"no match in span", `\d+`, "ab123cd", 5, 7, Unanchored, false, nil},
+		{"offsets are absolute", `b`, "abab", 2, 4, Unanchored, false, []int{3, 4}},
+
+		{"word boundary sees outside start", `\bcat`, "concat", 3, 6, Unanchored, false, nil},
+		{"word boundary sees outside end", `cat\b`, "cats", 0, 3, Unanchored, false, nil},
+		{"word boundary at real edges", `\bcat\b`, "a cat b", 2, 5, Unanchored, false, []int{2, 5}},
+		{"non-word boundary at span edge", `\Bcat\B`, "xcatx", 1, 4, Unanchored, false, []int{1, 4}},
+		{"caret is haystack start", `^a`, "aaa", 1, 3, Unanchored, false, nil},
+		{"dollar is haystack end", `a$`, "aaa", 0, 2, Unanchored, false, nil},
+		{"multiline caret after newline", `(?m)^b`, "a\nb", 2, 3, Unanchored, false, []int{2, 3}},
+
+		{"anchored start", `\d+`, "ab123", 2, 5, AnchorStart, false, []int{2, 5}},
+		{"anchored start misses", `\d+`, "ab123", 1, 5, AnchorStart, false, nil},
+		{"anchored start bounded", `\d+`, "12345", 0, 3, AnchorStart, false, []int{0, 3}},
+		{"anchored start empty", `a*`, "bbb", 1, 3, AnchorStart, false, []int{1, 1}},
+
+		{"full", `a|ab`, "xab", 1, 3, AnchorBoth, false, []int{1, 3}},
+		{"full too short", `\d+`, "12a", 0, 3, AnchorBoth, false, nil},
+		{"full lazy", `a+?`, "aaa", 0, 3, AnchorBoth, false, []int{0, 3}},
+		{"full empty span", `a*`, "xyz", 1, 1, AnchorBoth, false, []int{1, 1}},
+		{"full with boundary", `\w+\b`, "abc", 0, 2, AnchorBoth, false, nil},
+
+		{"earliest", `a+`, "xaaa", 0, 4, Unanchored, true, []int{1, 2}},
+		{"earliest alternation", `abc|b`, "abc", 0, 3, Unanchored, true, []int{1, 2}},
+		{"earliest anchored", `\d+`, "123", 0, 3, AnchorStart, true, []int{0, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			re := MustCompile(tt.pattern)
+			in := NewInputString(tt.haystack).WithSpan(tt.start, tt.end).
+				WithAnchor(tt.anchor).WithEarliest(tt.earliest)
+
+			if got := re.Search(in); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Search = %v, want %v", got, tt.want)
+			}
+			if got := re.MatchInput(in); got != (tt.want != nil) {
+				t.Errorf("MatchInput = %v, want %v", got, tt.want != nil)
+			}
+			if got := re.SearchSubmatch(in); tt.want == nil && got != nil ||
+				tt.want != nil && (got == nil || got[0] != tt.want[0] || got[1] != tt.want[1]) {
+				t.Errorf("SearchSubmatch = %v, want match %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchSubmatchInput(t *testing.T) {
+	re := MustCompile(`(\w+)=(\d+)?`)
+	haystack := "x a=1 bb= cc=22"
+
+	in := NewInputString(haystack).WithSpan(6, 15)
+	if got, want := re.SearchSubmatch(in), []int{6, 9, 6, 8, -1, -1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("unanchored: got %v, want %v", got, want)
+	}
+
+	in = NewInputString(haystack).WithSpan(10, 14).WithAnchor(AnchorBoth)
+	if got, want := re.SearchSubmatch(in), []int{10, 14, 10, 12, 13, 14}; !reflect.DeepEqual(got, want) {
+		t.Errorf("full: got %v, want %v", got, want)
+	}
+}
+
+// TestSearchInputWholeHaystack checks that an Input covering the whole
+// haystack agrees with stdlib regexp, with AnchorBoth as ^(?:...)$.
+func TestSearchInputWholeHaystack(t *testing.T) {
+	patterns := []string{`a+`, `(a|ab)(c|bcd)(d*)`, `\b\w+\b`, `x*`, `(?i)hello`, `[^b]+b`}
+	haystacks := []string{"", "a", "abcd", "hello world", "aaab", "HeLLo", "xx"}
+
+	for _, pattern := range patterns {
+		re := MustCompile(pattern)
+		std := regexp.MustCompile(pattern)
+		full := regexp.MustCompile(`^(?:` + pattern + `)$`)
+		for _, h := range haystacks {
+			in := NewInputString(h)
+			if got, want := re.SearchSubmatch(in), std.FindStringSubmatchIndex(h); !reflect.DeepEqual(got, want) {
+				t.Errorf("%q on %q: SearchSubmatch = %v, want %v", pattern, h, got, want)
+			}
+			in = in.WithAnchor(AnchorBoth)
+			if got, want := re.SearchSubmatch(in), full.FindStringSubmatchIndex(h); !reflect.DeepEqual(got, want) {
+				t.Errorf("%q on %q: full SearchSubmatch = %v, want %v", pattern, h, got, want)
+			}
+		}
+	}
+}
+
+func TestInputSpanPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("WithSpan out of range did not panic")
+		}
+	}()
+	NewInputString("abc").WithSpan(2, 4)
+}
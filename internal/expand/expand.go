@@ -0,0 +1,96 @@
+// Package expand parses the $name and ${name} group references of
+// replacement templates, for the template compiler of the root package and
+// the Expand of package fancy.
+package expand
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Ref parses a $name or ${name} reference at the start of s, which begins
+// with '$'. num is the group number for a numeric name and -1 otherwise.
+// This follows the rules of regexp.Regexp.Expand: a name is a run of
+// letters, digits and underscores, and $name takes the longest name
+// possible.
+func Ref(s string) (name string, num int, rest string, ok bool) {
+	if len(s) < 2 || s[0] != '$' {
+		return "", 0, "", false
+	}
+	brace := false
+	if s[1] == '{' {
+		brace = true
+		s = s[2:]
+	} else {
+		s = s[1:]
+	}
+	i := 0
+	for i < len(s) {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+			break
+		}
+		i += size
+	}
+	if i == 0 {
+		return "", 0, "", false
+	}
+	name = s[:i]
+	if brace {
+		if i >= len(s) || s[i] != '}' {
+			return "", 0, "", false
+		}
+		i++
+	}
+	rest = s[i:]
+
+	num = 0
+	for i := 0; i < len(name); i++ {
+		if name[i] < '0' || '9' < name[i] || num >= 1e8 {
+			num = -1
+			break
+		}
+		num = num*10 + int(name[i]) - '0'
+	}
+	// Disallow leading zeros.
+	if name[0] == '0' && len(name) > 1 {
+		num = -1
+	}
+	return name, num, rest, true
+}
+
+// Append appends template to dst with each reference replaced by the text
+// of its group in src, as located by match (FindSubmatchIndex pairs).
+// index returns the number of a named group, or -1. A reference to a
+// missing or unmatched group expands to nothing, $$ is a literal $ and a
+// $ that does not start a reference is kept as is.
+func Append(dst []byte, template string, src []byte, match []int, index func(name string) int) []byte {
+	for {
+		i := strings.IndexByte(template, '$')
+		if i < 0 {
+			break
+		}
+		dst = append(dst, template[:i]...)
+		template = template[i:]
+		if len(template) > 1 && template[1] == '$' {
+			dst = append(dst, '$')
+			template = template[2:]
+			continue
+		}
+		name, num, rest, ok := Ref(template)
+		if !ok {
+			dst = append(dst, '$')
+			template = template[1:]
+			continue
+		}
+		template = rest
+		if num < 0 {
+			num = index(name)
+		}
+		if num >= 0 && 2*num+1 < len(match) && match[2*num] >= 0 {
+			dst = append(dst, src[match[2*num]:match[2*num+1]]...)
+		}
+	}
+	return append(dst, template...)
+}
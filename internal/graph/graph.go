@@ -0,0 +1,161 @@
+// Package graph holds the automaton graphs that the nfa, lazy and onepass
+// packages render for debugging, as Graphviz DOT or as JSON.
+//
+// A package describes its automaton as a Graph of numbered nodes and
+// labeled edges; WriteDOT and WriteJSON then produce the same graph in the
+// two formats, so tools can read the JSON form of what a DOT picture shows.
+package graph
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Graph is a directed graph of automaton states.
+type Graph struct {
+	// Name is the automaton kind, used as the DOT graph name.
+	Name  string `json:"name"`
+	Nodes []Node `json:"nodes"`
+	Edges []Edge `json:"edges"`
+}
+
+// Node is an automaton state.
+type Node struct {
+	ID uint32 `json:"id"`
+
+	// Label describes the state; it is the node label in DOT.
+	Label string `json:"label"`
+
+	// Match marks accepting states, drawn with a double circle.
+	Match bool `json:"match,omitempty"`
+
+	// Starts names the start configurations that begin in this state,
+	// e.g. "anchored"; each gets an entry arrow in DOT.
+	Starts []string `json:"starts,omitempty"`
+
+	// NFAStates is the NFA state set of a DFA state.
+	NFAStates []uint32 `json:"nfa_states,omitempty"`
+
+	// Slots is the capture slot mask a one-pass match state saves when the
+	// match ends in it.
+	Slots uint32 `json:"slots,omitempty"`
+}
+
+// Edge is a transition between two states.
+type Edge struct {
+	From uint32 `json:"from"`
+	To   uint32 `json:"to"`
+
+	// Label describes the transition; it is the edge label in DOT.
+	Label string `json:"label"`
+
+	// Ranges are the inclusive byte ranges the transition consumes; for
+	// NFA rune transitions, which consume a whole UTF-8 sequence, those of
+	// its first byte. An edge without ranges is an epsilon transition,
+	// drawn dashed.
+	Ranges [][2]byte `json:"ranges,omitempty"`
+
+	// Slots is the capture slot mask a one-pass transition saves.
+	Slots uint32 `json:"slots,omitempty"`
+}
+
+// WriteDOT writes g in Graphviz DOT format.
+func (g *Graph) WriteDOT(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %s {\n\trankdir=LR;\n\tnode [shape=circle];\n", g.Name)
+	for _, n := range g.Nodes {
+		shape := ""
+		if n.Match {
+			shape = ", shape=doublecircle"
+		}
+		fmt.Fprintf(&b, "\t%d [label=%s%s];\n", n.ID, quote(n.Label), shape)
+		for i, start := range n.Starts {
+			fmt.Fprintf(&b, "\tstart_%d_%d [shape=point];\n\tstart_%d_%d -> %d [label=%s];\n",
+				n.ID, i, n.ID, i, n.ID, quote(start))
+		}
+	}
+	for _, e := range g.Edges {
+		style := ""
+		if len(e.Ranges) == 0 {
+			style = ", style=dashed"
+		}
+		fmt.Fprintf(&b, "\t%d -> %d [label=%s%s];\n", e.From, e.To, quote(e.Label), style)
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteJSON writes g as indented JSON.
+func (g *Graph) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(g)
+}
+
+// quote returns s as a DOT string literal.
+func quote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
+
+// Byte formats b as itself if it is printable ASCII and as a \x escape
+// otherwise. Class syntax characters are escaped with a backslash.
+func Byte(b byte) string {
+	switch {
+	case strings.IndexByte(`\[]-^`, b) >= 0:
+		return `\` + string(rune(b))
+	case b > ' ' && b <= '~':
+		return string(rune(b))
+	}
+	return fmt.Sprintf(`\x%02x`, b)
+}
+
+// RangesLabel formats byte ranges like a character class: "a", "[a-z_]".
+func RangesLabel(ranges [][2]byte) string {
+	if len(ranges) == 1 && ranges[0][0] == ranges[0][1] {
+		return Byte(ranges[0][0])
+	}
+	var b strings.Builder
+	b.WriteByte('[')
+	for _, r := range ranges {
+		b.WriteString(Byte(r[0]))
+		if r[1] != r[0] {
+			if r[1] > r[0]+1 {
+				b.WriteByte('-')
+			}
+			b.WriteString(Byte(r[1]))
+		}
+	}
+	b.WriteByte(']')
+	return b.String()
+}
+
+// SlotsLabel formats a capture slot mask as its slot numbers: "{0 3}".
+func SlotsLabel(mask uint32) string {
+	var slots []string
+	for i := range 32 {
+		if mask&(1<<i) != 0 {
+			slots = append(slots, fmt.Sprint(i))
+		}
+	}
+	return "{" + strings.Join(slots, " ") + "}"
+}
+
+// Ranges merges a sorted byte set into inclusive ranges.
+func Ranges(bytes []byte) [][2]byte {
+	var ranges [][2]byte
+	for _, c := range bytes {
+		if n := len(ranges); n > 0 && int(ranges[n-1][1])+1 == int(c) {
+			ranges[n-1][1] = c
+			continue
+		}
+		ranges = append(ranges, [2]byte{c, c})
+	}
+	return ranges
+}
@@ -0,0 +1,116 @@
+package graph
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWriteDOT(t *testing.T) {
+	g := &Graph{
+		Name: "test",
+		Nodes: []Node{
+			{ID: 0, Label: "0", Starts: []string{"anchored"}},
+			{ID: 1, Label: "1\n\"m\"", Match: true},
+		},
+		Edges: []Edge{
+			{From: 0, To: 1, Label: "[a-z]", Ranges: [][2]byte{{'a', 'z'}}},
+			{From: 1, To: 0, Label: `ε`},
+		},
+	}
+	var b bytes.Buffer
+	if err := g.WriteDOT(&b); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	for _, want := range []string{
+		"digraph test {\n",
+		`0 [label="0"];`,
+		`1 [label="1\n\"m\"", shape=doublecircle];`,
+		`start_0_0 -> 0 [label="anchored"];`,
+		`0 -> 1 [label="[a-z]"];`,
+		`1 -> 0 [label="ε", style=dashed];`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("WriteDOT output lacks %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	g := &Graph{
+		Name:  "test",
+		Nodes: []Node{{ID: 3, Label: "3", Match: true, NFAStates: []uint32{1, 2}, Slots: 0b1010}},
+		Edges: []Edge{{From: 3, To: 3, Label: "<", Ranges: [][2]byte{{'<', '<'}}, Slots: 1}},
+	}
+	var b bytes.Buffer
+	if err := g.WriteJSON(&b); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(b.String(), `\u003c`) {
+		t.Errorf("WriteJSON escapes HTML:\n%s", b.String())
+	}
+	var got Graph
+	if err := json.Unmarshal(b.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&got, g) {
+		t.Errorf("WriteJSON round trip = %+v, want %+v", got, *g)
+	}
+}
+
+func TestByte(t *testing.T) {
+	tests := []struct {
+		b    byte
+		want string
+	}{
+		{'a', "a"},
+		{'-', `\-`},
+		{']', `\]`},
+		{'\\', `\\`},
+		{' ', `\x20`},
+		{'\n', `\x0a`},
+		{0xff, `\xff`},
+	}
+	for _, tt := range tests {
+		if got := Byte(tt.b); got != tt.want {
+			t.Errorf("Byte(%#x) = %q, want %q", tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestRangesLabel(t *testing.T) {
+	tests := []struct {
+		bytes string
+		want  string
+	}{
+		{"a", "a"},
+		{"ab", "[ab]"},
+		{"abc_", "[a-c_]"},
+		{"0123456789abcdef", "[0-9a-f]"},
+	}
+	for _, tt := range tests {
+		if got := RangesLabel(Ranges([]byte(tt.bytes))); got != tt.want {
+			t.Errorf("RangesLabel(%q) = %q, want %q", tt.bytes, got, tt.want)
+		}
+	}
+}
+
+func TestSlotsLabel(t *testing.T) {
+	tests := []struct {
+		mask uint32
+		want string
+	}{
+		{0, "{}"},
+		{1, "{0}"},
+		{0b1001, "{0 3}"},
+		{1 << 31, "{31}"},
+	}
+	for _, tt := range tests {
+		if got := SlotsLabel(tt.mask); got != tt.want {
+			t.Errorf("SlotsLabel(%#b) = %q, want %q", tt.mask, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+// Package scan splits regexp/syntax pattern text into the tokens that the
+// pattern rewriters of the meta, nfa and fancy packages must step over
+// whole: escapes, \Q...\E and character classes. It follows the rules of
+// the regexp/syntax parser, so a rewriter never mistakes an escaped or
+// quoted byte, or a byte inside a class, for an operator.
+package scan
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Kind is the kind of a Token.
+type Kind uint8
+
+const (
+	// Byte is a byte outside a character class, e.g. '(' or 'a'.
+	Byte Kind = iota
+
+	// Escape is a backslash and the rune after it, or a backslash that
+	// ends the pattern.
+	Escape
+
+	// Quoted is \Q...\E, or \Q and the rest of the pattern if there is
+	// no \E.
+	Quoted
+
+	// ClassOpen is the '[' that starts a character class, with the '^' and
+	// the literal ']' that may follow it.
+	ClassOpen
+
+	// ClassByte is a byte inside a character class.
+	ClassByte
+
+	// ClassName is an ASCII class such as [:alpha:] inside a character
+	// class.
+	ClassName
+
+	// ClassClose is the ']' that ends a character class.
+	ClassClose
+)
+
+// Token is a token of a pattern: pattern[Start:End].
+type Token struct {
+	Kind       Kind
+	Start, End int
+	InClass    bool // the token is inside a character class
+}
+
+// Scanner returns the tokens of a pattern in order.
+type Scanner struct {
+	pattern string
+	pos     int
+	inClass bool
+}
+
+// New returns a Scanner at the start of pattern.
+func New(pattern string) *Scanner {
+	return &Scanner{pattern: pattern}
+}
+
+// Next returns the next token, or false at the end of the pattern.
+func (s *Scanner) Next() (Token, bool) {
+	p, i := s.pattern, s.pos
+	if i >= len(p) {
+		return Token{}, false
+	}
+	tok := Token{Kind: Byte, Start: i, End: i + 1, InClass: s.inClass}
+	switch {
+	case strings.HasPrefix(p[i:], `\Q`):
+		tok.Kind, tok.End = Quoted, len(p)
+		if end := strings.Index(p[i+2:], `\E`); end >= 0 {
+			tok.End = i + 2 + end + 2
+		}
+
+	case p[i] == '\\':
+		tok.Kind = Escape
+		if i+1 < len(p) {
+			_, size := utf8.DecodeRuneInString(p[i+1:])
+			tok.End = i + 1 + size
+		}
+
+	case s.inClass:
+		tok.Kind = ClassByte
+		switch {
+		case p[i] == ']':
+			tok.Kind = ClassClose
+			s.inClass = false
+		case strings.HasPrefix(p[i:], "[:"):
+			// An ASCII class such as [:alpha:] may contain a ']'.
+			if end := strings.Index(p[i+2:], ":]"); end >= 0 {
+				tok.Kind, tok.End = ClassName, i+2+end+2
+			}
+		}
+
+	case p[i] == '[':
+		tok.Kind = ClassOpen
+		s.inClass = true
+		// A ']' right after '[' or '[^' is a literal, not the end.
+		if tok.End < len(p) && p[tok.End] == '^' {
+			tok.End++
+		}
+		if tok.End < len(p) && p[tok.End] == ']' {
+			tok.End++
+		}
+	}
+	s.pos = tok.End
+	return tok, true
+}
+
+// Seek continues the scan at pos, outside a character class. It lets a
+// caller step over a construct it parses itself.
+func (s *Scanner) Seek(pos int) {
+	s.pos = pos
+	s.inClass = false
+}
@@ -0,0 +1,50 @@
+package scan
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestScanner(t *testing.T) {
+	// Each token is written as its text, with a prefix for the kinds
+	// other than Byte.
+	prefixes := map[Kind]string{Escape: "E:", Quoted: "Q:", ClassOpen: "[:", ClassByte: "c:", ClassName: "n:", ClassClose: "]:"}
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{`a(b)`, []string{"a", "(", "b", ")"}},
+		{`\(x\é`, []string{`E:\(`, "x", `E:\é`}},
+		{`\Q(]\Ea`, []string{`Q:\Q(]\E`, "a"}},
+		{`\Q(`, []string{`Q:\Q(`}},
+		{`[]a]b`, []string{"[:[]", "c:a", "]:]", "b"}},
+		{`[^]\]][:a]`, []string{"[:[^]", `E:\]`, "]:]", "[:[", "c::", "c:a", "]:]"}},
+		{`[[:alpha:]](`, []string{"[:[", "n:[:alpha:]", "]:]", "("}},
+		{`a\`, []string{"a", `E:\`}},
+	}
+	for _, tt := range tests {
+		var got []string
+		s := New(tt.pattern)
+		for {
+			tok, ok := s.Next()
+			if !ok {
+				break
+			}
+			got = append(got, prefixes[tok.Kind]+tt.pattern[tok.Start:tok.End])
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("tokens of %q = %q, want %q", tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestScannerSeek(t *testing.T) {
+	s := New(`[a]b`)
+	if tok, _ := s.Next(); tok.Kind != ClassOpen {
+		t.Fatalf("first token = %v, want ClassOpen", tok)
+	}
+	s.Seek(3)
+	if tok, ok := s.Next(); !ok || tok.Kind != Byte || tok.InClass || tok.Start != 3 {
+		t.Errorf("token after Seek(3) = %+v, want the Byte 'b'", tok)
+	}
+}
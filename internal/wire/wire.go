@@ -0,0 +1,173 @@
+// Package wire provides the primitive encoding shared by the binary formats
+// of the nfa, onepass, literal and meta packages.
+//
+// Values are written with append-style helpers on a byte slice: unsigned
+// integers as uvarints, signed integers as zig-zag varints, and byte strings
+// with a uvarint length prefix. Reader decodes them in the same order and
+// keeps the first error, so a decoder can read a whole record and check Err
+// once at the end.
+package wire
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// ErrInvalid is returned for truncated or malformed data.
+var ErrInvalid = errors.New("invalid binary data")
+
+// AppendUint appends v as a uvarint.
+func AppendUint(b []byte, v uint64) []byte {
+	return binary.AppendUvarint(b, v)
+}
+
+// AppendInt appends v as a zig-zag varint.
+func AppendInt(b []byte, v int) []byte {
+	return binary.AppendVarint(b, int64(v))
+}
+
+// AppendBool appends v as one byte.
+func AppendBool(b []byte, v bool) []byte {
+	if v {
+		return append(b, 1)
+	}
+	return append(b, 0)
+}
+
+// AppendBytes appends the length of v followed by v.
+func AppendBytes(b, v []byte) []byte {
+	b = binary.AppendUvarint(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+// AppendString appends the length of v followed by v.
+func AppendString(b []byte, v string) []byte {
+	b = binary.AppendUvarint(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+// Reader decodes values written by the Append functions.
+type Reader struct {
+	data []byte
+	err  error
+}
+
+// NewReader returns a Reader that decodes data.
+func NewReader(data []byte) *Reader {
+	return &Reader{data: data}
+}
+
+// Err returns the first error encountered, or nil.
+func (r *Reader) Err() error {
+	return r.err
+}
+
+// Fail records err unless an error was already recorded.
+func (r *Reader) Fail(err error) {
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+// Done returns the first error encountered, or ErrInvalid if data is left
+// over after the last value.
+func (r *Reader) Done() error {
+	if r.err == nil && len(r.data) != 0 {
+		r.err = ErrInvalid
+	}
+	return r.err
+}
+
+// Uint reads a uvarint.
+func (r *Reader) Uint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(r.data)
+	if n <= 0 {
+		r.err = ErrInvalid
+		return 0
+	}
+	r.data = r.data[n:]
+	return v
+}
+
+// Uint32 reads a uvarint that must fit in a uint32.
+func (r *Reader) Uint32() uint32 {
+	v := r.Uint()
+	if v > math.MaxUint32 {
+		r.Fail(ErrInvalid)
+		return 0
+	}
+	return uint32(v)
+}
+
+// Int reads a zig-zag varint that must fit in an int.
+func (r *Reader) Int() int {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Varint(r.data)
+	if n <= 0 || v < math.MinInt || v > math.MaxInt {
+		r.err = ErrInvalid
+		return 0
+	}
+	r.data = r.data[n:]
+	return int(v)
+}
+
+// Len reads a count of elements that each take at least one byte, so it is
+// never larger than the data left. This bounds the allocations a decoder
+// makes for corrupt data.
+func (r *Reader) Len() int {
+	v := r.Uint()
+	if v > uint64(len(r.data)) {
+		r.Fail(ErrInvalid)
+		return 0
+	}
+	return int(v)
+}
+
+// Byte reads one byte.
+func (r *Reader) Byte() byte {
+	if r.err != nil {
+		return 0
+	}
+	if len(r.data) == 0 {
+		r.err = ErrInvalid
+		return 0
+	}
+	v := r.data[0]
+	r.data = r.data[1:]
+	return v
+}
+
+// Bool reads a value written by AppendBool.
+func (r *Reader) Bool() bool {
+	switch r.Byte() {
+	case 0:
+		return false
+	case 1:
+		return true
+	}
+	r.Fail(ErrInvalid)
+	return false
+}
+
+// Bytes reads a value written by AppendBytes. The result aliases the data
+// of the Reader.
+func (r *Reader) Bytes() []byte {
+	n := r.Len()
+	if r.err != nil {
+		return nil
+	}
+	v := r.data[:n:n]
+	r.data = r.data[n:]
+	return v
+}
+
+// String reads a value written by AppendString.
+func (r *Reader) String() string {
+	return string(r.Bytes())
+}
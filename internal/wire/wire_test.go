@@ -0,0 +1,62 @@
+package wire
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	var b []byte
+	b = AppendUint(b, math.MaxUint32)
+	b = AppendInt(b, -42)
+	b = AppendBool(b, true)
+	b = AppendBytes(b, []byte{0, 255})
+	b = AppendString(b, "héllo")
+	b = append(b, 7)
+
+	r := NewReader(b)
+	if got := r.Uint32(); got != math.MaxUint32 {
+		t.Errorf("Uint32() = %d, want %d", got, uint32(math.MaxUint32))
+	}
+	if got := r.Int(); got != -42 {
+		t.Errorf("Int() = %d, want -42", got)
+	}
+	if got := r.Bool(); !got {
+		t.Errorf("Bool() = false, want true")
+	}
+	if got := r.Bytes(); len(got) != 2 || got[0] != 0 || got[1] != 255 {
+		t.Errorf("Bytes() = %v, want [0 255]", got)
+	}
+	if got := r.String(); got != "héllo" {
+		t.Errorf("String() = %q, want %q", got, "héllo")
+	}
+	if got := r.Byte(); got != 7 {
+		t.Errorf("Byte() = %d, want 7", got)
+	}
+	if err := r.Done(); err != nil {
+		t.Errorf("Done() = %v, want nil", err)
+	}
+}
+
+func TestReaderErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		read func(r *Reader)
+	}{
+		{"empty", nil, func(r *Reader) { r.Byte() }},
+		{"truncated uvarint", []byte{0x80}, func(r *Reader) { r.Uint() }},
+		{"uint32 overflow", AppendUint(nil, math.MaxUint32+1), func(r *Reader) { r.Uint32() }},
+		{"bad bool", []byte{2}, func(r *Reader) { r.Bool() }},
+		{"length past end", AppendUint(nil, 5), func(r *Reader) { r.Bytes() }},
+		{"trailing data", []byte{1, 2}, func(r *Reader) { r.Byte() }},
+	}
+	for _, tt := range tests {
+		r := NewReader(tt.data)
+		tt.read(r)
+		if err := r.Done(); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%s: Done() = %v, want ErrInvalid", tt.name, err)
+		}
+	}
+}
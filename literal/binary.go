@@ -0,0 +1,43 @@
+package literal
+
+import "github.com/coregx/coregex/internal/wire"
+
+// AppendBinary implements encoding.BinaryAppender. It appends the literals
+// of the sequence and whether it covers all alternation branches to b.
+//
+// Example:
+//
+//	seq := literal.NewSeq(literal.NewLiteral([]byte("foo"), true))
+//	data, _ := seq.AppendBinary(nil)
+//	var loaded literal.Seq
+//	_ = loaded.UnmarshalBinary(data) // loaded has the literal "foo"
+func (s *Seq) AppendBinary(b []byte) ([]byte, error) {
+	b = wire.AppendBool(b, s.partialCoverage)
+	b = wire.AppendUint(b, uint64(len(s.literals)))
+	for _, lit := range s.literals {
+		b = wire.AppendBytes(b, lit.Bytes)
+		b = wire.AppendBool(b, lit.Complete)
+	}
+	return b, nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (s *Seq) MarshalBinary() ([]byte, error) {
+	return s.AppendBinary(nil)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. It replaces s with
+// the sequence encoded by AppendBinary.
+func (s *Seq) UnmarshalBinary(data []byte) error {
+	r := wire.NewReader(data)
+	partial := r.Bool()
+	lits := make([]Literal, r.Len())
+	for i := range lits {
+		lits[i] = NewLiteral([]byte(r.String()), r.Bool())
+	}
+	if err := r.Done(); err != nil {
+		return err
+	}
+	*s = Seq{literals: lits, partialCoverage: partial}
+	return nil
+}
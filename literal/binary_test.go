@@ -0,0 +1,40 @@
+package literal
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSeqBinaryRoundTrip(t *testing.T) {
+	partial := NewSeq(NewLiteral([]byte("foo"), false))
+	partial.partialCoverage = true
+	tests := []*Seq{
+		NewSeq(),
+		NewSeq(NewLiteral([]byte("foo"), true), NewLiteral([]byte("bar"), false)),
+		NewSeq(NewLiteral(nil, true)),
+		partial,
+	}
+	for _, seq := range tests {
+		data, err := seq.MarshalBinary()
+		if err != nil {
+			t.Fatalf("%v: MarshalBinary: %v", seq, err)
+		}
+		var loaded Seq
+		if err := loaded.UnmarshalBinary(data); err != nil {
+			t.Fatalf("%v: UnmarshalBinary: %v", seq, err)
+		}
+		if loaded.Len() != seq.Len() || loaded.partialCoverage != seq.partialCoverage {
+			t.Fatalf("loaded %d literals (partial %v), want %d (partial %v)",
+				loaded.Len(), loaded.partialCoverage, seq.Len(), seq.partialCoverage)
+		}
+		for i, lit := range seq.literals {
+			got := loaded.literals[i]
+			if !bytes.Equal(got.Bytes, lit.Bytes) || got.Complete != lit.Complete {
+				t.Errorf("literal %d: loaded %v, want %v", i, got, lit)
+			}
+		}
+		if loaded.UnmarshalBinary(data[:len(data)-1]) == nil {
+			t.Errorf("%v: UnmarshalBinary accepted truncated data", seq)
+		}
+	}
+}
@@ -4,6 +4,7 @@ package literal
 
 import (
 	"regexp/syntax"
+	"slices"
 	"unicode"
 )
 
@@ -48,6 +49,10 @@ type ExtractorConfig struct {
 	// When exceeded, literals are truncated to 4 bytes (Teddy fingerprint size),
 	// deduplicated, and marked as inexact. Default: 250 (matching Rust regex-syntax).
 	CrossProductLimit int
+
+	// Bytes extracts literals for a byte-mode pattern: each rune (<= 0xFF)
+	// is one byte instead of its UTF-8 encoding.
+	Bytes bool
 }
 
 // DefaultConfig returns the default extractor configuration.
@@ -168,7 +173,7 @@ func (e *Extractor) extractPrefixes(re *syntax.Regexp, depth int) *Seq {
 			return e.expandCaseFoldLiteral(re.Rune)
 		}
 		// Direct literal: "hello" → ["hello"]
-		bytes := runeSliceToBytes(re.Rune)
+		bytes := e.runesToBytes(re.Rune)
 		if len(bytes) > e.config.MaxLiteralLen {
 			bytes = bytes[:e.config.MaxLiteralLen]
 		}
@@ -380,7 +385,7 @@ func (e *Extractor) concatSubContribution(sub *syntax.Regexp, depth int) *Seq {
 		if sub.Flags&syntax.FoldCase != 0 {
 			return e.expandCaseFoldLiteral(sub.Rune)
 		}
-		b := runeSliceToBytes(sub.Rune)
+		b := e.runesToBytes(sub.Rune)
 		return NewSeq(NewLiteral(b, true))
 
 	case syntax.OpCharClass:
@@ -588,7 +593,7 @@ func (e *Extractor) extractSuffixes(re *syntax.Regexp, depth int) *Seq {
 			return e.expandCaseFoldLiteral(re.Rune)
 		}
 		// Direct literal
-		bytes := runeSliceToBytes(re.Rune)
+		bytes := e.runesToBytes(re.Rune)
 		if len(bytes) > e.config.MaxLiteralLen {
 			// For suffix, take the LAST MaxLiteralLen bytes
 			bytes = bytes[len(bytes)-e.config.MaxLiteralLen:]
@@ -650,7 +655,7 @@ func (e *Extractor) extractSuffixes(re *syntax.Regexp, depth int) *Seq {
 			}
 
 			// Prepend this literal to all suffixes (cross_reverse)
-			prefix := runeSliceToBytes(sub.Rune)
+			prefix := e.runesToBytes(sub.Rune)
 			lits := make([]Literal, suffixes.Len())
 			for j := 0; j < suffixes.Len(); j++ {
 				lit := suffixes.Get(j)
@@ -758,7 +763,7 @@ func (e *Extractor) extractInner(re *syntax.Regexp, depth int) *Seq {
 			}
 			return seq
 		}
-		bytes := runeSliceToBytes(re.Rune)
+		bytes := e.runesToBytes(re.Rune)
 		if len(bytes) > e.config.MaxLiteralLen {
 			bytes = bytes[:e.config.MaxLiteralLen]
 		}
@@ -852,6 +857,10 @@ func (e *Extractor) expandCaseFoldLiteral(runes []rune) *Seq {
 	filledCount := 0
 	for i, r := range runes {
 		folds := caseFolds(r)
+		if e.config.Bytes {
+			// Folds outside 0x00-0xFF are not bytes (e.g. K for k).
+			folds = slices.DeleteFunc(folds, func(f rune) bool { return f > 0xFF })
+		}
 		foldSets[i] = folds
 		filledCount = i + 1
 		totalProduct *= len(folds)
@@ -907,7 +916,7 @@ func (e *Extractor) generateCaseFoldVariants(foldSets [][]rune, prefixLen int) *
 
 	lits := make([]Literal, 0, len(variants))
 	for _, v := range variants {
-		b := runeSliceToBytes(v)
+		b := e.runesToBytes(v)
 		if len(b) > e.config.MaxLiteralLen {
 			b = b[:e.config.MaxLiteralLen]
 		}
@@ -982,7 +991,7 @@ func (e *Extractor) expandCharClass(re *syntax.Regexp) *Seq {
 	for i := 0; i < len(re.Rune); i += 2 {
 		lo, hi := re.Rune[i], re.Rune[i+1]
 		for r := lo; r <= hi; r++ {
-			bytes := []byte(string(r))
+			bytes := e.runesToBytes([]rune{r})
 			// Truncate if exceeds MaxLiteralLen
 			if len(bytes) > e.config.MaxLiteralLen {
 				bytes = bytes[:e.config.MaxLiteralLen]
@@ -1242,6 +1251,19 @@ func isWildcardOrRepetition(re *syntax.Regexp) bool {
 
 // Helper functions
 
+// runesToBytes converts pattern runes to the bytes they match: their UTF-8
+// encoding, or one byte per rune in byte mode.
+func (e *Extractor) runesToBytes(runes []rune) []byte {
+	if !e.config.Bytes {
+		return runeSliceToBytes(runes)
+	}
+	b := make([]byte, len(runes))
+	for i, r := range runes {
+		b[i] = byte(r)
+	}
+	return b
+}
+
 // runeSliceToBytes converts []rune to []byte using UTF-8 encoding.
 func runeSliceToBytes(runes []rune) []byte {
 	return []byte(string(runes))
@@ -0,0 +1,180 @@
+package coregex
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coregx/coregex/meta"
+	"github.com/coregx/coregex/nfa"
+)
+
+func TestLookaround(t *testing.T) {
+	tests := []struct {
+		pattern string
+		input   string
+		want    [][]int
+	}{
+		{`foo(?=bar)`, "foobar foobaz foobar", [][]int{{0, 3}, {14, 17}}},
+		{`foo(?!bar)`, "foobar foobaz foo", [][]int{{7, 10}, {14, 17}}},
+		{`(?<=\$)\d+`, "$10, 20, $30", [][]int{{1, 3}, {10, 12}}},
+		{`(?<!\$)\b\d+`, "$10, 20, $30", [][]int{{5, 7}}},
+		{`\b\w+(?=ing\b)`, "sing singer bring", [][]int{{0, 1}, {12, 14}}},
+		{`(?<=[A-Z])[a-z]+`, "Hello world Go", [][]int{{1, 5}, {13, 14}}},
+		{`(?=a)`, "banana", [][]int{{1, 1}, {3, 3}, {5, 5}}},
+		{`(?m)(?<=^- )\w+`, "- one\n- two\nthree", [][]int{{2, 5}, {8, 11}}},
+		{`(?i)(?<=id=)\d+`, "ID=7 id=42", [][]int{{3, 4}, {8, 10}}},
+		{`x(?=y)`, strings.Repeat("x", 100), nil},
+	}
+
+	dfaOff := DefaultConfig()
+	dfaOff.EnableDFA = false
+	for _, tt := range tests {
+		for _, re := range []*Regex{MustCompile(tt.pattern), mustCompileWithConfig(t, tt.pattern, dfaOff)} {
+			if got := re.FindAllStringIndex(tt.input, -1); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%q FindAllStringIndex(%q) = %v, want %v", tt.pattern, tt.input, got, tt.want)
+			}
+			if got := re.MatchString(tt.input); got != (tt.want != nil) {
+				t.Errorf("%q MatchString(%q) = %v", tt.pattern, tt.input, got)
+			}
+			if got := re.MatchReader(strings.NewReader(tt.input)); got != (tt.want != nil) {
+				t.Errorf("%q MatchReader(%q) = %v", tt.pattern, tt.input, got)
+			}
+		}
+	}
+}
+
+func mustCompileWithConfig(t *testing.T, pattern string, config meta.Config) *Regex {
+	t.Helper()
+	re, err := CompileWithConfig(pattern, config)
+	if err != nil {
+		t.Fatalf("CompileWithConfig(%q): %v", pattern, err)
+	}
+	return re
+}
+
+func TestLookaroundCaptures(t *testing.T) {
+	re := MustCompile(`(?P<key>\w+)(?==)(?:=(?P<val>\w+))`)
+	if got, want := re.SubexpNames(), []string{"", "key", "val"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("SubexpNames() = %q, want %q", got, want)
+	}
+	if got, want := re.FindStringSubmatch("a b=c"), []string{"b=c", "b", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FindStringSubmatch = %q, want %q", got, want)
+	}
+	if got, want := MustCompile(`(?<=\d)(?=(?:\d{3})+\b)`).ReplaceAllString("1234567", ","), "1,234,567"; got != want {
+		t.Errorf("ReplaceAllString = %q, want %q", got, want)
+	}
+}
+
+// TestLookaroundEmptyAndAnchoredFind checks that Find, FindAll and Count see
+// the look-around matches that FindSubmatchIndex finds: empty matches and
+// anchored matches whose look-ahead reads past the match end.
+func TestLookaroundEmptyAndAnchoredFind(t *testing.T) {
+	tests := []struct {
+		pattern string
+		input   string
+		want    [][]int
+	}{
+		{`^c(?=a)`, "cab", [][]int{{0, 1}}},
+		{`^c(?!b)`, "cab", [][]int{{0, 1}}},
+		{`(?=a)`, "ab", [][]int{{0, 0}}},
+		{`(?!x)`, "ab", [][]int{{0, 0}, {1, 1}, {2, 2}}},
+		{`a*(?!x)`, "cab", [][]int{{0, 0}, {1, 2}, {3, 3}}},
+	}
+
+	dfaOff := DefaultConfig()
+	dfaOff.EnableDFA = false
+	for _, tt := range tests {
+		for _, re := range []*Regex{MustCompile(tt.pattern), mustCompileWithConfig(t, tt.pattern, dfaOff)} {
+			first := re.FindStringSubmatchIndex(tt.input)
+			if !reflect.DeepEqual(first, tt.want[0]) {
+				t.Errorf("%q FindStringSubmatchIndex(%q) = %v, want %v", tt.pattern, tt.input, first, tt.want[0])
+			}
+			if got := re.FindStringIndex(tt.input); !reflect.DeepEqual(got, first) {
+				t.Errorf("%q FindStringIndex(%q) = %v, want %v", tt.pattern, tt.input, got, first)
+			}
+			if got := re.FindAllStringIndex(tt.input, -1); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%q FindAllStringIndex(%q) = %v, want %v", tt.pattern, tt.input, got, tt.want)
+			}
+			if got := re.FindAllStringSubmatchIndex(tt.input, -1); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%q FindAllStringSubmatchIndex(%q) = %v, want %v", tt.pattern, tt.input, got, tt.want)
+			}
+			if got := re.Count([]byte(tt.input), -1); got != len(tt.want) {
+				t.Errorf("%q Count(%q) = %d, want %d", tt.pattern, tt.input, got, len(tt.want))
+			}
+		}
+	}
+}
+
+func TestLookaroundLinear(t *testing.T) {
+	tests := []struct {
+		pattern string
+		input   func(n int) string
+		matches func(n int) int
+	}{
+		// Every position needs the look-ahead to scan to the final digit;
+		// the results are kept across the searches of FindAll, ReplaceAll
+		// and Split.
+		{`(?=.*\d)\w`, func(n int) string { return strings.Repeat("a", n) + "1" }, func(n int) int { return n + 1 }},
+		// Every position needs the look-behind to scan 50 bytes back.
+		{`(?<=a{0,50})b`, func(n int) string { return strings.Repeat("a", n) + "b" }, func(int) int { return 1 }},
+		{`x(?=\w{0,50}y)`, func(n int) string { return strings.Repeat("x", n) }, func(int) int { return 0 }},
+	}
+	for _, tt := range tests {
+		re := MustCompile(tt.pattern)
+		elapsed := func(n int) time.Duration {
+			input := tt.input(n)
+			best := time.Duration(1 << 62)
+			for range 3 {
+				start := time.Now()
+				if got := len(re.FindAllStringIndex(input, -1)); got != tt.matches(n) {
+					t.Fatalf("%q FindAllStringIndex found %d matches, want %d", tt.pattern, got, tt.matches(n))
+				}
+				re.MatchString(input)
+				re.ReplaceAllString(input, "")
+				re.ReplaceAllString(input, "$0")
+				re.Split(input, -1)
+				best = min(best, time.Since(start))
+			}
+			return best
+		}
+		small, large := elapsed(4<<10), elapsed(16<<10)
+		// Linear time grows 4x; quadratic 16x.
+		if large > 8*small+5*time.Millisecond {
+			t.Errorf("%q: %v for 4 KB, %v for 16 KB: not linear", tt.pattern, small, large)
+		}
+	}
+
+	re := MustCompile(`(?=.*\d)\w`)
+	// The results of one haystack are not used for the next, even if it is
+	// the same slice with other bytes.
+	buf := []byte("ab1")
+	if got := re.FindAllIndex(buf, -1); len(got) != 3 {
+		t.Fatalf("FindAllIndex(%q) = %v, want 3 matches", buf, got)
+	}
+	buf[2] = 'c'
+	if got := re.FindAllIndex(buf, -1); got != nil {
+		t.Errorf("FindAllIndex(%q) = %v, want none", buf, got)
+	}
+}
+
+func TestLookaroundErrors(t *testing.T) {
+	for _, pattern := range []string{`(?<=a+)b`, `(?=(a))`} {
+		_, err := Compile(pattern)
+		if !errors.Is(err, nfa.ErrUnsupportedLookaround) {
+			t.Errorf("Compile(%q) = %v, want ErrUnsupportedLookaround", pattern, err)
+		}
+	}
+	if _, err := CompileSet([]string{`a`, `b(?=c)`}); !errors.Is(err, nfa.ErrUnsupportedLookaround) {
+		t.Errorf("CompileSet with look-around = %v, want ErrUnsupportedLookaround", err)
+	}
+}
+
+func TestLookaroundLiteralPrefix(t *testing.T) {
+	prefix, complete := MustCompile(`foo(?=bar)`).LiteralPrefix()
+	if prefix != "foo" || complete {
+		t.Errorf("LiteralPrefix() = (%q, %v), want (\"foo\", false)", prefix, complete)
+	}
+}
@@ -279,7 +279,7 @@ func TestAhoCorasickLargePatternSet(t *testing.T) {
 func TestAhoCorasickStats(t *testing.T) {
 	pattern := `stat1|stat2|stat3|stat4|stat5|stat6|stat7|stat8|stat9`
 
-	re, err := Compile(pattern)
+	re, err := CompileWithConfig(pattern, statsConfig())
 	if err != nil {
 		t.Fatalf("Compile(%q) failed: %v", pattern, err)
 	}
@@ -0,0 +1,438 @@
+// Package meta implements the meta-engine orchestrator.
+//
+// binary.go contains the binary engine format: Engine.AppendBinary and
+// LoadEngine.
+
+package meta
+
+import (
+	"errors"
+	"fmt"
+	"regexp/syntax"
+	"slices"
+	"unicode"
+
+	"github.com/coregx/coregex/dfa/dense"
+	"github.com/coregx/coregex/dfa/onepass"
+	"github.com/coregx/coregex/internal/wire"
+	"github.com/coregx/coregex/literal"
+	"github.com/coregx/coregex/nfa"
+)
+
+// Version is the coregex release this package belongs to. Engines saved by
+// Engine.AppendBinary record it, and LoadEngine rejects engines saved by
+// another release, because the compiled automata and strategies change
+// between releases.
+const Version = "0.13.0-dev"
+
+// binaryMagic starts every saved engine.
+const binaryMagic = "coregex\x00"
+
+// binaryFormat is the version of the layout written by AppendBinary. It is
+// incremented when the layout changes between releases.
+const binaryFormat = 1
+
+// maxRegexpDepth bounds the nesting of a loaded syntax tree.
+const maxRegexpDepth = 10_000
+
+var (
+	// ErrVersionMismatch is returned by LoadEngine for data saved by another
+	// coregex version.
+	ErrVersionMismatch = errors.New("regexp: engine saved by a different coregex version")
+
+	// ErrInvalidEngineData is returned by LoadEngine for data that is not a
+	// saved engine or is corrupt.
+	ErrInvalidEngineData = errors.New("regexp: invalid engine data")
+)
+
+// AppendBinary implements encoding.BinaryAppender. It appends the compiled
+// engine to b: the configuration, the simplified syntax tree and the pattern
+// string, the NFAs, the OnePass and dense DFA tables, the selected strategy
+// and the extracted literals, from which the prefilter is rebuilt.
+// LoadEngine turns the result back into an Engine without parsing, literal
+// extraction or strategy selection. Config.Observer is not saved; pass it
+// to LoadEngineWithObserver.
+//
+// The data starts with the format version and the coregex Version; it can
+// only be loaded by the same release.
+//
+// Example:
+//
+//	data, err := engine.AppendBinary(nil)
+//	...
+//	engine, err = meta.LoadEngine(data)
+func (e *Engine) AppendBinary(b []byte) ([]byte, error) {
+	p := e.plan
+	if p == nil {
+		return nil, errors.New("regexp: engine was not compiled")
+	}
+	b = append(b, binaryMagic...)
+	b = wire.AppendUint(b, binaryFormat)
+	b = wire.AppendString(b, Version)
+
+	b = appendConfig(b, p.config)
+	b = appendRegexp(b, p.re)
+	b = wire.AppendString(b, p.pattern)
+	var err error
+	for _, n := range []*nfa.NFA{p.nfa, p.runeNFA, p.asciiNFA} {
+		if b, err = appendNFA(b, n); err != nil {
+			return nil, err
+		}
+	}
+	b = wire.AppendBool(b, p.onepass != nil)
+	if p.onepass != nil {
+		dfa, err := p.onepass.AppendBinary(nil)
+		if err != nil {
+			return nil, err
+		}
+		b = wire.AppendBytes(b, dfa)
+	}
+	b = wire.AppendInt(b, int(p.strategy))
+	for _, d := range []*dense.DFA{p.dense, p.denseReverse} {
+		b = wire.AppendBool(b, d != nil)
+		if d != nil {
+			data, _ := d.AppendBinary(nil)
+			b = wire.AppendBytes(b, data)
+		}
+	}
+
+	b = appendSeq(b, p.prefixes)
+	b = wire.AppendBool(b, p.hasSuffixes)
+	b = appendSeq(b, p.suffixes)
+	b = wire.AppendBool(b, p.hasLinePref)
+	b = appendSeq(b, p.linePrefixes)
+	b = wire.AppendBool(b, p.hasInner)
+	b = wire.AppendBool(b, p.inner != nil)
+	if p.inner != nil {
+		b = appendSeq(b, p.inner.Literals)
+		b = wire.AppendInt(b, p.inner.InnerIdx)
+		b = appendRegexp(b, p.inner.PrefixAST)
+		b = appendRegexp(b, p.inner.SuffixAST)
+	}
+	return b, nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (e *Engine) MarshalBinary() ([]byte, error) {
+	return e.AppendBinary(nil)
+}
+
+// LoadEngine returns the engine saved by Engine.AppendBinary.
+//
+// It returns an error matching ErrVersionMismatch if data was saved by
+// another coregex version, and one matching ErrInvalidEngineData if data is
+// not a saved engine.
+func LoadEngine(data []byte) (*Engine, error) {
+	return LoadEngineWithObserver(data, nil)
+}
+
+// LoadEngineWithObserver is like LoadEngine but sets the Config.Observer of
+// the engine, which Engine.AppendBinary does not save, to observer.
+func LoadEngineWithObserver(data []byte, observer Observer) (*Engine, error) {
+	if len(data) < len(binaryMagic) || string(data[:len(binaryMagic)]) != binaryMagic {
+		return nil, fmt.Errorf("%w: missing header", ErrInvalidEngineData)
+	}
+	r := wire.NewReader(data[len(binaryMagic):])
+	format, version := r.Uint(), r.String()
+	if err := r.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEngineData, err)
+	}
+	if format != binaryFormat || version != Version {
+		return nil, fmt.Errorf("%w: saved by %s (format %d), this is %s (format %d)",
+			ErrVersionMismatch, version, format, Version, binaryFormat)
+	}
+
+	p := &compilePlan{config: readConfig(r)}
+	p.re = readRegexp(r, 0)
+	p.pattern = r.String()
+	p.nfa = readNFA(r)
+	p.runeNFA = readNFA(r)
+	p.asciiNFA = readNFA(r)
+	if r.Bool() {
+		p.onepass = new(onepass.DFA)
+		if err := p.onepass.UnmarshalBinary(r.Bytes()); err != nil {
+			r.Fail(err)
+		}
+	}
+	p.strategy = Strategy(r.Int())
+	p.dense = readDense(r)
+	p.denseReverse = readDense(r)
+
+	p.prefixes = readSeq(r)
+	p.hasSuffixes = r.Bool()
+	p.suffixes = readSeq(r)
+	p.hasLinePref = r.Bool()
+	p.linePrefixes = readSeq(r)
+	p.hasInner = r.Bool()
+	if r.Bool() {
+		p.inner = &literal.InnerLiteralInfo{Literals: readSeq(r), InnerIdx: r.Int()}
+		p.inner.PrefixAST = readRegexp(r, 0)
+		p.inner.SuffixAST = readRegexp(r, 0)
+	}
+	if err := r.Done(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEngineData, err)
+	}
+
+	if p.re == nil || p.nfa == nil || p.strategy < UseNFA || p.strategy > UseDenseDFA {
+		return nil, fmt.Errorf("%w: missing pattern, NFA or strategy", ErrInvalidEngineData)
+	}
+	if err := p.config.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEngineData, err)
+	}
+	// The search state sizes the capture slots of every engine from p.nfa.
+	captures := p.nfa.CaptureCount()
+	if p.runeNFA != nil && p.runeNFA.CaptureCount() != captures ||
+		p.asciiNFA != nil && p.asciiNFA.CaptureCount() != captures ||
+		p.onepass != nil && p.onepass.NumCaptures() != captures {
+		return nil, fmt.Errorf("%w: engines disagree on the capture groups", ErrInvalidEngineData)
+	}
+	if p.strategy == UseDenseDFA && (p.dense == nil || p.denseReverse == nil && !p.nfa.IsAlwaysAnchored()) {
+		return nil, fmt.Errorf("%w: missing dense DFA", ErrInvalidEngineData)
+	}
+	p.config.Observer = observer
+	if p.nfa.HasLookaround() {
+		return compileLookaround(p), nil
+	}
+	return p.engine(), nil
+}
+
+// appendNFA appends whether n is set and, if so, n.
+func appendNFA(b []byte, n *nfa.NFA) ([]byte, error) {
+	b = wire.AppendBool(b, n != nil)
+	if n == nil {
+		return b, nil
+	}
+	data, err := n.AppendBinary(nil)
+	if err != nil {
+		return nil, err
+	}
+	return wire.AppendBytes(b, data), nil
+}
+
+// readNFA reads a value written by appendNFA.
+func readNFA(r *wire.Reader) *nfa.NFA {
+	if !r.Bool() {
+		return nil
+	}
+	n := new(nfa.NFA)
+	if err := n.UnmarshalBinary(r.Bytes()); err != nil {
+		r.Fail(err)
+	}
+	return n
+}
+
+// readDense reads a dense DFA written by AppendBinary, or nil.
+func readDense(r *wire.Reader) *dense.DFA {
+	if !r.Bool() {
+		return nil
+	}
+	d := new(dense.DFA)
+	if err := d.UnmarshalBinary(r.Bytes()); err != nil {
+		r.Fail(err)
+	}
+	return d
+}
+
+// appendSeq appends whether seq is set and, if so, seq.
+func appendSeq(b []byte, seq *literal.Seq) []byte {
+	b = wire.AppendBool(b, seq != nil)
+	if seq == nil {
+		return b
+	}
+	data, _ := seq.AppendBinary(nil)
+	return wire.AppendBytes(b, data)
+}
+
+// readSeq reads a value written by appendSeq.
+func readSeq(r *wire.Reader) *literal.Seq {
+	if !r.Bool() {
+		return nil
+	}
+	seq := new(literal.Seq)
+	if err := seq.UnmarshalBinary(r.Bytes()); err != nil {
+		r.Fail(err)
+	}
+	return seq
+}
+
+// appendRegexp appends the syntax tree re, which may be nil.
+func appendRegexp(b []byte, re *syntax.Regexp) []byte {
+	if re == nil {
+		return append(b, 0)
+	}
+	b = append(b, byte(re.Op))
+	b = wire.AppendUint(b, uint64(re.Flags))
+	b = wire.AppendUint(b, uint64(len(re.Rune)))
+	for _, r := range re.Rune {
+		b = wire.AppendInt(b, int(r))
+	}
+	b = wire.AppendInt(b, re.Min)
+	b = wire.AppendInt(b, re.Max)
+	b = wire.AppendInt(b, re.Cap)
+	b = wire.AppendString(b, re.Name)
+	b = wire.AppendUint(b, uint64(len(re.Sub)))
+	for _, sub := range re.Sub {
+		b = appendRegexp(b, sub)
+	}
+	return b
+}
+
+// readRegexp reads a syntax tree written by appendRegexp.
+func readRegexp(r *wire.Reader, depth int) *syntax.Regexp {
+	op := syntax.Op(r.Byte())
+	if op == 0 || r.Err() != nil {
+		return nil
+	}
+	if op > syntax.OpAlternate || depth > maxRegexpDepth {
+		r.Fail(wire.ErrInvalid)
+		return nil
+	}
+	re := &syntax.Regexp{Op: op, Flags: syntax.Flags(r.Uint())}
+	if n := r.Len(); n > 0 {
+		re.Rune = make([]rune, n)
+		for i := range re.Rune {
+			re.Rune[i] = rune(r.Int())
+		}
+	}
+	re.Min, re.Max, re.Cap = r.Int(), r.Int(), r.Int()
+	re.Name = r.String()
+	if n := r.Len(); n > 0 {
+		re.Sub = make([]*syntax.Regexp, n)
+		for i := range re.Sub {
+			if re.Sub[i] = readRegexp(r, depth+1); re.Sub[i] == nil {
+				r.Fail(wire.ErrInvalid)
+				return nil
+			}
+		}
+	}
+	if !regexpIsValid(re) {
+		r.Fail(wire.ErrInvalid)
+		return nil
+	}
+	return re
+}
+
+// regexpIsValid reports whether the node re has the shape regexp/syntax
+// gives its op, or is a look-around node, so that the code that walks the
+// tree can index it safely.
+func regexpIsValid(re *syntax.Regexp) bool {
+	for _, r := range re.Rune {
+		if r < 0 || r > unicode.MaxRune {
+			return false
+		}
+	}
+	switch re.Op {
+	case syntax.OpLiteral:
+		return len(re.Rune) > 0 && len(re.Sub) == 0
+	case syntax.OpCharClass:
+		for i := 1; i < len(re.Rune); i += 2 {
+			if re.Rune[i-1] > re.Rune[i] {
+				return false
+			}
+		}
+		return len(re.Rune)%2 == 0 && len(re.Sub) == 0
+	case syntax.OpCapture:
+		return re.Cap > 0 && len(re.Sub) == 1
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest:
+		return len(re.Sub) == 1
+	case syntax.OpRepeat:
+		return len(re.Sub) == 1 && re.Min >= 0 && re.Min <= 1000 &&
+			re.Max <= 1000 && (re.Max == -1 || re.Min <= re.Max)
+	case syntax.OpConcat, syntax.OpAlternate:
+		return true
+	default:
+		return len(re.Rune) == 0 && (len(re.Sub) == 0 || nfa.IsLookaround(re))
+	}
+}
+
+// appendConfig appends every field of config.
+func appendConfig(b []byte, config Config) []byte {
+	b = wire.AppendBool(b, config.EnableDFA)
+	b = wire.AppendBool(b, config.EnablePrefilter)
+	b = wire.AppendUint(b, uint64(config.MaxDFAStates))
+	b = wire.AppendInt(b, config.DeterminizationLimit)
+	b = wire.AppendInt(b, config.MinLiteralLen)
+	b = wire.AppendInt(b, config.MaxLiterals)
+	b = wire.AppendInt(b, config.MaxRecursionDepth)
+	b = wire.AppendBool(b, config.EnableASCIIOptimization)
+	b = wire.AppendBool(b, config.Bytes)
+	b = wire.AppendUint(b, uint64(config.SyntaxFlags))
+	b = wire.AppendBool(b, config.Verbose)
+	b = wire.AppendBool(b, config.UnicodeWordBoundary)
+	b = wire.AppendBool(b, config.ExtendedSyntax)
+	b = wire.AppendInt(b, config.DenseDFAMaxStates)
+	b = wire.AppendBool(b, config.EnableStats)
+
+	// Classes are only needed to parse the pattern again, as
+	// Regex.LiteralPrefix does. They are written in name order so that the
+	// same engine always gives the same bytes.
+	names := make([]string, 0, len(config.Classes))
+	for name := range config.Classes {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	b = wire.AppendUint(b, uint64(len(names)))
+	for _, name := range names {
+		table := config.Classes[name]
+		b = wire.AppendString(b, name)
+		b = wire.AppendUint(b, uint64(len(table.R16)))
+		for _, r := range table.R16 {
+			b = wire.AppendUint(b, uint64(r.Lo))
+			b = wire.AppendUint(b, uint64(r.Hi))
+			b = wire.AppendUint(b, uint64(r.Stride))
+		}
+		b = wire.AppendUint(b, uint64(len(table.R32)))
+		for _, r := range table.R32 {
+			b = wire.AppendUint(b, uint64(r.Lo))
+			b = wire.AppendUint(b, uint64(r.Hi))
+			b = wire.AppendUint(b, uint64(r.Stride))
+		}
+		b = wire.AppendInt(b, table.LatinOffset)
+	}
+	return b
+}
+
+// readConfig reads a configuration written by appendConfig.
+func readConfig(r *wire.Reader) Config {
+	config := Config{
+		EnableDFA:               r.Bool(),
+		EnablePrefilter:         r.Bool(),
+		MaxDFAStates:            r.Uint32(),
+		DeterminizationLimit:    r.Int(),
+		MinLiteralLen:           r.Int(),
+		MaxLiterals:             r.Int(),
+		MaxRecursionDepth:       r.Int(),
+		EnableASCIIOptimization: r.Bool(),
+		Bytes:                   r.Bool(),
+		SyntaxFlags:             syntax.Flags(r.Uint()),
+		Verbose:                 r.Bool(),
+		UnicodeWordBoundary:     r.Bool(),
+		ExtendedSyntax:          r.Bool(),
+		DenseDFAMaxStates:       r.Int(),
+		EnableStats:             r.Bool(),
+	}
+	n := r.Len()
+	if n == 0 {
+		return config
+	}
+	config.Classes = make(map[string]*unicode.RangeTable, n)
+	for range n {
+		name := r.String()
+		table := new(unicode.RangeTable)
+		if n := r.Len(); n > 0 {
+			table.R16 = make([]unicode.Range16, n)
+			for i := range table.R16 {
+				table.R16[i] = unicode.Range16{Lo: uint16(r.Uint()), Hi: uint16(r.Uint()), Stride: uint16(r.Uint())}
+			}
+		}
+		if n := r.Len(); n > 0 {
+			table.R32 = make([]unicode.Range32, n)
+			for i := range table.R32 {
+				table.R32[i] = unicode.Range32{Lo: r.Uint32(), Hi: r.Uint32(), Stride: r.Uint32()}
+			}
+		}
+		table.LatinOffset = r.Int()
+		config.Classes[name] = table
+	}
+	return config
+}
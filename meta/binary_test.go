@@ -0,0 +1,379 @@
+package meta
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"reflect"
+	"regexp/syntax"
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/coregx/coregex/internal/wire"
+)
+
+// submatches returns the capture spans of every match of engine in input.
+func submatches(engine *Engine, input []byte) [][]int {
+	var got [][]int
+	for _, m := range engine.FindAllSubmatch(input, -1) {
+		var loc []int
+		for g := range m.NumCaptures() {
+			if span := m.GroupIndex(g); span != nil {
+				loc = append(loc, span...)
+			} else {
+				loc = append(loc, -1, -1)
+			}
+		}
+		got = append(got, loc)
+	}
+	return got
+}
+
+func TestEngineBinaryRoundTrip(t *testing.T) {
+	words := make([]string, 80)
+	for i := range words {
+		words[i] = fmt.Sprintf("word%02dx", i)
+	}
+	foldCase := DefaultConfig()
+	foldCase.SyntaxFlags |= syntax.FoldCase
+	byteMode := DefaultConfig()
+	byteMode.Bytes = true
+	unicodeWords := DefaultConfig()
+	unicodeWords.UnicodeWordBoundary = true
+	extended := DefaultConfig()
+	extended.ExtendedSyntax = true
+	extended.Classes = map[string]*unicode.RangeTable{"Hex": unicode.ASCII_Hex_Digit}
+	noDFA := DefaultConfig()
+	noDFA.EnableDFA = false
+
+	tests := []struct {
+		pattern string
+		config  Config
+	}{
+		{`a`, DefaultConfig()},
+		{`.*\.txt`, DefaultConfig()},
+		{`.*\.(txt|log|csv)`, DefaultConfig()},
+		{`.*ERROR.*`, DefaultConfig()},
+		{`hello$`, DefaultConfig()},
+		{`\w+`, DefaultConfig()},
+		{`(\w)+`, DefaultConfig()},
+		{`[a-zA-Z]+[0-9]+`, DefaultConfig()},
+		{`\d+\.\d+\.\d+`, DefaultConfig()},
+		{`foo|bar|baz`, DefaultConfig()},
+		{strings.Join(words, "|"), DefaultConfig()},
+		{`^/.*\.php$`, DefaultConfig()},
+		{`(?m)^/.*\.php`, DefaultConfig()},
+		{`^(\d+|UUID)`, DefaultConfig()},
+		{`^(\d+)-(?P<b>\d+)$`, DefaultConfig()},
+		{useBothPattern(), DefaultConfig()},
+		{`(\w+)@(\w+)\.com`, DefaultConfig()},
+		{`(a|ab)(c|bcd)`, DefaultConfig()},
+		{`foo(?=bar)|(?<!x)baz`, DefaultConfig()},
+		{`get /\S+`, foldCase},
+		{`\xff.`, byteMode},
+		{`\bпривет\b`, unicodeWords},
+		{`[\p{Hex}--\d]+\h\R?`, extended},
+		{`a(b|c)*d`, noDFA},
+		{`[a-z]+\d+`, statsConfig()},
+	}
+	inputs := [][]byte{
+		nil,
+		[]byte("hello world 1.2.3 x@y.com /index.php\n/a.php foo barbaz xbaz"),
+		[]byte("a.txt b.log ERROR: c.csv 123-456 UUID9 word42x word07xy GET /Index"),
+		[]byte("abcdefghz aaz привет мир \xff\xfe\xffa cafe \t\r\n abccbd"),
+	}
+	for _, tt := range tests {
+		original, err := CompileWithConfig(tt.pattern, tt.config)
+		if err != nil {
+			t.Fatalf("CompileWithConfig(%q): %v", tt.pattern, err)
+		}
+		data, err := original.AppendBinary([]byte("prefix"))
+		if err != nil {
+			t.Fatalf("%q: AppendBinary: %v", tt.pattern, err)
+		}
+		if !bytes.HasPrefix(data, []byte("prefix")) {
+			t.Fatalf("%q: AppendBinary dropped the prefix", tt.pattern)
+		}
+		data = data[len("prefix"):]
+		loaded, err := LoadEngine(data)
+		if err != nil {
+			t.Fatalf("%q: LoadEngine: %v", tt.pattern, err)
+		}
+
+		if loaded.Strategy() != original.Strategy() {
+			t.Errorf("%q: loaded strategy %s, want %s", tt.pattern, loaded.Strategy(), original.Strategy())
+		}
+		if !reflect.DeepEqual(loaded.Config(), original.Config()) {
+			t.Errorf("%q: loaded config %+v, want %+v", tt.pattern, loaded.Config(), original.Config())
+		}
+		if got, want := loaded.SubexpNames(), original.SubexpNames(); !reflect.DeepEqual(got, want) {
+			t.Errorf("%q: SubexpNames = %q, want %q", tt.pattern, got, want)
+		}
+		again, err := loaded.MarshalBinary()
+		if err != nil || !bytes.Equal(again, data) {
+			t.Errorf("%q: saving the loaded engine gave different data (err %v)", tt.pattern, err)
+		}
+		for _, in := range inputs {
+			if got, want := submatches(loaded, in), submatches(original, in); !reflect.DeepEqual(got, want) {
+				t.Errorf("%q on %q: loaded %v, want %v", tt.pattern, in, got, want)
+			}
+			if got, want := loaded.IsMatch(in), original.IsMatch(in); got != want {
+				t.Errorf("%q on %q: loaded IsMatch %v, want %v", tt.pattern, in, got, want)
+			}
+		}
+	}
+}
+
+func TestLoadEngineErrors(t *testing.T) {
+	engine, err := Compile(`(\w+)@(\w+)\.com`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := engine.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every truncation and many single-byte changes must fail cleanly.
+	for n := range len(data) {
+		if _, err := LoadEngine(data[:n]); !errors.Is(err, ErrInvalidEngineData) && !errors.Is(err, ErrVersionMismatch) {
+			t.Fatalf("LoadEngine(data[:%d]) error = %v", n, err)
+		}
+	}
+	for i := range data {
+		corrupt := bytes.Clone(data)
+		corrupt[i] ^= 0xff
+		if e, err := LoadEngine(corrupt); err == nil {
+			e.FindAllSubmatch([]byte("a@b.com x@y.org"), -1)
+		}
+	}
+
+	if _, err := LoadEngine(append(bytes.Clone(data), 0)); !errors.Is(err, ErrInvalidEngineData) {
+		t.Errorf("LoadEngine with trailing data: error = %v, want ErrInvalidEngineData", err)
+	}
+
+	// Parts that decode on their own but do not fit together.
+	groups, err := Compile(`(a)(b)(c)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		mutate func(p *compilePlan)
+	}{
+		{"class with an odd number of runes", func(p *compilePlan) {
+			p.re = &syntax.Regexp{Op: syntax.OpCharClass, Rune: []rune{'a', 'z', 'A'}}
+		}},
+		{"capture without a group", func(p *compilePlan) {
+			p.re = &syntax.Regexp{Op: syntax.OpCapture, Cap: 1}
+		}},
+		{"rune NFA with other groups", func(p *compilePlan) { p.runeNFA = groups.plan.nfa }},
+		{"OnePass DFA with other groups", func(p *compilePlan) { p.onepass = groups.plan.onepass }},
+	}
+	for _, tt := range tests {
+		p := *engine.plan
+		tt.mutate(&p)
+		corrupt, err := (&Engine{plan: &p}).MarshalBinary()
+		if err != nil {
+			t.Fatalf("%s: MarshalBinary: %v", tt.name, err)
+		}
+		if _, err := LoadEngine(corrupt); !errors.Is(err, ErrInvalidEngineData) {
+			t.Errorf("LoadEngine with %s: error = %v, want ErrInvalidEngineData", tt.name, err)
+		}
+	}
+}
+
+func TestLoadEngineHeader(t *testing.T) {
+	engine, err := Compile(`(\w+)@(\w+)\.com`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := engine.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := wire.AppendString(wire.AppendUint([]byte(binaryMagic), binaryFormat), Version)
+	if !bytes.HasPrefix(data, header) {
+		t.Fatal("saved engine does not start with the header")
+	}
+	body := data[len(header):]
+	withHeader := func(magic string, format uint64, version string) []byte {
+		b := wire.AppendString(wire.AppendUint([]byte(magic), format), version)
+		return append(b, body...)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"empty", nil, ErrInvalidEngineData},
+		{"pattern text", []byte(`(\w+)@(\w+)\.com`), ErrInvalidEngineData},
+		{"other magic", withHeader("coregey\x00", binaryFormat, Version), ErrInvalidEngineData},
+		{"truncated magic", []byte(binaryMagic[:4]), ErrInvalidEngineData},
+		{"magic only", []byte(binaryMagic), ErrInvalidEngineData},
+		{"truncated version", header[:len(header)-1], ErrInvalidEngineData},
+		{"previous format", withHeader(binaryMagic, binaryFormat-1, Version), ErrVersionMismatch},
+		{"next format", withHeader(binaryMagic, binaryFormat+1, Version), ErrVersionMismatch},
+		{"other version", withHeader(binaryMagic, binaryFormat, Version+".1"), ErrVersionMismatch},
+		{"empty version", withHeader(binaryMagic, binaryFormat, ""), ErrVersionMismatch},
+	}
+	for _, tt := range tests {
+		_, err := LoadEngine(tt.data)
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: LoadEngine error = %v, want %v", tt.name, err, tt.want)
+		}
+		if errors.Is(tt.want, ErrVersionMismatch) && !strings.Contains(fmt.Sprint(err), Version) {
+			t.Errorf("%s: error %q does not name this version", tt.name, err)
+		}
+	}
+}
+
+// TestLoadEngineFormat1 loads an engine saved in layout format 1 and
+// searches with it. Only the header is rewritten to this version: the test
+// pins the layout, not the bytes a build of the engine produces, which
+// change with strategy selection and literal extraction. Regenerate
+// testdata/format1.engine with MarshalBinary when binaryFormat changes.
+func TestLoadEngineFormat1(t *testing.T) {
+	data, err := os.ReadFile("testdata/format1.engine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(data, []byte(binaryMagic)) {
+		t.Fatal("fixture does not start with the magic")
+	}
+	r := wire.NewReader(data[len(binaryMagic):])
+	format, version := r.Uint(), r.String()
+	if r.Err() != nil || format != 1 {
+		t.Fatalf("fixture header: format %d, error %v; want format 1", format, r.Err())
+	}
+	if binaryFormat != 1 {
+		t.Fatalf("layout is format %d: save a new fixture for it", binaryFormat)
+	}
+	header := wire.AppendString(wire.AppendUint([]byte(binaryMagic), format), version)
+	data = append(wire.AppendString(wire.AppendUint([]byte(binaryMagic), binaryFormat), Version), data[len(header):]...)
+
+	engine, err := LoadEngine(data)
+	if err != nil {
+		t.Fatalf("LoadEngine: %v", err)
+	}
+	if got := engine.SubexpNames(); len(got) != 3 {
+		t.Errorf("SubexpNames = %q, want 3 groups", got)
+	}
+	input := []byte("a@b.com x@y.org bob@example.com")
+	want := [][]int{{0, 7, 0, 1, 2, 3}, {16, 31, 16, 19, 20, 27}}
+	if got := submatches(engine, input); !reflect.DeepEqual(got, want) {
+		t.Errorf("FindAllSubmatch(%q) = %v, want %v", input, got, want)
+	}
+	if !engine.IsMatch(input) || engine.IsMatch([]byte("a@b.org")) {
+		t.Errorf("IsMatch disagrees with FindAllSubmatch")
+	}
+}
+
+// TestLoadEngineSkipsExtraction checks that a loaded engine finds the
+// literals its builders need in the saved data.
+func TestLoadEngineSkipsExtraction(t *testing.T) {
+	for _, pattern := range []string{`.*\.txt`, `.*ERROR.*`, `(?m)^/.*\.php`} {
+		engine, err := Compile(pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := engine.MarshalBinary()
+		loaded, err := LoadEngine(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p, q := engine.plan, loaded.plan
+		if p.hasSuffixes != q.hasSuffixes || p.hasLinePref != q.hasLinePref || p.hasInner != q.hasInner {
+			t.Errorf("%q: loaded engine extracted literals again", pattern)
+		}
+		if !p.hasSuffixes && !p.hasLinePref && !p.hasInner {
+			t.Errorf("%q: no literals were extracted while building", pattern)
+		}
+	}
+}
+
+// TestLoadEngineWithObserver checks that the observer given to
+// LoadEngineWithObserver receives the fallback events of the loaded engine.
+func TestLoadEngineWithObserver(t *testing.T) {
+	config := DefaultConfig()
+	config.EnablePrefilter = false
+	config.DenseDFAMaxStates = 0
+	config.Observer = &recorder{}
+	engine, err := CompileWithConfig(`a[ab]{20}c`, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := engine.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadEngine(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Config().Observer != nil {
+		t.Errorf("LoadEngine restored the observer")
+	}
+	rec := &recorder{}
+	loaded, err = LoadEngineWithObserver(data, rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rng := rand.New(rand.NewSource(1))
+	haystack := make([]byte, 256<<10)
+	for i := range haystack {
+		haystack[i] = "ab"[rng.Intn(2)]
+	}
+	for range 20 {
+		loaded.IsMatch(haystack)
+		if len(rec.kinds()) > 0 {
+			break
+		}
+	}
+	if got := rec.kinds(); len(got) == 0 || got[0] != FallbackDFACacheFull {
+		t.Errorf("kinds = %v, want DFACacheFull", got)
+	}
+}
+
+// FuzzLoadEngine checks that LoadEngine rejects corrupt data with an error
+// instead of panicking, and that an engine it accepts can be searched.
+func FuzzLoadEngine(f *testing.F) {
+	for _, pattern := range []string{
+		`a`,
+		`(\w+)@(\w+)\.com`,
+		`.*\.(txt|log)`,
+		`(?m)^/.*\.php`,
+		`foo|bar|baz`,
+		`^(\d+)-(?P<b>\d+)$`,
+		`foo(?=bar)|(?<!x)baz`,
+		`[a-z]+\d+`,
+	} {
+		engine, err := Compile(pattern)
+		if err != nil {
+			f.Fatal(err)
+		}
+		data, err := engine.MarshalBinary()
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(data)
+	}
+	input := []byte("a@b.com x.txt\n/a.php foobar xbaz 12-34 abc123")
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		engine, err := LoadEngine(data)
+		if err != nil {
+			if !errors.Is(err, ErrInvalidEngineData) && !errors.Is(err, ErrVersionMismatch) {
+				t.Fatalf("LoadEngine error = %v", err)
+			}
+			return
+		}
+		engine.IsMatch(input)
+		engine.FindAllSubmatch(input, -1)
+		engine.Count(input, -1)
+	})
+}
@@ -0,0 +1,154 @@
+// Package meta implements the meta-engine orchestrator.
+//
+// bytes.go contains the pattern rewrite behind byte mode (Config.Bytes).
+
+package meta
+
+import (
+	"errors"
+	"fmt"
+	"regexp/syntax"
+	"unicode"
+)
+
+// ErrNotByte is returned when a byte-mode pattern contains a literal
+// character above 0xFF, which does not name a byte.
+var ErrNotByte = errors.New("character is not a byte value (above \\xFF) in byte mode")
+
+// byteRegexp rewrites a parsed pattern so that every node means the bytes it
+// matches in byte mode, leaving nothing for later stages to interpret as
+// UTF-8:
+//   - a literal character r <= 0xFF is the byte r; a literal above 0xFF is
+//     an error (ErrNotByte)
+//   - case-insensitive literals become classes of their simple case folds
+//     that are bytes, so (?i)k is [Kk] and (?i)é is [Éé] (Latin-1)
+//   - classes, including negated, Perl (\d, \w, \s) and Unicode (\pL)
+//     classes, keep only their part in 0x00-0xFF; an empty result never
+//     matches
+//   - . is [^\n] over bytes, and (?s). is any byte
+//
+// Empty-width assertions are unchanged: \b uses ASCII word characters in
+// both modes. The input tree is not modified.
+func byteRegexp(re *syntax.Regexp) (*syntax.Regexp, error) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		return byteLiteral(re)
+
+	case syntax.OpCharClass:
+		return byteClass(re, clipClassToBytes(re.Rune)), nil
+
+	case syntax.OpAnyCharNotNL:
+		return byteClass(re, []rune{0x00, '\n' - 1, '\n' + 1, 0xFF}), nil
+
+	case syntax.OpAnyChar:
+		return byteClass(re, []rune{0x00, 0xFF}), nil
+	}
+
+	if len(re.Sub) == 0 {
+		return re, nil
+	}
+	cp := *re
+	cp.Sub = make([]*syntax.Regexp, len(re.Sub))
+	cp.Sub0 = [1]*syntax.Regexp{}
+	for i, sub := range re.Sub {
+		converted, err := byteRegexp(sub)
+		if err != nil {
+			return nil, err
+		}
+		cp.Sub[i] = converted
+	}
+	return &cp, nil
+}
+
+// byteLiteral converts a literal node for byte mode.
+func byteLiteral(re *syntax.Regexp) (*syntax.Regexp, error) {
+	flags := re.Flags &^ syntax.FoldCase
+	var parts []*syntax.Regexp
+	var run []rune // pending plain bytes
+
+	flush := func() {
+		if len(run) > 0 {
+			parts = append(parts, &syntax.Regexp{Op: syntax.OpLiteral, Flags: flags, Rune: run})
+			run = nil
+		}
+	}
+
+	for _, r := range re.Rune {
+		if re.Flags&syntax.FoldCase == 0 {
+			if r > 0xFF {
+				return nil, fmt.Errorf("%w: %q", ErrNotByte, r)
+			}
+			run = append(run, r)
+			continue
+		}
+
+		folds := byteFolds(r)
+		switch len(folds) {
+		case 0:
+			return nil, fmt.Errorf("%w: %q", ErrNotByte, r)
+		case 1:
+			run = append(run, folds[0])
+		default:
+			flush()
+			class := make([]rune, 0, 2*len(folds))
+			for _, f := range folds {
+				class = append(class, f, f)
+			}
+			parts = append(parts, &syntax.Regexp{Op: syntax.OpCharClass, Flags: flags, Rune: class})
+		}
+	}
+	flush()
+
+	switch len(parts) {
+	case 0:
+		return &syntax.Regexp{Op: syntax.OpEmptyMatch, Flags: flags}, nil
+	case 1:
+		return parts[0], nil
+	}
+	return &syntax.Regexp{Op: syntax.OpConcat, Flags: flags, Sub: parts}, nil
+}
+
+// byteFolds returns the simple case folds of r (r included) that are byte
+// values, in ascending order.
+func byteFolds(r rune) []rune {
+	var folds []rune
+	f := r
+	for {
+		if f <= 0xFF {
+			folds = append(folds, f)
+		}
+		f = unicode.SimpleFold(f)
+		if f == r {
+			break
+		}
+	}
+	// The fold orbit is short; insertion sort keeps the class ranges ordered.
+	for i := 1; i < len(folds); i++ {
+		for j := i; j > 0 && folds[j-1] > folds[j]; j-- {
+			folds[j-1], folds[j] = folds[j], folds[j-1]
+		}
+	}
+	return folds
+}
+
+// clipClassToBytes returns the part of sorted class ranges in 0x00-0xFF.
+func clipClassToBytes(ranges []rune) []rune {
+	clipped := make([]rune, 0, len(ranges))
+	for i := 0; i+1 < len(ranges); i += 2 {
+		lo, hi := ranges[i], ranges[i+1]
+		if lo > 0xFF {
+			break
+		}
+		clipped = append(clipped, lo, min(hi, 0xFF))
+	}
+	return clipped
+}
+
+// byteClass returns a class node with the given byte ranges.
+func byteClass(re *syntax.Regexp, ranges []rune) *syntax.Regexp {
+	return &syntax.Regexp{
+		Op:    syntax.OpCharClass,
+		Flags: re.Flags &^ syntax.FoldCase,
+		Rune:  ranges,
+	}
+}
@@ -0,0 +1,55 @@
+package meta
+
+import (
+	"errors"
+	"regexp/syntax"
+	"testing"
+)
+
+func TestByteRegexp(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string // syntax.Regexp.String of the rewritten tree
+	}{
+		{`abc`, `abc`},
+		{`\xFF`, `ÿ`},
+		{`.`, `[\x00-\t\v-ÿ]`},
+		{`(?s).`, `[\x00-ÿ]`},
+		{`(?i)k`, `[Kk]`},
+		{`(?i)ké`, `[Kk][Éé]`},
+		{`(?i)1k2`, `1[Kk]2`},
+		{`[^a]`, `[\x00-` + "`" + `b-ÿ]`},
+		{`[\x{100}-\x{200}]`, `[^\x00-\x{10FFFF}]`},
+		{`(a.)+`, `(a[\x00-\t\v-ÿ])+`},
+	}
+
+	for _, tt := range tests {
+		re, err := syntax.Parse(tt.pattern, syntax.Perl)
+		if err != nil {
+			t.Fatal(err)
+		}
+		before := re.String()
+		got, err := byteRegexp(re)
+		if err != nil {
+			t.Fatalf("byteRegexp(%q): %v", tt.pattern, err)
+		}
+		if s := got.String(); s != tt.want {
+			t.Errorf("byteRegexp(%q) = %s, want %s", tt.pattern, s, tt.want)
+		}
+		if re.String() != before {
+			t.Errorf("byteRegexp(%q) modified its input: %s", tt.pattern, re.String())
+		}
+	}
+}
+
+func TestByteRegexpErrors(t *testing.T) {
+	for _, pattern := range []string{`中`, `(ab|c\x{100})`, `(?i)\x{100}`} {
+		re, err := syntax.Parse(pattern, syntax.Perl)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := byteRegexp(re); !errors.Is(err, ErrNotByte) {
+			t.Errorf("byteRegexp(%q) err = %v, want ErrNotByte", pattern, err)
+		}
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"regexp/syntax"
 
 	"github.com/coregx/ahocorasick"
+	"github.com/coregx/coregex/dfa/dense"
 	"github.com/coregx/coregex/dfa/lazy"
 	"github.com/coregx/coregex/dfa/onepass"
 	"github.com/coregx/coregex/literal"
@@ -55,15 +56,17 @@ func CompileWithConfig(pattern string, config Config) (*Engine, error) {
 	}
 
 	// Parse pattern
-	re, err := syntax.Parse(pattern, syntax.Perl)
+	re, err := Parse(pattern, config)
 	if err != nil {
-		return nil, &CompileError{
-			Pattern: pattern,
-			Err:     err,
-		}
+		return nil, err
 	}
 
-	return CompileRegexp(re, config)
+	engine, err := CompileRegexp(re, config)
+	if err != nil {
+		return nil, err
+	}
+	engine.plan.pattern = pattern
+	return engine, nil
 }
 
 // buildOnePassDFA tries to build a OnePass DFA for anchored patterns with captures.
@@ -76,10 +79,12 @@ func buildOnePassDFA(re *syntax.Regexp, nfaEngine *nfa.NFA, config Config) *onep
 
 	// Compile anchored NFA for OnePass (requires Anchored: true)
 	anchoredCompiler := nfa.NewCompiler(nfa.CompilerConfig{
-		UTF8:              true,
-		Anchored:          true,
-		DotNewline:        false,
-		MaxRecursionDepth: config.MaxRecursionDepth,
+		UTF8:                !config.Bytes,
+		Bytes:               config.Bytes,
+		Anchored:            true,
+		DotNewline:          false,
+		MaxRecursionDepth:   config.MaxRecursionDepth,
+		UnicodeWordBoundary: config.UnicodeWordBoundary,
 	})
 	anchoredNFA, err := anchoredCompiler.CompileRegexp(re)
 	if err != nil {
@@ -112,14 +117,8 @@ type strategyEngines struct {
 
 // buildStrategyEngines builds all strategy-specific engines based on the selected strategy.
 // Returns the engines and potentially updated strategy (if building fails and fallback is needed).
-func buildStrategyEngines(
-	strategy Strategy,
-	re *syntax.Regexp,
-	nfaEngine *nfa.NFA,
-	literals *literal.Seq,
-	pf prefilter.Prefilter,
-	config Config,
-) strategyEngines {
+func buildStrategyEngines(strategy Strategy, plan *compilePlan, pf prefilter.Prefilter) strategyEngines {
+	re, nfaEngine, literals, config := plan.re, plan.nfa, plan.prefixes, plan.config
 	result := strategyEngines{finalStrategy: strategy}
 
 	// Build Aho-Corasick automaton for large literal alternations (>32 patterns)
@@ -154,7 +153,7 @@ func buildStrategyEngines(
 	dfaConfig.MaxStates = config.MaxDFAStates //nolint:staticcheck // legacy API compat
 	dfaConfig.DeterminizationLimit = config.DeterminizationLimit
 
-	result = buildReverseSearchers(result, strategy, re, nfaEngine, dfaConfig, config)
+	result = buildReverseSearchers(result, strategy, plan, dfaConfig)
 
 	// Build forward DFA for non-reverse strategies
 	if result.finalStrategy == UseDFA || result.finalStrategy == UseBoth || result.finalStrategy == UseDigitPrefilter {
@@ -222,16 +221,10 @@ func buildReverseDFA(
 func buildReverseSearchers(
 	result strategyEngines,
 	strategy Strategy,
-	re *syntax.Regexp,
-	nfaEngine *nfa.NFA,
+	plan *compilePlan,
 	dfaConfig lazy.Config,
-	config Config,
 ) strategyEngines {
-	extractor := literal.New(literal.ExtractorConfig{
-		MaxLiterals:   config.MaxLiterals,
-		MaxLiteralLen: 64,
-		MaxClassSize:  10,
-	})
+	re, nfaEngine := plan.re, plan.nfa
 
 	switch strategy {
 	case UseReverseAnchored:
@@ -243,7 +236,7 @@ func buildReverseSearchers(
 		}
 
 	case UseReverseSuffix:
-		suffixLiterals := extractor.ExtractSuffixes(re)
+		suffixLiterals := plan.suffixLiterals()
 		searcher, err := NewReverseSuffixSearcher(nfaEngine, suffixLiterals, dfaConfig, hasDotStarPrefix(re))
 		if err != nil {
 			result.finalStrategy = UseDFA
@@ -252,7 +245,7 @@ func buildReverseSearchers(
 		}
 
 	case UseReverseSuffixSet:
-		suffixLiterals := extractor.ExtractSuffixes(re)
+		suffixLiterals := plan.suffixLiterals()
 		searcher, err := NewReverseSuffixSetSearcher(nfaEngine, suffixLiterals, dfaConfig, hasDotStarPrefix(re))
 		if err != nil {
 			result.finalStrategy = UseBoth
@@ -261,7 +254,7 @@ func buildReverseSearchers(
 		}
 
 	case UseReverseInner:
-		innerInfo := extractor.ExtractInnerForReverseSearch(re)
+		innerInfo := plan.innerLiterals()
 		if innerInfo == nil {
 			result.finalStrategy = UseDFA
 		} else {
@@ -275,7 +268,7 @@ func buildReverseSearchers(
 
 	case UseMultilineReverseSuffix:
 		// Issue #97: Build multiline-aware reverse suffix searcher for (?m)^.*suffix patterns
-		suffixLiterals := extractor.ExtractSuffixes(re)
+		suffixLiterals := plan.suffixLiterals()
 		searcher, err := NewMultilineReverseSuffixSearcher(nfaEngine, suffixLiterals, dfaConfig)
 		if err != nil {
 			// Fallback to regular ReverseSuffix or DFA
@@ -283,7 +276,7 @@ func buildReverseSearchers(
 		} else {
 			// Issue #99: Extract prefix literals for fast path verification
 			// For patterns like (?m)^/.*\.php, prefix is "/" - enables O(1) verification
-			prefixLiterals := extractor.ExtractPrefixes(re)
+			prefixLiterals := plan.linePrefixLiterals()
 			searcher.SetPrefixLiterals(prefixLiterals)
 			result.multilineReverseSuffixSearcher = searcher
 		}
@@ -381,31 +374,29 @@ func buildCharClassSearchers(
 // buildDotOptimizedNFAs compiles optimized NFA variants for patterns with '.'.
 // Returns:
 //   - asciiNFA: NFA with '.' compiled as single ASCII byte range (for ASCII-only input)
-//   - asciiBT: BoundedBacktracker for asciiNFA
 //   - runeNFA: NFA with '.' compiled as sparse dispatch (fewer split states for PikeVM)
-func buildDotOptimizedNFAs(
-	re *syntax.Regexp, config Config,
-) (*nfa.NFA, *nfa.BoundedBacktracker, *nfa.NFA) {
+func buildDotOptimizedNFAs(re *syntax.Regexp, config Config) (asciiNFA, runeNFA *nfa.NFA) {
 	if !nfa.ContainsDot(re) {
-		return nil, nil, nil
+		return nil, nil
 	}
 
 	// ASCII-only NFA (V11-002 optimization):
 	// compile '.' as single byte range [0x00-0x7F] for ASCII-only inputs.
 	var asciiNFAEngine *nfa.NFA
-	var asciiBT *nfa.BoundedBacktracker
 	if config.EnableASCIIOptimization {
 		asciiCompiler := nfa.NewCompiler(nfa.CompilerConfig{
-			UTF8:              true,
-			Anchored:          false,
-			DotNewline:        false,
-			ASCIIOnly:         true,
-			MaxRecursionDepth: config.MaxRecursionDepth,
+			UTF8:                !config.Bytes,
+			Bytes:               config.Bytes,
+			Anchored:            false,
+			DotNewline:          false,
+			ASCIIOnly:           true,
+			MaxRecursionDepth:   config.MaxRecursionDepth,
+			UnicodeWordBoundary: config.UnicodeWordBoundary,
 		})
 		var err error
 		asciiNFAEngine, err = asciiCompiler.CompileRegexp(re)
-		if err == nil {
-			asciiBT = nfa.NewBoundedBacktracker(asciiNFAEngine)
+		if err != nil {
+			asciiNFAEngine = nil
 		}
 	}
 
@@ -415,18 +406,20 @@ func buildDotOptimizedNFAs(
 	// O(branches) split-chain DFS. Measured 2.8-4.8x PikeVM speedup.
 	var runeNFAEngine *nfa.NFA
 	runeCompiler := nfa.NewCompiler(nfa.CompilerConfig{
-		UTF8:              true,
-		Anchored:          false,
-		DotNewline:        false,
-		UseRuneStates:     true,
-		MaxRecursionDepth: config.MaxRecursionDepth,
+		UTF8:                !config.Bytes,
+		Bytes:               config.Bytes,
+		Anchored:            false,
+		DotNewline:          false,
+		UseRuneStates:       true,
+		MaxRecursionDepth:   config.MaxRecursionDepth,
+		UnicodeWordBoundary: config.UnicodeWordBoundary,
 	})
 	runeNFAEngine, err := runeCompiler.CompileRegexp(re)
 	if err != nil {
 		runeNFAEngine = nil
 	}
 
-	return asciiNFAEngine, asciiBT, runeNFAEngine
+	return asciiNFAEngine, runeNFAEngine
 }
 
 // CompileRegexp compiles a parsed syntax.Regexp with default configuration.
@@ -438,12 +431,23 @@ func buildDotOptimizedNFAs(
 //	re, _ := syntax.Parse("hello", syntax.Perl)
 //	engine, err := meta.CompileRegexp(re, meta.DefaultConfig())
 func CompileRegexp(re *syntax.Regexp, config Config) (*Engine, error) {
+	if config.Bytes {
+		var err error
+		if re, err = byteRegexp(re); err != nil {
+			return nil, &CompileError{
+				Err: err,
+			}
+		}
+	}
+
 	// Compile to NFA
 	compiler := nfa.NewCompiler(nfa.CompilerConfig{
-		UTF8:              true,
-		Anchored:          false,
-		DotNewline:        false,
-		MaxRecursionDepth: config.MaxRecursionDepth,
+		UTF8:                !config.Bytes,
+		Bytes:               config.Bytes,
+		Anchored:            false,
+		DotNewline:          false,
+		MaxRecursionDepth:   config.MaxRecursionDepth,
+		UnicodeWordBoundary: config.UnicodeWordBoundary,
 	})
 
 	nfaEngine, err := compiler.CompileRegexp(re)
@@ -452,38 +456,129 @@ func CompileRegexp(re *syntax.Regexp, config Config) (*Engine, error) {
 			Err: err,
 		}
 	}
+	plan := &compilePlan{re: re, config: config, nfa: nfaEngine}
+	if nfaEngine.HasLookaround() {
+		return compileLookaround(plan), nil
+	}
 
 	// Compile optimized NFA variants for patterns with '.'
-	asciiNFAEngine, asciiBT, runeNFAEngine := buildDotOptimizedNFAs(re, config)
+	plan.asciiNFA, plan.runeNFA = buildDotOptimizedNFAs(re, config)
 
 	// Extract literals for prefiltering
 	// NOTE: Don't build prefilter for start-anchored patterns (^...).
 	// A prefilter for "^abc" would find "abc" anywhere in input, bypassing the anchor.
 	// The prefilter's IsComplete() would return true, causing false positives.
-	var literals *literal.Seq
-	var pf prefilter.Prefilter
 	isStartAnchored := nfaEngine.IsAlwaysAnchored()
 	if config.EnablePrefilter && !isStartAnchored {
-		extractor := literal.New(literal.ExtractorConfig{
-			MaxLiterals:   config.MaxLiterals,
-			MaxLiteralLen: 64,
-			MaxClassSize:  10,
-		})
-		literals = extractor.ExtractPrefixes(re)
-
-		// Build prefilter from prefix literals
-		if literals != nil && !literals.IsEmpty() {
-			builder := prefilter.NewBuilder(literals, nil)
-			pf = builder.Build()
-		}
+		plan.prefixes = plan.extractor().ExtractPrefixes(re)
 	}
 
 	// Debug: log extracted literals (prefixes + suffixes)
-	debugLiterals("prefixes", literals)
+	debugLiterals("prefixes", plan.prefixes)
 	debugSuffixes(re, config, isStartAnchored)
 
 	// Select strategy (pass re for anchor detection)
-	strategy := SelectStrategy(nfaEngine, re, literals, config)
+	plan.strategy = SelectStrategy(nfaEngine, re, plan.prefixes, config)
+	buildDenseDFAs(plan)
+
+	// Build OnePass DFA for anchored patterns with captures (optional optimization)
+	plan.onepass = buildOnePassDFA(re, nfaEngine, config)
+
+	return plan.engine(), nil
+}
+
+// compilePlan holds what CompileRegexp derives from the pattern before it
+// builds the search engines: the compiled NFAs and OnePass DFA, the literals
+// and the selected strategy. The Engine keeps its plan, so that
+// Engine.AppendBinary can save it and LoadEngine can build the engine again
+// without parsing, literal extraction or strategy selection.
+type compilePlan struct {
+	re     *syntax.Regexp
+	config Config
+
+	// pattern is the pattern string, empty for CompileRegexp.
+	pattern string
+
+	nfa      *nfa.NFA
+	runeNFA  *nfa.NFA // nil if the pattern has no '.'
+	asciiNFA *nfa.NFA // nil if the pattern has no '.' or ASCII optimization is off
+	onepass  *onepass.DFA
+
+	// dense and denseReverse are the DFAs of UseDenseDFA.
+	dense        *dense.DFA
+	denseReverse *dense.DFA
+
+	// prefixes are the prefilter literals, nil if there is no prefilter.
+	prefixes *literal.Seq
+
+	// strategy is the strategy chosen by SelectStrategy. Building the
+	// engines may fall back to another one.
+	strategy Strategy
+
+	// The literals below are extracted on first use by the engine builders;
+	// the flags record whether that happened.
+	suffixes     *literal.Seq
+	linePrefixes *literal.Seq // prefix literals of UseMultilineReverseSuffix
+	inner        *literal.InnerLiteralInfo
+	hasSuffixes  bool
+	hasLinePref  bool
+	hasInner     bool
+}
+
+// extractor returns the literal extractor for the plan's configuration.
+func (p *compilePlan) extractor() *literal.Extractor {
+	return literal.New(literal.ExtractorConfig{
+		MaxLiterals:   p.config.MaxLiterals,
+		MaxLiteralLen: 64,
+		MaxClassSize:  10,
+		Bytes:         p.config.Bytes,
+	})
+}
+
+// suffixLiterals returns the suffix literals of the pattern.
+func (p *compilePlan) suffixLiterals() *literal.Seq {
+	if !p.hasSuffixes {
+		p.suffixes, p.hasSuffixes = p.extractor().ExtractSuffixes(p.re), true
+	}
+	return p.suffixes
+}
+
+// linePrefixLiterals returns the prefix literals that
+// UseMultilineReverseSuffix verifies candidates with. Unlike prefixes they
+// are extracted whether or not there is a prefilter.
+func (p *compilePlan) linePrefixLiterals() *literal.Seq {
+	if !p.hasLinePref {
+		p.linePrefixes, p.hasLinePref = p.extractor().ExtractPrefixes(p.re), true
+	}
+	return p.linePrefixes
+}
+
+// innerLiterals returns the inner literal split of UseReverseInner, or nil.
+func (p *compilePlan) innerLiterals() *literal.InnerLiteralInfo {
+	if !p.hasInner {
+		p.inner, p.hasInner = p.extractor().ExtractInnerForReverseSearch(p.re), true
+	}
+	return p.inner
+}
+
+// engine builds the search engines of the plan.
+func (p *compilePlan) engine() *Engine {
+	re, nfaEngine, config := p.re, p.nfa, p.config
+	literals, strategy := p.prefixes, p.strategy
+	runeNFAEngine := p.runeNFA
+	isStartAnchored := nfaEngine.IsAlwaysAnchored()
+
+	var asciiBT *nfa.BoundedBacktracker
+	if p.asciiNFA != nil {
+		asciiBT = nfa.NewBoundedBacktracker(p.asciiNFA)
+	}
+
+	// Build prefilter from prefix literals
+	var pf prefilter.Prefilter
+	if literals != nil && !literals.IsEmpty() {
+		builder := prefilter.NewBuilder(literals, nil)
+		pf = builder.Build()
+	}
 
 	pf, strategy = adjustForAnchors(pf, strategy, re)
 
@@ -502,11 +597,10 @@ func CompileRegexp(re *syntax.Regexp, config Config) (*Engine, error) {
 	// Safe for partial-coverage prefilters — NFA processes all branches.
 	configurePikeVMSkipAhead(pikevm, pf, isStartAnchored)
 
-	// Build OnePass DFA for anchored patterns with captures (optional optimization)
-	onePassRes := buildOnePassDFA(re, nfaEngine, config)
+	onePassRes := p.onepass
 
 	// Build strategy-specific engines (DFA, reverse searchers, Aho-Corasick, etc.)
-	engines := buildStrategyEngines(strategy, re, nfaEngine, literals, pf, config)
+	engines := buildStrategyEngines(strategy, p, pf)
 	strategy = engines.finalStrategy
 
 	// Build specialized searchers for character class patterns.
@@ -519,6 +613,7 @@ func CompileRegexp(re *syntax.Regexp, config Config) (*Engine, error) {
 	debugEngine("OnePass DFA", onePassRes != nil, "not worth it or not anchored")
 	debugEngine("lazy DFA", engines.dfa != nil, "strategy does not need DFA")
 	debugEngine("reverse DFA", engines.reverseDFA != nil, "")
+	debugEngine("dense DFA", p.dense != nil, "too large or not a DFA strategy")
 
 	// Debug: log final strategy selection
 	debugStrategy(re.String(), strategy, nfaEngine.States(), literals, "")
@@ -555,12 +650,7 @@ func CompileRegexp(re *syntax.Regexp, config Config) (*Engine, error) {
 	var anchoredSuffix []byte
 	isEndAnchored := nfa.IsPatternEndAnchored(re)
 	if isStartAnchored && isEndAnchored && strategy == UseBoundedBacktracker {
-		suffixExtractor := literal.New(literal.ExtractorConfig{
-			MaxLiterals:   config.MaxLiterals,
-			MaxLiteralLen: 64,
-			MaxClassSize:  10,
-		})
-		suffixLiterals := suffixExtractor.ExtractSuffixes(re)
+		suffixLiterals := p.suffixLiterals()
 		if suffixLiterals != nil && !suffixLiterals.IsEmpty() {
 			lcs := suffixLiterals.LongestCommonSuffix()
 			if len(lcs) >= config.MinLiteralLen {
@@ -609,7 +699,7 @@ func CompileRegexp(re *syntax.Regexp, config Config) (*Engine, error) {
 	eng := &Engine{
 		nfa:                            nfaEngine,
 		runeNFA:                        runeNFAEngine,
-		asciiNFA:                       asciiNFAEngine,
+		asciiNFA:                       p.asciiNFA,
 		asciiBoundedBacktracker:        asciiBT,
 		dfa:                            engines.dfa,
 		reverseDFA:                     engines.reverseDFA,
@@ -636,11 +726,14 @@ func CompileRegexp(re *syntax.Regexp, config Config) (*Engine, error) {
 		strategy:                       strategy,
 		config:                         config,
 		onepass:                        onePassRes,
+		denseDFA:                       p.dense,
+		denseReverseDFA:                p.denseReverse,
 		canMatchEmpty:                  canMatchEmpty,
 		isStartAnchored:                isStartAnchored,
 		fatTeddyFallback:               fatTeddyFallback,
 		statePool:                      newSearchStatePool(ssCfg),
-		stats:                          Stats{},
+		stats:                          newStatCounters(config),
+		plan:                           p,
 	}
 
 	// Issue #158: Defer SearchState allocation to first search.
@@ -650,7 +743,7 @@ func CompileRegexp(re *syntax.Regexp, config Config) (*Engine, error) {
 	// compiled but never searched (e.g., pattern sets loaded at startup).
 	// The sync.Pool in statePool handles subsequent allocations efficiently.
 
-	return eng, nil
+	return eng
 }
 
 // adjustForAnchors fixes prefilter for patterns with anchors.
@@ -767,7 +860,13 @@ func buildSearchStateConfig(nfaEngine *nfa.NFA, numCaptures int, engines strateg
 // CompileError represents a pattern compilation error.
 type CompileError struct {
 	Pattern string
-	Err     error
+
+	// Offset is the byte offset in Pattern of the text a syntax error refers
+	// to (the Expr of the *syntax.Error), or -1 if it cannot be located. It
+	// is not set for other errors.
+	Offset int
+
+	Err error
 }
 
 // Error implements the error interface.
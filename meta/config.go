@@ -15,6 +15,12 @@
 // hiding the complexity of multi-engine coordination from users.
 package meta
 
+import (
+	"regexp/syntax"
+	"strings"
+	"unicode"
+)
+
 // Config controls meta-engine behavior and performance characteristics.
 //
 // Configuration options affect:
@@ -49,6 +55,14 @@ type Config struct {
 	// Default: 1000
 	DeterminizationLimit int
 
+	// DenseDFAMaxStates is the largest number of states, counted before
+	// minimization, for which a pattern is compiled to dense DFAs
+	// (UseDenseDFA) instead of using the lazy DFA. Dense DFAs are built
+	// ahead of time and are read-only, so searches need no DFA cache.
+	// Zero disables dense DFAs. Ignored if EnableDFA is false.
+	// Default: 512
+	DenseDFAMaxStates int
+
 	// MinLiteralLen is the minimum length for prefilter literals.
 	// Shorter literals may have too many false positives.
 	// Default: 2
@@ -81,6 +95,79 @@ type Config struct {
 	//
 	// Default: true
 	EnableASCIIOptimization bool
+
+	// Bytes compiles the pattern in byte mode: the haystack is a sequence of
+	// bytes, not UTF-8 text. Every character in the pattern stands for the
+	// byte with the same value, so `\xFF` matches the single byte 0xFF and
+	// `.` and negated classes match any one byte. See CompileBytes for the
+	// full rules.
+	// Default: false
+	Bytes bool
+
+	// SyntaxFlags are the regexp/syntax parser flags, for example
+	// syntax.Perl | syntax.FoldCase for a case-insensitive pattern or
+	// syntax.Perl &^ syntax.OneLine for multi-line mode.
+	// The zero value means syntax.Perl.
+	// Default: syntax.Perl
+	SyntaxFlags syntax.Flags
+
+	// Verbose enables verbose (x) mode: unescaped whitespace in the pattern
+	// is ignored and # starts a comment that runs to the end of the line,
+	// except inside character classes and \Q...\E. Use `\ ` or [ ] for a
+	// literal space and `\#` for a literal #. Ignored if SyntaxFlags has
+	// syntax.Literal.
+	// Default: false
+	Verbose bool
+
+	// UnicodeWordBoundary makes \b and \B treat Unicode letters, marks,
+	// decimal digits and connector punctuation as word chars, so that they
+	// work on Cyrillic or CJK text; by default only [0-9A-Za-z_] are. The
+	// lazy DFA still runs such patterns but hands the search to the NFA at
+	// the first non-ASCII byte. \w is not affected. Ignored in byte mode.
+	// Default: false
+	UnicodeWordBoundary bool
+
+	// ExtendedSyntax enables the class set operations and PCRE escapes
+	// that regexp/syntax lacks. They are rewritten to plain character
+	// classes before parsing, so every strategy and literal extractor
+	// handles them as usual:
+	//   - [A&&B] is the intersection and [A--B] the difference of the
+	//     class items A and B, e.g. [\p{L}&&[^\p{Latin}]] or [a-z--[aeiou]];
+	//     in case-insensitive mode A and B are case folded first
+	//   - a [...] inside a class is a nested class, not a literal '['
+	//   - \h and \H match horizontal whitespace and anything else
+	//   - \R matches a line break: \r\n, \n, \v, \f, \r, U+0085, U+2028
+	//     or U+2029
+	//   - \Z matches at the end of text or before a final \n (a
+	//     look-ahead, so it is rejected in a Set)
+	//   - \p{Name} and \P{Name} match the classes of Classes
+	// Inside a class, && and -- are always operators. Ignored unless
+	// SyntaxFlags has syntax.PerlX and not syntax.Literal.
+	// Default: false
+	ExtendedSyntax bool
+
+	// Classes are named classes for \p{Name}, \P{Name} and \p{^Name} in
+	// extended syntax, for example {"Hex": unicode.ASCII_Hex_Digit}. They
+	// take precedence over the Unicode classes of the same name. Ignored
+	// unless ExtendedSyntax is set.
+	// Default: nil
+	Classes map[string]*unicode.RangeTable
+
+	// EnableStats makes the engine count its searches, prefilter
+	// candidates, DFA cache clears, NFA fallbacks and scanned bytes; see
+	// Engine.Stats. The counters are atomic and shared by all goroutines
+	// searching the engine, so they cost some throughput on hot patterns.
+	// When false, Stats returns zeros and searches only check a nil pointer.
+	// Default: false
+	EnableStats bool
+
+	// Observer, if set, is told about searches that fall back to a slower
+	// engine: the lazy DFA giving up after its cache clears, or input too
+	// long for the BoundedBacktracker. Without an observer, searches only
+	// check for nil.
+	// It is not saved by Engine.AppendBinary; see LoadEngineWithObserver.
+	// Default: nil
+	Observer Observer
 }
 
 // DefaultConfig returns a configuration with sensible defaults.
@@ -103,10 +190,12 @@ func DefaultConfig() Config {
 		EnablePrefilter:         true,
 		MaxDFAStates:            10000,
 		DeterminizationLimit:    1000,
+		DenseDFAMaxStates:       512,
 		MinLiteralLen:           1,   // Allow single-byte prefilters (memchr) like Rust
 		MaxLiterals:             256, // Allow detecting >64 literals for Aho-Corasick
 		MaxRecursionDepth:       100,
 		EnableASCIIOptimization: true, // V11-002: ASCII runtime detection for '.' patterns
+		SyntaxFlags:             syntax.Perl,
 	}
 }
 
@@ -116,6 +205,7 @@ func DefaultConfig() Config {
 // Valid ranges:
 //   - MaxDFAStates: 1 to 1,000,000
 //   - DeterminizationLimit: 10 to 100,000
+//   - DenseDFAMaxStates: 0 to 100,000
 //   - MinLiteralLen: 1 to 64
 //   - MaxLiterals: 1 to 1,000
 //   - MaxRecursionDepth: 10 to 1,000
@@ -140,6 +230,12 @@ func (c Config) Validate() error {
 				Message: "must be between 10 and 100,000",
 			}
 		}
+		if c.DenseDFAMaxStates < 0 || c.DenseDFAMaxStates > 100_000 {
+			return &ConfigError{
+				Field:   "DenseDFAMaxStates",
+				Message: "must be between 0 and 100,000",
+			}
+		}
 	}
 
 	if c.EnablePrefilter {
@@ -164,6 +260,15 @@ func (c Config) Validate() error {
 		}
 	}
 
+	for name, table := range c.Classes {
+		if name == "" || strings.ContainsAny(name, "{}^") || table == nil {
+			return &ConfigError{
+				Field:   "Classes",
+				Message: "names must be non-empty without '{', '}' or '^' and tables non-nil",
+			}
+		}
+	}
+
 	return nil
 }
 
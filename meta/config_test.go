@@ -21,6 +21,9 @@ func TestDefaultConfigValues(t *testing.T) {
 	if c.DeterminizationLimit != 1000 {
 		t.Errorf("DeterminizationLimit = %d, want 1000", c.DeterminizationLimit)
 	}
+	if c.DenseDFAMaxStates != 512 {
+		t.Errorf("DenseDFAMaxStates = %d, want 512", c.DenseDFAMaxStates)
+	}
 	if c.MinLiteralLen != 1 {
 		t.Errorf("MinLiteralLen = %d, want 1", c.MinLiteralLen)
 	}
@@ -110,6 +113,34 @@ func TestConfigValidateDeterminizationLimit(t *testing.T) {
 	}
 }
 
+// TestConfigValidateDenseDFAMaxStates tests DenseDFAMaxStates validation.
+func TestConfigValidateDenseDFAMaxStates(t *testing.T) {
+	tests := []struct {
+		name   string
+		states int
+		valid  bool
+	}{
+		{"negative", -1, false},
+		{"disabled (0)", 0, true},
+		{"typical (512)", 512, true},
+		{"at maximum (100000)", 100_000, true},
+		{"above maximum", 100_001, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := DefaultConfig()
+			c.DenseDFAMaxStates = tt.states
+			err := c.Validate()
+
+			if (err == nil) != tt.valid {
+				t.Errorf("DenseDFAMaxStates=%d: Validate() error = %v, wantValid %v",
+					tt.states, err, tt.valid)
+			}
+		})
+	}
+}
+
 // TestConfigValidateMinLiteralLen tests MinLiteralLen validation.
 func TestConfigValidateMinLiteralLen(t *testing.T) {
 	tests := []struct {
@@ -0,0 +1,244 @@
+package meta
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/coregx/coregex/dfa/lazy"
+	"github.com/coregx/coregex/nfa"
+)
+
+// ErrSearchAborted is matched (errors.Is) by every error returned by an
+// interrupted *Context search. See SearchAbortedError.
+var ErrSearchAborted = errors.New("regexp: search aborted")
+
+// ErrBudgetExhausted is the cause of a search aborted because the work budget
+// set with WithSearchBudget ran out.
+var ErrBudgetExhausted = errors.New("regexp: search budget exhausted")
+
+// SearchAbortedError is returned by *Context searches that were stopped before
+// they could complete. Cause is ctx.Err() if the context was cancelled, or
+// ErrBudgetExhausted.
+//
+// errors.Is(err, ErrSearchAborted) is true for every SearchAbortedError, and
+// errors.Is(err, context.Canceled) etc. checks the cause.
+type SearchAbortedError struct {
+	Cause error
+
+	// Work is the amount of work spent before the search was stopped, in the
+	// units of WithSearchBudget.
+	Work int64
+}
+
+// Error implements the error interface.
+func (e *SearchAbortedError) Error() string {
+	return "regexp: search aborted: " + e.Cause.Error()
+}
+
+// Unwrap returns the cause.
+func (e *SearchAbortedError) Unwrap() error {
+	return e.Cause
+}
+
+// Is reports whether target is ErrSearchAborted.
+func (e *SearchAbortedError) Is(target error) bool {
+	return target == ErrSearchAborted
+}
+
+// searchBudgetKey is the context key of the work budget.
+type searchBudgetKey struct{}
+
+// WithSearchBudget returns a copy of ctx that limits every *Context search
+// run with it to the given amount of work.
+//
+// Work is counted in engine steps: one unit per haystack byte scanned by the
+// DFA, one unit per NFA thread per byte when the NFA runs. A budget of a few
+// times the haystack length lets every DFA search finish; pathological
+// pattern/input combinations that fall back to the NFA run out sooner.
+// The search stops as soon as the budget is exceeded, however small it is.
+// Zero or negative means unlimited.
+func WithSearchBudget(ctx context.Context, work int64) context.Context {
+	return context.WithValue(ctx, searchBudgetKey{}, work)
+}
+
+// newInterrupt returns the Interrupt for ctx, or nil if the search can never
+// be interrupted (no Done channel, no budget), in which case the regular
+// search is used at no extra cost.
+func newInterrupt(ctx context.Context) *nfa.Interrupt {
+	budget, _ := ctx.Value(searchBudgetKey{}).(int64)
+	done := ctx.Done()
+	if done == nil && budget <= 0 {
+		return nil
+	}
+	return &nfa.Interrupt{Done: done, Budget: budget}
+}
+
+// abortError builds the error for a stopped search.
+func abortError(ctx context.Context, in *nfa.Interrupt) error {
+	cause := ctx.Err()
+	if in.BudgetExhausted() || cause == nil {
+		cause = ErrBudgetExhausted
+	}
+	return &SearchAbortedError{Cause: cause, Work: in.Spent()}
+}
+
+// IsMatchContext is like IsMatch but stops when ctx is done or its search
+// budget (WithSearchBudget) runs out, returning a *SearchAbortedError.
+//
+// Interruptible searches run the lazy DFA, checking the context every
+// nfa.InterruptCheckInterval bytes, and the PikeVM where the DFA cannot
+// decide; the Find searches run the PikeVM only from a position the DFA
+// found at or before the match start to the match end. The
+// strategy-specific fast paths of the regular searches are not used.
+func (e *Engine) IsMatchContext(ctx context.Context, haystack []byte) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, &SearchAbortedError{Cause: err}
+	}
+	in := newInterrupt(ctx)
+	if in == nil {
+		return e.IsMatch(haystack), nil
+	}
+	e.stats.searched(len(haystack))
+
+	if _, end, ok := e.searchDFAInterruptible(haystack, 0, true, in); ok {
+		return end >= 0, nil
+	}
+	if in.Stopped() {
+		return false, abortError(ctx, in)
+	}
+
+	state := e.getSearchState(len(haystack))
+	defer e.putSearchState(state)
+	state.pikevm.SetInterrupt(in)
+	defer state.pikevm.SetInterrupt(nil)
+	e.stats.inc(statNFASearches)
+	_, _, found := state.pikevm.SearchWithSlotTableAt(haystack, 0, nfa.SearchModeIsMatch)
+	if in.Stopped() {
+		return false, abortError(ctx, in)
+	}
+	return found, nil
+}
+
+// FindIndicesContext is like FindIndicesAt but interruptible, see
+// IsMatchContext.
+func (e *Engine) FindIndicesContext(ctx context.Context, haystack []byte, at int) (start, end int, found bool, err error) {
+	if err := ctx.Err(); err != nil {
+		return -1, -1, false, &SearchAbortedError{Cause: err}
+	}
+	in := newInterrupt(ctx)
+	if in == nil {
+		start, end, found = e.FindIndicesAt(haystack, at)
+		return start, end, found, nil
+	}
+
+	m := e.findInterruptible(haystack, at, in)
+	if in.Stopped() {
+		return -1, -1, false, abortError(ctx, in)
+	}
+	if m == nil {
+		e.stats.scanned(haystack, at, -1)
+		return -1, -1, false, nil
+	}
+	e.stats.scanned(haystack, at, m.End)
+	return m.Start, m.End, true, nil
+}
+
+// FindSubmatchContext is like FindSubmatchAt but interruptible, see
+// IsMatchContext.
+func (e *Engine) FindSubmatchContext(ctx context.Context, haystack []byte, at int) (*MatchWithCaptures, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, &SearchAbortedError{Cause: err}
+	}
+	in := newInterrupt(ctx)
+	if in == nil {
+		return e.FindSubmatchAt(haystack, at), nil
+	}
+
+	m := e.findInterruptible(haystack, at, in)
+	if in.Stopped() {
+		return nil, abortError(ctx, in)
+	}
+	if m == nil {
+		e.stats.scanned(haystack, at, -1)
+		return nil, nil
+	}
+	e.stats.scanned(haystack, at, m.End)
+	return NewMatchWithCaptures(haystack, m.Captures), nil
+}
+
+// findInterruptible finds the first match from at, charging in. The forward
+// DFA finds the end of the leftmost-first match and a position at or before
+// its start, so the PikeVM, which finds the start and the captures, only
+// runs between the two. In leftmost-longest mode the match may end past the
+// DFA's end and the PikeVM runs to the end of the haystack. If the DFA is
+// unavailable or gives up, the PikeVM searches from at.
+func (e *Engine) findInterruptible(haystack []byte, at int, in *nfa.Interrupt) *nfa.MatchWithCaptures {
+	from, end, ok := e.searchDFAInterruptible(haystack, at, false, in)
+	if in.Stopped() || (ok && end < 0) {
+		return nil
+	}
+	if !ok {
+		from, end = at, len(haystack)
+	} else if e.longest {
+		end = len(haystack)
+	}
+
+	state := e.getSearchState(len(haystack))
+	defer e.putSearchState(state)
+	state.pikevm.SetInterrupt(in)
+	defer state.pikevm.SetInterrupt(nil)
+	e.stats.inc(statNFASearches)
+	return state.pikevm.SearchSpan(haystack, from, end, nfa.SpanOptions{})
+}
+
+// searchDFAInterruptible runs the reader-search DFA over haystack from at,
+// see lazy.DFA.SearchAtInterruptible. ok=false means the search was aborted
+// or the DFA is unavailable or gave up.
+func (e *Engine) searchDFAInterruptible(haystack []byte, at int, earliest bool, in *nfa.Interrupt) (from, end int, ok bool) {
+	d := e.getStreamDFA()
+	if d == nil {
+		return at, -1, false
+	}
+	cache, before := e.getDFACache(d, &e.contextCaches)
+	from, end, ok = d.SearchAtInterruptible(cache, haystack, at, earliest, in)
+	e.putDFACache(&e.contextCaches, cache, before, len(haystack))
+	return from, end, ok
+}
+
+// searchSpanDFA runs a lazy DFA over haystack[start:end], see
+// lazy.DFA.SearchSpan: the reader-search DFA, or with all the push-mode DFA,
+// which sees every match end. ok=false means the search was aborted or the
+// DFA is unavailable or gave up.
+func (e *Engine) searchSpanDFA(haystack []byte, start, end int, opts nfa.SpanOptions, all bool, in *nfa.Interrupt) (int, bool) {
+	d, pool := e.getStreamDFA(), &e.contextCaches
+	if all {
+		d, pool = e.getPushDFA(), &e.pushCaches
+	}
+	if d == nil {
+		return -1, false
+	}
+	cache, before := e.getDFACache(d, pool)
+	matchEnd, ok := d.SearchSpan(cache, haystack, start, end, opts, in)
+	e.putDFACache(pool, cache, before, len(haystack))
+	return matchEnd, ok
+}
+
+// getDFACache takes a cache for d from pool and counts the DFA search.
+func (e *Engine) getDFACache(d *lazy.DFA, pool *sync.Pool) (*lazy.DFACache, cacheCounts) {
+	cache, _ := pool.Get().(*lazy.DFACache)
+	if cache == nil {
+		cache = d.NewCache()
+	}
+	e.stats.inc(statDFASearches)
+	return cache, readCacheCounts(cache)
+}
+
+// putDFACache reports the cache activity of a search of n bytes since
+// getDFACache and returns the cache to pool.
+func (e *Engine) putDFACache(pool *sync.Pool, cache *lazy.DFACache, before cacheCounts, n int) {
+	if e.stats != nil || e.config.Observer != nil {
+		e.observeCacheCounts(readCacheCounts(cache).sub(before), n)
+	}
+	pool.Put(cache)
+}
@@ -0,0 +1,249 @@
+package meta
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/rand"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestContextSearchesMatchRegular(t *testing.T) {
+	long := strings.Repeat("abc ", 4096)
+	tests := []struct {
+		pattern  string
+		haystack string
+	}{
+		{`foo`, "xx foo foo"},
+		{`(\d+)-(\d+)`, "tel 555-1234"},
+		{`a|ab`, "xab"},
+		{`(?i)hello`, long + "HeLLo"},
+		{`\bcat\b`, "concat cat"},
+		{`^abc`, "xabc"},
+		{`x*`, "abc"},
+		{`needle`, long},
+		// End-of-text assertions, and matches the DFA end bounds.
+		{`a$\b`, "a"},
+		{`$\B`, "ab "},
+		{`(\B)+$\B`, "b "},
+		{`ab$|c`, "abc"},
+		{`\w+$`, long + "end"},
+		{`(?m)c $`, long},
+		// Chains of assertions the DFA cannot follow.
+		{`\B\A`, " x"},
+		{`\z(?m:^)`, "a\n"},
+		{`\B^`, "=x"},
+		{`(?m:$)\B`, "a \n b"},
+	}
+
+	// A cancellable context takes the interruptible path.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	for _, tt := range tests {
+		engine, err := Compile(tt.pattern)
+		if err != nil {
+			t.Fatalf("Compile(%q): %v", tt.pattern, err)
+		}
+		h := []byte(tt.haystack)
+
+		matched, err := engine.IsMatchContext(ctx, h)
+		if err != nil || matched != engine.IsMatch(h) {
+			t.Errorf("%q: IsMatchContext = %v, %v; want %v", tt.pattern, matched, err, engine.IsMatch(h))
+		}
+
+		start, end, found, err := engine.FindIndicesContext(ctx, h, 0)
+		wantStart, wantEnd, wantFound := engine.FindIndicesAt(h, 0)
+		if err != nil || start != wantStart || end != wantEnd || found != wantFound {
+			t.Errorf("%q: FindIndicesContext = (%d, %d, %v, %v); want (%d, %d, %v)",
+				tt.pattern, start, end, found, err, wantStart, wantEnd, wantFound)
+		}
+
+		m, err := engine.FindSubmatchContext(ctx, h, 0)
+		want := engine.FindSubmatchAt(h, 0)
+		if err != nil || (m == nil) != (want == nil) || (m != nil && !reflect.DeepEqual(m.AllGroupStrings(), want.AllGroupStrings())) {
+			t.Errorf("%q: FindSubmatchContext = %v, %v; want %v", tt.pattern, m, err, want)
+		}
+	}
+}
+
+// TestFindIndicesContextLeftmostFirst checks matches that start before the
+// end of the DFA's leftmost-first match and end after it.
+func TestFindIndicesContextLeftmostFirst(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		haystack string
+		want     []int
+	}{
+		{`((?:[ab])*|((?s:.))+)\s(?s:.)x?`, "axxcAccc1Abaabacc=a 1xb\nbb", []int{0, 25}},
+		{`(?:\w?){1,3}.?a`, "abbba b xx\n=", []int{0, 5}},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	for _, tt := range tests {
+		engine, err := Compile(tt.pattern)
+		if err != nil {
+			t.Fatalf("Compile(%q): %v", tt.pattern, err)
+		}
+		start, end, found, err := engine.FindIndicesContext(ctx, []byte(tt.haystack), 0)
+		if err != nil || !found || start != tt.want[0] || end != tt.want[1] {
+			t.Errorf("%q: FindIndicesContext(%q) = (%d, %d, %v, %v); want %v",
+				tt.pattern, tt.haystack, start, end, found, err, tt.want)
+		}
+	}
+}
+
+// TestFindIndicesContextMatchesStdlib checks FindIndicesContext against
+// regexp over random patterns.
+func TestFindIndicesContextMatchesStdlib(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		pattern := randomPattern(rng, 3)
+		std := regexp.MustCompile(pattern)
+		engine, err := Compile(pattern)
+		if err != nil {
+			t.Fatalf("Compile(%q): %v", pattern, err)
+		}
+		for j := 0; j < 5; j++ {
+			h := randomHaystack(rng, 20)
+			start, end, found, err := engine.FindIndicesContext(ctx, h, 0)
+			var got []int
+			if found {
+				got = []int{start, end}
+			}
+			if want := std.FindIndex(h); err != nil || !reflect.DeepEqual(got, want) {
+				t.Errorf("%q: FindIndicesContext(%q) = %v, %v; want %v", pattern, h, got, err, want)
+			}
+		}
+	}
+}
+
+// TestContextSearchesLongHaystack checks the context searches against the
+// regular ones on haystacks long enough for the DFA to narrow the span the
+// PikeVM runs over, in both match semantics.
+func TestContextSearchesLongHaystack(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 300; i++ {
+		pattern := randomPattern(rng, 3)
+		engine, err := Compile(pattern)
+		if err != nil {
+			t.Fatalf("Compile(%q): %v", pattern, err)
+		}
+		longest := rng.Intn(4) == 0
+		engine.SetLongest(longest)
+
+		var h []byte
+		for len(h) < 3*4096 {
+			h = append(h, randomHaystack(rng, 40)...)
+		}
+		at := rng.Intn(len(h))
+
+		start, end, found, err := engine.FindIndicesContext(ctx, h, at)
+		wantStart, wantEnd, wantFound := engine.FindIndicesAt(h, at)
+		if err != nil || start != wantStart || end != wantEnd || found != wantFound {
+			t.Errorf("%q longest=%v at %d: FindIndicesContext = (%d, %d, %v, %v); want (%d, %d, %v)",
+				pattern, longest, at, start, end, found, err, wantStart, wantEnd, wantFound)
+		}
+
+		m, err := engine.FindSubmatchContext(ctx, h, at)
+		want := engine.FindSubmatchAt(h, at)
+		if err != nil || (m == nil) != (want == nil) || (m != nil && !reflect.DeepEqual(m.captures, want.captures)) {
+			t.Errorf("%q longest=%v at %d: FindSubmatchContext = %v, %v; want %v", pattern, longest, at, m, err, want)
+		}
+	}
+}
+
+func TestContextSearchAborted(t *testing.T) {
+	engine, err := Compile(`(a+)+b`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := []byte(strings.Repeat("a", 1<<20))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = engine.IsMatchContext(ctx, h)
+	if !errors.Is(err, ErrSearchAborted) || !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled: err = %v, want ErrSearchAborted wrapping context.Canceled", err)
+	}
+
+	ctx = WithSearchBudget(context.Background(), 64<<10)
+	_, _, _, err = engine.FindIndicesContext(ctx, h, 0)
+	var aborted *SearchAbortedError
+	if !errors.As(err, &aborted) || !errors.Is(err, ErrBudgetExhausted) {
+		t.Fatalf("budget: err = %v, want SearchAbortedError(ErrBudgetExhausted)", err)
+	}
+	if aborted.Work < 64<<10 {
+		t.Errorf("budget: Work = %d, want at least the budget", aborted.Work)
+	}
+
+	_, err = engine.FindSubmatchContext(ctx, h, 0)
+	if !errors.Is(err, ErrBudgetExhausted) {
+		t.Errorf("budget: FindSubmatchContext err = %v", err)
+	}
+
+	// A budget large enough for the DFA lets the search finish.
+	ctx = WithSearchBudget(context.Background(), 4*int64(len(h)))
+	if matched, err := engine.IsMatchContext(ctx, h); err != nil || matched {
+		t.Errorf("large budget: IsMatchContext = %v, %v; want false, nil", matched, err)
+	}
+
+	// Budgets smaller than nfa.InterruptCheckInterval are enforced too.
+	ctx = WithSearchBudget(context.Background(), 10)
+	if _, err := engine.IsMatchContext(ctx, h[:100]); !errors.As(err, &aborted) || !errors.Is(err, ErrBudgetExhausted) {
+		t.Fatalf("small budget: err = %v, want SearchAbortedError(ErrBudgetExhausted)", err)
+	}
+	if aborted.Work > 11 {
+		t.Errorf("small budget: Work = %d, want the search to stop right after the budget", aborted.Work)
+	}
+}
+
+// TestFindContextMatchNearEnd checks that the PikeVM only runs around the
+// match: the DFA scans the haystack, so a budget of a few times its length
+// is enough even when the match is at the end.
+func TestFindContextMatchNearEnd(t *testing.T) {
+	engine, err := Compile(`(\w+)@(\w+)\.com`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := append(bytes.Repeat([]byte("lorem ipsum "), 1<<16), "bob@example.com"...)
+	ctx := WithSearchBudget(context.Background(), 2*int64(len(h)))
+
+	start, end, found, err := engine.FindIndicesContext(ctx, h, 0)
+	if err != nil || !found || start != len(h)-15 || end != len(h) {
+		t.Errorf("FindIndicesContext = (%d, %d, %v, %v); want (%d, %d, true, nil)",
+			start, end, found, err, len(h)-15, len(h))
+	}
+	m, err := engine.FindSubmatchContext(ctx, h, 0)
+	if err != nil || m == nil || m.GroupString(2) != "example" {
+		t.Errorf("FindSubmatchContext = %v, %v; want group 2 %q", m, err, "example")
+	}
+}
+
+func BenchmarkFindIndicesContext(b *testing.B) {
+	engine, err := Compile(`(\w+)@(\w+)\.com`)
+	if err != nil {
+		b.Fatal(err)
+	}
+	h := append(bytes.Repeat([]byte("lorem ipsum "), 1<<16), "bob@example.com"...)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	b.SetBytes(int64(len(h)))
+	for i := 0; i < b.N; i++ {
+		if _, _, _, err := engine.FindIndicesContext(ctx, h, 0); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -85,6 +85,7 @@ func debugSuffixes(re *syntax.Regexp, config Config, isStartAnchored bool) {
 		MaxLiterals:   config.MaxLiterals,
 		MaxLiteralLen: 64,
 		MaxClassSize:  10,
+		Bytes:         config.Bytes,
 	})
 	suffixes := extractor.ExtractSuffixes(re)
 	debugLiterals("suffixes", suffixes)
@@ -95,23 +96,7 @@ func debugPrefilter(pf prefilter.Prefilter) {
 	if debugLevel < 1 || pf == nil {
 		return
 	}
-	// Determine prefilter type name from concrete type
-	name := fmt.Sprintf("%T", pf)
-	switch pf.(type) {
-	case *prefilter.Teddy:
-		name = "Teddy (SSSE3 slim)"
-	case *prefilter.FatTeddy:
-		name = "FatTeddy (AVX2 fat)"
-	case *prefilter.AhoCorasickPrefilter:
-		name = "AhoCorasick (DFA)"
-	}
-	// Extract short name from *prefilter.memchrPrefilter → memchr
-	if strings.Contains(name, "memchr") {
-		name = "memchr"
-	} else if strings.Contains(name, "memmem") {
-		name = "memmem"
-	}
-	fmt.Fprintf(os.Stderr, "[coregex] prefilter=%s complete=%v\n", name, pf.IsComplete())
+	fmt.Fprintf(os.Stderr, "[coregex] prefilter=%s complete=%v\n", prefilterName(pf), pf.IsComplete())
 }
 
 // debugEngine logs which engines were built or skipped.
@@ -0,0 +1,134 @@
+// Package meta implements the meta-engine orchestrator.
+//
+// dense.go contains the UseDenseDFA strategy: patterns whose DFA is small
+// are determinized ahead of time into read-only dense DFAs.
+
+package meta
+
+import (
+	"github.com/coregx/coregex/dfa/dense"
+	"github.com/coregx/coregex/nfa"
+)
+
+// buildDenseDFAs switches the plan from the lazy DFA to UseDenseDFA when the
+// pattern's DFAs stay under Config.DenseDFAMaxStates.
+//
+// Only UseDFA and UseBoth are replaced: the other strategies rely on
+// literals or specialized searchers that beat a DFA scan. Literal patterns
+// with a complete prefilter keep the memchr/Teddy fast path of UseDFA.
+//
+// The reverse NFA treats assertions as always true, so the reverse DFA would
+// report wrong starts for patterns with ^, $, \b or \B. Those are only
+// converted when they are start-anchored and need no reverse search.
+func buildDenseDFAs(plan *compilePlan) {
+	config, n := plan.config, plan.nfa
+	if !config.EnableDFA || config.DenseDFAMaxStates == 0 ||
+		(plan.strategy != UseDFA && plan.strategy != UseBoth) {
+		return
+	}
+	// Determinizing costs at least one closure per NFA state; a large NFA
+	// rarely gives a small DFA, so do not spend the compile time on it.
+	if n.States() > config.DenseDFAMaxStates {
+		return
+	}
+	if plan.prefixes != nil && !plan.prefixes.IsEmpty() && plan.prefixes.AllComplete() {
+		return
+	}
+	anchored := n.IsAlwaysAnchored()
+	if !anchored && hasAnchorAssertions(plan.re) {
+		return
+	}
+
+	denseConfig := dense.DefaultConfig()
+	denseConfig.MaxStates = config.DenseDFAMaxStates
+	forward, err := dense.Build(n, denseConfig)
+	if err != nil {
+		return
+	}
+	var reverse *dense.DFA
+	if !anchored {
+		denseConfig.BreakAtMatch = false
+		if reverse, err = dense.Build(nfa.ReverseAnchored(n), denseConfig); err != nil {
+			return
+		}
+	}
+	plan.dense, plan.denseReverse = forward, reverse
+	plan.strategy = UseDenseDFA
+}
+
+// findDenseDFA searches using the dense DFAs.
+func (e *Engine) findDenseDFA(haystack []byte) *Match {
+	return e.findDenseDFAAt(haystack, 0)
+}
+
+// findDenseDFAAt searches using the dense DFAs starting at position 'at'.
+func (e *Engine) findDenseDFAAt(haystack []byte, at int) *Match {
+	start, end, found := e.findIndicesDenseDFAAt(haystack, at, nil)
+	if !found {
+		return nil
+	}
+	return NewMatch(start, end, haystack)
+}
+
+// findIndicesDenseDFA searches using the dense DFAs - zero alloc.
+func (e *Engine) findIndicesDenseDFA(haystack []byte) (int, int, bool) {
+	return e.findIndicesDenseDFAAt(haystack, 0, nil)
+}
+
+// findIndicesDenseDFAAt searches using the dense DFAs starting at position
+// 'at' - zero alloc. The forward DFA finds the leftmost-first match end and
+// the reverse DFA, run back from it, the match start.
+//
+// state is only used in Longest (POSIX) mode, which the leftmost-first DFA
+// cannot do, for the PikeVM; if nil, a pooled state is taken.
+func (e *Engine) findIndicesDenseDFAAt(haystack []byte, at int, state *SearchState) (int, int, bool) {
+	if e.longest {
+		if state == nil {
+			state = e.getSearchState(len(haystack))
+			defer e.putSearchState(state)
+		}
+		e.stats.inc(statNFASearches)
+		return state.pikevm.SearchAt(haystack, at)
+	}
+	e.stats.inc(statDFASearches)
+
+	// Prefilter skip-ahead: a candidate is at or before the leftmost match
+	// start, so the unanchored forward search from it finds the same match.
+	// Partial-coverage prefilters would skip unrepresented branches.
+	if e.prefilter != nil && !e.prefilterPartialCoverage {
+		pos := e.prefilter.Find(haystack, at)
+		if pos == -1 {
+			return -1, -1, false
+		}
+		e.stats.inc(statPrefilterHits)
+		at = pos
+	}
+
+	end := e.denseDFA.SearchAt(haystack, at)
+	if end < 0 {
+		return -1, -1, false
+	}
+	if end == at || e.denseReverseDFA == nil {
+		return at, end, true
+	}
+	start := e.denseReverseDFA.SearchReverse(haystack, at, end)
+	if start < 0 {
+		// Unreachable: the forward match guarantees a reverse one.
+		return at, end, true
+	}
+	return start, end, true
+}
+
+// isMatchDenseDFA checks for a match using the forward dense DFA, stopping
+// at the first match state.
+func (e *Engine) isMatchDenseDFA(haystack []byte) bool {
+	e.stats.inc(statDFASearches)
+	at := 0
+	if e.prefilter != nil && !e.prefilterPartialCoverage {
+		if at = e.prefilter.Find(haystack, 0); at == -1 {
+			return false
+		}
+		e.stats.inc(statPrefilterHits)
+	}
+	return e.denseDFA.IsMatchAt(haystack, at)
+}
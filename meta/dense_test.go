@@ -0,0 +1,121 @@
+package meta
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+var denseStrategyPatterns = []string{
+	`a+b+c+`,
+	`(a|b)*abb`,
+	`(foo|bar)\d+`,
+	`x+y?z`,
+	`[^\n]*x`,
+	`(a|ab)(c|bcd)`,
+	`(a+?)(b*)`,
+	`(?:ab)*?c`,
+	`é+|ü`,
+}
+
+func TestDenseDFAStrategy(t *testing.T) {
+	inputs := []string{
+		"",
+		"aabbcc abc ac",
+		"abababb aabb abb",
+		"foo1 bar22 baz333 foo",
+		"xz xyz xyyz xxxz",
+		"line\nwith x\nno",
+		"abcd abc ab",
+		"aab abbb b",
+		"ababc abcc c",
+		"ééé ü e u",
+	}
+	for _, pattern := range denseStrategyPatterns {
+		engine, err := Compile(pattern)
+		if err != nil {
+			t.Fatalf("Compile(%q): %v", pattern, err)
+		}
+		if engine.Strategy() != UseDenseDFA {
+			t.Errorf("%q: strategy %s, want UseDenseDFA", pattern, engine.Strategy())
+			continue
+		}
+		re := regexp.MustCompile(pattern)
+		for _, in := range inputs {
+			h := []byte(in)
+			if got, want := engine.FindAllIndicesStreaming(h, -1, nil), re.FindAllIndex(h, -1); !equalIndices(got, want) {
+				t.Errorf("%q.FindAll(%q) = %v, want %v", pattern, in, got, want)
+			}
+			if got, want := submatches(engine, h), re.FindAllSubmatchIndex(h, -1); !reflect.DeepEqual(got, want) {
+				t.Errorf("%q.FindAllSubmatch(%q) = %v, want %v", pattern, in, got, want)
+			}
+			if got, want := engine.IsMatch(h), re.Match(h); got != want {
+				t.Errorf("%q.IsMatch(%q) = %v, want %v", pattern, in, got, want)
+			}
+			for at := 0; at <= len(h); at++ {
+				start, end, found := engine.FindIndicesAt(h, at)
+				want := re.FindIndex(h[at:])
+				if found != (want != nil) || found && (start != at+want[0] || end != at+want[1]) {
+					t.Errorf("%q.FindIndicesAt(%q, %d) = (%d, %d, %v), want %v", pattern, in, at, start, end, found, want)
+				}
+			}
+		}
+	}
+}
+
+// equalIndices compares FindAllIndicesStreaming results with regexp's.
+func equalIndices(got [][2]int, want [][]int) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i][0] != want[i][0] || got[i][1] != want[i][1] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestDenseDFAStrategyNotSelected(t *testing.T) {
+	disabled := DefaultConfig()
+	disabled.DenseDFAMaxStates = 0
+	tiny := DefaultConfig()
+	tiny.DenseDFAMaxStates = 4
+
+	tests := []struct {
+		name    string
+		pattern string
+		config  Config
+	}{
+		{"disabled", `(a|b)*abb`, disabled},
+		{"too many states", `(a|b)*abb`, tiny},
+		{"word boundary", `\b(a|b)*abb`, DefaultConfig()},
+		{"end anchor", `(a|b)*abb$`, DefaultConfig()},
+	}
+	for _, tt := range tests {
+		engine, err := CompileWithConfig(tt.pattern, tt.config)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if engine.Strategy() == UseDenseDFA {
+			t.Errorf("%s: %q selected UseDenseDFA", tt.name, tt.pattern)
+		}
+	}
+}
+
+func TestDenseDFAStrategyLongest(t *testing.T) {
+	engine, err := Compile(`(a|ab)(c|bcd)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if engine.Strategy() != UseDenseDFA {
+		t.Fatalf("strategy %s, want UseDenseDFA", engine.Strategy())
+	}
+	engine.SetLongest(true)
+	re := regexp.MustCompile(`(a|ab)(c|bcd)`)
+	re.Longest()
+	h := []byte("xabcd abc")
+	if got, want := engine.FindAllIndicesStreaming(h, -1, nil), re.FindAllIndex(h, -1); !equalIndices(got, want) {
+		t.Errorf("Longest FindAll = %v, want %v", got, want)
+	}
+}
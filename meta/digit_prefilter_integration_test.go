@@ -359,7 +359,7 @@ func TestDigitPrefilterAdaptiveSwitching(t *testing.T) {
 	// Using "999" - a 3-digit sequence that's rare in random digits
 	pattern := `999`
 
-	re, err := Compile(pattern)
+	re, err := CompileWithConfig(pattern, statsConfig())
 	if err != nil {
 		t.Fatalf("Compile failed: %v", err)
 	}
@@ -422,7 +422,7 @@ func TestDigitPrefilterAdaptiveSwitchingStats(t *testing.T) {
 	// and won't match most digit sequences
 	pattern := `99999` // 5 nines - very rare
 
-	re, err := Compile(pattern)
+	re, err := CompileWithConfig(pattern, statsConfig())
 	if err != nil {
 		t.Fatalf("Compile failed: %v", err)
 	}
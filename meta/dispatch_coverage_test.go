@@ -715,7 +715,7 @@ func TestSetLongestAndMatch(t *testing.T) {
 
 // TestStatsTracking tests that stats are properly tracked across operations.
 func TestStatsTracking(t *testing.T) {
-	engine, err := Compile(`\d+`)
+	engine, err := CompileWithConfig(`\d+`, statsConfig())
 	if err != nil {
 		t.Fatal(err)
 	}
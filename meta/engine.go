@@ -5,9 +5,11 @@
 package meta
 
 import (
+	"sync"
 	"sync/atomic"
 
 	"github.com/coregx/ahocorasick"
+	"github.com/coregx/coregex/dfa/dense"
 	"github.com/coregx/coregex/dfa/lazy"
 	"github.com/coregx/coregex/dfa/onepass"
 	"github.com/coregx/coregex/nfa"
@@ -45,10 +47,8 @@ import (
 //	    println(match.String()) // "foo123"
 //	}
 type Engine struct {
-	// Statistics (useful for debugging and tuning)
-	// IMPORTANT: stats MUST be first field for proper 8-byte alignment on 32-bit platforms.
-	// This ensures atomic operations on uint64 fields work correctly.
-	stats Stats
+	// stats are the execution statistics, nil unless Config.EnableStats.
+	stats *statCounters
 
 	nfa *nfa.NFA
 
@@ -121,6 +121,12 @@ type Engine struct {
 	reverseDFA    *lazy.DFA
 	nfaStateCount int // NFA state count for prefilter loop guard
 
+	// denseDFA and denseReverseDFA are the read-only DFAs of UseDenseDFA:
+	// the forward DFA finds the match end, the reverse DFA the start.
+	// denseReverseDFA is nil for start-anchored patterns.
+	denseDFA        *dense.DFA
+	denseReverseDFA *dense.DFA
+
 	// statePool provides thread-safe pooling of per-search mutable state.
 	// This enables concurrent searches on the same Engine instance.
 	statePool *searchStatePool
@@ -153,33 +159,26 @@ type Engine struct {
 	// same digit run produce the same result, so the inner loop can skip
 	// the entire run instead of trying each digit.
 	digitRunSkipSafe bool
-}
-
-// Stats tracks execution statistics for performance analysis.
-type Stats struct {
-	// NFASearches counts NFA (PikeVM) searches
-	NFASearches uint64
 
-	// DFASearches counts DFA searches
-	DFASearches uint64
+	// streamDFA is the forward lazy DFA used by reader searches (stream.go).
+	// Built on first use: most engines never search a reader.
+	streamOnce sync.Once
+	streamDFA  *lazy.DFA
 
-	// OnePassSearches counts OnePass DFA searches (for FindSubmatch)
-	OnePassSearches uint64
+	// pushDFA is streamDFA without BreakAtMatch, for push-mode Streams.
+	pushOnce sync.Once
+	pushDFA  *lazy.DFA
 
-	// AhoCorasickSearches counts Aho-Corasick automaton searches
-	AhoCorasickSearches uint64
+	// contextCaches pools *lazy.DFACache for streamDFA, used by the
+	// interruptible (context.go) and span (input.go) searches.
+	contextCaches sync.Pool
 
-	// PrefilterHits counts successful prefilter matches
-	PrefilterHits uint64
+	// pushCaches pools *lazy.DFACache for pushDFA, used by span searches
+	// that need every match end.
+	pushCaches sync.Pool
 
-	// PrefilterMisses counts prefilter candidates that didn't match
-	PrefilterMisses uint64
-
-	// PrefilterAbandoned counts times prefilter was abandoned due to high FP rate
-	PrefilterAbandoned uint64
-
-	// DFACacheFull counts times DFA fell back to NFA due to cache full
-	DFACacheFull uint64
+	// plan is what the engine was built from; AppendBinary saves it.
+	plan *compilePlan
 }
 
 // Strategy returns the execution strategy selected for this engine.
@@ -209,22 +208,9 @@ func (e *Engine) IsStartAnchoredWithFirstByteReject(haystack []byte) bool {
 		!e.anchoredFirstBytes.Contains(haystack[0])
 }
 
-// Stats returns execution statistics.
-//
-// Useful for performance analysis and debugging.
-//
-// Example:
-//
-//	stats := engine.Stats()
-//	println("NFA searches:", stats.NFASearches)
-//	println("DFA searches:", stats.DFASearches)
-func (e *Engine) Stats() Stats {
-	return e.stats
-}
-
-// ResetStats resets execution statistics to zero.
-func (e *Engine) ResetStats() {
-	e.stats = Stats{}
+// Config returns the configuration the engine was compiled with.
+func (e *Engine) Config() Config {
+	return e.config
 }
 
 // NumCaptures returns the number of capture groups in the pattern.
@@ -255,16 +241,18 @@ func (e *Engine) SetLongest(longest bool) {
 	}
 }
 
-// getSearchState retrieves a SearchState, trying the local GC-proof cache first.
+// getSearchState retrieves a SearchState for a search of a haystack of
+// haystackLen bytes, trying the local GC-proof cache first.
 // Caller must call putSearchState when done.
 // The returned state contains its own PikeVM instance for thread-safe concurrent use.
-func (e *Engine) getSearchState() *SearchState {
+func (e *Engine) getSearchState(haystackLen int) *SearchState {
 	// Fast path: grab from local cache (survives GC, zero-alloc steady state).
 	state := e.localState.Swap(nil)
 	if state == nil {
 		// Slow path: concurrent access or first call before eager init.
 		state = e.statePool.get()
 	}
+	state.haystackLen = haystackLen
 
 	// Initialize state for BoundedBacktracker if needed
 	if e.boundedBacktracker != nil && state.backtracker != nil {
@@ -287,6 +275,9 @@ func (e *Engine) putSearchState(state *SearchState) {
 		return
 	}
 	state.reset()
+	if e.stats != nil || e.config.Observer != nil {
+		e.observeCacheCounts(state.takeCacheCounts(), state.haystackLen)
+	}
 	// Try to store in local cache (GC-proof single slot).
 	if e.localState.CompareAndSwap(nil, state) {
 		return
@@ -230,7 +230,7 @@ func TestEngineSubexpNames(t *testing.T) {
 
 // TestEngineStatsTracking tests that Stats() tracks searches correctly.
 func TestEngineStatsTracking(t *testing.T) {
-	engine, err := Compile("hello")
+	engine, err := CompileWithConfig("hello", statsConfig())
 	if err != nil {
 		t.Fatalf("Compile() error: %v", err)
 	}
@@ -264,7 +264,7 @@ func TestEngineStatsTracking(t *testing.T) {
 
 // TestEngineStatsAfterFind tests stats tracking for Find operations.
 func TestEngineStatsAfterFind(t *testing.T) {
-	engine, err := Compile(`\d+`)
+	engine, err := CompileWithConfig(`\d+`, statsConfig())
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -640,6 +640,8 @@ func TestEngineFindIndices(t *testing.T) {
 		{"no match", "xyz", "abc def", 0, 0, false},
 		{"empty haystack", "a", "", 0, 0, false},
 		{"match at start", "^hello", "hello world", 0, 5, true},
+		{"non-greedy char class", `[a-z]+?`, "abc", 0, 1, true},
+		{"non-greedy composite", `[0-9]+?[0-9]`, "123", 0, 2, true},
 	}
 
 	for _, tt := range tests {
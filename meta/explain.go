@@ -0,0 +1,188 @@
+package meta
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/coregx/coregex/literal"
+	"github.com/coregx/coregex/nfa"
+	"github.com/coregx/coregex/prefilter"
+)
+
+// Plan describes how an Engine was built: the strategy it selected and why,
+// the NFAs, the literals and prefilter it searches with, and the auxiliary
+// engines it built. It holds what COREGEX_DEBUG prints at compile time.
+type Plan struct {
+	// Strategy is the final execution strategy.
+	Strategy Strategy
+
+	// Reason is the StrategyReason text for Strategy.
+	Reason string
+
+	// NFAStates is the number of states of the main NFA. RuneNFAStates and
+	// ASCIINFAStates count the states of the NFA with rune states for '.'
+	// and of the ASCII-only NFA; they are 0 if those were not built.
+	NFAStates      int
+	RuneNFAStates  int
+	ASCIINFAStates int
+
+	// Prefixes are the prefix literals the prefilter was built from, nil if
+	// the pattern has none.
+	Prefixes *literal.Seq
+
+	// Suffixes are the suffix literals of the pattern, and Inner the inner
+	// literals UseReverseInner would search for. Both are nil for
+	// start-anchored patterns or when prefilters are disabled, and Inner
+	// also when the pattern has no inner literal.
+	Suffixes *literal.Seq
+	Inner    *literal.Seq
+
+	// Prefilter describes the prefix prefilter. Its Kind is empty if the
+	// engine has none.
+	Prefilter PrefilterPlan
+
+	// OnePass reports whether a one-pass DFA was built for captures.
+	OnePass bool
+
+	// ReverseDFA reports whether the engine searches with a reverse DFA,
+	// either directly or in one of the reverse searchers.
+	ReverseDFA bool
+
+	// Engines lists the search engines that were built, PikeVM first.
+	Engines []string
+}
+
+// PrefilterPlan describes the prefilter of a Plan.
+type PrefilterPlan struct {
+	// Kind names the prefilter ("memchr", "memmem", "Teddy (SSSE3 slim)", ...).
+	Kind string
+
+	// IsFast, IsComplete and HeapBytes are the prefilter's Prefilter methods.
+	IsFast     bool
+	IsComplete bool
+	HeapBytes  int
+}
+
+// Explain returns how the engine was built. The literal sequences of the
+// Plan are copies, so the caller may modify them.
+//
+// Suffix and inner literals the builders did not need are extracted anew, so
+// Explain is meant for diagnostics and pattern admission rather than search
+// paths.
+func (e *Engine) Explain() Plan {
+	plan := Plan{
+		Strategy:  e.strategy,
+		NFAStates: e.nfa.States(),
+		OnePass:   e.onepass != nil,
+		ReverseDFA: e.reverseDFA != nil || e.denseReverseDFA != nil ||
+			e.reverseSearcher != nil || e.reverseSuffixSearcher != nil ||
+			e.reverseSuffixSetSearcher != nil || e.reverseInnerSearcher != nil ||
+			e.multilineReverseSuffixSearcher != nil,
+		Engines: e.engineNames(),
+	}
+	if e.runeNFA != nil {
+		plan.RuneNFAStates = e.runeNFA.States()
+	}
+	if e.asciiNFA != nil {
+		plan.ASCIINFAStates = e.asciiNFA.States()
+	}
+	p := e.plan
+	if e.nfa.HasLookaround() {
+		plan.Reason = lookaroundReason
+	} else {
+		plan.Reason = StrategyReason(e.strategy, e.nfa, p.prefixes, e.config)
+	}
+	if p.prefixes != nil && !p.prefixes.IsEmpty() {
+		plan.Prefixes = p.prefixes.Clone()
+	}
+	if e.config.EnablePrefilter && !e.isStartAnchored {
+		// The engines search with the literals the builders extracted; the
+		// plan must not hand those out.
+		suffixes, inner := p.suffixes, p.inner
+		if !p.hasSuffixes {
+			suffixes = p.extractor().ExtractSuffixes(p.re)
+		}
+		if !p.hasInner {
+			inner = p.extractor().ExtractInnerForReverseSearch(p.re)
+		}
+		plan.Suffixes = suffixes.Clone()
+		if inner != nil {
+			plan.Inner = inner.Literals.Clone()
+		}
+	}
+	if pf := e.prefilter; pf != nil {
+		plan.Prefilter = PrefilterPlan{
+			Kind:       prefilterName(pf),
+			IsFast:     pf.IsFast(),
+			IsComplete: pf.IsComplete(),
+			HeapBytes:  pf.HeapBytes(),
+		}
+	}
+	return plan
+}
+
+// NFA returns the main (byte-level, unanchored) NFA of the engine.
+func (e *Engine) NFA() *nfa.NFA {
+	return e.nfa
+}
+
+// engineNames lists the engines that were built, in the order of the
+// COREGEX_DEBUG output.
+func (e *Engine) engineNames() []string {
+	engines := []struct {
+		name  string
+		built bool
+	}{
+		{"PikeVM", e.pikevm != nil},
+		{"OnePass DFA", e.onepass != nil},
+		{"lazy DFA", e.dfa != nil},
+		{"reverse DFA", e.reverseDFA != nil},
+		{"dense DFA", e.denseDFA != nil},
+		{"reverse dense DFA", e.denseReverseDFA != nil},
+		{"BoundedBacktracker", e.boundedBacktracker != nil},
+		{"ASCII BoundedBacktracker", e.asciiBoundedBacktracker != nil},
+		{"CharClassSearcher", e.charClassSearcher != nil},
+		{"CompositeSearcher", e.compositeSearcher != nil},
+		{"CompositeSequenceDFA", e.compositeSequenceDFA != nil},
+		{"BranchDispatcher", e.branchDispatcher != nil},
+		{"ReverseAnchoredSearcher", e.reverseSearcher != nil},
+		{"ReverseSuffixSearcher", e.reverseSuffixSearcher != nil},
+		{"ReverseSuffixSetSearcher", e.reverseSuffixSetSearcher != nil},
+		{"ReverseInnerSearcher", e.reverseInnerSearcher != nil},
+		{"MultilineReverseSuffixSearcher", e.multilineReverseSuffixSearcher != nil},
+		{"DigitPrefilter", e.digitPrefilter != nil},
+		{"AhoCorasick", e.ahoCorasick != nil},
+		{"AhoCorasick (FatTeddy fallback)", e.fatTeddyFallback != nil},
+		{"AnchoredLiteral", e.anchoredLiteralInfo != nil},
+	}
+	var names []string
+	for _, engine := range engines {
+		if engine.built {
+			names = append(names, engine.name)
+		}
+	}
+	return names
+}
+
+// prefilterName returns a short name of the prefilter's kind, looking
+// through the anchor wrappers.
+func prefilterName(pf prefilter.Prefilter) string {
+	pf = prefilter.Unwrap(pf)
+	switch pf.(type) {
+	case *prefilter.Teddy:
+		return "Teddy (SSSE3 slim)"
+	case *prefilter.FatTeddy:
+		return "FatTeddy (AVX2 fat)"
+	case *prefilter.AhoCorasickPrefilter:
+		return "AhoCorasick (DFA)"
+	}
+	// Unexported types: *prefilter.memchrPrefilter → memchr
+	name := fmt.Sprintf("%T", pf)
+	if strings.Contains(name, "memchr") {
+		return "memchr"
+	}
+	if strings.Contains(name, "memmem") {
+		return "memmem"
+	}
+	return name
+}
@@ -0,0 +1,185 @@
+package meta
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+
+	"github.com/coregx/coregex/literal"
+)
+
+func TestExplain(t *testing.T) {
+	tests := []struct {
+		pattern    string
+		strategy   Strategy
+		prefilter  PrefilterPlan
+		prefixes   int
+		inner      int
+		onePass    bool
+		reverseDFA bool
+		runeNFA    bool
+		engines    []string
+	}{
+		{
+			pattern:    `(a|b)*abb`,
+			strategy:   UseDenseDFA,
+			reverseDFA: true,
+			engines:    []string{"dense DFA", "reverse dense DFA"},
+		},
+		{
+			pattern:    `hello`,
+			strategy:   UseDFA,
+			prefilter:  PrefilterPlan{Kind: "memmem", IsFast: true, IsComplete: true},
+			prefixes:   1,
+			reverseDFA: true,
+			engines:    []string{"lazy DFA", "reverse DFA"},
+		},
+		{
+			pattern:    `^(\d+)-(\w+)$`,
+			strategy:   UseBoundedBacktracker,
+			onePass:    true,
+			reverseDFA: true,
+			engines:    []string{"OnePass DFA", "BoundedBacktracker"},
+		},
+		{
+			pattern:  `\w+(?=x)`,
+			strategy: UseNFA,
+			engines:  []string{"lazy DFA"},
+		},
+		{
+			pattern:    `\w+@\w+\.com`,
+			strategy:   UseReverseSuffix,
+			inner:      1,
+			reverseDFA: true,
+			engines:    []string{"ReverseSuffixSearcher"},
+		},
+		{
+			pattern:    `\w+connection\w+`,
+			strategy:   UseReverseInner,
+			inner:      1,
+			reverseDFA: true,
+			engines:    []string{"ReverseInnerSearcher"},
+		},
+		{
+			pattern:    `x.y`,
+			strategy:   UseDenseDFA,
+			prefilter:  PrefilterPlan{Kind: "memchr", IsFast: true},
+			prefixes:   1,
+			reverseDFA: true,
+			runeNFA:    true,
+		},
+	}
+	for _, tt := range tests {
+		engine, err := Compile(tt.pattern)
+		if err != nil {
+			t.Fatalf("Compile(%q): %v", tt.pattern, err)
+		}
+		plan := engine.Explain()
+		if plan.Strategy != tt.strategy {
+			t.Errorf("%q: Strategy = %s, want %s", tt.pattern, plan.Strategy, tt.strategy)
+		}
+		if plan.Strategy != engine.Strategy() {
+			t.Errorf("%q: Strategy = %s, engine has %s", tt.pattern, plan.Strategy, engine.Strategy())
+		}
+		if plan.Reason == "" {
+			t.Errorf("%q: no Reason", tt.pattern)
+		}
+		if plan.NFAStates != engine.NFA().States() {
+			t.Errorf("%q: NFAStates = %d, want %d", tt.pattern, plan.NFAStates, engine.NFA().States())
+		}
+		if (plan.RuneNFAStates > 0) != tt.runeNFA {
+			t.Errorf("%q: RuneNFAStates = %d, want rune NFA %v", tt.pattern, plan.RuneNFAStates, tt.runeNFA)
+		}
+		pf := plan.Prefilter
+		pf.HeapBytes = 0
+		if pf != tt.prefilter {
+			t.Errorf("%q: Prefilter = %+v, want %+v", tt.pattern, plan.Prefilter, tt.prefilter)
+		}
+		if got := plan.Prefixes.Len(); got != tt.prefixes {
+			t.Errorf("%q: %d prefixes, want %d", tt.pattern, got, tt.prefixes)
+		}
+		if got := plan.Inner.Len(); got != tt.inner {
+			t.Errorf("%q: %d inner literals, want %d", tt.pattern, got, tt.inner)
+		}
+		if plan.OnePass != tt.onePass {
+			t.Errorf("%q: OnePass = %v, want %v", tt.pattern, plan.OnePass, tt.onePass)
+		}
+		if plan.ReverseDFA != tt.reverseDFA {
+			t.Errorf("%q: ReverseDFA = %v, want %v", tt.pattern, plan.ReverseDFA, tt.reverseDFA)
+		}
+		if len(plan.Engines) == 0 || plan.Engines[0] != "PikeVM" {
+			t.Errorf("%q: Engines = %v, want PikeVM first", tt.pattern, plan.Engines)
+		}
+		for _, name := range tt.engines {
+			if !slices.Contains(plan.Engines, name) {
+				t.Errorf("%q: Engines = %v, want %s", tt.pattern, plan.Engines, name)
+			}
+		}
+	}
+}
+
+func TestExplainLoaded(t *testing.T) {
+	for _, pattern := range []string{`(a|b)*abb`, `hello`, `\w+@\w+\.com`, `^(\d+)-(\w+)$`} {
+		engine, err := Compile(pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := engine.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		loaded, err := LoadEngine(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, got := engine.Explain(), loaded.Explain()
+		if got.Strategy != want.Strategy || got.Reason != want.Reason || got.Prefilter != want.Prefilter ||
+			got.OnePass != want.OnePass || got.ReverseDFA != want.ReverseDFA || !slices.Equal(got.Engines, want.Engines) {
+			t.Errorf("%q: loaded plan %+v, want %+v", pattern, got, want)
+		}
+	}
+}
+
+func TestExplainCopiesLiterals(t *testing.T) {
+	for _, pattern := range []string{`hello`, `\w+@\w+\.com`, `\w+connection\w+`, `(foo|bar)\d+`} {
+		engine, err := Compile(pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fresh, err := Compile(pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := fresh.Explain()
+		scribbled := engine.Explain()
+		for _, seq := range []*literal.Seq{scribbled.Prefixes, scribbled.Suffixes, scribbled.Inner} {
+			for i := range seq.Len() {
+				clear(seq.Get(i).Bytes)
+			}
+			seq.KeepFirstBytes(1)
+		}
+		got := engine.Explain()
+		for _, seqs := range [][2]*literal.Seq{{got.Prefixes, want.Prefixes}, {got.Suffixes, want.Suffixes}, {got.Inner, want.Inner}} {
+			if !seqEqual(seqs[0], seqs[1]) {
+				t.Errorf("%q: literals changed to %v after modifying a plan, want %v", pattern, seqs[0], seqs[1])
+			}
+		}
+		haystack := []byte("hello a@b.com xconnectiony bar42")
+		if !engine.IsMatch(haystack) {
+			t.Errorf("%q: no match after modifying a plan", pattern)
+		}
+	}
+}
+
+// seqEqual reports whether a and b hold the same literals.
+func seqEqual(a, b *literal.Seq) bool {
+	if a.Len() != b.Len() {
+		return false
+	}
+	for i := range a.Len() {
+		if x, y := a.Get(i), b.Get(i); !bytes.Equal(x.Bytes, y.Bytes) || x.Complete != y.Complete {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,546 @@
+package meta
+
+import (
+	"regexp/syntax"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/coregx/coregex/internal/scan"
+)
+
+// Extended syntax (Config.ExtendedSyntax) is lowered to regexp/syntax text
+// before parsing, so that the parser, the NFA compiler and the literal
+// extractors only ever see plain character classes:
+//
+//	[A&&B]      intersection of the class items A and B
+//	[A--B]      difference: A without B
+//	[a[bc]]     nested class, unioned with the other items
+//	\h, \H      horizontal whitespace and its complement
+//	\R          any line break: (?:\r\n|[\n\v\f\r\x{85}\x{2028}\x{2029}])
+//	\Z          end of text or before a final \n: (?=\n?\z)
+//	\p{Name}    a class of Config.Classes (also \P{Name}, \p{^Name}, \pN)
+//
+// The operators && and -- bind looser than the union of adjacent items and
+// are applied left to right, as in Rust's regex crate. In case-insensitive
+// mode (syntax.FoldCase or an inline (?i)), each operand is case folded
+// before it is combined, so [a-z--[A-E]] leaves out a-e as well.
+
+// horizontalSpace are the rune ranges of \h, as in PCRE.
+var horizontalSpace = []rune{
+	0x09, 0x09,
+	0x20, 0x20,
+	0xA0, 0xA0,
+	0x1680, 0x1680,
+	0x180E, 0x180E,
+	0x2000, 0x200A,
+	0x202F, 0x202F,
+	0x205F, 0x205F,
+	0x3000, 0x3000,
+}
+
+const (
+	// lineBreak is the lowering of \R. The leftmost-first alternation
+	// prefers \r\n over a lone \r.
+	lineBreak = `(?:\r\n|[\n\v\f\r\x{85}\x{2028}\x{2029}])`
+
+	// endBeforeNewline is the lowering of \Z.
+	endBeforeNewline = `(?=\n?\z)`
+)
+
+// extParser lowers the extended syntax of a pattern.
+type extParser struct {
+	pattern string
+	classes map[string]*unicode.RangeTable
+	flags   syntax.Flags // for the plain items of rewritten classes
+
+	// fold is true where the pattern is case-insensitive; folds holds the
+	// value to restore at the end of each open group.
+	fold  bool
+	folds []bool
+
+	b       strings.Builder
+	offsets []int
+	changed bool
+}
+
+// expandExtended lowers the extended syntax of pattern to regexp/syntax
+// text. offsets maps the result to pattern as in stripVerbose; both results
+// are pattern and nil if pattern uses no extended syntax.
+//
+// Character classes without extended syntax are kept as written, so that
+// the parser reports their errors.
+func expandExtended(pattern string, classes map[string]*unicode.RangeTable, flags syntax.Flags) (string, []int, error) {
+	p := &extParser{
+		pattern: pattern,
+		classes: classes,
+		flags:   flags &^ syntax.FoldCase,
+		fold:    flags&syntax.FoldCase != 0,
+		offsets: make([]int, 0, len(pattern)+1),
+	}
+	p.b.Grow(len(pattern))
+
+	sc := scan.New(pattern)
+	for {
+		tok, ok := sc.Next()
+		if !ok {
+			break
+		}
+		i := tok.Start
+		switch tok.Kind {
+		case scan.Quoted:
+			// Quoted text is kept verbatim up to and including \E.
+			p.keep(i, tok.End)
+
+		case scan.Escape:
+			n := escapeLen(pattern[i:])
+			esc := pattern[i : i+n]
+			switch esc {
+			case `\h`:
+				p.emit(formatClass(horizontalSpace, false), i, i+n)
+			case `\H`:
+				p.emit(formatClass(horizontalSpace, true), i, i+n)
+			case `\R`:
+				p.emit(lineBreak, i, i+n)
+			case `\Z`:
+				p.emit(endBeforeNewline, i, i+n)
+			default:
+				if ranges, negated, ok := p.namedClass(esc); ok {
+					p.emit(formatClass(ranges, negated), i, i+n)
+				} else {
+					p.keep(i, i+n)
+				}
+			}
+			sc.Seek(i + n)
+
+		case scan.ClassOpen:
+			end, ranges, negated, extended, err := p.parseClass(i)
+			if err != nil {
+				return "", nil, err
+			}
+			if extended {
+				p.emit(formatClass(ranges, negated), i, end)
+			} else {
+				p.keep(i, end)
+			}
+			sc.Seek(end)
+
+		default:
+			switch pattern[i] {
+			case '(':
+				p.openGroup(i)
+			case ')':
+				if n := len(p.folds); n > 0 {
+					p.fold, p.folds = p.folds[n-1], p.folds[:n-1]
+				}
+			}
+			p.keep(i, i+1)
+		}
+	}
+
+	if !p.changed {
+		return pattern, nil, nil
+	}
+	p.offsets = append(p.offsets, len(pattern))
+	return p.b.String(), p.offsets, nil
+}
+
+// openGroup tracks the case-insensitive mode at the group that opens at
+// pattern[i]. A flag group such as (?i) sets the mode up to the end of the
+// enclosing group, and (?i:re) only inside its own group.
+func (p *extParser) openGroup(i int) {
+	p.folds = append(p.folds, p.fold)
+	flags := p.pattern[i+1:]
+	if !strings.HasPrefix(flags, "?") {
+		return
+	}
+	fold, on := p.fold, true
+	for j := 1; j < len(flags); j++ {
+		switch flags[j] {
+		case 'i':
+			fold = on
+		case 'm', 's', 'U':
+		case '-':
+			on = false
+		case ':':
+			p.fold = fold
+			return
+		case ')':
+			// The group closes at once; the mode applies to the enclosing one.
+			p.fold = fold
+			p.folds[len(p.folds)-1] = fold
+			return
+		default:
+			return
+		}
+	}
+}
+
+// keep copies pattern[from:to] to the result.
+func (p *extParser) keep(from, to int) {
+	p.b.WriteString(p.pattern[from:to])
+	for i := from; i < to; i++ {
+		p.offsets = append(p.offsets, i)
+	}
+}
+
+// emit writes s to the result in place of pattern[from:to]. The bytes of s
+// map to from, except the last one, which maps to to-1 so that an error
+// ending at s quotes all of pattern[from:to].
+func (p *extParser) emit(s string, from, to int) {
+	p.b.WriteString(s)
+	for i := range len(s) {
+		if i == len(s)-1 {
+			p.offsets = append(p.offsets, to-1)
+		} else {
+			p.offsets = append(p.offsets, from)
+		}
+	}
+	p.changed = true
+}
+
+// parseClass parses the bracket expression at pattern[start] and returns
+// the offset after it and its rune ranges, which are only computed if it
+// uses extended syntax. A negated class returns its ranges before negation.
+//
+// An unterminated class is not an error here if it uses no extended syntax,
+// so that the parser reports it.
+func (p *extParser) parseClass(start int) (end int, ranges []rune, negated, extended bool, err error) {
+	pattern := p.pattern
+	i := start + 1
+	if i < len(pattern) && pattern[i] == '^' {
+		negated = true
+		i++
+	}
+
+	var (
+		acc   []rune          // the operands combined so far
+		op    string          // operator before the current operand, "" for the first
+		plain strings.Builder // plain items of the current operand, as written
+		extra []rune          // extended items of the current operand
+		items int             // number of items of the current operand
+	)
+	// finish combines the current operand, which ends before pattern[end],
+	// into acc.
+	finish := func(end int) error {
+		if items == 0 {
+			return &syntax.Error{Code: syntax.ErrInvalidCharClass, Expr: pattern[start:end]}
+		}
+		if !extended {
+			return nil
+		}
+		operand := extra
+		if plain.Len() > 0 {
+			set, err := p.classRanges("[" + plain.String() + "]")
+			if err != nil {
+				return err
+			}
+			operand = unionRanges(set, extra)
+		}
+		if p.fold {
+			operand = foldRanges(operand)
+		}
+		switch op {
+		case "":
+			acc = operand
+		case "&&":
+			acc = intersectRanges(acc, operand)
+		case "--":
+			acc = subtractRanges(acc, operand)
+		}
+		plain.Reset()
+		extra, items = nil, 0
+		return nil
+	}
+	// addExtra adds an extended item to the current operand.
+	addExtra := func(set []rune) {
+		extra = unionRanges(extra, set)
+		extended = true
+		items++
+	}
+
+	for first := true; ; first = false {
+		if i >= len(pattern) {
+			if extended {
+				return 0, nil, false, false, &syntax.Error{Code: syntax.ErrMissingBracket, Expr: pattern[start:]}
+			}
+			return len(pattern), nil, false, false, nil
+		}
+		c := pattern[i]
+		switch {
+		case c == ']' && !first:
+			i++
+			if err := finish(i); err != nil {
+				return 0, nil, false, false, err
+			}
+			return i, acc, negated, extended, nil
+
+		case strings.HasPrefix(pattern[i:], "&&") || strings.HasPrefix(pattern[i:], "--"):
+			extended = true
+			if err := finish(i + 2); err != nil {
+				return 0, nil, false, false, err
+			}
+			op = pattern[i : i+2]
+			i += 2
+
+		case strings.HasPrefix(pattern[i:], "[:") && strings.Contains(pattern[i+2:], ":]"):
+			// An ASCII class such as [:alpha:] may contain a ']'.
+			n := strings.Index(pattern[i+2:], ":]")
+			plain.WriteString(pattern[i : i+2+n+2])
+			items++
+			i += 2 + n + 2
+
+		case c == '[':
+			end, set, neg, ext, err := p.parseClass(i)
+			if err == nil && !ext {
+				set, err = p.classRanges(pattern[i:end])
+			} else if neg {
+				set = negateRanges(set)
+			}
+			if err != nil {
+				return 0, nil, false, false, err
+			}
+			i = end
+			addExtra(set)
+
+		case c == '\\':
+			n := escapeLen(pattern[i:])
+			esc := pattern[i : i+n]
+			switch {
+			case esc == `\h`:
+				addExtra(horizontalSpace)
+			case esc == `\H`:
+				addExtra(negateRanges(horizontalSpace))
+			default:
+				if set, neg, ok := p.namedClass(esc); ok {
+					if neg {
+						set = negateRanges(set)
+					}
+					addExtra(set)
+				} else {
+					plain.WriteString(esc)
+					items++
+				}
+			}
+			i += n
+
+		case c == '^':
+			// Only special at the start of a class; plain items are
+			// joined, so keep it literal.
+			plain.WriteString(`\^`)
+			items++
+			i++
+
+		default:
+			_, size := utf8.DecodeRuneInString(pattern[i:])
+			plain.WriteString(pattern[i : i+size])
+			items++
+			i += size
+		}
+	}
+}
+
+// classRanges returns the rune ranges of a bracket expression without
+// extended syntax, as the parser sees them.
+func (p *extParser) classRanges(class string) ([]rune, error) {
+	re, err := syntax.Parse(class, p.flags)
+	if err != nil {
+		return nil, err
+	}
+	switch re.Op {
+	case syntax.OpCharClass:
+		return unionRanges(nil, re.Rune), nil
+	case syntax.OpLiteral:
+		return []rune{re.Rune[0], re.Rune[0]}, nil
+	case syntax.OpAnyChar:
+		return []rune{0, unicode.MaxRune}, nil
+	case syntax.OpAnyCharNotNL:
+		return []rune{0, '\n' - 1, '\n' + 1, unicode.MaxRune}, nil
+	}
+	return nil, nil
+}
+
+// namedClass returns the ranges of a \p or \P escape naming a class of
+// Config.Classes. negated is true for \P{Name} and \p{^Name}.
+func (p *extParser) namedClass(esc string) (ranges []rune, negated, ok bool) {
+	if len(p.classes) == 0 || len(esc) < 3 || (esc[1] != 'p' && esc[1] != 'P') {
+		return nil, false, false
+	}
+	negated = esc[1] == 'P'
+	name := esc[2:]
+	if strings.HasPrefix(name, "{") && strings.HasSuffix(name, "}") {
+		name = name[1 : len(name)-1]
+		if strings.HasPrefix(name, "^") {
+			negated = !negated
+			name = name[1:]
+		}
+	}
+	table := p.classes[name]
+	if table == nil {
+		return nil, false, false
+	}
+	return tableRanges(table), negated, true
+}
+
+// escapeLen returns the length of the escape sequence at the start of s,
+// which starts with a backslash.
+func escapeLen(s string) int {
+	if len(s) < 2 {
+		return len(s)
+	}
+	switch s[1] {
+	case 'p', 'P', 'x':
+		if len(s) > 2 && s[2] == '{' {
+			if end := strings.IndexByte(s, '}'); end >= 0 {
+				return end + 1
+			}
+			return len(s)
+		}
+		if s[1] == 'x' {
+			return min(4, len(s))
+		}
+	}
+	_, size := utf8.DecodeRuneInString(s[1:])
+	n := 1 + size
+	if (s[1] == 'p' || s[1] == 'P') && n < len(s) {
+		_, size = utf8.DecodeRuneInString(s[n:])
+		n += size
+	}
+	return n
+}
+
+// formatClass returns a bracket expression matching ranges, or the other
+// runes if negated.
+func formatClass(ranges []rune, negated bool) string {
+	if len(ranges) == 0 {
+		// An empty class: no rune, or any rune if negated.
+		ranges, negated = []rune{0, unicode.MaxRune}, !negated
+	}
+	var b strings.Builder
+	b.WriteByte('[')
+	if negated {
+		b.WriteByte('^')
+	}
+	for i := 0; i < len(ranges); i += 2 {
+		writeClassRune(&b, ranges[i])
+		if ranges[i+1] != ranges[i] {
+			b.WriteByte('-')
+			writeClassRune(&b, ranges[i+1])
+		}
+	}
+	b.WriteByte(']')
+	return b.String()
+}
+
+// writeClassRune writes r as a \x{...} escape.
+func writeClassRune(b *strings.Builder, r rune) {
+	b.WriteString(`\x{`)
+	b.WriteString(strconv.FormatInt(int64(r), 16))
+	b.WriteByte('}')
+}
+
+// tableRanges returns the runes of a range table as sorted, merged ranges.
+func tableRanges(table *unicode.RangeTable) []rune {
+	var ranges []rune
+	for _, r := range table.R16 {
+		ranges = appendStrided(ranges, rune(r.Lo), rune(r.Hi), rune(r.Stride))
+	}
+	for _, r := range table.R32 {
+		ranges = appendStrided(ranges, rune(r.Lo), rune(r.Hi), rune(r.Stride))
+	}
+	return unionRanges(nil, ranges)
+}
+
+// appendStrided appends the runes lo, lo+stride, ... up to hi.
+func appendStrided(ranges []rune, lo, hi, stride rune) []rune {
+	if stride <= 1 {
+		return append(ranges, lo, hi)
+	}
+	for r := lo; r <= hi; r += stride {
+		ranges = append(ranges, r, r)
+	}
+	return ranges
+}
+
+// unionRanges returns the sorted, merged union of two lists of lo, hi pairs.
+func unionRanges(a, b []rune) []rune {
+	pairs := make([][2]rune, 0, (len(a)+len(b))/2)
+	for _, ranges := range [][]rune{a, b} {
+		for i := 0; i+1 < len(ranges); i += 2 {
+			pairs = append(pairs, [2]rune{ranges[i], ranges[i+1]})
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i][0] < pairs[j][0] })
+
+	var out []rune
+	for _, r := range pairs {
+		if n := len(out); n > 0 && r[0] <= out[n-1]+1 {
+			out[n-1] = max(out[n-1], r[1])
+			continue
+		}
+		out = append(out, r[0], r[1])
+	}
+	return out
+}
+
+// negateRanges returns the runes not in sorted, merged ranges.
+func negateRanges(ranges []rune) []rune {
+	var out []rune
+	next := rune(0)
+	for i := 0; i < len(ranges); i += 2 {
+		if ranges[i] > next {
+			out = append(out, next, ranges[i]-1)
+		}
+		next = ranges[i+1] + 1
+	}
+	if next <= unicode.MaxRune {
+		out = append(out, next, unicode.MaxRune)
+	}
+	return out
+}
+
+// intersectRanges returns the runes in both sorted, merged ranges.
+func intersectRanges(a, b []rune) []rune {
+	var out []rune
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		lo, hi := max(a[i], b[j]), min(a[i+1], b[j+1])
+		if lo <= hi {
+			out = append(out, lo, hi)
+		}
+		if a[i+1] < b[j+1] {
+			i += 2
+		} else {
+			j += 2
+		}
+	}
+	return out
+}
+
+// minFold and maxFold are the least and greatest runes with other case
+// variants, as in regexp/syntax.
+const (
+	minFold = 0x0041
+	maxFold = 0x1e943
+)
+
+// foldRanges returns sorted, merged ranges with the case variants of
+// their runes added (unicode.SimpleFold), as the parser folds a class in
+// case-insensitive mode.
+func foldRanges(ranges []rune) []rune {
+	out := ranges[:len(ranges):len(ranges)]
+	for i := 0; i < len(ranges); i += 2 {
+		for r := max(ranges[i], minFold); r <= min(ranges[i+1], maxFold); r++ {
+			for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+				out = append(out, f, f)
+			}
+		}
+	}
+	return unionRanges(nil, out)
+}
+
+// subtractRanges returns the runes of a that are not in b.
+func subtractRanges(a, b []rune) []rune {
+	return intersectRanges(a, negateRanges(b))
+}
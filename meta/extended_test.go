@@ -0,0 +1,168 @@
+package meta
+
+import (
+	"errors"
+	"reflect"
+	"regexp/syntax"
+	"testing"
+	"unicode"
+)
+
+func TestExpandExtended(t *testing.T) {
+	classes := map[string]*unicode.RangeTable{
+		"Hex":  unicode.ASCII_Hex_Digit,
+		"Even": {R16: []unicode.Range16{{Lo: '0', Hi: '8', Stride: 2}}},
+	}
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{`abc`, `abc`},
+		{`[a-c]\d`, `[a-c]\d`},
+		{`[a-z--[b-y]]`, `[\x{61}\x{7a}]`},
+		{`[a-z&&[x-~]]`, `[\x{78}-\x{7a}]`},
+		{`[^a-c--b]`, `[^\x{61}\x{63}]`},
+		{`[a-c--\w]`, `[^\x{0}-\x{10ffff}]`},
+		{`[a[x]]`, `[\x{61}\x{78}]`},
+		{`[a[^\x00-\x{10FFFE}]]`, `[\x{61}\x{10ffff}]`},
+		{`[a-c&&b-d&&c-e]`, `[\x{63}]`},
+		{`[a-f--b--d]`, `[\x{61}\x{63}\x{65}-\x{66}]`},
+		{`[a^--a]`, `[\x{5e}]`},
+		{`[]a--a]`, `[\x{5d}]`},
+		{`[[:digit:]--5]`, `[\x{30}-\x{34}\x{36}-\x{39}]`},
+		{`a\hb`, `a[\x{9}\x{20}\x{a0}\x{1680}\x{180e}\x{2000}-\x{200a}\x{202f}\x{205f}\x{3000}]b`},
+		{`\H`, `[^\x{9}\x{20}\x{a0}\x{1680}\x{180e}\x{2000}-\x{200a}\x{202f}\x{205f}\x{3000}]`},
+		{`[\h--\t]`, `[\x{20}\x{a0}\x{1680}\x{180e}\x{2000}-\x{200a}\x{202f}\x{205f}\x{3000}]`},
+		{`a\R`, `a` + lineBreak},
+		{`a\Z`, `a` + endBeforeNewline},
+		{`\A\z`, `\A\z`},
+		{`\p{Even}`, `[\x{30}\x{32}\x{34}\x{36}\x{38}]`},
+		{`\P{Even}`, `[^\x{30}\x{32}\x{34}\x{36}\x{38}]`},
+		{`\p{^Even}`, `[^\x{30}\x{32}\x{34}\x{36}\x{38}]`},
+		{`[\p{Hex}--[a-z\d]]`, `[\x{41}-\x{46}]`},
+		{`[\P{Hex}&&0-9g]`, `[\x{67}]`},
+		{`\p{L}\pN[\p{Greek}]`, `\p{L}\pN[\p{Greek}]`},
+		{`\Q\h[a--b]\E\h`, `\Q\h[a--b]\E[\x{9}\x{20}\x{a0}\x{1680}\x{180e}\x{2000}-\x{200a}\x{202f}\x{205f}\x{3000}]`},
+		{`\\h`, `\\h`},
+	}
+	for _, tt := range tests {
+		got, offsets, err := expandExtended(tt.pattern, classes, syntax.Perl)
+		if err != nil {
+			t.Errorf("expandExtended(%q): %v", tt.pattern, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("expandExtended(%q) = %q, want %q", tt.pattern, got, tt.want)
+			continue
+		}
+		if got == tt.pattern {
+			if offsets != nil {
+				t.Errorf("expandExtended(%q): offsets %v for an unchanged pattern", tt.pattern, offsets)
+			}
+			continue
+		}
+		if len(offsets) != len(got)+1 || offsets[len(got)] != len(tt.pattern) {
+			t.Errorf("expandExtended(%q): bad offsets %v", tt.pattern, offsets)
+		}
+	}
+}
+
+func TestRangeSetOps(t *testing.T) {
+	a := []rune{'a', 'f', 'x', 'z'}
+	b := []rune{'c', 'y'}
+	if got, want := unionRanges(a, b), []rune{'a', 'z'}; !reflect.DeepEqual(got, want) {
+		t.Errorf("union = %q, want %q", got, want)
+	}
+	if got, want := unionRanges(nil, []rune{'d', 'e', 'a', 'c', 'g', 'g'}), []rune{'a', 'e', 'g', 'g'}; !reflect.DeepEqual(got, want) {
+		t.Errorf("union of unsorted = %q, want %q", got, want)
+	}
+	if got, want := intersectRanges(a, b), []rune{'c', 'f', 'x', 'y'}; !reflect.DeepEqual(got, want) {
+		t.Errorf("intersect = %q, want %q", got, want)
+	}
+	if got, want := subtractRanges(a, b), []rune{'a', 'b', 'z', 'z'}; !reflect.DeepEqual(got, want) {
+		t.Errorf("subtract = %q, want %q", got, want)
+	}
+	if got, want := negateRanges([]rune{0, 'a', unicode.MaxRune, unicode.MaxRune}), []rune{'b', unicode.MaxRune - 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("negate = %q, want %q", got, want)
+	}
+	if got := negateRanges(negateRanges(a)); !reflect.DeepEqual(got, a) {
+		t.Errorf("double negation = %q, want %q", got, a)
+	}
+}
+
+func TestParseExtendedErrors(t *testing.T) {
+	extended := DefaultConfig()
+	extended.ExtendedSyntax = true
+	verbose := extended
+	verbose.Verbose = true
+
+	tests := []struct {
+		pattern string
+		config  Config
+		code    syntax.ErrorCode
+		expr    string
+		offset  int
+	}{
+		{`x[a&&]`, extended, syntax.ErrInvalidCharClass, `[a&&]`, 1},
+		{`[--a]`, extended, syntax.ErrInvalidCharClass, `[--`, 0},
+		{`x[a--[b]`, extended, syntax.ErrMissingBracket, `[a--[b]`, 1},
+		{`[z-a--b]`, extended, syntax.ErrInvalidCharRange, `z-a`, 1},
+		{`[a\R]`, extended, syntax.ErrInvalidEscape, `\R`, 2},
+		{`\h**`, extended, syntax.ErrInvalidRepeatOp, `**`, 2},
+		{`[a--b]c**`, extended, syntax.ErrInvalidRepeatOp, `**`, 7},
+		{"\\h  [a -- b]  c**  # x", verbose, syntax.ErrInvalidRepeatOp, `**`, 15},
+		{"\\R  (  # open", verbose, syntax.ErrMissingParen, "\\R  (", 0},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.pattern, tt.config)
+		var cerr *CompileError
+		var serr *syntax.Error
+		if !errors.As(err, &cerr) || !errors.As(err, &serr) {
+			t.Errorf("Parse(%q) = %v, want a *CompileError wrapping a *syntax.Error", tt.pattern, err)
+			continue
+		}
+		if serr.Code != tt.code || serr.Expr != tt.expr || cerr.Offset != tt.offset {
+			t.Errorf("Parse(%q): %s %q at %d, want %s %q at %d",
+				tt.pattern, serr.Code, serr.Expr, cerr.Offset, tt.code, tt.expr, tt.offset)
+		}
+	}
+}
+
+func TestParseExtendedIgnored(t *testing.T) {
+	config := DefaultConfig()
+	config.Classes = map[string]*unicode.RangeTable{"Hex": unicode.ASCII_Hex_Digit}
+
+	// Without ExtendedSyntax the pattern means what regexp/syntax says.
+	re, err := Parse(`[+--]`, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, _ := syntax.Parse(`[+--]`, syntax.Perl); re.String() != want.String() {
+		t.Errorf("Parse = %v, want %v", re, want)
+	}
+	if _, err := Parse(`\h`, config); err == nil {
+		t.Error(`\h parsed without ExtendedSyntax`)
+	}
+
+	config.ExtendedSyntax = true
+	config.SyntaxFlags = syntax.Perl | syntax.Literal
+	if re, err := Parse(`\h`, config); err != nil || re.Op != syntax.OpLiteral {
+		t.Errorf(`Parse literal \h = %v, %v; want a literal`, re, err)
+	}
+}
+
+func TestConfigValidateClasses(t *testing.T) {
+	for _, classes := range []map[string]*unicode.RangeTable{
+		{"": unicode.Latin},
+		{"a}b": unicode.Latin},
+		{"^a": unicode.Latin},
+		{"Nil": nil},
+	} {
+		config := DefaultConfig()
+		config.Classes = classes
+		var cerr *ConfigError
+		if err := config.Validate(); !errors.As(err, &cerr) || cerr.Field != "Classes" {
+			t.Errorf("Validate(%v) = %v, want a Classes error", classes, err)
+		}
+	}
+}
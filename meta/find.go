@@ -5,8 +5,6 @@
 package meta
 
 import (
-	"sync/atomic"
-
 	"github.com/coregx/coregex/prefilter"
 	"github.com/coregx/coregex/simd"
 )
@@ -46,6 +44,19 @@ func (e *Engine) FindAt(haystack []byte, at int) *Match {
 	if at > len(haystack) {
 		return nil
 	}
+	m := e.findAt(haystack, at)
+	if e.stats != nil {
+		end := -1
+		if m != nil {
+			end = m.End()
+		}
+		e.stats.scanned(haystack, at, end)
+	}
+	return m
+}
+
+// findAt is FindAt without statistics.
+func (e *Engine) findAt(haystack []byte, at int) *Match {
 
 	// Early impossibility check: anchored pattern can only match at position 0
 	if at > 0 && e.nfa.IsAlwaysAnchored() {
@@ -97,6 +108,8 @@ func (e *Engine) findAtZero(haystack []byte) *Match {
 		return e.findAhoCorasick(haystack)
 	case UseAnchoredLiteral:
 		return e.findAnchoredLiteral(haystack)
+	case UseDenseDFA:
+		return e.findDenseDFA(haystack)
 	default:
 		return e.findNFA(haystack)
 	}
@@ -149,6 +162,8 @@ func (e *Engine) findAtNonZero(haystack []byte, at int) *Match {
 		// Start-anchored patterns can only match at position 0
 		// This case should not be reached due to early check in FindAt
 		return nil
+	case UseDenseDFA:
+		return e.findDenseDFAAt(haystack, at)
 	default:
 		return e.findNFAAt(haystack, at)
 	}
@@ -157,9 +172,9 @@ func (e *Engine) findAtNonZero(haystack []byte, at int) *Match {
 // findNFA searches using NFA (PikeVM) directly.
 // Thread-safe: uses pooled PikeVM instance.
 func (e *Engine) findNFA(haystack []byte) *Match {
-	atomic.AddUint64(&e.stats.NFASearches, 1)
+	e.stats.inc(statNFASearches)
 
-	state := e.getSearchState()
+	state := e.getSearchState(len(haystack))
 	defer e.putSearchState(state)
 
 	start, end, matched := state.pikevm.Search(haystack)
@@ -172,7 +187,7 @@ func (e *Engine) findNFA(haystack []byte) *Match {
 
 // findDFA searches using DFA with prefilter and NFA fallback.
 func (e *Engine) findDFA(haystack []byte) *Match {
-	atomic.AddUint64(&e.stats.DFASearches, 1)
+	e.stats.inc(statDFASearches)
 
 	// If prefilter available, use it to find candidate positions quickly
 	if e.prefilter != nil {
@@ -180,7 +195,7 @@ func (e *Engine) findDFA(haystack []byte) *Match {
 		if pos == -1 {
 			return nil
 		}
-		atomic.AddUint64(&e.stats.PrefilterHits, 1)
+		e.stats.inc(statPrefilterHits)
 
 		// Literal fast path: if prefilter is complete and we know literal length
 		if e.prefilter.IsComplete() {
@@ -201,7 +216,7 @@ func (e *Engine) findDFA(haystack []byte) *Match {
 	}
 
 	// Use DFA search with pooled cache
-	state := e.getSearchState()
+	state := e.getSearchState(len(haystack))
 	endPos := e.dfa.Find(state.dfaCache, haystack)
 	e.putSearchState(state)
 	if endPos == -1 {
@@ -234,8 +249,8 @@ func (e *Engine) findAdaptive(haystack []byte) *Match {
 			if start == -1 {
 				return nil
 			}
-			atomic.AddUint64(&e.stats.PrefilterHits, 1)
-			atomic.AddUint64(&e.stats.DFASearches, 1)
+			e.stats.inc(statPrefilterHits)
+			e.stats.inc(statDFASearches)
 			return NewMatch(start, end, haystack)
 		}
 
@@ -245,8 +260,8 @@ func (e *Engine) findAdaptive(haystack []byte) *Match {
 			// No candidate found - definitely no match
 			return nil
 		}
-		atomic.AddUint64(&e.stats.PrefilterHits, 1)
-		atomic.AddUint64(&e.stats.DFASearches, 1)
+		e.stats.inc(statPrefilterHits)
+		e.stats.inc(statDFASearches)
 
 		// Literal fast path: if prefilter is complete and we know literal length
 		if e.prefilter.IsComplete() {
@@ -267,8 +282,8 @@ func (e *Engine) findAdaptive(haystack []byte) *Match {
 
 	// Try DFA without prefilter
 	if e.dfa != nil {
-		atomic.AddUint64(&e.stats.DFASearches, 1)
-		state := e.getSearchState()
+		e.stats.inc(statDFASearches)
+		state := e.getSearchState(len(haystack))
 		endPos := e.dfa.Find(state.dfaCache, haystack)
 		if endPos != -1 {
 			e.putSearchState(state)
@@ -284,12 +299,7 @@ func (e *Engine) findAdaptive(haystack []byte) *Match {
 			}
 			return NewMatch(start, end, haystack)
 		}
-		// DFA failed (might be cache full) - check cache stats
-		size, capacity, _, _, _ := e.dfa.CacheStats(state.dfaCache)
 		e.putSearchState(state)
-		if size >= int(capacity)*9/10 { // 90% full
-			atomic.AddUint64(&e.stats.DFACacheFull, 1)
-		}
 	}
 
 	// Fall back to NFA
@@ -299,7 +309,7 @@ func (e *Engine) findAdaptive(haystack []byte) *Match {
 // findNFAAt searches using NFA starting from a specific position.
 // This preserves absolute positions for correct anchor handling.
 func (e *Engine) findNFAAt(haystack []byte, at int) *Match {
-	atomic.AddUint64(&e.stats.NFASearches, 1)
+	e.stats.inc(statNFASearches)
 	start, end, matched := e.pikevm.SearchAt(haystack, at)
 	if !matched {
 		return nil
@@ -310,7 +320,7 @@ func (e *Engine) findNFAAt(haystack []byte, at int) *Match {
 // findDFAAt searches using DFA starting from a specific position.
 // This preserves absolute positions for correct anchor handling.
 func (e *Engine) findDFAAt(haystack []byte, at int) *Match {
-	atomic.AddUint64(&e.stats.DFASearches, 1)
+	e.stats.inc(statDFASearches)
 
 	// If prefilter available and complete, use literal fast path
 	if e.prefilter != nil && e.prefilter.IsComplete() {
@@ -318,7 +328,7 @@ func (e *Engine) findDFAAt(haystack []byte, at int) *Match {
 		if pos == -1 {
 			return nil
 		}
-		atomic.AddUint64(&e.stats.PrefilterHits, 1)
+		e.stats.inc(statPrefilterHits)
 		// Literal fast path: prefilter already found exact match
 		// Use LiteralLen() to calculate end position directly
 		literalLen := e.prefilter.LiteralLen()
@@ -335,7 +345,7 @@ func (e *Engine) findDFAAt(haystack []byte, at int) *Match {
 	}
 
 	// Use DFA search with FindAt and pooled cache
-	state := e.getSearchState()
+	state := e.getSearchState(len(haystack))
 	pos := e.dfa.FindAt(state.dfaCache, haystack, at)
 	e.putSearchState(state)
 	if pos == -1 {
@@ -355,8 +365,8 @@ func (e *Engine) findDFAAt(haystack []byte, at int) *Match {
 func (e *Engine) findAdaptiveAt(haystack []byte, at int) *Match {
 	// Try DFA first
 	if e.dfa != nil {
-		atomic.AddUint64(&e.stats.DFASearches, 1)
-		state := e.getSearchState()
+		e.stats.inc(statDFASearches)
+		state := e.getSearchState(len(haystack))
 		pos := e.dfa.FindAt(state.dfaCache, haystack, at)
 		if pos != -1 {
 			e.putSearchState(state)
@@ -366,12 +376,7 @@ func (e *Engine) findAdaptiveAt(haystack []byte, at int) *Match {
 				return NewMatch(start, end, haystack)
 			}
 		} else {
-			// DFA failed (might be cache full) - check cache stats
-			size, capacity, _, _, _ := e.dfa.CacheStats(state.dfaCache)
 			e.putSearchState(state)
-			if size >= int(capacity)*9/10 { // 90% full
-				atomic.AddUint64(&e.stats.DFACacheFull, 1)
-			}
 		}
 	}
 
@@ -386,7 +391,7 @@ func (e *Engine) findReverseAnchored(haystack []byte) *Match {
 		return e.findNFA(haystack)
 	}
 
-	atomic.AddUint64(&e.stats.DFASearches, 1)
+	e.stats.inc(statDFASearches)
 	return e.reverseSearcher.Find(haystack)
 }
 
@@ -397,7 +402,7 @@ func (e *Engine) findReverseSuffix(haystack []byte) *Match {
 		return e.findNFA(haystack)
 	}
 
-	atomic.AddUint64(&e.stats.DFASearches, 1)
+	e.stats.inc(statDFASearches)
 	return e.reverseSuffixSearcher.Find(haystack)
 }
 
@@ -407,7 +412,7 @@ func (e *Engine) findReverseSuffixSet(haystack []byte) *Match {
 		return e.findNFA(haystack)
 	}
 
-	atomic.AddUint64(&e.stats.DFASearches, 1)
+	e.stats.inc(statDFASearches)
 	return e.reverseSuffixSetSearcher.Find(haystack)
 }
 
@@ -418,7 +423,7 @@ func (e *Engine) findReverseInner(haystack []byte) *Match {
 		return e.findNFA(haystack)
 	}
 
-	atomic.AddUint64(&e.stats.DFASearches, 1)
+	e.stats.inc(statDFASearches)
 	return e.reverseInnerSearcher.Find(haystack)
 }
 
@@ -430,7 +435,7 @@ func (e *Engine) findMultilineReverseSuffix(haystack []byte) *Match {
 		return e.findNFA(haystack)
 	}
 
-	atomic.AddUint64(&e.stats.DFASearches, 1)
+	e.stats.inc(statDFASearches)
 	return e.multilineReverseSuffixSearcher.Find(haystack)
 }
 
@@ -440,7 +445,7 @@ func (e *Engine) findMultilineReverseSuffixAt(haystack []byte, at int) *Match {
 		return e.findNFAAt(haystack, at)
 	}
 
-	atomic.AddUint64(&e.stats.DFASearches, 1)
+	e.stats.inc(statDFASearches)
 	return e.multilineReverseSuffixSearcher.FindAt(haystack, at)
 }
 
@@ -462,11 +467,11 @@ func (e *Engine) findBoundedBacktracker(haystack []byte) *Match {
 		}
 	}
 
-	atomic.AddUint64(&e.stats.NFASearches, 1)
+	e.stats.inc(statNFASearches)
 
 	// V11-002 ASCII optimization
 	if e.asciiBoundedBacktracker != nil && simd.IsASCII(haystack) {
-		if !e.asciiBoundedBacktracker.CanHandle(len(haystack)) {
+		if !e.canBacktrack(e.asciiBoundedBacktracker, len(haystack)) {
 			return e.findNFA(haystack)
 		}
 		start, end, found := e.asciiBoundedBacktracker.Search(haystack)
@@ -476,11 +481,11 @@ func (e *Engine) findBoundedBacktracker(haystack []byte) *Match {
 		return NewMatch(start, end, haystack)
 	}
 
-	if !e.boundedBacktracker.CanHandle(len(haystack)) {
+	if !e.canBacktrack(e.boundedBacktracker, len(haystack)) {
 		return e.findNFA(haystack)
 	}
 
-	state := e.getSearchState()
+	state := e.getSearchState(len(haystack))
 	defer e.putSearchState(state)
 	start, end, found := e.boundedBacktracker.SearchWithState(haystack, state.backtracker)
 	if !found {
@@ -501,7 +506,7 @@ func (e *Engine) findCharClassSearcher(haystack []byte) *Match {
 	if e.charClassSearcher == nil {
 		return e.findNFA(haystack)
 	}
-	atomic.AddUint64(&e.stats.NFASearches, 1) // Count as NFA-family for stats
+	e.stats.inc(statNFASearches) // Count as NFA-family for stats
 	start, end, found := e.charClassSearcher.Search(haystack)
 	if !found {
 		return nil
@@ -514,7 +519,7 @@ func (e *Engine) findCharClassSearcherAt(haystack []byte, at int) *Match {
 	if e.charClassSearcher == nil {
 		return e.findNFAAt(haystack, at)
 	}
-	atomic.AddUint64(&e.stats.NFASearches, 1)
+	e.stats.inc(statNFASearches)
 	start, end, found := e.charClassSearcher.SearchAt(haystack, at)
 	if !found {
 		return nil
@@ -527,7 +532,7 @@ func (e *Engine) findCompositeSearcher(haystack []byte) *Match {
 	if e.compositeSearcher == nil {
 		return e.findNFA(haystack)
 	}
-	atomic.AddUint64(&e.stats.NFASearches, 1) // Count as NFA-family for stats
+	e.stats.inc(statNFASearches) // Count as NFA-family for stats
 	start, end, found := e.compositeSearcher.Search(haystack)
 	if !found {
 		return nil
@@ -540,7 +545,7 @@ func (e *Engine) findCompositeSearcherAt(haystack []byte, at int) *Match {
 	if e.compositeSearcher == nil {
 		return e.findNFAAt(haystack, at)
 	}
-	atomic.AddUint64(&e.stats.NFASearches, 1)
+	e.stats.inc(statNFASearches)
 	start, end, found := e.compositeSearcher.SearchAt(haystack, at)
 	if !found {
 		return nil
@@ -554,7 +559,7 @@ func (e *Engine) findBranchDispatch(haystack []byte) *Match {
 	if e.branchDispatcher == nil {
 		return e.findBoundedBacktracker(haystack)
 	}
-	atomic.AddUint64(&e.stats.NFASearches, 1)
+	e.stats.inc(statNFASearches)
 	start, end, found := e.branchDispatcher.Search(haystack)
 	if !found {
 		return nil
@@ -589,7 +594,7 @@ func (e *Engine) findTeddy(haystack []byte) *Match {
 	// For Fat Teddy with small haystacks, use Aho-Corasick fallback.
 	// Fat Teddy's AVX2 SIMD setup overhead exceeds benefit on small inputs.
 	if e.fatTeddyFallback != nil && len(haystack) < fatTeddySmallHaystackThreshold {
-		atomic.AddUint64(&e.stats.AhoCorasickSearches, 1)
+		e.stats.inc(statAhoCorasickSearches)
 		match, found := e.fatTeddyFallback.Find(haystack, 0)
 		if !found {
 			return nil
@@ -597,7 +602,7 @@ func (e *Engine) findTeddy(haystack []byte) *Match {
 		return NewMatch(match.Start, match.End, haystack)
 	}
 
-	atomic.AddUint64(&e.stats.PrefilterHits, 1)
+	e.stats.inc(statPrefilterHits)
 
 	// Use FindMatch which returns both start and end positions
 	if matcher, ok := e.prefilter.(interface{ FindMatch([]byte, int) (int, int) }); ok {
@@ -629,7 +634,7 @@ func (e *Engine) findTeddyAt(haystack []byte, at int) *Match {
 
 	// For Fat Teddy with small haystacks, use Aho-Corasick fallback.
 	if e.fatTeddyFallback != nil && len(haystack) < fatTeddySmallHaystackThreshold {
-		atomic.AddUint64(&e.stats.AhoCorasickSearches, 1)
+		e.stats.inc(statAhoCorasickSearches)
 		match, found := e.fatTeddyFallback.FindAt(haystack, at)
 		if !found {
 			return nil
@@ -637,7 +642,7 @@ func (e *Engine) findTeddyAt(haystack []byte, at int) *Match {
 		return NewMatch(match.Start, match.End, haystack)
 	}
 
-	atomic.AddUint64(&e.stats.PrefilterHits, 1)
+	e.stats.inc(statPrefilterHits)
 
 	// Use FindMatch which returns both start and end positions
 	if matcher, ok := e.prefilter.(interface{ FindMatch([]byte, int) (int, int) }); ok {
@@ -680,11 +685,11 @@ func (e *Engine) findDigitPrefilter(haystack []byte) *Match {
 		return e.findNFA(haystack)
 	}
 
-	atomic.AddUint64(&e.stats.PrefilterHits, 1)
+	e.stats.inc(statPrefilterHits)
 	pos := 0
 
 	// Acquire pooled state once for the entire loop
-	state := e.getSearchState()
+	state := e.getSearchState(len(haystack))
 	defer e.putSearchState(state)
 
 	for pos < len(haystack) {
@@ -696,7 +701,7 @@ func (e *Engine) findDigitPrefilter(haystack []byte) *Match {
 
 		// Verify match at digit position using DFA
 		if e.dfa != nil {
-			atomic.AddUint64(&e.stats.DFASearches, 1)
+			e.stats.inc(statDFASearches)
 			endPos := e.dfa.FindAt(state.dfaCache, haystack, digitPos)
 			if endPos != -1 {
 				// DFA found potential match - get exact bounds from NFA
@@ -707,7 +712,7 @@ func (e *Engine) findDigitPrefilter(haystack []byte) *Match {
 			}
 		} else {
 			// No DFA - use PikeVM directly
-			atomic.AddUint64(&e.stats.NFASearches, 1)
+			e.stats.inc(statNFASearches)
 			start, end, found := state.pikevm.SearchAt(haystack, digitPos)
 			if found {
 				return NewMatch(start, end, haystack)
@@ -727,11 +732,11 @@ func (e *Engine) findDigitPrefilterAt(haystack []byte, at int) *Match {
 		return e.findNFAAt(haystack, at)
 	}
 
-	atomic.AddUint64(&e.stats.PrefilterHits, 1)
+	e.stats.inc(statPrefilterHits)
 	pos := at
 
 	// Acquire pooled state once for the entire loop
-	state := e.getSearchState()
+	state := e.getSearchState(len(haystack))
 	defer e.putSearchState(state)
 
 	for pos < len(haystack) {
@@ -741,7 +746,7 @@ func (e *Engine) findDigitPrefilterAt(haystack []byte, at int) *Match {
 		}
 
 		if e.dfa != nil {
-			atomic.AddUint64(&e.stats.DFASearches, 1)
+			e.stats.inc(statDFASearches)
 			endPos := e.dfa.FindAt(state.dfaCache, haystack, digitPos)
 			if endPos != -1 {
 				start, end, found := state.pikevm.SearchAt(haystack, digitPos)
@@ -750,7 +755,7 @@ func (e *Engine) findDigitPrefilterAt(haystack []byte, at int) *Match {
 				}
 			}
 		} else {
-			atomic.AddUint64(&e.stats.NFASearches, 1)
+			e.stats.inc(statNFASearches)
 			start, end, found := state.pikevm.SearchAt(haystack, digitPos)
 			if found {
 				return NewMatch(start, end, haystack)
@@ -770,7 +775,7 @@ func (e *Engine) findAhoCorasick(haystack []byte) *Match {
 	if e.ahoCorasick == nil {
 		return e.findNFA(haystack)
 	}
-	atomic.AddUint64(&e.stats.AhoCorasickSearches, 1)
+	e.stats.inc(statAhoCorasickSearches)
 
 	m, found := e.ahoCorasick.Find(haystack, 0)
 	if !found {
@@ -784,7 +789,7 @@ func (e *Engine) findAhoCorasickAt(haystack []byte, at int) *Match {
 	if e.ahoCorasick == nil || at >= len(haystack) {
 		return e.findNFAAt(haystack, at)
 	}
-	atomic.AddUint64(&e.stats.AhoCorasickSearches, 1)
+	e.stats.inc(statAhoCorasickSearches)
 
 	m, found := e.ahoCorasick.Find(haystack, at)
 	if !found {
@@ -6,9 +6,10 @@
 package meta
 
 import (
-	"sync/atomic"
+	"fmt"
 
 	"github.com/coregx/coregex/nfa"
+	"github.com/coregx/coregex/prefilter"
 	"github.com/coregx/coregex/simd"
 )
 
@@ -18,6 +19,13 @@ import (
 // This is a zero-allocation alternative to Find() - it returns indices
 // directly instead of creating a Match object.
 func (e *Engine) FindIndices(haystack []byte) (start, end int, found bool) {
+	start, end, found = e.findIndices(haystack)
+	e.stats.scanned(haystack, 0, end)
+	return start, end, found
+}
+
+// findIndices is FindIndices without statistics.
+func (e *Engine) findIndices(haystack []byte) (start, end int, found bool) {
 	switch e.strategy {
 	case UseNFA:
 		return e.findIndicesNFA(haystack)
@@ -51,6 +59,8 @@ func (e *Engine) FindIndices(haystack []byte) (start, end int, found bool) {
 		return e.findIndicesMultilineReverseSuffix(haystack)
 	case UseAnchoredLiteral:
 		return e.findIndicesAnchoredLiteral(haystack)
+	case UseDenseDFA:
+		return e.findIndicesDenseDFA(haystack)
 	default:
 		return e.findIndicesNFA(haystack)
 	}
@@ -59,6 +69,13 @@ func (e *Engine) FindIndices(haystack []byte) (start, end int, found bool) {
 // FindIndicesAt returns the start and end indices of the first match starting at position 'at'.
 // Returns (-1, -1, false) if no match is found.
 func (e *Engine) FindIndicesAt(haystack []byte, at int) (start, end int, found bool) {
+	start, end, found = e.findIndicesAt(haystack, at)
+	e.stats.scanned(haystack, at, end)
+	return start, end, found
+}
+
+// findIndicesAt is FindIndicesAt without statistics.
+func (e *Engine) findIndicesAt(haystack []byte, at int) (start, end int, found bool) {
 	// Early impossibility check: anchored pattern can only match at position 0
 	if at > 0 && e.nfa.IsAlwaysAnchored() {
 		return -1, -1, false
@@ -95,11 +112,32 @@ func (e *Engine) FindIndicesAt(haystack []byte, at int) (start, end int, found b
 		return e.findIndicesMultilineReverseSuffixAt(haystack, at)
 	case UseAnchoredLiteral:
 		return e.findIndicesAnchoredLiteralAt(haystack, at)
+	case UseDenseDFA:
+		return e.findIndicesDenseDFAAt(haystack, at, nil)
 	default:
 		return e.findIndicesNFAAt(haystack, at)
 	}
 }
 
+// nextCandidate returns the next candidate of the prefilter of tracker at or
+// after at, or -1 if there is none. It returns retired instead once the
+// tracker turns the prefilter off because too few of its candidates
+// matched: the caller then scans the rest of the haystack from at without
+// it, as Rust's regex retires an ineffective prefilter.
+func (e *Engine) nextCandidate(tracker *prefilter.Tracker, haystack []byte, at int) (pos int, retired bool) {
+	pos = tracker.Find(haystack, at)
+	if tracker.IsActive() {
+		return pos, false
+	}
+	e.stats.inc(statPrefilterAbandoned)
+	if e.config.Observer != nil {
+		candidates, confirms, _, _ := tracker.Stats()
+		e.observe(FallbackPrefilterRetired, len(haystack),
+			fmt.Sprintf("prefilter retired after %d of %d candidates matched", confirms, candidates))
+	}
+	return -1, true
+}
+
 // findIndicesNFA searches using NFA (PikeVM) directly - zero alloc.
 // Uses prefilter for skip-ahead when available (like Rust regex).
 //
@@ -108,7 +146,7 @@ func (e *Engine) FindIndicesAt(haystack []byte, at int) (start, end int, found b
 // so we must use PikeVM which correctly implements leftmost-first semantics.
 // Thread-safe: uses pooled state for both BoundedBacktracker and PikeVM.
 func (e *Engine) findIndicesNFA(haystack []byte) (int, int, bool) {
-	atomic.AddUint64(&e.stats.NFASearches, 1)
+	e.stats.inc(statNFASearches)
 
 	// BoundedBacktracker can be used for Find operations only when:
 	// 1. It's available
@@ -116,9 +154,13 @@ func (e *Engine) findIndicesNFA(haystack []byte) (int, int, bool) {
 	useBT := e.boundedBacktracker != nil && !e.canMatchEmpty
 
 	// Get pooled state for thread-safe execution
-	state := e.getSearchState()
+	state := e.getSearchState(len(haystack))
 	defer e.putSearchState(state)
 
+	if start, end, found, ok := e.findLookaround(haystack, 0, state); ok {
+		return start, end, found
+	}
+
 	// Use prefilter for candidate skip-ahead if available.
 	// Prefilter finds PREFIX positions → NFA/BT verifies full match from there.
 	// Safe for both complete and incomplete prefilters — as long as all
@@ -128,20 +170,24 @@ func (e *Engine) findIndicesNFA(haystack []byte) (int, int, bool) {
 	// candidate loop would miss unrepresented branches entirely.
 	// Rust avoids this by integrating prefilter inside PikeVM as skip-ahead
 	// (not as an external correctness gate). See pikevm.rs:1293-1299.
+	at := 0
 	if e.prefilter != nil && !e.prefilterPartialCoverage {
-		at := 0
+		tracker := state.prefilterTracker(e.prefilter)
 		for at < len(haystack) {
 			// Find next candidate position via prefilter
-			pos := e.prefilter.Find(haystack, at)
+			pos, retired := e.nextCandidate(tracker, haystack, at)
+			if retired {
+				break // Scan the rest without the prefilter
+			}
 			if pos == -1 {
 				return -1, -1, false // No more candidates
 			}
-			atomic.AddUint64(&e.stats.PrefilterHits, 1)
+			e.stats.inc(statPrefilterHits)
 
 			// Try to match at candidate position
 			var start, end int
 			var found bool
-			if useBT && e.boundedBacktracker.CanHandle(len(haystack)-pos) {
+			if useBT && e.canBacktrack(e.boundedBacktracker, len(haystack)-pos) {
 				start, end, found = e.boundedBacktracker.SearchAtWithState(haystack, pos, state.backtracker)
 			} else {
 				start, end, found = state.pikevm.SearchWithSlotTableAt(haystack, pos, nfa.SearchModeFind)
@@ -151,19 +197,21 @@ func (e *Engine) findIndicesNFA(haystack []byte) (int, int, bool) {
 			}
 
 			// Move past this position
-			atomic.AddUint64(&e.stats.PrefilterMisses, 1)
+			e.stats.inc(statPrefilterMisses)
 			at = pos + 1
 		}
-		return -1, -1, false
+		if tracker.IsActive() {
+			return -1, -1, false
+		}
 	}
 
-	// No prefilter: use BoundedBacktracker if available and safe
-	if useBT && e.boundedBacktracker.CanHandle(len(haystack)) {
-		return e.boundedBacktracker.SearchWithState(haystack, state.backtracker)
+	// No prefilter, or a retired one: use BoundedBacktracker if available and safe
+	if useBT && e.canBacktrack(e.boundedBacktracker, len(haystack)-at) {
+		return e.boundedBacktracker.SearchAtWithState(haystack, at, state.backtracker)
 	}
 
 	// Use optimized SlotTable-based search for large inputs
-	return state.pikevm.SearchWithSlotTable(haystack, nfa.SearchModeFind)
+	return state.pikevm.SearchWithSlotTableAt(haystack, at, nfa.SearchModeFind)
 }
 
 // findIndicesNFAAt searches using NFA starting at position - zero alloc.
@@ -171,27 +219,35 @@ func (e *Engine) findIndicesNFA(haystack []byte) (int, int, bool) {
 // Same BoundedBacktracker rules as findIndicesNFA.
 // Thread-safe: uses pooled state for both BoundedBacktracker and PikeVM.
 func (e *Engine) findIndicesNFAAt(haystack []byte, at int) (int, int, bool) {
-	atomic.AddUint64(&e.stats.NFASearches, 1)
+	e.stats.inc(statNFASearches)
 
 	// BoundedBacktracker can be used for Find operations only when safe
 	useBT := e.boundedBacktracker != nil && !e.canMatchEmpty
 
 	// Get pooled state for thread-safe execution
-	state := e.getSearchState()
+	state := e.getSearchState(len(haystack))
 	defer e.putSearchState(state)
 
+	if start, end, found, ok := e.findLookaround(haystack, at, state); ok {
+		return start, end, found
+	}
+
 	// Use prefilter candidate loop — safe unless partial coverage (overflow)
 	if e.prefilter != nil && !e.prefilterPartialCoverage {
+		tracker := state.prefilterTracker(e.prefilter)
 		for at < len(haystack) {
-			pos := e.prefilter.Find(haystack, at)
+			pos, retired := e.nextCandidate(tracker, haystack, at)
+			if retired {
+				break // Scan the rest without the prefilter
+			}
 			if pos == -1 {
 				return -1, -1, false
 			}
-			atomic.AddUint64(&e.stats.PrefilterHits, 1)
+			e.stats.inc(statPrefilterHits)
 
 			var start, end int
 			var found bool
-			if useBT && e.boundedBacktracker.CanHandle(len(haystack)-pos) {
+			if useBT && e.canBacktrack(e.boundedBacktracker, len(haystack)-pos) {
 				start, end, found = e.boundedBacktracker.SearchAtWithState(haystack, pos, state.backtracker)
 			} else {
 				start, end, found = state.pikevm.SearchWithSlotTableAt(haystack, pos, nfa.SearchModeFind)
@@ -200,14 +256,16 @@ func (e *Engine) findIndicesNFAAt(haystack []byte, at int) (int, int, bool) {
 				return start, end, true
 			}
 
-			atomic.AddUint64(&e.stats.PrefilterMisses, 1)
+			e.stats.inc(statPrefilterMisses)
 			at = pos + 1
 		}
-		return -1, -1, false
+		if tracker.IsActive() {
+			return -1, -1, false
+		}
 	}
 
-	// No prefilter or incomplete: use BoundedBacktracker if available and safe
-	if useBT && e.boundedBacktracker.CanHandle(len(haystack)-at) {
+	// No prefilter, an incomplete or a retired one: use BoundedBacktracker if available and safe
+	if useBT && e.canBacktrack(e.boundedBacktracker, len(haystack)-at) {
 		return e.boundedBacktracker.SearchAtWithState(haystack, at, state.backtracker)
 	}
 
@@ -217,7 +275,7 @@ func (e *Engine) findIndicesNFAAt(haystack []byte, at int) (int, int, bool) {
 
 // findIndicesDFA searches using DFA with prefilter - zero alloc.
 func (e *Engine) findIndicesDFA(haystack []byte) (int, int, bool) { //nolint:cyclop // DFA with prefilter paths
-	atomic.AddUint64(&e.stats.DFASearches, 1)
+	e.stats.inc(statDFASearches)
 
 	// Longest (POSIX) mode: DFA uses leftmost-first (break-at-match), which is
 	// incompatible with leftmost-longest semantics. Fall back to PikeVM.
@@ -231,7 +289,7 @@ func (e *Engine) findIndicesDFA(haystack []byte) (int, int, bool) { //nolint:cyc
 		if pos == -1 {
 			return -1, -1, false
 		}
-		atomic.AddUint64(&e.stats.PrefilterHits, 1)
+		e.stats.inc(statPrefilterHits)
 		literalLen := e.prefilter.LiteralLen()
 		if literalLen > 0 {
 			return pos, pos + literalLen, true
@@ -246,7 +304,7 @@ func (e *Engine) findIndicesDFA(haystack []byte) (int, int, bool) { //nolint:cyc
 		if pos == -1 {
 			return -1, -1, false
 		}
-		atomic.AddUint64(&e.stats.PrefilterHits, 1)
+		e.stats.inc(statPrefilterHits)
 		if e.reverseDFA != nil {
 			return e.findIndicesBidirectionalDFA(haystack, pos)
 		}
@@ -264,7 +322,7 @@ func (e *Engine) findIndicesDFA(haystack []byte) (int, int, bool) { //nolint:cyc
 	//   making candidate loop slower than single-pass DFA.
 	if e.prefilter != nil && e.reverseDFA != nil && e.nfaStateCount > 100 {
 		// Acquire state once for the candidate loop
-		state := e.getSearchState()
+		state := e.getSearchState(len(haystack))
 		defer e.putSearchState(state)
 		pos := 0
 		for pos < len(haystack) {
@@ -272,7 +330,7 @@ func (e *Engine) findIndicesDFA(haystack []byte) (int, int, bool) { //nolint:cyc
 			if candidate == -1 {
 				return -1, -1, false
 			}
-			atomic.AddUint64(&e.stats.PrefilterHits, 1)
+			e.stats.inc(statPrefilterHits)
 			// Complete prefilter: candidate IS the match
 			if e.prefilter.IsComplete() {
 				litLen := e.prefilter.LiteralLen()
@@ -311,7 +369,7 @@ func (e *Engine) findIndicesDFA(haystack []byte) (int, int, bool) { //nolint:cyc
 		if pos == -1 {
 			return -1, -1, false
 		}
-		atomic.AddUint64(&e.stats.PrefilterHits, 1)
+		e.stats.inc(statPrefilterHits)
 		return e.pikevm.SearchAt(haystack, pos)
 	}
 
@@ -319,7 +377,7 @@ func (e *Engine) findIndicesDFA(haystack []byte) (int, int, bool) { //nolint:cyc
 	if e.reverseDFA != nil {
 		return e.findIndicesBidirectionalDFA(haystack, 0)
 	}
-	state := e.getSearchState()
+	state := e.getSearchState(len(haystack))
 	matched := e.dfa.IsMatch(state.dfaCache, haystack)
 	e.putSearchState(state)
 	if !matched {
@@ -332,7 +390,7 @@ func (e *Engine) findIndicesDFA(haystack []byte) (int, int, bool) { //nolint:cyc
 
 // findIndicesDFAAt searches using DFA starting at position - zero alloc.
 func (e *Engine) findIndicesDFAAt(haystack []byte, at int) (int, int, bool) {
-	atomic.AddUint64(&e.stats.DFASearches, 1)
+	e.stats.inc(statDFASearches)
 
 	// Longest (POSIX) mode: DFA uses leftmost-first, fall back to PikeVM.
 	if e.longest {
@@ -345,7 +403,7 @@ func (e *Engine) findIndicesDFAAt(haystack []byte, at int) (int, int, bool) {
 		if pos == -1 {
 			return -1, -1, false
 		}
-		atomic.AddUint64(&e.stats.PrefilterHits, 1)
+		e.stats.inc(statPrefilterHits)
 		// Bidirectional DFA: forward DFA → end, reverse DFA → start. O(n) total.
 		if e.reverseDFA != nil {
 			return e.findIndicesBidirectionalDFA(haystack, pos)
@@ -356,7 +414,7 @@ func (e *Engine) findIndicesDFAAt(haystack []byte, at int) (int, int, bool) {
 	if e.reverseDFA != nil {
 		return e.findIndicesBidirectionalDFA(haystack, at)
 	}
-	state := e.getSearchState()
+	state := e.getSearchState(len(haystack))
 	matched := e.dfa.IsMatchAt(state.dfaCache, haystack, at)
 	e.putSearchState(state)
 	if !matched {
@@ -370,7 +428,7 @@ func (e *Engine) findIndicesDFAAt(haystack []byte, at int) (int, int, bool) {
 // findIndicesDFAAtWithState searches using DFA starting at position, reusing provided state.
 // Eliminates per-match sync.Pool overhead when called from FindAll/Count loops.
 func (e *Engine) findIndicesDFAAtWithState(haystack []byte, at int, state *SearchState) (int, int, bool) {
-	atomic.AddUint64(&e.stats.DFASearches, 1)
+	e.stats.inc(statDFASearches)
 
 	// Longest (POSIX) mode: DFA uses leftmost-first, fall back to PikeVM.
 	if e.longest {
@@ -383,7 +441,7 @@ func (e *Engine) findIndicesDFAAtWithState(haystack []byte, at int, state *Searc
 		if pos == -1 {
 			return -1, -1, false
 		}
-		atomic.AddUint64(&e.stats.PrefilterHits, 1)
+		e.stats.inc(statPrefilterHits)
 		// Bidirectional DFA: forward DFA → end, reverse DFA → start. O(n) total.
 		if e.reverseDFA != nil {
 			return e.findIndicesBidirectionalDFACore(haystack, pos, state)
@@ -412,8 +470,8 @@ func (e *Engine) findIndicesAdaptiveAtWithState(haystack []byte, at int, state *
 		if pos == -1 {
 			return -1, -1, false
 		}
-		atomic.AddUint64(&e.stats.PrefilterHits, 1)
-		atomic.AddUint64(&e.stats.DFASearches, 1)
+		e.stats.inc(statPrefilterHits)
+		e.stats.inc(statDFASearches)
 
 		// Literal fast path
 		if e.prefilter.IsComplete() {
@@ -429,7 +487,7 @@ func (e *Engine) findIndicesAdaptiveAtWithState(haystack []byte, at int, state *
 
 	// Try DFA without prefilter
 	if e.dfa != nil {
-		atomic.AddUint64(&e.stats.DFASearches, 1)
+		e.stats.inc(statDFASearches)
 		endPos := e.dfa.FindAt(state.dfaCache, haystack, at)
 		if endPos != -1 {
 			// Use estimated start for O(m) search
@@ -439,10 +497,6 @@ func (e *Engine) findIndicesAdaptiveAtWithState(haystack []byte, at int, state *
 			}
 			return state.pikevm.SearchAt(haystack, estimatedStart)
 		}
-		size, capacity, _, _, _ := e.dfa.CacheStats(state.dfaCache)
-		if size >= int(capacity)*9/10 {
-			atomic.AddUint64(&e.stats.DFACacheFull, 1)
-		}
 	}
 	return e.findIndicesNFAAtWithState(haystack, at, state)
 }
@@ -457,8 +511,8 @@ func (e *Engine) findIndicesAdaptive(haystack []byte) (int, int, bool) {
 			if start == -1 {
 				return -1, -1, false
 			}
-			atomic.AddUint64(&e.stats.PrefilterHits, 1)
-			atomic.AddUint64(&e.stats.DFASearches, 1)
+			e.stats.inc(statPrefilterHits)
+			e.stats.inc(statDFASearches)
 			return start, end, true
 		}
 
@@ -468,8 +522,8 @@ func (e *Engine) findIndicesAdaptive(haystack []byte) (int, int, bool) {
 			// No candidate found - definitely no match
 			return -1, -1, false
 		}
-		atomic.AddUint64(&e.stats.PrefilterHits, 1)
-		atomic.AddUint64(&e.stats.DFASearches, 1)
+		e.stats.inc(statPrefilterHits)
+		e.stats.inc(statDFASearches)
 
 		// Literal fast path
 		if e.prefilter.IsComplete() {
@@ -485,8 +539,8 @@ func (e *Engine) findIndicesAdaptive(haystack []byte) (int, int, bool) {
 
 	// Try DFA without prefilter
 	if e.dfa != nil {
-		atomic.AddUint64(&e.stats.DFASearches, 1)
-		state := e.getSearchState()
+		e.stats.inc(statDFASearches)
+		state := e.getSearchState(len(haystack))
 		endPos := e.dfa.Find(state.dfaCache, haystack)
 		if endPos != -1 {
 			e.putSearchState(state)
@@ -497,11 +551,7 @@ func (e *Engine) findIndicesAdaptive(haystack []byte) (int, int, bool) {
 			}
 			return e.pikevm.SearchAt(haystack, estimatedStart)
 		}
-		size, capacity, _, _, _ := e.dfa.CacheStats(state.dfaCache)
 		e.putSearchState(state)
-		if size >= int(capacity)*9/10 {
-			atomic.AddUint64(&e.stats.DFACacheFull, 1)
-		}
 	}
 	return e.findIndicesNFA(haystack)
 }
@@ -514,8 +564,8 @@ func (e *Engine) findIndicesAdaptiveAt(haystack []byte, at int) (int, int, bool)
 		if pos == -1 {
 			return -1, -1, false
 		}
-		atomic.AddUint64(&e.stats.PrefilterHits, 1)
-		atomic.AddUint64(&e.stats.DFASearches, 1)
+		e.stats.inc(statPrefilterHits)
+		e.stats.inc(statDFASearches)
 
 		// Literal fast path
 		if e.prefilter.IsComplete() {
@@ -531,8 +581,8 @@ func (e *Engine) findIndicesAdaptiveAt(haystack []byte, at int) (int, int, bool)
 
 	// Try DFA without prefilter
 	if e.dfa != nil {
-		atomic.AddUint64(&e.stats.DFASearches, 1)
-		state := e.getSearchState()
+		e.stats.inc(statDFASearches)
+		state := e.getSearchState(len(haystack))
 		endPos := e.dfa.FindAt(state.dfaCache, haystack, at)
 		if endPos != -1 {
 			e.putSearchState(state)
@@ -543,11 +593,7 @@ func (e *Engine) findIndicesAdaptiveAt(haystack []byte, at int) (int, int, bool)
 			}
 			return e.pikevm.SearchAt(haystack, estimatedStart)
 		}
-		size, capacity, _, _, _ := e.dfa.CacheStats(state.dfaCache)
 		e.putSearchState(state)
-		if size >= int(capacity)*9/10 {
-			atomic.AddUint64(&e.stats.DFACacheFull, 1)
-		}
 	}
 	return e.findIndicesNFAAt(haystack, at)
 }
@@ -557,7 +603,7 @@ func (e *Engine) findIndicesReverseAnchored(haystack []byte) (int, int, bool) {
 	if e.reverseSearcher == nil {
 		return e.findIndicesNFA(haystack)
 	}
-	atomic.AddUint64(&e.stats.DFASearches, 1)
+	e.stats.inc(statDFASearches)
 	match := e.reverseSearcher.Find(haystack)
 	if match == nil {
 		return -1, -1, false
@@ -570,7 +616,7 @@ func (e *Engine) findIndicesReverseSuffix(haystack []byte) (int, int, bool) {
 	if e.reverseSuffixSearcher == nil {
 		return e.findIndicesNFA(haystack)
 	}
-	atomic.AddUint64(&e.stats.DFASearches, 1)
+	e.stats.inc(statDFASearches)
 	match := e.reverseSuffixSearcher.Find(haystack)
 	if match == nil {
 		return -1, -1, false
@@ -583,7 +629,7 @@ func (e *Engine) findIndicesReverseSuffixAt(haystack []byte, at int) (int, int,
 	if e.reverseSuffixSearcher == nil {
 		return e.findIndicesNFAAt(haystack, at)
 	}
-	atomic.AddUint64(&e.stats.DFASearches, 1)
+	e.stats.inc(statDFASearches)
 	return e.reverseSuffixSearcher.FindIndicesAt(haystack, at)
 }
 
@@ -592,7 +638,7 @@ func (e *Engine) findIndicesReverseSuffixSet(haystack []byte) (int, int, bool) {
 	if e.reverseSuffixSetSearcher == nil {
 		return e.findIndicesNFA(haystack)
 	}
-	atomic.AddUint64(&e.stats.DFASearches, 1)
+	e.stats.inc(statDFASearches)
 	match := e.reverseSuffixSetSearcher.Find(haystack)
 	if match == nil {
 		return -1, -1, false
@@ -605,7 +651,7 @@ func (e *Engine) findIndicesReverseSuffixSetAt(haystack []byte, at int) (int, in
 	if e.reverseSuffixSetSearcher == nil {
 		return e.findIndicesNFAAt(haystack, at)
 	}
-	atomic.AddUint64(&e.stats.DFASearches, 1)
+	e.stats.inc(statDFASearches)
 	return e.reverseSuffixSetSearcher.FindIndicesAt(haystack, at)
 }
 
@@ -614,7 +660,7 @@ func (e *Engine) findIndicesReverseInner(haystack []byte) (int, int, bool) {
 	if e.reverseInnerSearcher == nil {
 		return e.findIndicesNFA(haystack)
 	}
-	atomic.AddUint64(&e.stats.DFASearches, 1)
+	e.stats.inc(statDFASearches)
 	match := e.reverseInnerSearcher.Find(haystack)
 	if match == nil {
 		return -1, -1, false
@@ -627,7 +673,7 @@ func (e *Engine) findIndicesReverseInnerAt(haystack []byte, at int) (int, int, b
 	if e.reverseInnerSearcher == nil {
 		return e.findIndicesNFAAt(haystack, at)
 	}
-	atomic.AddUint64(&e.stats.DFASearches, 1)
+	e.stats.inc(statDFASearches)
 	return e.reverseInnerSearcher.FindIndicesAt(haystack, at)
 }
 
@@ -636,7 +682,7 @@ func (e *Engine) findIndicesMultilineReverseSuffix(haystack []byte) (int, int, b
 	if e.multilineReverseSuffixSearcher == nil {
 		return e.findIndicesNFA(haystack)
 	}
-	atomic.AddUint64(&e.stats.DFASearches, 1)
+	e.stats.inc(statDFASearches)
 	return e.multilineReverseSuffixSearcher.FindIndicesAt(haystack, 0)
 }
 
@@ -663,7 +709,7 @@ func (e *Engine) findIndicesMultilineReverseSuffixAt(haystack []byte, at int) (i
 	if e.multilineReverseSuffixSearcher == nil {
 		return e.findIndicesNFAAt(haystack, at)
 	}
-	atomic.AddUint64(&e.stats.DFASearches, 1)
+	e.stats.inc(statDFASearches)
 	return e.multilineReverseSuffixSearcher.FindIndicesAt(haystack, at)
 }
 
@@ -674,8 +720,8 @@ func (e *Engine) findIndicesMultilineReverseSuffixAt(haystack []byte, at int) (i
 // leftmost-first greedy match ends directly (verified against Rust regex-automata
 // fwd search). No Phase 3 re-scan needed.
 func (e *Engine) findIndicesBidirectionalDFA(haystack []byte, at int) (int, int, bool) {
-	atomic.AddUint64(&e.stats.DFASearches, 1)
-	state := e.getSearchState()
+	e.stats.inc(statDFASearches)
+	state := e.getSearchState(len(haystack))
 	defer e.putSearchState(state)
 	return e.findIndicesBidirectionalDFACore(haystack, at, state)
 }
@@ -709,12 +755,12 @@ func (e *Engine) findIndicesBidirectionalDFACore(haystack []byte, at int, state
 // Used by BoundedBacktracker fallback where greedy semantics are required.
 // Accepts optional state to avoid redundant pool.Get when caller already has one.
 func (e *Engine) findIndicesBidirectionalDFALongest(haystack []byte, at int, existingState ...*SearchState) (int, int, bool) {
-	atomic.AddUint64(&e.stats.DFASearches, 1)
+	e.stats.inc(statDFASearches)
 	var state *SearchState
 	if len(existingState) > 0 && existingState[0] != nil {
 		state = existingState[0]
 	} else {
-		state = e.getSearchState()
+		state = e.getSearchState(len(haystack))
 		defer e.putSearchState(state)
 	}
 	end := e.dfa.SearchAt(state.dfaCache, haystack, at)
@@ -748,13 +794,13 @@ func (e *Engine) findIndicesBoundedBacktracker(haystack []byte) (int, int, bool)
 	// For always-anchored patterns (^) on large inputs where BT can't handle
 	// the full haystack, use PikeVM directly. PikeVM memory is O(states) per
 	// step, not O(states × haystack) like BT visited table.
-	if e.nfa.IsAlwaysAnchored() && !e.boundedBacktracker.CanHandle(len(haystack)) {
-		atomic.AddUint64(&e.stats.NFASearches, 1)
+	if e.nfa.IsAlwaysAnchored() && !e.canBacktrack(e.boundedBacktracker, len(haystack)) {
+		e.stats.inc(statNFASearches)
 		return e.pikevm.SearchWithSlotTable(haystack, nfa.SearchModeFind)
 	}
 
-	atomic.AddUint64(&e.stats.NFASearches, 1)
-	if !e.boundedBacktracker.CanHandle(len(haystack)) {
+	e.stats.inc(statNFASearches)
+	if !e.canBacktrack(e.boundedBacktracker, len(haystack)) {
 		// Bidirectional DFA: O(n) vs PikeVM's O(n*states) for large inputs
 		// Use longest variant to preserve greedy semantics for BoundedBacktracker patterns.
 		if e.dfa != nil && e.reverseDFA != nil {
@@ -763,7 +809,7 @@ func (e *Engine) findIndicesBoundedBacktracker(haystack []byte) (int, int, bool)
 		return e.pikevm.SearchWithSlotTable(haystack, nfa.SearchModeFind)
 	}
 
-	state := e.getSearchState()
+	state := e.getSearchState(len(haystack))
 	defer e.putSearchState(state)
 	return e.boundedBacktracker.SearchWithState(haystack, state.backtracker)
 }
@@ -782,7 +828,7 @@ func (e *Engine) findIndicesBoundedBacktrackerAt(haystack []byte, at int) (int,
 	if e.boundedBacktracker == nil {
 		return e.findIndicesNFAAt(haystack, at)
 	}
-	atomic.AddUint64(&e.stats.NFASearches, 1)
+	e.stats.inc(statNFASearches)
 
 	// Slice to remaining portion for more efficient BoundedBacktracker usage.
 	// This allows BT to handle large inputs in FindAll where we only need
@@ -798,7 +844,7 @@ func (e *Engine) findIndicesBoundedBacktrackerAt(haystack []byte, at int) (int,
 			asciiCheck = asciiCheck[:4096]
 		}
 		if simd.IsASCII(asciiCheck) {
-			if !e.asciiBoundedBacktracker.CanHandle(len(remaining)) {
+			if !e.canBacktrack(e.asciiBoundedBacktracker, len(remaining)) {
 				if e.dfa != nil && e.reverseDFA != nil {
 					return e.findIndicesBidirectionalDFALongest(haystack, at)
 				}
@@ -812,14 +858,14 @@ func (e *Engine) findIndicesBoundedBacktrackerAt(haystack []byte, at int) (int,
 		}
 	}
 
-	if !e.boundedBacktracker.CanHandle(len(remaining)) {
+	if !e.canBacktrack(e.boundedBacktracker, len(remaining)) {
 		if e.dfa != nil && e.reverseDFA != nil {
 			return e.findIndicesBidirectionalDFALongest(haystack, at)
 		}
 		return e.findIndicesNFAAt(haystack, at)
 	}
 
-	state := e.getSearchState()
+	state := e.getSearchState(len(haystack))
 	defer e.putSearchState(state)
 	start, end, found := e.boundedBacktracker.SearchWithState(remaining, state.backtracker)
 	if found {
@@ -833,7 +879,7 @@ func (e *Engine) findIndicesCharClassSearcher(haystack []byte) (int, int, bool)
 	if e.charClassSearcher == nil {
 		return e.findIndicesNFA(haystack)
 	}
-	atomic.AddUint64(&e.stats.NFASearches, 1)
+	e.stats.inc(statNFASearches)
 	return e.charClassSearcher.Search(haystack)
 }
 
@@ -842,7 +888,7 @@ func (e *Engine) findIndicesCharClassSearcherAt(haystack []byte, at int) (int, i
 	if e.charClassSearcher == nil {
 		return e.findIndicesNFAAt(haystack, at)
 	}
-	atomic.AddUint64(&e.stats.NFASearches, 1)
+	e.stats.inc(statNFASearches)
 	return e.charClassSearcher.SearchAt(haystack, at)
 }
 
@@ -850,13 +896,13 @@ func (e *Engine) findIndicesCharClassSearcherAt(haystack []byte, at int) (int, i
 func (e *Engine) findIndicesCompositeSearcher(haystack []byte) (int, int, bool) {
 	// Prefer DFA over backtracking (2-4x faster for overlapping patterns)
 	if e.compositeSequenceDFA != nil {
-		atomic.AddUint64(&e.stats.DFASearches, 1)
+		e.stats.inc(statDFASearches)
 		return e.compositeSequenceDFA.Search(haystack)
 	}
 	if e.compositeSearcher == nil {
 		return e.findIndicesNFA(haystack)
 	}
-	atomic.AddUint64(&e.stats.NFASearches, 1)
+	e.stats.inc(statNFASearches)
 	return e.compositeSearcher.Search(haystack)
 }
 
@@ -864,13 +910,13 @@ func (e *Engine) findIndicesCompositeSearcher(haystack []byte) (int, int, bool)
 func (e *Engine) findIndicesCompositeSearcherAt(haystack []byte, at int) (int, int, bool) {
 	// Prefer DFA over backtracking (2-4x faster for overlapping patterns)
 	if e.compositeSequenceDFA != nil {
-		atomic.AddUint64(&e.stats.DFASearches, 1)
+		e.stats.inc(statDFASearches)
 		return e.compositeSequenceDFA.SearchAt(haystack, at)
 	}
 	if e.compositeSearcher == nil {
 		return e.findIndicesNFAAt(haystack, at)
 	}
-	atomic.AddUint64(&e.stats.NFASearches, 1)
+	e.stats.inc(statNFASearches)
 	return e.compositeSearcher.SearchAt(haystack, at)
 }
 
@@ -879,7 +925,7 @@ func (e *Engine) findIndicesBranchDispatch(haystack []byte) (int, int, bool) {
 	if e.branchDispatcher == nil {
 		return e.findIndicesBoundedBacktracker(haystack)
 	}
-	atomic.AddUint64(&e.stats.NFASearches, 1)
+	e.stats.inc(statNFASearches)
 	return e.branchDispatcher.Search(haystack)
 }
 
@@ -898,7 +944,7 @@ func (e *Engine) findIndicesTeddy(haystack []byte) (int, int, bool) {
 		return e.findIndicesNFA(haystack)
 	}
 
-	atomic.AddUint64(&e.stats.PrefilterHits, 1)
+	e.stats.inc(statPrefilterHits)
 
 	// Use FindMatch which returns both start and end positions
 	if matcher, ok := e.prefilter.(interface{ FindMatch([]byte, int) (int, int) }); ok {
@@ -927,7 +973,7 @@ func (e *Engine) findIndicesTeddyAt(haystack []byte, at int) (int, int, bool) {
 		return e.findIndicesNFAAt(haystack, at)
 	}
 
-	atomic.AddUint64(&e.stats.PrefilterHits, 1)
+	e.stats.inc(statPrefilterHits)
 
 	// Use FindMatch which returns both start and end positions
 	if matcher, ok := e.prefilter.(interface{ FindMatch([]byte, int) (int, int) }); ok {
@@ -956,11 +1002,11 @@ func (e *Engine) findIndicesDigitPrefilter(haystack []byte) (int, int, bool) {
 		return e.findIndicesNFA(haystack)
 	}
 
-	atomic.AddUint64(&e.stats.PrefilterHits, 1)
+	e.stats.inc(statPrefilterHits)
 	pos := 0
 
 	// Acquire pooled state once for the entire loop
-	state := e.getSearchState()
+	state := e.getSearchState(len(haystack))
 	defer e.putSearchState(state)
 
 	for pos < len(haystack) {
@@ -970,7 +1016,7 @@ func (e *Engine) findIndicesDigitPrefilter(haystack []byte) (int, int, bool) {
 		}
 
 		if e.dfa != nil {
-			atomic.AddUint64(&e.stats.DFASearches, 1)
+			e.stats.inc(statDFASearches)
 			// Use anchored search - pattern MUST start at digitPos
 			// This is much faster than PikeVM for patterns that require digit start
 			endPos := e.dfa.SearchAtAnchored(state.dfaCache, haystack, digitPos)
@@ -978,7 +1024,7 @@ func (e *Engine) findIndicesDigitPrefilter(haystack []byte) (int, int, bool) {
 				return digitPos, endPos, true
 			}
 		} else {
-			atomic.AddUint64(&e.stats.NFASearches, 1)
+			e.stats.inc(statNFASearches)
 			start, end, found := state.pikevm.SearchAt(haystack, digitPos)
 			if found {
 				return start, end, true
@@ -1005,11 +1051,11 @@ func (e *Engine) findIndicesDigitPrefilterAt(haystack []byte, at int) (int, int,
 		return e.findIndicesNFAAt(haystack, at)
 	}
 
-	atomic.AddUint64(&e.stats.PrefilterHits, 1)
+	e.stats.inc(statPrefilterHits)
 	pos := at
 
 	// Acquire pooled state once for the entire loop
-	state := e.getSearchState()
+	state := e.getSearchState(len(haystack))
 	defer e.putSearchState(state)
 
 	for pos < len(haystack) {
@@ -1019,7 +1065,7 @@ func (e *Engine) findIndicesDigitPrefilterAt(haystack []byte, at int) (int, int,
 		}
 
 		if e.dfa != nil {
-			atomic.AddUint64(&e.stats.DFASearches, 1)
+			e.stats.inc(statDFASearches)
 			// Use anchored search - pattern MUST start at digitPos
 			// This is much faster than PikeVM for patterns that require digit start
 			endPos := e.dfa.SearchAtAnchored(state.dfaCache, haystack, digitPos)
@@ -1027,7 +1073,7 @@ func (e *Engine) findIndicesDigitPrefilterAt(haystack []byte, at int) (int, int,
 				return digitPos, endPos, true
 			}
 		} else {
-			atomic.AddUint64(&e.stats.NFASearches, 1)
+			e.stats.inc(statNFASearches)
 			start, end, found := state.pikevm.SearchAt(haystack, digitPos)
 			if found {
 				return start, end, true
@@ -1052,7 +1098,7 @@ func (e *Engine) findIndicesDigitPrefilterAtWithState(haystack []byte, at int, s
 		return e.findIndicesNFAAtWithState(haystack, at, state)
 	}
 
-	atomic.AddUint64(&e.stats.PrefilterHits, 1)
+	e.stats.inc(statPrefilterHits)
 	pos := at
 
 	for pos < len(haystack) {
@@ -1062,14 +1108,14 @@ func (e *Engine) findIndicesDigitPrefilterAtWithState(haystack []byte, at int, s
 		}
 
 		if e.dfa != nil {
-			atomic.AddUint64(&e.stats.DFASearches, 1)
+			e.stats.inc(statDFASearches)
 			// Use anchored search - pattern MUST start at digitPos
 			endPos := e.dfa.SearchAtAnchored(state.dfaCache, haystack, digitPos)
 			if endPos != -1 {
 				return digitPos, endPos, true
 			}
 		} else {
-			atomic.AddUint64(&e.stats.NFASearches, 1)
+			e.stats.inc(statNFASearches)
 			start, end, found := state.pikevm.SearchAt(haystack, digitPos)
 			if found {
 				return start, end, true
@@ -1092,7 +1138,7 @@ func (e *Engine) findIndicesAhoCorasick(haystack []byte) (int, int, bool) {
 	if e.ahoCorasick == nil {
 		return e.findIndicesNFA(haystack)
 	}
-	atomic.AddUint64(&e.stats.AhoCorasickSearches, 1)
+	e.stats.inc(statAhoCorasickSearches)
 
 	m, found := e.ahoCorasick.Find(haystack, 0)
 	if !found {
@@ -1106,7 +1152,7 @@ func (e *Engine) findIndicesAhoCorasickAt(haystack []byte, at int) (int, int, bo
 	if e.ahoCorasick == nil || at >= len(haystack) {
 		return e.findIndicesNFAAt(haystack, at)
 	}
-	atomic.AddUint64(&e.stats.AhoCorasickSearches, 1)
+	e.stats.inc(statAhoCorasickSearches)
 
 	m, found := e.ahoCorasick.Find(haystack, at)
 	if !found {
@@ -1138,10 +1184,13 @@ func (e *Engine) findIndicesAtWithState(haystack []byte, at int, state *SearchSt
 	case UseBoth:
 		return e.findIndicesAdaptiveAtWithState(haystack, at, state)
 	case UseReverseSuffix:
+		e.stats.inc(statDFASearches)
 		return e.reverseSuffixSearcher.FindIndicesAtWithCaches(haystack, at, state.stratFwdCache, state.stratRevCache)
 	case UseReverseSuffixSet:
+		e.stats.inc(statDFASearches)
 		return e.reverseSuffixSetSearcher.FindIndicesAtWithCaches(haystack, at, state.stratRevCache)
 	case UseReverseInner:
+		e.stats.inc(statDFASearches)
 		return e.reverseInnerSearcher.FindIndicesAtWithCaches(haystack, at, state.stratFwdCache, state.stratRevCache)
 	case UseBoundedBacktracker:
 		return e.findIndicesBoundedBacktrackerAtWithState(haystack, at, state)
@@ -1158,9 +1207,12 @@ func (e *Engine) findIndicesAtWithState(haystack []byte, at int, state *SearchSt
 	case UseAhoCorasick:
 		return e.findIndicesAhoCorasickAt(haystack, at)
 	case UseMultilineReverseSuffix:
+		e.stats.inc(statDFASearches)
 		return e.multilineReverseSuffixSearcher.FindIndicesAtWithCaches(haystack, at, state.stratFwdCache)
 	case UseAnchoredLiteral:
 		return e.findIndicesAnchoredLiteralAt(haystack, at)
+	case UseDenseDFA:
+		return e.findIndicesDenseDFAAt(haystack, at, state)
 	default:
 		return e.findIndicesNFAAtWithState(haystack, at, state)
 	}
@@ -1170,24 +1222,32 @@ func (e *Engine) findIndicesAtWithState(haystack []byte, at int, state *SearchSt
 // This is the state-reusing version for findAllIndicesLoop optimization.
 // Thread-safe: reuses provided state (no sync.Pool Get/Put).
 func (e *Engine) findIndicesNFAAtWithState(haystack []byte, at int, state *SearchState) (int, int, bool) {
-	atomic.AddUint64(&e.stats.NFASearches, 1)
+	e.stats.inc(statNFASearches)
 
 	// BoundedBacktracker can be used for Find operations only when safe
 	useBT := e.boundedBacktracker != nil && !e.canMatchEmpty
 
+	if start, end, found, ok := e.findLookaround(haystack, at, state); ok {
+		return start, end, found
+	}
+
 	// Use prefilter candidate loop — safe unless partial coverage (overflow).
 	// Partial-coverage prefilters would miss unrepresented branches.
 	if e.prefilter != nil && !e.prefilterPartialCoverage {
+		tracker := state.prefilterTracker(e.prefilter)
 		for at < len(haystack) {
-			pos := e.prefilter.Find(haystack, at)
+			pos, retired := e.nextCandidate(tracker, haystack, at)
+			if retired {
+				break // Scan the rest without the prefilter
+			}
 			if pos == -1 {
 				return -1, -1, false
 			}
-			atomic.AddUint64(&e.stats.PrefilterHits, 1)
+			e.stats.inc(statPrefilterHits)
 
 			var start, end int
 			var found bool
-			if useBT && e.boundedBacktracker.CanHandle(len(haystack)-pos) {
+			if useBT && e.canBacktrack(e.boundedBacktracker, len(haystack)-pos) {
 				start, end, found = e.boundedBacktracker.SearchAtWithState(haystack, pos, state.backtracker)
 			} else {
 				start, end, found = state.pikevm.SearchWithSlotTableAt(haystack, pos, nfa.SearchModeFind)
@@ -1196,14 +1256,16 @@ func (e *Engine) findIndicesNFAAtWithState(haystack []byte, at int, state *Searc
 				return start, end, true
 			}
 
-			atomic.AddUint64(&e.stats.PrefilterMisses, 1)
+			e.stats.inc(statPrefilterMisses)
 			at = pos + 1
 		}
-		return -1, -1, false
+		if tracker.IsActive() {
+			return -1, -1, false
+		}
 	}
 
-	// No prefilter or incomplete: use BoundedBacktracker if available and safe
-	if useBT && e.boundedBacktracker.CanHandle(len(haystack)-at) {
+	// No prefilter, an incomplete or a retired one: use BoundedBacktracker if available and safe
+	if useBT && e.canBacktrack(e.boundedBacktracker, len(haystack)-at) {
 		return e.boundedBacktracker.SearchAtWithState(haystack, at, state.backtracker)
 	}
 
@@ -1235,7 +1297,7 @@ func (e *Engine) findIndicesBoundedBacktrackerAtWithState(haystack []byte, at in
 		}
 	}
 
-	atomic.AddUint64(&e.stats.NFASearches, 1)
+	e.stats.inc(statNFASearches)
 
 	// Slice to remaining portion for more efficient BoundedBacktracker usage.
 	// This allows BT to handle large inputs in FindAll where we only need
@@ -1251,7 +1313,7 @@ func (e *Engine) findIndicesBoundedBacktrackerAtWithState(haystack []byte, at in
 			asciiCheck = asciiCheck[:4096]
 		}
 		if simd.IsASCII(asciiCheck) {
-			if !e.asciiBoundedBacktracker.CanHandle(len(remaining)) {
+			if !e.canBacktrack(e.asciiBoundedBacktracker, len(remaining)) {
 				// Bidirectional DFA: O(n) vs PikeVM's O(n*states)
 				if e.dfa != nil && e.reverseDFA != nil {
 					return e.findIndicesBidirectionalDFALongest(haystack, at, state)
@@ -1275,7 +1337,7 @@ func (e *Engine) findIndicesBoundedBacktrackerAtWithState(haystack []byte, at in
 		}
 	}
 
-	if !e.boundedBacktracker.CanHandle(len(remaining)) {
+	if !e.canBacktrack(e.boundedBacktracker, len(remaining)) {
 		// Bidirectional DFA: O(n) vs PikeVM's O(n*states) for large inputs
 		if e.dfa != nil && e.reverseDFA != nil {
 			return e.findIndicesBidirectionalDFALongest(haystack, at, state)
@@ -4,10 +4,6 @@
 
 package meta
 
-import (
-	"sync/atomic"
-)
-
 // FindSubmatch returns the first match with capture group information.
 // Returns nil if no match is found.
 //
@@ -52,10 +48,18 @@ func (e *Engine) FindSubmatchAt(haystack []byte, at int) *MatchWithCaptures {
 	}
 
 	// Get pooled state for thread-safe access
-	state := e.getSearchState()
+	state := e.getSearchState(len(haystack))
 	defer e.putSearchState(state)
 
-	return e.findSubmatchAtWithState(haystack, at, state)
+	m := e.findSubmatchAtWithState(haystack, at, state)
+	if e.stats != nil {
+		end := -1
+		if m != nil {
+			end = m.End()
+		}
+		e.stats.scanned(haystack, at, end)
+	}
+	return m
 }
 
 // findSubmatchAtWithState is the state-reusing internal version of FindSubmatchAt.
@@ -64,7 +68,7 @@ func (e *Engine) findSubmatchAtWithState(haystack []byte, at int, state *SearchS
 	// For position 0, try OnePass DFA if available (10-20x faster for anchored patterns).
 	// OnePass handles captures natively — no need for two-phase search.
 	if at == 0 && e.onepass != nil && state.onepassCache != nil {
-		atomic.AddUint64(&e.stats.OnePassSearches, 1)
+		e.stats.inc(statOnePassSearches)
 		slots := e.onepass.Search(haystack, state.onepassCache)
 		if slots != nil {
 			captures := slotsToCaptures(slots)
@@ -89,7 +93,7 @@ func (e *Engine) findSubmatchAtWithState(haystack []byte, at int, state *SearchS
 	switch e.strategy {
 	case UseBoundedBacktracker, UseNFA,
 		UseDFA, UseBoth, UseDigitPrefilter:
-		atomic.AddUint64(&e.stats.NFASearches, 1)
+		e.stats.inc(statNFASearches)
 		nfaMatch := state.pikevm.SearchWithSlotTableCapturesAt(haystack, at)
 		if nfaMatch == nil {
 			return nil
@@ -114,7 +118,7 @@ func (e *Engine) findSubmatchAtWithState(haystack []byte, at int, state *SearchS
 	// Phase 2: PikeVM extracts captures within the narrow [start, end] span.
 	// The full haystack is passed for lookbehind context (\b at span boundary),
 	// but PikeVM only processes bytes within [start, end].
-	atomic.AddUint64(&e.stats.NFASearches, 1)
+	e.stats.inc(statNFASearches)
 	nfaMatch := state.pikevm.SearchWithCapturesInSpan(haystack, start, end)
 	if nfaMatch == nil {
 		// Defensive fallback: DFA found a match but PikeVM disagrees.
@@ -127,6 +131,114 @@ func (e *Engine) findSubmatchAtWithState(haystack []byte, at int, state *SearchS
 	return NewMatchWithCaptures(haystack, nfaMatch.Captures)
 }
 
+// FindSubmatchSlotsAt is the allocation-free form of FindSubmatchAt. It
+// writes the first match starting at or after at into slots as
+// [start0, end0, start1, end1, ...], with -1, -1 for groups that did not
+// participate, and reports whether a match was found.
+//
+// slots must have room for 2*NumCaptures() entries. It is not modified if
+// there is no match.
+//
+// Loops over the matches of a haystack use a Searcher instead, which keeps
+// the look-around results of one search for the next.
+func (e *Engine) FindSubmatchSlotsAt(haystack []byte, at int, slots []int) bool {
+	s := e.NewSearcher(len(haystack))
+	defer s.Close()
+	return s.FindSubmatchSlotsAt(haystack, at, slots)
+}
+
+// Searcher runs the successive searches of one loop over a haystack, such
+// as the matches of ReplaceAll, on one search state, as FindAllSubmatch
+// does. The look-around results of a search carry over to the next, so the
+// loop runs each look-around body over the haystack once instead of once
+// per match.
+//
+// A Searcher is not safe for concurrent use. The haystack must not change
+// between its searches; call Close when the loop is done.
+type Searcher struct {
+	e     *Engine
+	state *SearchState
+}
+
+// NewSearcher returns a Searcher for a haystack of haystackLen bytes.
+func (e *Engine) NewSearcher(haystackLen int) Searcher {
+	return Searcher{e: e, state: e.getSearchState(haystackLen)}
+}
+
+// FindIndicesAt is Engine.FindIndicesAt on the state of s.
+func (s *Searcher) FindIndicesAt(haystack []byte, at int) (start, end int, found bool) {
+	s.state.haystackLen = len(haystack)
+	start, end, found = s.e.findIndicesAtWithState(haystack, at, s.state)
+	s.e.stats.scanned(haystack, at, end)
+	return start, end, found
+}
+
+// FindSubmatchSlotsAt is Engine.FindSubmatchSlotsAt on the state of s.
+func (s *Searcher) FindSubmatchSlotsAt(haystack []byte, at int, slots []int) bool {
+	if at > len(haystack) {
+		return false
+	}
+	e := s.e
+	s.state.haystackLen = len(haystack)
+	found := e.findSubmatchSlotsAtWithState(haystack, at, s.state, slots)
+	if e.stats != nil {
+		end := -1
+		if found {
+			end = slots[1]
+		}
+		e.stats.scanned(haystack, at, end)
+	}
+	return found
+}
+
+// Close returns the state of s to the engine. s must not be used after.
+func (s *Searcher) Close() {
+	s.e.putSearchState(s.state)
+	s.state = nil
+}
+
+// findSubmatchSlotsAtWithState mirrors findSubmatchAtWithState, using the
+// slot-writing forms of the OnePass and PikeVM searches.
+func (e *Engine) findSubmatchSlotsAtWithState(haystack []byte, at int, state *SearchState, slots []int) bool {
+	if at == 0 && e.onepass != nil && state.onepassCache != nil {
+		e.stats.inc(statOnePassSearches)
+		if found := e.onepass.Search(haystack, state.onepassCache); found != nil {
+			copy(slots, found)
+			for i := 0; i+1 < len(slots); i += 2 {
+				if slots[i] < 0 || slots[i+1] < 0 {
+					slots[i], slots[i+1] = -1, -1
+				}
+			}
+			return true
+		}
+	}
+
+	// See findSubmatchAtWithState for why these go straight to the PikeVM.
+	switch e.strategy {
+	case UseBoundedBacktracker, UseNFA,
+		UseDFA, UseBoth, UseDigitPrefilter:
+		e.stats.inc(statNFASearches)
+		return state.pikevm.SearchSlotsAt(haystack, at, slots)
+	}
+
+	start, end, found := e.findIndicesAtWithState(haystack, at, state)
+	if !found {
+		return false
+	}
+
+	if e.nfa.CaptureCount() <= 1 {
+		slots[0], slots[1] = start, end
+		return true
+	}
+
+	e.stats.inc(statNFASearches)
+	if state.pikevm.SearchSlotsInSpan(haystack, start, end, slots) {
+		return true
+	}
+	// Defensive fallback: DFA found a match but PikeVM disagrees.
+	return state.pikevm.SearchSlotsAt(haystack, at, slots)
+}
+
 // slotsToCaptures converts flat slots [start0, end0, start1, end1, ...]
 // to nested captures [[start0, end0], [start1, end1], ...].
 func slotsToCaptures(slots []int) [][]int {
@@ -153,12 +265,15 @@ func slotsToCaptures(slots []int) [][]int {
 //
 // This method is optimized for patterns like \w+, \d+, [a-z]+ where matches are frequent.
 func (e *Engine) FindAllIndicesStreaming(haystack []byte, n int, results [][2]int) [][2]int {
+	e.stats.searched(len(haystack))
+
 	// Only CharClassSearcher benefits from streaming - others use standard loop
 	if e.strategy != UseCharClassSearcher || e.charClassSearcher == nil {
 		return e.findAllIndicesLoop(haystack, n, results)
 	}
 
 	// Use streaming state machine for CharClassSearcher
+	e.stats.inc(statNFASearches)
 	allMatches := e.charClassSearcher.FindAllIndices(haystack, results)
 
 	// Apply limit if specified
@@ -199,7 +314,7 @@ func (e *Engine) findAllIndicesLoop(haystack []byte, n int, results [][2]int) []
 	// Fast path: start-anchored patterns (^) match at most once at position 0.
 	// Skip pool Get/Put overhead entirely — use non-pooled FindIndices.
 	if e.nfa.IsAlwaysAnchored() {
-		start, end, found := e.FindIndices(haystack)
+		start, end, found := e.findIndices(haystack)
 		if found {
 			results = append(results, [2]int{start, end})
 		}
@@ -207,7 +322,7 @@ func (e *Engine) findAllIndicesLoop(haystack []byte, n int, results [][2]int) []
 	}
 
 	// Get state ONCE for entire iteration - eliminates 1.29M sync.Pool ops for FindAll
-	state := e.getSearchState()
+	state := e.getSearchState(len(haystack))
 	defer e.putSearchState(state)
 
 	// DFA fast path: call DFA functions directly, skip meta prefilter layer.
@@ -224,6 +339,7 @@ func (e *Engine) findAllIndicesLoop(haystack []byte, n int, results [][2]int) []
 		if useDFADirect {
 			// 2-pass bidirectional DFA, called directly (no meta prefilter).
 			// SearchAt → match end (matches Rust find_fwd), reverse DFA → start.
+			e.stats.inc(statDFASearches)
 			matchEnd := e.dfa.SearchAt(state.dfaCache, haystack, pos)
 			if matchEnd < 0 {
 				break
@@ -298,13 +414,14 @@ func (e *Engine) Count(haystack []byte, n int) int {
 	if n == 0 {
 		return 0
 	}
+	e.stats.searched(len(haystack))
 
 	count := 0
 	pos := 0
 	lastNonEmptyEnd := -1
 
 	// Get state ONCE for entire iteration - eliminates sync.Pool overhead per match
-	state := e.getSearchState()
+	state := e.getSearchState(len(haystack))
 	defer e.putSearchState(state)
 
 	// DFA fast path: call DFA functions directly, skip meta prefilter layer.
@@ -318,6 +435,7 @@ func (e *Engine) Count(haystack []byte, n int) int {
 		var found bool
 
 		if useDFADirect {
+			e.stats.inc(statDFASearches)
 			matchEnd := e.dfa.SearchAt(state.dfaCache, haystack, pos)
 			if matchEnd < 0 {
 				break
@@ -391,6 +509,7 @@ func (e *Engine) FindAllSubmatch(haystack []byte, n int) []*MatchWithCaptures {
 	if n == 0 {
 		return nil
 	}
+	e.stats.searched(len(haystack))
 
 	var matches []*MatchWithCaptures
 	pos := 0
@@ -398,7 +517,7 @@ func (e *Engine) FindAllSubmatch(haystack []byte, n int) []*MatchWithCaptures {
 
 	// Get state ONCE for entire iteration — eliminates sync.Pool overhead per match.
 	// Critical for race detector performance (10+ minute timeout without this).
-	state := e.getSearchState()
+	state := e.getSearchState(len(haystack))
 	defer e.putSearchState(state)
 
 	for pos <= len(haystack) {
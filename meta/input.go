@@ -0,0 +1,230 @@
+// Package meta implements the meta-engine orchestrator.
+//
+// input.go contains span-limited searches configured with an Input.
+
+package meta
+
+import (
+	"fmt"
+
+	"github.com/coregx/coregex/nfa"
+)
+
+// Anchor selects where a match may start and end within the span of an Input.
+type Anchor uint8
+
+const (
+	// Unanchored allows a match anywhere in the span.
+	Unanchored Anchor = iota
+
+	// AnchorStart requires the match to start at the span start.
+	AnchorStart
+
+	// AnchorBoth requires the match to start at the span start and end at
+	// the span end (a full match of the span).
+	AnchorBoth
+)
+
+// Input describes one search: a haystack, the span of it to search, and how.
+//
+// Only matches within the span are reported, but the whole haystack is the
+// context for look-around assertions: ^ and \A match only at offset 0 of the
+// haystack, $ and \z only at its end, and \b at a span edge looks at the byte
+// outside the span. Slicing the haystack instead would make the span edges
+// look like the start and end of text.
+//
+// Input is a small value; the With methods return modified copies:
+//
+//	in := meta.NewInput(data).WithSpan(10, 20).WithAnchor(meta.AnchorStart)
+type Input struct {
+	haystack []byte
+	start    int
+	end      int
+	anchor   Anchor
+	earliest bool
+}
+
+// NewInput returns an unanchored Input that searches all of haystack.
+func NewInput(haystack []byte) Input {
+	return Input{haystack: haystack, end: len(haystack)}
+}
+
+// WithSpan returns a copy of in that searches haystack[start:end].
+// It panics if the span is out of range, like slicing would.
+func (in Input) WithSpan(start, end int) Input {
+	if start < 0 || start > end || end > len(in.haystack) {
+		panic(fmt.Sprintf("regexp: invalid span [%d, %d] for haystack of length %d", start, end, len(in.haystack)))
+	}
+	in.start, in.end = start, end
+	return in
+}
+
+// WithAnchor returns a copy of in with the given anchoring mode.
+func (in Input) WithAnchor(anchor Anchor) Input {
+	in.anchor = anchor
+	return in
+}
+
+// WithEarliest returns a copy of in that reports a match as soon as one is
+// known to end, instead of extending it by the usual leftmost-first (or
+// leftmost-longest) rules. The match found this way ends at the smallest
+// possible offset; it is only useful when any match will do. Earliest has no
+// effect with AnchorBoth.
+func (in Input) WithEarliest(earliest bool) Input {
+	in.earliest = earliest
+	return in
+}
+
+// Haystack returns the haystack.
+func (in Input) Haystack() []byte {
+	return in.haystack
+}
+
+// Span returns the start and end of the searched span.
+func (in Input) Span() (start, end int) {
+	return in.start, in.end
+}
+
+// Anchor returns the anchoring mode.
+func (in Input) Anchor() Anchor {
+	return in.anchor
+}
+
+// Earliest reports whether earliest-match mode is set.
+func (in Input) Earliest() bool {
+	return in.earliest
+}
+
+// spanOptions converts the search mode for nfa.PikeVM.SearchSpan.
+func (in Input) spanOptions() nfa.SpanOptions {
+	return nfa.SpanOptions{
+		Anchored: in.anchor == AnchorStart,
+		Full:     in.anchor == AnchorBoth,
+		Earliest: in.earliest,
+	}
+}
+
+// IsMatchInput reports whether the regex matches within the span of in.
+func (e *Engine) IsMatchInput(in Input) bool {
+	e.stats.searched(in.end - in.start)
+	if in.anchor == Unanchored {
+		start, end, found := e.findIndicesAt(in.lookahead(), in.start)
+		if !found || start > in.end {
+			return false
+		}
+		if end <= in.end {
+			return true
+		}
+		in.start = start
+	}
+
+	in = in.WithEarliest(true)
+	if end, ok := e.spanDFA(in); ok {
+		return end >= 0
+	}
+	return e.searchSpan(in) != nil
+}
+
+// SearchIndices returns the first match within the span of in.
+// Returns (-1, -1, false) if there is none.
+func (e *Engine) SearchIndices(in Input) (start, end int, found bool) {
+	e.stats.searched(in.end - in.start)
+	if in.anchor == Unanchored {
+		// See Input.lookahead.
+		start, end, found := e.findIndicesAt(in.lookahead(), in.start)
+		if !found || start > in.end {
+			return -1, -1, false
+		}
+		if end <= in.end && !in.earliest {
+			return start, end, true
+		}
+		in.start = start
+	}
+
+	end, ok := e.spanDFA(in)
+	if ok {
+		if end < 0 {
+			return -1, -1, false
+		}
+		if in.anchor != Unanchored {
+			return in.start, end, true
+		}
+	}
+	m := e.searchSpan(e.cutSpan(in, end, ok))
+	if m == nil {
+		return -1, -1, false
+	}
+	return m.Start, m.End, true
+}
+
+// SearchSubmatch returns the first match within the span of in, with
+// capture groups. Returns nil if there is none.
+func (e *Engine) SearchSubmatch(in Input) *MatchWithCaptures {
+	e.stats.searched(in.end - in.start)
+	if in.anchor == Unanchored {
+		// See Input.lookahead.
+		state := e.getSearchState(len(in.haystack))
+		m := e.findSubmatchAtWithState(in.lookahead(), in.start, state)
+		e.putSearchState(state)
+		if m == nil || m.Start() > in.end {
+			return nil
+		}
+		if m.End() <= in.end && !in.earliest {
+			return NewMatchWithCaptures(in.haystack, m.captures)
+		}
+		in.start = m.Start()
+	}
+
+	end, ok := e.spanDFA(in)
+	if ok && end < 0 {
+		return nil
+	}
+	m := e.searchSpan(e.cutSpan(in, end, ok))
+	if m == nil {
+		return nil
+	}
+	return NewMatchWithCaptures(in.haystack, m.Captures)
+}
+
+// lookahead returns the haystack up to one byte past the span, for the
+// regular search of an unanchored span search.
+//
+// The matches that end inside the span are the same in it as in the whole
+// haystack: the byte after the span is there to resolve \b and $ at the span
+// end. The regular search over it finds the most preferred of a superset of
+// the span's matches, so its result is the span's answer whenever it ends
+// inside the span, and if there is none, neither has the span. Otherwise the
+// span's match starts no earlier than the regular one, and the search goes
+// on from there.
+func (in Input) lookahead() []byte {
+	return in.haystack[:min(in.end+1, len(in.haystack))]
+}
+
+// spanDFA runs the lazy DFA over the span of in and returns the end of the
+// match it selects (see lazy.DFA.SearchSpan), or -1 if there is none. For
+// anchored searches that is the end of the match in.
+// ok=false means the DFA is unavailable or gave up.
+func (e *Engine) spanDFA(in Input) (end int, ok bool) {
+	// Full and leftmost-longest anchored searches need every match end.
+	all := in.anchor == AnchorBoth || (in.anchor == AnchorStart && e.longest && !in.earliest)
+	return e.searchSpanDFA(in.haystack, in.start, in.end, in.spanOptions(), all, nil)
+}
+
+// cutSpan returns in with its span ending at end, the match end found by
+// spanDFA, when the match in the span is known to end there: the PikeVM
+// then stops at the match. A leftmost-longest match may end after the
+// leftmost-first one found by the DFA.
+func (e *Engine) cutSpan(in Input, end int, ok bool) Input {
+	if ok && (in.anchor != Unanchored || in.earliest || !e.longest) {
+		in.end = end
+	}
+	return in
+}
+
+// searchSpan runs the PikeVM over the span of in.
+func (e *Engine) searchSpan(in Input) *nfa.MatchWithCaptures {
+	e.stats.inc(statNFASearches)
+	state := e.getSearchState(len(in.haystack))
+	defer e.putSearchState(state)
+	return state.pikevm.SearchSpan(in.haystack, in.start, in.end, in.spanOptions())
+}
@@ -0,0 +1,125 @@
+package meta
+
+import (
+	"bytes"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// TestSearchInputMatchesPikeVM checks span searches against the PikeVM run
+// over the same span, which sees the surrounding bytes as context, over
+// random patterns, spans and modes.
+func TestSearchInputMatchesPikeVM(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	anchors := []Anchor{Unanchored, AnchorStart, AnchorBoth}
+	for i := 0; i < 3000; i++ {
+		pattern := randomPattern(rng, 3)
+		if rng.Intn(4) == 0 {
+			pattern = "(?U)" + pattern
+		}
+		engine, err := Compile(pattern)
+		if err != nil {
+			t.Fatalf("Compile(%q): %v", pattern, err)
+		}
+		longest := rng.Intn(4) == 0
+		engine.SetLongest(longest)
+		for j := 0; j < 5; j++ {
+			h := randomHaystack(rng, 12)
+			start := rng.Intn(len(h) + 1)
+			end := start + rng.Intn(len(h)-start+1)
+			in := NewInput(h).WithSpan(start, end).
+				WithAnchor(anchors[rng.Intn(len(anchors))]).WithEarliest(rng.Intn(3) == 0)
+
+			want := engine.searchSpan(in)
+			wantLoc := []int{-1, -1}
+			if want != nil {
+				wantLoc = []int{want.Start, want.End}
+			}
+			s, e, found := engine.SearchIndices(in)
+			if got := []int{s, e}; !reflect.DeepEqual(got, wantLoc) || found != (want != nil) {
+				t.Errorf("%q longest=%v: SearchIndices(%q, %+v) = %v, want %v",
+					pattern, longest, h, in, got, wantLoc)
+			}
+			if got := engine.IsMatchInput(in); got != (want != nil) {
+				t.Errorf("%q longest=%v: IsMatchInput(%q, %+v) = %v, want %v",
+					pattern, longest, h, in, got, want != nil)
+			}
+
+			m := engine.SearchSubmatch(in)
+			switch {
+			case (m == nil) != (want == nil):
+				t.Errorf("%q longest=%v: SearchSubmatch(%q, %+v) = %v, want %v",
+					pattern, longest, h, in, m, want)
+			case m != nil && !reflect.DeepEqual(m.captures, NewMatchWithCaptures(h, want.Captures).captures):
+				t.Errorf("%q longest=%v: SearchSubmatch(%q, %+v) = %v, want %v",
+					pattern, longest, h, in, m.captures, want.Captures)
+			}
+		}
+	}
+}
+
+// TestSearchInputLongHaystack checks span searches at the start of a long
+// haystack, whose matches after the span must not be reported.
+func TestSearchInputLongHaystack(t *testing.T) {
+	engine, err := Compile(`(\w+)@(\w+)\.com\b`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := append([]byte("mail bob@example.com "), bytes.Repeat([]byte("x@y.com "), 1<<17)...)
+
+	tests := []struct {
+		in   Input
+		want []int
+	}{
+		{NewInput(h).WithSpan(0, 20), []int{5, 20}},
+		{NewInput(h).WithSpan(0, 19), nil},
+		{NewInput(h).WithSpan(8, 20), nil},
+		{NewInput(h).WithSpan(5, 20).WithAnchor(AnchorStart), []int{5, 20}},
+		{NewInput(h).WithSpan(5, 20).WithAnchor(AnchorBoth), []int{5, 20}},
+		{NewInput(h).WithSpan(6, 20).WithAnchor(AnchorBoth), []int{6, 20}},
+		{NewInput(h).WithSpan(5, 20).WithEarliest(true), []int{5, 20}},
+		{NewInput(h).WithSpan(0, 19).WithEarliest(true), nil},
+	}
+	for _, tt := range tests {
+		start, end := tt.in.Span()
+		s, e, found := engine.SearchIndices(tt.in)
+		var got []int
+		if found {
+			got = []int{s, e}
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SearchIndices(span [%d, %d], %v, earliest=%v) = %v, want %v",
+				start, end, tt.in.Anchor(), tt.in.Earliest(), got, tt.want)
+		}
+		if got := engine.IsMatchInput(tt.in); got != (tt.want != nil) {
+			t.Errorf("IsMatchInput(span [%d, %d], %v) = %v, want %v", start, end, tt.in.Anchor(), got, tt.want != nil)
+		}
+	}
+}
+
+func BenchmarkSearchInput(b *testing.B) {
+	engine, err := Compile(`(\w+)@(\w+)\.com`)
+	if err != nil {
+		b.Fatal(err)
+	}
+	h := append(bytes.Repeat([]byte("lorem ipsum "), 1<<16), "bob@example.com"...)
+	inputs := []struct {
+		name string
+		in   Input
+	}{
+		{"unanchored", NewInput(h)},
+		{"span", NewInput(h).WithSpan(0, len(h)-3)},
+		{"earliest", NewInput(h).WithEarliest(true)},
+		{"anchored", NewInput(h).WithAnchor(AnchorStart)},
+		{"full", NewInput(h).WithAnchor(AnchorBoth)},
+	}
+	for _, bm := range inputs {
+		b.Run(bm.name, func(b *testing.B) {
+			b.SetBytes(int64(len(h)))
+			for i := 0; i < b.N; i++ {
+				engine.SearchIndices(bm.in)
+			}
+		})
+	}
+}
@@ -6,7 +6,6 @@ package meta
 
 import (
 	"bytes"
-	"sync/atomic"
 
 	"github.com/coregx/coregex/simd"
 )
@@ -25,6 +24,7 @@ import (
 //	    println("matches!")
 //	}
 func (e *Engine) IsMatch(haystack []byte) bool {
+	e.stats.searched(len(haystack))
 	switch e.strategy {
 	case UseNFA:
 		return e.isMatchNFA(haystack)
@@ -58,6 +58,8 @@ func (e *Engine) IsMatch(haystack []byte) bool {
 		return e.isMatchAhoCorasick(haystack)
 	case UseAnchoredLiteral:
 		return e.isMatchAnchoredLiteral(haystack)
+	case UseDenseDFA:
+		return e.isMatchDenseDFA(haystack)
 	default:
 		return e.isMatchNFA(haystack)
 	}
@@ -79,30 +81,45 @@ func (e *Engine) isMatchAnchoredLiteral(haystack []byte) bool {
 // For small NFAs, prefers BoundedBacktracker (2-3x faster than PikeVM on small inputs).
 // Thread-safe: uses pooled state for both BoundedBacktracker and PikeVM.
 func (e *Engine) isMatchNFA(haystack []byte) bool {
-	atomic.AddUint64(&e.stats.NFASearches, 1)
+	e.stats.inc(statNFASearches)
 
 	// BoundedBacktracker is preferred when available (supports both default and Longest modes)
 	useBT := e.boundedBacktracker != nil
 
 	// Get pooled state for thread-safe execution
-	state := e.getSearchState()
+	state := e.getSearchState(len(haystack))
 	defer e.putSearchState(state)
 
+	if end, ok := e.lookaroundEnd(haystack, 0, state, true); ok {
+		return end >= 0
+	}
+
 	// Use prefilter for skip-ahead if available
 	if e.prefilter != nil {
+		tracker := state.prefilterTracker(e.prefilter)
 		at := 0
 		for at < len(haystack) {
 			// Find next candidate position via prefilter
-			pos := e.prefilter.Find(haystack, at)
+			pos, retired := e.nextCandidate(tracker, haystack, at)
+			if retired {
+				// Scan the rest without the prefilter
+				var found bool
+				if useBT && e.canBacktrack(e.boundedBacktracker, len(haystack)-at) {
+					_, _, found = e.boundedBacktracker.SearchAtWithState(haystack, at, state.backtracker)
+				} else {
+					_, _, found = state.pikevm.SearchAt(haystack, at)
+				}
+				return found
+			}
 			if pos == -1 {
 				return false // No more candidates
 			}
-			atomic.AddUint64(&e.stats.PrefilterHits, 1)
+			e.stats.inc(statPrefilterHits)
 
 			// Try to match at candidate position
 			// Prefer BoundedBacktracker for small inputs (2-3x faster)
 			var found bool
-			if useBT && e.boundedBacktracker.CanHandle(len(haystack)-pos) {
+			if useBT && e.canBacktrack(e.boundedBacktracker, len(haystack)-pos) {
 				_, _, found = e.boundedBacktracker.SearchAtWithState(haystack, pos, state.backtracker)
 			} else {
 				_, _, found = state.pikevm.SearchAt(haystack, pos)
@@ -112,14 +129,14 @@ func (e *Engine) isMatchNFA(haystack []byte) bool {
 			}
 
 			// Move past this position
-			atomic.AddUint64(&e.stats.PrefilterMisses, 1)
+			e.stats.inc(statPrefilterMisses)
 			at = pos + 1
 		}
 		return false
 	}
 
 	// No prefilter: use BoundedBacktracker if available, else PikeVM
-	if useBT && e.boundedBacktracker.CanHandle(len(haystack)) {
+	if useBT && e.canBacktrack(e.boundedBacktracker, len(haystack)) {
 		return e.boundedBacktracker.IsMatchWithState(haystack, state.backtracker)
 	}
 
@@ -135,11 +152,11 @@ func (e *Engine) isMatchNFA(haystack []byte) bool {
 //
 // Thread-safe: uses pooled DFACache for lazy DFA state construction.
 func (e *Engine) isMatchDFA(haystack []byte) bool {
-	atomic.AddUint64(&e.stats.DFASearches, 1)
+	e.stats.inc(statDFASearches)
 
 	// DFA.IsMatch handles prefilter internally (isMatchWithPrefilter).
 	// Don't call prefilter separately — avoids double prefilter scan.
-	state := e.getSearchState()
+	state := e.getSearchState(len(haystack))
 	result := e.dfa.IsMatch(state.dfaCache, haystack)
 	e.putSearchState(state)
 	return result
@@ -153,7 +170,7 @@ func (e *Engine) isMatchAdaptive(haystack []byte) bool {
 		if pos == -1 {
 			return false // Prefilter says no match
 		}
-		atomic.AddUint64(&e.stats.PrefilterHits, 1)
+		e.stats.inc(statPrefilterHits)
 		// For complete prefilters (Teddy with literals), the find is sufficient
 		if e.prefilter.IsComplete() {
 			return true
@@ -164,8 +181,8 @@ func (e *Engine) isMatchAdaptive(haystack []byte) bool {
 
 	// Fall back to DFA
 	if e.dfa != nil {
-		atomic.AddUint64(&e.stats.DFASearches, 1)
-		state := e.getSearchState()
+		e.stats.inc(statDFASearches)
+		state := e.getSearchState(len(haystack))
 		matched := e.dfa.IsMatch(state.dfaCache, haystack)
 		if matched {
 			e.putSearchState(state)
@@ -175,7 +192,6 @@ func (e *Engine) isMatchAdaptive(haystack []byte) bool {
 		size, capacity, _, _, _ := e.dfa.CacheStats(state.dfaCache)
 		e.putSearchState(state)
 		if size >= int(capacity)*9/10 {
-			atomic.AddUint64(&e.stats.DFACacheFull, 1)
 			// Cache nearly full, fall back to NFA
 			return e.isMatchNFA(haystack)
 		}
@@ -209,26 +225,26 @@ func (e *Engine) isMatchBoundedBacktracker(haystack []byte) bool {
 		return false
 	}
 
-	atomic.AddUint64(&e.stats.NFASearches, 1) // Count as NFA-family search for stats
+	e.stats.inc(statNFASearches) // Count as NFA-family search for stats
 
 	// V11-002 ASCII optimization: use ASCII NFA when input is ASCII-only.
 	// SIMD isASCII check runs at ~20-40 GB/s, adding minimal overhead (~3-4ns).
 	// For Issue #79 pattern ^/.*[\w-]+\.php, ASCII NFA has 14 states vs 39 states.
 	if e.asciiBoundedBacktracker != nil && simd.IsASCII(haystack) {
-		if !e.asciiBoundedBacktracker.CanHandle(len(haystack)) {
+		if !e.canBacktrack(e.asciiBoundedBacktracker, len(haystack)) {
 			return e.pikevm.IsMatch(haystack)
 		}
 		// Use ASCII backtracker directly (no pooled state needed - it's independent)
 		return e.asciiBoundedBacktracker.IsMatch(haystack)
 	}
 
-	if !e.boundedBacktracker.CanHandle(len(haystack)) {
+	if !e.canBacktrack(e.boundedBacktracker, len(haystack)) {
 		// Input too large for bounded backtracker, fall back to PikeVM
 		return e.pikevm.IsMatch(haystack)
 	}
 
 	// Use pooled state for thread-safety
-	state := e.getSearchState()
+	state := e.getSearchState(len(haystack))
 	defer e.putSearchState(state)
 	return e.boundedBacktracker.IsMatchWithState(haystack, state.backtracker)
 }
@@ -238,7 +254,7 @@ func (e *Engine) isMatchCharClassSearcher(haystack []byte) bool {
 	if e.charClassSearcher == nil {
 		return e.isMatchNFA(haystack)
 	}
-	atomic.AddUint64(&e.stats.NFASearches, 1)
+	e.stats.inc(statNFASearches)
 	return e.charClassSearcher.IsMatch(haystack)
 }
 
@@ -246,13 +262,13 @@ func (e *Engine) isMatchCharClassSearcher(haystack []byte) bool {
 func (e *Engine) isMatchCompositeSearcher(haystack []byte) bool {
 	// Prefer DFA over backtracking
 	if e.compositeSequenceDFA != nil {
-		atomic.AddUint64(&e.stats.DFASearches, 1)
+		e.stats.inc(statDFASearches)
 		return e.compositeSequenceDFA.IsMatch(haystack)
 	}
 	if e.compositeSearcher == nil {
 		return e.isMatchNFA(haystack)
 	}
-	atomic.AddUint64(&e.stats.NFASearches, 1)
+	e.stats.inc(statNFASearches)
 	return e.compositeSearcher.IsMatch(haystack)
 }
 
@@ -261,7 +277,7 @@ func (e *Engine) isMatchBranchDispatch(haystack []byte) bool {
 	if e.branchDispatcher == nil {
 		return e.isMatchBoundedBacktracker(haystack)
 	}
-	atomic.AddUint64(&e.stats.NFASearches, 1)
+	e.stats.inc(statNFASearches)
 	return e.branchDispatcher.IsMatch(haystack)
 }
 
@@ -273,11 +289,11 @@ func (e *Engine) isMatchTeddy(haystack []byte) bool {
 
 	// For Fat Teddy with small haystacks, use Aho-Corasick fallback.
 	if e.fatTeddyFallback != nil && len(haystack) < fatTeddySmallHaystackThreshold {
-		atomic.AddUint64(&e.stats.AhoCorasickSearches, 1)
+		e.stats.inc(statAhoCorasickSearches)
 		return e.fatTeddyFallback.IsMatch(haystack)
 	}
 
-	atomic.AddUint64(&e.stats.PrefilterHits, 1)
+	e.stats.inc(statPrefilterHits)
 	return e.prefilter.Find(haystack, 0) != -1
 }
 
@@ -288,11 +304,11 @@ func (e *Engine) isMatchDigitPrefilter(haystack []byte) bool {
 		return e.isMatchNFA(haystack)
 	}
 
-	atomic.AddUint64(&e.stats.PrefilterHits, 1)
+	e.stats.inc(statPrefilterHits)
 	pos := 0
 
 	// Acquire pooled state once for the entire loop to avoid repeated get/put
-	state := e.getSearchState()
+	state := e.getSearchState(len(haystack))
 	defer e.putSearchState(state)
 
 	for pos < len(haystack) {
@@ -305,12 +321,12 @@ func (e *Engine) isMatchDigitPrefilter(haystack []byte) bool {
 		// Unanchored FindAt scans to end of input per candidate = O(n²).
 		// Anchored checks only a few bytes per candidate = O(pattern_len).
 		if e.dfa != nil {
-			atomic.AddUint64(&e.stats.DFASearches, 1)
+			e.stats.inc(statDFASearches)
 			if e.dfa.SearchAtAnchored(state.dfaCache, haystack, digitPos) != -1 {
 				return true
 			}
 		} else {
-			atomic.AddUint64(&e.stats.NFASearches, 1)
+			e.stats.inc(statNFASearches)
 			start, _, found := state.pikevm.SearchAt(haystack, digitPos)
 			if found && start == digitPos {
 				return true
@@ -335,7 +351,7 @@ func (e *Engine) isMatchAhoCorasick(haystack []byte) bool {
 	if e.ahoCorasick == nil {
 		return e.isMatchNFA(haystack)
 	}
-	atomic.AddUint64(&e.stats.AhoCorasickSearches, 1)
+	e.stats.inc(statAhoCorasickSearches)
 	return e.ahoCorasick.IsMatch(haystack)
 }
 
@@ -345,7 +361,7 @@ func (e *Engine) isMatchReverseAnchored(haystack []byte) bool {
 		return e.isMatchNFA(haystack)
 	}
 
-	atomic.AddUint64(&e.stats.DFASearches, 1)
+	e.stats.inc(statDFASearches)
 	return e.reverseSearcher.IsMatch(haystack)
 }
 
@@ -355,7 +371,7 @@ func (e *Engine) isMatchReverseSuffix(haystack []byte) bool {
 		return e.isMatchNFA(haystack)
 	}
 
-	atomic.AddUint64(&e.stats.DFASearches, 1)
+	e.stats.inc(statDFASearches)
 	return e.reverseSuffixSearcher.IsMatch(haystack)
 }
 
@@ -365,7 +381,7 @@ func (e *Engine) isMatchReverseSuffixSet(haystack []byte) bool {
 		return e.isMatchNFA(haystack)
 	}
 
-	atomic.AddUint64(&e.stats.DFASearches, 1)
+	e.stats.inc(statDFASearches)
 	return e.reverseSuffixSetSearcher.IsMatch(haystack)
 }
 
@@ -375,7 +391,7 @@ func (e *Engine) isMatchReverseInner(haystack []byte) bool {
 		return e.isMatchNFA(haystack)
 	}
 
-	atomic.AddUint64(&e.stats.DFASearches, 1)
+	e.stats.inc(statDFASearches)
 	return e.reverseInnerSearcher.IsMatch(haystack)
 }
 
@@ -386,6 +402,6 @@ func (e *Engine) isMatchMultilineReverseSuffix(haystack []byte) bool {
 		return e.isMatchNFA(haystack)
 	}
 
-	atomic.AddUint64(&e.stats.DFASearches, 1)
+	e.stats.inc(statDFASearches)
 	return e.multilineReverseSuffixSearcher.IsMatch(haystack)
 }
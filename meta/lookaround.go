@@ -0,0 +1,88 @@
+package meta
+
+import (
+	"github.com/coregx/coregex/dfa/lazy"
+	"github.com/coregx/coregex/nfa"
+)
+
+// compileLookaround builds the engine of a pattern with look-ahead or
+// look-behind assertions.
+//
+// Only the PikeVM and the forward lazy DFA evaluate look-around, so the
+// strategy is UseNFA and the prefilter, OnePass DFA, BoundedBacktracker and
+// specialized searchers are not built: their literal and anchor analysis
+// would take the assertions for empty matches. The lazy DFA decides
+// IsMatch on its own and finds the end of the first match, which bounds
+// the PikeVM run for its start (see lookaroundEnd).
+func compileLookaround(plan *compilePlan) *Engine {
+	re, nfaEngine, config := plan.re, plan.nfa, plan.config
+	plan.strategy = UseNFA
+	engines := strategyEngines{finalStrategy: UseNFA}
+	if config.EnableDFA {
+		dfaConfig := lazy.DefaultConfig()
+		dfaConfig.MaxStates = config.MaxDFAStates //nolint:staticcheck // legacy API compat
+		dfaConfig.DeterminizationLimit = config.DeterminizationLimit
+		if d, err := lazy.CompileWithConfig(nfaEngine, dfaConfig); err == nil {
+			engines.dfa = d
+		}
+	}
+
+	pikevm := nfa.NewPikeVM(nfaEngine)
+	sharePikeVMWithDFAs(nfaEngine, engines)
+	ssCfg := buildSearchStateConfig(nfaEngine, nfaEngine.CaptureCount(), engines, UseNFA, false)
+
+	debugEngine("lazy DFA", engines.dfa != nil, "disabled")
+	debugStrategy(re.String(), UseNFA, nfaEngine.States(), nil, lookaroundReason)
+
+	return &Engine{
+		nfa:             nfaEngine,
+		dfa:             engines.dfa,
+		nfaStateCount:   nfaEngine.States(),
+		pikevm:          pikevm,
+		strategy:        UseNFA,
+		config:          config,
+		canMatchEmpty:   pikevm.IsMatch(nil),
+		isStartAnchored: nfaEngine.IsAlwaysAnchored(),
+		statePool:       newSearchStatePool(ssCfg),
+		stats:           newStatCounters(config),
+		plan:            plan,
+	}
+}
+
+// lookaroundReason is the Explain reason of look-around patterns.
+const lookaroundReason = "look-around needs the lazy DFA and PikeVM"
+
+// lookaroundEnd returns the end of the first match at or after at that the
+// lazy DFA finds in a look-around pattern, or -1 if there is none. It
+// returns false if the engine has no lazy DFA for the pattern. earliest
+// stops at the first match end seen, which only tells whether there is a
+// match.
+func (e *Engine) lookaroundEnd(haystack []byte, at int, state *SearchState, earliest bool) (int, bool) {
+	if state.dfaCache == nil || !e.nfa.HasLookaround() {
+		return 0, false
+	}
+	e.stats.inc(statDFASearches)
+	if earliest {
+		if e.dfa.IsMatchAt(state.dfaCache, haystack, at) {
+			return at, true
+		}
+		return -1, true
+	}
+	return e.dfa.SearchAt(state.dfaCache, haystack, at), true
+}
+
+// findLookaround finds the first match at or after at of a look-around
+// pattern: the lazy DFA finds its end and the PikeVM its start, scanning
+// only up to that end. It returns false if the engine has no lazy DFA for
+// the pattern.
+func (e *Engine) findLookaround(haystack []byte, at int, state *SearchState) (start, end int, found, ok bool) {
+	end, ok = e.lookaroundEnd(haystack, at, state, false)
+	if !ok {
+		return -1, -1, false, false
+	}
+	if end < 0 {
+		return -1, -1, false, true
+	}
+	start, end, found = state.pikevm.SearchBetween(haystack, at, end)
+	return start, end, found, true
+}
@@ -32,6 +32,7 @@
 //   - UseBranchDispatch: O(1) branch dispatch for anchored alternations
 //   - UseCompositeSearcher: For concatenated char classes
 //   - UseAnchoredLiteral: O(1) matching for ^prefix.*suffix$ patterns (32-133x)
+//   - UseDenseDFA: Precompiled dense DFAs for small patterns
 //
 // # Thread Safety
 //
@@ -76,6 +77,8 @@
 //   - config.go: Configuration options
 //   - match.go: Match and MatchWithCaptures types
 //   - search_state.go: Thread-safe state pooling
+//   - binary.go: Saving and loading compiled engines
 //   - anchored_literal.go: UseAnchoredLiteral implementation
+//   - dense.go: UseDenseDFA implementation
 //   - reverse_*.go: Reverse search implementations
 package meta
@@ -317,7 +317,7 @@ func TestEngineStrategies(t *testing.T) {
 
 // TestEngineStats tests statistics tracking
 func TestEngineStats(t *testing.T) {
-	engine, err := Compile("hello")
+	engine, err := CompileWithConfig("hello", statsConfig())
 	if err != nil {
 		t.Fatalf("Compile() failed: %v", err)
 	}
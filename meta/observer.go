@@ -0,0 +1,128 @@
+package meta
+
+import (
+	"fmt"
+
+	"github.com/coregx/coregex/nfa"
+)
+
+// FallbackKind identifies how a search fell back to a slower engine.
+type FallbackKind int
+
+const (
+	// FallbackDFACacheFull means the lazy DFA cache was still full after
+	// lazy.Config.MaxCacheClears clears; the search finished on the NFA.
+	FallbackDFACacheFull FallbackKind = iota
+
+	// FallbackBacktrackerTooLong means the input was too long for the
+	// BoundedBacktracker; the search ran a DFA or the PikeVM instead.
+	FallbackBacktrackerTooLong
+
+	// FallbackPrefilterRetired means the prefilter.Tracker of an NFA search
+	// retired its prefilter because too few candidates matched; the search
+	// scanned the rest of the haystack without it.
+	FallbackPrefilterRetired
+)
+
+// String returns the name of the fallback kind.
+func (k FallbackKind) String() string {
+	switch k {
+	case FallbackDFACacheFull:
+		return "DFACacheFull"
+	case FallbackBacktrackerTooLong:
+		return "BacktrackerTooLong"
+	case FallbackPrefilterRetired:
+		return "PrefilterRetired"
+	default:
+		return fmt.Sprintf("FallbackKind(%d)", int(k))
+	}
+}
+
+// FallbackEvent describes a search that fell back to a slower engine.
+type FallbackEvent struct {
+	Kind FallbackKind
+
+	// Pattern is the pattern of the engine, as it was compiled; for an
+	// engine compiled with CompileRegexp, the syntax tree as a string.
+	Pattern string
+
+	// Strategy is the strategy of the engine.
+	Strategy Strategy
+
+	// HaystackLen is the length of the searched haystack.
+	HaystackLen int
+
+	// Reason explains the fallback, e.g. "input of 1048576 bytes exceeds
+	// the BoundedBacktracker limit of 32768 bytes".
+	Reason string
+}
+
+// Observer receives the fallback events of an engine: searches that
+// silently degrade to a slower engine. Set it with Config.Observer to log
+// or count them in production.
+//
+// OnFallback is called on the searching goroutine, after the fallback
+// and before the search returns, so it must be fast and safe for
+// concurrent use.
+type Observer interface {
+	OnFallback(FallbackEvent)
+}
+
+// ObserverFunc adapts a function to the Observer interface.
+type ObserverFunc func(FallbackEvent)
+
+// OnFallback calls f(event).
+func (f ObserverFunc) OnFallback(event FallbackEvent) {
+	f(event)
+}
+
+// observe reports a fallback of kind to the observer. Callers check
+// e.config.Observer first, so that engines without an observer do no more
+// than that nil check and format no reason.
+func (e *Engine) observe(kind FallbackKind, haystackLen int, reason string) {
+	e.config.Observer.OnFallback(FallbackEvent{
+		Kind:        kind,
+		Pattern:     e.pattern(),
+		Strategy:    e.strategy,
+		HaystackLen: haystackLen,
+		Reason:      reason,
+	})
+}
+
+// pattern returns the pattern the engine was compiled from.
+func (e *Engine) pattern() string {
+	switch p := e.plan; {
+	case p == nil:
+		return ""
+	case p.pattern == "" && p.re != nil:
+		return p.re.String()
+	default:
+		return p.pattern
+	}
+}
+
+// canBacktrack reports whether bt can search n bytes. If not, the caller
+// falls back to a DFA or the PikeVM, which counts as an NFA fallback.
+func (e *Engine) canBacktrack(bt *nfa.BoundedBacktracker, n int) bool {
+	if bt.CanHandle(n) {
+		return true
+	}
+	e.stats.inc(statNFAFallbacks)
+	if e.config.Observer != nil {
+		e.observe(FallbackBacktrackerTooLong, n,
+			fmt.Sprintf("input of %d bytes exceeds the BoundedBacktracker limit of %d bytes", n, bt.MaxInputSize()))
+	}
+	return false
+}
+
+// observeCacheCounts reports the lazy DFA cache clears and give-ups d of a
+// search of n bytes to the statistics and the observer.
+func (e *Engine) observeCacheCounts(d cacheCounts, n int) {
+	if e.stats != nil {
+		e.stats.cacheStats(d)
+	}
+	if d.giveUps > 0 && e.config.Observer != nil {
+		e.observe(FallbackDFACacheFull, n,
+			"lazy DFA cache still full after its clear budget was used up")
+	}
+}
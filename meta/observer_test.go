@@ -0,0 +1,219 @@
+package meta
+
+import (
+	"math/rand"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// recorder is an Observer that records its events.
+type recorder struct {
+	mu     sync.Mutex
+	events []FallbackEvent
+}
+
+func (r *recorder) OnFallback(event FallbackEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+// kinds returns the kinds of the recorded events.
+func (r *recorder) kinds() []FallbackKind {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	kinds := make([]FallbackKind, len(r.events))
+	for i, event := range r.events {
+		kinds[i] = event.Kind
+	}
+	return kinds
+}
+
+func TestObserverBacktrackerTooLong(t *testing.T) {
+	rec := &recorder{}
+	config := DefaultConfig()
+	config.Observer = rec
+	engine, err := CompileWithConfig(`(\w+)\s(\d+)`, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bt := engine.boundedBacktracker
+	if bt == nil {
+		t.Skipf("%s engine has no BoundedBacktracker", engine.Strategy())
+	}
+	if _, _, found := engine.FindIndices([]byte("abc 123")); !found {
+		t.Fatal("FindIndices found no match")
+	}
+	if got := rec.kinds(); len(got) != 0 {
+		t.Fatalf("kinds = %v for a short input, want none", got)
+	}
+	haystack := []byte(strings.Repeat("x", bt.MaxInputSize()) + " 1")
+	n := len(haystack)
+	if start, end, found := engine.FindIndices(haystack); !found || start != 0 || end != n {
+		t.Fatalf("FindIndices = %d, %d, %v, want 0, %d, true", start, end, found, n)
+	}
+	if got := rec.kinds(); len(got) != 1 || got[0] != FallbackBacktrackerTooLong {
+		t.Fatalf("kinds = %v, want [BacktrackerTooLong]", got)
+	}
+	if event := rec.events[0]; event.HaystackLen != n || !strings.Contains(event.Reason, "BoundedBacktracker limit") {
+		t.Errorf("event = %+v, want the input length and the limit", event)
+	}
+
+	// Capture searches run the PikeVM whatever the input length, so they
+	// have nothing to fall back from.
+	if m := engine.FindSubmatch(haystack); m == nil || !slices.Equal(m.GroupIndex(1), []int{0, n - 2}) || !slices.Equal(m.GroupIndex(2), []int{n - 1, n}) {
+		t.Fatalf("FindSubmatch = %v, want groups [0 %d] and [%d %d]", m, n-2, n-1, n)
+	}
+	if got := rec.kinds(); len(got) != 1 {
+		t.Errorf("kinds = %v after FindSubmatch, want only the FindIndices event", got)
+	}
+}
+
+func TestObserverDFACacheFull(t *testing.T) {
+	rec := &recorder{}
+	config := DefaultConfig()
+	config.EnablePrefilter = false
+	config.DenseDFAMaxStates = 0
+	config.Observer = rec
+	engine, err := CompileWithConfig(`a[ab]{20}c`, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rng := rand.New(rand.NewSource(1))
+	haystack := make([]byte, 256<<10)
+	for i := range haystack {
+		haystack[i] = "ab"[rng.Intn(2)]
+	}
+	for range 20 {
+		engine.IsMatch(haystack)
+		if len(rec.kinds()) > 0 {
+			break
+		}
+	}
+	if got := rec.kinds(); len(got) == 0 || got[0] != FallbackDFACacheFull {
+		t.Fatalf("kinds = %v, want DFACacheFull", got)
+	}
+	if event := rec.events[0]; event.HaystackLen != len(haystack) || event.Strategy != engine.Strategy() {
+		t.Errorf("event = %+v, want %s on %d bytes", event, engine.Strategy(), len(haystack))
+	}
+}
+
+func TestObserverFunc(t *testing.T) {
+	var got []FallbackKind
+	config := DefaultConfig()
+	config.Observer = ObserverFunc(func(event FallbackEvent) { got = append(got, event.Kind) })
+	engine, err := CompileWithConfig(`(\w+)\s(\d+)`, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bt := engine.boundedBacktracker
+	if bt == nil {
+		t.Skipf("%s engine has no BoundedBacktracker", engine.Strategy())
+	}
+	engine.FindIndices([]byte(strings.Repeat("x", bt.MaxInputSize()) + " 1"))
+	if len(got) != 1 || got[0] != FallbackBacktrackerTooLong {
+		t.Errorf("kinds = %v, want [BacktrackerTooLong]", got)
+	}
+}
+
+func TestObserverPrefilterMisses(t *testing.T) {
+	// Prefilter candidates that do not match are no fallback until the
+	// tracker retires the prefilter.
+	rec := &recorder{}
+	config := DefaultConfig()
+	config.Observer = rec
+	engine, err := CompileWithConfig(`x(?:\pL|\d)+y`, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	haystack := []byte(strings.Repeat("x1 ", 100))
+	if engine.IsMatch(haystack) {
+		t.Fatal("IsMatch found a match without 'y'")
+	}
+	engine.FindIndices(haystack)
+	if got := rec.kinds(); len(got) != 0 {
+		t.Errorf("kinds = %v, want none", got)
+	}
+}
+
+func TestObserverPrefilterRetired(t *testing.T) {
+	// Every 'x' is a prefilter candidate, but none is followed by a 'y', so
+	// the tracker retires the prefilter at its 128th candidate.
+	rec := &recorder{}
+	config := DefaultConfig()
+	config.Observer = rec
+	engine, err := CompileWithConfig(`x(?:\pL|\d)+y`, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	haystack := []byte(strings.Repeat("x1 ", 150))
+	if engine.IsMatch(haystack) {
+		t.Fatal("IsMatch found a match without 'y'")
+	}
+	if _, _, found := engine.FindIndices(haystack); found {
+		t.Fatal("FindIndices found a match without 'y'")
+	}
+	want := []FallbackKind{FallbackPrefilterRetired, FallbackPrefilterRetired}
+	if got := rec.kinds(); !slices.Equal(got, want) {
+		t.Fatalf("kinds = %v, want %v", got, want)
+	}
+	event := rec.events[0]
+	if event.Pattern != `x(?:\pL|\d)+y` || event.Strategy != engine.Strategy() || event.HaystackLen != len(haystack) {
+		t.Errorf("event = %+v", event)
+	}
+	if want := "prefilter retired after 0 of 128 candidates matched"; event.Reason != want {
+		t.Errorf("Reason = %q, want %q", event.Reason, want)
+	}
+}
+
+func TestFallbackKindString(t *testing.T) {
+	tests := []struct {
+		kind FallbackKind
+		want string
+	}{
+		{FallbackDFACacheFull, "DFACacheFull"},
+		{FallbackBacktrackerTooLong, "BacktrackerTooLong"},
+		{FallbackPrefilterRetired, "PrefilterRetired"},
+		{FallbackKind(9), "FallbackKind(9)"},
+	}
+	for _, tt := range tests {
+		if got := tt.kind.String(); got != tt.want {
+			t.Errorf("%d.String() = %q, want %q", int(tt.kind), got, tt.want)
+		}
+	}
+}
+
+func TestEnginePattern(t *testing.T) {
+	engine, err := Compile(`\d+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := engine.pattern(); got != `\d+` {
+		t.Errorf("pattern() = %q, want %q", got, `\d+`)
+	}
+	data, err := engine.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadEngine(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := loaded.pattern(); got != `\d+` {
+		t.Errorf("loaded pattern() = %q, want %q", got, `\d+`)
+	}
+
+	re, err := Parse(`\d+`, DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine, err = CompileRegexp(re, DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := engine.pattern(); got != re.String() {
+		t.Errorf("CompileRegexp pattern() = %q, want %q", got, re.String())
+	}
+}
@@ -0,0 +1,257 @@
+package meta
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/coregx/coregex/internal/scan"
+	"github.com/coregx/coregex/nfa"
+)
+
+// Parse parses pattern with the parser flags, verbose mode and extended
+// syntax of config.
+// Compile and CompileSet parse patterns with it; it is exported for callers
+// that need the syntax tree of a pattern compiled with a non-default Config.
+//
+// Look-around groups are accepted as by nfa.Parse.
+//
+// Errors are *CompileError. For syntax errors, the Expr of the wrapped
+// *syntax.Error and the CompileError's Offset refer to the original pattern,
+// also in verbose mode and with extended syntax.
+func Parse(pattern string, config Config) (*syntax.Regexp, error) {
+	flags := config.syntaxFlags()
+	src := pattern
+	var offsets []int
+	if config.Verbose && flags&syntax.Literal == 0 {
+		src, offsets = stripVerbose(pattern)
+	}
+	if config.ExtendedSyntax && flags&(syntax.PerlX|syntax.Literal) == syntax.PerlX {
+		expanded, extOffsets, err := expandExtended(src, config.Classes, flags)
+		if err != nil {
+			return nil, newSyntaxError(pattern, src, offsets, err)
+		}
+		if extOffsets != nil {
+			if offsets != nil {
+				for i, off := range extOffsets {
+					extOffsets[i] = offsets[off]
+				}
+			}
+			src, offsets = expanded, extOffsets
+		}
+	}
+
+	re, err := nfa.Parse(src, flags)
+	if err != nil {
+		return nil, newSyntaxError(pattern, src, offsets, err)
+	}
+	return re, nil
+}
+
+// ErrNoInlineFlags is returned by InlineFlags for a Config whose pattern
+// options have no inline flag.
+var ErrNoInlineFlags = errors.New("pattern options have no inline flag")
+
+// InlineFlags returns pattern rewritten so that Parse with the default Config
+// parses it as Parse with config does: verbose whitespace and comments are
+// stripped, a literal pattern is quoted with regexp.QuoteMeta, and the
+// case-insensitive, multi-line, dot-all and ungreedy flags become an inline
+// (?imsU) prefix. Regex.MarshalText saves patterns this way.
+//
+// Byte mode, Unicode word boundaries, extended syntax and parser flags other
+// than these have no inline form; for them InlineFlags returns an error
+// wrapping ErrNoInlineFlags.
+func InlineFlags(pattern string, config Config) (string, error) {
+	const inline = syntax.FoldCase | syntax.OneLine | syntax.DotNL | syntax.NonGreedy | syntax.Literal
+	flags := config.syntaxFlags()
+	switch {
+	case config.Bytes:
+		return "", fmt.Errorf("byte mode: %w", ErrNoInlineFlags)
+	case config.UnicodeWordBoundary:
+		return "", fmt.Errorf("Unicode word boundaries: %w", ErrNoInlineFlags)
+	case config.ExtendedSyntax && flags&(syntax.PerlX|syntax.Literal) == syntax.PerlX:
+		return "", fmt.Errorf("extended syntax: %w", ErrNoInlineFlags)
+	case flags&^inline != syntax.Perl&^inline:
+		return "", fmt.Errorf("syntax flags %#x: %w", uint16(flags), ErrNoInlineFlags)
+	}
+
+	switch {
+	case flags&syntax.Literal != 0:
+		pattern = regexp.QuoteMeta(pattern)
+	case config.Verbose:
+		pattern, _ = stripVerbose(pattern)
+	}
+	var prefix []byte
+	if flags&syntax.FoldCase != 0 {
+		prefix = append(prefix, 'i')
+	}
+	if flags&syntax.OneLine == 0 {
+		prefix = append(prefix, 'm')
+	}
+	if flags&syntax.DotNL != 0 {
+		prefix = append(prefix, 's')
+	}
+	if flags&syntax.NonGreedy != 0 {
+		prefix = append(prefix, 'U')
+	}
+	if len(prefix) == 0 {
+		return pattern, nil
+	}
+	return "(?" + string(prefix) + ")" + pattern, nil
+}
+
+// syntaxFlags returns the parser flags, defaulting to syntax.Perl.
+func (c Config) syntaxFlags() syntax.Flags {
+	if c.SyntaxFlags == 0 {
+		return syntax.Perl
+	}
+	return c.SyntaxFlags
+}
+
+// newSyntaxError wraps a parse error of src, which is pattern with verbose
+// whitespace and comments stripped (offsets maps src to pattern; nil if
+// nothing was stripped).
+func newSyntaxError(pattern, src string, offsets []int, err error) *CompileError {
+	var serr *syntax.Error
+	if !errors.As(err, &serr) {
+		return &CompileError{Pattern: pattern, Offset: -1, Err: err}
+	}
+	// The parser reports where the error is by the text it refers to, which
+	// is almost always unique in practice; take its first occurrence.
+	start := strings.Index(src, serr.Expr)
+	if start < 0 {
+		return &CompileError{Pattern: pattern, Offset: -1, Err: err}
+	}
+	if offsets == nil {
+		return &CompileError{Pattern: pattern, Offset: start, Err: err}
+	}
+
+	end := start + len(serr.Expr)
+	origStart, origEnd := offsets[start], offsets[end]
+	if end > start {
+		// End right after the last byte of Expr, not after the whitespace
+		// and comments that follow it, also when Expr is the whole pattern.
+		origEnd = offsets[end-1] + 1
+	}
+	return &CompileError{
+		Pattern: pattern,
+		Offset:  origStart,
+		Err:     &syntax.Error{Code: serr.Code, Expr: pattern[origStart:origEnd]},
+	}
+}
+
+// stripVerbose removes the whitespace and comments of a verbose (x mode)
+// pattern. offsets[i] is the offset in pattern of byte i of the result, and
+// offsets[len(result)] is len(pattern).
+//
+// As in Perl's /x:
+//   - unescaped whitespace is ignored
+//   - an unescaped # starts a comment that runs to the end of the line
+//   - both are literal inside a character class and inside \Q...\E
+//   - an escaped space or # (`\ `, `\#`) is a literal space or #
+//
+// Whitespace is also kept in the argument of \p, \P and \x and inside a
+// {n,m} repetition, so that `\p {L}` stays an error and `a{1, 3}` stays a
+// literal, as they are without verbose mode, rather than becoming `\p{L}`
+// and `a{1,3}`.
+func stripVerbose(pattern string) (string, []int) {
+	var b strings.Builder
+	b.Grow(len(pattern))
+	offsets := make([]int, 0, len(pattern)+1)
+	keep := func(from, to int) {
+		b.WriteString(pattern[from:to])
+		for i := from; i < to; i++ {
+			offsets = append(offsets, i)
+		}
+	}
+
+	sc := scan.New(pattern)
+	for {
+		tok, ok := sc.Next()
+		if !ok {
+			break
+		}
+		switch c := pattern[tok.Start]; {
+		case tok.Kind == scan.Escape && !tok.InClass:
+			end := escapeArgEnd(pattern, tok.Start, tok.End)
+			keep(tok.Start, end)
+			sc.Seek(end)
+
+		case tok.Kind != scan.Byte:
+			// Escapes, quoted text and classes are kept verbatim.
+			keep(tok.Start, tok.End)
+
+		case c == '{':
+			end := repeatEnd(pattern, tok.Start)
+			keep(tok.Start, end)
+			sc.Seek(end)
+
+		case c == '#':
+			end := strings.IndexByte(pattern[tok.Start:], '\n')
+			if end < 0 {
+				end = len(pattern) - tok.Start
+			}
+			sc.Seek(tok.Start + end)
+
+		case isVerboseSpace(c):
+
+		default:
+			keep(tok.Start, tok.End)
+		}
+	}
+	offsets = append(offsets, len(pattern))
+	return b.String(), offsets
+}
+
+// escapeArgEnd returns the end of the argument of the escape
+// pattern[start:end]: {...} or one rune after \p and \P, {...} or two bytes
+// after \x. It returns end for other escapes.
+func escapeArgEnd(pattern string, start, end int) int {
+	switch pattern[start:end] {
+	case `\p`, `\P`, `\x`:
+	default:
+		return end
+	}
+	if end >= len(pattern) {
+		return end
+	}
+	if pattern[end] == '{' {
+		if i := strings.IndexByte(pattern[end:], '}'); i >= 0 {
+			return end + i + 1
+		}
+		return len(pattern)
+	}
+	if pattern[end-1] == 'x' {
+		return min(end+2, len(pattern))
+	}
+	_, size := utf8.DecodeRuneInString(pattern[end:])
+	return end + size
+}
+
+// repeatEnd returns the end of the {n,m} repetition that starts at pattern[i]
+// == '{': the offset after its '}' if only digits, commas and whitespace come
+// before it, i+1 otherwise.
+func repeatEnd(pattern string, i int) int {
+	for j := i + 1; j < len(pattern); j++ {
+		switch c := pattern[j]; {
+		case c == '}':
+			return j + 1
+		case c == ',', '0' <= c && c <= '9', isVerboseSpace(c):
+		default:
+			return i + 1
+		}
+	}
+	return i + 1
+}
+
+// isVerboseSpace reports whether c is whitespace ignored in verbose mode.
+func isVerboseSpace(c byte) bool {
+	switch c {
+	case ' ', '\t', '\n', '\r', '\f', '\v':
+		return true
+	}
+	return false
+}
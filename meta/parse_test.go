@@ -0,0 +1,103 @@
+package meta
+
+import (
+	"errors"
+	"regexp/syntax"
+	"testing"
+)
+
+func TestStripVerbose(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{"a b c", "abc"},
+		{"a\tb\n\rc", "abc"},
+		{"a # comment\nb", "ab"},
+		{"a # comment", "a"},
+		{`a\ b\#c`, `a\ b\#c`},
+		{"[a b#]", "[a b#]"},
+		{"[] ]x", "[] ]x"},
+		{"[^] ]x", "[^] ]x"},
+		{"[[:alpha:] ] y", "[[:alpha:] ]y"},
+		{`\Q a # b \E c`, `\Q a # b \Ec`},
+		{`\Q a # b`, `\Q a # b`},
+		{"é é", "éé"},
+		{`(?P<year> \d{4} ) # year`, `(?P<year>\d{4})`},
+		{`\p {L}`, `\p {L}`},
+		{`\pL \p{Greek} \P {L}`, `\pL\p{Greek}\P {L}`},
+		{`\x 41 \x{ 41 }`, `\x 41\x{ 41 }`},
+		{`[\p {L}]`, `[\p {L}]`},
+		{`a{1, 3}`, `a{1, 3}`},
+		{`a{ 2 } b{x y}`, `a{ 2 }b{xy}`},
+		{`a{1,3 `, `a{1,3`},
+		{`a\`, `a\`},
+	}
+	for _, tt := range tests {
+		got, offsets := stripVerbose(tt.pattern)
+		if got != tt.want {
+			t.Errorf("stripVerbose(%q) = %q, want %q", tt.pattern, got, tt.want)
+			continue
+		}
+		if len(offsets) != len(got)+1 || offsets[len(got)] != len(tt.pattern) {
+			t.Errorf("stripVerbose(%q): bad offsets %v", tt.pattern, offsets)
+			continue
+		}
+		for i := 0; i < len(got); i++ {
+			if tt.pattern[offsets[i]] != got[i] {
+				t.Errorf("stripVerbose(%q): byte %d maps to %d (%q)", tt.pattern, i, offsets[i], tt.pattern[offsets[i]])
+			}
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	verbose := DefaultConfig()
+	verbose.Verbose = true
+
+	tests := []struct {
+		pattern string
+		config  Config
+		expr    string
+		offset  int
+	}{
+		{`a**`, DefaultConfig(), `**`, 1},
+		{`a(b`, DefaultConfig(), `a(b`, 0},
+		{"a  b # x\n  c**", verbose, `**`, 12},
+		{"a  [z-a] # bad range", verbose, `z-a`, 4},
+		{"a ( # open\n b", verbose, "a ( # open\n b", 0},
+		{"  a ( # open", verbose, "a (", 2},
+		{"a ( # open\n b # tail\n", verbose, "a ( # open\n b", 0},
+		{`x \p {L}`, verbose, `\p `, 2},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.pattern, tt.config)
+		var cerr *CompileError
+		var serr *syntax.Error
+		if !errors.As(err, &cerr) || !errors.As(err, &serr) {
+			t.Errorf("Parse(%q) = %v, want a *CompileError wrapping a *syntax.Error", tt.pattern, err)
+			continue
+		}
+		if serr.Expr != tt.expr || cerr.Offset != tt.offset {
+			t.Errorf("Parse(%q): Expr %q at %d, want %q at %d", tt.pattern, serr.Expr, cerr.Offset, tt.expr, tt.offset)
+		}
+	}
+}
+
+func TestParseFlags(t *testing.T) {
+	config := DefaultConfig()
+	config.SyntaxFlags = syntax.Perl | syntax.Literal
+	config.Verbose = true // ignored for literal patterns
+	re, err := Parse("a b.", config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if re.Op != syntax.OpLiteral || string(re.Rune) != "a b." {
+		t.Errorf("Parse literal = %v, want literal %q", re, "a b.")
+	}
+
+	config = Config{} // zero SyntaxFlags parse as syntax.Perl
+	if _, err := Parse(`\d+(?P<x>y)`, config); err != nil {
+		t.Errorf("zero SyntaxFlags: %v", err)
+	}
+}
@@ -0,0 +1,328 @@
+package meta
+
+import (
+	"io"
+
+	"github.com/coregx/coregex/dfa/lazy"
+)
+
+// ReplaceReader copies src to dst with every match replaced by what expand
+// appends for it, and returns the number of bytes written to dst.
+//
+// expand is called once per match, in order, with out (the pending output),
+// hay (the part of the stream held in memory) and the match slots relative
+// to hay: group 0 only, or all groups in the layout of FindSubmatchIndex if
+// captures is true. hay is only valid during the call.
+//
+// Matches are found as by FindReaderIndex, one after the other: the lazy DFA
+// scans the stream chunk by chunk, so a match spanning a chunk boundary is
+// found, and a match is only replaced once it can no longer change. After a
+// match the DFA starts again at its end, with the byte before as look-behind
+// context, and scans each byte of the stream about once. Empty matches follow
+// the rules of ReplaceAll. Memory use is bounded as for FindReaderIndex (see
+// StreamWindow); without the DFA the stream is read into memory.
+//
+// Returns the first read or write error.
+func (e *Engine) ReplaceReader(dst io.Writer, src io.Reader, captures bool, expand func(out, hay []byte, match []int) []byte) (int64, error) {
+	rr := &readerReplacer{
+		engine:       e,
+		src:          src,
+		dst:          dst,
+		expand:       expand,
+		captures:     captures,
+		lastMatchEnd: -1,
+	}
+	if captures {
+		rr.match = make([]int, 2*e.nfa.CaptureCount())
+	} else {
+		rr.match = make([]int, 2)
+	}
+	if d := e.getStreamDFA(); d != nil {
+		rr.scanner = d.NewStreamScanner(d.NewCache())
+		rr.onMatch = func(int64) bool {
+			rr.matched = true
+			return true
+		}
+		if push := e.getPushDFA(); e.longest && push != nil {
+			rr.anchored = push.NewStreamScanner(push.NewCache())
+		}
+	}
+	rr.searcher = e.NewSearcher(0)
+	defer rr.searcher.Close()
+	err := rr.run()
+	return rr.written, err
+}
+
+// readerReplacer holds the state of ReplaceReader.
+//
+// All offsets are absolute stream offsets. The window always holds the input
+// from min(copied, pos-1) on: the text not yet written out and one byte of
+// look-behind context for the next search. It is trimmed to that before each
+// read, not after each match, so that replacing costs no copying per match.
+type readerReplacer struct {
+	engine   *Engine
+	src      io.Reader
+	dst      io.Writer
+	expand   func(out, hay []byte, match []int) []byte
+	captures bool
+
+	scanner *lazy.StreamScanner // nil without the DFA
+	onMatch func(int64) bool
+	matched bool // the scanner has seen a match since the last restart
+
+	// anchored resolves the end of leftmost-longest matches; nil for
+	// leftmost-first engines.
+	anchored *lazy.StreamScanner
+
+	// searcher runs the searches of the window, keeping the look-around
+	// results of one for the next until the window changes.
+	searcher Searcher
+
+	w            streamWindow
+	eof          bool
+	match        []int // slots of the current match, relative to w.buf
+	out          []byte
+	written      int64
+	copied       int64 // input before this offset has been written out
+	pos          int64 // the next search starts here
+	lastMatchEnd int64 // end of the last non-empty match, or -1
+}
+
+// run is the ReplaceAll loop over the stream.
+func (rr *readerReplacer) run() error {
+	for {
+		found, err := rr.next()
+		if err != nil {
+			return err
+		}
+		if !found {
+			break
+		}
+		start := rr.w.base + int64(rr.match[0])
+		end := rr.w.base + int64(rr.match[1])
+
+		// Skip empty matches that start exactly where the previous non-empty
+		// match ended, as ReplaceAll does.
+		//nolint:gocritic // badCond: intentional - checking empty match at lastMatchEnd
+		if start == end && start == rr.lastMatchEnd {
+			rr.pos++
+			continue
+		}
+
+		rr.out = append(rr.out, rr.w.buf[rr.copied-rr.w.base:rr.match[0]]...)
+		rr.out = rr.expand(rr.out, rr.w.buf[:rr.match[1]], rr.match)
+		rr.copied = end
+		if start != end {
+			rr.lastMatchEnd = end
+			rr.pos = end
+		} else {
+			rr.pos = end + 1
+		}
+		if len(rr.out) >= streamChunkSize {
+			if err := rr.flush(); err != nil {
+				return err
+			}
+		}
+	}
+
+	// No more matches: copy the rest of the input unchanged.
+	rr.out = append(rr.out, rr.w.buf[rr.copied-rr.w.base:]...)
+	rr.copied = rr.end()
+	if err := rr.flush(); err != nil {
+		return err
+	}
+	if !rr.eof {
+		n, err := io.Copy(rr.dst, rr.src)
+		rr.written += n
+		return err
+	}
+	return nil
+}
+
+// next finds the next match at or after pos and stores it in rr.match.
+func (rr *readerReplacer) next() (bool, error) {
+	// The look-behind byte before pos must be in the window.
+	for !rr.eof && rr.pos > rr.end() {
+		if _, err := rr.read(); err != nil {
+			return false, err
+		}
+	}
+	if rr.pos > rr.end() {
+		return false, nil
+	}
+
+	hayEnd, ok, err := rr.scan()
+	if err != nil || !ok {
+		return false, err
+	}
+
+	hay := rr.w.buf[:hayEnd]
+	at := int(rr.pos - rr.w.base)
+	if rr.captures {
+		return rr.searcher.FindSubmatchSlotsAt(hay, at, rr.match), nil
+	}
+	start, end, found := rr.searcher.FindIndicesAt(hay, at)
+	rr.match[0], rr.match[1] = start, end
+	return found, nil
+}
+
+// scan reads until a match at or after pos is certain, as Phase 1 of
+// findReader, and returns the length of the window prefix that holds it.
+// Without the DFA it reads the whole stream.
+func (rr *readerReplacer) scan() (int, bool, error) {
+	if rr.scanner != nil {
+		ok, err := rr.scanDFA()
+		if err != nil {
+			return 0, false, err
+		}
+		if rr.scanner != nil {
+			if !ok {
+				return 0, false, nil
+			}
+			if rr.eof {
+				return len(rr.w.buf), true, nil
+			}
+			end := int(rr.scanner.Offset() - rr.w.base)
+			if rr.anchored != nil {
+				return rr.longestEnd(end)
+			}
+			return end, true, nil
+		}
+		// The DFA cannot handle this pattern: finish without it.
+	}
+	for !rr.eof {
+		if _, err := rr.read(); err != nil {
+			return 0, false, err
+		}
+	}
+	return len(rr.w.buf), true, nil
+}
+
+// scanDFA runs the scanner from pos until a match is certain: the scanner
+// went dead after a match, or the stream ended. It reports whether there is
+// a match; rr.scanner is nil on return if the DFA gave up.
+func (rr *readerReplacer) scanDFA() (bool, error) {
+	if !rr.restart() {
+		return false, nil
+	}
+	for !rr.eof && !rr.scanner.Dead() {
+		if !rr.matched && len(rr.w.buf) > StreamWindow && rr.scanner.Fresh() {
+			// Window full, and no attempt in progress started before the
+			// scanner's offset: no match starts before it, so the text up
+			// to there is copied out unchanged.
+			from := rr.scanner.Offset()
+			rr.out = append(rr.out, rr.w.buf[rr.copied-rr.w.base:from-rr.w.base]...)
+			rr.copied, rr.pos = from, from
+			if err := rr.flush(); err != nil {
+				return false, err
+			}
+		}
+
+		chunk, err := rr.read()
+		if err != nil {
+			return false, err
+		}
+		if !rr.feed(chunk) {
+			return false, nil
+		}
+	}
+	return rr.matched, nil
+}
+
+// longestEnd returns the length of the window prefix that holds the
+// leftmost-longest match at or after pos, given that the leftmost-first DFA
+// went dead at window index end. As in longestReaderEnd, an anchored scan
+// from the start of the match reads on until no attempt from there survives.
+func (rr *readerReplacer) longestEnd(end int) (int, bool, error) {
+	start, _, found := rr.engine.FindIndicesAt(rr.w.buf[:end], int(rr.pos-rr.w.base))
+	if !found {
+		return end, true, nil
+	}
+	prev := -1
+	if start > 0 {
+		prev = int(rr.w.buf[start-1])
+	}
+	rr.anchored.RestartAnchored(rr.w.base+int64(start), prev)
+	onMatch := func(int64) bool { return true }
+
+	chunk := rr.w.buf[start:]
+	for {
+		if _, err := rr.anchored.Feed(chunk, onMatch); err != nil {
+			// The DFA gave up: resolve over the rest of the stream.
+			for !rr.eof {
+				if _, err := rr.read(); err != nil {
+					return 0, false, err
+				}
+			}
+			return len(rr.w.buf), true, nil
+		}
+		if rr.anchored.Dead() {
+			return int(rr.anchored.Offset() - rr.w.base), true, nil
+		}
+		if rr.eof {
+			return len(rr.w.buf), true, nil
+		}
+		var err error
+		if chunk, err = rr.read(); err != nil {
+			return 0, false, err
+		}
+	}
+}
+
+// restart starts the scanner at pos, with the byte before pos as look-behind
+// context, and feeds it the part of the window from there. The scanner stops
+// where it goes dead, normally right after the next match.
+func (rr *readerReplacer) restart() bool {
+	prev := -1
+	if rr.pos > 0 {
+		prev = int(rr.w.buf[rr.pos-1-rr.w.base])
+	}
+	rr.matched = false
+	rr.scanner.Restart(rr.pos, prev)
+	return rr.feed(rr.w.buf[rr.pos-rr.w.base:])
+}
+
+// feed scans chunk, and the end of text once the stream has ended. It
+// returns false and drops the scanner if the DFA cannot handle the pattern.
+func (rr *readerReplacer) feed(chunk []byte) bool {
+	if _, err := rr.scanner.Feed(chunk, rr.onMatch); err != nil {
+		rr.scanner = nil
+		return false
+	}
+	if rr.eof {
+		rr.scanner.Finish(rr.onMatch)
+	}
+	return true
+}
+
+// read appends the next chunk of the stream to the window, after dropping
+// what the window no longer needs to hold.
+func (rr *readerReplacer) read() ([]byte, error) {
+	rr.w.discard(min(rr.copied, rr.pos-1))
+	chunk, err := rr.w.read(rr.src)
+	rr.searcher.state.resetLookaround()
+	if err == io.EOF {
+		rr.eof = true
+		err = nil
+	}
+	return chunk, err
+}
+
+// end returns the stream offset just past the window.
+func (rr *readerReplacer) end() int64 {
+	return rr.w.base + int64(len(rr.w.buf))
+}
+
+// flush writes the pending output to dst.
+func (rr *readerReplacer) flush() error {
+	if len(rr.out) == 0 {
+		return nil
+	}
+	n, err := rr.dst.Write(rr.out)
+	rr.written += int64(n)
+	if err == nil && n < len(rr.out) {
+		err = io.ErrShortWrite
+	}
+	rr.out = rr.out[:0]
+	return err
+}
@@ -0,0 +1,101 @@
+package meta
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// replaceReader runs ReplaceReader replacing every match with "<match>".
+func replaceReader(t *testing.T, engine *Engine, input string, n int) string {
+	t.Helper()
+	var out bytes.Buffer
+	written, err := engine.ReplaceReader(&out, &chunkReader{data: []byte(input), n: n}, false,
+		func(out, hay []byte, match []int) []byte {
+			out = append(out, '<')
+			out = append(out, hay[match[0]:match[1]]...)
+			return append(out, '>')
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if written != int64(out.Len()) {
+		t.Errorf("written = %d, want %d", written, out.Len())
+	}
+	return out.String()
+}
+
+func TestReplaceReader(t *testing.T) {
+	noDFA := DefaultConfig()
+	noDFA.EnableDFA = false
+
+	for _, tt := range streamTests {
+		want := regexp.MustCompile(tt.pattern).ReplaceAllString(tt.input, "<$0>")
+		for _, config := range []Config{DefaultConfig(), noDFA} {
+			engine, err := CompileWithConfig(tt.pattern, config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, n := range []int{1, 2, 3, 1 << 10} {
+				if got := replaceReader(t, engine, tt.input, n); got != want {
+					t.Errorf("%q (DFA %v, chunks of %d): ReplaceReader(%q) = %q, want %q",
+						tt.pattern, config.EnableDFA, n, tt.input, got, want)
+				}
+			}
+		}
+	}
+}
+
+// TestReplaceReaderWindowDiscard checks that text dropped when the window
+// fills up is copied through, and that a match straddling the point where it
+// was dropped is still replaced.
+func TestReplaceReaderWindowDiscard(t *testing.T) {
+	engine, err := Compile(`a[^z]*z|needle`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	boundary := StreamWindow + streamChunkSize
+	input := "a" + strings.Repeat("b", boundary-4) + "needle" + strings.Repeat("b", 100)
+	want := "a" + strings.Repeat("b", boundary-4) + "<needle>" + strings.Repeat("b", 100)
+	if got := replaceReader(t, engine, input, streamChunkSize); got != want {
+		t.Errorf("ReplaceReader: got %d bytes, want %d", len(got), len(want))
+	}
+}
+
+// TestReplaceReaderLongMatch checks matches and unfinished match attempts
+// longer than StreamWindow against regexp.
+func TestReplaceReaderLongMatch(t *testing.T) {
+	long := strings.Repeat("c", 2*StreamWindow)
+	tests := []struct {
+		pattern string
+		longest bool
+		input   string
+	}{
+		{`a[^z]*b`, false, "a" + long + "b ab"},
+		{`a[^z]*b`, false, "ab a" + long},
+		{`a[^z]*z|needle`, false, "a" + long + "needle" + long},
+		{`a[^z]*z|needle`, false, "needle a" + long + "needle" + long + "z"},
+		{`a[^z]*b`, false, long + "zabz" + long + "ab"},
+		{`\w+@\w+`, false, strings.Repeat("a b ", StreamWindow) + "me@host " + long + "@x"},
+		{`a|a[^z]*b`, true, "a" + long + "b a"},
+		{`a|a[^z]*b`, true, "a" + long + "z ab"},
+		{`a|ab`, true, "ab" + long + "ab a"},
+	}
+
+	for _, tt := range tests {
+		engine, err := Compile(tt.pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		engine.SetLongest(tt.longest)
+		re := regexp.MustCompile(tt.pattern)
+		if tt.longest {
+			re.Longest()
+		}
+		want := re.ReplaceAllString(tt.input, "<$0>")
+		if got := replaceReader(t, engine, tt.input, streamChunkSize); got != want {
+			t.Errorf("%s (longest %v) on %d bytes: got %d bytes, want %d", tt.pattern, tt.longest, len(tt.input), len(got), len(want))
+		}
+	}
+}
@@ -209,8 +209,10 @@ func NewReverseInnerSearcher(
 	var err error
 	if innerInfo.PrefixAST != nil {
 		compiler := nfa.NewCompiler(nfa.CompilerConfig{
-			UTF8:     true,
-			Anchored: false,
+			UTF8:                fullNFA.IsUTF8(),
+			Bytes:               !fullNFA.IsUTF8(),
+			Anchored:            false,
+			UnicodeWordBoundary: fullNFA.HasUnicodeWordBoundary(),
 		})
 		prefixNFA, err = compiler.CompileRegexp(innerInfo.PrefixAST)
 		if err != nil {
@@ -237,8 +239,10 @@ func NewReverseInnerSearcher(
 	var suffixNFA *nfa.NFA
 	if innerInfo.SuffixAST != nil {
 		compiler := nfa.NewCompiler(nfa.CompilerConfig{
-			UTF8:     true,
-			Anchored: false,
+			UTF8:                fullNFA.IsUTF8(),
+			Bytes:               !fullNFA.IsUTF8(),
+			Anchored:            false,
+			UnicodeWordBoundary: fullNFA.HasUnicodeWordBoundary(),
 		})
 		suffixNFA, err = compiler.CompileRegexp(innerInfo.SuffixAST)
 		if err != nil {
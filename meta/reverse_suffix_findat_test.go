@@ -453,3 +453,31 @@ func TestReverseSuffixSet_FindIndicesAt_PositionSweep(t *testing.T) {
 		}
 	}
 }
+
+// TestReverseSuffixSet_Find_Leftmost checks that Find at position 0 returns
+// the leftmost match, not the one at the last suffix candidate.
+func TestReverseSuffixSet_Find_Leftmost(t *testing.T) {
+	tests := []struct {
+		pattern string
+		input   string
+	}{
+		{`\w+@\w+\.(com|org)`, "mail bob@example.com or eve@test.org."},
+		{`\w+\.(?:com|org)`, "a.org b.com"},
+		{`.*\.(txt|log)`, "a.txt\nb.log"},
+		{`.*\.(txt|log)`, "a.txt x.log\nb.log"},
+	}
+	for _, tt := range tests {
+		engine, err := Compile(tt.pattern)
+		if err != nil {
+			t.Fatalf("Compile(%q): %v", tt.pattern, err)
+		}
+		if engine.Strategy() != UseReverseSuffixSet {
+			t.Fatalf("%q: strategy %s, want UseReverseSuffixSet", tt.pattern, engine.Strategy())
+		}
+		want := regexp.MustCompile(tt.pattern).FindStringIndex(tt.input)
+		start, end, found := engine.FindIndices([]byte(tt.input))
+		if !found || start != want[0] || end != want[1] {
+			t.Errorf("%q.FindIndices(%q) = (%d, %d, %v), want %v", tt.pattern, tt.input, start, end, found, want)
+		}
+	}
+}
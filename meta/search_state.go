@@ -6,6 +6,7 @@ import (
 	"github.com/coregx/coregex/dfa/lazy"
 	"github.com/coregx/coregex/dfa/onepass"
 	"github.com/coregx/coregex/nfa"
+	"github.com/coregx/coregex/prefilter"
 )
 
 // SearchState holds per-search mutable state for thread-safe concurrent searches.
@@ -59,6 +60,20 @@ type SearchState struct {
 
 	// onepassCache is the cache for OnePass DFA searches.
 	onepassCache *onepass.Cache
+
+	// cacheCounts are the lazy.DFACache.ClearStats totals of the caches
+	// above when the state was last returned to the pool. The engine adds
+	// what they grew by since then to its Stats.
+	cacheCounts cacheCounts
+
+	// haystackLen is the haystack length of the search the state was taken
+	// for, reported to the Config.Observer with lazy DFA give-ups.
+	haystackLen int
+
+	// tracker counts the prefilter candidates of the NFA candidate loops and
+	// how many of them matched, to retire a prefilter that keeps missing.
+	// Created on first use.
+	tracker *prefilter.Tracker
 }
 
 // searchStateConfig holds all DFA references needed to create per-search caches.
@@ -105,6 +120,11 @@ func newSearchState(cfg searchStateConfig) *SearchState {
 		switch cfg.strategy {
 		case UseDFA, UseBoth, UseDigitPrefilter, UseBoundedBacktracker:
 			state.dfaCache = cfg.forwardDFA.NewCache()
+		case UseNFA:
+			// Look-around patterns: the DFA decides IsMatch and finds match ends.
+			if cfg.nfaEngine.HasLookaround() {
+				state.dfaCache = cfg.forwardDFA.NewCache()
+			}
 		}
 	}
 
@@ -153,7 +173,10 @@ func (s *SearchState) reset() {
 		s.backtracker.Longest = false
 	}
 
-	// PikeVM reset is handled internally when search begins
+	// PikeVM reset is handled internally when search begins, except for
+	// the look-around results it keeps for the searches of one haystack:
+	// the caller may change the bytes before the next operation.
+	s.resetLookaround()
 
 	// Reset onepass slots to -1 (unmatched)
 	for i := range s.onepassSlots {
@@ -161,6 +184,39 @@ func (s *SearchState) reset() {
 	}
 }
 
+// prefilterTracker returns the tracker of pf, reset for a new search.
+func (s *SearchState) prefilterTracker(pf prefilter.Prefilter) *prefilter.Tracker {
+	if s.tracker == nil {
+		s.tracker = prefilter.NewTracker(pf)
+	} else {
+		s.tracker.Reset()
+	}
+	return s.tracker
+}
+
+// resetLookaround forgets the look-around results kept for the searches of
+// one haystack.
+func (s *SearchState) resetLookaround() {
+	s.pikevm.ResetLookaround()
+	if s.dfaCache != nil {
+		s.dfaCache.ResetLookaround()
+	}
+}
+
+// takeCacheCounts returns what the counts of the lazy DFA caches grew by
+// since the last call.
+func (s *SearchState) takeCacheCounts() cacheCounts {
+	var total cacheCounts
+	for _, c := range [...]*lazy.DFACache{s.dfaCache, s.revDFACache, s.stratFwdCache, s.stratRevCache} {
+		if c != nil {
+			total = total.add(readCacheCounts(c))
+		}
+	}
+	delta := total.sub(s.cacheCounts)
+	s.cacheCounts = total
+	return delta
+}
+
 // searchStatePool manages a pool of SearchState instances for thread-safe reuse.
 // This follows the stdlib regexp pattern of using sync.Pool for concurrent safety.
 type searchStatePool struct {
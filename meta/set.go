@@ -0,0 +1,376 @@
+// Package meta implements the meta-engine orchestrator.
+//
+// set.go contains SetEngine, the multi-pattern engine behind coregex.Set.
+
+package meta
+
+import (
+	"fmt"
+	"regexp/syntax"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/coregx/ahocorasick"
+	"github.com/coregx/coregex/dfa/lazy"
+	"github.com/coregx/coregex/nfa"
+)
+
+// SetEngine matches a haystack against many patterns at once and reports
+// which patterns matched.
+//
+// Three engines cooperate:
+//   - a regular Engine compiled from the alternation of all patterns, each
+//     wrapped in its own capture group. IsMatch and FindAll run through it,
+//     so a set gets the same strategies and prefilters (Teddy, Aho-Corasick,
+//     reverse suffix, ...) as a single pattern.
+//   - a multi-pattern NFA whose match states carry pattern IDs, searched by a
+//     lazy DFA (BreakAtMatch disabled) for WhichMatches. The PikeVM on the
+//     same NFA is the fallback when the DFA gives up.
+//   - an Aho-Corasick automaton when every pattern is a plain literal, which
+//     reports overlapping literal hits with their pattern IDs directly.
+//
+// Thread safety: SetEngine is safe for concurrent use. Per-search DFA caches
+// and PikeVMs are pooled.
+type SetEngine struct {
+	engine *Engine
+
+	// groups[i] is the capture index of the wrapper group around pattern i.
+	groups []int
+
+	setNFA *nfa.NFA
+	dfa    *lazy.DFA
+
+	// lookChain is set if the DFAs miss matches of some patterns through
+	// chains of assertions, such as $^. Only the PikeVM searches then.
+	lookChain bool
+
+	// literals is non-nil when every pattern is a case-sensitive literal.
+	literals *ahocorasick.Automaton
+
+	pool sync.Pool // *setSearchState
+}
+
+// setSearchState holds per-goroutine mutable state for WhichMatches.
+type setSearchState struct {
+	cache   *lazy.DFACache
+	pikevm  *nfa.PikeVM
+	matched []bool
+}
+
+// SetMatch is a single match found by SetEngine.FindAll.
+type SetMatch struct {
+	// Pattern is the index of the pattern that produced the match.
+	Pattern int
+
+	// Start and End are the byte offsets of the match.
+	Start, End int
+}
+
+// CompileSet compiles a set of patterns into a SetEngine.
+//
+// Each pattern is parsed independently with Perl syntax, so flags such as
+// (?i) apply only to the pattern that contains them. Returns a *CompileError
+// carrying the offending pattern if any pattern fails to parse or uses
+// look-around.
+//
+// Example:
+//
+//	set, err := meta.CompileSet([]string{`\d+`, `[a-z]+`}, meta.DefaultConfig())
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	ids := set.WhichMatches([]byte("abc"), nil) // [1]
+func CompileSet(patterns []string, config Config) (*SetEngine, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	res := make([]*syntax.Regexp, len(patterns))
+	for i, p := range patterns {
+		re, err := Parse(p, config)
+		if err != nil {
+			return nil, err
+		}
+		if nfa.HasLookaround(re) {
+			// The set engines scan with DFAs that cannot evaluate it.
+			return nil, &CompileError{
+				Pattern: p,
+				Offset:  -1,
+				Err:     fmt.Errorf("%w: look-around is not supported in sets", nfa.ErrUnsupportedLookaround),
+			}
+		}
+		if config.Bytes {
+			if re, err = byteRegexp(re); err != nil {
+				return nil, &CompileError{
+					Pattern: p,
+					Err:     err,
+				}
+			}
+		}
+		res[i] = re
+	}
+
+	s := &SetEngine{}
+	if len(res) == 0 {
+		return s, nil
+	}
+
+	// Combined engine: (p0)|(p1)|... with user captures renumbered after the
+	// wrapper group of their pattern.
+	alt, groups := combineSetPatterns(res)
+	engine, err := CompileRegexp(alt, config)
+	if err != nil {
+		return nil, err
+	}
+	s.engine = engine
+	s.groups = groups
+
+	compiler := nfa.NewCompiler(nfa.CompilerConfig{
+		UTF8:                !config.Bytes,
+		Bytes:               config.Bytes,
+		MaxRecursionDepth:   config.MaxRecursionDepth,
+		UnicodeWordBoundary: config.UnicodeWordBoundary,
+	})
+	setNFA, err := compiler.CompileSet(res)
+	if err != nil {
+		return nil, &CompileError{
+			Err: err,
+		}
+	}
+	s.setNFA = setNFA
+
+	s.lookChain = lazy.HasInexactLookChain(setNFA)
+	if config.EnableDFA && !s.lookChain {
+		dfaConfig := lazy.DefaultConfig()
+		dfaConfig.MaxStates = config.MaxDFAStates //nolint:staticcheck // legacy API compat
+		dfaConfig.DeterminizationLimit = config.DeterminizationLimit
+		// Overlapping search must not drop lower-priority patterns at a match.
+		dfaConfig.BreakAtMatch = false
+		if d, err := lazy.CompileWithConfig(setNFA, dfaConfig); err == nil {
+			s.dfa = d
+		}
+	}
+
+	if config.EnablePrefilter && !config.Bytes {
+		s.literals = buildSetLiterals(res)
+	}
+
+	s.pool.New = func() any {
+		st := &setSearchState{
+			pikevm:  nfa.NewPikeVMLazy(s.setNFA),
+			matched: make([]bool, len(res)),
+		}
+		if s.dfa != nil {
+			st.cache = s.dfa.NewCache()
+		}
+		return st
+	}
+
+	return s, nil
+}
+
+// combineSetPatterns builds the alternation (p0)|(p1)|... used by the
+// combined engine and returns the capture index of each wrapper group.
+func combineSetPatterns(res []*syntax.Regexp) (*syntax.Regexp, []int) {
+	groups := make([]int, len(res))
+	subs := make([]*syntax.Regexp, len(res))
+	next := 0
+	for i, re := range res {
+		next++
+		groups[i] = next
+		inner := shiftCaptures(re, next)
+		next += re.MaxCap()
+		subs[i] = &syntax.Regexp{
+			Op:  syntax.OpCapture,
+			Cap: groups[i],
+			Sub: []*syntax.Regexp{inner},
+		}
+	}
+	if len(subs) == 1 {
+		return subs[0], groups
+	}
+	return &syntax.Regexp{
+		Op:  syntax.OpAlternate,
+		Sub: subs,
+	}, groups
+}
+
+// shiftCaptures returns a copy of re with every capture index increased by
+// offset. The original tree is not modified.
+func shiftCaptures(re *syntax.Regexp, offset int) *syntax.Regexp {
+	cp := *re
+	if cp.Op == syntax.OpCapture {
+		cp.Cap += offset
+	}
+	if len(re.Sub) > 0 {
+		cp.Sub = make([]*syntax.Regexp, len(re.Sub))
+		for i, sub := range re.Sub {
+			cp.Sub[i] = shiftCaptures(sub, offset)
+		}
+		cp.Sub0 = [1]*syntax.Regexp{}
+	}
+	return &cp
+}
+
+// buildSetLiterals returns an Aho-Corasick automaton over the patterns when
+// every pattern is a non-empty, case-sensitive literal. Returns nil otherwise.
+func buildSetLiterals(res []*syntax.Regexp) *ahocorasick.Automaton {
+	builder := ahocorasick.NewBuilder()
+	var buf []byte
+	for _, re := range res {
+		if re.Op != syntax.OpLiteral || re.Flags&syntax.FoldCase != 0 || len(re.Rune) == 0 {
+			return nil
+		}
+		buf = buf[:0]
+		for _, r := range re.Rune {
+			buf = utf8.AppendRune(buf, r)
+		}
+		builder.AddPattern(append([]byte(nil), buf...))
+	}
+	auto, err := builder.Build()
+	if err != nil {
+		return nil
+	}
+	return auto
+}
+
+// PatternCount returns the number of patterns in the set.
+func (s *SetEngine) PatternCount() int {
+	return len(s.groups)
+}
+
+// IsMatch reports whether any pattern matches the haystack.
+func (s *SetEngine) IsMatch(haystack []byte) bool {
+	if s.engine == nil {
+		return false
+	}
+	if !s.lookChain {
+		return s.engine.IsMatch(haystack)
+	}
+
+	// The combined engine misses the same matches as the set DFA.
+	st := s.pool.Get().(*setSearchState)
+	clear(st.matched)
+	n := st.pikevm.WhichOverlappingMatches(haystack, st.matched)
+	s.pool.Put(st)
+	return n > 0
+}
+
+// WhichMatches appends to dst the indices of all patterns that match anywhere
+// in the haystack, in ascending order, and returns the extended slice.
+//
+// Matches of different patterns may overlap; every pattern that matches is
+// reported exactly once. The haystack is scanned once.
+func (s *SetEngine) WhichMatches(haystack []byte, dst []int) []int {
+	if s.engine == nil {
+		return dst
+	}
+
+	if s.literals != nil {
+		return s.whichLiterals(haystack, dst)
+	}
+
+	// Cheap rejection through the combined engine (prefilters apply there).
+	if !s.lookChain && !s.engine.IsMatch(haystack) {
+		return dst
+	}
+
+	st := s.pool.Get().(*setSearchState)
+	matched := st.matched
+	clear(matched)
+
+	ok := false
+	if s.dfa != nil {
+		st.cache.ResetClearCount()
+		_, ok = s.dfa.WhichOverlappingMatches(st.cache, haystack, matched)
+	}
+	if !ok {
+		st.pikevm.WhichOverlappingMatches(haystack, matched)
+	}
+
+	for pid, m := range matched {
+		if m {
+			dst = append(dst, pid)
+		}
+	}
+	s.pool.Put(st)
+	return dst
+}
+
+// whichLiterals implements WhichMatches for all-literal sets.
+func (s *SetEngine) whichLiterals(haystack []byte, dst []int) []int {
+	st := s.pool.Get().(*setSearchState)
+	matched := st.matched
+	clear(matched)
+	for _, m := range s.literals.FindAllOverlapping(haystack) {
+		matched[m.PatternID] = true
+	}
+	for pid, m := range matched {
+		if m {
+			dst = append(dst, pid)
+		}
+	}
+	s.pool.Put(st)
+	return dst
+}
+
+// FindAll appends to dst the successive non-overlapping leftmost-first
+// matches of the set, each tagged with the pattern that produced it, and
+// returns the extended slice. If n >= 0, at most n matches are appended,
+// none for n == 0; if n < 0, all of them.
+//
+// Semantics are those of the alternation p0|p1|...: at each match position
+// the earliest pattern in the set that matches wins. Empty matches follow
+// the same rules as Regex.FindAllIndex.
+func (s *SetEngine) FindAll(haystack []byte, n int, dst []SetMatch) []SetMatch {
+	if s.engine == nil || n == 0 {
+		return dst
+	}
+
+	count := 0
+	pos := 0
+	lastMatchEnd := -1
+	for pos <= len(haystack) {
+		m := s.engine.FindSubmatchAt(haystack, pos)
+		if m == nil {
+			break
+		}
+		start, end := m.Start(), m.End()
+
+		//nolint:gocritic // badCond: intentional - checking empty match at lastMatchEnd
+		if start == end && start == lastMatchEnd {
+			pos++
+			continue
+		}
+
+		dst = append(dst, SetMatch{Pattern: s.patternOf(m), Start: start, End: end})
+		count++
+		if n > 0 && count >= n {
+			break
+		}
+
+		// Advance as findAllIndicesLoop does: past an empty match, so that
+		// it is not reported again, and to the end of a non-empty one.
+		lastMatchEnd = end
+		switch {
+		case start == end:
+			pos = end + 1
+		case end > pos:
+			pos = end
+		default:
+			pos++
+		}
+	}
+	return dst
+}
+
+// patternOf returns the index of the pattern whose wrapper group participated
+// in the match. Exactly one wrapper group is set for any match.
+func (s *SetEngine) patternOf(m *MatchWithCaptures) int {
+	for pid, g := range s.groups {
+		if idx := m.GroupIndex(g); len(idx) >= 2 && idx[0] >= 0 {
+			return pid
+		}
+	}
+	return -1
+}
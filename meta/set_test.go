@@ -0,0 +1,212 @@
+package meta
+
+import (
+	"errors"
+	"math/rand"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+// setOracle returns the indices of patterns matching input, using stdlib.
+func setOracle(patterns []string, input string) []int {
+	var ids []int
+	for i, p := range patterns {
+		if regexp.MustCompile(p).MatchString(input) {
+			ids = append(ids, i)
+		}
+	}
+	return ids
+}
+
+func TestSetEngineWhichMatches(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		inputs   []string
+	}{
+		{"literals", []string{"foo", "oo", "bar", "o"}, []string{"foo", "xbarx", "", "zzz"}},
+		{"regex", []string{`\d+`, `[a-z]+@[a-z]+`, `^GET `, `\.php$`},
+			[]string{"GET /index.php", "mail bob@example 42", "POST /x", ""}},
+		{"case insensitive", []string{`(?i)select\s+\*`, `(?i)union`, `<script`},
+			[]string{"SeLeCt * from t UNION", "<SCRIPT>", "nothing"}},
+		{"word boundary", []string{`\bcat\b`, `cat`, `\Bat`}, []string{"concat", "a cat", "at"}},
+		{"empty", []string{"", "x"}, []string{"", "abc", "x"}},
+		{"unicode", []string{`\p{Greek}+`, `é`}, []string{"αβγ", "café", "plain"}},
+		{"assertion chain", []string{"b", "$^", "(?:bc)*"}, []string{"", "a\n", "bc"}},
+		{"assertion chain before class", []string{`x`, `(\b|ab)^[ab]`, `[ab][^a]a[ab]`}, []string{"a", "b", "xab"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set, err := CompileSet(tt.patterns, DefaultConfig())
+			if err != nil {
+				t.Fatalf("CompileSet: %v", err)
+			}
+			if set.PatternCount() != len(tt.patterns) {
+				t.Errorf("PatternCount() = %d, want %d", set.PatternCount(), len(tt.patterns))
+			}
+			for _, input := range tt.inputs {
+				want := setOracle(tt.patterns, input)
+				got := set.WhichMatches([]byte(input), nil)
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("WhichMatches(%q) = %v, want %v", input, got, want)
+				}
+				if set.IsMatch([]byte(input)) != (len(want) > 0) {
+					t.Errorf("IsMatch(%q) = %v, want %v", input, !(len(want) > 0), len(want) > 0)
+				}
+			}
+		})
+	}
+}
+
+// TestSetEngineWhichMatchesRandom checks WhichMatches against one regexp per
+// pattern over random sets, and IsMatch against WhichMatches.
+func TestSetEngineWhichMatchesRandom(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		patterns := make([]string, 1+rng.Intn(4))
+		for j := range patterns {
+			patterns[j] = randomPattern(rng, 2)
+		}
+		set, err := CompileSet(patterns, DefaultConfig())
+		if err != nil {
+			t.Fatalf("CompileSet(%q): %v", patterns, err)
+		}
+		for j := 0; j < 5; j++ {
+			h := randomHaystack(rng, 12)
+			want := setOracle(patterns, string(h))
+			got := set.WhichMatches(h, nil)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("%q: WhichMatches(%q) = %v, want %v", patterns, h, got, want)
+			}
+			if set.IsMatch(h) != (len(got) > 0) {
+				t.Errorf("%q: IsMatch(%q) = %v, WhichMatches = %v", patterns, h, !(len(got) > 0), got)
+			}
+		}
+	}
+}
+
+func TestSetEngineWhichMatchesNoDFA(t *testing.T) {
+	config := DefaultConfig()
+	config.EnableDFA = false
+	config.EnablePrefilter = false
+	patterns := []string{`a+b`, `b+c`, `x`}
+	set, err := CompileSet(patterns, config)
+	if err != nil {
+		t.Fatalf("CompileSet: %v", err)
+	}
+	got := set.WhichMatches([]byte("aabbc"), nil)
+	if want := []int{0, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("WhichMatches = %v, want %v", got, want)
+	}
+}
+
+func TestSetEngineFindAll(t *testing.T) {
+	tests := []struct {
+		patterns []string
+		input    string
+		n        int
+		want     []SetMatch
+	}{
+		{[]string{`\d+`, `[a-z]+`}, "ab 12 cd", -1,
+			[]SetMatch{{1, 0, 2}, {0, 3, 5}, {1, 6, 8}}},
+		{[]string{`foo`, `foobar`}, "foobar", -1,
+			[]SetMatch{{0, 0, 3}}},
+		{[]string{`foobar`, `foo`}, "foobar foo", -1,
+			[]SetMatch{{0, 0, 6}, {1, 7, 10}}},
+		{[]string{`(a)(b)`, `(c)`}, "abc", -1,
+			[]SetMatch{{0, 0, 2}, {1, 2, 3}}},
+		{[]string{`x`, `y`}, "xyxy", 2,
+			[]SetMatch{{0, 0, 1}, {1, 1, 2}}},
+		{[]string{`x`}, "abc", -1, nil},
+		{[]string{`x`, `y`}, "xyxy", 0, nil},
+	}
+
+	for _, tt := range tests {
+		set, err := CompileSet(tt.patterns, DefaultConfig())
+		if err != nil {
+			t.Fatalf("CompileSet(%q): %v", tt.patterns, err)
+		}
+		got := set.FindAll([]byte(tt.input), tt.n, nil)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q FindAll(%q, %d) = %v, want %v", tt.patterns, tt.input, tt.n, got, tt.want)
+		}
+	}
+}
+
+// TestSetEngineFindAllEmptyMatches checks FindAll against stdlib
+// FindAllStringSubmatchIndex of the alternation (p0)|(p1)|..., which gives
+// both the match spans and the pattern of each match.
+func TestSetEngineFindAllEmptyMatches(t *testing.T) {
+	tests := []struct {
+		patterns []string
+		input    string
+	}{
+		{[]string{`$`}, "ab"},
+		{[]string{`\b`}, "ab cd"},
+		{[]string{`\B`}, "ab cd"},
+		{[]string{`x*`, `y`}, "ab"},
+		{[]string{`x*`, `y`}, ""},
+		{[]string{`a*`, `b`}, "baaab"},
+		{[]string{`y`, `x*`}, "xyzy"},
+		{[]string{`\s*`, `\w+`}, "  lots   of  space "},
+		{[]string{`(?m)^`, `(?m)$`}, "a\n\nb\n"},
+	}
+
+	for _, tt := range tests {
+		set, err := CompileSet(tt.patterns, DefaultConfig())
+		if err != nil {
+			t.Fatalf("CompileSet(%q): %v", tt.patterns, err)
+		}
+		alt := ""
+		for i, p := range tt.patterns {
+			if i > 0 {
+				alt += "|"
+			}
+			alt += "(" + p + ")"
+		}
+		var want []SetMatch
+		for _, loc := range regexp.MustCompile(alt).FindAllStringSubmatchIndex(tt.input, -1) {
+			pid := -1
+			for i := range tt.patterns {
+				if loc[2+2*i] >= 0 {
+					pid = i
+					break
+				}
+			}
+			want = append(want, SetMatch{Pattern: pid, Start: loc[0], End: loc[1]})
+		}
+		got := set.FindAll([]byte(tt.input), -1, nil)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%q FindAll(%q) = %v, want %v", tt.patterns, tt.input, got, want)
+		}
+	}
+}
+
+func TestSetEngineEmptySet(t *testing.T) {
+	set, err := CompileSet(nil, DefaultConfig())
+	if err != nil {
+		t.Fatalf("CompileSet(nil): %v", err)
+	}
+	if set.IsMatch([]byte("abc")) {
+		t.Error("empty set matched")
+	}
+	if got := set.WhichMatches([]byte("abc"), nil); len(got) != 0 {
+		t.Errorf("WhichMatches = %v, want none", got)
+	}
+	if got := set.FindAll([]byte("abc"), -1, nil); len(got) != 0 {
+		t.Errorf("FindAll = %v, want none", got)
+	}
+}
+
+func TestSetEngineCompileError(t *testing.T) {
+	_, err := CompileSet([]string{"ok", "(bad"}, DefaultConfig())
+	var ce *CompileError
+	if !errors.As(err, &ce) {
+		t.Fatalf("error = %v, want *CompileError", err)
+	}
+	if ce.Pattern != "(bad" {
+		t.Errorf("CompileError.Pattern = %q, want %q", ce.Pattern, "(bad")
+	}
+}
@@ -0,0 +1,201 @@
+package meta
+
+import (
+	"sync/atomic"
+
+	"github.com/coregx/coregex/dfa/lazy"
+)
+
+// Stats are the execution statistics of an engine compiled with
+// Config.EnableStats: how many searches each engine ran, how well the
+// prefilter did and how often the faster engines gave up.
+//
+// The counters are updated atomically, so an engine may be searched from
+// several goroutines while its Stats are read.
+type Stats struct {
+	// Searches counts search calls: each IsMatch, Find, FindSubmatch, Count
+	// or FindAll call counts once, whichever engines it ran. Callers that
+	// loop over matches with FindIndicesAt, as ReplaceAll does, count one
+	// search per match.
+	Searches uint64 `json:"searches"`
+
+	// NFASearches counts NFA (PikeVM and BoundedBacktracker) searches
+	NFASearches uint64 `json:"nfa_searches"`
+
+	// DFASearches counts DFA searches (lazy, dense and the reverse DFA
+	// strategies)
+	DFASearches uint64 `json:"dfa_searches"`
+
+	// OnePassSearches counts OnePass DFA searches (for FindSubmatch)
+	OnePassSearches uint64 `json:"onepass_searches"`
+
+	// AhoCorasickSearches counts Aho-Corasick automaton searches
+	AhoCorasickSearches uint64 `json:"aho_corasick_searches"`
+
+	// PrefilterHits counts successful prefilter matches
+	PrefilterHits uint64 `json:"prefilter_hits"`
+
+	// PrefilterMisses counts prefilter candidates that didn't match
+	PrefilterMisses uint64 `json:"prefilter_misses"`
+
+	// PrefilterAbandoned counts searches that retired their prefilter
+	// because too few of its candidates matched (see prefilter.Tracker) and
+	// scanned the rest of the haystack without it
+	PrefilterAbandoned uint64 `json:"prefilter_abandoned"`
+
+	// DFACacheFull counts times the lazy DFA gave up because its cache was
+	// still full after lazy.Config.MaxCacheClears clears, and fell back to
+	// the NFA
+	DFACacheFull uint64 `json:"dfa_cache_full"`
+
+	// DFACacheClears counts lazy DFA cache clears. A search that fills the
+	// cache clears it and goes on, up to lazy.Config.MaxCacheClears times.
+	DFACacheClears uint64 `json:"dfa_cache_clears"`
+
+	// NFAFallbacks counts searches a faster engine handed on because it
+	// gave up: the lazy DFA to the NFA (DFACacheFull), or the
+	// BoundedBacktracker, on input too long for it, to a DFA or the PikeVM.
+	NFAFallbacks uint64 `json:"nfa_fallbacks"`
+
+	// BytesScanned counts haystack bytes searched: from the search start
+	// to the end of the match, or to the end of the haystack if there is
+	// none, so that finding all matches counts the haystack once.
+	BytesScanned uint64 `json:"bytes_scanned"`
+}
+
+// stat is a counter of Stats.
+type stat int
+
+const (
+	statSearches stat = iota
+	statNFASearches
+	statDFASearches
+	statOnePassSearches
+	statAhoCorasickSearches
+	statPrefilterHits
+	statPrefilterMisses
+	statPrefilterAbandoned
+	statDFACacheFull
+	statDFACacheClears
+	statNFAFallbacks
+	statBytesScanned
+	statCount
+)
+
+// statCounters are the counters of an engine with statistics enabled. The
+// engine holds a nil *statCounters otherwise, and its methods do nothing
+// on nil, so disabled statistics cost a nil check per update.
+type statCounters [statCount]atomic.Uint64
+
+// inc adds one to counter s.
+func (c *statCounters) inc(s stat) {
+	if c != nil {
+		c[s].Add(1)
+	}
+}
+
+// add adds n to counter s.
+func (c *statCounters) add(s stat, n int) {
+	if c != nil && n > 0 {
+		c[s].Add(uint64(n))
+	}
+}
+
+// searched counts a search call that scanned n bytes.
+func (c *statCounters) searched(n int) {
+	if c != nil {
+		c[statSearches].Add(1)
+		c.add(statBytesScanned, n)
+	}
+}
+
+// scanned counts a search call of haystack from at that ended at end, or
+// found nothing if end is negative.
+func (c *statCounters) scanned(haystack []byte, at, end int) {
+	if c == nil {
+		return
+	}
+	if end < 0 {
+		end = len(haystack)
+	}
+	c.searched(end - at)
+}
+
+// cacheStats counts the lazy DFA cache clears and give-ups of d; each
+// give-up is also an NFA fallback.
+func (c *statCounters) cacheStats(d cacheCounts) {
+	c[statDFACacheClears].Add(d.clears)
+	c[statDFACacheFull].Add(d.giveUps)
+	c[statNFAFallbacks].Add(d.giveUps)
+}
+
+// cacheCounts are the counts of lazy.DFACache.ClearStats.
+type cacheCounts struct {
+	clears, giveUps uint64
+}
+
+// readCacheCounts returns the counts of cache.
+func readCacheCounts(cache *lazy.DFACache) cacheCounts {
+	clears, giveUps := cache.ClearStats()
+	return cacheCounts{clears, giveUps}
+}
+
+// add returns the sums of the counts of a and b.
+func (a cacheCounts) add(b cacheCounts) cacheCounts {
+	return cacheCounts{a.clears + b.clears, a.giveUps + b.giveUps}
+}
+
+// sub returns what the counts of a grew by since b.
+func (a cacheCounts) sub(b cacheCounts) cacheCounts {
+	return cacheCounts{a.clears - b.clears, a.giveUps - b.giveUps}
+}
+
+// newStatCounters returns the counters for config, nil if statistics are
+// disabled.
+func newStatCounters(config Config) *statCounters {
+	if !config.EnableStats {
+		return nil
+	}
+	return new(statCounters)
+}
+
+// Stats returns execution statistics.
+//
+// Useful for performance analysis and debugging. All counters are zero
+// unless the engine was compiled with Config.EnableStats.
+//
+// Example:
+//
+//	stats := engine.Stats()
+//	println("NFA searches:", stats.NFASearches)
+//	println("DFA searches:", stats.DFASearches)
+func (e *Engine) Stats() Stats {
+	c := e.stats
+	if c == nil {
+		return Stats{}
+	}
+	return Stats{
+		Searches:            c[statSearches].Load(),
+		NFASearches:         c[statNFASearches].Load(),
+		DFASearches:         c[statDFASearches].Load(),
+		OnePassSearches:     c[statOnePassSearches].Load(),
+		AhoCorasickSearches: c[statAhoCorasickSearches].Load(),
+		PrefilterHits:       c[statPrefilterHits].Load(),
+		PrefilterMisses:     c[statPrefilterMisses].Load(),
+		PrefilterAbandoned:  c[statPrefilterAbandoned].Load(),
+		DFACacheFull:        c[statDFACacheFull].Load(),
+		DFACacheClears:      c[statDFACacheClears].Load(),
+		NFAFallbacks:        c[statNFAFallbacks].Load(),
+		BytesScanned:        c[statBytesScanned].Load(),
+	}
+}
+
+// ResetStats resets execution statistics to zero. Searches running
+// meanwhile may still add to the new counts.
+func (e *Engine) ResetStats() {
+	if c := e.stats; c != nil {
+		for i := range c {
+			c[i].Store(0)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package meta
+
+import (
+	"bytes"
+	"context"
+	"math/rand"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/coregx/coregex/prefilter"
+)
+
+// statsConfig returns DefaultConfig with statistics enabled.
+func statsConfig() Config {
+	config := DefaultConfig()
+	config.EnableStats = true
+	return config
+}
+
+func TestStatsDisabled(t *testing.T) {
+	engine, err := Compile(`\w+@\w+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if engine.stats != nil {
+		t.Fatal("DefaultConfig engine has stat counters")
+	}
+	haystack := []byte("mail a@b and c@d")
+	engine.IsMatch(haystack)
+	engine.FindAllIndicesStreaming(haystack, -1, nil)
+	engine.ResetStats()
+	if got := engine.Stats(); got != (Stats{}) {
+		t.Errorf("Stats() = %+v, want zero", got)
+	}
+}
+
+func TestStatsBytesScanned(t *testing.T) {
+	haystack := []byte("foo123 bar45 baz6 qux")
+	budget := WithSearchBudget(context.Background(), 1<<20)
+	tests := []struct {
+		name     string
+		search   func(*Engine)
+		searches int
+		want     int
+	}{
+		{"IsMatch", func(e *Engine) { e.IsMatch(haystack) }, 1, len(haystack)},
+		{"Find", func(e *Engine) { e.Find(haystack) }, 1, len("foo123")},
+		{"FindIndicesAt", func(e *Engine) { e.FindIndicesAt(haystack, 7) }, 1, len("bar45")},
+		{"FindIndices no match", func(e *Engine) { e.FindIndices([]byte("no digits")) }, 1, len("no digits")},
+		{"FindAllIndicesStreaming", func(e *Engine) { e.FindAllIndicesStreaming(haystack, -1, nil) }, 1, len(haystack)},
+		{"Count", func(e *Engine) { e.Count(haystack, -1) }, 1, len(haystack)},
+		{"IsMatchContext", func(e *Engine) { _, _ = e.IsMatchContext(budget, haystack) }, 1, len(haystack)},
+		{"FindIndicesContext", func(e *Engine) { _, _, _, _ = e.FindIndicesContext(budget, haystack, 7) }, 1, len("bar45")},
+		{"FindIndicesAt loop", func(e *Engine) {
+			for at := 0; at <= len(haystack); {
+				_, end, found := e.FindIndicesAt(haystack, at)
+				if !found {
+					break
+				}
+				at = end
+			}
+		}, 4, len(haystack)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, err := CompileWithConfig(`[a-z]+\d+`, statsConfig())
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.search(engine)
+			stats := engine.Stats()
+			if stats.Searches != uint64(tt.searches) || stats.BytesScanned != uint64(tt.want) {
+				t.Errorf("Searches = %d, BytesScanned = %d, want %d, %d",
+					stats.Searches, stats.BytesScanned, tt.searches, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatsEngineSearches(t *testing.T) {
+	// Every strategy that runs an engine counts it, whichever loop drives it.
+	haystack := []byte("a.txt ab ERROR 1.2.3 abcz xabcy a@b.com\n/a.php ab12 zz")
+	dfa := func(s Stats) uint64 { return s.DFASearches }
+	nfa := func(s Stats) uint64 { return s.NFASearches }
+	tests := []struct {
+		pattern  string
+		strategy Strategy
+		searches func(Stats) uint64
+	}{
+		{`(a|b)c`, UseDFA, dfa},
+		{`.*\.txt`, UseReverseSuffix, dfa},
+		{`.*\.(txt|log|csv)`, UseReverseSuffixSet, dfa},
+		{`.*ERROR.*`, UseReverseInner, dfa},
+		{`(?m)^/.*\.php`, UseMultilineReverseSuffix, dfa},
+		{`\w+`, UseCharClassSearcher, nfa},
+		{`(\w)+`, UseBoundedBacktracker, nfa},
+		{`(?i)x[a-z]{3}y`, UseNFA, nfa},
+		{useBothPattern(), UseBoth, dfa},
+	}
+	for _, tt := range tests {
+		config := statsConfig()
+		config.DenseDFAMaxStates = 0
+		engine, err := CompileWithConfig(tt.pattern, config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if engine.Strategy() != tt.strategy {
+			t.Errorf("%q uses %s, want %s", tt.pattern, engine.Strategy(), tt.strategy)
+			continue
+		}
+		for name, search := range map[string]func(){
+			"FindIndices":             func() { engine.FindIndices(haystack) },
+			"FindAllIndicesStreaming": func() { engine.FindAllIndicesStreaming(haystack, -1, nil) },
+			"Count":                   func() { engine.Count(haystack, -1) },
+		} {
+			engine.ResetStats()
+			search()
+			if tt.searches(engine.Stats()) == 0 {
+				t.Errorf("%s %s: %+v, want the %s engine counted", tt.strategy, name, engine.Stats(), tt.strategy)
+			}
+		}
+	}
+}
+
+func TestStatsDFACacheFull(t *testing.T) {
+	// a[ab]{20}c needs far more DFA states than the cache holds, so each
+	// search clears the cache; the search cache is reused, and after
+	// MaxCacheClears clears the DFA gives up and falls back to the NFA.
+	config := statsConfig()
+	config.EnablePrefilter = false
+	config.DenseDFAMaxStates = 0
+	engine, err := CompileWithConfig(`a[ab]{20}c`, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rng := rand.New(rand.NewSource(1))
+	haystack := make([]byte, 256<<10)
+	for i := range haystack {
+		haystack[i] = "ab"[rng.Intn(2)]
+	}
+	for range 20 {
+		if engine.IsMatch(haystack) {
+			t.Fatal("IsMatch found a match without 'c'")
+		}
+		if engine.Stats().DFACacheFull > 0 {
+			break
+		}
+	}
+	stats := engine.Stats()
+	if stats.DFACacheClears == 0 || stats.DFACacheFull == 0 {
+		t.Fatalf("DFACacheClears = %d, DFACacheFull = %d, want both > 0", stats.DFACacheClears, stats.DFACacheFull)
+	}
+	if stats.NFAFallbacks < stats.DFACacheFull {
+		t.Errorf("NFAFallbacks = %d, want at least DFACacheFull = %d", stats.NFAFallbacks, stats.DFACacheFull)
+	}
+}
+
+func TestStatsPrefilterCandidates(t *testing.T) {
+	// Every 'x' is a prefilter candidate, but none is followed by a 'y'.
+	engine, err := CompileWithConfig(`x(?:\pL|\d)+y`, statsConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if engine.IsMatch([]byte(strings.Repeat("x1 ", 100))) {
+		t.Fatal("IsMatch found a match without 'y'")
+	}
+	stats := engine.Stats()
+	if stats.PrefilterHits != 100 || stats.PrefilterMisses != 100 {
+		t.Errorf("PrefilterHits = %d, PrefilterMisses = %d, want 100, 100", stats.PrefilterHits, stats.PrefilterMisses)
+	}
+	if stats.PrefilterAbandoned != 0 {
+		t.Errorf("PrefilterAbandoned = %d, want 0", stats.PrefilterAbandoned)
+	}
+}
+
+func TestStatsPrefilterAbandoned(t *testing.T) {
+	// The tracker retires the prefilter at its 128th candidate without a
+	// match, and the search scans the rest of the haystack without it.
+	engine, err := CompileWithConfig(`x(?:\pL|\d)+y`, statsConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	misses := prefilter.DefaultTrackerConfig().WarmupPeriod - 1
+	haystack := []byte(strings.Repeat("x1 ", 150))
+	for name, search := range map[string]func() bool{
+		"IsMatch":       func() bool { return engine.IsMatch(haystack) },
+		"FindIndices":   func() bool { _, _, found := engine.FindIndices(haystack); return found },
+		"FindIndicesAt": func() bool { _, _, found := engine.FindIndicesAt(haystack, 1); return found },
+		"Count":         func() bool { return engine.Count(haystack, -1) > 0 },
+	} {
+		engine.ResetStats()
+		if search() {
+			t.Errorf("%s found a match without 'y'", name)
+		}
+		stats := engine.Stats()
+		if stats.PrefilterAbandoned != 1 || stats.PrefilterMisses != misses {
+			t.Errorf("%s: PrefilterAbandoned = %d, PrefilterMisses = %d, want 1, %d",
+				name, stats.PrefilterAbandoned, stats.PrefilterMisses, misses)
+		}
+	}
+}
+
+func TestStatsBacktrackerFallback(t *testing.T) {
+	engine, err := CompileWithConfig(`(\w+)\s(\d+)`, statsConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	bt := engine.boundedBacktracker
+	if bt == nil {
+		t.Skipf("%s engine has no BoundedBacktracker", engine.Strategy())
+	}
+	if !engine.canBacktrack(bt, 100) {
+		t.Fatal("canBacktrack(100) = false")
+	}
+	if engine.canBacktrack(bt, bt.MaxInputSize()+1) {
+		t.Fatal("canBacktrack past MaxInputSize = true")
+	}
+	if got := engine.Stats().NFAFallbacks; got != 1 {
+		t.Errorf("NFAFallbacks = %d, want 1", got)
+	}
+}
+
+func TestStatsConcurrent(t *testing.T) {
+	// Run with -race: searches update the counters while Stats reads them.
+	engine, err := CompileWithConfig(`(\w+)@(\w+)\.com`, statsConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	haystack := bytes.Repeat([]byte("user@example.com "), 10)
+	const goroutines, searches = 8, 50
+	var wg sync.WaitGroup
+	for range goroutines {
+		wg.Go(func() {
+			for range searches {
+				engine.FindAllSubmatch(haystack, -1)
+				_ = engine.Stats()
+			}
+		})
+	}
+	wg.Wait()
+	stats := engine.Stats()
+	if stats.Searches != goroutines*searches {
+		t.Errorf("Searches = %d, want %d", stats.Searches, goroutines*searches)
+	}
+	if got, want := stats.BytesScanned, uint64(goroutines*searches*len(haystack)); got != want {
+		t.Errorf("BytesScanned = %d, want %d", got, want)
+	}
+	if stats.NFASearches+stats.DFASearches+stats.OnePassSearches == 0 {
+		t.Errorf("no searches counted: %+v", stats)
+	}
+}
@@ -227,6 +227,18 @@ const (
 	// because UseReverseSuffix assumed match always starts at position 0.
 	// Reference: https://github.com/coregx/coregex/issues/97
 	UseMultilineReverseSuffix
+
+	// UseDenseDFA uses fully determinized, minimized DFAs (dfa/dense).
+	// Selected at compile time in place of UseDFA or UseBoth when:
+	//   - Config.DenseDFAMaxStates > 0 and the DFA stays under it
+	//   - The pattern has no complete literal prefilter
+	//   - The pattern has no anchors or word boundaries, unless it is
+	//     start-anchored (the reverse DFA cannot check them)
+	//
+	// A forward DFA finds the match end and a reverse DFA the match start.
+	// Both are read-only tables, so searches need no per-goroutine DFA cache
+	// and never fall back to the NFA on cache exhaustion.
+	UseDenseDFA
 )
 
 // String returns a human-readable representation of the Strategy.
@@ -266,6 +278,8 @@ func (s Strategy) String() string {
 		return "UseAnchoredLiteral"
 	case UseMultilineReverseSuffix:
 		return "UseMultilineReverseSuffix"
+	case UseDenseDFA:
+		return "UseDenseDFA"
 	default:
 		return "Unknown"
 	}
@@ -998,6 +1012,7 @@ func selectReverseStrategy(n *nfa.NFA, re *syntax.Regexp, literals *literal.Seq,
 		MaxLiterals:   config.MaxLiterals,
 		MaxLiteralLen: 64,
 		MaxClassSize:  10,
+		Bytes:         config.Bytes,
 	})
 
 	// MULTILINE CHECK FIRST (Issue #97): For patterns like `(?m)^/.*\.php`,
@@ -1562,6 +1577,7 @@ var strategyReasons = map[Strategy]string{
 	UseAhoCorasick:            "Aho-Corasick automaton for large literal alternations (50-500x for >32 pattern sets)",
 	UseAnchoredLiteral:        "O(1) specialized matching for ^prefix.*suffix$ patterns (50-90x faster than stdlib)",
 	UseMultilineReverseSuffix: "line-aware suffix prefilter for multiline patterns (5-20x for (?m)^.*\\.php patterns)",
+	UseDenseDFA:               "precompiled dense DFA for small patterns (read-only tables, no lazy DFA cache)",
 }
 
 // StrategyReason provides a human-readable explanation for strategy selection.
@@ -315,7 +315,7 @@ func TestCompile_VariousStrategies(t *testing.T) {
 // -----------------------------------------------------------------------------
 
 func TestEngine_Stats(t *testing.T) {
-	engine, err := Compile(`hello`)
+	engine, err := CompileWithConfig(`hello`, statsConfig())
 	if err != nil {
 		t.Fatal(err)
 	}
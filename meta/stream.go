@@ -0,0 +1,404 @@
+package meta
+
+import (
+	"errors"
+	"io"
+
+	"github.com/coregx/coregex/dfa/lazy"
+)
+
+// ErrStreamClosed is returned by Stream.Write after Close.
+var ErrStreamClosed = errors.New("regexp: write to closed stream")
+
+// ErrStreamUnsupported is returned by NewStream when the engine has no lazy
+// DFA to carry state across writes (EnableDFA is false, the pattern has
+// look-around or Unicode word boundaries, or the DFA could not be built).
+var ErrStreamUnsupported = errors.New("regexp: stream matching requires the lazy DFA")
+
+// StreamWindow is the maximum number of bytes of history a reader search
+// keeps in memory.
+//
+// Reader searches scan the input chunk by chunk with a lazy DFA whose state
+// is carried across chunk boundaries, so detecting a match needs no history.
+// The window is only used to recover the exact start (and captures) of the
+// match once the DFA has found one.
+//
+// When the window is full and no match has been found yet, the bytes before
+// the current position are dropped, provided that no match attempt in
+// progress started in them (lazy.StreamScanner.Fresh). The DFA state is
+// kept, so matches of any length are found with exact offsets. While an
+// attempt is in progress, and once a match has been found, the window grows
+// past StreamWindow instead: memory use exceeds the bound only by the length
+// of the match, or of an unfinished attempt, that needs the bytes.
+const StreamWindow = 1 << 20
+
+// streamChunkSize is the number of bytes requested per Read.
+const streamChunkSize = 64 << 10
+
+// MatchReader reports whether the data read from r contains a match.
+//
+// Reading stops at the first byte that proves a match. See StreamWindow for
+// the memory bound. Returns the first read error other than io.EOF.
+func (e *Engine) MatchReader(r io.Reader) (bool, error) {
+	loc, err := e.findReader(r, false, true)
+	return loc != nil, err
+}
+
+// FindReaderIndex returns the leftmost match in the data read from r as
+// absolute stream byte offsets [start, end], or nil if there is no match.
+//
+// Reading stops once the match can no longer change, so the reader is
+// usually not consumed to the end. See StreamWindow for the memory bound.
+// Returns the first read error other than io.EOF.
+func (e *Engine) FindReaderIndex(r io.Reader) ([]int, error) {
+	return e.findReader(r, false, false)
+}
+
+// FindReaderSubmatchIndex is like FindReaderIndex but also returns the
+// offsets of capture groups, in the layout of FindSubmatchIndex.
+func (e *Engine) FindReaderSubmatchIndex(r io.Reader) ([]int, error) {
+	return e.findReader(r, true, false)
+}
+
+// getStreamDFA returns the forward DFA used for reader searches, or nil if
+// the DFA is disabled or cannot be built for this pattern.
+func (e *Engine) getStreamDFA() *lazy.DFA {
+	e.streamOnce.Do(func() {
+		e.streamDFA = e.buildStreamDFA(true)
+	})
+	return e.streamDFA
+}
+
+// getPushDFA returns the forward DFA used by Stream. BreakAtMatch is off so
+// that the scan keeps reporting match ends after the first match, and since
+// a Stream keeps no data for another engine to resume from, the DFA has no
+// determinization limit: no state holds more than every NFA state.
+func (e *Engine) getPushDFA() *lazy.DFA {
+	e.pushOnce.Do(func() {
+		e.pushDFA = e.buildStreamDFA(false)
+	})
+	return e.pushDFA
+}
+
+// buildStreamDFA compiles an unanchored forward lazy DFA for streaming.
+// Returns nil if the DFA is disabled, cannot be built, or would not be exact.
+func (e *Engine) buildStreamDFA(breakAtMatch bool) *lazy.DFA {
+	// The DFA of a look-around pattern matches a superset of it, with
+	// Unicode word boundaries it quits on non-ASCII input, and it misses
+	// matches through some chains of assertions, such as \B^.
+	if !e.config.EnableDFA || e.nfa.HasLookaround() || e.nfa.HasUnicodeWordBoundary() || lazy.HasInexactLookChain(e.nfa) {
+		return nil
+	}
+	dfaConfig := lazy.DefaultConfig()
+	dfaConfig.MaxStates = e.config.MaxDFAStates //nolint:staticcheck // legacy API compat
+	dfaConfig.DeterminizationLimit = e.config.DeterminizationLimit
+	dfaConfig.BreakAtMatch = breakAtMatch
+	if !breakAtMatch {
+		dfaConfig.DeterminizationLimit = max(dfaConfig.DeterminizationLimit, e.nfa.States())
+	}
+	d, err := lazy.CompileWithConfig(e.nfa, dfaConfig)
+	if err != nil {
+		return nil
+	}
+	return d
+}
+
+// streamWindow is the history buffer of a reader search.
+// buf[0] is the byte at absolute stream offset base.
+type streamWindow struct {
+	buf  []byte
+	base int64
+}
+
+// read appends up to streamChunkSize bytes from r and returns them.
+func (w *streamWindow) read(r io.Reader) ([]byte, error) {
+	n := len(w.buf)
+	if cap(w.buf)-n < streamChunkSize {
+		grown := make([]byte, n, 2*cap(w.buf)+streamChunkSize)
+		copy(grown, w.buf)
+		w.buf = grown
+	}
+	m, err := r.Read(w.buf[n : n+streamChunkSize])
+	w.buf = w.buf[:n+m]
+	return w.buf[n:], err
+}
+
+// discard drops the history before absolute offset keep.
+func (w *streamWindow) discard(keep int64) {
+	drop := int(keep - w.base)
+	if drop <= 0 {
+		return
+	}
+	w.buf = w.buf[:copy(w.buf, w.buf[drop:])]
+	w.base = keep
+}
+
+// findReader is the shared reader search.
+//
+// Phase 1 scans the stream with the lazy DFA until a match is certain and
+// can no longer change: the DFA (leftmost-first, BreakAtMatch) goes dead
+// after having seen a match, or the stream ends. The DFA state is never
+// reset, and the window only drops bytes that no match attempt started in,
+// so every attempt the DFA tracked started inside the window.
+//
+// Phase 2 runs the regular engine over the window to get the exact match.
+// The window ends exactly where the DFA died, and no match attempt survives
+// that point, so the engine's view of it as end of text cannot create a
+// spurious match ($, \b), and the leftmost one is found.
+//
+// If the DFA gives up (determinization limit, cache too small), the search
+// finishes with the regular engine over the window and the rest of the
+// stream, read into memory. No attempt started in the dropped bytes, so the
+// leftmost match starts at or after from.
+//
+// In earliest mode only the existence of a match matters: Phase 1 stops at
+// the first one.
+func (e *Engine) findReader(r io.Reader, captures, earliest bool) ([]int, error) {
+	d := e.getStreamDFA()
+	if d == nil {
+		return e.findReaderBuffered(r, nil, 0, 0, captures)
+	}
+
+	scanner := d.NewStreamScanner(d.NewCache())
+	w := &streamWindow{}
+	var from int64 // matches start at or after this offset
+
+	matched := false
+	onMatch := func(int64) bool {
+		matched = true
+		return !earliest
+	}
+
+	eof := false
+	for !eof {
+		chunk, err := w.read(r)
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if _, ferr := scanner.Feed(chunk, onMatch); ferr != nil {
+			// The DFA cannot handle this input: finish without it.
+			return e.findReaderBuffered(r, w.buf, w.base, from, captures)
+		}
+		if eof = err == io.EOF; eof {
+			scanner.Finish(onMatch)
+		}
+
+		if matched && earliest {
+			return []int{}, nil
+		}
+		if eof || scanner.Dead() {
+			break // a complete match, or an anchored pattern that can no longer match
+		}
+
+		if !matched && int64(len(w.buf)) > StreamWindow && scanner.Fresh() {
+			// Window full: drop it, keeping one byte of look-behind context.
+			from = scanner.Offset()
+			w.discard(from - 1)
+		}
+	}
+
+	if !matched {
+		return nil, nil
+	}
+	end := int64(len(w.buf))
+	if !eof {
+		end = scanner.Offset() - w.base
+	}
+	if e.longest && !eof {
+		var err error
+		if end, err = e.longestReaderEnd(r, w, from); err != nil {
+			return nil, err
+		}
+	}
+	return e.resolveReaderMatch(w.buf[:end], w.base, from, captures), nil
+}
+
+// longestReaderEnd returns the window index at which a leftmost-longest
+// reader search can resolve its match, reading on as needed.
+//
+// The leftmost-first DFA went dead: the leftmost match is known, but a longer
+// one may start at the same position. An anchored scan from its start reads
+// until no attempt from there survives.
+func (e *Engine) longestReaderEnd(r io.Reader, w *streamWindow, from int64) (int64, error) {
+	start, _, found := e.FindIndicesAt(w.buf, int(from-w.base))
+	push := e.getPushDFA()
+	if !found || push == nil {
+		return int64(len(w.buf)), nil
+	}
+
+	scanner := push.NewStreamScanner(push.NewCache())
+	prev := -1
+	if start > 0 {
+		prev = int(w.buf[start-1])
+	}
+	scanner.RestartAnchored(w.base+int64(start), prev)
+	onMatch := func(int64) bool { return true }
+
+	chunk, err := w.buf[start:], error(nil)
+	for {
+		if _, ferr := scanner.Feed(chunk, onMatch); ferr != nil {
+			// The DFA gave up: resolve over the rest of the stream.
+			rest, rerr := io.ReadAll(r)
+			w.buf = append(w.buf, rest...)
+			return int64(len(w.buf)), rerr
+		}
+		if scanner.Dead() {
+			return scanner.Offset() - w.base, nil
+		}
+		if err == io.EOF {
+			return int64(len(w.buf)), nil
+		}
+		if chunk, err = w.read(r); err != nil && err != io.EOF {
+			return 0, err
+		}
+	}
+}
+
+// findReaderBuffered finishes a reader search without the DFA by reading the
+// rest of the stream into memory. buf holds already-read data starting at
+// absolute offset base; matches must start at or after from.
+func (e *Engine) findReaderBuffered(r io.Reader, buf []byte, base, from int64, captures bool) ([]int, error) {
+	rest, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return e.resolveReaderMatch(append(buf, rest...), base, from, captures), nil
+}
+
+// resolveReaderMatch finds the leftmost match in hay (which starts at
+// absolute offset base) beginning at or after absolute offset from, and
+// returns its absolute offsets.
+func (e *Engine) resolveReaderMatch(hay []byte, base, from int64, captures bool) []int {
+	at := int(from - base)
+	if at < 0 {
+		at = 0
+	}
+
+	if !captures {
+		start, end, found := e.FindIndicesAt(hay, at)
+		if !found {
+			return nil
+		}
+		return []int{int(base) + start, int(base) + end}
+	}
+
+	m := e.FindSubmatchAt(hay, at)
+	if m == nil {
+		return nil
+	}
+	n := m.NumCaptures()
+	loc := make([]int, 2*n)
+	for i := 0; i < n; i++ {
+		idx := m.GroupIndex(i)
+		if len(idx) >= 2 && idx[0] >= 0 {
+			loc[2*i] = int(base) + idx[0]
+			loc[2*i+1] = int(base) + idx[1]
+		} else {
+			loc[2*i] = -1
+			loc[2*i+1] = -1
+		}
+	}
+	return loc
+}
+
+// Stream matches a pattern against data pushed to it in pieces, such as
+// reassembled network packets, without keeping the data.
+//
+// The lazy DFA state, including the look-behind context (start of line, word
+// character) of the last byte, is carried from one Write to the next, so the
+// result does not depend on how the data is split. onMatch is called with the
+// absolute stream offset of every position at which a match ends; matches
+// are not de-overlapped and their starts are not reported (earliest-match
+// semantics). Matches that depend on end of text ($, \z, a trailing \b) are
+// reported by Close.
+//
+// Memory use is bounded by the DFA cache and independent of the stream
+// length. The cache is cleared whenever it fills up; if it cannot hold the
+// DFA states of a single byte, Write fails with lazy.ErrCacheFull instead of
+// growing it. A Stream is not safe for concurrent use.
+type Stream struct {
+	scanner *lazy.StreamScanner
+	report  func(end int64) bool
+	closed  bool
+
+	// err is the error of a failed Write, returned until Reset.
+	err error
+}
+
+// NewStream returns a Stream that calls onMatch for every match end.
+// Returns ErrStreamUnsupported if the engine cannot match streams.
+//
+// Example:
+//
+//	s, err := engine.NewStream(func(end int64) { log.Printf("match ending at %d", end) })
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for pkt := range packets {
+//	    s.Write(pkt)
+//	}
+//	s.Close()
+func (e *Engine) NewStream(onMatch func(end int64)) (*Stream, error) {
+	d := e.getPushDFA()
+	if d == nil {
+		return nil, ErrStreamUnsupported
+	}
+	return &Stream{
+		scanner: d.NewStreamScanner(d.NewCache()),
+		report: func(end int64) bool {
+			onMatch(end)
+			return true
+		},
+	}, nil
+}
+
+// Write scans p as the next piece of the stream. It implements io.Writer:
+// on success it returns len(p) and a nil error.
+//
+// If the DFA cache is too small for the pattern, Write returns the number of
+// bytes scanned and lazy.ErrCacheFull. Like bufio.Writer, the Stream then
+// accepts no more data: further writes and Close return the same error
+// until Reset.
+func (s *Stream) Write(p []byte) (int, error) {
+	if s.err != nil {
+		return 0, s.err
+	}
+	if s.closed {
+		return 0, ErrStreamClosed
+	}
+	if n, err := s.scanner.Feed(p, s.report); err != nil {
+		s.err = err
+		return n, err
+	}
+	// The report callback never stops the scan; a dead scanner (anchored
+	// pattern that can no longer match) simply ignores the rest.
+	return len(p), nil
+}
+
+// Close ends the stream, reporting a match that ends at the end of the data.
+// Further writes fail with ErrStreamClosed until Reset. After a failed Write,
+// Close reports nothing and returns the Write error.
+func (s *Stream) Close() error {
+	if s.err != nil {
+		s.closed = true
+		return s.err
+	}
+	if !s.closed {
+		s.closed = true
+		s.scanner.Finish(s.report)
+	}
+	return nil
+}
+
+// Offset returns the number of bytes written to the stream so far.
+func (s *Stream) Offset() int64 {
+	return s.scanner.Offset()
+}
+
+// Reset discards all state so the Stream can match a new stream.
+// The DFA cache is kept.
+func (s *Stream) Reset() {
+	s.scanner.Reset()
+	s.closed = false
+	s.err = nil
+}
@@ -0,0 +1,525 @@
+package meta
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/coregx/coregex/dfa/lazy"
+)
+
+// chunkReader returns at most n bytes per Read.
+type chunkReader struct {
+	data []byte
+	n    int
+	read int // total bytes handed out
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, io.EOF
+	}
+	k := min(r.n, len(p), len(r.data))
+	copy(p, r.data[:k])
+	r.data = r.data[k:]
+	r.read += k
+	return k, nil
+}
+
+var streamTests = []struct {
+	pattern string
+	input   string
+}{
+	{`foo`, "xx foo yy"},
+	{`foo`, "no match here"},
+	{`a+b`, "xaaaab aab"},
+	{`a.*b`, "a1b2b3\nab"},
+	{`a+?`, "baaa"},
+	{`(a|ab)(c|bcd)`, "xabcd"},
+	{`^abc`, "abcabc"},
+	{`^abc`, "xabc"},
+	{`abc$`, "abc abc"},
+	{`(?m)^b\w+$`, "a\nbcd\nbe"},
+	{`\bcat\b`, "concat cat"},
+	{`\Bat`, "at cat"},
+	{`x*`, "abc"},
+	{``, ""},
+	{`(\d+)-(\d+)`, "tel 555-1234 end"},
+	{`(?i)hello`, "say HeLLo"},
+	{`é+`, "caféé!"},
+	{`[^a]+`, "aaa\xffbaa"},
+	{`(foo)?bar`, "xbar"},
+	{`a$\b`, "a"},
+	{`$\B`, "ab "},
+	{`(\B)+$\B`, "b "},
+	// Priority order of the threads past \b and \B.
+	{`.\ba|(?:)?b\B|(.)b.\B|b`, "   bbb"},
+	{`(?:\b|\B)+(b)|\B.(?: )*?\bb\wb`, "xxx bab"},
+	// Leftmost-first priority of lazy quantifiers and empty alternatives.
+	{`(a+?)`, "aaa"},
+	{`(()| )\w?`, " _= "},
+	{`(?:(a|.))*?`, " _c"},
+	// Chains of assertions the DFA cannot follow.
+	{`\B\A`, " x"},
+	{`\z(?m:^)`, ""},
+	{`\z(?m:^)`, "a\n"},
+	{`\B^`, "=x"},
+	{`(?m:$)\B`, "a \n b"},
+}
+
+func TestFindReaderIndex(t *testing.T) {
+	for _, tt := range streamTests {
+		std := regexp.MustCompile(tt.pattern)
+		engine, err := Compile(tt.pattern)
+		if err != nil {
+			t.Fatalf("Compile(%q): %v", tt.pattern, err)
+		}
+		want := std.FindIndex([]byte(tt.input))
+		wantSub := std.FindSubmatchIndex([]byte(tt.input))
+
+		for _, n := range []int{1, 2, 3, 64 << 10} {
+			got, err := engine.FindReaderIndex(&chunkReader{data: []byte(tt.input), n: n})
+			if err != nil {
+				t.Fatalf("FindReaderIndex(%q): %v", tt.pattern, err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("%q chunk=%d FindReaderIndex(%q) = %v, want %v", tt.pattern, n, tt.input, got, want)
+			}
+
+			gotSub, err := engine.FindReaderSubmatchIndex(&chunkReader{data: []byte(tt.input), n: n})
+			if err != nil {
+				t.Fatalf("FindReaderSubmatchIndex(%q): %v", tt.pattern, err)
+			}
+			if !reflect.DeepEqual(gotSub, wantSub) {
+				t.Errorf("%q chunk=%d FindReaderSubmatchIndex(%q) = %v, want %v", tt.pattern, n, tt.input, gotSub, wantSub)
+			}
+
+			matched, err := engine.MatchReader(&chunkReader{data: []byte(tt.input), n: n})
+			if err != nil {
+				t.Fatalf("MatchReader(%q): %v", tt.pattern, err)
+			}
+			if matched != (want != nil) {
+				t.Errorf("%q chunk=%d MatchReader(%q) = %v, want %v", tt.pattern, n, tt.input, matched, want != nil)
+			}
+		}
+	}
+}
+
+// TestFindReaderMatchesFind checks the reader searches against the search of
+// the same bytes in memory, over random patterns and chunk sizes.
+func TestFindReaderMatchesFind(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		pattern := randomPattern(rng, 3)
+		engine, err := Compile(pattern)
+		if err != nil {
+			t.Fatalf("Compile(%q): %v", pattern, err)
+		}
+		for j := 0; j < 5; j++ {
+			input := randomHaystack(rng, 20)
+			n := 1 + rng.Intn(4)
+			want := engine.resolveReaderMatch(input, 0, 0, false)
+			wantSub := engine.resolveReaderMatch(input, 0, 0, true)
+
+			got, err := engine.FindReaderIndex(&chunkReader{data: input, n: n})
+			if err != nil || !reflect.DeepEqual(got, want) {
+				t.Errorf("%q chunk=%d FindReaderIndex(%q) = %v, %v; want %v", pattern, n, input, got, err, want)
+			}
+			gotSub, err := engine.FindReaderSubmatchIndex(&chunkReader{data: input, n: n})
+			if err != nil || !reflect.DeepEqual(gotSub, wantSub) {
+				t.Errorf("%q chunk=%d FindReaderSubmatchIndex(%q) = %v, %v; want %v", pattern, n, input, gotSub, err, wantSub)
+			}
+		}
+	}
+}
+
+// TestFindReaderLongStream checks offsets far into a stream spanning many
+// chunks, and that the search stops reading once the match is certain.
+func TestFindReaderLongStream(t *testing.T) {
+	engine, err := Compile(`ERROR \d+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	line := "INFO all good here\n"
+	var buf bytes.Buffer
+	for buf.Len() < 3<<20 {
+		buf.WriteString(line)
+	}
+	matchAt := buf.Len()
+	buf.WriteString("ERROR 42\n")
+	for buf.Len() < 6<<20 {
+		buf.WriteString(line)
+	}
+
+	r := &chunkReader{data: buf.Bytes(), n: 4096}
+	got, err := engine.FindReaderIndex(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{matchAt, matchAt + 8}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("FindReaderIndex = %v, want %v", got, want)
+	}
+	if r.read >= buf.Len() {
+		t.Errorf("read the whole stream (%d bytes); expected to stop after the match", r.read)
+	}
+}
+
+// TestFindReaderWindowDiscard checks that an unfinished match attempt longer
+// than the window does not hide a later match, even when it straddles the
+// point where the window filled up.
+func TestFindReaderWindowDiscard(t *testing.T) {
+	engine, err := Compile(`a[^z]*z|needle`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := "a" + strings.Repeat("b", 2*StreamWindow) + "needle"
+	got, err := engine.FindReaderIndex(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []int{len(input) - 6, len(input)}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FindReaderIndex = %v, want %v", got, want)
+	}
+
+	// Reads are 64 KiB, so the window first overflows right after byte
+	// StreamWindow+64KiB; put the match across that boundary.
+	boundary := StreamWindow + streamChunkSize
+	input = strings.Repeat("b", boundary-3) + "needle" + strings.Repeat("b", 100)
+	got, err = engine.FindReaderIndex(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []int{boundary - 3, boundary + 3}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("straddling: FindReaderIndex = %v, want %v", got, want)
+	}
+}
+
+// TestFindReaderLongMatch checks matches and unfinished match attempts longer
+// than StreamWindow against FindIndices.
+func TestFindReaderLongMatch(t *testing.T) {
+	long := strings.Repeat("c", 2*StreamWindow)
+	tests := []struct {
+		pattern string
+		longest bool
+		input   string
+	}{
+		{`a[^z]*b`, false, "a" + long + "b"},
+		{`a[^z]*b`, false, "a" + long},
+		{`a*b`, false, strings.Repeat("a", 2*StreamWindow) + "b"},
+		{`(?s)a.*`, false, "xa" + long},
+		{`a[^z]*z|needle`, false, "a" + long + "needle" + long},
+		{`a[^z]*z|needle`, false, "a" + long + "needle" + long + "z"},
+		{`a[^z]*b`, false, long + "zabz" + long},
+		{`a[^z]*b`, false, long + "zab" + long},
+		{`"[^"]*"`, false, long + `"quoted"` + long},
+		{`\w+@\w+`, false, strings.Repeat("a b ", StreamWindow) + "me@host"},
+		{`a|a[^z]*b`, true, "a" + long + "b"},
+		{`a|a[^z]*b`, true, "a" + long + "z"},
+		{`a|ab`, true, "ab" + long},
+	}
+
+	for _, tt := range tests {
+		engine, err := Compile(tt.pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		engine.SetLongest(tt.longest)
+		name := fmt.Sprintf("%s on %d bytes", tt.pattern, len(tt.input))
+
+		var want []int
+		if start, end, found := engine.FindIndices([]byte(tt.input)); found {
+			want = []int{start, end}
+		}
+		ok, err := engine.MatchReader(&chunkReader{data: []byte(tt.input), n: 1 << 20})
+		if err != nil || ok != (want != nil) {
+			t.Errorf("%s: MatchReader = %v, %v, want %v", name, ok, err, want != nil)
+		}
+		got, err := engine.FindReaderIndex(strings.NewReader(tt.input))
+		if err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: FindReaderIndex = %v, %v, want %v", name, got, err, want)
+		}
+	}
+}
+
+// TestFindReaderDeterminizationLimit checks that a reader search finishes
+// without the DFA when it gives up after the window has been dropped.
+func TestFindReaderDeterminizationLimit(t *testing.T) {
+	engine, err := Compile(`\d\pL`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := strings.Repeat("hello world ", 2*StreamWindow/12) + "1é"
+	want := []int{len(input) - 3, len(input)}
+
+	ok, err := engine.MatchReader(strings.NewReader(input))
+	if err != nil || !ok {
+		t.Errorf("MatchReader = %v, %v, want true", ok, err)
+	}
+	got, err := engine.FindReaderIndex(strings.NewReader(input))
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("FindReaderIndex = %v, %v, want %v", got, err, want)
+	}
+	got, err = engine.FindReaderSubmatchIndex(strings.NewReader(input))
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("FindReaderSubmatchIndex = %v, %v, want %v", got, err, want)
+	}
+}
+
+func TestFindReaderError(t *testing.T) {
+	engine, err := Compile(`foo`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	boom := errors.New("boom")
+	_, err = engine.FindReaderIndex(iotest.ErrReader(boom))
+	if !errors.Is(err, boom) {
+		t.Errorf("error = %v, want %v", err, boom)
+	}
+}
+
+func TestFindReaderNoDFA(t *testing.T) {
+	config := DefaultConfig()
+	config.EnableDFA = false
+	engine, err := CompileWithConfig(`b+`, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := engine.FindReaderIndex(strings.NewReader("aabbba"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{2, 5}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FindReaderIndex = %v, want %v", got, want)
+	}
+}
+
+// streamEnds writes input to a Stream in pieces of n bytes and returns the
+// reported match ends.
+func streamEnds(t *testing.T, engine *Engine, input string, n int) []int64 {
+	t.Helper()
+	var ends []int64
+	s, err := engine.NewStream(func(end int64) { ends = append(ends, end) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	for data := []byte(input); len(data) > 0; {
+		k := min(n, len(data))
+		if m, err := s.Write(data[:k]); err != nil || m != k {
+			t.Fatalf("Write = %d, %v; want %d, nil", m, err, k)
+		}
+		data = data[k:]
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return ends
+}
+
+func TestStream(t *testing.T) {
+	tests := []struct {
+		pattern string
+		input   string
+		want    []int64
+	}{
+		{`foo`, "xfoox foo", []int64{4, 9}},
+		{`foo`, "no match", nil},
+		{`a+`, "baab", []int64{2, 3}},
+		{`GET /admin`, "GET /adminGET /admin", []int64{10, 20}},
+		{`^abc`, "abcabc", []int64{3}},
+		{`abc$`, "abc abc", []int64{7}},
+		{`(?m)^b$`, "a\nb\nb", []int64{3, 5}},
+		{`\bcat\b`, "concat cat cats", []int64{10}},
+		{`foo\b`, "foo foox foo", []int64{3, 12}},
+		{`é`, "caféé", []int64{5, 7}},
+		{`x*`, "ab", []int64{0, 1, 2}},
+		{`a$\b`, "a", []int64{1}},
+		{`$\b`, "ab", []int64{2}},
+		// Past the default determinization limit.
+		{`\pL+`, "abc", []int64{1, 2, 3}},
+		{`\pL*`, "abc", []int64{0, 1, 2, 3}},
+		// Matches of lower priority than one ending earlier.
+		{`a\b|x`, "xa\n", []int64{1, 2}},
+		{`x|\Ba`, "xa", []int64{1, 2}},
+	}
+
+	for _, tt := range tests {
+		engine, err := Compile(tt.pattern)
+		if err != nil {
+			t.Fatalf("Compile(%q): %v", tt.pattern, err)
+		}
+		for _, n := range []int{1, 2, 3, 1 << 10} {
+			got := streamEnds(t, engine, tt.input, n)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%q on %q, chunks of %d: ends = %v, want %v", tt.pattern, tt.input, n, got, tt.want)
+			}
+		}
+	}
+}
+
+// TestStreamMatchesFindAll checks over random patterns and chunk sizes that
+// a Stream reports the end of every match FindAllIndex finds, and reports
+// ends in increasing order.
+func TestStreamMatchesFindAll(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		pattern := randomPattern(rng, 3)
+		engine, err := Compile(pattern)
+		if err != nil {
+			t.Fatalf("Compile(%q): %v", pattern, err)
+		}
+		if _, err := engine.NewStream(func(int64) {}); err != nil {
+			continue // chains of assertions the DFA cannot follow
+		}
+		std := regexp.MustCompile(pattern)
+		for j := 0; j < 5; j++ {
+			input := randomHaystack(rng, 20)
+			n := 1 + rng.Intn(4)
+			ends := streamEnds(t, engine, string(input), n)
+			reported := make(map[int64]bool, len(ends))
+			for k, end := range ends {
+				if k > 0 && end <= ends[k-1] {
+					t.Errorf("%q chunk=%d on %q: ends = %v, not increasing", pattern, n, input, ends)
+				}
+				reported[end] = true
+			}
+			for _, m := range std.FindAllIndex(input, -1) {
+				if !reported[int64(m[1])] {
+					t.Errorf("%q chunk=%d on %q: ends = %v, missing the end of %v", pattern, n, input, ends, m)
+				}
+			}
+		}
+	}
+}
+
+// TestStreamAssertionChain checks that a pattern with a chain of assertions
+// the DFA cannot follow is not streamed with missing matches.
+func TestStreamAssertionChain(t *testing.T) {
+	engine, err := Compile(`\B(?m:$)c?`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := engine.NewStream(func(int64) {}); !errors.Is(err, ErrStreamUnsupported) {
+		t.Errorf("NewStream err = %v, want ErrStreamUnsupported", err)
+	}
+}
+
+func TestStreamCloseAndReset(t *testing.T) {
+	engine, err := Compile(`ab`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ends []int64
+	s, err := engine.NewStream(func(end int64) { ends = append(ends, end) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Write([]byte("xa")); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Offset(); got != 2 {
+		t.Errorf("Offset = %d, want 2", got)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Write([]byte("b")); !errors.Is(err, ErrStreamClosed) {
+		t.Errorf("Write after Close: err = %v, want ErrStreamClosed", err)
+	}
+
+	s.Reset()
+	if _, err := s.Write([]byte("ab")); err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+	if want := []int64{2}; !reflect.DeepEqual(ends, want) {
+		t.Errorf("ends = %v, want %v", ends, want)
+	}
+}
+
+// TestStreamCacheFull checks that a Stream whose DFA cache cannot hold the
+// states of a single byte fails instead of growing the cache, and stays
+// failed until Reset.
+func TestStreamCacheFull(t *testing.T) {
+	engine, err := Compile(`\w+x`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dfaConfig := lazy.DefaultConfig()
+	dfaConfig.BreakAtMatch = false
+	dfaConfig.CacheCapacityBytes = 1
+	d, err := lazy.CompileWithConfig(engine.nfa, dfaConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Stream{
+		scanner: d.NewStreamScanner(d.NewCache()),
+		report:  func(int64) bool { return true },
+	}
+
+	n, err := s.Write([]byte("abx"))
+	if !errors.Is(err, lazy.ErrCacheFull) {
+		t.Fatalf("Write err = %v, want lazy.ErrCacheFull", err)
+	}
+	if n >= 3 || int64(n) != s.Offset() {
+		t.Errorf("Write n = %d, Offset = %d, want n < 3 and equal", n, s.Offset())
+	}
+	if n, err := s.Write([]byte("x")); n != 0 || !errors.Is(err, lazy.ErrCacheFull) {
+		t.Errorf("Write after failure = %d, %v, want 0, lazy.ErrCacheFull", n, err)
+	}
+	if err := s.Close(); !errors.Is(err, lazy.ErrCacheFull) {
+		t.Errorf("Close err = %v, want lazy.ErrCacheFull", err)
+	}
+
+	s.Reset()
+	if _, err := s.Write(nil); err != nil {
+		t.Errorf("Write after Reset: err = %v, want nil", err)
+	}
+}
+
+func TestStreamLong(t *testing.T) {
+	engine, err := Compile(`needle\d`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chunk := []byte(strings.Repeat("hay ", 1024))
+	var ends []int64
+	s, err := engine.NewStream(func(end int64) { ends = append(ends, end) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want []int64
+	for i := 0; i < 1000; i++ {
+		s.Write(chunk)
+		if i%100 == 0 {
+			s.Write([]byte("need"))
+			s.Write([]byte("le7"))
+			want = append(want, s.Offset())
+		}
+	}
+	s.Close()
+	if !reflect.DeepEqual(ends, want) {
+		t.Errorf("ends = %v, want %v", ends, want)
+	}
+}
+
+func TestStreamNoDFA(t *testing.T) {
+	config := DefaultConfig()
+	config.EnableDFA = false
+	engine, err := CompileWithConfig(`a`, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := engine.NewStream(func(int64) {}); !errors.Is(err, ErrStreamUnsupported) {
+		t.Errorf("NewStream err = %v, want ErrStreamUnsupported", err)
+	}
+}
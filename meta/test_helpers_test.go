@@ -3,6 +3,7 @@ package meta
 // Shared test helper functions for meta package tests.
 
 import (
+	"math/rand"
 	"testing"
 )
 
@@ -54,3 +55,40 @@ func minInt(a, b int) int {
 	}
 	return b
 }
+
+// randomPattern returns a random pattern of the given nesting depth, built
+// from the constructs whose priority and assertion handling differ between
+// engines: lazy quantifiers, empty alternatives and groups, and chains of
+// zero-width assertions. It is valid RE2 syntax, so regexp can check it.
+func randomPattern(rng *rand.Rand, depth int) string {
+	atoms := []string{
+		"a", "b", "x", ".", "(?s:.)", `\w`, `\s`, `\d`, "[ab]", "[^a]", "()",
+		`\b`, `\B`, "^", "$", "(?m:^)", "(?m:$)", `\A`, `\z`,
+	}
+	quantifiers := []string{"*", "+", "?", "*?", "+?", "??", "{1,3}", "{0,2}?"}
+	if depth <= 0 || rng.Intn(3) == 0 {
+		return atoms[rng.Intn(len(atoms))]
+	}
+	switch rng.Intn(5) {
+	case 0:
+		return randomPattern(rng, depth-1) + randomPattern(rng, depth-1)
+	case 1:
+		return randomPattern(rng, depth-1) + "|" + randomPattern(rng, depth-1)
+	case 2:
+		return "(" + randomPattern(rng, depth-1) + ")"
+	case 3:
+		return "(?:" + randomPattern(rng, depth-1) + ")" + quantifiers[rng.Intn(len(quantifiers))]
+	default:
+		return randomPattern(rng, depth-1) + randomPattern(rng, depth-1) + randomPattern(rng, depth-1)
+	}
+}
+
+// randomHaystack returns up to maxLen random bytes over a small alphabet of
+// word, non-word and newline characters.
+func randomHaystack(rng *rand.Rand, maxLen int) []byte {
+	b := make([]byte, rng.Intn(maxLen+1))
+	for i := range b {
+		b[i] = "ab x_1=\n"[rng.Intn(8)]
+	}
+	return b
+}
@@ -133,6 +133,16 @@ func (bcs *ByteClassSet) SetByte(b byte) {
 	bcs.SetRange(b, b)
 }
 
+// SetWordBoundary marks the ASCII word bytes [0-9A-Za-z_] as distinct from
+// the bytes around them, so that no class mixes word and non-word bytes.
+// Needed by word boundary assertions, which look at the class of a byte.
+func (bcs *ByteClassSet) SetWordBoundary() {
+	bcs.SetRange('0', '9')
+	bcs.SetRange('A', 'Z')
+	bcs.SetByte('_')
+	bcs.SetRange('a', 'z')
+}
+
 // setBit sets bit i in the bitset
 func (bcs *ByteClassSet) setBit(b byte) {
 	word := b / 64
@@ -64,6 +64,9 @@ type BacktrackerState struct {
 	// When true, explores all branches to find the longest match instead of
 	// returning on the first match found.
 	Longest bool
+
+	// lookaround holds the look-around results of the current search.
+	lookaround LookaroundMemo
 }
 
 // NewBoundedBacktracker creates a new bounded backtracker for the given NFA.
@@ -148,6 +151,7 @@ func (b *BoundedBacktracker) reset(state *BacktrackerState, haystackLen int) {
 	state.InputLen = haystackLen
 	state.NumStates = b.numStates
 	state.SpanStart = 0 // Default: span starts at beginning of haystack
+	state.lookaround.Reset()
 
 	// Calculate required size in entries
 	entriesNeeded := b.numStates * (haystackLen + 1)
@@ -360,8 +364,8 @@ func (b *BoundedBacktracker) backtrackWithState(haystack []byte, pos int, nfaSta
 		return b.backtrackWithState(haystack, pos, next, st)
 
 	case StateLook:
-		look, next := s.Look()
-		if checkLookAssertion(look, haystack, pos) {
+		_, next := s.Look()
+		if s.LookHolds(&st.lookaround, haystack, pos) {
 			return b.backtrackWithState(haystack, pos, next, st)
 		}
 		return false
@@ -456,8 +460,8 @@ func (b *BoundedBacktracker) backtrackFindWithState(haystack []byte, pos int, nf
 		return b.backtrackFindWithState(haystack, pos, next, st)
 
 	case StateLook:
-		look, next := s.Look()
-		if checkLookAssertion(look, haystack, pos) {
+		_, next := s.Look()
+		if s.LookHolds(&st.lookaround, haystack, pos) {
 			return b.backtrackFindWithState(haystack, pos, next, st)
 		}
 		return -1
@@ -555,8 +559,8 @@ func (b *BoundedBacktracker) backtrackFindLongestWithState(haystack []byte, pos
 		return b.backtrackFindLongestWithState(haystack, pos, next, st)
 
 	case StateLook:
-		look, next := s.Look()
-		if checkLookAssertion(look, haystack, pos) {
+		_, next := s.Look()
+		if s.LookHolds(&st.lookaround, haystack, pos) {
 			return b.backtrackFindLongestWithState(haystack, pos, next, st)
 		}
 		return -1
@@ -0,0 +1,233 @@
+package nfa
+
+import (
+	"fmt"
+
+	"github.com/coregx/coregex/internal/conv"
+	"github.com/coregx/coregex/internal/wire"
+)
+
+// AppendBinary implements encoding.BinaryAppender. It appends the states,
+// start states, capture names and byte classes of the NFA, including the
+// bodies of look-around assertions, to b.
+//
+// The format is internal to this version of coregex; it is meant to be
+// embedded in the versioned engine format of the meta package.
+func (n *NFA) AppendBinary(b []byte) ([]byte, error) {
+	b = wire.AppendUint(b, uint64(len(n.states)))
+	for i := range n.states {
+		var err error
+		if b, err = n.states[i].appendBinary(b); err != nil {
+			return nil, err
+		}
+	}
+	b = wire.AppendUint(b, uint64(n.startAnchored))
+	b = wire.AppendUint(b, uint64(n.startUnanchored))
+	b = wire.AppendBool(b, n.anchored)
+	b = wire.AppendBool(b, n.utf8)
+	b = wire.AppendInt(b, n.patternCount)
+	b = wire.AppendInt(b, n.captureCount)
+	b = wire.AppendUint(b, uint64(len(n.captureNames)))
+	for _, name := range n.captureNames {
+		b = wire.AppendString(b, name)
+	}
+	return wire.AppendBytes(b, n.byteClasses.classes[:]), nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (n *NFA) MarshalBinary() ([]byte, error) {
+	return n.AppendBinary(nil)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. It replaces n with
+// the NFA encoded by AppendBinary, after checking that every state
+// reference and capture index is in range.
+func (n *NFA) UnmarshalBinary(data []byte) error {
+	r := wire.NewReader(data)
+	decoded := readNFA(r)
+	if err := r.Done(); err != nil {
+		return err
+	}
+	*n = *decoded
+	return nil
+}
+
+// appendBinary appends the kind of s and the fields that kind uses.
+func (s *State) appendBinary(b []byte) ([]byte, error) {
+	b = append(b, byte(s.kind))
+	switch s.kind {
+	case StateMatch:
+		b = wire.AppendUint(b, uint64(s.pattern))
+	case StateByteRange:
+		b = append(b, s.lo, s.hi)
+		b = wire.AppendUint(b, uint64(s.next))
+	case StateSparse:
+		b = wire.AppendUint(b, uint64(len(s.transitions)))
+		for _, t := range s.transitions {
+			b = append(b, t.Lo, t.Hi)
+			b = wire.AppendUint(b, uint64(t.Next))
+		}
+	case StateSplit:
+		b = wire.AppendUint(b, uint64(s.left))
+		b = wire.AppendUint(b, uint64(s.right))
+		b = wire.AppendBool(b, s.isQuantifierSplit)
+	case StateCapture:
+		b = wire.AppendUint(b, uint64(s.captureIndex))
+		b = wire.AppendBool(b, s.captureStart)
+		b = wire.AppendUint(b, uint64(s.next))
+	case StateLook:
+		b = append(b, byte(s.look))
+		b = wire.AppendUint(b, uint64(s.next))
+		if s.look.IsLookaround() {
+			body, err := s.lookaround.nfa.AppendBinary(nil)
+			if err != nil {
+				return nil, err
+			}
+			b = wire.AppendInt(b, s.lookaround.maxLen)
+			b = wire.AppendBytes(b, body)
+		}
+	case StateEpsilon, StateRuneAny, StateRuneAnyNotNL:
+		b = wire.AppendUint(b, uint64(s.next))
+	case StateFail:
+	default:
+		return nil, fmt.Errorf("%w: state %d has kind %v", ErrInvalidState, s.id, s.kind)
+	}
+	return b, nil
+}
+
+// readNFA decodes an NFA written by AppendBinary. Errors are recorded in r.
+func readNFA(r *wire.Reader) *NFA {
+	states := make([]State, r.Len())
+	for i := range states {
+		states[i] = readState(r, StateID(conv.IntToUint32(i)))
+	}
+	n := &NFA{
+		states:          states,
+		startAnchored:   StateID(r.Uint32()),
+		startUnanchored: StateID(r.Uint32()),
+		anchored:        r.Bool(),
+		utf8:            r.Bool(),
+		patternCount:    r.Int(),
+		captureCount:    r.Int(),
+	}
+	if names := r.Len(); names > 0 {
+		n.captureNames = make([]string, names)
+		for i := range n.captureNames {
+			n.captureNames[i] = r.String()
+		}
+	}
+	if err := n.byteClasses.UnmarshalBinary(r.Bytes()); err != nil {
+		r.Fail(err)
+	}
+	if r.Err() != nil {
+		return n
+	}
+
+	if n.patternCount < 1 || n.captureCount < 1 ||
+		(n.captureNames != nil && len(n.captureNames) != n.captureCount) {
+		r.Fail(wire.ErrInvalid)
+		return n
+	}
+	b := &Builder{states: states, startAnchored: n.startAnchored, startUnanchored: n.startUnanchored}
+	if err := b.Validate(); err != nil {
+		r.Fail(err)
+		return n
+	}
+	for i := range states {
+		s := &states[i]
+		switch {
+		case !s.hasNext():
+			r.Fail(&BuildError{Message: "transition to no state", StateID: s.id})
+		case s.kind == StateCapture && int(s.captureIndex) >= n.captureCount:
+			r.Fail(&BuildError{Message: fmt.Sprintf("capture index %d out of range", s.captureIndex), StateID: s.id})
+		case s.kind == StateMatch && int(s.pattern) >= n.patternCount:
+			r.Fail(&BuildError{Message: fmt.Sprintf("pattern %d out of range", s.pattern), StateID: s.id})
+		case s.kind == StateLook && s.lookaround != nil:
+			n.hasLookaround = true
+		case s.kind == StateLook && (s.look == LookWordBoundaryUnicode || s.look == LookNoWordBoundaryUnicode):
+			n.hasUnicodeWordBoundary = true
+		}
+	}
+	return n
+}
+
+// readState decodes a state written by State.appendBinary.
+func readState(r *wire.Reader, id StateID) State {
+	s := State{id: id, kind: StateKind(r.Byte())}
+	switch s.kind {
+	case StateMatch:
+		s.pattern = PatternID(r.Uint32())
+	case StateByteRange:
+		s.lo, s.hi = r.Byte(), r.Byte()
+		s.next = StateID(r.Uint32())
+	case StateSparse:
+		s.transitions = make([]Transition, r.Len())
+		for i := range s.transitions {
+			s.transitions[i] = Transition{Lo: r.Byte(), Hi: r.Byte(), Next: StateID(r.Uint32())}
+		}
+	case StateSplit:
+		s.left = StateID(r.Uint32())
+		s.right = StateID(r.Uint32())
+		s.isQuantifierSplit = r.Bool()
+	case StateCapture:
+		s.captureIndex = r.Uint32()
+		s.captureStart = r.Bool()
+		s.next = StateID(r.Uint32())
+	case StateLook:
+		s.look = Look(r.Byte())
+		s.next = StateID(r.Uint32())
+		if s.look > LookNoWordBoundaryUnicode {
+			r.Fail(wire.ErrInvalid)
+			break
+		}
+		if s.look.IsLookaround() {
+			maxLen := r.Int()
+			body := wire.NewReader(r.Bytes())
+			bodyNFA := readNFA(body)
+			if err := body.Done(); err != nil || maxLen < 0 {
+				r.Fail(wire.ErrInvalid)
+				break
+			}
+			s.lookaround = &Lookaround{look: s.look, nfa: bodyNFA, maxLen: maxLen}
+		}
+	case StateEpsilon, StateRuneAny, StateRuneAnyNotNL:
+		s.next = StateID(r.Uint32())
+	case StateFail:
+	default:
+		r.Fail(wire.ErrInvalid)
+	}
+	return s
+}
+
+// AppendBinary implements encoding.BinaryAppender. It appends the class of
+// each of the 256 byte values.
+func (bc *ByteClasses) AppendBinary(b []byte) ([]byte, error) {
+	return append(b, bc.classes[:]...), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler for data written by
+// AppendBinary.
+func (bc *ByteClasses) UnmarshalBinary(data []byte) error {
+	if len(data) != len(bc.classes) {
+		return wire.ErrInvalid
+	}
+	copy(bc.classes[:], data)
+	return nil
+}
+
+// hasNext reports whether every transition of s that consumes input, records
+// a capture or checks an assertion has a target. The compiler leaves only
+// epsilon transitions without a target, and the engines skip only those.
+func (s *State) hasNext() bool {
+	switch s.kind {
+	case StateByteRange, StateCapture, StateLook, StateRuneAny, StateRuneAnyNotNL:
+		return s.next != InvalidState
+	case StateSparse:
+		for _, t := range s.transitions {
+			if t.Next == InvalidState {
+				return false
+			}
+		}
+	}
+	return true
+}
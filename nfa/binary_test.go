@@ -0,0 +1,102 @@
+package nfa
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestNFABinaryRoundTrip(t *testing.T) {
+	patterns := []string{`a`, `(?P<user>\w+)@(\w+)\.com`, `[α-ω]+|x*`, `foo(?=bar)|(?<!x)baz`, `\bword\b`}
+	for _, pattern := range patterns {
+		n, err := NewDefaultCompiler().Compile(pattern)
+		if err != nil {
+			t.Fatalf("Compile(%q): %v", pattern, err)
+		}
+		data, err := n.MarshalBinary()
+		if err != nil {
+			t.Fatalf("%q: MarshalBinary: %v", pattern, err)
+		}
+		var loaded NFA
+		if err := loaded.UnmarshalBinary(data); err != nil {
+			t.Fatalf("%q: UnmarshalBinary: %v", pattern, err)
+		}
+		if !reflect.DeepEqual(&loaded, n) {
+			t.Errorf("%q: loaded NFA differs from the original", pattern)
+		}
+	}
+}
+
+func TestNFAUnmarshalBinaryErrors(t *testing.T) {
+	n, err := NewDefaultCompiler().Compile(`(\w+)@(\w+)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := n.MarshalBinary()
+	var loaded NFA
+	for i := range len(data) {
+		if loaded.UnmarshalBinary(data[:i]) == nil {
+			t.Fatalf("UnmarshalBinary(data[:%d]) succeeded", i)
+		}
+	}
+	for i := range data {
+		corrupt := bytes.Clone(data)
+		corrupt[i] ^= 0xff
+		if loaded.UnmarshalBinary(corrupt) == nil {
+			NewPikeVM(&loaded).Search([]byte("a@b c@d"))
+		}
+	}
+}
+
+// TestNFAUnmarshalBinaryDanglingStates checks that UnmarshalBinary rejects
+// NFAs that consume input, record a capture or check an assertion without
+// a target state, or have no group 0, which no compiled NFA has and which
+// the engines would index out of range.
+func TestNFAUnmarshalBinaryDanglingStates(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(n *NFA)
+	}{
+		{"byte range", func(n *NFA) { n.states[n.find(StateByteRange)].next = InvalidState }},
+		{"sparse", func(n *NFA) { n.states[n.find(StateSparse)].transitions[0].Next = InvalidState }},
+		{"capture", func(n *NFA) { n.states[n.find(StateCapture)].next = InvalidState }},
+		{"look", func(n *NFA) { n.states[n.find(StateLook)].next = InvalidState }},
+		{"look-around body", func(n *NFA) {
+			body := n.states[n.find(StateLook)].lookaround.nfa
+			body.states[body.find(StateByteRange)].next = InvalidState
+		}},
+		{"no groups", func(n *NFA) {
+			n.captureCount = 0
+			for i := range n.states {
+				if n.states[i].kind == StateCapture {
+					n.states[i].kind = StateEpsilon
+				}
+			}
+		}},
+	}
+	for _, tt := range tests {
+		n, err := NewDefaultCompiler().Compile(`(\w+)(?=b)[a-c]`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tt.mutate(n)
+		data, err := n.MarshalBinary()
+		if err != nil {
+			t.Fatalf("%s: MarshalBinary: %v", tt.name, err)
+		}
+		var loaded NFA
+		if loaded.UnmarshalBinary(data) == nil {
+			t.Errorf("%s: UnmarshalBinary succeeded", tt.name)
+		}
+	}
+}
+
+// find returns the first state of the given kind.
+func (n *NFA) find(kind StateKind) StateID {
+	for i := range n.states {
+		if n.states[i].kind == kind {
+			return StateID(i)
+		}
+	}
+	panic("no state of kind " + kind.String())
+}
@@ -40,6 +40,19 @@ func (b *Builder) AddMatch() StateID {
 	return id
 }
 
+// AddMatchPattern adds a match state for the given pattern and returns its ID.
+// Used when building multi-pattern NFAs, where each pattern has its own
+// match state so that searches can report which pattern matched.
+func (b *Builder) AddMatchPattern(pattern PatternID) StateID {
+	id := StateID(conv.IntToUint32(len(b.states)))
+	b.states = append(b.states, State{
+		id:      id,
+		kind:    StateMatch,
+		pattern: pattern,
+	})
+	return id
+}
+
 // AddByteRange adds a state that transitions on a single byte or byte range [lo, hi].
 // For a single byte, set lo == hi.
 func (b *Builder) AddByteRange(lo, hi byte, next StateID) StateID {
@@ -147,6 +160,21 @@ func (b *Builder) AddCapture(captureIndex uint32, isStart bool, next StateID) St
 // look is the assertion type (start/end of text/line).
 // next is the state to transition to if the assertion succeeds.
 func (b *Builder) AddLook(look Look, next StateID) StateID {
+	switch look {
+	case LookStartLine, LookEndLine:
+		// The DFA resolves multiline ^ and $ on '\n': it must not share a
+		// class with the bytes around it.
+		b.byteClassSet.SetRange('\n', '\n')
+	case LookWordBoundary, LookNoWordBoundary:
+		// The DFA resolves \b on the byte it consumes: word and non-word
+		// bytes must not share a class.
+		b.byteClassSet.SetWordBoundary()
+	case LookWordBoundaryUnicode, LookNoWordBoundaryUnicode:
+		// Non-ASCII bytes also get classes of their own: the DFA gives up
+		// on them.
+		b.byteClassSet.SetWordBoundary()
+		b.byteClassSet.SetRange(0x80, 0xFF)
+	}
 	id := StateID(conv.IntToUint32(len(b.states)))
 	b.states = append(b.states, State{
 		id:   id,
@@ -157,6 +185,21 @@ func (b *Builder) AddLook(look Look, next StateID) StateID {
 	return id
 }
 
+// AddLookaround adds a look-ahead or look-behind assertion state that runs
+// the compiled body l at the current position.
+// next is the state to transition to if the assertion succeeds.
+func (b *Builder) AddLookaround(l *Lookaround, next StateID) StateID {
+	id := StateID(conv.IntToUint32(len(b.states)))
+	b.states = append(b.states, State{
+		id:         id,
+		kind:       StateLook,
+		look:       l.look,
+		lookaround: l,
+		next:       next,
+	})
+	return id
+}
+
 // AddRuneAny adds a state that matches any Unicode codepoint (including newlines).
 // This is used for (?s). (dot with DOTALL flag).
 // The state consumes 1-4 bytes (UTF-8 encoded rune) and transitions to next.
@@ -324,6 +367,17 @@ func (b *Builder) Build(opts ...BuildOption) (*NFA, error) {
 		patternCount:    1,
 		byteClasses:     b.byteClassSet.ByteClasses(), // Finalize byte classes
 	}
+	for i := range b.states {
+		if b.states[i].kind != StateLook {
+			continue
+		}
+		switch {
+		case b.states[i].lookaround != nil:
+			nfa.hasLookaround = true
+		case b.states[i].look == LookWordBoundaryUnicode || b.states[i].look == LookNoWordBoundaryUnicode:
+			nfa.hasUnicodeWordBoundary = true
+		}
+	}
 
 	// Apply user options
 	for _, opt := range opts {
@@ -12,6 +12,7 @@ import "regexp/syntax"
 //
 // NOT supported:
 //   - char_class* patterns (zero-width matches not handled by CharClassSearcher)
+//   - Non-greedy char_class+? patterns (the searcher finds the longest run)
 //   - Patterns with anchors (^, $)
 //   - Patterns with alternation outside char class
 //   - Patterns with concatenation (abc[\w]+)
@@ -24,7 +25,7 @@ func ExtractCharClassRanges(re *syntax.Regexp) [][2]byte {
 	// Must be OpPlus of a char class (NOT OpStar)
 	// OpStar requires zero-width match support which CharClassSearcher doesn't handle.
 	// For [0-9]* on "A", the result should be true (zero-width match at position 0).
-	if re.Op != syntax.OpPlus {
+	if re.Op != syntax.OpPlus || re.Flags&syntax.NonGreedy != 0 {
 		return nil
 	}
 
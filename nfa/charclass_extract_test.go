@@ -22,10 +22,11 @@ func TestExtractCharClassRanges(t *testing.T) {
 		{`[abc]+`, 1}, // Go optimizes consecutive chars [a-c] to single range
 
 		// Not supported - no quantifier
-		{`abc`, -1},    // No quantifier
-		{`[a-z]`, -1},  // No quantifier (need + or *)
-		{`[a-z]?`, -1}, // ? not supported
-		{`a+`, -1},     // Single char, not char class
+		{`abc`, -1},     // No quantifier
+		{`[a-z]`, -1},   // No quantifier (need + or *)
+		{`[a-z]?`, -1},  // ? not supported
+		{`[a-z]+?`, -1}, // Non-greedy
+		{`a+`, -1},      // Single char, not char class
 
 		// Not supported - complex patterns
 		{`[a-z]+[0-9]+`, -1}, // Concatenation
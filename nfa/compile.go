@@ -40,9 +40,25 @@ type CompilerConfig struct {
 	// standard byte-range states, just organized as sparse instead of splits.
 	UseRuneStates bool
 
+	// Bytes compiles in byte mode: a rune r <= 0xFF in the pattern matches
+	// the single byte r instead of its UTF-8 encoding, '.' matches any byte
+	// but '\n', and class ranges are clipped to 0x00-0xFF. Literal runes
+	// above 0xFF are a compile error. Case folding is limited to ASCII letters.
+	Bytes bool
+
 	// MaxRecursionDepth limits recursion during compilation to prevent stack overflow
 	// Default: 100
 	MaxRecursionDepth int
+
+	// SyntaxFlags are the regexp/syntax flags Compile parses patterns with.
+	// The zero value means syntax.Perl.
+	SyntaxFlags syntax.Flags
+
+	// UnicodeWordBoundary compiles \b and \B to LookWordBoundaryUnicode and
+	// LookNoWordBoundaryUnicode, whose word chars are Unicode letters, marks,
+	// decimal digits and connector punctuation instead of [0-9A-Za-z_].
+	// Ignored in byte mode.
+	UnicodeWordBoundary bool
 }
 
 // DefaultCompilerConfig returns a compiler configuration with sensible defaults
@@ -84,7 +100,11 @@ func NewDefaultCompiler() *Compiler {
 // Compile compiles a regex pattern string into an NFA
 func (c *Compiler) Compile(pattern string) (*NFA, error) {
 	// Parse the pattern using regexp/syntax
-	re, err := syntax.Parse(pattern, syntax.Perl)
+	flags := c.config.SyntaxFlags
+	if flags == 0 {
+		flags = syntax.Perl
+	}
+	re, err := Parse(pattern, flags)
 	if err != nil {
 		return nil, &CompileError{
 			Pattern: pattern,
@@ -161,6 +181,81 @@ func (c *Compiler) CompileRegexp(re *syntax.Regexp) (*NFA, error) {
 	return nfa, nil
 }
 
+// CompileSet compiles several parsed patterns into a single multi-pattern NFA.
+//
+// Each pattern gets its own match state tagged with its index in res, so
+// engines that walk the NFA can report which patterns matched (see
+// State.MatchPattern). The anchored start state is an alternation over all
+// pattern starts in order; the unanchored start adds the usual (?s:.)*? prefix
+// unless every pattern is anchored.
+//
+// Capture groups are compiled but their indices are per-pattern and may
+// overlap; multi-pattern NFAs are intended for matching, not for extracting
+// submatches.
+func (c *Compiler) CompileSet(res []*syntax.Regexp) (*NFA, error) {
+	if len(res) == 0 {
+		return nil, &CompileError{
+			Err: fmt.Errorf("%w: empty pattern set", ErrInvalidPattern),
+		}
+	}
+
+	c.builder = NewBuilder()
+	c.depth = 0
+	c.captureCount = 0
+	c.captureNames = nil
+
+	allAnchored := true
+	starts := make([]StateID, 0, len(res))
+	for i, re := range res {
+		c.countCapturesRecursive(re)
+		if !c.isPatternAnchored(re) {
+			allAnchored = false
+		}
+
+		patternStart, patternEnd, err := c.compileRegexp(re)
+		if err != nil {
+			return nil, err
+		}
+
+		matchID := c.builder.AddMatchPattern(PatternID(conv.IntToUint32(i)))
+		if err := c.builder.Patch(patternEnd, matchID); err != nil {
+			epsilonID := c.builder.AddEpsilon(matchID)
+			if patchErr := c.builder.Patch(patternEnd, epsilonID); patchErr != nil {
+				return nil, &CompileError{
+					Err: fmt.Errorf("failed to connect pattern %d to match state: %w", i, patchErr),
+				}
+			}
+		}
+		starts = append(starts, patternStart)
+	}
+
+	// Alternation over all patterns. Plain (non-quantifier) splits keep the
+	// leftmost-first priority order equal to the pattern order.
+	anchoredStart := c.buildSplitChain(starts)
+
+	var unanchoredStart StateID
+	if c.config.Anchored || allAnchored {
+		unanchoredStart = anchoredStart
+	} else {
+		unanchoredStart = c.compileUnanchoredPrefix(anchoredStart)
+	}
+	c.builder.SetStarts(anchoredStart, unanchoredStart)
+
+	nfa, err := c.builder.Build(
+		WithUTF8(c.config.UTF8),
+		WithAnchored(c.config.Anchored || allAnchored),
+		WithPatternCount(len(res)),
+		WithCaptureCount(c.captureCount+1),
+	)
+	if err != nil {
+		return nil, &CompileError{
+			Err: err,
+		}
+	}
+
+	return nfa, nil
+}
+
 // compileRegexp recursively compiles a syntax.Regexp node.
 // Returns (start, end) state IDs for the compiled fragment.
 // The 'end' state is a state that needs to be patched to continue the automaton.
@@ -217,13 +312,24 @@ func (c *Compiler) compileRegexp(re *syntax.Regexp) (start, end StateID, err err
 		return id, id, nil
 	case syntax.OpWordBoundary:
 		// \b - word boundary (transition between word and non-word chars)
-		id := c.builder.AddLook(LookWordBoundary, InvalidState)
+		look := LookWordBoundary
+		if c.unicodeWordBoundary() {
+			look = LookWordBoundaryUnicode
+		}
+		id := c.builder.AddLook(look, InvalidState)
 		return id, id, nil
 	case syntax.OpNoWordBoundary:
 		// \B - non-word boundary (no transition between word and non-word chars)
-		id := c.builder.AddLook(LookNoWordBoundary, InvalidState)
+		look := LookNoWordBoundary
+		if c.unicodeWordBoundary() {
+			look = LookNoWordBoundaryUnicode
+		}
+		id := c.builder.AddLook(look, InvalidState)
 		return id, id, nil
 	case syntax.OpEmptyMatch:
+		if look, body, ok := lookaroundOf(re); ok {
+			return c.compileLookaround(look, body)
+		}
 		return c.compileEmptyMatch()
 	default:
 		return InvalidState, InvalidState, &CompileError{
@@ -313,9 +419,15 @@ func (c *Compiler) compileFoldCaseRune(r rune, prev StateID, first *StateID) (St
 // compileCaseSensitiveRune compiles a single rune in case-sensitive mode
 // by converting it to UTF-8 bytes and chaining ByteRange states
 func (c *Compiler) compileCaseSensitiveRune(r rune, prev StateID, first *StateID) (StateID, error) {
+	if c.config.Bytes && r > 0xFF {
+		return InvalidState, &CompileError{
+			Err: fmt.Errorf("%w: %q is not a byte value", ErrInvalidPattern, r),
+		}
+	}
+
 	// Convert rune to UTF-8 bytes
 	buf := make([]byte, 4)
-	n := encodeRune(buf, r)
+	n := c.encodeRune(buf, r)
 
 	for i := 0; i < n; i++ {
 		b := buf[i]
@@ -337,7 +449,7 @@ func (c *Compiler) compileCaseSensitiveRune(r rune, prev StateID, first *StateID
 // compileSingleRune compiles a single rune to UTF-8 byte sequence
 func (c *Compiler) compileSingleRune(r rune) (start, end StateID, err error) {
 	buf := make([]byte, 4)
-	n := encodeRune(buf, r)
+	n := c.encodeRune(buf, r)
 
 	var prev = InvalidState
 	var first = InvalidState
@@ -359,6 +471,16 @@ func (c *Compiler) compileSingleRune(r rune) (start, end StateID, err error) {
 	return first, prev, nil
 }
 
+// encodeRune writes the bytes matched by rune r into buf: its UTF-8
+// encoding, or the single byte r in byte mode.
+func (c *Compiler) encodeRune(buf []byte, r rune) int {
+	if c.config.Bytes {
+		buf[0] = byte(r)
+		return 1
+	}
+	return encodeRune(buf, r)
+}
+
 // isASCIILetter checks if a rune is an ASCII letter (a-z, A-Z)
 func isASCIILetter(r rune) bool {
 	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
@@ -390,11 +512,18 @@ func (c *Compiler) compileCharClass(ranges []rune) (start, end StateID, err erro
 	// Character class ranges are pairs: [lo1, hi1, lo2, hi2, ...]
 	// For UTF-8, we need to handle multi-byte sequences
 
-	// Simple case: ASCII character class
+	if c.config.Bytes {
+		ranges = clipToBytes(ranges)
+		if len(ranges) == 0 {
+			return c.compileNoMatch()
+		}
+	}
+
+	// Simple case: ASCII character class (or any class in byte mode)
 	// Check if all ranges are ASCII
 	allASCII := true
 	for _, r := range ranges {
-		if r > 127 {
+		if r > 127 && !c.config.Bytes {
 			allASCII = false
 			break
 		}
@@ -436,6 +565,19 @@ func (c *Compiler) compileCharClass(ranges []rune) (start, end StateID, err erro
 	return c.compileUnicodeClass(ranges)
 }
 
+// clipToBytes returns the part of the class ranges that lies in 0x00-0xFF.
+func clipToBytes(ranges []rune) []rune {
+	clipped := make([]rune, 0, len(ranges))
+	for i := 0; i+1 < len(ranges); i += 2 {
+		lo, hi := ranges[i], ranges[i+1]
+		if lo > 0xFF {
+			break // ranges are sorted
+		}
+		clipped = append(clipped, lo, min(hi, 0xFF))
+	}
+	return clipped
+}
+
 // compileUnicodeClass handles Unicode character classes by building UTF-8 automata
 func (c *Compiler) compileUnicodeClass(ranges []rune) (start, end StateID, err error) {
 	// For MVP: convert to alternation of individual characters
@@ -975,6 +1117,9 @@ func (c *Compiler) utf8Cont2HiFull(leadVal, cont1Val, hiLead, hiCont1, hiCont2 b
 // This is used for OpAnyChar which the parser generates when DotNL flag is set
 // (either globally via syntax.DotNL or locally via inline flag (?s:...)).
 func (c *Compiler) compileAnyChar() (start, end StateID, err error) {
+	if c.config.Bytes {
+		return c.compileCharClass([]rune{0x00, 0xFF})
+	}
 	// UseRuneStates mode: compile '.' as a single sparse state mapping
 	// each leading byte range to the correct continuation chain.
 	// This eliminates ~9 split states, giving PikeVM O(1) dispatch
@@ -993,6 +1138,9 @@ func (c *Compiler) compileAnyChar() (start, end StateID, err error) {
 
 // compileAnyCharNotNL compiles '.' matching any character except \n
 func (c *Compiler) compileAnyCharNotNL() (start, end StateID, err error) {
+	if c.config.Bytes {
+		return c.compileCharClass([]rune{0x00, '\n' - 1, '\n' + 1, 0xFF})
+	}
 	// UseRuneStates mode: compile '.' as a single sparse state mapping
 	// each leading byte range to the correct continuation chain.
 	// This eliminates ~9 split states, giving PikeVM O(1) dispatch
@@ -1564,6 +1712,11 @@ func (c *Compiler) compileRepeatRange(sub *syntax.Regexp, minCount, maxCount int
 	return c.compileConcat(subs)
 }
 
+// unicodeWordBoundary reports whether \b and \B use Unicode word chars.
+func (c *Compiler) unicodeWordBoundary() bool {
+	return c.config.UnicodeWordBoundary && !c.config.Bytes
+}
+
 // compileEmptyMatch compiles an epsilon transition (matches without consuming input)
 func (c *Compiler) compileEmptyMatch() (start, end StateID, err error) {
 	id := c.builder.AddEpsilon(InvalidState)
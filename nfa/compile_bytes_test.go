@@ -0,0 +1,70 @@
+package nfa
+
+import (
+	"errors"
+	"testing"
+)
+
+func mustCompileBytes(t *testing.T, pattern string) *NFA {
+	t.Helper()
+	config := DefaultCompilerConfig()
+	config.UTF8 = false
+	config.Bytes = true
+	n, err := NewCompiler(config).Compile(pattern)
+	if err != nil {
+		t.Fatalf("Compile(%q) in byte mode: %v", pattern, err)
+	}
+	return n
+}
+
+// TestCompileBytes checks that byte mode compiles literals, classes and dot
+// to single bytes rather than UTF-8 sequences.
+func TestCompileBytes(t *testing.T) {
+	tests := []struct {
+		name     string
+		pattern  string
+		haystack string
+		wantPos  []int // [start, end] or nil for no match
+	}{
+		{"high byte literal", `\xFF`, "a\xffb", []int{1, 2}},
+		{"latin-1 literal is one byte", `é`, "caf\xc3\xa9 caf\xe9", []int{9, 10}},
+		{"byte range", `[\x80-\xFF]+`, "ab\x80\x90\xffc", []int{2, 5}},
+		{"class clipped to bytes", `[\x{F0}-\x{10FFFF}]`, "\xef\xf0", []int{1, 2}},
+		{"class above bytes never matches", `a[\x{100}-\x{200}]`, "a\xc4\x80", nil},
+		{"dot is one byte", `a.b`, "a\xffb", []int{0, 3}},
+		{"dot excludes newline", `a.b`, "a\nb", nil},
+		{"any byte", `(?s)a.b`, "a\nb", []int{0, 3}},
+		{"dot on invalid UTF-8", `.+`, "\xff\xfe", []int{0, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vm := NewPikeVM(mustCompileBytes(t, tt.pattern))
+			start, end, matched := vm.Search([]byte(tt.haystack))
+
+			if tt.wantPos == nil {
+				if matched {
+					t.Errorf("expected no match, got (%d, %d)", start, end)
+				}
+				return
+			}
+			if !matched {
+				t.Errorf("expected match at %v, got no match", tt.wantPos)
+				return
+			}
+			if start != tt.wantPos[0] || end != tt.wantPos[1] {
+				t.Errorf("got (%d, %d), want (%d, %d)", start, end, tt.wantPos[0], tt.wantPos[1])
+			}
+		})
+	}
+}
+
+func TestCompileBytesRejectsNonByte(t *testing.T) {
+	config := DefaultCompilerConfig()
+	config.UTF8 = false
+	config.Bytes = true
+	_, err := NewCompiler(config).Compile(`a中`)
+	if !errors.Is(err, ErrInvalidPattern) {
+		t.Errorf("err = %v, want ErrInvalidPattern", err)
+	}
+}
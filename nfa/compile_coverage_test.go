@@ -577,3 +577,21 @@ func TestCompile_UTF83ByteRange_SurrogateEdge(t *testing.T) {
 		})
 	}
 }
+
+// TestCompile_SyntaxFlags checks that Compile parses with CompilerConfig.SyntaxFlags.
+func TestCompile_SyntaxFlags(t *testing.T) {
+	config := DefaultCompilerConfig()
+	config.SyntaxFlags = syntax.Perl | syntax.FoldCase
+	n, err := NewCompiler(config).Compile(`hello`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !NewPikeVM(n).IsMatch([]byte("HeLLo")) {
+		t.Error("FoldCase: IsMatch(HeLLo) = false, want true")
+	}
+
+	// The zero value parses Perl syntax (\d is a Perl class).
+	if _, err := NewCompiler(CompilerConfig{}).Compile(`\d`); err != nil {
+		t.Errorf("zero SyntaxFlags: %v", err)
+	}
+}
@@ -0,0 +1,85 @@
+package nfa
+
+import (
+	"errors"
+	"reflect"
+	"regexp/syntax"
+	"testing"
+)
+
+func parseSet(t *testing.T, patterns []string) []*syntax.Regexp {
+	t.Helper()
+	res := make([]*syntax.Regexp, len(patterns))
+	for i, p := range patterns {
+		re, err := syntax.Parse(p, syntax.Perl)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", p, err)
+		}
+		res[i] = re
+	}
+	return res
+}
+
+func TestCompileSetPatternIDs(t *testing.T) {
+	res := parseSet(t, []string{"a", "b", "c"})
+	n, err := NewDefaultCompiler().CompileSet(res)
+	if err != nil {
+		t.Fatalf("CompileSet: %v", err)
+	}
+	if n.PatternCount() != 3 {
+		t.Fatalf("PatternCount() = %d, want 3", n.PatternCount())
+	}
+
+	seen := make(map[PatternID]bool)
+	for id := StateID(0); int(id) < n.States(); id++ {
+		if n.IsMatch(id) {
+			seen[n.MatchPattern(id)] = true
+		}
+	}
+	for pid := PatternID(0); pid < 3; pid++ {
+		if !seen[pid] {
+			t.Errorf("no match state for pattern %d", pid)
+		}
+	}
+}
+
+func TestCompileSetEmpty(t *testing.T) {
+	_, err := NewDefaultCompiler().CompileSet(nil)
+	if err == nil {
+		t.Fatal("CompileSet(nil) succeeded, want error")
+	}
+	if !errors.Is(err, ErrInvalidPattern) {
+		t.Errorf("CompileSet(nil) error = %v, want ErrInvalidPattern", err)
+	}
+}
+
+func TestPikeVMWhichOverlappingMatches(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		input    string
+		want     []bool
+	}{
+		{"disjoint", []string{"foo", "bar", "baz"}, "xx bar yy", []bool{false, true, false}},
+		{"overlapping", []string{"foo", "o+", "fo"}, "foo", []bool{true, true, true}},
+		{"none", []string{"a", "b"}, "xyz", []bool{false, false}},
+		{"anchored", []string{"^a", "a$", "b"}, "ba", []bool{false, true, true}},
+		{"empty pattern", []string{"", "z"}, "abc", []bool{true, false}},
+		{"word boundary", []string{`\bcat\b`, `cat`}, "concat", []bool{false, true}},
+		{"classes", []string{`\d+`, `[a-z]+`, `\s`}, "abc 123", []bool{true, true, true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := NewDefaultCompiler().CompileSet(parseSet(t, tt.patterns))
+			if err != nil {
+				t.Fatalf("CompileSet: %v", err)
+			}
+			matched := make([]bool, len(tt.patterns))
+			NewPikeVM(n).WhichOverlappingMatches([]byte(tt.input), matched)
+			if !reflect.DeepEqual(matched, tt.want) {
+				t.Errorf("matched = %v, want %v", matched, tt.want)
+			}
+		})
+	}
+}
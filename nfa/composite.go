@@ -85,8 +85,9 @@ func extractCompositeCharClassParts(re *syntax.Regexp) []*charClassPart {
 }
 
 // extractSinglePart extracts a single char class part from a quantified char class.
+// Non-greedy quantifiers are not supported: parts match greedily.
 func extractSinglePart(re *syntax.Regexp) *charClassPart {
-	if re == nil {
+	if re == nil || re.Flags&syntax.NonGreedy != 0 {
 		return nil
 	}
 
@@ -253,6 +254,7 @@ func (c *CompositeSearcher) matchAtWithBacktrack(haystack []byte, pos int, partI
 //   - Each sub-pattern must be a quantified char class (OpPlus, OpStar, OpQuest, OpRepeat)
 //   - At least 2 parts
 //   - No anchors, no captures, no alternations
+//   - No non-greedy quantifiers (the searcher matches greedily)
 func IsCompositeCharClassPattern(re *syntax.Regexp) bool {
 	if re == nil || re.Op != syntax.OpConcat {
 		return false
@@ -273,7 +275,7 @@ func IsCompositeCharClassPattern(re *syntax.Regexp) bool {
 
 // isValidCompositePart checks if a sub-expression is valid for composite searcher.
 func isValidCompositePart(re *syntax.Regexp) bool {
-	if re == nil {
+	if re == nil || re.Flags&syntax.NonGreedy != 0 {
 		return false
 	}
 
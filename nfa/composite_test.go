@@ -113,6 +113,7 @@ func TestIsCompositeCharClassPattern(t *testing.T) {
 		"[a-z]+|[0-9]+",  // Alternation
 		"^[a-z]+[0-9]+",  // Anchored
 		"([a-z]+)[0-9]+", // With capture
+		"[a-z]+?[0-9]+",  // Non-greedy
 	}
 
 	for _, pattern := range invalid {
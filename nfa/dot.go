@@ -0,0 +1,95 @@
+package nfa
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/coregx/coregex/internal/graph"
+)
+
+// WriteDOT writes the NFA as a Graphviz DOT graph, for debugging.
+//
+// Byte transitions are labeled with their byte ranges; epsilon transitions
+// (splits, captures, assertions) are dashed, and a split labels its
+// branches 1 and 2 in priority order. Render it with, for example,
+// `dot -Tsvg nfa.dot > nfa.svg`.
+func (n *NFA) WriteDOT(w io.Writer) error {
+	return n.graph().WriteDOT(w)
+}
+
+// WriteJSON writes the graph of WriteDOT as JSON: a list of nodes, one per
+// state, and a list of edges with their byte ranges.
+func (n *NFA) WriteJSON(w io.Writer) error {
+	return n.graph().WriteJSON(w)
+}
+
+// graph returns the state graph of the NFA.
+func (n *NFA) graph() *graph.Graph {
+	g := &graph.Graph{Name: "nfa", Nodes: make([]graph.Node, 0, len(n.states))}
+	for i := range n.states {
+		s := &n.states[i]
+		node := graph.Node{ID: uint32(s.id), Label: fmt.Sprintf("%d\n%s", s.id, s.kind), Match: s.kind == StateMatch}
+		if s.id == n.startAnchored {
+			node.Starts = append(node.Starts, "anchored")
+		}
+		if s.id == n.startUnanchored {
+			node.Starts = append(node.Starts, "unanchored")
+		}
+		g.Nodes = append(g.Nodes, node)
+		g.Edges = append(g.Edges, s.edges()...)
+	}
+	return g
+}
+
+// edges returns the outgoing transitions of s.
+func (s *State) edges() []graph.Edge {
+	from := uint32(s.id)
+	epsilon := func(to StateID, label string) graph.Edge {
+		return graph.Edge{From: from, To: uint32(to), Label: label}
+	}
+	consume := func(to StateID, label string, ranges ...[2]byte) graph.Edge {
+		if label == "" {
+			label = graph.RangesLabel(ranges)
+		}
+		return graph.Edge{From: from, To: uint32(to), Label: label, Ranges: ranges}
+	}
+
+	switch s.kind {
+	case StateByteRange:
+		return []graph.Edge{consume(s.next, "", [2]byte{s.lo, s.hi})}
+	case StateSparse:
+		// One edge per target, in order of first appearance.
+		var edges []graph.Edge
+		index := make(map[StateID]int)
+		for _, t := range s.transitions {
+			i, ok := index[t.Next]
+			if !ok {
+				i = len(edges)
+				index[t.Next] = i
+				edges = append(edges, graph.Edge{From: from, To: uint32(t.Next)})
+			}
+			edges[i].Ranges = append(edges[i].Ranges, [2]byte{t.Lo, t.Hi})
+		}
+		for i := range edges {
+			edges[i].Label = graph.RangesLabel(edges[i].Ranges)
+		}
+		return edges
+	case StateSplit:
+		return []graph.Edge{epsilon(s.left, "1"), epsilon(s.right, "2")}
+	case StateEpsilon:
+		return []graph.Edge{epsilon(s.next, "ε")}
+	case StateCapture:
+		boundary := "end"
+		if s.captureStart {
+			boundary = "start"
+		}
+		return []graph.Edge{epsilon(s.next, fmt.Sprintf("capture %d %s", s.captureIndex, boundary))}
+	case StateLook:
+		return []graph.Edge{epsilon(s.next, s.look.String())}
+	case StateRuneAny:
+		return []graph.Edge{consume(s.next, "any rune", [2]byte{0x00, 0xff})}
+	case StateRuneAnyNotNL:
+		return []graph.Edge{consume(s.next, `any rune but \n`, [2]byte{0x00, '\n' - 1}, [2]byte{'\n' + 1, 0xff})}
+	}
+	return nil
+}
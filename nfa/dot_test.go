@@ -0,0 +1,93 @@
+package nfa
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestNFAWriteDOT(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{`a`, []string{"digraph nfa {", `label="a"`, "shape=doublecircle", `label="unanchored"`, `label="anchored"`}},
+		{`[a-c]x`, []string{`label="[a-c]"`, `label="x"`}},
+		{`a|b`, []string{`label="1", style=dashed`, `label="2", style=dashed`}},
+		{`(a)`, []string{`label="capture 1 start"`, `label="capture 1 end"`}},
+		{`^a$`, []string{`label="StartText", style=dashed`, `label="EndText", style=dashed`}},
+		{`\x00\n`, []string{`label="\\x00"`, `label="\\x0a"`}},
+	}
+	for _, tt := range tests {
+		n, err := NewDefaultCompiler().Compile(tt.pattern)
+		if err != nil {
+			t.Fatalf("Compile(%q): %v", tt.pattern, err)
+		}
+		var b bytes.Buffer
+		if err := n.WriteDOT(&b); err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(b.String(), want) {
+				t.Errorf("%q: WriteDOT output lacks %q:\n%s", tt.pattern, want, b.String())
+			}
+		}
+	}
+}
+
+func TestNFAWriteJSON(t *testing.T) {
+	n, err := NewDefaultCompiler().Compile(`a[0-9]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	if err := n.WriteJSON(&b); err != nil {
+		t.Fatal(err)
+	}
+	var g struct {
+		Nodes []struct {
+			ID uint32 `json:"id"`
+		} `json:"nodes"`
+		Edges []struct {
+			From   uint32    `json:"from"`
+			To     uint32    `json:"to"`
+			Ranges [][2]byte `json:"ranges"`
+		} `json:"edges"`
+	}
+	if err := json.Unmarshal(b.Bytes(), &g); err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Nodes) != n.States() {
+		t.Errorf("%d nodes, want %d", len(g.Nodes), n.States())
+	}
+	var digits bool
+	for _, e := range g.Edges {
+		if int(e.To) >= n.States() {
+			t.Errorf("edge %d -> %d points past the last state", e.From, e.To)
+		}
+		if len(e.Ranges) == 1 && e.Ranges[0] == [2]byte{'0', '9'} {
+			digits = true
+		}
+	}
+	if !digits {
+		t.Errorf("no [0-9] edge in %s", b.String())
+	}
+}
+
+func TestLook_String(t *testing.T) {
+	tests := []struct {
+		look Look
+		want string
+	}{
+		{LookStartText, "StartText"},
+		{LookStartLine, "StartLine"},
+		{LookWordBoundary, "WordBoundary"},
+		{Look(255), "Unknown"},
+	}
+	for _, tt := range tests {
+		if got := tt.look.String(); got != tt.want {
+			t.Errorf("Look(%d).String() = %q, want %q", tt.look, got, tt.want)
+		}
+	}
+}
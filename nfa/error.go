@@ -28,6 +28,10 @@ var (
 	// ErrInvalidConfig indicates invalid configuration was provided
 	ErrInvalidConfig = errors.New("invalid NFA configuration")
 
+	// ErrUnsupportedLookaround indicates a look-around assertion the engines
+	// cannot evaluate, such as a look-behind of unbounded length
+	ErrUnsupportedLookaround = errors.New("unsupported look-around")
+
 	// ErrNoMatch indicates no match was found (not an error, used internally)
 	ErrNoMatch = errors.New("no match found")
 )
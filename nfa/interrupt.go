@@ -0,0 +1,90 @@
+package nfa
+
+// InterruptCheckInterval is the amount of work (see Interrupt) done between
+// two polls of an Interrupt's Done channel. Searches stop at most this much
+// work after Done is closed.
+const InterruptCheckInterval = 4 << 10
+
+// Interrupt lets a caller abort a long-running search.
+//
+// Search engines that accept an Interrupt charge it for the work they do and
+// stop early, reporting no match, once Spend returns true. The caller tells
+// an aborted search from a real miss with Stopped.
+//
+// Work is counted in engine steps: one unit per haystack byte scanned by a
+// DFA, one unit per thread per byte stepped by the PikeVM. Done is polled
+// every InterruptCheckInterval units, so the overhead is negligible; Budget
+// is exact, a search stops as soon as it is exceeded, however small.
+//
+// An Interrupt is used by one search at a time and is not safe for concurrent
+// use.
+type Interrupt struct {
+	// Done aborts the search once closed (typically context.Context.Done()).
+	// Nil means never.
+	Done <-chan struct{}
+
+	// Budget is the maximum amount of work before the search is aborted.
+	// Zero or negative means unlimited.
+	Budget int64
+
+	spent   int64
+	pending int
+	stopped bool
+}
+
+// Spend charges work units and reports whether the search must stop.
+func (in *Interrupt) Spend(work int) bool {
+	in.pending += work
+	if in.pending < InterruptCheckInterval &&
+		(in.Budget <= 0 || in.spent+int64(in.pending) <= in.Budget) {
+		return in.stopped
+	}
+	return in.check()
+}
+
+// Allowance returns how much work can be done before the next check that
+// matters: the rest of the check interval, or just past the budget if that
+// is closer. Engines that charge in chunks, like the DFA, size their chunks
+// with it so that small budgets are not overrun.
+func (in *Interrupt) Allowance() int {
+	n := InterruptCheckInterval - in.pending
+	if in.Budget > 0 {
+		if left := in.Budget - in.spent - int64(in.pending) + 1; left < int64(n) {
+			n = int(max(left, 1))
+		}
+	}
+	return max(n, 1)
+}
+
+// check settles pending work and polls Done.
+func (in *Interrupt) check() bool {
+	in.spent += int64(in.pending)
+	in.pending = 0
+	if in.Budget > 0 && in.spent > in.Budget {
+		in.stopped = true
+	}
+	if in.Done != nil {
+		select {
+		case <-in.Done:
+			in.stopped = true
+		default:
+		}
+	}
+	return in.stopped
+}
+
+// Stopped reports whether a search charged to this Interrupt was aborted.
+func (in *Interrupt) Stopped() bool {
+	return in.stopped
+}
+
+// Spent returns the total work charged so far.
+func (in *Interrupt) Spent() int64 {
+	return in.spent + int64(in.pending)
+}
+
+// BudgetExhausted reports whether the search was stopped by Budget rather
+// than by Done.
+func (in *Interrupt) BudgetExhausted() bool {
+	return in.stopped && in.Budget > 0 && in.spent > in.Budget
+}
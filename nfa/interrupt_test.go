@@ -0,0 +1,69 @@
+package nfa
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInterruptSpend(t *testing.T) {
+	in := &Interrupt{Budget: 3 * InterruptCheckInterval}
+	for i := 0; i < 3; i++ {
+		if in.Spend(InterruptCheckInterval) {
+			t.Fatalf("stopped after %d units, budget %d", in.Spent(), in.Budget)
+		}
+	}
+	if !in.Spend(InterruptCheckInterval) || !in.Stopped() || !in.BudgetExhausted() {
+		t.Errorf("budget exceeded but not stopped: Stopped() = %v", in.Stopped())
+	}
+
+	in = &Interrupt{Budget: 10}
+	if got := in.Allowance(); got != 11 {
+		t.Errorf("Allowance() = %d, want 11 (just past the budget)", got)
+	}
+	if in.Spend(10) {
+		t.Error("stopped at the budget")
+	}
+	if !in.Spend(1) || !in.BudgetExhausted() || in.Spent() != 11 {
+		t.Errorf("small budget exceeded: Stopped() = %v, Spent() = %d", in.Stopped(), in.Spent())
+	}
+
+	done := make(chan struct{})
+	in = &Interrupt{Done: done}
+	if in.Spend(InterruptCheckInterval) {
+		t.Error("stopped before Done was closed")
+	}
+	close(done)
+	if in.Spend(1) {
+		t.Error("checked before InterruptCheckInterval units")
+	}
+	if !in.Spend(InterruptCheckInterval) || in.BudgetExhausted() {
+		t.Errorf("Done closed: Stopped() = %v, BudgetExhausted() = %v", in.Stopped(), in.BudgetExhausted())
+	}
+}
+
+func TestPikeVMInterrupt(t *testing.T) {
+	n, err := NewCompiler(DefaultCompilerConfig()).Compile(`(a+)+b`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	haystack := []byte(strings.Repeat("a", 20*InterruptCheckInterval))
+
+	vm := NewPikeVM(n)
+	in := &Interrupt{Budget: InterruptCheckInterval}
+	vm.SetInterrupt(in)
+	if _, _, found := vm.SearchWithSlotTableAt(haystack, 0, SearchModeFind); found {
+		t.Error("aborted search reported a match")
+	}
+	if m := vm.SearchWithSlotTableCapturesAt(haystack, 0); m != nil {
+		t.Error("aborted capture search reported a match")
+	}
+	if !in.Stopped() {
+		t.Error("Stopped() = false after exhausting the budget")
+	}
+
+	vm.SetInterrupt(nil)
+	haystack = append(haystack, 'b')
+	if start, end, found := vm.SearchWithSlotTableAt(haystack, 0, SearchModeFind); !found || start != 0 || end != len(haystack) {
+		t.Errorf("without interrupt: got (%d, %d, %v)", start, end, found)
+	}
+}
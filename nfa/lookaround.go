@@ -0,0 +1,689 @@
+package nfa
+
+import (
+	"errors"
+	"fmt"
+	"regexp/syntax"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+	"unsafe"
+
+	"github.com/coregx/coregex/internal/conv"
+	"github.com/coregx/coregex/internal/scan"
+	"github.com/coregx/coregex/internal/sparse"
+)
+
+// Look-around assertions.
+//
+// regexp/syntax does not parse (?=re), (?!re), (?<=re) or (?<!re). Parse
+// rewrites each such group into a named capture, parses the result with the
+// caller's flags (so inline flags such as (?i) apply to the body as usual),
+// and replaces the capture by a look-around node: an OpEmptyMatch whose Name
+// is lookaroundName, with the Look kind in Min and the body in Sub[0]. Code
+// that does not know these nodes sees an empty match; the compiler turns them
+// into Look states holding the body compiled as a second, anchored NFA.
+//
+// Evaluation is linear in the input. A search keeps a LookaroundMemo, in
+// which each body is run over the input once and the positions where it
+// matches are kept in a bitmap:
+//   - look-ahead walks the body backward from the end of the input, which
+//     decides every position it passes
+//   - look-behind runs the body forward, starting a thread at every
+//     position, and records where matches end; it is only supported for
+//     bodies of bounded length
+
+// lookaroundName is the Name of the look-around nodes made by Parse. It
+// cannot be the name of a capture group.
+const lookaroundName = "\x00lookaround"
+
+// lookaroundCapture is the name prefix of the captures Parse puts in place
+// of look-around groups before parsing.
+const lookaroundCapture = "__coregex_lookaround_"
+
+// lookaroundOpeners maps the opening text of each look-around group to its
+// kind.
+var lookaroundOpeners = []struct {
+	text string
+	look Look
+}{
+	{"(?=", LookAhead},
+	{"(?!", LookAheadNeg},
+	{"(?<=", LookBehind},
+	{"(?<!", LookBehindNeg},
+}
+
+// lookaroundGroup is a look-around group of a pattern.
+type lookaroundGroup struct {
+	look   Look
+	opener string // "(?=", "(?!", "(?<=" or "(?<!"
+	start  int    // offset of the opening parenthesis
+	end    int    // offset after the closing parenthesis, -1 if unclosed
+}
+
+// Parse parses pattern like syntax.Parse, and also accepts the look-around
+// groups (?=re), (?!re), (?<=re) and (?<!re) when flags include PerlX.
+//
+// Look-around groups are returned as nodes that the Compiler compiles into
+// look-around Look states; other code sees them as empty matches (see
+// IsLookaround). Capture groups inside a look-around and look-behind bodies
+// that can match unbounded input are rejected with ErrUnsupportedLookaround.
+func Parse(pattern string, flags syntax.Flags) (*syntax.Regexp, error) {
+	var groups []lookaroundGroup
+	if flags&(syntax.PerlX|syntax.Literal) == syntax.PerlX {
+		groups = findLookarounds(pattern)
+	}
+	if len(groups) == 0 {
+		return syntax.Parse(pattern, flags)
+	}
+
+	var b strings.Builder
+	restore := make([]string, 0, 2*len(groups))
+	prev := 0
+	for i, g := range groups {
+		capture := "(?P<" + lookaroundCapture + strconv.Itoa(i) + ">"
+		b.WriteString(pattern[prev:g.start])
+		b.WriteString(capture)
+		prev = g.start + len(g.opener)
+		restore = append(restore, capture, g.opener)
+	}
+	b.WriteString(pattern[prev:])
+
+	re, err := syntax.Parse(b.String(), flags)
+	if err != nil {
+		// Quote the pattern as written, not the rewritten one.
+		var serr *syntax.Error
+		if errors.As(err, &serr) {
+			expr := strings.NewReplacer(restore...).Replace(serr.Expr)
+			return nil, &syntax.Error{Code: serr.Code, Expr: expr}
+		}
+		return nil, err
+	}
+
+	p := &lookaroundParser{pattern: pattern, groups: groups}
+	root := &syntax.Regexp{Op: syntax.OpConcat, Sub: []*syntax.Regexp{re}}
+	if err := p.rewrite(root); err != nil {
+		return nil, err
+	}
+	re = root.Sub[0]
+	sort.Ints(p.removed)
+	p.renumber(re)
+	return re, nil
+}
+
+// findLookarounds returns the look-around groups of pattern in the order of
+// their opening parentheses. Escapes, \Q...\E and character classes are
+// skipped with the same rules as the parser.
+func findLookarounds(pattern string) []lookaroundGroup {
+	var groups []lookaroundGroup
+	var open []int // per open group: index in groups, or -1
+	sc := scan.New(pattern)
+	for {
+		tok, ok := sc.Next()
+		if !ok {
+			break
+		}
+		if tok.Kind != scan.Byte {
+			continue
+		}
+		switch i := tok.Start; pattern[i] {
+		case '(':
+			idx := -1
+			for _, o := range lookaroundOpeners {
+				if strings.HasPrefix(pattern[i:], o.text) {
+					idx = len(groups)
+					groups = append(groups, lookaroundGroup{look: o.look, opener: o.text, start: i, end: -1})
+					break
+				}
+			}
+			open = append(open, idx)
+
+		case ')':
+			if n := len(open); n > 0 {
+				if idx := open[n-1]; idx >= 0 {
+					groups[idx].end = i + 1
+				}
+				open = open[:n-1]
+			}
+		}
+	}
+	return groups
+}
+
+// lookaroundParser turns the placeholder captures of a parsed pattern into
+// look-around nodes.
+type lookaroundParser struct {
+	pattern string
+	groups  []lookaroundGroup
+	removed []int // capture indices of the placeholders
+}
+
+// rewrite replaces the placeholder captures below re, innermost first.
+func (p *lookaroundParser) rewrite(re *syntax.Regexp) error {
+	for i, sub := range re.Sub {
+		if err := p.rewrite(sub); err != nil {
+			return err
+		}
+		if sub.Op != syntax.OpCapture || !strings.HasPrefix(sub.Name, lookaroundCapture) {
+			continue
+		}
+		idx, err := strconv.Atoi(strings.TrimPrefix(sub.Name, lookaroundCapture))
+		if err != nil || idx >= len(p.groups) {
+			continue // a user group that happens to use the prefix
+		}
+		node, err := p.lookaround(p.groups[idx], sub)
+		if err != nil {
+			return err
+		}
+		p.removed = append(p.removed, sub.Cap)
+		re.Sub[i] = node
+	}
+	return nil
+}
+
+// lookaround checks the body of a look-around group and returns its node.
+func (p *lookaroundParser) lookaround(g lookaroundGroup, capture *syntax.Regexp) (*syntax.Regexp, error) {
+	text := p.pattern[g.start:]
+	if g.end >= 0 {
+		text = p.pattern[g.start:g.end]
+	}
+	body := capture.Sub[0]
+	if hasCapture(body) {
+		return nil, fmt.Errorf("%w: capture groups are not supported in look-around: `%s`",
+			ErrUnsupportedLookaround, text)
+	}
+	if (g.look == LookBehind || g.look == LookBehindNeg) && maxMatchLen(body) < 0 {
+		return nil, fmt.Errorf("%w: look-behind must have a bounded length: `%s`",
+			ErrUnsupportedLookaround, text)
+	}
+	return &syntax.Regexp{
+		Op:    syntax.OpEmptyMatch,
+		Flags: capture.Flags,
+		Name:  lookaroundName,
+		Min:   int(g.look),
+		Sub:   []*syntax.Regexp{body},
+	}, nil
+}
+
+// renumber closes the gaps the placeholders leave in the capture indices.
+// p.removed must be sorted.
+func (p *lookaroundParser) renumber(re *syntax.Regexp) {
+	if re.Op == syntax.OpCapture {
+		re.Cap -= sort.SearchInts(p.removed, re.Cap)
+	}
+	for _, sub := range re.Sub {
+		p.renumber(sub)
+	}
+}
+
+// hasCapture reports whether re contains a capture group.
+func hasCapture(re *syntax.Regexp) bool {
+	if re.Op == syntax.OpCapture {
+		return true
+	}
+	for _, sub := range re.Sub {
+		if hasCapture(sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsLookaround reports whether re is a look-around node made by Parse.
+func IsLookaround(re *syntax.Regexp) bool {
+	_, _, ok := lookaroundOf(re)
+	return ok
+}
+
+// HasLookaround reports whether re contains a look-around node made by Parse.
+func HasLookaround(re *syntax.Regexp) bool {
+	if IsLookaround(re) {
+		return true
+	}
+	for _, sub := range re.Sub {
+		if HasLookaround(sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// lookaroundOf returns the kind and body of a look-around node.
+func lookaroundOf(re *syntax.Regexp) (look Look, body *syntax.Regexp, ok bool) {
+	if re.Op != syntax.OpEmptyMatch || re.Name != lookaroundName || len(re.Sub) != 1 {
+		return 0, nil, false
+	}
+	for _, o := range lookaroundOpeners {
+		if int(o.look) == re.Min {
+			return o.look, re.Sub[0], true
+		}
+	}
+	return 0, nil, false
+}
+
+// maxMatchLen returns the most bytes re can match, or -1 if unbounded.
+func maxMatchLen(re *syntax.Regexp) int {
+	switch re.Op {
+	case syntax.OpLiteral:
+		n := 0
+		for _, r := range re.Rune {
+			n += maxRuneLen(r, re.Flags&syntax.FoldCase != 0)
+		}
+		return n
+	case syntax.OpCharClass:
+		if len(re.Rune) == 0 {
+			return 0
+		}
+		return runeLen(re.Rune[len(re.Rune)-1])
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		return utf8.UTFMax
+	case syntax.OpCapture, syntax.OpQuest:
+		return maxMatchLen(re.Sub[0])
+	case syntax.OpStar, syntax.OpPlus:
+		if maxMatchLen(re.Sub[0]) == 0 {
+			return 0
+		}
+		return -1
+	case syntax.OpRepeat:
+		n := maxMatchLen(re.Sub[0])
+		switch {
+		case n == 0:
+			return 0
+		case n < 0 || re.Max < 0:
+			return -1
+		}
+		return n * re.Max
+	case syntax.OpConcat:
+		total := 0
+		for _, sub := range re.Sub {
+			n := maxMatchLen(sub)
+			if n < 0 {
+				return -1
+			}
+			total += n
+		}
+		return total
+	case syntax.OpAlternate:
+		most := 0
+		for _, sub := range re.Sub {
+			n := maxMatchLen(sub)
+			if n < 0 {
+				return -1
+			}
+			most = max(most, n)
+		}
+		return most
+	}
+	// Empty matches and assertions, look-around included.
+	return 0
+}
+
+// maxRuneLen returns the UTF-8 length of r, or of the longest rune that r
+// matches under case folding.
+func maxRuneLen(r rune, fold bool) int {
+	n := runeLen(r)
+	if fold {
+		for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+			n = max(n, runeLen(f))
+		}
+	}
+	return n
+}
+
+// runeLen is utf8.RuneLen, counting invalid runes as UTFMax bytes.
+func runeLen(r rune) int {
+	if n := utf8.RuneLen(r); n > 0 {
+		return n
+	}
+	return utf8.UTFMax
+}
+
+// compileLookaround compiles a look-around node into a Look state whose body
+// is a separate anchored NFA.
+func (c *Compiler) compileLookaround(look Look, body *syntax.Regexp) (start, end StateID, err error) {
+	maxLen := 0
+	if look == LookBehind || look == LookBehindNeg {
+		if maxLen = maxMatchLen(body); maxLen < 0 {
+			return InvalidState, InvalidState, &CompileError{
+				Err: fmt.Errorf("%w: look-behind must have a bounded length", ErrUnsupportedLookaround),
+			}
+		}
+	}
+
+	// The body gets byte states only: the evaluator steps one byte at a time.
+	sub := NewCompiler(CompilerConfig{
+		UTF8:                c.config.UTF8,
+		Bytes:               c.config.Bytes,
+		Anchored:            true,
+		ASCIIOnly:           c.config.ASCIIOnly,
+		MaxRecursionDepth:   c.config.MaxRecursionDepth,
+		UnicodeWordBoundary: c.config.UnicodeWordBoundary,
+	})
+	bodyNFA, err := sub.CompileRegexp(body)
+	if err != nil {
+		return InvalidState, InvalidState, err
+	}
+
+	id := c.builder.AddLookaround(&Lookaround{look: look, nfa: bodyNFA, maxLen: maxLen}, InvalidState)
+	return id, id, nil
+}
+
+// Lookaround is the compiled body of a look-ahead or look-behind assertion.
+// It is safe for concurrent use.
+type Lookaround struct {
+	look   Look
+	nfa    *NFA
+	maxLen int
+
+	// State lists of the backward pass of look-ahead (see
+	// lookaroundTable.extendBackward), computed on first use.
+	backward  sync.Once
+	accepts   []StateID
+	consumers []StateID
+	preds     [][]StateID
+}
+
+// Look returns the assertion kind: LookAhead, LookAheadNeg, LookBehind or
+// LookBehindNeg.
+func (l *Lookaround) Look() Look {
+	return l.look
+}
+
+// NFA returns the anchored NFA of the body.
+func (l *Lookaround) NFA() *NFA {
+	return l.nfa
+}
+
+// MaxLen returns the most bytes a look-behind body can match. It is 0 for
+// look-ahead.
+func (l *Lookaround) MaxLen() int {
+	return l.maxLen
+}
+
+// Holds reports whether the assertion holds at pos. memo keeps the results
+// of the body for haystack, so that the positions of a search are decided
+// by one pass of the body; with a nil memo the pass is not kept.
+func (l *Lookaround) Holds(memo *LookaroundMemo, haystack []byte, pos int) bool {
+	if memo == nil {
+		memo = new(LookaroundMemo)
+	}
+	matched := memo.table(l, haystack).matchesAt(memo, haystack, pos)
+	if l.look == LookAhead || l.look == LookBehind {
+		return matched
+	}
+	return !matched
+}
+
+// byteNext returns the state that byte state s moves to on b, or
+// InvalidState.
+func (s *State) byteNext(b byte) StateID {
+	switch s.kind {
+	case StateByteRange:
+		if s.lo <= b && b <= s.hi {
+			return s.next
+		}
+	case StateSparse:
+		for _, t := range s.transitions {
+			if t.Lo <= b && b <= t.Hi {
+				return t.Next
+			}
+		}
+	}
+	return InvalidState
+}
+
+// LookaroundMemo holds the results of the look-around bodies for the
+// searches of one haystack. Each body runs over the haystack once, in one
+// forward pass for look-behind and one backward pass for look-ahead, and
+// marks the positions where it matches in a bitmap; the assertions are then
+// read off the bitmaps. So a search does O(len(haystack)) work per body
+// state, however often it checks the assertions.
+//
+// The zero value is empty. A LookaroundMemo must not be shared by
+// concurrent searches.
+type LookaroundMemo struct {
+	data   *byte // unsafe.SliceData of the haystack
+	n      int   // length of the haystack
+	tables []*lookaroundTable
+}
+
+// Begin prepares m for a search of haystack starting at at. A search at 0,
+// or of another haystack, starts afresh; a later one continues the search
+// before it, so the haystack must not change in between (call Reset if it
+// does).
+func (m *LookaroundMemo) Begin(haystack []byte, at int) {
+	if at == 0 || unsafe.SliceData(haystack) != m.data || len(haystack) != m.n {
+		m.Reset()
+	}
+}
+
+// Reset forgets all results.
+func (m *LookaroundMemo) Reset() {
+	m.data, m.n = nil, 0
+	for _, t := range m.tables {
+		t.lo, t.hi = -1, -1
+	}
+}
+
+// table returns the results of look-around l for haystack.
+func (m *LookaroundMemo) table(l *Lookaround, haystack []byte) *lookaroundTable {
+	if data := unsafe.SliceData(haystack); data != m.data || len(haystack) != m.n {
+		m.Reset()
+		m.data, m.n = data, len(haystack)
+	}
+	for _, t := range m.tables {
+		if t.l == l {
+			return t
+		}
+	}
+	n := conv.IntToUint32(l.nfa.States())
+	t := &lookaroundTable{l: l, lo: -1, hi: -1, live: sparse.NewSparseSet(n), next: sparse.NewSparseSet(n)}
+	m.tables = append(m.tables, t)
+	return t
+}
+
+// lookaroundTable holds the results of one look-around body for one
+// haystack: bit i of matches is set if the body matches input starting at
+// i (look-ahead) or ending at i (look-behind). The bits are known for the
+// positions in [lo, hi), and the range grows by one pass in one direction:
+//   - look-ahead walks the body backward from the end of the haystack
+//     (extendBackward), so lo moves down and hi stays len(haystack)+1
+//   - look-behind runs the body forward, starting a thread at every
+//     position (extendForward), so hi moves up. It starts MaxLen bytes
+//     before the first position asked for, since no match is longer, and
+//     a position before lo restarts it
+type lookaroundTable struct {
+	l       *Lookaround
+	lo, hi  int // -1 if nothing is known
+	matches []uint64
+
+	// live holds the body states of the pass at its current position: for
+	// look-ahead the states from which the body matches input starting at
+	// lo, for look-behind the states reached at hi. liveMatch reports
+	// whether the forward pass reached a match state at hi.
+	live, next *sparse.SparseSet
+	liveMatch  bool
+	stack      []StateID
+}
+
+// matchesAt reports whether the body matches at pos, extending the known
+// range as needed.
+func (t *lookaroundTable) matchesAt(memo *LookaroundMemo, haystack []byte, pos int) bool {
+	if pos < t.lo || pos >= t.hi {
+		if t.l.look == LookAhead || t.l.look == LookAheadNeg {
+			t.extendBackward(memo, haystack, pos)
+		} else {
+			t.extendForward(memo, haystack, pos)
+		}
+	}
+	return t.matches[pos/64]&(1<<(pos%64)) != 0
+}
+
+// start empties t for a pass that starts at pos.
+func (t *lookaroundTable) start(haystack []byte, pos int) {
+	t.lo, t.hi = pos, pos
+	t.live.Clear()
+	t.liveMatch = false
+	if words := len(haystack)/64 + 1; len(t.matches) != words {
+		t.matches = make([]uint64, words)
+	}
+}
+
+// set records whether the body matches at pos.
+func (t *lookaroundTable) set(pos int, matched bool) {
+	if matched {
+		t.matches[pos/64] |= 1 << (pos % 64)
+	} else {
+		t.matches[pos/64] &^= 1 << (pos % 64)
+	}
+}
+
+// extendForward runs the look-behind body forward from t.hi up to pos,
+// starting a thread at each position, and records where it matches.
+func (t *lookaroundTable) extendForward(memo *LookaroundMemo, haystack []byte, pos int) {
+	if t.hi < 0 || pos < t.lo {
+		from := max(0, pos-t.l.maxLen)
+		t.start(haystack, from)
+		if from > 0 {
+			// Matches ending in the first MaxLen positions may start
+			// before from.
+			t.lo = pos
+		}
+	}
+	n := t.l.nfa
+	for i := t.hi; i <= pos; i++ {
+		matched := t.addClosure(memo, t.live, n.startAnchored, haystack, i) || t.liveMatch
+		t.set(i, matched)
+		t.liveMatch = false
+		if i < len(haystack) {
+			t.liveMatch = t.step(memo, haystack, i)
+		}
+		t.hi = i + 1
+	}
+}
+
+// step moves the states of t.live over haystack[pos] into their closures at
+// pos+1, which become t.live. It reports whether a match state was reached.
+func (t *lookaroundTable) step(memo *LookaroundMemo, haystack []byte, pos int) bool {
+	n := t.l.nfa
+	b := haystack[pos]
+	matched := false
+	for _, v := range t.live.Values() {
+		next := n.State(StateID(v)).byteNext(b)
+		if next != InvalidState && t.addClosure(memo, t.next, next, haystack, pos+1) {
+			matched = true
+		}
+	}
+	t.live, t.next = t.next, t.live
+	t.next.Clear()
+	return matched
+}
+
+// addClosure adds sid and the states reachable from it without consuming
+// input at pos to set. It reports whether a match state was added.
+func (t *lookaroundTable) addClosure(memo *LookaroundMemo, set *sparse.SparseSet, sid StateID, haystack []byte, pos int) bool {
+	n := t.l.nfa
+	matched := false
+	t.stack = append(t.stack[:0], sid)
+	for len(t.stack) > 0 {
+		sid := t.stack[len(t.stack)-1]
+		t.stack = t.stack[:len(t.stack)-1]
+		if sid == InvalidState || !set.Insert(uint32(sid)) {
+			continue
+		}
+		s := n.State(sid)
+		switch s.kind {
+		case StateMatch:
+			matched = true
+		case StateEpsilon, StateCapture:
+			t.stack = append(t.stack, s.next)
+		case StateSplit:
+			t.stack = append(t.stack, s.right, s.left)
+		case StateLook:
+			if s.LookHolds(memo, haystack, pos) {
+				t.stack = append(t.stack, s.next)
+			}
+		}
+	}
+	return matched
+}
+
+// extendBackward walks the look-ahead body backward from t.lo-1 down to
+// pos and records where it matches. The first call starts at the end of
+// the haystack.
+func (t *lookaroundTable) extendBackward(memo *LookaroundMemo, haystack []byte, pos int) {
+	l := t.l
+	l.prepareBackward()
+	if t.hi < 0 {
+		t.start(haystack, len(haystack)+1)
+	}
+	n := l.nfa
+	for i := t.lo - 1; i >= pos; i-- {
+		t.next.Clear()
+		t.stack = t.stack[:0]
+		for _, sid := range l.accepts {
+			t.push(sid)
+		}
+		if i < len(haystack) {
+			b := haystack[i]
+			for _, sid := range l.consumers {
+				if next := n.State(sid).byteNext(b); next != InvalidState && t.live.Contains(uint32(next)) {
+					t.push(sid)
+				}
+			}
+		}
+		for len(t.stack) > 0 {
+			sid := t.stack[len(t.stack)-1]
+			t.stack = t.stack[:len(t.stack)-1]
+			for _, pred := range l.preds[sid] {
+				if s := n.State(pred); s.kind != StateLook || s.LookHolds(memo, haystack, i) {
+					t.push(pred)
+				}
+			}
+		}
+		t.set(i, t.next.Contains(uint32(n.startAnchored)))
+		t.live, t.next = t.next, t.live
+		t.lo = i
+	}
+	t.hi = len(haystack) + 1
+}
+
+// push adds sid to t.next and, if it is new, to the stack.
+func (t *lookaroundTable) push(sid StateID) {
+	if t.next.Insert(uint32(sid)) {
+		t.stack = append(t.stack, sid)
+	}
+}
+
+// prepareBackward computes, once, the state lists of the backward pass:
+// the match states, the byte states, and the states that reach each state
+// without consuming input.
+func (l *Lookaround) prepareBackward() {
+	l.backward.Do(func() {
+		n := l.nfa
+		l.preds = make([][]StateID, n.States())
+		for i := range n.States() {
+			sid := StateID(i)
+			s := n.State(sid)
+			switch s.kind {
+			case StateMatch:
+				l.accepts = append(l.accepts, sid)
+			case StateByteRange, StateSparse:
+				l.consumers = append(l.consumers, sid)
+			case StateEpsilon, StateCapture, StateLook:
+				if s.next != InvalidState {
+					l.preds[s.next] = append(l.preds[s.next], sid)
+				}
+			case StateSplit:
+				for _, next := range []StateID{s.left, s.right} {
+					if next != InvalidState {
+						l.preds[next] = append(l.preds[next], sid)
+					}
+				}
+			}
+		}
+	})
+}
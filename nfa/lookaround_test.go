@@ -0,0 +1,313 @@
+package nfa
+
+import (
+	"errors"
+	"math/rand"
+	"regexp/syntax"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coregx/coregex/internal/sparse"
+)
+
+// lookaroundTests lists the leftmost-first match of each pattern, or
+// {-1, -1} for no match.
+var lookaroundTests = []struct {
+	pattern  string
+	haystack string
+	start    int
+	end      int
+}{
+	{`foo(?=bar)`, "foobaz foobar", 7, 10},
+	{`foo(?=bar)`, "foobaz", -1, -1},
+	{`foo(?!bar)`, "foobar foobaz", 7, 10},
+	{`foo(?!bar)`, "foo", 0, 3},
+	{`(?<=\$)\d+`, "a1 $42", 4, 6},
+	{`(?<=\$)\d+`, "42", -1, -1},
+	{`(?<!\$)\b\d+`, "$42 17", 4, 6},
+	{`(?<=ab|c)x`, "bx abx", 5, 6},
+	{`(?<=^|,)\w+`, "a,bc", 0, 1},
+	{`\w+(?=,)`, "a b, c", 2, 3},
+	{`(?i)x(?=ab)`, "xAB", 0, 1},
+	{`x(?i:(?=ab))`, "xab xAB", 0, 1},
+	{`(?<=a(?!b))c`, "abc ac", 5, 6},
+	{`(?=\w+@)\w+`, "hi bob@x", 3, 6},
+	{`^(?=.*\d)(?=.*[a-z]).{6,}$`, "abc123", 0, 6},
+	{`^(?=.*\d)(?=.*[a-z]).{6,}$`, "abcdef", -1, -1},
+	{`(?<=é)b`, "ab éb", 5, 6},
+	{`(?<!é)b`, "éb ab", 5, 6},
+	{`(?=a)`, "banana", 1, 1},
+	{`(?<=a)`, "banana", 2, 2},
+	{`(?!)`, "abc", -1, -1},
+	{`(?<=a$)`, "ba", 2, 2},
+	{`a(?=\b)`, "aa a", 1, 2},
+}
+
+func TestLookaroundSearch(t *testing.T) {
+	for _, tt := range lookaroundTests {
+		n, err := NewDefaultCompiler().Compile(tt.pattern)
+		if err != nil {
+			t.Errorf("Compile(%q): %v", tt.pattern, err)
+			continue
+		}
+		if !n.HasLookaround() {
+			t.Errorf("%q: HasLookaround() = false", tt.pattern)
+		}
+
+		start, end, found := NewPikeVM(n).Search([]byte(tt.haystack))
+		if !found {
+			start, end = -1, -1
+		}
+		if start != tt.start || end != tt.end {
+			t.Errorf("%q PikeVM.Search(%q) = (%d, %d), want (%d, %d)",
+				tt.pattern, tt.haystack, start, end, tt.start, tt.end)
+		}
+
+		start, end, found = NewBoundedBacktracker(n).Search([]byte(tt.haystack))
+		if !found {
+			start, end = -1, -1
+		}
+		if start != tt.start || end != tt.end {
+			t.Errorf("%q BoundedBacktracker.Search(%q) = (%d, %d), want (%d, %d)",
+				tt.pattern, tt.haystack, start, end, tt.start, tt.end)
+		}
+	}
+}
+
+func TestParseLookaround(t *testing.T) {
+	tests := []struct {
+		pattern string
+		caps    []string // capture names in group order
+	}{
+		{`(a)(?=b)(?P<c>c)`, []string{"", "c"}},
+		{`(?<=x)(a)|(?!y)(b)`, []string{"", ""}},
+		{`(?=(?<!a)b)(c)`, []string{""}},
+		{`[(?=]x\(?=y\Q(?=\E`, nil},
+	}
+	for _, tt := range tests {
+		re, err := Parse(tt.pattern, syntax.Perl)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.pattern, err)
+			continue
+		}
+		if got := re.CapNames()[1:]; strings.Join(got, ",") != strings.Join(tt.caps, ",") {
+			t.Errorf("Parse(%q) capture names = %q, want %q", tt.pattern, got, tt.caps)
+		}
+		if re.MaxCap() != len(tt.caps) {
+			t.Errorf("Parse(%q) MaxCap = %d, want %d", tt.pattern, re.MaxCap(), len(tt.caps))
+		}
+	}
+
+	// Without PerlX, or as a literal, look-around is not special.
+	if _, err := Parse(`(?=a)`, syntax.POSIX); err == nil {
+		t.Error("Parse POSIX (?=a): want error")
+	}
+	re, err := Parse(`(?=a)`, syntax.Perl|syntax.Literal)
+	if err != nil || re.Op != syntax.OpLiteral || string(re.Rune) != "(?=a)" {
+		t.Errorf("Parse Literal (?=a) = %v, %v; want the literal text", re, err)
+	}
+}
+
+func TestParseLookaroundErrors(t *testing.T) {
+	tests := []struct {
+		pattern     string
+		unsupported bool
+		want        string
+	}{
+		{`(?<=a+)b`, true, "look-behind must have a bounded length: `(?<=a+)`"},
+		{`(?<!a|b*)c`, true, "look-behind must have a bounded length: `(?<!a|b*)`"},
+		{`(?=(a))`, true, "capture groups are not supported in look-around: `(?=(a))`"},
+		{`(?=a`, false, "missing closing ): `(?=a`"},
+		{`x(?<=*)`, false, "missing argument to repetition operator: `*`"},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.pattern, syntax.Perl)
+		if err == nil {
+			t.Errorf("Parse(%q): want error", tt.pattern)
+			continue
+		}
+		if errors.Is(err, ErrUnsupportedLookaround) != tt.unsupported {
+			t.Errorf("Parse(%q) = %v, want ErrUnsupportedLookaround: %v", tt.pattern, err, tt.unsupported)
+		}
+		if !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Parse(%q) = %q, want it to contain %q", tt.pattern, err, tt.want)
+		}
+	}
+
+	// Bounded look-behind of any shape is fine.
+	for _, p := range []string{`(?<=a{2,5})`, `(?<=(?:ab|c)?\d{3})`, `(?<=.\b)`, `(?<=x??)`} {
+		if _, err := Parse(p, syntax.Perl); err != nil {
+			t.Errorf("Parse(%q): %v", p, err)
+		}
+	}
+}
+
+func TestMaxMatchLen(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    int
+	}{
+		{`abc`, 3},
+		{`é`, 2},
+		{`(?i)k`, 3}, // K folds to the Kelvin sign U+212A
+		{`[a-z]`, 1},
+		{`\pL`, 4},
+		{`.`, 4},
+		{`a?b{2,3}`, 4},
+		{`ab|cde`, 3},
+		{`a*`, -1},
+		{`a{2,}`, -1},
+		{`(?:\b)*`, 0},
+		{`^$`, 0},
+	}
+	for _, tt := range tests {
+		re, err := syntax.Parse(tt.pattern, syntax.Perl)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := maxMatchLen(re); got != tt.want {
+			t.Errorf("maxMatchLen(%q) = %d, want %d", tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestLookaroundState(t *testing.T) {
+	n, err := NewDefaultCompiler().Compile(`a(?<!bc)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found *State
+	for it := n.Iter(); it.HasNext(); {
+		if s := it.Next(); s.Lookaround() != nil {
+			found = s
+		}
+	}
+	if found == nil {
+		t.Fatal("no look-around state")
+	}
+	l := found.Lookaround()
+	if look, _ := found.Look(); look != LookBehindNeg || !look.IsLookaround() || l.Look() != LookBehindNeg {
+		t.Errorf("Look() = %v, want LookBehindNeg", look)
+	}
+	if l.MaxLen() != 2 || l.NFA() == nil || !l.NFA().IsAnchored() {
+		t.Errorf("Lookaround MaxLen = %d, NFA = %v; want 2 and an anchored NFA", l.MaxLen(), l.NFA())
+	}
+	if !strings.Contains(found.String(), "Look(BehindNeg)") {
+		t.Errorf("String() = %q", found.String())
+	}
+
+	if LookWordBoundary.IsLookaround() {
+		t.Error("LookWordBoundary.IsLookaround() = true")
+	}
+	plain, _ := NewDefaultCompiler().Compile(`a\b`)
+	if plain.HasLookaround() {
+		t.Error(`a\b: HasLookaround() = true`)
+	}
+}
+
+// lookaroundOfPattern returns the first look-around of pattern.
+func lookaroundOfPattern(t *testing.T, pattern string) *Lookaround {
+	t.Helper()
+	n, err := NewDefaultCompiler().Compile(pattern)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for it := n.Iter(); it.HasNext(); {
+		if l := it.Next().Lookaround(); l != nil {
+			return l
+		}
+	}
+	t.Fatalf("%q has no look-around", pattern)
+	return nil
+}
+
+func TestLookaroundTable(t *testing.T) {
+	// The passes over the whole input must decide each position like a
+	// search of the body at that position does.
+	patterns := []string{
+		`(?=.*\d)`,
+		`(?=a*b)`,
+		`(?=(?:ab|b)*c$)`,
+		`(?=\w*\b-)`,
+		`(?=(?m).*$\n)`,
+		`(?=[a-c]*(?=\d)\d)`,
+		`(?=x|)`,
+		`(?<=a)`,
+		`(?<=a{0,3}b)`,
+		`(?<=^ab|c)`,
+		`(?<=\b\w{1,2})`,
+		`(?<=(?<=a)b)`,
+		`(?<=x|)`,
+	}
+	rng := rand.New(rand.NewSource(1))
+	for _, pattern := range patterns {
+		l := lookaroundOfPattern(t, pattern)
+		body := NewPikeVM(l.nfa)
+		for range 50 {
+			haystack := make([]byte, rng.Intn(40))
+			for i := range haystack {
+				haystack[i] = "abc1 -\n"[rng.Intn(7)]
+			}
+			var memo LookaroundMemo
+			// Ask for the positions in a shuffled order, so that the
+			// passes are extended and restarted.
+			for _, pos := range rng.Perm(len(haystack) + 1) {
+				want := false
+				if l.look == LookAhead {
+					want = body.SearchSpan(haystack, pos, len(haystack), SpanOptions{Anchored: true, Earliest: true}) != nil
+				} else {
+					for start := max(0, pos-l.maxLen); start <= pos && !want; start++ {
+						want = body.SearchSpan(haystack, start, pos, SpanOptions{Full: true}) != nil
+					}
+				}
+				if got := l.Holds(&memo, haystack, pos); got != want {
+					t.Errorf("%q at %d of %q: table = %v, search = %v", pattern, pos, haystack, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestLookaheadLinear(t *testing.T) {
+	// Each position starts a look-ahead whose body runs to the end of the
+	// input: deciding them one at a time would take quadratic time.
+	tests := []struct {
+		pattern  string
+		haystack func(n int) []byte
+		search   func(n *NFA, haystack []byte)
+	}{
+		{`\w(?=\w*!)`, func(n int) []byte { return []byte(strings.Repeat("a", n)) },
+			func(n *NFA, haystack []byte) { NewPikeVM(n).Search(haystack) }},
+		{`(?=.*\d)\w`, func(n int) []byte { return []byte(strings.Repeat("a", n) + "1") },
+			func(n *NFA, haystack []byte) { NewPikeVM(n).SearchAll(haystack) }},
+		{`\w(?!\w*!)x`, func(n int) []byte { return []byte(strings.Repeat("a", n)) },
+			func(n *NFA, haystack []byte) { NewBoundedBacktracker(n).Search(haystack) }},
+	}
+	for _, tt := range tests {
+		n, err := NewDefaultCompiler().Compile(tt.pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		elapsed := func(size int) time.Duration {
+			haystack := tt.haystack(size)
+			best := time.Duration(1 << 62)
+			for range 3 {
+				start := time.Now()
+				tt.search(n, haystack)
+				best = min(best, time.Since(start))
+			}
+			return best
+		}
+		small, large := elapsed(4<<10), elapsed(16<<10)
+		// Linear time grows 4x; quadratic 16x.
+		if large > 8*small+5*time.Millisecond {
+			t.Errorf("%q: %v for 4 KB, %v for 16 KB: not linear", tt.pattern, small, large)
+		}
+	}
+}
+
+// sparseSetFor returns a sparse set for the states of n.
+func sparseSetFor(n *NFA) *sparse.SparseSet {
+	return sparse.NewSparseSet(uint32(n.States()))
+}
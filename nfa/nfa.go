@@ -17,6 +17,10 @@ const (
 	FailState StateID = 0xFFFFFFFE
 )
 
+// PatternID identifies a pattern in a multi-pattern NFA.
+// Single-pattern NFAs use pattern 0 for their only match state.
+type PatternID uint32
+
 // StateKind identifies the type of NFA state and determines which transitions are valid.
 type StateKind uint8
 
@@ -113,8 +117,51 @@ const (
 	// LookNoWordBoundary matches a non-word boundary (\B)
 	// Matches where is_word_char(prev) == is_word_char(curr)
 	LookNoWordBoundary
+
+	// LookAhead matches where its body matches the input that follows: (?=re)
+	LookAhead
+
+	// LookAheadNeg matches where its body does not match the input that
+	// follows: (?!re)
+	LookAheadNeg
+
+	// LookBehind matches where its body matches input that ends here: (?<=re)
+	LookBehind
+
+	// LookBehindNeg matches where its body matches no input that ends here:
+	// (?<!re)
+	LookBehindNeg
+
+	// LookWordBoundaryUnicode matches a word boundary (\b) where word chars
+	// are Unicode letters, marks, decimal digits and connector punctuation.
+	// Compiled for \b when CompilerConfig.UnicodeWordBoundary is set.
+	LookWordBoundaryUnicode
+
+	// LookNoWordBoundaryUnicode matches a non-word boundary (\B) with the
+	// Unicode word chars of LookWordBoundaryUnicode.
+	LookNoWordBoundaryUnicode
 )
 
+// lookNames are the names of the Look values, in order.
+var lookNames = [...]string{
+	"StartText", "EndText", "StartLine", "EndLine", "WordBoundary", "NoWordBoundary",
+	"Ahead", "AheadNeg", "Behind", "BehindNeg", "WordBoundaryUnicode", "NoWordBoundaryUnicode",
+}
+
+// String returns the name of the assertion, e.g. "WordBoundary".
+func (l Look) String() string {
+	if int(l) < len(lookNames) {
+		return lookNames[l]
+	}
+	return "Unknown"
+}
+
+// IsLookaround reports whether l is a look-ahead or look-behind assertion.
+// Such Look states carry a compiled body (see State.Lookaround).
+func (l Look) IsLookaround() bool {
+	return l >= LookAhead && l <= LookBehindNeg
+}
+
 // State represents a single NFA state with its transitions.
 // The state's kind determines which fields are valid.
 type State struct {
@@ -143,6 +190,12 @@ type State struct {
 
 	// For Look: zero-width assertion type
 	look Look
+
+	// For look-ahead and look-behind Look states: the compiled body
+	lookaround *Lookaround
+
+	// For Match: the pattern this match state belongs to (multi-pattern NFAs)
+	pattern PatternID
 }
 
 // Transition represents a byte range and target state for sparse transitions.
@@ -153,6 +206,14 @@ type Transition struct {
 	Next StateID // target state
 }
 
+// String returns a human-readable representation of the transition
+func (t Transition) String() string {
+	if t.Lo == t.Hi {
+		return fmt.Sprintf("%s -> %d", byteString(t.Lo), t.Next)
+	}
+	return fmt.Sprintf("[%s-%s] -> %d", byteString(t.Lo), byteString(t.Hi), t.Next)
+}
+
 // ID returns the state's unique identifier
 func (s *State) ID() StateID {
 	return s.id
@@ -168,6 +229,15 @@ func (s *State) IsMatch() bool {
 	return s.kind == StateMatch
 }
 
+// MatchPattern returns the pattern ID of a Match state.
+// Returns 0 for non-Match states and for single-pattern NFAs.
+func (s *State) MatchPattern() PatternID {
+	if s.kind == StateMatch {
+		return s.pattern
+	}
+	return 0
+}
+
 // ByteRange returns the byte range for ByteRange states.
 // Returns (0, 0, InvalidState) for non-ByteRange states.
 func (s *State) ByteRange() (lo, hi byte, next StateID) {
@@ -230,6 +300,25 @@ func (s *State) Look() (Look, StateID) {
 	return 0, InvalidState
 }
 
+// Lookaround returns the compiled body of a look-ahead or look-behind state.
+// Returns nil for other states.
+func (s *State) Lookaround() *Lookaround {
+	if s.kind == StateLook {
+		return s.lookaround
+	}
+	return nil
+}
+
+// LookHolds reports whether the assertion of Look state s holds at pos.
+// memo holds the look-around results of the current search (see
+// Lookaround.Holds); it is only used by look-ahead and look-behind.
+func (s *State) LookHolds(memo *LookaroundMemo, haystack []byte, pos int) bool {
+	if s.lookaround != nil {
+		return s.lookaround.Holds(memo, haystack, pos)
+	}
+	return checkLookAssertion(s.look, haystack, pos)
+}
+
 // RuneAny returns the next state for RuneAny states.
 // Returns InvalidState for non-RuneAny states.
 func (s *State) RuneAny() StateID {
@@ -252,27 +341,31 @@ func (s *State) RuneAnyNotNL() StateID {
 func (s *State) String() string {
 	switch s.kind {
 	case StateMatch:
+		if s.pattern != 0 {
+			return fmt.Sprintf("State(%d, Match(pattern=%d))", s.id, s.pattern)
+		}
 		return fmt.Sprintf("State(%d, Match)", s.id)
 	case StateByteRange:
 		if s.lo == s.hi {
-			return fmt.Sprintf("State(%d, ByteRange '%c' -> %d)", s.id, s.lo, s.next)
+			return fmt.Sprintf("State(%d, ByteRange %s -> %d)", s.id, byteString(s.lo), s.next)
 		}
-		return fmt.Sprintf("State(%d, ByteRange ['%c'-'%c'] -> %d)", s.id, s.lo, s.hi, s.next)
+		return fmt.Sprintf("State(%d, ByteRange [%s-%s] -> %d)", s.id, byteString(s.lo), byteString(s.hi), s.next)
 	case StateSparse:
 		return fmt.Sprintf("State(%d, Sparse %d transitions)", s.id, len(s.transitions))
 	case StateSplit:
 		return fmt.Sprintf("State(%d, Split -> [%d, %d])", s.id, s.left, s.right)
 	case StateEpsilon:
 		return fmt.Sprintf("State(%d, Epsilon -> %d)", s.id, s.next)
+	case StateCapture:
+		boundary := "end"
+		if s.captureStart {
+			boundary = "start"
+		}
+		return fmt.Sprintf("State(%d, Capture(%d %s) -> %d)", s.id, s.captureIndex, boundary, s.next)
 	case StateFail:
 		return fmt.Sprintf("State(%d, Fail)", s.id)
 	case StateLook:
-		lookNames := []string{"StartText", "EndText", "StartLine", "EndLine"}
-		lookName := "Unknown"
-		if int(s.look) < len(lookNames) {
-			lookName = lookNames[s.look]
-		}
-		return fmt.Sprintf("State(%d, Look(%s) -> %d)", s.id, lookName, s.next)
+		return fmt.Sprintf("State(%d, Look(%s) -> %d)", s.id, s.look, s.next)
 	case StateRuneAny:
 		return fmt.Sprintf("State(%d, RuneAny -> %d)", s.id, s.next)
 	case StateRuneAnyNotNL:
@@ -282,6 +375,15 @@ func (s *State) String() string {
 	}
 }
 
+// byteString formats b as a quoted character if it is printable ASCII and
+// as a \x escape otherwise.
+func byteString(b byte) string {
+	if b >= ' ' && b <= '~' {
+		return fmt.Sprintf("'%c'", b)
+	}
+	return fmt.Sprintf(`\x%02x`, b)
+}
+
 // NFA represents a compiled Thompson NFA.
 // It is the result of compiling a regexp/syntax.Regexp pattern.
 type NFA struct {
@@ -322,6 +424,12 @@ type NFA struct {
 	// Bytes in the same class always have identical transitions in any DFA state.
 	// This reduces DFA state size from 256 transitions to ~8-16 transitions.
 	byteClasses ByteClasses
+
+	// hasLookaround is true if some Look state is a look-ahead or look-behind
+	hasLookaround bool
+
+	// hasUnicodeWordBoundary is true if some Look state is a Unicode \b or \B
+	hasUnicodeWordBoundary bool
 }
 
 // Start returns the starting state ID of the NFA
@@ -379,11 +487,34 @@ func (n *NFA) IsUTF8() bool {
 	return n.utf8
 }
 
+// HasLookaround returns true if the NFA has look-ahead or look-behind
+// assertions. Only the PikeVM and BoundedBacktracker evaluate them; the lazy
+// DFA treats them as always satisfied.
+func (n *NFA) HasLookaround() bool {
+	return n.hasLookaround
+}
+
+// HasUnicodeWordBoundary returns true if the NFA has Unicode word boundary
+// assertions (LookWordBoundaryUnicode or LookNoWordBoundaryUnicode). The lazy
+// DFA evaluates them only between ASCII bytes and gives up on other input.
+func (n *NFA) HasUnicodeWordBoundary() bool {
+	return n.hasUnicodeWordBoundary
+}
+
 // PatternCount returns the number of patterns in the NFA
 func (n *NFA) PatternCount() int {
 	return n.patternCount
 }
 
+// MatchPattern returns the pattern ID of the given match state.
+// Returns 0 if the state is not a match state.
+func (n *NFA) MatchPattern(id StateID) PatternID {
+	if s := n.State(id); s != nil {
+		return s.MatchPattern()
+	}
+	return 0
+}
+
 // CaptureCount returns the number of capture groups in the NFA.
 // Group 0 is the entire match, groups 1+ are explicit captures.
 // For a pattern like "(a)(b)", this returns 3 (entire match + 2 groups).
@@ -34,6 +34,23 @@ func TestStateKind_String(t *testing.T) {
 	}
 }
 
+func TestTransition_String(t *testing.T) {
+	tests := []struct {
+		t    Transition
+		want string
+	}{
+		{Transition{Lo: 'a', Hi: 'a', Next: 4}, "'a' -> 4"},
+		{Transition{Lo: '0', Hi: '9', Next: 5}, "['0'-'9'] -> 5"},
+		{Transition{Lo: 0xc3, Hi: 0xc3, Next: 6}, `\xc3 -> 6`},
+		{Transition{Lo: 0, Hi: '\t', Next: 7}, `[\x00-\x09] -> 7`},
+	}
+	for _, tt := range tests {
+		if got := tt.t.String(); got != tt.want {
+			t.Errorf("Transition.String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
 func TestState_String_AllKinds(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -55,6 +72,11 @@ func TestState_String_AllKinds(t *testing.T) {
 			state:   State{id: 2, kind: StateByteRange, lo: 'a', hi: 'z', next: 3},
 			wantSub: "State(2, ByteRange ['a'-'z'] -> 3)",
 		},
+		{
+			name:    "ByteRange non-ASCII",
+			state:   State{id: 2, kind: StateByteRange, lo: 0x80, hi: 0xbf, next: 3},
+			wantSub: `State(2, ByteRange [\x80-\xbf] -> 3)`,
+		},
 		{
 			name: "Sparse",
 			state: State{
@@ -77,6 +99,16 @@ func TestState_String_AllKinds(t *testing.T) {
 			state:   State{id: 5, kind: StateEpsilon, next: 6},
 			wantSub: "State(5, Epsilon -> 6)",
 		},
+		{
+			name:    "Capture start",
+			state:   State{id: 5, kind: StateCapture, captureIndex: 1, captureStart: true, next: 6},
+			wantSub: "State(5, Capture(1 start) -> 6)",
+		},
+		{
+			name:    "Capture end",
+			state:   State{id: 5, kind: StateCapture, captureIndex: 1, next: 6},
+			wantSub: "State(5, Capture(1 end) -> 6)",
+		},
 		{
 			name:    "Fail",
 			state:   State{id: 6, kind: StateFail},
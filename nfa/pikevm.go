@@ -1,6 +1,7 @@
 package nfa
 
 import (
+	"unicode"
 	"unicode/utf8"
 
 	"github.com/coregx/coregex/internal/conv"
@@ -90,6 +91,11 @@ type PikeVM struct {
 	nfa       *NFA
 	skipAhead SkipAhead // Optional prefilter for skip-ahead (nil = disabled)
 
+	// interrupt, if set, is charged by the SlotTable searches and SearchSpan,
+	// which give up (reporting no match) once it fires. Nil for regular
+	// searches.
+	interrupt *Interrupt
+
 	// internalState is used by legacy non-thread-safe methods.
 	// For concurrent usage, use *WithState methods with external PikeVMState.
 	internalState PikeVMState
@@ -138,6 +144,10 @@ type PikeVMState struct {
 	// By default (false), uses leftmost-first (Perl) semantics where
 	// the first alternative wins. When true, the longest match wins.
 	Longest bool
+
+	// lookaround holds the look-around results of the searches of the
+	// current haystack.
+	lookaround LookaroundMemo
 }
 
 // isBetterMatch returns true if the candidate match is better than the current best.
@@ -351,6 +361,21 @@ func (p *PikeVM) SetSkipAhead(sa SkipAhead) {
 	p.skipAhead = sa
 }
 
+// SetInterrupt makes the SlotTable searches (SearchWithSlotTable*) and
+// SearchSpan abort once in fires; an aborted search reports no match and in.Stopped() is true.
+// Pass nil to remove it.
+func (p *PikeVM) SetInterrupt(in *Interrupt) {
+	p.interrupt = in
+}
+
+// ResetLookaround forgets the look-around results the PikeVM keeps between
+// searches of the same haystack. A search at position 0 or of another slice
+// does so itself; call ResetLookaround before a search at a later position
+// if the bytes of the haystack changed since the previous search.
+func (p *PikeVM) ResetLookaround() {
+	p.internalState.lookaround.Reset()
+}
+
 // NewPikeVMState creates a new mutable state for use with PikeVM.
 // The state must be initialized by calling PikeVM.InitState before use.
 // This should be pooled via sync.Pool for concurrent usage.
@@ -425,6 +450,7 @@ func (p *PikeVM) Search(haystack []byte) (int, int, bool) {
 // if a match exists, not where it is.
 func (p *PikeVM) IsMatch(haystack []byte) bool {
 	p.ensureInternalState()
+	p.internalState.lookaround.Begin(haystack, 0)
 	if len(haystack) == 0 {
 		return p.matchesEmpty()
 	}
@@ -514,6 +540,72 @@ func (p *PikeVM) isMatchAnchored(haystack []byte) bool {
 	return false
 }
 
+// WhichOverlappingMatches reports every pattern of a multi-pattern NFA that
+// matches anywhere in the haystack. matched must have length >= PatternCount();
+// matched[pid] is set to true for each matching pattern (existing true entries
+// are left untouched). Returns the number of patterns newly marked.
+//
+// Unlike Search, no thread is ever pruned in favor of a higher-priority
+// match: the simulation keeps running until the haystack is exhausted or every
+// pattern has matched, so overlapping matches of different patterns are all
+// observed. This is the NFA counterpart of lazy.DFA.WhichOverlappingMatches.
+//
+// This method uses internal state and is NOT thread-safe.
+func (p *PikeVM) WhichOverlappingMatches(haystack []byte, matched []bool) int {
+	p.ensureInternalState()
+	p.internalState.lookaround.Begin(haystack, 0)
+	p.internalState.Queue = p.internalState.Queue[:0]
+	p.internalState.NextQueue = p.internalState.NextQueue[:0]
+	p.internalState.Visited.Clear()
+
+	total := p.nfa.PatternCount()
+	remaining := 0
+	for pid := 0; pid < total; pid++ {
+		if !matched[pid] {
+			remaining++
+		}
+	}
+	found := 0
+	anchored := p.nfa.IsAnchored()
+
+	for pos := 0; pos <= len(haystack); pos++ {
+		// Unanchored: seed a new thread at every position. Threads already
+		// in the queue were added to Visited by the previous step.
+		if pos == 0 || !anchored {
+			p.addThreadForMatch(p.nfa.StartAnchored(), haystack, pos)
+		}
+
+		for _, t := range p.internalState.Queue {
+			if !p.nfa.IsMatch(t.state) {
+				continue
+			}
+			pid := p.nfa.MatchPattern(t.state)
+			if !matched[pid] {
+				matched[pid] = true
+				found++
+				remaining--
+			}
+		}
+
+		if remaining == 0 || pos >= len(haystack) {
+			break
+		}
+		if anchored && len(p.internalState.Queue) == 0 {
+			break
+		}
+
+		b := haystack[pos]
+		p.internalState.Visited.Clear()
+		for _, t := range p.internalState.Queue {
+			p.stepForMatch(t, b, haystack, pos+1)
+		}
+
+		p.internalState.Queue, p.internalState.NextQueue = p.internalState.NextQueue, p.internalState.Queue[:0]
+	}
+
+	return found
+}
+
 // addThreadForMatch adds thread for IsMatch - loop-based epsilon closure.
 // This follows the Rust regex pattern: inner loop for linear chains,
 // stack only for split right branches.
@@ -580,8 +672,8 @@ func (p *PikeVM) addThreadForMatch(id StateID, haystack []byte, pos int) {
 
 		case StateLook:
 			// Check assertion - continue if passes
-			look, next := state.Look()
-			if checkLookAssertion(look, haystack, pos) && next != InvalidState {
+			_, next := state.Look()
+			if state.LookHolds(&p.internalState.lookaround, haystack, pos) && next != InvalidState {
 				sid = next
 				continue
 			}
@@ -718,8 +810,8 @@ func (p *PikeVM) addThreadToNextForMatch(id StateID, haystack []byte, pos int) {
 
 		case StateLook:
 			// Check assertion - continue if passes
-			look, next := state.Look()
-			if checkLookAssertion(look, haystack, pos) && next != InvalidState {
+			_, next := state.Look()
+			if state.LookHolds(&p.internalState.lookaround, haystack, pos) && next != InvalidState {
 				sid = next
 				continue
 			}
@@ -746,6 +838,7 @@ func (p *PikeVM) addThreadToNextForMatch(id StateID, haystack []byte, pos int) {
 // like ^ correctly check against the original input start, not a sliced position.
 func (p *PikeVM) SearchAt(haystack []byte, at int) (int, int, bool) {
 	p.ensureInternalState()
+	p.internalState.lookaround.Begin(haystack, at)
 	if at > len(haystack) {
 		return -1, -1, false
 	}
@@ -890,12 +983,15 @@ func (p *PikeVM) searchUnanchoredAt(haystack []byte, startAt int) (int, int, boo
 //   - startAt: minimum position to start searching
 //   - maxEnd: maximum position where match can end (exclusive for search, inclusive for match)
 //
-// Returns (start, end, found) where start >= startAt and end <= maxEnd.
+// Returns (start, end, found) where start >= startAt and end <= maxEnd. An
+// empty match at startAt (end == startAt == maxEnd) is found. Only the match
+// end is bounded: look-around assertions still see the whole haystack.
 //
 // Performance: O(maxEnd - startAt) instead of O(len(haystack) - startAt).
 func (p *PikeVM) SearchBetween(haystack []byte, startAt, maxEnd int) (int, int, bool) {
 	p.ensureInternalState()
-	if startAt > len(haystack) || startAt >= maxEnd {
+	p.internalState.lookaround.Begin(haystack, startAt)
+	if startAt > len(haystack) || startAt > maxEnd {
 		return -1, -1, false
 	}
 
@@ -906,8 +1002,7 @@ func (p *PikeVM) SearchBetween(haystack []byte, startAt, maxEnd int) (int, int,
 
 	if p.nfa.IsAnchored() {
 		// Anchored mode: only try at startAt position
-		start, end, matched := p.searchAt(haystack[:maxEnd], startAt)
-		return start, end, matched
+		return p.searchAtBetween(haystack, startAt, maxEnd)
 	}
 
 	// Unanchored mode: parallel NFA simulation limited to [startAt, maxEnd]
@@ -1005,6 +1100,7 @@ func (p *PikeVM) SearchWithCaptures(haystack []byte) *MatchWithCaptures {
 // Unlike SearchWithCaptures, it takes the FULL haystack and a starting position.
 func (p *PikeVM) SearchWithCapturesAt(haystack []byte, at int) *MatchWithCaptures {
 	p.ensureInternalState()
+	p.internalState.lookaround.Begin(haystack, at)
 	if at > len(haystack) {
 		return nil
 	}
@@ -1209,6 +1305,7 @@ func (p *PikeVM) searchAtWithCaptures(haystack []byte, startPos int) *MatchWithC
 //nolint:gocognit // Merged match-check + step loop (Rust's nexts pattern) is inherently complex
 func (p *PikeVM) SearchWithCapturesInSpan(haystack []byte, spanStart, spanEnd int) *MatchWithCaptures {
 	p.ensureInternalState()
+	p.internalState.lookaround.Begin(haystack, spanStart)
 	if spanStart > spanEnd || spanEnd > len(haystack) {
 		return nil
 	}
@@ -1313,6 +1410,7 @@ func (p *PikeVM) buildCapturesResult(caps []int, matchStart, matchEnd int) [][]i
 // Returns a slice of matches in order of occurrence.
 func (p *PikeVM) SearchAll(haystack []byte) []Match {
 	p.ensureInternalState()
+	p.internalState.lookaround.Begin(haystack, 0)
 	var matches []Match
 	pos := 0
 
@@ -1340,6 +1438,13 @@ func (p *PikeVM) SearchAll(haystack []byte) []Match {
 // searchAt attempts to find a match starting at the given position.
 // Uses leftmost-first (Perl) or leftmost-longest (POSIX) semantics based on p.internalState.Longest flag.
 func (p *PikeVM) searchAt(haystack []byte, startPos int) (int, int, bool) {
+	return p.searchAtBetween(haystack, startPos, len(haystack))
+}
+
+// searchAtBetween is searchAt for a match that ends at or before maxEnd. The
+// threads step over haystack[startPos:maxEnd] only, but assertions see the
+// whole haystack.
+func (p *PikeVM) searchAtBetween(haystack []byte, startPos, maxEnd int) (int, int, bool) {
 	// Reset state
 	p.internalState.Queue = p.internalState.Queue[:0]
 	p.internalState.NextQueue = p.internalState.NextQueue[:0]
@@ -1349,9 +1454,9 @@ func (p *PikeVM) searchAt(haystack []byte, startPos int) (int, int, bool) {
 
 	lastMatchPos := -1
 
-	for pos := startPos; pos <= len(haystack); pos++ {
+	for pos := startPos; pos <= maxEnd; pos++ {
 		// Combined match-check + step with break-on-first-match
-		if pos < len(haystack) {
+		if pos < maxEnd {
 			b := haystack[pos]
 			p.internalState.Visited.Clear()
 			for _, t := range p.internalState.Queue {
@@ -1377,11 +1482,11 @@ func (p *PikeVM) searchAt(haystack []byte, startPos int) (int, int, bool) {
 			}
 		}
 
-		if len(p.internalState.NextQueue) == 0 && (pos >= len(haystack) || lastMatchPos != -1) {
+		if len(p.internalState.NextQueue) == 0 && (pos >= maxEnd || lastMatchPos != -1) {
 			break
 		}
 
-		if pos >= len(haystack) {
+		if pos >= maxEnd {
 			break
 		}
 
@@ -1428,12 +1533,15 @@ func (p *PikeVM) addThread(t thread, haystack []byte, pos int) {
 		// For alternation: left=first alt, right=second alt → first alt explored first
 		left, right := state.Split()
 
+		// Clone captures for right branch to ensure COW works properly.
+		// The reference must be taken before the left branch is explored,
+		// or a capture on the left would update the shared slots in place.
+		rightCaps := t.captures.clone()
 		if left != InvalidState {
 			p.addThread(thread{state: left, startPos: t.startPos, captures: t.captures}, haystack, pos)
 		}
 		if right != InvalidState {
-			// Clone captures for right branch to ensure COW works properly.
-			p.addThread(thread{state: right, startPos: t.startPos, captures: t.captures.clone()}, haystack, pos)
+			p.addThread(thread{state: right, startPos: t.startPos, captures: rightCaps}, haystack, pos)
 		}
 
 	case StateCapture:
@@ -1444,8 +1552,8 @@ func (p *PikeVM) addThread(t thread, haystack []byte, pos int) {
 		}
 
 	case StateLook:
-		look, next := state.Look()
-		if checkLookAssertion(look, haystack, pos) && next != InvalidState {
+		_, next := state.Look()
+		if state.LookHolds(&p.internalState.lookaround, haystack, pos) && next != InvalidState {
 			p.addThread(thread{state: next, startPos: t.startPos, captures: t.captures}, haystack, pos)
 		}
 
@@ -1531,11 +1639,12 @@ func (p *PikeVM) addThreadToNext(t thread, haystack []byte, pos int) {
 	case StateSplit:
 		left, right := state.Split()
 
+		rightCaps := t.captures.clone() // before the left branch can update it
 		if left != InvalidState {
 			p.addThreadToNext(thread{state: left, startPos: t.startPos, captures: t.captures}, haystack, pos)
 		}
 		if right != InvalidState {
-			p.addThreadToNext(thread{state: right, startPos: t.startPos, captures: t.captures.clone()}, haystack, pos)
+			p.addThreadToNext(thread{state: right, startPos: t.startPos, captures: rightCaps}, haystack, pos)
 		}
 		return
 
@@ -1548,8 +1657,8 @@ func (p *PikeVM) addThreadToNext(t thread, haystack []byte, pos int) {
 		return
 
 	case StateLook:
-		look, next := state.Look()
-		if checkLookAssertion(look, haystack, pos) && next != InvalidState {
+		_, next := state.Look()
+		if state.LookHolds(&p.internalState.lookaround, haystack, pos) && next != InvalidState {
 			p.addThreadToNext(thread{state: next, startPos: t.startPos, captures: t.captures}, haystack, pos)
 		}
 		return
@@ -1611,8 +1720,8 @@ func (p *PikeVM) matchesEmptyAt(haystack []byte, pos int) bool {
 
 		case StateLook:
 			// Check if assertion holds at the actual position
-			look, next := state.Look()
-			if checkLookAssertion(look, haystack, pos) && next != InvalidState && !p.internalState.Visited.Contains(uint32(next)) {
+			_, next := state.Look()
+			if state.LookHolds(&p.internalState.lookaround, haystack, pos) && next != InvalidState && !p.internalState.Visited.Contains(uint32(next)) {
 				p.internalState.Visited.Insert(uint32(next))
 				stack = append(stack, next)
 			}
@@ -1639,6 +1748,42 @@ func isWordByte(b byte) bool {
 		b == '_'
 }
 
+// isWordRune reports whether r is a Unicode word char: a letter, mark,
+// decimal digit or connector punctuation, as \w in UTS #18.
+func isWordRune(r rune) bool {
+	if r < utf8.RuneSelf {
+		return isWordByte(byte(r))
+	}
+	return unicode.IsLetter(r) || unicode.IsMark(r) || unicode.IsDigit(r) ||
+		unicode.In(r, unicode.Nl, unicode.Pc, unicode.Other_Alphabetic, unicode.Join_Control)
+}
+
+// isWordRuneBefore reports whether the character ending at pos is a Unicode
+// word char. Invalid UTF-8 is not.
+func isWordRuneBefore(haystack []byte, pos int) bool {
+	if pos == 0 {
+		return false
+	}
+	if b := haystack[pos-1]; b < utf8.RuneSelf {
+		return isWordByte(b)
+	}
+	r, _ := utf8.DecodeLastRune(haystack[:pos])
+	return isWordRune(r)
+}
+
+// isWordRuneAfter reports whether the character starting at pos is a
+// Unicode word char. Invalid UTF-8 is not.
+func isWordRuneAfter(haystack []byte, pos int) bool {
+	if pos == len(haystack) {
+		return false
+	}
+	if b := haystack[pos]; b < utf8.RuneSelf {
+		return isWordByte(b)
+	}
+	r, _ := utf8.DecodeRune(haystack[pos:])
+	return isWordRune(r)
+}
+
 // (checkLeftLookSucceeds and calcRightBranchPriority removed —
 // greedy/non-greedy is now handled by DFS ordering + break-on-first-match)
 
@@ -1669,6 +1814,10 @@ func checkLookAssertion(look Look, haystack []byte, pos int) bool {
 		wordBefore := pos > 0 && isWordByte(haystack[pos-1])
 		wordAfter := pos < len(haystack) && isWordByte(haystack[pos])
 		return wordBefore == wordAfter
+	case LookWordBoundaryUnicode:
+		return isWordRuneBefore(haystack, pos) != isWordRuneAfter(haystack, pos)
+	case LookNoWordBoundaryUnicode:
+		return isWordRuneBefore(haystack, pos) == isWordRuneAfter(haystack, pos)
 	}
 	return false
 }
@@ -1710,6 +1859,7 @@ func (p *PikeVM) SearchWithSlotTable(haystack []byte, mode SearchMode) (int, int
 // Returns (start, end, found) for the first match.
 func (p *PikeVM) SearchWithSlotTableAt(haystack []byte, at int, mode SearchMode) (int, int, bool) {
 	p.ensureInternalState()
+	p.internalState.lookaround.Begin(haystack, at)
 	if at > len(haystack) {
 		return -1, -1, false
 	}
@@ -1759,6 +1909,9 @@ func (p *PikeVM) searchWithSlotTableUnanchored(haystack []byte, startAt int) (in
 	isAnchored := p.nfa.IsAnchored()
 
 	for pos := startAt; pos <= len(haystack); pos++ {
+		if p.interrupt != nil && p.interrupt.Spend(len(p.internalState.SearchQueue)+1) {
+			return -1, -1, false
+		}
 		if bestStart == -1 && (!isAnchored || pos == 0) {
 			// Skip-ahead (Rust pikevm.rs:1293)
 			if len(p.internalState.SearchQueue) == 0 && p.skipAhead != nil && pos > startAt {
@@ -1843,6 +1996,9 @@ func (p *PikeVM) searchWithSlotTableAnchored(haystack []byte, startPos int) (int
 	lastMatchPos := -1
 
 	for pos := startPos; pos <= len(haystack); pos++ {
+		if p.interrupt != nil && p.interrupt.Spend(len(p.internalState.SearchQueue)+1) {
+			return -1, -1, false
+		}
 		// Combined match-check + step with break-on-first-match
 		if pos < len(haystack) {
 			b := haystack[pos]
@@ -1992,8 +2148,8 @@ func (p *PikeVM) addSearchThread(t searchThread, haystack []byte, pos int) {
 			}
 
 		case StateLook:
-			look, next := state.Look()
-			if checkLookAssertion(look, haystack, pos) && next != InvalidState {
+			_, next := state.Look()
+			if state.LookHolds(&p.internalState.lookaround, haystack, pos) && next != InvalidState {
 				st.captureStack = append(st.captureStack, captureFrame{
 					state: next, startPos: frame.startPos,
 				})
@@ -2161,8 +2317,8 @@ func (p *PikeVM) addSearchThreadToNext(t searchThread, srcState StateID, haystac
 			}
 
 		case StateLook:
-			look, next := state.Look()
-			if checkLookAssertion(look, haystack, pos) && next != InvalidState {
+			_, next := state.Look()
+			if state.LookHolds(&p.internalState.lookaround, haystack, pos) && next != InvalidState {
 				st.captureStack = append(st.captureStack, captureFrame{
 					state: next, startPos: frame.startPos,
 				})
@@ -2185,6 +2341,7 @@ func (p *PikeVM) SearchWithSlotTableCaptures(haystack []byte) *MatchWithCaptures
 // Matches Rust's PikeVM Cache with curr/next ActiveStates (pikevm.rs:1878).
 func (p *PikeVM) SearchWithSlotTableCapturesAt(haystack []byte, at int) *MatchWithCaptures {
 	p.ensureInternalState()
+	p.internalState.lookaround.Begin(haystack, at)
 	if at > len(haystack) {
 		return nil
 	}
@@ -2196,33 +2353,116 @@ func (p *PikeVM) SearchWithSlotTableCapturesAt(haystack []byte, at int) *MatchWi
 	p.internalState.SlotTable.SetActiveSlots(totalSlots)
 	p.internalState.NextSlotTable.SetActiveSlots(totalSlots)
 
-	numGroups := p.nfa.CaptureCount()
-
-	if at == len(haystack) {
-		if p.matchesEmptyAt(haystack, at) {
-			return p.buildCapturesFromSlots(nil, at, at)
-		}
+	// At the end of the haystack only an empty match is possible; reject
+	// quickly, but run the search for a match so that its groups are set.
+	if at == len(haystack) && !p.matchesEmptyAt(haystack, at) {
 		return nil
 	}
-	if len(haystack) == 0 {
-		if p.matchesEmpty() {
-			return p.buildCapturesFromSlots(nil, 0, 0)
-		}
+
+	slots := make([]int, totalSlots)
+	var start, end int
+	var found bool
+	if p.nfa.IsAnchored() {
+		start, end, found = p.searchWithSlotTableCapturesAnchored(haystack, at, len(haystack), slots)
+	} else {
+		start, end, found = p.searchWithSlotTableCapturesUnanchored(haystack, at, slots)
+	}
+	if !found {
 		return nil
 	}
-	_ = numGroups
+	return p.buildCapturesFromSlots(slots, start, end)
+}
+
+// SearchSlotsAt is the allocation-free form of SearchWithSlotTableCapturesAt.
+// It writes the match into slots as [start0, end0, start1, end1, ...], with
+// -1 for both ends of every group that did not participate, and reports
+// whether a match was found. slots must have room for 2*CaptureCount()
+// entries; it is not modified if there is no match.
+func (p *PikeVM) SearchSlotsAt(haystack []byte, at int, slots []int) bool {
+	p.ensureInternalState()
+	p.internalState.lookaround.Begin(haystack, at)
+	if at > len(haystack) {
+		return false
+	}
+	p.prepareSlotSearch()
 
+	if at == len(haystack) && !p.matchesEmptyAt(haystack, at) {
+		return false
+	}
+
+	var start, end int
+	var found bool
 	if p.nfa.IsAnchored() {
-		return p.searchWithSlotTableCapturesAnchored(haystack, at)
+		start, end, found = p.searchWithSlotTableCapturesAnchored(haystack, at, len(haystack), slots)
+	} else {
+		start, end, found = p.searchWithSlotTableCapturesUnanchored(haystack, at, slots)
+	}
+	if found {
+		fillMatchSlots(slots, slots, start, end)
 	}
-	return p.searchWithSlotTableCapturesUnanchored(haystack, at)
+	return found
+}
+
+// SearchSlotsInSpan is the allocation-free form of SearchWithCapturesInSpan:
+// it finds the match that starts at spanStart and ends at or before spanEnd
+// and writes its slots as SearchSlotsAt does. Bytes outside the span are
+// only used as look-around context.
+func (p *PikeVM) SearchSlotsInSpan(haystack []byte, spanStart, spanEnd int, slots []int) bool {
+	p.ensureInternalState()
+	p.internalState.lookaround.Begin(haystack, spanStart)
+	if spanStart > spanEnd || spanEnd > len(haystack) {
+		return false
+	}
+	p.prepareSlotSearch()
+
+	start, end, found := p.searchWithSlotTableCapturesAnchored(haystack, spanStart, spanEnd, slots)
+	if found {
+		fillMatchSlots(slots, slots, start, end)
+	}
+	return found
+}
+
+// prepareSlotSearch sizes the SlotTables for a capture search.
+func (p *PikeVM) prepareSlotSearch() {
+	p.ensureSlotTables(&p.internalState)
+	totalSlots := p.nfa.CaptureCount() * 2
+	p.internalState.SlotTable.SetActiveSlots(totalSlots)
+	p.internalState.NextSlotTable.SetActiveSlots(totalSlots)
+}
+
+// fillMatchSlots writes a match found by a slot search into dst: group 0 is
+// [matchStart, matchEnd], other groups come from src, and a group with
+// either end unset is reported as -1, -1. src may be dst or nil.
+func fillMatchSlots(dst, src []int, matchStart, matchEnd int) {
+	for i := 2; i+1 < len(dst); i += 2 {
+		if src == nil || i+1 >= len(src) || src[i] < 0 || src[i+1] < 0 {
+			dst[i], dst[i+1] = -1, -1
+			continue
+		}
+		dst[i], dst[i+1] = src[i], src[i+1]
+	}
+	if len(dst) >= 2 {
+		dst[0], dst[1] = matchStart, matchEnd
+	}
+}
+
+// saveMatchSlots copies the slots of a matching thread into dst, marking every
+// slot -1 when the state has none.
+func saveMatchSlots(dst, matchSlots []int) {
+	if matchSlots == nil {
+		for i := range dst {
+			dst[i] = -1
+		}
+		return
+	}
+	copy(dst, matchSlots)
 }
 
 // searchWithSlotTableCapturesUnanchored implements unanchored search with captures.
 // Captures stored in SlotTable per-state, saved to bestSlots on match.
 //
 //nolint:gocognit,gocyclo,cyclop // Merged match-check + step + seed loop
-func (p *PikeVM) searchWithSlotTableCapturesUnanchored(haystack []byte, startAt int) *MatchWithCaptures {
+func (p *PikeVM) searchWithSlotTableCapturesUnanchored(haystack []byte, startAt int, bestSlots []int) (int, int, bool) {
 	st := &p.internalState
 	st.SearchQueue = st.SearchQueue[:0]
 	st.SearchNextQueue = st.SearchNextQueue[:0]
@@ -2234,9 +2474,11 @@ func (p *PikeVM) searchWithSlotTableCapturesUnanchored(haystack []byte, startAt
 	st.NextSlotTable.SetActiveSlots(totalSlots)
 	bestStart := -1
 	bestEnd := -1
-	var bestSlots []int
 
 	for pos := startAt; pos <= len(haystack); pos++ {
+		if p.interrupt != nil && p.interrupt.Spend(len(st.SearchQueue)+1) {
+			return -1, -1, false
+		}
 		if bestStart == -1 {
 			if len(st.SearchQueue) == 0 && p.skipAhead != nil && pos > startAt {
 				candidate := p.skipAhead.Find(haystack, pos)
@@ -2264,13 +2506,7 @@ func (p *PikeVM) searchWithSlotTableCapturesUnanchored(haystack []byte, startAt
 						bestStart = t.startPos
 						bestEnd = pos
 						// Read from CURRENT SlotTable
-						matchSlots := st.SlotTable.ForState(t.state)
-						if matchSlots != nil && totalSlots > 0 {
-							if bestSlots == nil {
-								bestSlots = make([]int, totalSlots)
-							}
-							copy(bestSlots, matchSlots)
-						}
+						saveMatchSlots(bestSlots, st.SlotTable.ForState(t.state))
 					}
 					if !st.Longest {
 						break
@@ -2286,13 +2522,7 @@ func (p *PikeVM) searchWithSlotTableCapturesUnanchored(haystack []byte, startAt
 					if p.isBetterMatch(bestStart, bestEnd, t.startPos, pos) {
 						bestStart = t.startPos
 						bestEnd = pos
-						matchSlots := st.SlotTable.ForState(t.state)
-						if matchSlots != nil && totalSlots > 0 {
-							if bestSlots == nil {
-								bestSlots = make([]int, totalSlots)
-							}
-							copy(bestSlots, matchSlots)
-						}
+						saveMatchSlots(bestSlots, st.SlotTable.ForState(t.state))
 					}
 					break
 				}
@@ -2322,15 +2552,15 @@ func (p *PikeVM) searchWithSlotTableCapturesUnanchored(haystack []byte, startAt
 	}
 
 	if bestStart == -1 {
-		return nil
+		return -1, -1, false
 	}
-	return p.buildCapturesFromSlots(bestSlots, bestStart, bestEnd)
+	return bestStart, bestEnd, true
 }
 
 // searchWithSlotTableCapturesAnchored implements anchored search with captures.
 //
 //nolint:gocognit // Merged match-check + step loop (Rust's nexts pattern)
-func (p *PikeVM) searchWithSlotTableCapturesAnchored(haystack []byte, startPos int) *MatchWithCaptures {
+func (p *PikeVM) searchWithSlotTableCapturesAnchored(haystack []byte, startPos, maxEnd int, bestSlots []int) (int, int, bool) {
 	st := &p.internalState
 	st.SearchQueue = st.SearchQueue[:0]
 	st.SearchNextQueue = st.SearchNextQueue[:0]
@@ -2348,23 +2578,19 @@ func (p *PikeVM) searchWithSlotTableCapturesAnchored(haystack []byte, startPos i
 	p.addSearchThread(searchThread{state: startSid, startPos: startPos}, haystack, startPos)
 
 	lastMatchPos := -1
-	var bestSlots []int
 
-	for pos := startPos; pos <= len(haystack); pos++ {
-		if pos < len(haystack) {
+	for pos := startPos; pos <= maxEnd; pos++ {
+		if p.interrupt != nil && p.interrupt.Spend(len(st.SearchQueue)+1) {
+			return -1, -1, false
+		}
+		if pos < maxEnd {
 			b := haystack[pos]
 			st.Visited.Clear()
 			for _, t := range st.SearchQueue {
 				if p.nfa.IsMatch(t.state) {
 					if pos > lastMatchPos || lastMatchPos == -1 {
 						lastMatchPos = pos
-						matchSlots := st.SlotTable.ForState(t.state)
-						if matchSlots != nil && totalSlots > 0 {
-							if bestSlots == nil {
-								bestSlots = make([]int, totalSlots)
-							}
-							copy(bestSlots, matchSlots)
-						}
+						saveMatchSlots(bestSlots, st.SlotTable.ForState(t.state))
 					}
 					if !st.Longest {
 						break
@@ -2378,23 +2604,17 @@ func (p *PikeVM) searchWithSlotTableCapturesAnchored(haystack []byte, startPos i
 				if p.nfa.IsMatch(t.state) {
 					if pos > lastMatchPos || lastMatchPos == -1 {
 						lastMatchPos = pos
-						matchSlots := st.SlotTable.ForState(t.state)
-						if matchSlots != nil && totalSlots > 0 {
-							if bestSlots == nil {
-								bestSlots = make([]int, totalSlots)
-							}
-							copy(bestSlots, matchSlots)
-						}
+						saveMatchSlots(bestSlots, st.SlotTable.ForState(t.state))
 					}
 					break
 				}
 			}
 		}
 
-		if len(st.SearchNextQueue) == 0 && (pos >= len(haystack) || lastMatchPos != -1) {
+		if len(st.SearchNextQueue) == 0 && (pos >= maxEnd || lastMatchPos != -1) {
 			break
 		}
-		if pos >= len(haystack) {
+		if pos >= maxEnd {
 			break
 		}
 
@@ -2403,9 +2623,9 @@ func (p *PikeVM) searchWithSlotTableCapturesAnchored(haystack []byte, startPos i
 	}
 
 	if lastMatchPos == -1 {
-		return nil
+		return -1, -1, false
 	}
-	return p.buildCapturesFromSlots(bestSlots, startPos, lastMatchPos)
+	return startPos, lastMatchPos, true
 }
 
 // buildCapturesFromSlots converts flat slot data to MatchWithCaptures result.
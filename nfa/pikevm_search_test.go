@@ -378,6 +378,18 @@ func TestPikeVM_SearchBetween_Bounds(t *testing.T) {
 			name: "empty range", pattern: "a", haystack: "abc",
 			startAt: 1, maxEnd: 1, wantStart: -1, wantEnd: -1, wantFound: false,
 		},
+		{
+			name: "empty match in empty range", pattern: "a*", haystack: "bab",
+			startAt: 1, maxEnd: 1, wantStart: 1, wantEnd: 1, wantFound: true,
+		},
+		{
+			name: "anchored match bounded by maxEnd", pattern: "^ab*", haystack: "abbb",
+			startAt: 0, maxEnd: 2, wantStart: 0, wantEnd: 2, wantFound: true,
+		},
+		{
+			name: "end of text is outside the bounds", pattern: "a$|b", haystack: "abc",
+			startAt: 0, maxEnd: 2, wantStart: 1, wantEnd: 2, wantFound: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -456,6 +468,7 @@ func TestPikeVM_Search_WithCaptures_VsStdlib(t *testing.T) {
 		`([a-z]+)([0-9]+)`,
 		`(foo)(bar)`,
 		`(a+)(b+)(c+)`,
+		`(a|b)*abb`, // a capture on a split's left branch must not leak right
 	}
 
 	haystacks := []string{
@@ -464,6 +477,7 @@ func TestPikeVM_Search_WithCaptures_VsStdlib(t *testing.T) {
 		"foobar",
 		"aaabbbccc",
 		"no match here",
+		"aabbcc",
 	}
 
 	for _, pattern := range patterns {
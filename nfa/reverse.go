@@ -6,7 +6,20 @@ package nfa
 //
 // This is equivalent to Reverse() followed by marking the result as anchored.
 func ReverseAnchored(forward *NFA) *NFA {
-	return reverseWithOptions(forward, true)
+	return reverseWithOptions(forward, true, false)
+}
+
+// ReverseAnchoredLook is ReverseAnchored for DFAs that resolve assertions.
+// ReverseAnchored treats ^, $, \b and \B as always true; ReverseAnchoredLook
+// keeps them, mirrored for the backward scan: the start-of-text and
+// start-of-line assertions become end-of-text and end-of-line assertions
+// and vice versa, and word boundaries, which look at both sides, stay.
+// Look-around is treated as always true, as in ReverseAnchored.
+//
+// A DFA built from it finds the start of a match from its end: its start
+// state takes the byte after the match end as look-behind context.
+func ReverseAnchoredLook(forward *NFA) *NFA {
+	return reverseWithOptions(forward, true, true)
 }
 
 // Reverse builds a reverse NFA from the given forward NFA.
@@ -36,11 +49,12 @@ func ReverseAnchored(forward *NFA) *NFA {
 //	Reverse NFA:
 //	  start(from match) -> c -> b -> a -> match(from start)
 func Reverse(forward *NFA) *NFA {
-	return reverseWithOptions(forward, false)
+	return reverseWithOptions(forward, false, false)
 }
 
-// reverseWithOptions builds a reverse NFA with configurable anchoring.
-func reverseWithOptions(forward *NFA, anchored bool) *NFA {
+// reverseWithOptions builds a reverse NFA with configurable anchoring. With
+// keepLook, assertions are mirrored instead of treated as epsilons.
+func reverseWithOptions(forward *NFA, anchored, keepLook bool) *NFA {
 	// PASS 0: Collect all reverse edges
 	reverseEdges := collectReverseEdges(forward)
 
@@ -65,10 +79,14 @@ func reverseWithOptions(forward *NFA, anchored bool) *NFA {
 		unanchoredPrefixStates = findUnanchoredPrefixStates(forward, fwdStartAnchored, fwdStartUnanchored)
 	}
 	allocatePlaceholders(forward, builder, reverseEdges, revStateMap, unanchoredPrefixStates)
+	var lookFill map[StateID]StateID
+	if keepLook {
+		lookFill = wrapLookStates(forward, builder, revStateMap)
+	}
 
 	// PASS 2: Fill in actual transitions
 	// Pass anchored flag and skipStates to skip unanchored prefix
-	fillAllTransitions(forward, builder, reverseEdges, fwdStartAnchored, fwdStartUnanchored, reverseMatchID, revStateMap, anchored, unanchoredPrefixStates)
+	fillAllTransitions(forward, builder, reverseEdges, fwdStartAnchored, fwdStartUnanchored, reverseMatchID, revStateMap, lookFill, anchored, unanchoredPrefixStates)
 
 	// Build reverse start states from forward match states
 	forwardMatchIDs := collectMatchStates(forward)
@@ -170,6 +188,48 @@ func allocatePlaceholders(forward *NFA, builder *Builder, reverseEdges map[State
 	}
 }
 
+// wrapLookStates puts the mirrored assertion of every forward look state in
+// front of its reverse state: reverse edges into the look state then pass
+// the assertion first. It returns, for each wrapped forward state, the
+// reverse state to fill with its incoming edges.
+func wrapLookStates(forward *NFA, builder *Builder, revStateMap map[StateID]StateID) map[StateID]StateID {
+	lookFill := make(map[StateID]StateID)
+	for it := forward.Iter(); it.HasNext(); {
+		state := it.Next()
+		if state.Kind() != StateLook {
+			continue
+		}
+		look, _ := state.Look()
+		mirrored, ok := mirrorLook(look)
+		revID, mapped := revStateMap[state.ID()]
+		if !ok || !mapped {
+			continue
+		}
+		lookFill[state.ID()] = revID
+		revStateMap[state.ID()] = builder.AddLook(mirrored, revID)
+	}
+	return lookFill
+}
+
+// mirrorLook returns the assertion that holds at a position of the reversed
+// input exactly when look holds at that position of the input. Look-around
+// has no mirror.
+func mirrorLook(look Look) (Look, bool) {
+	switch look {
+	case LookStartText:
+		return LookEndText, true
+	case LookEndText:
+		return LookStartText, true
+	case LookStartLine:
+		return LookEndLine, true
+	case LookEndLine:
+		return LookStartLine, true
+	case LookWordBoundary, LookNoWordBoundary, LookWordBoundaryUnicode, LookNoWordBoundaryUnicode:
+		return look, true
+	}
+	return look, false
+}
+
 // findUnanchoredPrefixStates identifies states that are part of the unanchored prefix (.*?)
 // These are states reachable from startUnanchored but loop back to it (the .*? loop)
 func findUnanchoredPrefixStates(nfa *NFA, _, startUnanchored StateID) map[StateID]bool {
@@ -233,8 +293,9 @@ func findLoopStates(nfa *NFA, start, target StateID, result map[StateID]bool) {
 }
 
 // fillAllTransitions fills in actual transitions for all states
-// When forAnchored is true, skip the unanchored prefix states entirely
-func fillAllTransitions(forward *NFA, builder *Builder, reverseEdges map[StateID][]reverseEdge, fwdAnchored, fwdUnanchored, matchID StateID, revStateMap map[StateID]StateID, forAnchored bool, skipStates map[StateID]bool) {
+// When forAnchored is true, skip the unanchored prefix states entirely.
+// States wrapped by wrapLookStates are filled through lookFill.
+func fillAllTransitions(forward *NFA, builder *Builder, reverseEdges map[StateID][]reverseEdge, fwdAnchored, fwdUnanchored, matchID StateID, revStateMap, lookFill map[StateID]StateID, forAnchored bool, skipStates map[StateID]bool) {
 	for it := forward.Iter(); it.HasNext(); {
 		state := it.Next()
 		fwdID := state.ID()
@@ -255,12 +316,18 @@ func fillAllTransitions(forward *NFA, builder *Builder, reverseEdges map[StateID
 		if !exists {
 			continue // State not mapped (e.g., skipped unanchored start)
 		}
+		if fill, ok := lookFill[fwdID]; ok {
+			revID = fill
+		}
 
 		edges := reverseEdges[fwdID]
 
-		if isStart && hasIncoming {
+		switch {
+		case isStart && hasIncoming && fwdID == fwdAnchored:
+			fillAnchoredStartState(builder, revID, edges, revStateMap, matchID)
+		case isStart && hasIncoming:
 			fillStartStateWithIncoming(builder, revID, edges, revStateMap, matchID)
-		} else {
+		default:
 			fillReverseState(builder, revID, edges, revStateMap)
 		}
 	}
@@ -444,6 +511,31 @@ func fillStartStateWithIncoming(builder *Builder, proxyID StateID, edges []rever
 	}
 }
 
+// fillAnchoredStartState handles an anchored forward start state with
+// incoming edges, as in x*y or [ab]*c where the pattern starts with a loop.
+// The proxy becomes split -> (reverse of the incoming edges), match, with
+// the incoming edges filled into a state of their own like any other
+// state's edges, so that byte range edges keep their byte ranges.
+func fillAnchoredStartState(builder *Builder, proxyID StateID, edges []reverseEdge, revStateMap map[StateID]StateID, matchID StateID) {
+	var mapped []reverseEdge
+	for _, edge := range edges {
+		if _, ok := revStateMap[edge.from]; ok {
+			mapped = append(mapped, edge)
+		}
+	}
+	if len(mapped) == 0 {
+		return // Keep the epsilon -> match
+	}
+
+	loop := allocatePlaceholder(builder, mapped)
+	fillReverseState(builder, loop, mapped, revStateMap)
+	s := &builder.states[proxyID]
+	s.kind = StateSplit
+	s.left = loop
+	s.right = matchID
+	s.next = InvalidState
+}
+
 // fillEpsilonState fills a state for pure epsilon transitions
 func fillEpsilonState(builder *Builder, revID StateID, epsilonEdges []reverseEdge, revStateMap map[StateID]StateID) {
 	if len(epsilonEdges) == 1 {
@@ -1,6 +1,7 @@
 package nfa
 
 import (
+	"maps"
 	"testing"
 )
 
@@ -409,3 +410,65 @@ func TestReverse_StateCount(t *testing.T) {
 		})
 	}
 }
+
+// TestReverse_LoopAtStart tests patterns whose anchored start state is the
+// target of a loop: the reverse NFA must still require the looped bytes.
+func TestReverse_LoopAtStart(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		reversed string // input, reversed
+		wantEnd  int
+	}{
+		{`x*y`, "yxxz", 3},
+		{`x*y`, "yzxx", 1},
+		{`[ab]*c`, "cbab ", 4},
+		{`a*b*`, "bbaac", 4},
+	}
+	for _, tt := range tests {
+		forward, err := NewDefaultCompiler().Compile(tt.pattern)
+		if err != nil {
+			t.Fatalf("failed to compile %q: %v", tt.pattern, err)
+		}
+		start, end, found := NewPikeVM(ReverseAnchored(forward)).Search([]byte(tt.reversed))
+		if !found || start != 0 || end != tt.wantEnd {
+			t.Errorf("%q reversed on %q: got (%d, %d, %v), want (0, %d, true)",
+				tt.pattern, tt.reversed, start, end, found, tt.wantEnd)
+		}
+	}
+}
+
+// TestReverseAnchoredLook checks that assertions are kept and mirrored.
+func TestReverseAnchoredLook(t *testing.T) {
+	looks := func(n *NFA) map[Look]int {
+		found := make(map[Look]int)
+		for it := n.Iter(); it.HasNext(); {
+			if s := it.Next(); s.Kind() == StateLook {
+				look, _ := s.Look()
+				found[look]++
+			}
+		}
+		return found
+	}
+	tests := []struct {
+		pattern string
+		want    map[Look]int
+	}{
+		{`a\b`, map[Look]int{LookWordBoundary: 1}},
+		{`\Ba`, map[Look]int{LookNoWordBoundary: 1}},
+		{`a$`, map[Look]int{LookStartText: 1}},
+		{`(?m)a$|^b`, map[Look]int{LookStartLine: 1, LookEndLine: 1}},
+		{`x|^a\bb`, map[Look]int{LookEndText: 1, LookWordBoundary: 1}},
+	}
+	for _, tt := range tests {
+		forward, err := NewDefaultCompiler().Compile(tt.pattern)
+		if err != nil {
+			t.Fatalf("Compile(%q): %v", tt.pattern, err)
+		}
+		if got := looks(ReverseAnchoredLook(forward)); !maps.Equal(got, tt.want) {
+			t.Errorf("%q: reverse looks %v, want %v", tt.pattern, got, tt.want)
+		}
+		if got := looks(ReverseAnchored(forward)); len(got) != 0 {
+			t.Errorf("%q: ReverseAnchored looks %v, want none", tt.pattern, got)
+		}
+	}
+}
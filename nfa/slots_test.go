@@ -0,0 +1,100 @@
+package nfa
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestSearchSlotsAt checks that the slot-writing searches agree with
+// SearchWithSlotTableCapturesAt.
+func TestSearchSlotsAt(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		haystack string
+		at       int
+	}{
+		{`(a+)(b)?`, "xxaab", 0},
+		{`(a+)(b)?`, "xxaab", 3},
+		{`(a)|(b)`, "cb", 0},
+		{`^(a)`, "aa", 1},
+		{`(\w+)@(\w+)`, "mail: a@b", 0},
+		{`(x)*`, "", 0},
+		{`(x)*`, "ab", 2},
+		{`none(.)`, "haystack", 0},
+	}
+
+	for _, tt := range tests {
+		n := mustCompile(t, tt.pattern)
+		haystack := []byte(tt.haystack)
+		want := NewPikeVM(n).SearchWithSlotTableCapturesAt(haystack, tt.at)
+
+		slots := make([]int, 2*n.CaptureCount())
+		found := NewPikeVM(n).SearchSlotsAt(haystack, tt.at, slots)
+		if found != (want != nil) {
+			t.Errorf("%q at %d: found = %v, want %v", tt.pattern, tt.at, found, want != nil)
+			continue
+		}
+		if !found {
+			continue
+		}
+		if got := slotsToGroups(slots); !reflect.DeepEqual(got, want.Captures) {
+			t.Errorf("%q at %d: slots = %v, want %v", tt.pattern, tt.at, slots, want.Captures)
+		}
+
+		// The span search finds the same match when given its bounds.
+		clear(slots)
+		if !NewPikeVM(n).SearchSlotsInSpan(haystack, want.Start, want.End, slots) {
+			t.Errorf("%q: SearchSlotsInSpan(%d, %d) found nothing", tt.pattern, want.Start, want.End)
+		} else if got := slotsToGroups(slots); !reflect.DeepEqual(got, want.Captures) {
+			t.Errorf("%q: SearchSlotsInSpan slots = %v, want %v", tt.pattern, slots, want.Captures)
+		}
+	}
+}
+
+// slotsToGroups converts flat slots to the MatchWithCaptures.Captures layout.
+func slotsToGroups(slots []int) [][]int {
+	groups := make([][]int, len(slots)/2)
+	for i := range groups {
+		if slots[2*i] >= 0 {
+			groups[i] = []int{slots[2*i], slots[2*i+1]}
+		}
+	}
+	return groups
+}
+
+// TestSearchCapturesAtEnd checks that an empty match at the end of the
+// haystack reports its groups, not only the overall match.
+func TestSearchCapturesAtEnd(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		haystack string
+		want     [][]int
+	}{
+		{`(x*)|(y)`, "ab", [][]int{{2, 2}, {2, 2}, nil}},
+		{`(y)|(x*)`, "ab", [][]int{{2, 2}, nil, {2, 2}}},
+		{`(x*)|(y)`, "", [][]int{{0, 0}, {0, 0}, nil}},
+		{`(\b)`, "ab", [][]int{{2, 2}, {2, 2}}},
+		{`(a)?($)`, "ab", [][]int{{2, 2}, nil, {2, 2}}},
+	}
+
+	for _, tt := range tests {
+		n := mustCompile(t, tt.pattern)
+		haystack := []byte(tt.haystack)
+		at := len(haystack)
+		m := NewPikeVM(n).SearchWithSlotTableCapturesAt(haystack, at)
+		if m == nil {
+			t.Errorf("%q at %d: no match", tt.pattern, at)
+			continue
+		}
+		if !reflect.DeepEqual(m.Captures, tt.want) {
+			t.Errorf("%q at %d: captures = %v, want %v", tt.pattern, at, m.Captures, tt.want)
+		}
+
+		slots := make([]int, 2*n.CaptureCount())
+		if !NewPikeVM(n).SearchSlotsAt(haystack, at, slots) {
+			t.Errorf("%q at %d: SearchSlotsAt found nothing", tt.pattern, at)
+		} else if got := slotsToGroups(slots); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q at %d: slots = %v, want %v", tt.pattern, at, slots, tt.want)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package nfa
+
+// SpanOptions controls how SearchSpan treats the bounds of the span.
+type SpanOptions struct {
+	// Anchored requires the match to start at the span start.
+	Anchored bool
+
+	// Full requires the match to start at the span start and end at the span
+	// end. It implies Anchored.
+	Full bool
+
+	// Earliest stops at the first position where a match is known to end
+	// instead of extending it by leftmost-first (or leftmost-longest) rules.
+	// Ignored when Full is set.
+	Earliest bool
+}
+
+// SearchSpan finds the first match with capture group positions that lies
+// within haystack[start:end]. Returns nil if no match is found.
+//
+// Unlike slicing the haystack, the bytes outside the span stay visible to
+// look-around assertions: ^ and \A only match at 0, $ and \z only at
+// len(haystack), and \b at either span edge looks at the neighbouring byte.
+//
+// Like the SlotTable searches, SearchSpan is charged to the Interrupt set
+// with SetInterrupt, and reports no match once it fires.
+func (p *PikeVM) SearchSpan(haystack []byte, start, end int, opts SpanOptions) *MatchWithCaptures { //nolint:gocognit // Merged match-check + step loop (Rust's nexts pattern) is inherently complex
+	p.ensureInternalState()
+	p.internalState.lookaround.Begin(haystack, start)
+	if start < 0 || start > end || end > len(haystack) {
+		return nil
+	}
+	anchored := opts.Anchored || opts.Full
+	earliest := opts.Earliest && !opts.Full
+
+	// Reset state
+	p.internalState.Queue = p.internalState.Queue[:0]
+	p.internalState.NextQueue = p.internalState.NextQueue[:0]
+	p.internalState.Visited.Clear()
+
+	bestStart := -1
+	bestEnd := -1
+	var bestCaptures []int
+
+	for pos := start; pos <= end; pos++ {
+		if bestStart == -1 && (pos == start || !anchored) {
+			p.internalState.Visited.Clear()
+			caps := p.newCaptures()
+			p.addThread(thread{state: p.nfa.StartAnchored(), startPos: pos, captures: caps}, haystack, pos)
+		}
+
+		if p.interrupt != nil && p.interrupt.Spend(len(p.internalState.Queue)+1) {
+			return nil
+		}
+
+		// A full match may only end at the span end, so match states met
+		// earlier are dropped without cutting off lower-priority threads.
+		canEnd := !opts.Full || pos == end
+
+		p.internalState.Visited.Clear()
+		for _, t := range p.internalState.Queue {
+			if p.nfa.IsMatch(t.state) {
+				if !canEnd {
+					continue
+				}
+				if p.isBetterMatch(bestStart, bestEnd, t.startPos, pos) {
+					bestStart = t.startPos
+					bestEnd = pos
+					bestCaptures = t.captures.copyData()
+				}
+				if earliest || !p.internalState.Longest || pos == end {
+					break
+				}
+				continue
+			}
+			if pos < end {
+				p.step(t, haystack[pos], haystack, pos+1)
+			}
+		}
+
+		if pos >= end || (earliest && bestStart != -1) {
+			break
+		}
+
+		if bestStart != -1 {
+			hasLeftmostCandidate := false
+			for _, t := range p.internalState.NextQueue {
+				if t.startPos <= bestStart {
+					hasLeftmostCandidate = true
+					break
+				}
+			}
+			if !hasLeftmostCandidate {
+				break
+			}
+		} else if anchored && len(p.internalState.NextQueue) == 0 {
+			break
+		}
+
+		p.internalState.Queue, p.internalState.NextQueue = p.internalState.NextQueue, p.internalState.Queue[:0]
+	}
+
+	if bestStart != -1 {
+		return &MatchWithCaptures{
+			Start:    bestStart,
+			End:      bestEnd,
+			Captures: p.buildCapturesResult(bestCaptures, bestStart, bestEnd),
+		}
+	}
+	return nil
+}
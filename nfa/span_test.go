@@ -0,0 +1,47 @@
+package nfa
+
+import "testing"
+
+func TestPikeVMSearchSpan(t *testing.T) {
+	tests := []struct {
+		name       string
+		pattern    string
+		haystack   string
+		start, end int
+		opts       SpanOptions
+		wantPos    []int // [start, end] or nil for no match
+	}{
+		{"unanchored", `b+`, "abbba", 0, 5, SpanOptions{}, []int{1, 4}},
+		{"bounded end", `b+`, "abbba", 0, 3, SpanOptions{}, []int{1, 3}},
+		{"lookbehind outside span", `\bb`, "abbba", 1, 5, SpanOptions{}, nil},
+		{"lookahead outside span", `b\b`, "abbba", 0, 4, SpanOptions{}, nil},
+		{"anchored", `b+`, "abbba", 0, 5, SpanOptions{Anchored: true}, nil},
+		{"anchored at span start", `b+`, "abbba", 1, 5, SpanOptions{Anchored: true}, []int{1, 4}},
+		{"full", `a|ab`, "ab", 0, 2, SpanOptions{Full: true}, []int{0, 2}},
+		{"full mismatch", `b+`, "abbba", 1, 5, SpanOptions{Full: true}, nil},
+		{"earliest", `b+`, "abbba", 0, 5, SpanOptions{Earliest: true}, []int{1, 2}},
+		{"empty span", `a*`, "bbb", 2, 2, SpanOptions{}, []int{2, 2}},
+		{"invalid span", `a`, "aaa", 2, 1, SpanOptions{}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vm := NewPikeVM(mustCompile(t, tt.pattern))
+			m := vm.SearchSpan([]byte(tt.haystack), tt.start, tt.end, tt.opts)
+
+			if tt.wantPos == nil {
+				if m != nil {
+					t.Errorf("expected no match, got (%d, %d)", m.Start, m.End)
+				}
+				return
+			}
+			if m == nil {
+				t.Errorf("expected match at %v, got no match", tt.wantPos)
+				return
+			}
+			if m.Start != tt.wantPos[0] || m.End != tt.wantPos[1] {
+				t.Errorf("got (%d, %d), want (%d, %d)", m.Start, m.End, tt.wantPos[0], tt.wantPos[1])
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package coregex
+
+import (
+	"github.com/coregx/coregex/meta"
+)
+
+// Observer receives the fallback events of a Regex compiled with
+// meta.Config.Observer set: searches that silently degrade to a slower
+// engine. See meta.Observer.
+type Observer = meta.Observer
+
+// ObserverFunc adapts a function to the Observer interface.
+type ObserverFunc = meta.ObserverFunc
+
+// FallbackEvent describes a search that fell back to a slower engine: its
+// kind, the pattern, strategy and haystack length, and the reason.
+type FallbackEvent = meta.FallbackEvent
+
+// FallbackKind identifies how a search fell back to a slower engine.
+type FallbackKind = meta.FallbackKind
+
+// Fallback kinds of a FallbackEvent.
+const (
+	// FallbackDFACacheFull means the lazy DFA gave up on its full cache and
+	// the search finished on the NFA.
+	FallbackDFACacheFull = meta.FallbackDFACacheFull
+
+	// FallbackBacktrackerTooLong means the input was too long for the
+	// BoundedBacktracker.
+	FallbackBacktrackerTooLong = meta.FallbackBacktrackerTooLong
+
+	// FallbackPrefilterRetired means the search gave up a prefilter whose
+	// candidates kept failing to match and scanned on without it.
+	FallbackPrefilterRetired = meta.FallbackPrefilterRetired
+)
@@ -0,0 +1,69 @@
+package coregex
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestRegexObserver(t *testing.T) {
+	var events []FallbackEvent
+	config := DefaultConfig()
+	config.EnablePrefilter = false
+	config.DenseDFAMaxStates = 0
+	config.Observer = ObserverFunc(func(event FallbackEvent) { events = append(events, event) })
+	re, err := CompileWithConfig(`a[ab]{20}c`, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rng := rand.New(rand.NewSource(1))
+	b := make([]byte, 256<<10)
+	for i := range b {
+		b[i] = "ab"[rng.Intn(2)]
+	}
+	for range 20 {
+		re.Match(b)
+		if len(events) > 0 {
+			break
+		}
+	}
+	if len(events) == 0 {
+		t.Fatal("got no events, want DFACacheFull")
+	}
+	if event := events[0]; event.Kind != FallbackDFACacheFull || event.Pattern != re.String() || event.HaystackLen != len(b) {
+		t.Errorf("event = %+v, want DFACacheFull of %q on %d bytes", event, re.String(), len(b))
+	}
+}
+
+func TestRegexUnmarshalBinaryWithObserver(t *testing.T) {
+	config := DefaultConfig()
+	config.EnablePrefilter = false
+	config.DenseDFAMaxStates = 0
+	re, err := CompileWithConfig(`a[ab]{20}c`, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := re.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var events []FallbackEvent
+	var loaded Regex
+	observer := ObserverFunc(func(event FallbackEvent) { events = append(events, event) })
+	if err := loaded.UnmarshalBinaryWithObserver(data, observer); err != nil {
+		t.Fatal(err)
+	}
+	rng := rand.New(rand.NewSource(1))
+	b := make([]byte, 256<<10)
+	for i := range b {
+		b[i] = "ab"[rng.Intn(2)]
+	}
+	for range 20 {
+		loaded.Match(b)
+		if len(events) > 0 {
+			break
+		}
+	}
+	if len(events) == 0 || events[0].Kind != FallbackDFACacheFull {
+		t.Errorf("events = %+v, want DFACacheFull", events)
+	}
+}
@@ -0,0 +1,116 @@
+package coregex
+
+import (
+	"regexp/syntax"
+	"unicode"
+
+	"github.com/coregx/coregex/meta"
+)
+
+// CompileOptions are the pattern flags of CompileWithOptions. Each option has
+// the effect of the matching inline flag at the start of the pattern, so
+// patterns read from configuration files need no `(?i)` or `(?ms)` prefix.
+type CompileOptions struct {
+	// CaseInsensitive matches letters regardless of case, as (?i).
+	CaseInsensitive bool
+
+	// Multiline makes ^ and $ match at line starts and ends as well as at
+	// the start and end of text, as (?m).
+	Multiline bool
+
+	// DotNL lets . match '\n', as (?s).
+	DotNL bool
+
+	// Ungreedy swaps the meaning of x* and x*?, x+ and x+?, etc., as (?U).
+	Ungreedy bool
+
+	// Verbose ignores unescaped whitespace in the pattern and treats an
+	// unescaped # as the start of a comment that runs to the end of the
+	// line, as Perl's /x. Both are literal inside character classes and
+	// \Q...\E; write `\ ` and `\#` for a literal space and #. Whitespace
+	// is also kept after \p, \P and \x and inside {n,m}, so `a{1, 3}`
+	// matches the literal text as without Verbose. Syntax errors quote the
+	// original pattern text.
+	Verbose bool
+
+	// Literal treats the whole pattern as literal text, as QuoteMeta.
+	// Verbose is ignored; the other options still apply.
+	Literal bool
+
+	// UnicodeWordBoundary makes \b and \B use Unicode word chars (letters,
+	// marks, decimal digits and connector punctuation) instead of
+	// [0-9A-Za-z_], so that `\bслово\b` finds whole words in Russian text.
+	// Matching stays linear; searches fall back from the lazy DFA to the NFA
+	// on non-ASCII input. \w keeps its ASCII meaning.
+	UnicodeWordBoundary bool
+
+	// ExtendedSyntax enables character class set operations and PCRE
+	// escapes: [\p{L}&&[^\p{Latin}]] (intersection), [a-z--[aeiou]]
+	// (difference), nested classes, \h, \H, \R and \Z. See
+	// meta.Config.ExtendedSyntax for the details.
+	ExtendedSyntax bool
+
+	// Classes are named classes for \p{Name} and \P{Name}, for example
+	// {"Hex": unicode.ASCII_Hex_Digit}. Ignored unless ExtendedSyntax is set.
+	Classes map[string]*unicode.RangeTable
+}
+
+// syntaxFlags returns the regexp/syntax flags for the options.
+func (o CompileOptions) syntaxFlags() syntax.Flags {
+	flags := syntax.Perl
+	if o.CaseInsensitive {
+		flags |= syntax.FoldCase
+	}
+	if o.Multiline {
+		flags &^= syntax.OneLine
+	}
+	if o.DotNL {
+		flags |= syntax.DotNL
+	}
+	if o.Ungreedy {
+		flags |= syntax.NonGreedy
+	}
+	if o.Literal {
+		flags |= syntax.Literal
+	}
+	return flags
+}
+
+// Config returns DefaultConfig with the options applied, for use with
+// CompileWithConfig when other settings must be changed as well.
+func (o CompileOptions) Config() meta.Config {
+	config := meta.DefaultConfig()
+	config.SyntaxFlags = o.syntaxFlags()
+	config.Verbose = o.Verbose
+	config.UnicodeWordBoundary = o.UnicodeWordBoundary
+	config.ExtendedSyntax = o.ExtendedSyntax
+	config.Classes = o.Classes
+	return config
+}
+
+// CompileWithOptions compiles a pattern with the given flags.
+//
+// Syntax errors are reported as by Compile; in verbose mode they quote the
+// pattern as written, with the whitespace and comments inside the quoted
+// text but not those after it, and the
+// *meta.CompileError gives the byte offset of the error in the pattern.
+//
+// Example:
+//
+//	re, err := coregex.CompileWithOptions(`
+//	    (?P<year>\d{4}) - (?P<month>\d{2})   # date
+//	    T (?P<hour>\d{2}) : (?P<min>\d{2})   # time
+//	`, coregex.CompileOptions{Verbose: true})
+func CompileWithOptions(pattern string, opts CompileOptions) (*Regex, error) {
+	return CompileWithConfig(pattern, opts.Config())
+}
+
+// MustCompileWithOptions is like CompileWithOptions but panics if the pattern
+// is invalid.
+func MustCompileWithOptions(pattern string, opts CompileOptions) *Regex {
+	re, err := CompileWithOptions(pattern, opts)
+	if err != nil {
+		panic("regexp: CompileWithOptions(`" + pattern + "`): " + err.Error())
+	}
+	return re
+}
@@ -0,0 +1,237 @@
+package coregex
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/coregx/coregex/meta"
+)
+
+func TestCompileWithOptions(t *testing.T) {
+	tests := []struct {
+		pattern string
+		opts    CompileOptions
+		inline  string // equivalent pattern for Compile
+		input   string
+	}{
+		{`hello`, CompileOptions{CaseInsensitive: true}, `(?i)hello`, "say HeLLo"},
+		{`^b$`, CompileOptions{Multiline: true}, `(?m)^b$`, "a\nb\nc"},
+		{`^b$`, CompileOptions{}, `^b$`, "a\nb\nc"},
+		{`a.c`, CompileOptions{DotNL: true}, `(?s)a.c`, "a\nc"},
+		{`a.+`, CompileOptions{Ungreedy: true}, `(?U)a.+`, "abcd"},
+		{`a.+?`, CompileOptions{Ungreedy: true}, `(?U)a.+?`, "abcd"},
+		{`^a.*c$`, CompileOptions{Multiline: true, DotNL: true}, `(?ms)^a.*c$`, "x\na\nbc\nd"},
+		{`a.b*`, CompileOptions{Literal: true}, `a\.b\*`, "aab a.b*"},
+		{`A.B`, CompileOptions{Literal: true, CaseInsensitive: true}, `(?i)A\.B`, "xa.b"},
+		{`a # b`, CompileOptions{Literal: true, Verbose: true}, `a # b`, "xa # b"},
+		{"\\d+ - \\d+  # range", CompileOptions{Verbose: true}, `\d+-\d+`, "pages 10-20"},
+		{"[ ]+ x", CompileOptions{Verbose: true}, `[ ]+x`, "a   x"},
+		{`a\ b \# c`, CompileOptions{Verbose: true}, `a b#c`, "a b#c"},
+		{"a{1, 3} b{2}", CompileOptions{Verbose: true}, `a\{1, 3\}b{2}`, "aaa a{1, 3}bb"},
+		{"(?P<k> \\w+ ) = (?P<v> \\w+ )", CompileOptions{Verbose: true, CaseInsensitive: true}, `(?i)(?P<k>\w+)=(?P<v>\w+)`, "KEY=val"},
+	}
+
+	for _, tt := range tests {
+		re, err := CompileWithOptions(tt.pattern, tt.opts)
+		if err != nil {
+			t.Errorf("CompileWithOptions(%q, %+v): %v", tt.pattern, tt.opts, err)
+			continue
+		}
+		want := MustCompile(tt.inline)
+		if got, w := re.FindAllStringSubmatchIndex(tt.input, -1), want.FindAllStringSubmatchIndex(tt.input, -1); !reflect.DeepEqual(got, w) {
+			t.Errorf("%q %+v: FindAllStringSubmatchIndex(%q) = %v, want %v (as %q)", tt.pattern, tt.opts, tt.input, got, w, tt.inline)
+		}
+		if !reflect.DeepEqual(re.SubexpNames(), want.SubexpNames()) {
+			t.Errorf("%q %+v: SubexpNames = %q, want %q", tt.pattern, tt.opts, re.SubexpNames(), want.SubexpNames())
+		}
+		if re.String() != tt.pattern {
+			t.Errorf("String() = %q, want %q", re.String(), tt.pattern)
+		}
+	}
+}
+
+func TestCompileWithOptionsVerboseErrors(t *testing.T) {
+	pattern := strings.Join([]string{
+		`(?P<year> \d{4} )  # year`,
+		`- (?P<month> \d{2}** )  # month`,
+	}, "\n")
+	_, err := CompileWithOptions(pattern, CompileOptions{Verbose: true})
+	var cerr *meta.CompileError
+	if !errors.As(err, &cerr) {
+		t.Fatalf("error = %v, want a *meta.CompileError", err)
+	}
+	if want := strings.Index(pattern, "{2}*"); cerr.Offset != want {
+		t.Errorf("Offset = %d, want %d", cerr.Offset, want)
+	}
+	if want := "invalid nested repetition operator: `{2}*`"; !strings.Contains(err.Error(), want) {
+		t.Errorf("error = %q, want it to contain %q", err, want)
+	}
+
+	// The error quotes the pattern as written, comments included, up to
+	// the end of the text the parser refers to.
+	pattern = "a ( # group\n b"
+	_, err = CompileWithOptions(pattern, CompileOptions{Verbose: true})
+	if err == nil || !strings.Contains(err.Error(), "`"+pattern+"`") {
+		t.Errorf("error = %v, want it to quote %q", err, pattern)
+	}
+	_, err = CompileWithOptions(pattern+"  # tail", CompileOptions{Verbose: true})
+	if err == nil || !strings.Contains(err.Error(), "`"+pattern+"`") {
+		t.Errorf("trailing comment: error = %v, want it to quote %q", err, pattern)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("MustCompileWithOptions did not panic")
+		}
+	}()
+	MustCompileWithOptions(`a(`, CompileOptions{})
+}
+
+func TestCompileWithOptionsKeptByRegex(t *testing.T) {
+	re := MustCompileWithOptions("hello \\ world # greeting", CompileOptions{Verbose: true, CaseInsensitive: true})
+
+	if got := re.Copy().FindString("say HELLO WORLD"); got != "HELLO WORLD" {
+		t.Errorf("Copy().FindString = %q, want %q", got, "HELLO WORLD")
+	}
+
+	re = MustCompileWithOptions("abc def # tail", CompileOptions{Verbose: true})
+	if prefix, complete := re.LiteralPrefix(); prefix != "abcdef" || !complete {
+		t.Errorf("LiteralPrefix() = %q, %v; want %q, true", prefix, complete, "abcdef")
+	}
+
+	config := CompileOptions{Multiline: true}.Config()
+	config.EnableDFA = false
+	re, err := CompileWithConfig(`^x`, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := re.FindAllStringIndex("x\nx", -1); len(got) != 2 {
+		t.Errorf("Config(): FindAllStringIndex = %v, want 2 matches", got)
+	}
+}
+
+func TestCompileWithOptionsExtendedSyntax(t *testing.T) {
+	ext := CompileOptions{
+		ExtendedSyntax: true,
+		Classes:        map[string]*unicode.RangeTable{"Hex": unicode.ASCII_Hex_Digit},
+	}
+	tests := []struct {
+		pattern string
+		opts    CompileOptions
+		plain   string // equivalent pattern for Compile
+		input   string
+	}{
+		{`[a-z--[aeiou]]+`, ext, `[b-df-hj-np-tv-z]+`, "strength queue rhythm"},
+		{`[\p{L}&&[^\p{Latin}]]+`, ext, `[\p{Greek}\p{Cyrillic}]+`, "abc αβγ где xyz"},
+		{`[\w&&\D]+`, ext, `[A-Za-z_]+`, "a1_b2 C3"},
+		{`(?i)[a-z--[aeiou]]+`, ext, `(?i)[b-df-hj-np-tv-z]+`, "STRENGTH Queue"},
+		{`\h+`, ext, `[\t \x{A0}\x{3000}]+`, "a \t b\n　c"},
+		{`\H+`, ext, `[^\t \x{A0}\x{3000}]+`, "a \tb\nc"},
+		{`\w+\R`, ext, `\w+(?:\r\n|[\n\v\f\r\x{85}\x{2028}\x{2029}])`, "a\r\nb\rc d"},
+		{`\A\w+`, ext, `\A\w+`, "ab cd"},
+		{`\w+\Z`, ext, `\w+(?=\n?\z)`, "ab cd\n"},
+		{`\w+\Z`, ext, `\w+\z`, "ab cd"},
+		{`0x\p{Hex}+`, ext, `0x[0-9A-Fa-f]+`, "0xBEEF 0xzz"},
+		{`[\P{Hex}--\s]+`, ext, `[^\s0-9A-Fa-f]+`, "cafe xyz"},
+		{"[a-z -- [aeiou]]+  # consonants", CompileOptions{ExtendedSyntax: true, Verbose: true}, `[b-df-hj-np-tv-z]+`, "strength queue"},
+	}
+	for _, tt := range tests {
+		re, err := CompileWithOptions(tt.pattern, tt.opts)
+		if err != nil {
+			t.Errorf("CompileWithOptions(%q): %v", tt.pattern, err)
+			continue
+		}
+		want := MustCompile(tt.plain)
+		got, w := re.FindAllStringIndex(tt.input, -1), want.FindAllStringIndex(tt.input, -1)
+		if !reflect.DeepEqual(got, w) {
+			t.Errorf("%q: FindAllStringIndex(%q) = %v, want %v (as %q)", tt.pattern, tt.input, got, w, tt.plain)
+		}
+		if re.String() != tt.pattern {
+			t.Errorf("String() = %q, want %q", re.String(), tt.pattern)
+		}
+	}
+
+	// Without ExtendedSyntax, && and -- keep their regexp/syntax meaning.
+	if got := MustCompile(`[a&&b]+`).FindString("x&&ab"); got != "&&ab" {
+		t.Errorf("FindString = %q, want %q", got, "&&ab")
+	}
+	if _, err := CompileWithOptions(`\h`, CompileOptions{}); err == nil {
+		t.Error(`\h compiled without ExtendedSyntax`)
+	}
+}
+
+func TestCompileWithOptionsExtendedSyntaxFold(t *testing.T) {
+	ext := CompileOptions{ExtendedSyntax: true}
+	tests := []struct {
+		pattern string
+		opts    CompileOptions
+		plain   string // equivalent pattern for Compile
+		input   string
+	}{
+		{`(?i)[a-z--[A-E]]+`, ext, `(?i)[f-z]+`, "abcFGH xyz EdF"},
+		{`(?i)[A-Z&&[a-f]]+`, ext, `(?i)[a-f]+`, "ABCxyz def"},
+		{`[a-z--[A-E]]+`, CompileOptions{ExtendedSyntax: true, CaseInsensitive: true}, `(?i)[f-z]+`, "abcFGH xyz"},
+		{`(?i:[a-z--[A-E]])[a-z--[A-E]]`, ext, `(?i:[f-z])[a-z]`, "Fa Ab fb"},
+		{`(?i)a(?-i)[a-z--[A-E]]`, ext, `(?i:a)[a-z]`, "Aa ab"},
+		{`((?i)x)[a-z--[A-E]]`, ext, `((?i)x)[a-z]`, "Xa xb"},
+		{`(?i)[\x{212A}--k]`, ext, `[^\x00-\x{10FFFF}]`, "kKK"},
+	}
+	for _, tt := range tests {
+		re, err := CompileWithOptions(tt.pattern, tt.opts)
+		if err != nil {
+			t.Errorf("CompileWithOptions(%q): %v", tt.pattern, err)
+			continue
+		}
+		want := MustCompile(tt.plain)
+		got, w := re.FindAllStringIndex(tt.input, -1), want.FindAllStringIndex(tt.input, -1)
+		if !reflect.DeepEqual(got, w) {
+			t.Errorf("%q: FindAllStringIndex(%q) = %v, want %v (as %q)", tt.pattern, tt.input, got, w, tt.plain)
+		}
+	}
+}
+
+func TestCompileOptionsMarshalText(t *testing.T) {
+	tests := []struct {
+		pattern string
+		opts    CompileOptions
+		want    string
+		match   string
+	}{
+		{`a.`, CompileOptions{DotNL: true}, `(?s)a.`, "a\n"},
+		{`^b$`, CompileOptions{Multiline: true, CaseInsensitive: true}, `(?im)^b$`, "a\nB\nc"},
+		{`a+`, CompileOptions{Ungreedy: true}, `(?U)a+`, "aaa"},
+		{"a b # comment\n c", CompileOptions{Verbose: true}, `abc`, "abc"},
+		{`a.b`, CompileOptions{Literal: true, CaseInsensitive: true}, `(?i)a\.b`, "A.B"},
+		{`a.b`, CompileOptions{Literal: true, Verbose: true}, `a\.b`, "a.b"},
+		{`x`, CompileOptions{}, `x`, "x"},
+	}
+	for _, tt := range tests {
+		re := MustCompileWithOptions(tt.pattern, tt.opts)
+		text, err := re.MarshalText()
+		if err != nil {
+			t.Errorf("%q %+v: MarshalText: %v", tt.pattern, tt.opts, err)
+			continue
+		}
+		if string(text) != tt.want {
+			t.Errorf("%q %+v: MarshalText = %q, want %q", tt.pattern, tt.opts, text, tt.want)
+		}
+		var back Regex
+		if err := back.UnmarshalText(text); err != nil {
+			t.Errorf("UnmarshalText(%q): %v", text, err)
+			continue
+		}
+		if got, want := back.FindString(tt.match), re.FindString(tt.match); got != want || want == "" {
+			t.Errorf("%q after round trip: FindString(%q) = %q, want %q", tt.pattern, tt.match, got, want)
+		}
+	}
+
+	for _, opts := range []CompileOptions{{UnicodeWordBoundary: true}, {ExtendedSyntax: true}} {
+		re := MustCompileWithOptions(`\bx`, opts)
+		if _, err := re.MarshalText(); !errors.Is(err, meta.ErrNoInlineFlags) {
+			t.Errorf("%+v: MarshalText error = %v, want meta.ErrNoInlineFlags", opts, err)
+		}
+	}
+}
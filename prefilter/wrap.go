@@ -80,3 +80,18 @@ func (w *lineAnchorWrapper) HeapBytes() int {
 func (w *lineAnchorWrapper) IsFast() bool {
 	return w.inner.IsFast()
 }
+
+// Unwrap returns the prefilter that a WrapIncomplete or WrapLineAnchor
+// wrapper searches with, or pf itself if it is not wrapped.
+func Unwrap(pf Prefilter) Prefilter {
+	for {
+		switch w := pf.(type) {
+		case *incompleteWrapper:
+			pf = w.inner
+		case *lineAnchorWrapper:
+			pf = w.inner
+		default:
+			return pf
+		}
+	}
+}
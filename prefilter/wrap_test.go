@@ -0,0 +1,28 @@
+package prefilter
+
+import (
+	"testing"
+
+	"github.com/coregx/coregex/literal"
+)
+
+func TestUnwrap(t *testing.T) {
+	pf := NewBuilder(literal.NewSeq(literal.NewLiteral([]byte("foo"), true)), nil).Build()
+	if pf == nil {
+		t.Fatal("no prefilter for \"foo\"")
+	}
+	tests := []struct {
+		name string
+		pf   Prefilter
+	}{
+		{"plain", pf},
+		{"incomplete", WrapIncomplete(pf)},
+		{"line anchor", WrapLineAnchor(pf)},
+		{"nested", WrapIncomplete(WrapLineAnchor(pf))},
+	}
+	for _, tt := range tests {
+		if got := Unwrap(tt.pf); got != pf {
+			t.Errorf("%s: Unwrap = %T, want %T", tt.name, got, pf)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package coregex
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// runeOnlyReader hides every method but ReadRune, forcing the re-encoding path.
+type runeOnlyReader struct {
+	rr io.RuneReader
+}
+
+func (r runeOnlyReader) ReadRune() (rune, int, error) {
+	return r.rr.ReadRune()
+}
+
+func TestFindReaderIndexMatchesStdlib(t *testing.T) {
+	tests := []struct {
+		pattern string
+		input   string
+	}{
+		{`\d+`, "abc 123 def"},
+		{`wörld`, "héllo wörld"},
+		{`b`, "a\xffb"},
+		{`[^a]+`, "aa\xff\xfeb"},
+		{`(\w+)@(\w+)`, "mail: bob@example"},
+		{`x$`, "x\nx"},
+		{`nomatch`, "haystack"},
+	}
+
+	for _, tt := range tests {
+		re := MustCompile(tt.pattern)
+		std := regexp.MustCompile(tt.pattern)
+
+		readers := map[string]func() io.RuneReader{
+			"strings": func() io.RuneReader { return strings.NewReader(tt.input) },
+			"bufio":   func() io.RuneReader { return bufio.NewReaderSize(strings.NewReader(tt.input), 16) },
+			"runes":   func() io.RuneReader { return runeOnlyReader{strings.NewReader(tt.input)} },
+		}
+		for name, mk := range readers {
+			want := std.FindReaderIndex(mk())
+			if got := re.FindReaderIndex(mk()); !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: %q FindReaderIndex(%q) = %v, want %v", name, tt.pattern, tt.input, got, want)
+			}
+			wantSub := std.FindReaderSubmatchIndex(mk())
+			if got := re.FindReaderSubmatchIndex(mk()); !reflect.DeepEqual(got, wantSub) {
+				t.Errorf("%s: %q FindReaderSubmatchIndex(%q) = %v, want %v", name, tt.pattern, tt.input, got, wantSub)
+			}
+			if got, want := re.MatchReader(mk()), std.MatchReader(mk()); got != want {
+				t.Errorf("%s: %q MatchReader(%q) = %v, want %v", name, tt.pattern, tt.input, got, want)
+			}
+		}
+	}
+}
+
+func TestFindReaderIndexLongest(t *testing.T) {
+	re := MustCompile(`a|ab|abc`)
+	re.Longest()
+	got := re.FindReaderIndex(strings.NewReader("xxabcd"))
+	if want := []int{2, 5}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FindReaderIndex = %v, want %v", got, want)
+	}
+}
+
+func TestRuneByteReader(t *testing.T) {
+	// Runes split across short buffers are finished by the next Read, and
+	// an invalid byte comes out as 0xFF.
+	input := "héllo wörld 日本語 \U0001F600 a\xffb"
+	want := []byte("héllo wörld 日本語 \U0001F600 a\xffb")
+	if err := iotest.TestReader(readerBytes(runeOnlyReader{strings.NewReader(input)}), want); err != nil {
+		t.Error(err)
+	}
+	got, err := io.ReadAll(iotest.OneByteReader(readerBytes(runeOnlyReader{strings.NewReader(input)})))
+	if err != nil || !bytes.Equal(got, want) {
+		t.Errorf("ReadAll(OneByteReader) = %q, %v, want %q", got, err, want)
+	}
+}
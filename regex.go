@@ -46,13 +46,16 @@
 package coregex
 
 import (
+	"fmt"
 	"io"
 	"iter"
 	"regexp/syntax"
 	"strings"
+	"unicode/utf8"
 	"unsafe"
 
 	"github.com/coregx/coregex/meta"
+	"github.com/coregx/coregex/nfa"
 )
 
 // stringToBytes converts string to []byte without allocation.
@@ -134,6 +137,46 @@ func MustCompile(pattern string) *Regex {
 	return re
 }
 
+// CompileBytes compiles a pattern for matching arbitrary binary data.
+//
+// In byte mode the haystack is a sequence of bytes, not UTF-8 text, and every
+// character in the pattern stands for the byte with the same value (the
+// Latin-1 view of the input):
+//   - `\xFF`, `\x{80}` and `é` (U+00E9) match the single bytes 0xFF, 0x80
+//     and 0xE9; a literal character above U+00FF is a compile error
+//   - `.` matches any byte except '\n' ((?s). any byte), and negated classes
+//     such as [^a], \D, \W and \S match any byte outside the class,
+//     including 0x80-0xFF
+//   - Unicode classes (\pL, \p{Greek}, ...) keep only their code points up
+//     to U+00FF, so \pL matches ASCII and Latin-1 letter bytes, and
+//     \p{Greek} matches nothing
+//   - (?i) folds a character to its simple case folds that are bytes: (?i)k
+//     matches K and k, (?i)é matches 0xC9 and 0xE9
+//   - \b, \d, \w and \s are ASCII-only, as in UTF-8 mode
+//
+// Go's syntax has no (?-u) flag; byte mode applies to the whole pattern. All
+// engines (NFA, lazy DFA, prefilters, captures) run on bytes, and reported
+// offsets are byte offsets.
+//
+// Example:
+//
+//	re := coregex.MustCompileBytes(`\x89PNG\r\n\x1A\n(.{4})IHDR`)
+//	loc := re.FindSubmatchIndex(data)
+func CompileBytes(pattern string) (*Regex, error) {
+	config := meta.DefaultConfig()
+	config.Bytes = true
+	return CompileWithConfig(pattern, config)
+}
+
+// MustCompileBytes is like CompileBytes but panics if the pattern is invalid.
+func MustCompileBytes(pattern string) *Regex {
+	re, err := CompileBytes(pattern)
+	if err != nil {
+		panic("regexp: CompileBytes(`" + pattern + "`): " + err.Error())
+	}
+	return re
+}
+
 // CompilePOSIX is like Compile but restricts the regular expression to
 // POSIX ERE (egrep) syntax and changes the match semantics to leftmost-longest.
 //
@@ -480,7 +523,7 @@ func (r *Regex) Longest() {
 //	prefix2, complete2 := re2.LiteralPrefix()
 //	// prefix2 = "Hello", complete2 = true
 func (r *Regex) LiteralPrefix() (prefix string, complete bool) {
-	re, err := syntax.Parse(r.pattern, syntax.Perl)
+	re, err := meta.Parse(r.pattern, r.engine.Config())
 	if err != nil {
 		return "", false
 	}
@@ -514,7 +557,11 @@ func literalPrefix(re *syntax.Regexp) (string, bool) {
 				hasAnchor = true
 				continue
 			case syntax.OpEmptyMatch:
-				// Empty match doesn't affect prefix
+				// Empty match doesn't affect prefix, but look-around
+				// constrains what follows
+				if nfa.IsLookaround(sub) {
+					return string(prefix), false
+				}
 				continue
 			default:
 				// Non-literal found
@@ -536,7 +583,7 @@ func literalPrefix(re *syntax.Regexp) (string, bool) {
 		// Anchors alone mean no literal prefix and not complete
 		return "", false
 	case syntax.OpEmptyMatch:
-		return "", true
+		return "", !nfa.IsLookaround(re)
 	default:
 		return "", false
 	}
@@ -794,8 +841,11 @@ func (r *Regex) ReplaceAllLiteral(src, repl []byte) []byte {
 	lastMatchEnd := -1
 	matched := false
 
+	searcher := r.engine.NewSearcher(len(src))
+	defer searcher.Close()
+
 	for {
-		start, end, found := r.engine.FindIndicesAt(src, pos)
+		start, end, found := searcher.FindIndicesAt(src, pos)
 		if !found {
 			break
 		}
@@ -867,8 +917,11 @@ func (r *Regex) ReplaceAllLiteralString(src, repl string) string {
 	lastMatchEnd := -1
 	matched := false
 
+	searcher := r.engine.NewSearcher(len(b))
+	defer searcher.Close()
+
 	for {
-		start, end, found := r.engine.FindIndicesAt(b, pos)
+		start, end, found := searcher.FindIndicesAt(b, pos)
 		if !found {
 			break
 		}
@@ -1018,11 +1071,15 @@ func (r *Regex) ReplaceAll(src, repl []byte) []byte {
 		return r.ReplaceAllLiteral(src, repl)
 	}
 
-	// Need to find submatches for expansion
-	// Use NumCaptures() (includes group 0) for internal buffer sizing
-	// NumSubexp() returns only parenthesized groups (excluding group 0)
-	numCaptures := r.engine.NumCaptures() // includes group 0
+	return r.replaceAll(src, func(dst []byte, match []int) []byte {
+		return r.expand(dst, repl, src, match)
+	})
+}
 
+// replaceAll returns a copy of src with every match replaced by what expand
+// appends for it. match holds the submatch indices as in FindSubmatchIndex
+// and is reused across matches.
+func (r *Regex) replaceAll(src []byte, expand func(dst []byte, match []int) []byte) []byte {
 	// Pre-allocate result buffer based on input size
 	// Estimate: input size + 25% for replacements
 	estimatedLen := len(src) * 5 / 4
@@ -1030,33 +1087,23 @@ func (r *Regex) ReplaceAll(src, repl []byte) []byte {
 
 	// Pre-allocate matchIndices buffer and reuse it (avoid allocation per match)
 	// This includes group 0 (entire match) plus all capture groups
-	matchIndices := make([]int, numCaptures*2)
+	matchIndices := make([]int, r.engine.NumCaptures()*2)
 
 	lastEnd := 0
 	pos := 0
 	lastNonEmptyMatchEnd := -1 // Track where the last non-empty match ended
 
+	// One searcher for the whole loop keeps look-around results across matches.
+	searcher := r.engine.NewSearcher(len(src))
+	defer searcher.Close()
+
 	for {
-		// Search from current position using FindSubmatchAt to preserve absolute positions
+		// Search from current position using FindSubmatchSlotsAt to preserve absolute positions
 		// This is critical for correct anchor handling (^ should only match at pos 0)
-		matchData := r.engine.FindSubmatchAt(src, pos)
-		if matchData == nil {
+		if !searcher.FindSubmatchSlotsAt(src, pos, matchIndices) {
 			break
 		}
 
-		// Get match indices (already absolute from FindSubmatchAt)
-		// Reuse pre-allocated buffer (reset values each iteration)
-		for i := 0; i < numCaptures; i++ {
-			idx := matchData.GroupIndex(i)
-			if len(idx) >= 2 {
-				matchIndices[i*2] = idx[0]
-				matchIndices[i*2+1] = idx[1]
-			} else {
-				matchIndices[i*2] = -1
-				matchIndices[i*2+1] = -1
-			}
-		}
-
 		absStart := matchIndices[0]
 		absEnd := matchIndices[1]
 
@@ -1075,7 +1122,7 @@ func (r *Regex) ReplaceAll(src, repl []byte) []byte {
 		result = append(result, src[lastEnd:absStart]...)
 
 		// Expand template
-		result = r.expand(result, repl, src, matchIndices)
+		result = expand(result, matchIndices)
 
 		lastEnd = absEnd
 
@@ -1140,8 +1187,11 @@ func (r *Regex) ReplaceAllFunc(src []byte, repl func([]byte) []byte) []byte {
 	lastMatchEnd := -1
 	matched := false
 
+	searcher := r.engine.NewSearcher(len(src))
+	defer searcher.Close()
+
 	for {
-		start, end, found := r.engine.FindIndicesAt(src, pos)
+		start, end, found := searcher.FindIndicesAt(src, pos)
 		if !found {
 			break
 		}
@@ -1214,8 +1264,11 @@ func (r *Regex) ReplaceAllStringFunc(src string, repl func(string) string) strin
 	lastMatchEnd := -1
 	matched := false
 
+	searcher := r.engine.NewSearcher(len(b))
+	defer searcher.Close()
+
 	for {
-		start, end, found := r.engine.FindIndicesAt(b, pos)
+		start, end, found := searcher.FindIndicesAt(b, pos)
 		if !found {
 			break
 		}
@@ -1307,6 +1360,11 @@ func (r *Regex) Split(s string, n int) []string {
 
 	lastEnd := 0
 	for _, idx := range indices {
+		// Stop at the limit, leaving room for the unsplit remainder
+		if n > 0 && len(result) >= n-1 {
+			break
+		}
+
 		// Skip empty match at the beginning (position 0 with zero-width match)
 		// This matches stdlib behavior: Split("", "abc") = ["a", "b", "c"], not ["", "a", "b", "c", ""]
 		if lastEnd == 0 && idx[0] == 0 && idx[1] == 0 {
@@ -1321,13 +1379,6 @@ func (r *Regex) Split(s string, n int) []string {
 		// Add substring before match
 		result = append(result, s[lastEnd:idx[0]])
 		lastEnd = idx[1]
-
-		// Check if we've reached the limit (but need room for final element)
-		if n > 0 && len(result) >= n-1 {
-			// Add the rest as the final element
-			result = append(result, s[lastEnd:])
-			return result
-		}
 	}
 
 	// Add remaining text after last match
@@ -1484,10 +1535,12 @@ func (r *Regex) FindAllStringSubmatchIndex(s string, n int) [][]int {
 //	// match at [6, 9]
 func (r *Regex) AllIndex(b []byte) iter.Seq[[2]int] {
 	return func(yield func([2]int) bool) {
+		searcher := r.engine.NewSearcher(len(b))
+		defer searcher.Close()
 		pos := 0
 		lastMatchEnd := -1
 		for pos <= len(b) {
-			start, end, found := r.engine.FindIndicesAt(b, pos)
+			start, end, found := searcher.FindIndicesAt(b, pos)
 			if !found {
 				return
 			}
@@ -1586,7 +1639,7 @@ func (r *Regex) Copy() *Regex {
 	// Create a new Regex with the same pattern
 	// Note: This re-compiles the pattern, which is slightly slower than
 	// sharing the internal engine, but ensures complete independence.
-	re, err := Compile(r.pattern)
+	re, err := CompileWithConfig(r.pattern, r.engine.Config())
 	if err != nil {
 		// This should never happen since the pattern was already compiled
 		return nil
@@ -1597,10 +1650,26 @@ func (r *Regex) Copy() *Regex {
 	return re
 }
 
-// MarshalText implements encoding.TextMarshaler. The output
-// is the result of r.String().
+// MarshalText implements encoding.TextMarshaler. For a regex compiled with
+// Compile the output is the result of r.String(). The options of
+// CompileWithOptions are kept as an inline flag prefix, with verbose and
+// literal patterns rewritten first, so that UnmarshalText restores a regex
+// that matches the same text:
+//
+//	re := coregex.MustCompileWithOptions(`a.`, coregex.CompileOptions{DotNL: true})
+//	text, _ := re.MarshalText()
+//	// text = "(?s)a."
+//
+// Options without an inline flag (byte mode, UnicodeWordBoundary,
+// ExtendedSyntax) make MarshalText return an error wrapping
+// meta.ErrNoInlineFlags. Like regexp.Regexp, the output does not record
+// Longest.
 func (r *Regex) MarshalText() ([]byte, error) {
-	return []byte(r.pattern), nil
+	text, err := meta.InlineFlags(r.pattern, r.engine.Config())
+	if err != nil {
+		return nil, fmt.Errorf("regexp: MarshalText(`%s`): %w", r.pattern, err)
+	}
+	return []byte(text), nil
 }
 
 // UnmarshalText implements encoding.TextUnmarshaler by calling
@@ -1616,17 +1685,14 @@ func (r *Regex) UnmarshalText(text []byte) error {
 
 // MatchReader reports whether the text returned by the RuneReader
 // contains any match of the regular expression re.
+//
+// The input is searched as a stream: reading stops at the first byte that
+// proves a match, and memory use does not grow with the length of the input.
+// If reader also implements io.Reader, its bytes are searched directly, so
+// invalid UTF-8 is seen exactly as it appears in the stream.
 func (r *Regex) MatchReader(reader io.RuneReader) bool {
-	// Read all runes into a string and match
-	var runes []rune
-	for {
-		rn, _, err := reader.ReadRune()
-		if err != nil {
-			break
-		}
-		runes = append(runes, rn)
-	}
-	return r.MatchString(string(runes))
+	matched, _ := r.engine.MatchReader(readerBytes(reader))
+	return matched
 }
 
 // FindReaderIndex returns a two-element slice of integers defining the
@@ -1634,17 +1700,12 @@ func (r *Regex) MatchReader(reader io.RuneReader) bool {
 // the RuneReader. The match text was found in the input stream at
 // byte offset loc[0] through loc[1]-1.
 // A return value of nil indicates no match.
+//
+// The input is searched as a stream with bounded memory (see
+// meta.StreamWindow); reading stops once the match is certain.
 func (r *Regex) FindReaderIndex(reader io.RuneReader) []int {
-	// Read all runes into a string and find
-	var runes []rune
-	for {
-		rn, _, err := reader.ReadRune()
-		if err != nil {
-			break
-		}
-		runes = append(runes, rn)
-	}
-	return r.FindStringIndex(string(runes))
+	loc, _ := r.engine.FindReaderIndex(readerBytes(reader))
+	return loc
 }
 
 // FindReaderSubmatchIndex returns a slice holding the index pairs
@@ -1654,16 +1715,59 @@ func (r *Regex) FindReaderIndex(reader io.RuneReader) []int {
 // package comment.
 // A return value of nil indicates no match.
 func (r *Regex) FindReaderSubmatchIndex(reader io.RuneReader) []int {
-	// Read all runes into a string and find
-	var runes []rune
-	for {
-		rn, _, err := reader.ReadRune()
+	loc, _ := r.engine.FindReaderSubmatchIndex(readerBytes(reader))
+	return loc
+}
+
+// readerBytes returns the byte stream behind a RuneReader. Readers that also
+// implement io.Reader (bufio.Reader, strings.Reader, bytes.Reader, ...) are
+// used as-is; others are re-encoded rune by rune.
+func readerBytes(reader io.RuneReader) io.Reader {
+	if br, ok := reader.(io.Reader); ok {
+		return br
+	}
+	return &runeByteReader{rr: reader}
+}
+
+// runeByteReader re-encodes the runes of an io.RuneReader as UTF-8 bytes.
+//
+// A rune reported as utf8.RuneError with size 1 stands for one invalid byte
+// whose value is lost; it is replaced by the invalid byte 0xFF so that byte
+// offsets still match the original stream.
+type runeByteReader struct {
+	rr io.RuneReader
+
+	// buf holds the encoding of the last rune read, and pending the part
+	// of it that did not fit into the caller's buffer yet.
+	buf     [utf8.UTFMax]byte
+	pending []byte
+}
+
+// Read implements io.Reader. A rune that does not fit into the rest of p is
+// split, and its remaining bytes are returned first by the next Read, so
+// that buffers shorter than utf8.UTFMax still make progress.
+func (r *runeByteReader) Read(p []byte) (int, error) {
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	for n < len(p) {
+		c, size, err := r.rr.ReadRune()
 		if err != nil {
-			break
+			if n > 0 && err == io.EOF {
+				return n, nil
+			}
+			return n, err
+		}
+		enc := r.buf[:1]
+		if c == utf8.RuneError && size == 1 {
+			enc[0] = 0xFF
+		} else {
+			enc = r.buf[:utf8.EncodeRune(r.buf[:], c)]
 		}
-		runes = append(runes, rn)
+		k := copy(p[n:], enc)
+		n += k
+		r.pending = enc[k:]
 	}
-	return r.FindStringSubmatchIndex(string(runes))
+	return n, nil
 }
 
 // MatchReader reports whether the text returned by the RuneReader
@@ -0,0 +1,69 @@
+package coregex
+
+import (
+	"bytes"
+	"io"
+)
+
+// ReplaceAllReader copies src to dst, replacing matches of the pattern with
+// the replacement text repl, and returns the number of bytes written to dst.
+// Inside repl, $ signs are interpreted as in Expand, as in ReplaceAll.
+//
+// The input is processed in bounded chunks rather than read into memory:
+// the output is identical to ReplaceAll on the whole input, including for
+// matches that span chunk boundaries and for $, \b and empty matches at the
+// end of the stream. Each match is written out as soon as it can no longer
+// change. Memory use does not grow with the stream length: it is bounded by
+// meta.StreamWindow plus the length of the longest match.
+//
+// It returns the first error from reading src or writing dst.
+//
+// Example:
+//
+//	re := coregex.MustCompile(`(\w+)@(\w+)\.com`)
+//	f, _ := os.Open("access.log")
+//	defer f.Close()
+//	_, err := re.ReplaceAllReader(os.Stdout, f, []byte("<$1 at $2>"))
+func (r *Regex) ReplaceAllReader(dst io.Writer, src io.Reader, repl []byte) (int64, error) {
+	// If no $ variables, use faster literal replacement
+	if bytes.IndexByte(repl, '$') < 0 {
+		return r.ReplaceAllLiteralReader(dst, src, repl)
+	}
+	return r.engine.ReplaceReader(dst, src, true, func(out, hay []byte, match []int) []byte {
+		return r.expand(out, repl, hay, match)
+	})
+}
+
+// ReplaceAllLiteralReader is like ReplaceAllReader, but repl is substituted
+// directly, without expanding $ variables.
+func (r *Regex) ReplaceAllLiteralReader(dst io.Writer, src io.Reader, repl []byte) (int64, error) {
+	return r.engine.ReplaceReader(dst, src, false, func(out, _ []byte, _ []int) []byte {
+		return append(out, repl...)
+	})
+}
+
+// ReplaceAllFuncReader is like ReplaceAllReader, but each match is replaced
+// by the return value of repl applied to the matched text, without
+// expanding $ variables. The slice passed to repl is only valid during the
+// call.
+//
+// Example:
+//
+//	re := coregex.MustCompile(`\d+`)
+//	_, err := re.ReplaceAllFuncReader(w, r, func(s []byte) []byte {
+//	    n, _ := strconv.Atoi(string(s))
+//	    return strconv.AppendInt(nil, int64(n*2), 10)
+//	})
+func (r *Regex) ReplaceAllFuncReader(dst io.Writer, src io.Reader, repl func([]byte) []byte) (int64, error) {
+	return r.engine.ReplaceReader(dst, src, false, func(out, hay []byte, match []int) []byte {
+		return append(out, repl(hay[match[0]:match[1]:match[1]])...)
+	})
+}
+
+// ReplaceAllTemplateReader is like ReplaceAllReader, but every match is
+// replaced by the expansion of the precompiled template t.
+func (r *Regex) ReplaceAllTemplateReader(dst io.Writer, src io.Reader, t *Template) (int64, error) {
+	return r.engine.ReplaceReader(dst, src, true, func(out, hay []byte, match []int) []byte {
+		return t.Expand(out, hay, match)
+	})
+}
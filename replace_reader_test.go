@@ -0,0 +1,233 @@
+package coregex
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// errWriter fails every write after the first n bytes.
+type errWriter struct {
+	n   int
+	err error
+}
+
+func (w *errWriter) Write(p []byte) (int, error) {
+	if len(p) <= w.n {
+		w.n -= len(p)
+		return len(p), nil
+	}
+	n := w.n
+	w.n = 0
+	return n, w.err
+}
+
+func TestReplaceAllReaderMatchesReplaceAll(t *testing.T) {
+	tests := []struct {
+		pattern string
+		input   string
+		repl    string
+	}{
+		{`\d+`, "abc 123 def 4567", "<$0>"},
+		{`(\w+)@(\w+)`, "mail bob@example and eve@host", "$2:$1"},
+		{`foo`, "no match here", "bar"},
+		{`a*`, "baaac", "-"},
+		{`x*`, "abc", "[$0]"},
+		{``, "abc", "."},
+		{``, "", "."},
+		{`^`, "abc", ">"},
+		{`$`, "abc", "<"},
+		{`(?m)^`, "a\nb\nc", "> "},
+		{`(?m)$`, "a\nb\n", ";"},
+		{`^abc`, "abcabc", "X"},
+		{`abc$`, "abc abc", "X"},
+		{`\bcat\b`, "concat cat cat", "dog"},
+		{`\B`, "abc", "-"},
+		{`é+`, "caféé! éé", "e"},
+		{`[^a]+`, "aa\xff\xfeb", "?"},
+		{`(a|ab)(c|bcd)`, "xabcd abcd", "$1|$2"},
+		{`a+?`, "baaa", "b"},
+		{`(foo)?bar`, "xbar foobar", "[$1]"},
+		{`(?i)hello`, "say HeLLo hello", "hi"},
+		{`\s+`, "  a \t b\n\n", " "},
+		{`literal`, "literal literal", "$$"},
+		// Lazy quantifiers and empty alternatives: leftmost-first priority.
+		{`(a+?)`, "aaa", "<$0>"},
+		{`(a*?)b`, "aab ab b", "<$1>"},
+		{`(()| )\w?`, " _= ", "<$0>"},
+		{`(?:(a|.))*?`, " _c", "<$1>"},
+		{`|a`, "aa", "<$0>"},
+		{`a??`, "aa", "<$0>"},
+		{`^|a[ab]\w|(?m:^)`, " a\naa11=", "<$0>"},
+		{`[ab]|(?:()(?s:.))+`, "ab1\n11_=a=a= ", "<$0>"},
+		{`[ab]|\b\w\w$|.`, "aa_=1 b1a\n\n__ 1b", "<$0>"},
+	}
+
+	readers := map[string]func(string) io.Reader{
+		"whole": func(s string) io.Reader { return strings.NewReader(s) },
+		"one":   func(s string) io.Reader { return iotest.OneByteReader(strings.NewReader(s)) },
+		"half":  func(s string) io.Reader { return iotest.HalfReader(strings.NewReader(s)) },
+		"eof":   func(s string) io.Reader { return iotest.DataErrReader(strings.NewReader(s)) },
+	}
+
+	for _, tt := range tests {
+		re := MustCompile(tt.pattern)
+		want := re.ReplaceAllString(tt.input, tt.repl)
+		if std := regexp.MustCompile(tt.pattern).ReplaceAllString(tt.input, tt.repl); want != std {
+			t.Fatalf("%q: ReplaceAllString(%q, %q) = %q, regexp gives %q", tt.pattern, tt.input, tt.repl, want, std)
+		}
+		wantLit := re.ReplaceAllLiteralString(tt.input, tt.repl)
+		wantFunc := re.ReplaceAllStringFunc(tt.input, strings.ToUpper)
+		tmpl := re.MustCompileTemplate(tt.repl)
+		wantTmpl := re.ReplaceAllStringTemplate(tt.input, tmpl)
+
+		for name, mk := range readers {
+			var out bytes.Buffer
+			n, err := re.ReplaceAllReader(&out, mk(tt.input), []byte(tt.repl))
+			if err != nil || out.String() != want || n != int64(out.Len()) {
+				t.Errorf("%s: %q ReplaceAllReader(%q, %q) = %q, %d, %v; want %q", name, tt.pattern, tt.input, tt.repl, out.String(), n, err, want)
+			}
+
+			out.Reset()
+			if _, err := re.ReplaceAllLiteralReader(&out, mk(tt.input), []byte(tt.repl)); err != nil || out.String() != wantLit {
+				t.Errorf("%s: %q ReplaceAllLiteralReader(%q, %q) = %q, %v; want %q", name, tt.pattern, tt.input, tt.repl, out.String(), err, wantLit)
+			}
+
+			out.Reset()
+			upper := func(b []byte) []byte { return bytes.ToUpper(b) }
+			if _, err := re.ReplaceAllFuncReader(&out, mk(tt.input), upper); err != nil || out.String() != wantFunc {
+				t.Errorf("%s: %q ReplaceAllFuncReader(%q) = %q, %v; want %q", name, tt.pattern, tt.input, out.String(), err, wantFunc)
+			}
+
+			out.Reset()
+			if _, err := re.ReplaceAllTemplateReader(&out, mk(tt.input), tmpl); err != nil || out.String() != wantTmpl {
+				t.Errorf("%s: %q ReplaceAllTemplateReader(%q, %q) = %q, %v; want %q", name, tt.pattern, tt.input, tt.repl, out.String(), err, wantTmpl)
+			}
+		}
+	}
+}
+
+// TestReplaceAllReaderLong replaces matches throughout a stream several times
+// larger than the window, including across read boundaries.
+func TestReplaceAllReaderLong(t *testing.T) {
+	re := MustCompile(`(\d+)-(\d+)`)
+	var b strings.Builder
+	for i := 0; b.Len() < 3<<20; i++ {
+		b.WriteString("call 555-")
+		b.WriteString(strings.Repeat("1", i%7+1))
+		b.WriteString(strings.Repeat(" ", i%13))
+	}
+	input := b.String()
+	want := re.ReplaceAllString(input, "$2/$1")
+
+	var out bytes.Buffer
+	n, err := re.ReplaceAllReader(&out, strings.NewReader(input), []byte("$2/$1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(len(want)) || out.String() != want {
+		t.Errorf("ReplaceAllReader: got %d bytes, want %d (equal: %v)", n, len(want), out.String() == want)
+	}
+}
+
+// TestReplaceAllReaderSparse checks a stream with matches far apart, so the
+// window fills up between them.
+func TestReplaceAllReaderSparse(t *testing.T) {
+	re := MustCompile(`needle`)
+	gap := strings.Repeat("hay ", 600<<10)
+	input := "needle" + gap + "needle" + gap + "nee"
+	want := re.ReplaceAllString(input, "pin")
+
+	var out bytes.Buffer
+	if _, err := re.ReplaceAllReader(&out, strings.NewReader(input), []byte("pin")); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != want {
+		t.Errorf("ReplaceAllReader: got %d bytes, want %d", out.Len(), len(want))
+	}
+}
+
+// TestReplaceAllReaderAssertions compares ReplaceAllReader with ReplaceAll
+// for line and word assertions, over readers that split the input into
+// many chunks, on short inputs and on inputs several times the window.
+func TestReplaceAllReaderAssertions(t *testing.T) {
+	var b strings.Builder
+	for i := 0; b.Len() < 3<<20; i++ {
+		b.WriteString("bar x foo bar\nbarx ")
+		b.WriteString(strings.Repeat("ab ", i%5))
+		b.WriteString("x\n")
+	}
+	long := b.String()
+
+	tests := []struct {
+		pattern string
+		input   string
+	}{
+		{`(?m)^bar`, "bar\nbar x\nfoo bar\nbarx"},
+		{`(?m)^`, "a\nb\n\nc"},
+		{`(?m)$`, "a\nb\n\nc"},
+		{`(?m)x$`, "ax\nbx\nx"},
+		{`(?m)^\w+$`, "one\ntwo words\nthree"},
+		{`$`, "a\nb"},
+		{`x$`, "ax\nx"},
+		{`\b`, "ab cd, e"},
+		{`\bbar\b`, "bar barx xbar bar"},
+		{`\w+\b`, "ab cd  ef"},
+		{`(?m)^bar`, long},
+		{`(?m)^`, long},
+		{`(?m)x$`, long},
+		{`\bfoo\b`, long},
+		{`\b`, long},
+	}
+
+	readers := map[string]func(string) io.Reader{
+		"whole": func(s string) io.Reader { return strings.NewReader(s) },
+		"one":   func(s string) io.Reader { return iotest.OneByteReader(strings.NewReader(s)) },
+		"half":  func(s string) io.Reader { return iotest.HalfReader(strings.NewReader(s)) },
+	}
+
+	for _, tt := range tests {
+		re := MustCompile(tt.pattern)
+		want := re.ReplaceAllString(tt.input, "<$0>")
+		for name, mk := range readers {
+			if name == "one" && len(tt.input) > 1<<10 {
+				continue
+			}
+			var out bytes.Buffer
+			if _, err := re.ReplaceAllReader(&out, mk(tt.input), []byte("<$0>")); err != nil {
+				t.Fatal(err)
+			}
+			if got := out.String(); got != want {
+				if len(tt.input) > 1<<10 {
+					t.Errorf("%s: %q ReplaceAllReader on %d bytes: got %d bytes, want %d", name, tt.pattern, len(tt.input), len(got), len(want))
+					continue
+				}
+				t.Errorf("%s: %q ReplaceAllReader(%q) = %q, want %q", name, tt.pattern, tt.input, got, want)
+			}
+		}
+	}
+}
+
+func TestReplaceAllReaderErrors(t *testing.T) {
+	re := MustCompile(`a`)
+	boom := errors.New("boom")
+
+	_, err := re.ReplaceAllReader(io.Discard, iotest.ErrReader(boom), []byte("b"))
+	if !errors.Is(err, boom) {
+		t.Errorf("read error = %v, want %v", err, boom)
+	}
+
+	input := strings.Repeat("xa", 100<<10)
+	w := &errWriter{n: 1000, err: boom}
+	n, err := re.ReplaceAllReader(w, strings.NewReader(input), []byte("b"))
+	if !errors.Is(err, boom) {
+		t.Errorf("write error = %v, want %v", err, boom)
+	}
+	if n != 1000 {
+		t.Errorf("written = %d, want 1000", n)
+	}
+}
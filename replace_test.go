@@ -1,6 +1,8 @@
 package coregex
 
 import (
+	"reflect"
+	"regexp"
 	"strconv"
 	"testing"
 )
@@ -165,6 +167,7 @@ func TestSplit(t *testing.T) {
 	}{
 		{`,`, "a,b,c", -1, []string{"a", "b", "c"}},
 		{`,`, "a,b,c", 2, []string{"a", "b,c"}},
+		{`,`, "a,b,c", 1, []string{"a,b,c"}},
 		{`,`, "a,b,c", 0, nil},
 		{`,`, "abc", -1, []string{"abc"}},
 		{`\s+`, "a  b   c", -1, []string{"a", "b", "c"}},
@@ -194,6 +197,33 @@ func TestSplit(t *testing.T) {
 	}
 }
 
+// TestSplitLimitVsStdlib compares the limit n of Split with regexp.Split,
+// which returns at most n substrings, the last being the unsplit remainder.
+func TestSplitLimitVsStdlib(t *testing.T) {
+	tests := []struct {
+		pattern string
+		input   string
+	}{
+		{`,`, "a,b,c"},
+		{`,`, ",a,,b,"},
+		{`\s+`, "  a  b  "},
+		{`x*`, "axbxxc"},
+		{`,`, "abc"},
+		{`,`, ""},
+	}
+	for _, tt := range tests {
+		re := MustCompile(tt.pattern)
+		std := regexp.MustCompile(tt.pattern)
+		for n := -1; n <= 5; n++ {
+			got := re.Split(tt.input, n)
+			want := std.Split(tt.input, n)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Split(%q, %q, %d) = %q, want %q", tt.pattern, tt.input, n, got, want)
+			}
+		}
+	}
+}
+
 func TestExpandEdgeCases(t *testing.T) {
 	re := MustCompile(`(\d+)`)
 	match := re.FindSubmatchIndex([]byte("test 123 end"))
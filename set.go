@@ -0,0 +1,124 @@
+package coregex
+
+import (
+	"github.com/coregx/coregex/meta"
+)
+
+// SetMatch is a match reported by Set.FindAll: the byte range of the match
+// and the index of the pattern that produced it.
+type SetMatch = meta.SetMatch
+
+// Set is a compiled collection of regular expressions that are matched
+// against a haystack together, in a single pass.
+//
+// Pattern indices follow the order of the slice given to CompileSet.
+// Each pattern keeps its own flags and capture groups; captures are not
+// reported by Set.
+//
+// A Set is safe to use concurrently from multiple goroutines.
+//
+// Example:
+//
+//	set := coregex.MustCompileSet([]string{`\d+`, `[a-z]+@[a-z]+`, `foo`})
+//	ids := set.Matches([]byte("mail bob@example 42")) // [0 1]
+type Set struct {
+	engine   *meta.SetEngine
+	patterns []string
+}
+
+// CompileSet compiles a set of regular expression patterns.
+//
+// Syntax is Perl-compatible, as for Compile. Returns an error naming the
+// first invalid pattern. An empty set is valid and never matches.
+//
+// Example:
+//
+//	set, err := coregex.CompileSet([]string{`(?i)select\s`, `<script`})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func CompileSet(patterns []string) (*Set, error) {
+	return CompileSetWithConfig(patterns, meta.DefaultConfig())
+}
+
+// CompileSetWithConfig compiles a set of patterns with custom configuration.
+func CompileSetWithConfig(patterns []string, config meta.Config) (*Set, error) {
+	engine, err := meta.CompileSet(patterns, config)
+	if err != nil {
+		return nil, err
+	}
+	return &Set{
+		engine:   engine,
+		patterns: append([]string(nil), patterns...),
+	}, nil
+}
+
+// MustCompileSet is like CompileSet but panics if any pattern fails to compile.
+func MustCompileSet(patterns []string) *Set {
+	set, err := CompileSet(patterns)
+	if err != nil {
+		panic("regexp: CompileSet: " + err.Error())
+	}
+	return set
+}
+
+// Len returns the number of patterns in the set.
+func (s *Set) Len() int {
+	return len(s.patterns)
+}
+
+// Patterns returns the source patterns of the set, in index order.
+func (s *Set) Patterns() []string {
+	return append([]string(nil), s.patterns...)
+}
+
+// IsMatch reports whether any pattern in the set matches b.
+func (s *Set) IsMatch(b []byte) bool {
+	return s.engine.IsMatch(b)
+}
+
+// IsMatchString reports whether any pattern in the set matches s.
+func (s *Set) IsMatchString(str string) bool {
+	return s.engine.IsMatch(stringToBytes(str))
+}
+
+// Matches returns the indices of all patterns that match anywhere in b,
+// in ascending order. Matches of different patterns may overlap.
+// Returns nil if no pattern matches.
+//
+// Example:
+//
+//	set := coregex.MustCompileSet([]string{`foo`, `o+`, `bar`})
+//	set.Matches([]byte("foo")) // [0 1]
+func (s *Set) Matches(b []byte) []int {
+	return s.engine.WhichMatches(b, nil)
+}
+
+// MatchesString is like Matches but for a string.
+func (s *Set) MatchesString(str string) []int {
+	return s.engine.WhichMatches(stringToBytes(str), nil)
+}
+
+// FindAll returns successive non-overlapping matches of the set in b, each
+// tagged with the index of the pattern that produced it. If n >= 0, at most
+// n matches are returned, none for n == 0; if n < 0, all of them. Returns
+// nil if there is no match.
+//
+// At each position the set behaves like the alternation of its patterns:
+// the leftmost match wins, and among matches starting at the same position
+// the pattern with the lowest index wins.
+//
+// Example:
+//
+//	set := coregex.MustCompileSet([]string{`\d+`, `[a-z]+`})
+//	for _, m := range set.FindAll([]byte("ab 12"), -1) {
+//	    fmt.Println(m.Pattern, m.Start, m.End) // 1 0 2, then 0 3 5
+//	}
+func (s *Set) FindAll(b []byte, n int) []SetMatch {
+	return s.engine.FindAll(b, n, nil)
+}
+
+// FindAllString is like FindAll but for a string.
+func (s *Set) FindAllString(str string, n int) []SetMatch {
+	return s.engine.FindAll(stringToBytes(str), n, nil)
+}
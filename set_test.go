@@ -0,0 +1,192 @@
+package coregex
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSetMatches(t *testing.T) {
+	patterns := []string{
+		`(?i)union\s+select`,
+		`<script[^>]*>`,
+		`\.\./`,
+		`\bor\b\s+\d+=\d+`,
+		`etc/passwd$`,
+		`^POST `,
+		`%00`,
+	}
+	inputs := []string{
+		"GET /index.html",
+		"GET /?id=1 UNION  SELECT pass",
+		"POST /form <script src=x>",
+		"GET /../../etc/passwd",
+		"q=1 or 1=1",
+		"file%00.jpg",
+		"",
+	}
+
+	set := MustCompileSet(patterns)
+	if set.Len() != len(patterns) {
+		t.Fatalf("Len() = %d, want %d", set.Len(), len(patterns))
+	}
+	for _, input := range inputs {
+		var want []int
+		for i, p := range patterns {
+			if regexp.MustCompile(p).MatchString(input) {
+				want = append(want, i)
+			}
+		}
+		if got := set.MatchesString(input); !reflect.DeepEqual(got, want) {
+			t.Errorf("MatchesString(%q) = %v, want %v", input, got, want)
+		}
+		if got := set.Matches([]byte(input)); !reflect.DeepEqual(got, want) {
+			t.Errorf("Matches(%q) = %v, want %v", input, got, want)
+		}
+		if got := set.IsMatchString(input); got != (want != nil) {
+			t.Errorf("IsMatchString(%q) = %v, want %v", input, got, want != nil)
+		}
+	}
+}
+
+// TestSetMatchesBeforeDeadByte checks matches followed by a byte that kills
+// the lazy DFA, in sets of one pattern and of several.
+func TestSetMatchesBeforeDeadByte(t *testing.T) {
+	tests := []struct {
+		patterns []string
+		input    string
+	}{
+		{[]string{`(a|b)*c`}, "cc "},
+		{[]string{`(a|b)*c`}, "c "},
+		{[]string{`(a|b)*c`}, "acx"},
+		{[]string{`a+`}, "a\xa9x"},
+		{[]string{`(a|b)*c`, `a+`}, "acx"},
+	}
+	for _, tt := range tests {
+		var want []int
+		for i, p := range tt.patterns {
+			if regexp.MustCompile(p).MatchString(tt.input) {
+				want = append(want, i)
+			}
+		}
+		set := MustCompileSet(tt.patterns)
+		if got := set.MatchesString(tt.input); !reflect.DeepEqual(got, want) {
+			t.Errorf("%q MatchesString(%q) = %v, want %v", tt.patterns, tt.input, got, want)
+		}
+		if got := set.Matches([]byte(tt.input)); !reflect.DeepEqual(got, want) {
+			t.Errorf("%q Matches(%q) = %v, want %v", tt.patterns, tt.input, got, want)
+		}
+	}
+}
+
+// TestSetMatchesAssertionChain checks patterns whose assertions follow one
+// another, such as $^, which the set DFA cannot evaluate.
+func TestSetMatchesAssertionChain(t *testing.T) {
+	set := MustCompileSet([]string{"b", "$^", "(?:bc)*"})
+	if got, want := set.MatchesString(""), []int{1, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("MatchesString(\"\") = %v, want %v", got, want)
+	}
+	if got, want := set.MatchesString("a\nb"), []int{0, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("MatchesString(\"a\\nb\") = %v, want %v", got, want)
+	}
+
+	set = MustCompileSet([]string{`x`, `(\b|ab)^[ab]`, `[ab][^a]a[ab]`})
+	if got, want := set.MatchesString("a"), []int{1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("MatchesString(\"a\") = %v, want %v", got, want)
+	}
+	if !set.IsMatchString("a") {
+		t.Errorf("IsMatchString(\"a\") = false, want true")
+	}
+}
+
+// TestSetFindAllMatchesAlternation checks FindAll against stdlib on the
+// equivalent alternation.
+func TestSetFindAllMatchesAlternation(t *testing.T) {
+	patterns := []string{`\d+`, `[a-z]+`, `\s+`, `a`}
+	input := "abc 123  x9 a"
+
+	set := MustCompileSet(patterns)
+	got := set.FindAllString(input, -1)
+
+	wrapped := make([]string, len(patterns))
+	for i, p := range patterns {
+		wrapped[i] = "(" + p + ")"
+	}
+	std := regexp.MustCompile(strings.Join(wrapped, "|"))
+	want := std.FindAllStringSubmatchIndex(input, -1)
+
+	if len(got) != len(want) {
+		t.Fatalf("FindAllString = %v, want %d matches", got, len(want))
+	}
+	for i, m := range got {
+		w := want[i]
+		if m.Start != w[0] || m.End != w[1] {
+			t.Errorf("match %d = [%d,%d], want [%d,%d]", i, m.Start, m.End, w[0], w[1])
+		}
+		if w[2+2*m.Pattern] < 0 {
+			t.Errorf("match %d reported pattern %d, which did not participate", i, m.Pattern)
+		}
+	}
+}
+
+func TestSetFindAllLimit(t *testing.T) {
+	set := MustCompileSet([]string{`x`, `y`})
+	for _, tt := range []struct {
+		n    int
+		want int
+	}{{-1, 4}, {0, 0}, {1, 1}, {3, 3}, {5, 4}} {
+		got := set.FindAllString("xyxy", tt.n)
+		if len(got) != tt.want {
+			t.Errorf("FindAllString(%q, %d) = %v, want %d matches", "xyxy", tt.n, got, tt.want)
+		}
+		if tt.want == 0 && got != nil {
+			t.Errorf("FindAllString(%q, %d) = %#v, want nil", "xyxy", tt.n, got)
+		}
+	}
+}
+
+func TestCompileSetError(t *testing.T) {
+	if _, err := CompileSet([]string{`a`, `a(`}); err == nil {
+		t.Error("CompileSet with invalid pattern succeeded")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("MustCompileSet did not panic")
+		}
+	}()
+	MustCompileSet([]string{`[`})
+}
+
+func TestSetPatternsCopy(t *testing.T) {
+	src := []string{"a", "b"}
+	set := MustCompileSet(src)
+	src[0] = "z"
+	got := set.Patterns()
+	got[1] = "z"
+	if want := []string{"a", "b"}; !reflect.DeepEqual(set.Patterns(), want) {
+		t.Errorf("Patterns() = %v, want %v", set.Patterns(), want)
+	}
+}
+
+func TestSetConcurrent(t *testing.T) {
+	set := MustCompileSet([]string{`foo\d`, `bar`, `[xyz]{3}`})
+	want := []int{0, 2}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				if got := set.MatchesString("..foo7..xyx.."); !reflect.DeepEqual(got, want) {
+					t.Errorf("MatchesString = %v, want %v", got, want)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
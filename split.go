@@ -0,0 +1,195 @@
+package coregex
+
+import "iter"
+
+// SplitSeq returns an iterator over the substrings of s separated by matches
+// of the expression. It yields the same substrings as Split(s, -1), one at a
+// time, without building a slice: matches are found as the loop advances, so
+// breaking out early skips the rest of the search.
+//
+// Example:
+//
+//	re := coregex.MustCompile(`\s*,\s*`)
+//	for field := range re.SplitSeq("a , b,c") {
+//	    fmt.Println(field)
+//	}
+//	// Output:
+//	// a
+//	// b
+//	// c
+func (r *Regex) SplitSeq(s string) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		for f := range r.splitIndex(stringToBytes(s)) {
+			if !yield(s[f[0]:f[1]]) {
+				return
+			}
+		}
+	}
+}
+
+// SplitSeqBytes is like SplitSeq for a byte slice. Each yielded []byte is a
+// sub-slice of b (no copy, no allocation).
+func (r *Regex) SplitSeqBytes(b []byte) iter.Seq[[]byte] {
+	return func(yield func([]byte) bool) {
+		for f := range r.splitIndex(b) {
+			if !yield(b[f[0]:f[1]:f[1]]) {
+				return
+			}
+		}
+	}
+}
+
+// splitIndex yields the spans of the fields of Split(b, -1).
+//
+// The matches are found one at a time with the rules of
+// FindAllIndicesStreaming, the search behind Split and SplitAppend; in
+// particular, after an empty match the next search starts one byte later.
+func (r *Regex) splitIndex(b []byte) iter.Seq[[2]int] {
+	return func(yield func([2]int) bool) {
+		searcher := r.engine.NewSearcher(len(b))
+		defer searcher.Close()
+		lastEnd := 0
+		lastMatchEnd := -1
+		for pos := 0; pos <= len(b); {
+			start, end, found := searcher.FindIndicesAt(b, pos)
+			if !found {
+				break
+			}
+			// Skip empty matches where a non-empty match just ended.
+			//nolint:gocritic // badCond: intentional - checking empty match at lastMatchEnd
+			if start == end && start == lastMatchEnd {
+				pos++
+				continue
+			}
+			if start != end {
+				lastMatchEnd = end
+			}
+			switch {
+			case start == end:
+				pos = end + 1
+			case end > pos:
+				pos = end
+			default:
+				pos++
+			}
+
+			// Same rules as Split: an empty match at the very start or the very
+			// end does not delimit an empty field.
+			if lastEnd == 0 && end == 0 {
+				continue
+			}
+			if start == len(b) && end == len(b) {
+				break
+			}
+			if !yield([2]int{lastEnd, start}) {
+				return
+			}
+			lastEnd = end
+		}
+		yield([2]int{lastEnd, len(b)})
+	}
+}
+
+// SplitAppend appends the spans of the substrings of b separated by matches
+// of the expression to dst and returns the extended slice. The spans are
+// those of the substrings returned by Split(string(b), -1), as [start, end)
+// byte offsets into b.
+//
+// Zero-allocation when dst has sufficient capacity: the matches are found by
+// the streaming FindAll into the spare capacity of dst and turned into field
+// spans in place. A buffer kept across calls reaches its steady size after
+// the first few inputs.
+//
+// Example:
+//
+//	re := coregex.MustCompile(`[ \t]+`)
+//	fields := make([][2]int, 0, 16)
+//	for _, line := range lines {
+//	    fields = re.SplitAppend(fields[:0], line)
+//	    // line[fields[i][0]:fields[i][1]] is field i
+//	}
+func (r *Regex) SplitAppend(dst [][2]int, b []byte) [][2]int {
+	matches := r.engine.FindAllIndicesStreaming(b, -1, dst[len(dst):])
+
+	// Field k ends where match k starts, so fields overwrite matches that
+	// have already been read.
+	k := 0
+	lastEnd := 0
+	for _, m := range matches {
+		if lastEnd == 0 && m[1] == 0 {
+			continue
+		}
+		if m[0] == len(b) && m[1] == len(b) {
+			break
+		}
+		matches[k] = [2]int{lastEnd, m[0]}
+		k++
+		lastEnd = m[1]
+	}
+	// When matches shares the backing array of dst, this append copies the
+	// fields onto themselves.
+	dst = append(dst, matches[:k]...)
+	return append(dst, [2]int{lastEnd, len(b)})
+}
+
+// SplitAppendString is the string version of SplitAppend.
+func (r *Regex) SplitAppendString(dst [][2]int, s string) [][2]int {
+	return r.SplitAppend(dst, stringToBytes(s))
+}
+
+// SplitKeep is like Split, but keeps the separators: between each pair of
+// substrings it returns the text of the separator match followed by the text
+// of each of its capture groups, as in FindStringSubmatch. So the result is
+//
+//	field, sep, group1, ..., groupN, field, sep, group1, ..., groupN, field
+//
+// with N = NumSubexp(), and field i is element i*(N+2). Groups that did not
+// take part in the match are empty strings.
+//
+// The count n limits the number of substrings (fields) as in Split: with
+// n > 0 the last field is the unsplit remainder, n == 0 returns nil.
+//
+// Example:
+//
+//	re := coregex.MustCompile(`\s*([-+])\s*`)
+//	re.SplitKeep("1 + 2-3", -1)
+//	// ["1", " + ", "+", "2", "-", "-", "3"]
+func (r *Regex) SplitKeep(s string, n int) []string {
+	if n == 0 {
+		return nil
+	}
+	if n == 1 {
+		return []string{s}
+	}
+	b := stringToBytes(s)
+	buf := make([]int, r.engine.NumCaptures()*2)
+	slots := func() []int { return buf }
+
+	// The matches are found as the loop advances, so the search stops once
+	// n-1 fields are split off.
+	var result []string
+	fields := 0
+	lastEnd := 0
+	for m := range r.allSubmatchSlots(b, -1, slots) {
+		if lastEnd == 0 && m[1] == 0 {
+			continue
+		}
+		if m[0] == len(s) && m[1] == len(s) {
+			break
+		}
+		result = append(result, s[lastEnd:m[0]])
+		fields++
+		for g := 0; g < len(m); g += 2 {
+			if m[g] < 0 {
+				result = append(result, "")
+			} else {
+				result = append(result, s[m[g]:m[g+1]])
+			}
+		}
+		lastEnd = m[1]
+		if fields == n-1 {
+			break
+		}
+	}
+	return append(result, s[lastEnd:])
+}
@@ -0,0 +1,156 @@
+package coregex
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var splitSeqTests = []struct {
+	pattern string
+	input   string
+}{
+	{`,`, "a,b,c"},
+	{`,`, "a,b,c,"},
+	{`,`, ",a,,b"},
+	{`,`, ""},
+	{`,`, "no separator"},
+	{`\s+`, "  lead and trail  "},
+	{`x*`, "abc"},
+	{``, "abc"},
+	{``, ""},
+	{`a*`, "baaac"},
+	{`(-)|(\+)`, "1-2+3"},
+	{`\s*([-+])\s*`, "1 + 2-3"},
+	{`é`, "caféébé"},
+	{`^`, "abc"},
+	{`$`, "abc"},
+	{`\b`, "ab cd"},
+	{`\B`, "ab  cd"},
+	{`\s*`, "a  b c"},
+	{`(?m)^`, "a\nb\n"},
+	{`(?m)$`, "a\nb\n"},
+}
+
+func TestSplitSeq(t *testing.T) {
+	for _, tt := range splitSeqTests {
+		re := MustCompile(tt.pattern)
+		want := re.Split(tt.input, -1)
+
+		got := slices.Collect(re.SplitSeq(tt.input))
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%q SplitSeq(%q) = %q, want %q", tt.pattern, tt.input, got, want)
+		}
+
+		var gotBytes []string
+		for f := range re.SplitSeqBytes([]byte(tt.input)) {
+			gotBytes = append(gotBytes, string(f))
+		}
+		if !reflect.DeepEqual(gotBytes, want) {
+			t.Errorf("%q SplitSeqBytes(%q) = %q, want %q", tt.pattern, tt.input, gotBytes, want)
+		}
+
+		// Breaking out early must not panic or yield more.
+		for range re.SplitSeq(tt.input) {
+			break
+		}
+	}
+}
+
+func TestSplitAppend(t *testing.T) {
+	for _, tt := range splitSeqTests {
+		re := MustCompile(tt.pattern)
+		want := re.Split(tt.input, -1)
+
+		// Existing contents of dst are kept.
+		prefix := [][2]int{{-1, -1}}
+		for _, dst := range [][][2]int{nil, make([][2]int, 0, 64), prefix} {
+			spans := re.SplitAppendString(slices.Clone(dst), tt.input)
+			if !slices.Equal(spans[:len(dst)], dst) {
+				t.Errorf("%q SplitAppend(%v, %q) changed dst: %v", tt.pattern, dst, tt.input, spans)
+				continue
+			}
+			var got []string
+			for _, f := range spans[len(dst):] {
+				got = append(got, tt.input[f[0]:f[1]])
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("%q SplitAppend(%q) = %v (%q), want %q", tt.pattern, tt.input, spans, got, want)
+			}
+		}
+	}
+}
+
+func TestSplitAppendAllocs(t *testing.T) {
+	re := MustCompile(`[ \t]+`)
+	input := []byte("one two\tthree   four five")
+	fields := make([][2]int, 0, 16)
+	allocs := testing.AllocsPerRun(100, func() {
+		fields = re.SplitAppend(fields[:0], input)
+	})
+	if allocs != 0 {
+		t.Errorf("SplitAppend allocs = %v, want 0", allocs)
+	}
+	if len(fields) != 5 {
+		t.Errorf("SplitAppend = %v, want 5 fields", fields)
+	}
+}
+
+func TestSplitKeep(t *testing.T) {
+	tests := []struct {
+		pattern string
+		input   string
+		n       int
+		want    []string
+	}{
+		{`,`, "a,b,c", -1, []string{"a", ",", "b", ",", "c"}},
+		{`,`, "a,b,c", 2, []string{"a", ",", "b,c"}},
+		{`,`, "a,b,c", 1, []string{"a,b,c"}},
+		{`,`, "a,b,c", 0, nil},
+		{`,`, "abc", -1, []string{"abc"}},
+		{`\s*([-+])\s*`, "1 + 2-3", -1, []string{"1", " + ", "+", "2", "-", "-", "3"}},
+		{`(-)|(\+)`, "1-2+3", -1, []string{"1", "-", "-", "", "2", "+", "", "+", "3"}},
+		{`(?P<op>[*/])`, "6*7/2", -1, []string{"6", "*", "*", "7", "/", "/", "2"}},
+		{`\b`, "ab cd", -1, []string{"ab", "", " ", "", "cd"}},
+		{`(\s*)`, "a  b ", -1, []string{"a", "  ", "  ", "b", " ", " ", ""}},
+	}
+	for _, tt := range tests {
+		re := MustCompile(tt.pattern)
+		if got := re.SplitKeep(tt.input, tt.n); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q SplitKeep(%q, %d) = %q, want %q", tt.pattern, tt.input, tt.n, got, tt.want)
+		}
+	}
+
+	// Without the separators, SplitKeep is Split.
+	for _, tt := range splitSeqTests {
+		re := MustCompile(tt.pattern)
+		for _, n := range []int{-1, 1, 2, 3} {
+			keep := re.SplitKeep(tt.input, n)
+			var got []string
+			for i := 0; i < len(keep); i += re.NumSubexp() + 2 {
+				got = append(got, keep[i])
+			}
+			if want := re.Split(tt.input, n); !reflect.DeepEqual(got, want) {
+				t.Errorf("%q SplitKeep(%q, %d) fields = %q, want %q", tt.pattern, tt.input, n, got, want)
+			}
+		}
+	}
+}
+
+func TestSplitKeepStopsAtLimit(t *testing.T) {
+	config := DefaultConfig()
+	config.EnableStats = true
+	re, err := CompileWithConfig(`(,)`, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := strings.Repeat("a,", 1000)
+	got := re.SplitKeep(input, 2)
+	if want := []string{"a", ",", ",", input[2:]}; !reflect.DeepEqual(got, want) {
+		t.Errorf("SplitKeep(n=2) = %q, want %q", got, want)
+	}
+	if searches := re.Stats().Searches; searches != 1 {
+		t.Errorf("SplitKeep(n=2) ran %d searches, want 1", searches)
+	}
+}
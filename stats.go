@@ -0,0 +1,43 @@
+package coregex
+
+import (
+	"expvar"
+
+	"github.com/coregx/coregex/meta"
+)
+
+// Stats are the execution statistics of a Regex compiled with
+// meta.Config.EnableStats: searches per engine, prefilter hits and misses,
+// lazy DFA cache clears, NFA fallbacks and bytes scanned. See Regex.Stats.
+type Stats = meta.Stats
+
+// Stats returns the execution statistics of the regex. The counters are
+// maintained atomically, so Stats may be called while other goroutines
+// search with the regex.
+//
+// Statistics are off by default and cost nothing then; all counters stay
+// zero unless the regex was compiled with EnableStats:
+//
+//	config := coregex.DefaultConfig()
+//	config.EnableStats = true
+//	re, err := coregex.CompileWithConfig(`\w+@\w+\.com`, config)
+//	...
+//	fmt.Println(re.Stats().PrefilterHits)
+func (r *Regex) Stats() Stats {
+	return r.engine.Stats()
+}
+
+// ResetStats resets the execution statistics of the regex to zero.
+func (r *Regex) ResetStats() {
+	r.engine.ResetStats()
+}
+
+// PublishStats exports the execution statistics of the regex as the expvar
+// variable name, so that they appear, as a JSON object, on the
+// /debug/vars page of services that serve expvar. The value is read on
+// every request to the page.
+//
+// Like expvar.Publish, PublishStats panics if name is already in use.
+func (r *Regex) PublishStats(name string) {
+	expvar.Publish(name, expvar.Func(func() any { return r.Stats() }))
+}
@@ -0,0 +1,65 @@
+package coregex
+
+import (
+	"encoding/json"
+	"expvar"
+	"testing"
+)
+
+func TestRegexStats(t *testing.T) {
+	config := DefaultConfig()
+	config.EnableStats = true
+	re, err := CompileWithConfig(`\d+`, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	re.FindAllString("a1 b22 c333", -1)
+	stats := re.Stats()
+	if stats.BytesScanned != uint64(len("a1 b22 c333")) {
+		t.Errorf("BytesScanned = %d, want %d", stats.BytesScanned, len("a1 b22 c333"))
+	}
+	if stats.Searches != 1 {
+		t.Errorf("Searches = %d, want 1", stats.Searches)
+	}
+	re.ResetStats()
+	if got := re.Stats(); got != (Stats{}) {
+		t.Errorf("Stats() after ResetStats = %+v, want zero", got)
+	}
+
+	// Statistics are off by default.
+	re = MustCompile(`\d+`)
+	re.FindAllString("a1 b22 c333", -1)
+	if got := re.Stats(); got != (Stats{}) {
+		t.Errorf("Stats() without EnableStats = %+v, want zero", got)
+	}
+}
+
+func TestRegexPublishStats(t *testing.T) {
+	config := DefaultConfig()
+	config.EnableStats = true
+	re, err := CompileWithConfig(`hello`, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	re.PublishStats("coregex_test_hello")
+	re.MatchString("say hello")
+
+	v := expvar.Get("coregex_test_hello")
+	if v == nil {
+		t.Fatal("PublishStats did not publish the variable")
+	}
+	var got Stats
+	if err := json.Unmarshal([]byte(v.String()), &got); err != nil {
+		t.Fatalf("expvar value %s: %v", v, err)
+	}
+	if got != re.Stats() || got.BytesScanned != uint64(len("say hello")) {
+		t.Errorf("expvar value %+v, want %+v", got, re.Stats())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("publishing the same name twice did not panic")
+		}
+	}()
+	re.PublishStats("coregex_test_hello")
+}
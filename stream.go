@@ -0,0 +1,48 @@
+package coregex
+
+import (
+	"github.com/coregx/coregex/meta"
+)
+
+// Stream is a push-mode matcher: data is written to it in pieces (for example
+// reassembled network packets) and a callback fires with the absolute stream
+// offset of every match end. See Regex.NewStream.
+type Stream = meta.Stream
+
+// ErrStreamClosed is returned by Stream.Write after Stream.Close.
+var ErrStreamClosed = meta.ErrStreamClosed
+
+// ErrStreamUnsupported is returned by NewStream when the regex was compiled
+// without the lazy DFA (Config.EnableDFA is false) or cannot run on it
+// alone: patterns with look-around or Unicode word boundaries.
+var ErrStreamUnsupported = meta.ErrStreamUnsupported
+
+// NewStream returns a Stream that matches the regex against data written to
+// it and calls onMatch with the absolute byte offset at which each match
+// ends.
+//
+// The DFA state and its look-behind context are carried across writes, so
+// matches spanning chunk boundaries are found and the result does not depend
+// on how the data is split. Memory use does not grow with the stream length:
+// if the DFA cache is too small for the pattern, Write fails with
+// lazy.ErrCacheFull instead (see meta.Stream.Write).
+// Only match ends are reported, with earliest-match semantics: every position
+// where some match ends is reported once, in increasing order. Close must be
+// called at the end of the data to report matches that need end of text
+// ($, \z).
+//
+// Example:
+//
+//	re := coregex.MustCompile(`GET /admin`)
+//	s, err := re.NewStream(func(end int64) {
+//	    fmt.Println("match ending at", end)
+//	})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	s.Write([]byte("GET /ad"))
+//	s.Write([]byte("min HTTP/1.1")) // match ending at 10
+//	s.Close()
+func (r *Regex) NewStream(onMatch func(end int64)) (*Stream, error) {
+	return r.engine.NewStream(onMatch)
+}
@@ -0,0 +1,30 @@
+package coregex
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestRegexNewStream(t *testing.T) {
+	re := MustCompile(`GET /admin`)
+	var ends []int64
+	s, err := re.NewStream(func(end int64) { ends = append(ends, end) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pkt := range []string{"POST / GET /ad", "min HTTP/1.1\r\nGET", " /admin"} {
+		if _, err := s.Write([]byte(pkt)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if want := []int64{17, 38}; !reflect.DeepEqual(ends, want) {
+		t.Errorf("ends = %v, want %v", ends, want)
+	}
+	if _, err := s.Write([]byte("x")); !errors.Is(err, ErrStreamClosed) {
+		t.Errorf("Write after Close: err = %v, want ErrStreamClosed", err)
+	}
+}
@@ -0,0 +1,262 @@
+package coregex
+
+import (
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/coregx/coregex/internal/expand"
+)
+
+// TemplateError reports a replacement template that refers to a capture
+// group the regex does not have.
+type TemplateError struct {
+	Template string // the template
+	Offset   int    // byte offset of the reference in Template
+	Ref      string // the reference as written, e.g. "$name" or "${7}"
+	Err      error  // ErrUnknownGroup
+}
+
+// Error implements the error interface.
+func (e *TemplateError) Error() string {
+	return fmt.Sprintf("regexp: template %q: %s at offset %d: %v", e.Template, e.Ref, e.Offset, e.Err)
+}
+
+// Unwrap returns the cause.
+func (e *TemplateError) Unwrap() error {
+	return e.Err
+}
+
+// Template is a replacement template parsed once by Regex.CompileTemplate
+// and expanded for every match by ReplaceAllTemplate or Expand.
+//
+// A Template is bound to the capture groups of the regex that compiled it.
+// It is safe for concurrent use.
+type Template struct {
+	src  string
+	ops  []templateOp
+	lits []byte // literal text of all opLiteral ops
+}
+
+// templateOpKind is the kind of a templateOp.
+type templateOpKind uint8
+
+const (
+	opLiteral  templateOpKind = iota // append lits[start:end]
+	opGroup                          // append the text of group
+	opCaseSpan                       // set the span case mode (\U, \L, \E)
+	opCaseNext                       // set the one-shot case mode (\u, \l)
+)
+
+// caseMode is a case conversion applied while expanding.
+type caseMode uint8
+
+const (
+	caseNone caseMode = iota
+	caseUpper
+	caseLower
+)
+
+// templateOp is one step of template expansion.
+type templateOp struct {
+	kind       templateOpKind
+	mode       caseMode
+	group      int
+	start, end int
+}
+
+// CompileTemplate parses a replacement template for matches of r.
+//
+// Group references are written as in Expand: $1, ${1}, $name and ${name},
+// with $name taking the longest possible name ($1x is ${1x}) and $$ for a
+// literal $. Unlike Expand, a reference to a group that r does not have is
+// an error (a *TemplateError wrapping ErrUnknownGroup) instead of expanding
+// to nothing. A $ not followed by a valid reference is a literal $.
+//
+// The template may also contain the case modifiers of sed and Perl, which
+// apply to literal text and group text alike:
+//   - \U converts what follows to upper case, up to \L or \E
+//   - \L converts what follows to lower case, up to \U or \E
+//   - \E ends \U or \L
+//   - \u and \l convert only the next character to upper or lower case,
+//     so \L\u$1 capitalizes the group
+//
+// \\ is a literal backslash; any other backslash is kept as is. Case is
+// converted rune by rune (unicode.ToUpper and unicode.ToLower); bytes that
+// are not valid UTF-8 are copied unchanged.
+//
+// Example:
+//
+//	re := coregex.MustCompile(`(?P<first>\w+) (?P<last>\w+)`)
+//	tmpl, err := re.CompileTemplate(`\U${last}\E, \u$first`)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	re.ReplaceAllStringTemplate("ada lovelace", tmpl) // "LOVELACE, Ada"
+func (r *Regex) CompileTemplate(template string) (*Template, error) {
+	t := &Template{src: template}
+	names := r.SubexpNames()
+	numGroups := r.engine.NumCaptures()
+
+	litStart := len(t.lits)
+	flushLit := func() {
+		if len(t.lits) > litStart {
+			t.ops = append(t.ops, templateOp{kind: opLiteral, start: litStart, end: len(t.lits)})
+		}
+		litStart = len(t.lits)
+	}
+
+	for i := 0; i < len(template); {
+		c := template[i]
+		switch {
+		case c == '\\' && i+1 < len(template):
+			op, ok := templateCaseOp(template[i+1])
+			switch {
+			case ok:
+				flushLit()
+				t.ops = append(t.ops, op)
+			case template[i+1] == '\\':
+				t.lits = append(t.lits, '\\')
+			default:
+				t.lits = append(t.lits, c, template[i+1])
+			}
+			i += 2
+
+		case c == '$' && i+1 < len(template) && template[i+1] == '$':
+			t.lits = append(t.lits, '$')
+			i += 2
+
+		case c == '$':
+			name, num, rest, ok := expand.Ref(template[i:])
+			if !ok {
+				t.lits = append(t.lits, '$')
+				i++
+				continue
+			}
+			group := num
+			if num < 0 {
+				group = -1
+				for j, n := range names {
+					if n == name {
+						group = j
+						break
+					}
+				}
+			}
+			ref := template[i : len(template)-len(rest)]
+			if group < 0 || group >= numGroups {
+				return nil, &TemplateError{Template: template, Offset: i, Ref: ref, Err: ErrUnknownGroup}
+			}
+			flushLit()
+			t.ops = append(t.ops, templateOp{kind: opGroup, group: group})
+			i += len(ref)
+
+		default:
+			t.lits = append(t.lits, c)
+			i++
+		}
+	}
+	flushLit()
+	return t, nil
+}
+
+// MustCompileTemplate is like CompileTemplate but panics if the template
+// refers to a group r does not have.
+func (r *Regex) MustCompileTemplate(template string) *Template {
+	t, err := r.CompileTemplate(template)
+	if err != nil {
+		panic(err.Error())
+	}
+	return t
+}
+
+// templateCaseOp returns the op for the case modifier \c.
+func templateCaseOp(c byte) (templateOp, bool) {
+	switch c {
+	case 'U':
+		return templateOp{kind: opCaseSpan, mode: caseUpper}, true
+	case 'L':
+		return templateOp{kind: opCaseSpan, mode: caseLower}, true
+	case 'E':
+		return templateOp{kind: opCaseSpan, mode: caseNone}, true
+	case 'u':
+		return templateOp{kind: opCaseNext, mode: caseUpper}, true
+	case 'l':
+		return templateOp{kind: opCaseNext, mode: caseLower}, true
+	}
+	return templateOp{}, false
+}
+
+// String returns the source text of the template.
+func (t *Template) String() string {
+	return t.src
+}
+
+// Expand appends the template to dst, with group references replaced by the
+// text of the groups in match (as returned by FindSubmatchIndex) drawn from
+// src, and returns the result. Groups that did not take part in the match
+// expand to nothing.
+func (t *Template) Expand(dst []byte, src []byte, match []int) []byte {
+	span, next := caseNone, caseNone
+	for _, op := range t.ops {
+		switch op.kind {
+		case opLiteral:
+			dst, next = appendCased(dst, t.lits[op.start:op.end], span, next)
+		case opGroup:
+			if 2*op.group+1 < len(match) && match[2*op.group] >= 0 {
+				dst, next = appendCased(dst, src[match[2*op.group]:match[2*op.group+1]], span, next)
+			}
+		case opCaseSpan:
+			span = op.mode
+		case opCaseNext:
+			next = op.mode
+		}
+	}
+	return dst
+}
+
+// appendCased appends text to dst converted to the span case mode, with the
+// first rune converted to the one-shot mode next if it is set. It returns the
+// remaining one-shot mode, which is cleared once a rune has been written.
+func appendCased(dst, text []byte, span, next caseMode) ([]byte, caseMode) {
+	if span == caseNone && next == caseNone {
+		return append(dst, text...), caseNone
+	}
+	for len(text) > 0 {
+		r, size := utf8.DecodeRune(text)
+		mode := span
+		if next != caseNone {
+			mode, next = next, caseNone
+		}
+		if (r == utf8.RuneError && size <= 1) || mode == caseNone {
+			dst = append(dst, text[:size]...)
+		} else if mode == caseUpper {
+			dst = utf8.AppendRune(dst, unicode.ToUpper(r))
+		} else {
+			dst = utf8.AppendRune(dst, unicode.ToLower(r))
+		}
+		text = text[size:]
+	}
+	return dst, next
+}
+
+// ReplaceAllTemplate returns a copy of src in which every match of r is
+// replaced by the expansion of t. The template is not re-parsed per match,
+// and no memory is allocated per match besides the growth of the result.
+//
+// Example:
+//
+//	re := coregex.MustCompile(`(\w+)=(\w+)`)
+//	tmpl := re.MustCompileTemplate(`\U$1\E: $2`)
+//	re.ReplaceAllTemplate([]byte("host=db port=5432"), tmpl)
+//	// "HOST: db PORT: 5432"
+func (r *Regex) ReplaceAllTemplate(src []byte, t *Template) []byte {
+	return r.replaceAll(src, func(dst []byte, match []int) []byte {
+		return t.Expand(dst, src, match)
+	})
+}
+
+// ReplaceAllStringTemplate is like ReplaceAllTemplate for strings.
+func (r *Regex) ReplaceAllStringTemplate(src string, t *Template) string {
+	return string(r.ReplaceAllTemplate(stringToBytes(src), t))
+}
@@ -0,0 +1,128 @@
+package coregex
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestReplaceAllTemplate(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		template string
+		input    string
+		want     string
+	}{
+		{`(\w+)@(\w+)\.(\w+)`, `$1 at $2 dot $3`, "user@example.com", "user at example dot com"},
+		{`(?P<first>\w+) (?P<last>\w+)`, `${last}, ${first}`, "ada lovelace", "lovelace, ada"},
+		{`(?P<first>\w+) (?P<last>\w+)`, `\U${last}\E, \u$first`, "ada lovelace", "LOVELACE, Ada"},
+		{`(\w+)`, `\u$1`, "hello world", "Hello World"},
+		{`(\w+)`, `\L\u$1`, "hELLO wORLD", "Hello World"},
+		{`(\w+)`, `\U$1\E!`, "ab cd", "AB! CD!"},
+		{`(\w+)`, `\Ux\Ly\E$1`, "ab", "Xyab"},
+		{`(\w+)`, `\l$1`, "ABC", "aBC"},
+		{`(\S+)`, `\U$1`, "grüne", "GRÜNE"},
+		{`(\d+)`, `$$$1`, "cost 42", "cost $42"},
+		{`(\d+)`, `\\$1\n`, "7", `\7\n`},
+		{`(a)|(b)`, `[$1|$2]`, "ab", "[a|][|b]"},
+		{`(\d)`, `${1}0`, "5", "50"},
+		{`(x)`, `$`, "x", "$"},
+		{`(x)`, `${1`, "x", "${1"},
+		{`x*`, `-`, "abc", "-a-b-c-"},
+		{`(\w)`, `\u`, "ab", ""},
+	}
+
+	for _, tt := range tests {
+		re := MustCompile(tt.pattern)
+		tmpl, err := re.CompileTemplate(tt.template)
+		if err != nil {
+			t.Fatalf("CompileTemplate(%q): %v", tt.template, err)
+		}
+		if got := re.ReplaceAllStringTemplate(tt.input, tmpl); got != tt.want {
+			t.Errorf("%q with %q on %q = %q, want %q", tt.pattern, tt.template, tt.input, got, tt.want)
+		}
+		if tmpl.String() != tt.template {
+			t.Errorf("String() = %q, want %q", tmpl.String(), tt.template)
+		}
+	}
+}
+
+// TestTemplateMatchesStdlibExpand checks templates without case modifiers
+// against regexp.Regexp.ReplaceAllString.
+func TestTemplateMatchesStdlibExpand(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		template string
+		input    string
+	}{
+		{`(?P<k>\w+)=(?P<v>\w*)`, `$v=$k;`, "a=1 b= c=3"},
+		{`(\w)(\w)?`, `<$2$1>`, "abc"},
+		{`(\d+)`, `${1}$$`, "1 22 333"},
+		{`b*`, `[$0]`, "abba c"},
+		{`^(\w)`, `$1$1`, "xy xy"},
+		{`(?m)^(\w)`, `$1$1`, "xy\nxy"},
+	}
+	for _, tt := range tests {
+		want := regexp.MustCompile(tt.pattern).ReplaceAllString(tt.input, tt.template)
+		re := MustCompile(tt.pattern)
+		got := string(re.ReplaceAllTemplate([]byte(tt.input), re.MustCompileTemplate(tt.template)))
+		if got != want {
+			t.Errorf("%q with %q on %q = %q, want %q", tt.pattern, tt.template, tt.input, got, want)
+		}
+	}
+}
+
+func TestCompileTemplateErrors(t *testing.T) {
+	re := MustCompile(`(?P<word>\w+) (\d+)`)
+	tests := []struct {
+		template string
+		ref      string
+		offset   int
+	}{
+		{`$3`, "$3", 0},
+		{`x ${name}`, "${name}", 2},
+		{`$1x`, "$1x", 0},
+		{`$01`, "$01", 0},
+		{`$word $wordy`, "$wordy", 6},
+	}
+	for _, tt := range tests {
+		_, err := re.CompileTemplate(tt.template)
+		var te *TemplateError
+		if !errors.As(err, &te) || !errors.Is(err, ErrUnknownGroup) {
+			t.Errorf("CompileTemplate(%q) err = %v, want *TemplateError wrapping ErrUnknownGroup", tt.template, err)
+			continue
+		}
+		if te.Ref != tt.ref || te.Offset != tt.offset {
+			t.Errorf("CompileTemplate(%q): Ref = %q, Offset = %d, want %q, %d", tt.template, te.Ref, te.Offset, tt.ref, tt.offset)
+		}
+	}
+
+	for _, ok := range []string{`$0 $1 $2 $word ${word} ${2}x $$3`} {
+		if _, err := re.CompileTemplate(ok); err != nil {
+			t.Errorf("CompileTemplate(%q): %v", ok, err)
+		}
+	}
+}
+
+func TestTemplateExpand(t *testing.T) {
+	re := MustCompile(`(\w+):(\w+)`)
+	tmpl := re.MustCompileTemplate(`\U$2\E=$1`)
+	src := []byte("key:value")
+	if got := string(tmpl.Expand([]byte("> "), src, re.FindSubmatchIndex(src))); got != "> VALUE=key" {
+		t.Errorf("Expand = %q", got)
+	}
+}
+
+func TestReplaceAllTemplateAllocs(t *testing.T) {
+	re := MustCompile(`(\w+)=(\w+)`)
+	tmpl := re.MustCompileTemplate(`\U$1\E:$2`)
+	small := []byte("a=1 b=2")
+	large := []byte(strings.Repeat("host=db port=5432 ", 500))
+
+	allocsSmall := testing.AllocsPerRun(20, func() { re.ReplaceAllTemplate(small, tmpl) })
+	allocsLarge := testing.AllocsPerRun(20, func() { re.ReplaceAllTemplate(large, tmpl) })
+	if allocsLarge > allocsSmall {
+		t.Errorf("allocs grow with match count: %v for 2 matches, %v for 1000", allocsSmall, allocsLarge)
+	}
+}
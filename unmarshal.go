@@ -0,0 +1,375 @@
+package coregex
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNoMatch is returned by Unmarshal when the regex does not match.
+var ErrNoMatch = errors.New("regexp: no match")
+
+// ErrUnknownGroup is the Err of an UnmarshalError for a struct field whose
+// tag names a capture group that the regex does not have.
+var ErrUnknownGroup = errors.New("no capture group with that name")
+
+// InvalidUnmarshalError describes an invalid argument passed to Unmarshal:
+// it must be a non-nil pointer to a struct or to a map with string keys.
+type InvalidUnmarshalError struct {
+	Type reflect.Type
+}
+
+// Error implements the error interface.
+func (e *InvalidUnmarshalError) Error() string {
+	if e.Type == nil {
+		return "regexp: Unmarshal(nil)"
+	}
+	if e.Type.Kind() != reflect.Pointer {
+		return "regexp: Unmarshal(non-pointer " + e.Type.String() + ")"
+	}
+	if e.Type.Elem().Kind() == reflect.Struct || e.Type.Elem().Kind() == reflect.Map {
+		return "regexp: Unmarshal(nil " + e.Type.String() + ")"
+	}
+	return "regexp: Unmarshal(unsupported type " + e.Type.String() + ")"
+}
+
+// UnmarshalError reports a capture group that could not be stored in its
+// destination, usually because the captured text does not parse as the
+// destination type.
+type UnmarshalError struct {
+	Group string       // capture group name
+	Field string       // struct field name, empty for map entries
+	Text  string       // captured text
+	Type  reflect.Type // destination type
+	Err   error        // cause, e.g. a *strconv.NumError or ErrUnknownGroup
+}
+
+// Error implements the error interface.
+func (e *UnmarshalError) Error() string {
+	dest := "map value"
+	if e.Field != "" {
+		dest = "field " + e.Field
+	}
+	return fmt.Sprintf("regexp: cannot unmarshal group %q into %s of type %s: %v", e.Group, dest, e.Type, e.Err)
+}
+
+// Unwrap returns the cause.
+func (e *UnmarshalError) Unwrap() error {
+	return e.Err
+}
+
+// FindStringSubmatchMap returns the text of the named capture groups of the
+// leftmost match in s, keyed by group name. Unnamed groups and groups that
+// did not take part in the match are omitted; if several groups share a name,
+// the leftmost one that matched wins. A return value of nil indicates no
+// match.
+//
+// Example:
+//
+//	re := coregex.MustCompile(`(?P<key>\w+)=(?P<value>\w+)`)
+//	m := re.FindStringSubmatchMap("timeout=30")
+//	// m = map[key:timeout value:30]
+func (r *Regex) FindStringSubmatchMap(s string) map[string]string {
+	loc := r.FindStringSubmatchIndex(s)
+	if loc == nil {
+		return nil
+	}
+	m := make(map[string]string)
+	for i, name := range r.SubexpNames() {
+		if name == "" || loc[2*i] < 0 {
+			continue
+		}
+		if _, ok := m[name]; !ok {
+			m[name] = s[loc[2*i]:loc[2*i+1]]
+		}
+	}
+	return m
+}
+
+// Unmarshal finds the leftmost match in b and stores its named capture groups
+// in the value pointed to by v, which must be a struct or a map with string
+// keys. It returns ErrNoMatch if there is no match.
+//
+// Struct fields are filled from the group named by their `regex` tag; fields
+// without a tag, with the tag "-", or whose group did not take part in the
+// match are left unchanged. Embedded structs are searched for tagged fields
+// too. Map targets receive every named group that took part in the match,
+// as FindStringSubmatchMap.
+//
+// The captured text is converted to the destination type:
+//   - string, and []byte (copied)
+//   - bool (strconv.ParseBool), signed and unsigned integers (base 10),
+//     floats
+//   - time.Duration (time.ParseDuration)
+//   - time.Time, parsed as RFC 3339 unless the tag sets a layout:
+//     `regex:"when,layout=02/Jan/2006:15:04:05 -0700"` (the layout is the
+//     rest of the tag)
+//   - any type implementing encoding.TextUnmarshaler
+//   - pointers to the above, allocated as needed
+//
+// A conversion failure is reported as an *UnmarshalError; fields before it
+// may already be set. A tag naming a group the regex does not have is an
+// *UnmarshalError wrapping ErrUnknownGroup.
+//
+// Example:
+//
+//	type Request struct {
+//	    Method string        `regex:"method"`
+//	    Status int           `regex:"status"`
+//	    Took   time.Duration `regex:"took"`
+//	}
+//	re := coregex.MustCompile(`(?P<method>[A-Z]+) \S+ (?P<status>\d{3}) (?P<took>\S+)`)
+//	var req Request
+//	err := re.Unmarshal([]byte("GET /index 200 1.5ms"), &req)
+//	// req = {Method:GET Status:200 Took:1.5ms}
+func (r *Regex) Unmarshal(b []byte, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return &InvalidUnmarshalError{Type: reflect.TypeOf(v)}
+	}
+	target := rv.Elem()
+	switch {
+	case target.Kind() == reflect.Struct:
+	case target.Kind() == reflect.Map && target.Type().Key().Kind() == reflect.String:
+	default:
+		return &InvalidUnmarshalError{Type: rv.Type()}
+	}
+
+	loc := r.FindSubmatchIndex(b)
+	if loc == nil {
+		return ErrNoMatch
+	}
+	names := r.SubexpNames()
+
+	if target.Kind() == reflect.Map {
+		return unmarshalMap(target, b, loc, names)
+	}
+	return unmarshalStruct(target, b, loc, names)
+}
+
+// UnmarshalString is like Unmarshal but matches against a string.
+func (r *Regex) UnmarshalString(s string, v any) error {
+	return r.Unmarshal(stringToBytes(s), v)
+}
+
+// unmarshalMap stores every named group that took part in the match in m.
+func unmarshalMap(m reflect.Value, b []byte, loc []int, names []string) error {
+	if m.IsNil() {
+		m.Set(reflect.MakeMap(m.Type()))
+	}
+	keyType, elemType := m.Type().Key(), m.Type().Elem()
+	seen := make(map[string]bool, len(names))
+	for i, name := range names {
+		if name == "" || loc[2*i] < 0 || seen[name] {
+			continue
+		}
+		seen[name] = true
+		text := b[loc[2*i]:loc[2*i+1]]
+		elem := reflect.New(elemType).Elem()
+		if err := setText(elem, text, ""); err != nil {
+			return &UnmarshalError{Group: name, Text: string(text), Type: elemType, Err: err}
+		}
+		m.SetMapIndex(reflect.ValueOf(name).Convert(keyType), elem)
+	}
+	return nil
+}
+
+// unmarshalStruct fills the tagged fields of s.
+func unmarshalStruct(s reflect.Value, b []byte, loc []int, names []string) error {
+	for _, f := range structFields(s.Type()) {
+		group := -1
+		for i, name := range names {
+			if name == f.group && (group < 0 || loc[2*group] < 0) {
+				group = i
+			}
+		}
+		if group < 0 {
+			return &UnmarshalError{Group: f.group, Field: f.name, Type: f.typ, Err: ErrUnknownGroup}
+		}
+		if loc[2*group] < 0 {
+			continue
+		}
+
+		text := b[loc[2*group]:loc[2*group+1]]
+		field, err := fieldByIndexAlloc(s, f.index)
+		if err == nil {
+			err = setText(field, text, f.layout)
+		}
+		if err != nil {
+			return &UnmarshalError{Group: f.group, Field: f.name, Text: string(text), Type: f.typ, Err: err}
+		}
+	}
+	return nil
+}
+
+// fieldByIndexAlloc is FieldByIndex that allocates nil embedded pointers.
+func fieldByIndexAlloc(v reflect.Value, index []int) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return v, fmt.Errorf("cannot set embedded pointer to unexported struct %s", v.Type().Elem())
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, nil
+}
+
+var (
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+	timeType            = reflect.TypeFor[time.Time]()
+	durationType        = reflect.TypeFor[time.Duration]()
+)
+
+// setText converts text to the type of v and stores it. layout is the
+// time.Time layout, empty for RFC 3339.
+func setText(v reflect.Value, text []byte, layout string) error { //nolint:cyclop // one case per supported kind
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return setText(v.Elem(), text, layout)
+	}
+
+	// time.Time is a TextUnmarshaler, but only for RFC 3339.
+	switch v.Type() {
+	case timeType:
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		t, err := time.Parse(layout, string(text))
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	case durationType:
+		d, err := time.ParseDuration(string(text))
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	if v.CanAddr() && v.Addr().Type().Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText(text)
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(string(text))
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.Uint8 {
+			return fmt.Errorf("unsupported type %s", v.Type())
+		}
+		v.SetBytes(append([]byte(nil), text...))
+	case reflect.Bool:
+		x, err := strconv.ParseBool(string(text))
+		if err != nil {
+			return err
+		}
+		v.SetBool(x)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		x, err := strconv.ParseInt(string(text), 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(x)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		x, err := strconv.ParseUint(string(text), 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(x)
+	case reflect.Float32, reflect.Float64:
+		x, err := strconv.ParseFloat(string(text), v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(x)
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			return fmt.Errorf("unsupported type %s", v.Type())
+		}
+		v.Set(reflect.ValueOf(string(text)))
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// unmarshalField is a struct field filled by Unmarshal.
+type unmarshalField struct {
+	group  string       // capture group name from the tag
+	layout string       // time.Time layout from the tag
+	name   string       // Go field name, for errors
+	index  []int        // reflect field index path
+	typ    reflect.Type // field type
+}
+
+// fieldCache maps a struct type to its []unmarshalField.
+var fieldCache sync.Map
+
+// structFields returns the tagged fields of struct type t, including those of
+// embedded structs.
+func structFields(t reflect.Type) []unmarshalField {
+	if cached, ok := fieldCache.Load(t); ok {
+		return cached.([]unmarshalField)
+	}
+	fields := appendStructFields(nil, t, nil, map[reflect.Type]bool{t: true})
+	cached, _ := fieldCache.LoadOrStore(t, fields)
+	return cached.([]unmarshalField)
+}
+
+// appendStructFields appends the tagged fields of t, and of the structs it
+// embeds, to fields. visited holds the struct types on the current embedding
+// path: a type is not expanded again inside itself, so that a type embedding
+// itself (type T struct{ *T }) does not recurse forever, but a type embedded
+// through two different paths is expanded on each and all its fields are
+// filled.
+func appendStructFields(fields []unmarshalField, t reflect.Type, index []int, visited map[reflect.Type]bool) []unmarshalField {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		path := append(index[:len(index):len(index)], i)
+
+		tag, tagged := sf.Tag.Lookup("regex")
+		if !tagged && sf.Anonymous {
+			ft := sf.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct && !visited[ft] {
+				visited[ft] = true
+				fields = appendStructFields(fields, ft, path, visited)
+				delete(visited, ft)
+			}
+			continue
+		}
+		if !tagged || tag == "-" || !sf.IsExported() {
+			continue
+		}
+
+		group, layout := tag, ""
+		if i := strings.Index(tag, ",layout="); i >= 0 {
+			group, layout = tag[:i], tag[i+len(",layout="):]
+		}
+		fields = append(fields, unmarshalField{
+			group:  group,
+			layout: layout,
+			name:   sf.Name,
+			index:  path,
+			typ:    sf.Type,
+		})
+	}
+	return fields
+}
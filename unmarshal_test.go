@@ -0,0 +1,261 @@
+package coregex
+
+import (
+	"errors"
+	"net/netip"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestFindStringSubmatchMap(t *testing.T) {
+	tests := []struct {
+		pattern string
+		input   string
+		want    map[string]string
+	}{
+		{`(?P<key>\w+)=(?P<value>\w+)`, "timeout=30", map[string]string{"key": "timeout", "value": "30"}},
+		{`(?P<key>\w+)=(?P<value>\w+)?`, "timeout=", map[string]string{"key": "timeout"}},
+		{`(\w+)=(?P<value>\w+)`, "a=b", map[string]string{"value": "b"}},
+		{`(?P<x>a)|(?P<x>b)`, "b", map[string]string{"x": "b"}},
+		{`(\w+)`, "abc", map[string]string{}},
+		{`(?P<x>\d+)`, "none", nil},
+	}
+	for _, tt := range tests {
+		got := MustCompile(tt.pattern).FindStringSubmatchMap(tt.input)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q on %q: got %v, want %v", tt.pattern, tt.input, got, tt.want)
+		}
+	}
+}
+
+type accessLogEntry struct {
+	Addr    netip.Addr    `regex:"addr"`
+	When    time.Time     `regex:"when,layout=02/Jan/2006:15:04:05 -0700"`
+	Method  string        `regex:"method"`
+	Path    []byte        `regex:"path"`
+	Status  int           `regex:"status"`
+	Size    *uint64       `regex:"size"`
+	Took    time.Duration `regex:"took"`
+	Ratio   float64       `regex:"ratio"`
+	Cached  bool          `regex:"cached"`
+	Comment string        `regex:"comment"`
+	Ignored string        `regex:"-"`
+	NoTag   string
+}
+
+var accessLogRe = MustCompile(`^(?P<addr>\S+) \[(?P<when>[^\]]+)\] "(?P<method>[A-Z]+) (?P<path>\S+)" ` +
+	`(?P<status>\d+) (?:(?P<size>\d+)|-) (?P<took>\S+) (?P<ratio>\S+) (?P<cached>\w+)(?: #(?P<comment>.*))?$`)
+
+func TestUnmarshalStruct(t *testing.T) {
+	line := `10.0.0.1 [10/Oct/2026:13:55:36 +0200] "GET /index.html" 200 2326 1.5ms 0.25 true`
+
+	entry := accessLogEntry{Comment: "keep", Ignored: "keep", NoTag: "keep"}
+	if err := accessLogRe.UnmarshalString(line, &entry); err != nil {
+		t.Fatal(err)
+	}
+
+	size := uint64(2326)
+	want := accessLogEntry{
+		Addr:    netip.MustParseAddr("10.0.0.1"),
+		When:    time.Date(2026, 10, 10, 13, 55, 36, 0, time.FixedZone("", 2*3600)),
+		Method:  "GET",
+		Path:    []byte("/index.html"),
+		Status:  200,
+		Size:    &size,
+		Took:    1500 * time.Microsecond,
+		Ratio:   0.25,
+		Cached:  true,
+		Comment: "keep", // group did not take part in the match
+		Ignored: "keep",
+		NoTag:   "keep",
+	}
+	if !entry.When.Equal(want.When) {
+		t.Errorf("When = %v, want %v", entry.When, want.When)
+	}
+	entry.When = want.When
+	if !reflect.DeepEqual(entry, want) {
+		t.Errorf("got %+v\nwant %+v", entry, want)
+	}
+
+	// An unmatched optional group leaves the pointer nil.
+	var noSize accessLogEntry
+	line = `::1 [10/Oct/2026:13:55:36 +0000] "HEAD /" 304 - 2s 1 false #cache hit`
+	if err := accessLogRe.UnmarshalString(line, &noSize); err != nil {
+		t.Fatal(err)
+	}
+	if noSize.Size != nil || noSize.Comment != "cache hit" || noSize.Status != 304 {
+		t.Errorf("got %+v", noSize)
+	}
+}
+
+type embeddedBase struct {
+	ID int `regex:"id"`
+}
+
+type embeddingEntry struct {
+	embeddedBase
+	*EmbeddedPtr
+	Name string `regex:"name"`
+}
+
+type EmbeddedPtr struct {
+	Tag string `regex:"tag"`
+}
+
+func TestUnmarshalEmbedded(t *testing.T) {
+	re := MustCompile(`(?P<id>\d+):(?P<name>\w+):(?P<tag>\w+)`)
+	var e embeddingEntry
+	if err := re.UnmarshalString("42:alice:admin", &e); err != nil {
+		t.Fatal(err)
+	}
+	if e.ID != 42 || e.Name != "alice" || e.EmbeddedPtr == nil || e.Tag != "admin" {
+		t.Errorf("got %+v", e)
+	}
+
+	var unexported struct {
+		*embeddedBase
+	}
+	var ue *UnmarshalError
+	if err := re.UnmarshalString("42:alice:admin", &unexported); !errors.As(err, &ue) {
+		t.Errorf("err = %v, want *UnmarshalError", err)
+	}
+}
+
+// SelfEmbedding and the mutually embedding CycleA and CycleB would recurse
+// forever if embedded types were expanded more than once.
+type SelfEmbedding struct {
+	*SelfEmbedding
+	Name string `regex:"name"`
+}
+
+type CycleA struct {
+	*CycleB
+	ID int `regex:"id"`
+}
+
+type CycleB struct {
+	*CycleA
+	Tag string `regex:"tag"`
+}
+
+func TestUnmarshalEmbeddedCycle(t *testing.T) {
+	re := MustCompile(`(?P<id>\d+):(?P<name>\w+):(?P<tag>\w+)`)
+	var self SelfEmbedding
+	if err := re.UnmarshalString("42:alice:admin", &self); err != nil {
+		t.Fatal(err)
+	}
+	if self.Name != "alice" || self.SelfEmbedding != nil {
+		t.Errorf("got %+v", self)
+	}
+
+	var a CycleA
+	if err := re.UnmarshalString("42:alice:admin", &a); err != nil {
+		t.Fatal(err)
+	}
+	if a.ID != 42 || a.CycleB == nil || a.Tag != "admin" || a.CycleA != nil {
+		t.Errorf("got %+v, %+v", a, a.CycleB)
+	}
+}
+
+type diamondLeft struct {
+	embeddedBase
+}
+
+type diamondRight struct {
+	embeddedBase
+}
+
+// diamondEntry reaches embeddedBase through two paths.
+type diamondEntry struct {
+	diamondLeft
+	diamondRight
+	Name string `regex:"name"`
+}
+
+func TestUnmarshalEmbeddedDiamond(t *testing.T) {
+	re := MustCompile(`(?P<id>\d+):(?P<name>\w+)`)
+	var d diamondEntry
+	if err := re.UnmarshalString("42:alice", &d); err != nil {
+		t.Fatal(err)
+	}
+	if d.diamondLeft.ID != 42 || d.diamondRight.ID != 42 || d.Name != "alice" {
+		t.Errorf("got %+v", d)
+	}
+}
+
+func TestUnmarshalMap(t *testing.T) {
+	re := MustCompile(`(?P<a>\d+),(?P<b>\d+)(?:,(?P<c>\d+))?`)
+
+	var ints map[string]int
+	if err := re.UnmarshalString("1,2", &ints); err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]int{"a": 1, "b": 2}; !reflect.DeepEqual(ints, want) {
+		t.Errorf("got %v, want %v", ints, want)
+	}
+
+	anys := map[string]any{"old": true}
+	if err := re.UnmarshalString("1,2,3", &anys); err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]any{"old": true, "a": "1", "b": "2", "c": "3"}; !reflect.DeepEqual(anys, want) {
+		t.Errorf("got %v, want %v", anys, want)
+	}
+
+	var small map[string]int8
+	err := re.UnmarshalString("1,300", &small)
+	var ue *UnmarshalError
+	if !errors.As(err, &ue) || ue.Group != "b" || ue.Field != "" || ue.Text != "300" {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	re := MustCompile(`(?P<n>\w+)`)
+
+	var s struct {
+		N int `regex:"n"`
+	}
+	err := re.UnmarshalString("abc", &s)
+	var ue *UnmarshalError
+	if !errors.As(err, &ue) {
+		t.Fatalf("err = %v, want *UnmarshalError", err)
+	}
+	if ue.Group != "n" || ue.Field != "N" || ue.Text != "abc" || ue.Type != reflect.TypeFor[int]() {
+		t.Errorf("UnmarshalError = %+v", ue)
+	}
+	if !errors.Is(err, strconv.ErrSyntax) {
+		t.Errorf("err = %v, want it to wrap strconv.ErrSyntax", err)
+	}
+	if got := err.Error(); got != `regexp: cannot unmarshal group "n" into field N of type int: strconv.ParseInt: parsing "abc": invalid syntax` {
+		t.Errorf("Error() = %s", got)
+	}
+
+	var unknown struct {
+		X string `regex:"x"`
+	}
+	if err := re.UnmarshalString("abc", &unknown); !errors.Is(err, ErrUnknownGroup) {
+		t.Errorf("err = %v, want ErrUnknownGroup", err)
+	}
+
+	if err := re.UnmarshalString("!!!", &s); !errors.Is(err, ErrNoMatch) {
+		t.Errorf("err = %v, want ErrNoMatch", err)
+	}
+
+	var unsupported struct {
+		C chan int `regex:"n"`
+	}
+	if err := re.UnmarshalString("abc", &unsupported); !errors.As(err, &ue) {
+		t.Errorf("err = %v, want *UnmarshalError", err)
+	}
+
+	var nilPtr *struct{}
+	for _, v := range []any{nil, s, nilPtr, new(int), new(map[int]string)} {
+		var ie *InvalidUnmarshalError
+		if err := re.UnmarshalString("abc", v); !errors.As(err, &ie) {
+			t.Errorf("Unmarshal(%T) err = %v, want *InvalidUnmarshalError", v, err)
+		}
+	}
+}
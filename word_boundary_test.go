@@ -1,7 +1,13 @@
 package coregex
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
 	"regexp"
+	"strings"
 	"testing"
 )
 
@@ -336,6 +342,177 @@ func TestIssue12_WordBoundary(t *testing.T) {
 	}
 }
 
+// TestWordBoundaryByteClasses checks that bytes on both sides of \b never
+// share a DFA byte class: '!' and '0' take the same transition in [^a] but
+// only '!' follows a word boundary after 'a'.
+func TestWordBoundaryByteClasses(t *testing.T) {
+	for _, pattern := range []string{`a\b[^a]`, `a\B[^a]`, `[^x]\b.`} {
+		re := MustCompile(pattern)
+		std := regexp.MustCompile(pattern)
+		for _, input := range []string{"a! a0", "a0 a!", "xa0a!", "_!0 z"} {
+			got := re.FindAllStringIndex(input, -1)
+			want := std.FindAllStringIndex(input, -1)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("%q FindAllStringIndex(%q) = %v, want %v", pattern, input, got, want)
+			}
+		}
+	}
+}
+
+// TestNoWordBoundaryStreaming checks \B against the standard library on the
+// reader, context, streaming replace and Stream APIs, which run the lazy DFA
+// on their own. An empty \B match in a start state, e.g. at 6 in
+// "k=v a= b=c", was missed.
+func TestNoWordBoundaryStreaming(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	patterns := []string{`\B`, `\B\B`, `(?:\B)+`, `\B|zz`, `x*\B`, `\b`, `=\B`, `\B\w+`}
+	inputs := []string{"k=v a= b=c", "==", "  ab", "ab  cd", "a=\n\nb", ""}
+	for _, pattern := range patterns {
+		re := MustCompile(pattern)
+		std := regexp.MustCompile(pattern)
+		for _, input := range inputs {
+			want := std.FindStringIndex(input)
+			if got := re.FindReaderIndex(strings.NewReader(input)); !reflect.DeepEqual(got, want) {
+				t.Errorf("%q FindReaderIndex(%q) = %v, want %v", pattern, input, got, want)
+			}
+			if got := re.MatchReader(strings.NewReader(input)); got != (want != nil) {
+				t.Errorf("%q MatchReader(%q) = %v", pattern, input, got)
+			}
+			if got, err := re.FindIndexContext(ctx, []byte(input)); err != nil || !reflect.DeepEqual(got, want) {
+				t.Errorf("%q FindIndexContext(%q) = %v, %v, want %v", pattern, input, got, err, want)
+			}
+			if got, err := re.MatchContext(ctx, []byte(input)); err != nil || got != (want != nil) {
+				t.Errorf("%q MatchContext(%q) = %v, %v", pattern, input, got, err)
+			}
+
+			var out bytes.Buffer
+			if _, err := re.ReplaceAllReader(&out, strings.NewReader(input), []byte("<$0>")); err != nil {
+				t.Fatal(err)
+			}
+			if want := std.ReplaceAllString(input, "<$0>"); out.String() != want {
+				t.Errorf("%q ReplaceAllReader(%q) = %q, want %q", pattern, input, out.String(), want)
+			}
+
+			var ends []int64
+			stream, err := re.NewStream(func(end int64) { ends = append(ends, end) })
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := range len(input) {
+				_, _ = stream.Write([]byte{input[i]})
+			}
+			_ = stream.Close()
+			if want := matchEnds(std, input); !reflect.DeepEqual(ends, want) {
+				t.Errorf("%q Stream(%q) ends = %v, want %v", pattern, input, ends, want)
+			}
+		}
+	}
+}
+
+// matchEnds returns every offset of the ASCII input at which some match of
+// re ends, as a Stream reports them.
+func matchEnds(re *regexp.Regexp, input string) []int64 {
+	var ends []int64
+	for end := 0; end <= len(input); end++ {
+		// The match must be followed by exactly the rest of the input.
+		suffix := regexp.MustCompile(fmt.Sprintf(`(?:%s)(?s:.){%d}\z`, re, len(input)-end))
+		if suffix.MatchString(input) {
+			ends = append(ends, int64(end))
+		}
+	}
+	return ends
+}
+
+// TestUnicodeWordBoundary tests \b and \B with CompileOptions.UnicodeWordBoundary,
+// with and without the lazy DFA.
+func TestUnicodeWordBoundary(t *testing.T) {
+	tests := []struct {
+		pattern string
+		input   string
+		want    [][]int
+	}{
+		{`\bслово\b`, "слово словом слово", [][]int{{0, 10}, {24, 34}}},
+		{`\b\pL+\b`, "日本語 と English", [][]int{{0, 9}, {10, 13}, {14, 21}}},
+		{`\bcafé\b`, "café cafés", [][]int{{0, 5}}},
+		{`\b\d+\b`, "x٣ 42 ٤5", [][]int{{4, 6}}},
+		{`é\B`, "éé é", [][]int{{0, 2}}},
+		{`\Bb`, "ab éb b", [][]int{{1, 2}, {5, 6}}},
+		{`\b`, "ж", [][]int{{0, 0}, {2, 2}}},
+		{`\b`, "a\xffb", [][]int{{0, 0}, {1, 1}, {2, 2}, {3, 3}}},
+		{`x\b`, "x\u0301 x_ x-", [][]int{{7, 8}}},
+		{`\bword\b`, "a word, swords", [][]int{{2, 6}}},
+		{`\bÉTÉ\b`, "l'ÉTÉ", [][]int{{2, 7}}},
+		{`\b(?=\pL)`, "+ж", [][]int{{1, 1}}},
+	}
+
+	for _, enableDFA := range []bool{true, false} {
+		config := CompileOptions{UnicodeWordBoundary: true}.Config()
+		config.EnableDFA = enableDFA
+		for _, tt := range tests {
+			re := mustCompileWithConfig(t, tt.pattern, config)
+			if got := re.FindAllStringIndex(tt.input, -1); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DFA=%v %q FindAllStringIndex(%q) = %v, want %v",
+					enableDFA, tt.pattern, tt.input, got, tt.want)
+			}
+			if got := re.MatchString(tt.input); got != (tt.want != nil) {
+				t.Errorf("DFA=%v %q MatchString(%q) = %v", enableDFA, tt.pattern, tt.input, got)
+			}
+		}
+	}
+
+	// Without the option, Cyrillic letters are not word chars.
+	if loc := MustCompile(`\bслово\b`).FindStringIndex("слово"); loc != nil {
+		t.Errorf("ASCII \\b matched %v", loc)
+	}
+
+	// Byte mode keeps ASCII word boundaries.
+	config := CompileOptions{UnicodeWordBoundary: true}.Config()
+	config.Bytes = true
+	if locs := mustCompileWithConfig(t, `\b`, config).FindAllStringIndex("é", -1); locs != nil {
+		t.Errorf("byte mode \\b matched %v", locs)
+	}
+}
+
+// TestUnicodeWordBoundaryASCIIPrefix checks that the DFA answer on an ASCII
+// prefix is not taken for the answer on the whole input.
+func TestUnicodeWordBoundaryASCIIPrefix(t *testing.T) {
+	re := MustCompileWithOptions(`\bfoo\b`, CompileOptions{UnicodeWordBoundary: true})
+	ascii := strings.Repeat("x ", 1000)
+	if re.MatchString(ascii + "fooé") {
+		t.Error(`\bfoo\b matched "fooé"`)
+	}
+	if loc := re.FindStringIndex(ascii + "fooé foo"); !reflect.DeepEqual(loc, []int{2006, 2009}) {
+		t.Errorf("FindStringIndex = %v, want [2006 2009]", loc)
+	}
+	if got := re.ReplaceAllString("fooé foo", "bar"); got != "fooé bar" {
+		t.Errorf("ReplaceAllString = %q", got)
+	}
+	if _, err := re.NewStream(func(int64) {}); !errors.Is(err, ErrStreamUnsupported) {
+		t.Errorf("NewStream error = %v, want ErrStreamUnsupported", err)
+	}
+	if loc := re.FindReaderIndex(strings.NewReader("fooé foo")); !reflect.DeepEqual(loc, []int{6, 9}) {
+		t.Errorf("FindReaderIndex = %v, want [6 9]", loc)
+	}
+}
+
+// TestWordBoundaryCaptures checks word boundaries between capture groups,
+// which the one-pass DFA cannot evaluate.
+func TestWordBoundaryCaptures(t *testing.T) {
+	for _, tt := range []struct {
+		pattern, input string
+		want           []string
+	}{
+		{`^(a)\b(b)`, "ab", nil},
+		{`^(a)\B(b)`, "ab", []string{"ab", "a", "b"}},
+		{`^(\w+)\b(.*)`, "ab cd", []string{"ab cd", "ab", " cd"}},
+	} {
+		if got := MustCompile(tt.pattern).FindStringSubmatch(tt.input); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q FindStringSubmatch(%q) = %q, want %q", tt.pattern, tt.input, got, tt.want)
+		}
+	}
+}
+
 // BenchmarkWordBoundary benchmarks word boundary matching.
 func BenchmarkWordBoundary(b *testing.B) {
 	pattern := `\bword\b`